@@ -74,6 +74,9 @@ func Run2() error {
 	}()
 
 	app.RunBackgroundProcesses(firstCtx)
+	// team topics are authorized when subscribed to, drop the ones of
+	// members that were removed since
+	go appApi.RunSseTopicChecks(firstCtx, time.Minute)
 
 	fmt.Printf("server running on port %d", app.Config().Options.Port)
 
@@ -216,6 +216,72 @@ func BindAdminApi(api huma.API, appApi *Api) {
 		},
 		appApi.AdminUserPermissionsDelete,
 	)
+	// admin user permission explain
+	huma.Register(
+		adminGroup,
+		huma.Operation{
+			OperationID: "admin-user-permission-explain",
+			Method:      http.MethodGet,
+			Path:        "/users/{user-id}/permissions/explain",
+			Summary:     "Explain user permission",
+			Description: "Every path granting the permission to the user: directly, through a role, or through a product of a subscription",
+			Tags:        []string{"Admin", "Permissions", "User"},
+			Errors:      []int{http.StatusNotFound},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+		},
+		appApi.AdminUserPermissionExplain,
+	)
+	// admin user impersonate
+	huma.Register(
+		adminGroup,
+		huma.Operation{
+			OperationID: "admin-user-impersonate",
+			Method:      http.MethodPost,
+			Path:        "/users/{user-id}/impersonate",
+			Summary:     "Impersonate user",
+			Description: "Short lived access token acting as the user, every request made with it is written to the audit log",
+			Tags:        []string{"Admin", "User", "Impersonation"},
+			Errors:      []int{http.StatusNotFound, http.StatusBadRequest},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+		},
+		appApi.AdminUserImpersonate,
+	)
+	// admin user impersonations list
+	huma.Register(
+		adminGroup,
+		huma.Operation{
+			OperationID: "admin-user-impersonations",
+			Method:      http.MethodGet,
+			Path:        "/users/{user-id}/impersonations",
+			Summary:     "User impersonations",
+			Description: "Impersonations of the user, latest first",
+			Tags:        []string{"Admin", "User", "Impersonation"},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+		},
+		appApi.AdminUserImpersonations,
+	)
+	// admin impersonation audit logs
+	huma.Register(
+		adminGroup,
+		huma.Operation{
+			OperationID: "admin-impersonation-audit-logs",
+			Method:      http.MethodGet,
+			Path:        "/impersonations/{impersonation-id}/audit-logs",
+			Summary:     "Impersonation audit logs",
+			Description: "Audit logs of the impersonation, from its start to its end",
+			Tags:        []string{"Admin", "Impersonation"},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+		},
+		appApi.AdminImpersonationAuditLogs,
+	)
 	// admin user accounts list
 	huma.Register(
 		adminGroup,
@@ -349,6 +415,8 @@ func BindAdminApi(api huma.API, appApi *Api) {
 	huma.Register(adminGroup, huma.Operation{OperationID: "admin-permissions-update", Method: http.MethodPut, Path: "/permissions/{id}", Summary: "Update permission", Description: "Update permission", Tags: []string{"Admin", "Permissions"}, Errors: []int{http.StatusNotFound}, Security: []map[string][]string{{shared.BearerAuthSecurityKey: {}}}}, appApi.AdminPermissionsUpdate)
 	// admin permissions delete
 	huma.Register(adminGroup, huma.Operation{OperationID: "admin-permissions-delete", Method: http.MethodDelete, Path: "/permissions/{id}", Summary: "Delete permission", Description: "Delete permission", Tags: []string{"Admin", "Permissions"}, Errors: []int{http.StatusNotFound}, Security: []map[string][]string{{shared.BearerAuthSecurityKey: {}}}}, appApi.AdminPermissionsDelete)
+	// admin permissions check
+	huma.Register(adminGroup, huma.Operation{OperationID: "admin-permissions-check", Method: http.MethodPost, Path: "/permissions/check", Summary: "Check permissions", Description: "Dry run permission checks of users, with the paths granting them. Product grants are listed apart, they are not enforced yet", Tags: []string{"Admin", "Permissions"}, Errors: []int{http.StatusBadRequest}, Security: []map[string][]string{{shared.BearerAuthSecurityKey: {}}}}, appApi.AdminPermissionsCheck)
 
 	// admin stripe subscriptions
 	huma.Register(adminGroup, huma.Operation{OperationID: "admin-stripe-subscriptions", Method: http.MethodGet, Path: "/subscriptions", Summary: "Admin stripe subscriptions", Description: "List of stripe subscriptions", Tags: []string{"Admin", "Subscription", "Stripe"}, Errors: []int{http.StatusNotFound}, Security: []map[string][]string{{shared.BearerAuthSecurityKey: {}}}}, appApi.AdminStripeSubscriptions)
@@ -406,6 +474,37 @@ func BindAdminApi(api huma.API, appApi *Api) {
 		appApi.AdminGetJobs,
 	)
 
+	huma.Register(
+		adminGroup,
+		huma.Operation{
+			OperationID: "admin-jobs-stats",
+			Method:      http.MethodGet,
+			Path:        "/jobs/stats",
+			Summary:     "Admin job stats",
+			Description: "Job counts by kind and status, run durations, failure rates and queue lag",
+			Tags:        []string{"Admin", "Jobs"},
+			Errors:      []int{http.StatusBadRequest},
+			Security:    []map[string][]string{{shared.BearerAuthSecurityKey: {}}},
+		},
+		appApi.AdminGetJobStats,
+	)
+	appApi.BindAdminJobStatsSse(adminGroup)
+
+	huma.Register(
+		adminGroup,
+		huma.Operation{
+			OperationID: "admin-job-group-get",
+			Method:      http.MethodGet,
+			Path:        "/job-groups/{group-id}",
+			Summary:     "Admin job group get",
+			Description: "Get the state of a job group and its jobs",
+			Tags:        []string{"Admin", "Jobs"},
+			Errors:      []int{http.StatusNotFound, http.StatusBadRequest},
+			Security:    []map[string][]string{{shared.BearerAuthSecurityKey: {}}},
+		},
+		appApi.AdminGetJobGroup,
+	)
+
 	huma.Register(
 		adminGroup,
 		huma.Operation{
@@ -435,4 +534,109 @@ func BindAdminApi(api huma.API, appApi *Api) {
 		},
 		appApi.AdminUpdateJob,
 	)
+
+	huma.Register(
+		adminGroup,
+		huma.Operation{
+			OperationID: "admin-oidc-providers",
+			Method:      http.MethodGet,
+			Path:        "/oidc-providers",
+			Summary:     "Admin OIDC providers",
+			Description: "List the OpenID Connect providers users can sign in with",
+			Tags:        []string{"Admin", "OAuth2"},
+			Errors:      []int{http.StatusNotFound},
+			Security:    []map[string][]string{{shared.BearerAuthSecurityKey: {}}},
+		},
+		appApi.AdminOidcProviders,
+	)
+
+	huma.Register(
+		adminGroup,
+		huma.Operation{
+			OperationID: "admin-oidc-providers-create",
+			Method:      http.MethodPost,
+			Path:        "/oidc-providers",
+			Summary:     "Create OIDC provider",
+			Description: "Add an OpenID Connect provider like Keycloak, Okta or Azure AD. The issuer must serve a discovery document.",
+			Tags:        []string{"Admin", "OAuth2"},
+			Errors:      []int{http.StatusBadRequest, http.StatusConflict},
+			Security:    []map[string][]string{{shared.BearerAuthSecurityKey: {}}},
+		},
+		appApi.AdminOidcProvidersCreate,
+	)
+
+	huma.Register(
+		adminGroup,
+		huma.Operation{
+			OperationID: "admin-oidc-providers-delete",
+			Method:      http.MethodDelete,
+			Path:        "/oidc-providers/{provider-id}",
+			Summary:     "Delete OIDC provider",
+			Description: "Delete an OpenID Connect provider, users can no longer sign in with it",
+			Tags:        []string{"Admin", "OAuth2"},
+			Errors:      []int{http.StatusBadRequest},
+			Security:    []map[string][]string{{shared.BearerAuthSecurityKey: {}}},
+		},
+		appApi.AdminOidcProvidersDelete,
+	)
+
+	huma.Register(
+		adminGroup,
+		huma.Operation{
+			OperationID: "admin-user-sessions",
+			Method:      http.MethodGet,
+			Path:        "/users/{user-id}/sessions",
+			Summary:     "Admin user sessions",
+			Description: "List the devices a user is signed in on",
+			Tags:        []string{"Admin", "Users"},
+			Errors:      []int{http.StatusBadRequest},
+			Security:    []map[string][]string{{shared.BearerAuthSecurityKey: {}}},
+		},
+		appApi.AdminUserSessions,
+	)
+
+	huma.Register(
+		adminGroup,
+		huma.Operation{
+			OperationID: "admin-user-session-delete",
+			Method:      http.MethodDelete,
+			Path:        "/users/{user-id}/sessions/{session-id}",
+			Summary:     "Delete user session",
+			Description: "Sign a user out of a device",
+			Tags:        []string{"Admin", "Users"},
+			Errors:      []int{http.StatusBadRequest, http.StatusNotFound},
+			Security:    []map[string][]string{{shared.BearerAuthSecurityKey: {}}},
+		},
+		appApi.AdminUserSessionDelete,
+	)
+
+	huma.Register(
+		adminGroup,
+		huma.Operation{
+			OperationID: "admin-user-sessions-delete",
+			Method:      http.MethodDelete,
+			Path:        "/users/{user-id}/sessions",
+			Summary:     "Delete user sessions",
+			Description: "Sign a user out everywhere",
+			Tags:        []string{"Admin", "Users"},
+			Errors:      []int{http.StatusBadRequest},
+			Security:    []map[string][]string{{shared.BearerAuthSecurityKey: {}}},
+		},
+		appApi.AdminUserSessionsDelete,
+	)
+
+	huma.Register(
+		adminGroup,
+		huma.Operation{
+			OperationID: "admin-user-unlock",
+			Method:      http.MethodDelete,
+			Path:        "/users/{user-id}/lockout",
+			Summary:     "Unlock user",
+			Description: "Forget the failed sign ins of a user, lifting the lock of their account",
+			Tags:        []string{"Admin", "Users"},
+			Errors:      []int{http.StatusBadRequest, http.StatusNotFound},
+			Security:    []map[string][]string{{shared.BearerAuthSecurityKey: {}}},
+		},
+		appApi.AdminUserUnlock,
+	)
 }
@@ -0,0 +1,68 @@
+package apis
+
+import (
+	"context"
+	"errors"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/contextstore"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/services"
+)
+
+func (api *Api) AdminUserImpersonate(ctx context.Context, input *struct {
+	UserID uuid.UUID `path:"user-id" format:"uuid" required:"true"`
+}) (*AuthenticatedInfoResponse, error) {
+	info := contextstore.GetContextUserInfo(ctx)
+	if info == nil {
+		return nil, huma.Error401Unauthorized("unauthorized")
+	}
+	ok, err := api.App().Checker().CannotBeSuperUserID(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, huma.Error400BadRequest("Cannot impersonate super user")
+	}
+	tokens, err := api.App().Auth().Impersonate(ctx, info, input.UserID)
+	if err != nil {
+		if errors.Is(err, services.ErrImpersonateSelf) || errors.Is(err, services.ErrImpersonateSuperUser) || errors.Is(err, services.ErrAlreadyImpersonating) {
+			return nil, huma.Error400BadRequest(err.Error())
+		}
+		return nil, err
+	}
+	return &AuthenticatedInfoResponse{
+		Body: *ToApiUserInfoTokens(tokens),
+	}, nil
+}
+
+func (api *Api) AdminUserImpersonations(ctx context.Context, input *struct {
+	UserID uuid.UUID `path:"user-id" format:"uuid" required:"true"`
+}) (*ApiOutput[[]*models.Impersonation], error) {
+	impersonations, err := api.App().Auth().ListImpersonations(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if impersonations == nil {
+		impersonations = []*models.Impersonation{}
+	}
+	return &ApiOutput[[]*models.Impersonation]{
+		Body: impersonations,
+	}, nil
+}
+
+func (api *Api) AdminImpersonationAuditLogs(ctx context.Context, input *struct {
+	ImpersonationID uuid.UUID `path:"impersonation-id" format:"uuid" required:"true"`
+}) (*ApiOutput[[]*models.AuditLog], error) {
+	logs, err := api.App().Auth().ImpersonationAuditLogs(ctx, input.ImpersonationID)
+	if err != nil {
+		return nil, err
+	}
+	if logs == nil {
+		logs = []*models.AuditLog{}
+	}
+	return &ApiOutput[[]*models.AuditLog]{
+		Body: logs,
+	}, nil
+}
@@ -2,17 +2,26 @@ package apis
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
 	"time"
 
+	"github.com/danielgtaylor/huma/v2"
 	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/jobs"
 	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/services"
+	"github.com/tkahng/playground/internal/shared"
 	"github.com/tkahng/playground/internal/stores"
 	"github.com/tkahng/playground/internal/tools/mapper"
+	"github.com/tkahng/playground/internal/tools/sse"
 	"github.com/tkahng/playground/internal/tools/types"
 	"github.com/tkahng/playground/internal/tools/utils"
 )
 
-// 'pending', 'processing', 'done', 'failed'
+// 'pending', 'processing', 'done', 'failed', 'cancelled'
 type JobStatus string
 
 const (
@@ -20,6 +29,7 @@ const (
 	JobStatusProcessing JobStatus = "processing"
 	JobStatusDone       JobStatus = "done"
 	JobStatusFailed     JobStatus = "failed"
+	JobStatusCancelled  JobStatus = "cancelled"
 )
 
 type Job struct {
@@ -28,13 +38,21 @@ type Job struct {
 	Kind        string    `db:"kind" json:"kind"`
 	UniqueKey   *string   `db:"unique_key" json:"unique_key"`
 	Payload     string    `db:"payload" json:"payload"`
-	Status      JobStatus `db:"status" json:"status" enum:"pending,processing,done,failed"`
+	Status      JobStatus `db:"status" json:"status" enum:"pending,processing,done,failed,cancelled"`
 	RunAfter    time.Time `db:"run_after" json:"run_after"`
 	Attempts    int64     `db:"attempts" json:"attempts"`
 	MaxAttempts int64     `db:"max_attempts" json:"max_attempts"`
 	LastError   *string   `db:"last_error" json:"last_error"`
 	CreatedAt   time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+	Result      any       `db:"result" json:"result,omitempty"`
+	Progress    int64     `db:"progress" json:"progress"`
+
+	StartedAt          *time.Time  `db:"started_at" json:"started_at"`
+	FinishedAt         *time.Time  `db:"finished_at" json:"finished_at"`
+	GroupID            *uuid.UUID  `db:"group_id" json:"group_id"`
+	ParentIDs          []uuid.UUID `db:"parent_ids" json:"parent_ids"`
+	RunOnParentFailure bool        `db:"run_on_parent_failure" json:"run_on_parent_failure"`
 }
 
 func ToJob(j *models.JobRow) *Job {
@@ -53,19 +71,35 @@ func ToJob(j *models.JobRow) *Job {
 		LastError:   j.LastError,
 		CreatedAt:   j.CreatedAt,
 		UpdatedAt:   j.UpdatedAt,
+		Result:      toJobResult(j.Result),
+		Progress:    j.Progress,
+
+		StartedAt:          j.StartedAt,
+		FinishedAt:         j.FinishedAt,
+		GroupID:            j.GroupID,
+		ParentIDs:          j.ParentIDs,
+		RunOnParentFailure: j.RunOnParentFailure,
 	}
 }
 
+func toJobResult(raw []byte) any {
+	if len(raw) == 0 {
+		return nil
+	}
+	return json.RawMessage(raw)
+}
+
 type JobFilter struct {
 	PaginatedInput
 	SortParams
 	Ids        []string                       `query:"ids,omitempty" required:"false" minimum:"1" maximum:"100" format:"uuid"`
 	Kinds      []string                       `db:"kinds" json:"kinds" query:"kinds" required:"false" minimum:"1" maximum:"100" uniqueItems:"true"`
 	UniqueKeys []string                       `db:"unique_keys" json:"unique_keys" query:"unique_keys" required:"false" minimum:"1" maximum:"100" uniqueItems:"true"`
-	Statuses   []JobStatus                    `db:"statuses" json:"statuses" query:"statuses" required:"false" minimum:"1" maximum:"100" uniqueItems:"true" enum:"pending,processing,done,failed"`
+	Statuses   []JobStatus                    `db:"statuses" json:"statuses" query:"statuses" required:"false" minimum:"1" maximum:"100" uniqueItems:"true" enum:"pending,processing,done,failed,cancelled"`
 	RunAfter   types.OptionalParam[time.Time] `db:"run_after" json:"run_after" query:"run_after" required:"false"`
 	Attempt    types.OptionalParam[int64]     `db:"attempt" json:"attempt" query:"attempt" required:"false"`
 	LastErrors []string                       `db:"last_errors" json:"last_errors" query:"last_errors" required:"false" minimum:"1" maximum:"100" uniqueItems:"true"`
+	GroupIds   []string                       `db:"group_ids" json:"group_ids" query:"group_ids" required:"false" minimum:"1" maximum:"100" format:"uuid"`
 }
 
 func (api *Api) AdminGetJobs(
@@ -85,12 +119,13 @@ func (api *Api) AdminGetJobs(
 	filter.RunAfter = input.RunAfter
 	filter.Attempt = input.Attempt
 	filter.LastErrors = input.LastErrors
+	filter.GroupIds = utils.ParseValidUUIDs(input.GroupIds...)
 
-	jobs, err := api.app.Adapter().Job().FindJobs(ctx, filter)
+	jobs, err := api.app.JobStore().FindJobs(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
-	count, err := api.app.Adapter().Job().CountJobs(ctx, filter)
+	count, err := api.app.JobStore().CountJobs(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -111,7 +146,7 @@ func (api *Api) AdminGetJob(
 	input *FindJobInput,
 ) (*ApiOutput[*Job], error) {
 	id := uuid.MustParse(input.ID)
-	j, err := api.app.Adapter().Job().FindJob(ctx, &stores.JobFilter{
+	j, err := api.app.JobStore().FindJob(ctx, &stores.JobFilter{
 		Ids: []uuid.UUID{id},
 	})
 	if err != nil {
@@ -124,7 +159,7 @@ type JobUpdateDto struct {
 	Kind        string    `db:"kind" json:"kind,omitempty" required:"true"`
 	UniqueKey   *string   `db:"unique_key" json:"unique_key"`
 	Payload     string    `db:"payload" json:"payload"`
-	Status      JobStatus `db:"status" json:"status"`
+	Status      JobStatus `db:"status" json:"status" enum:"pending,processing,done,failed,cancelled"`
 	RunAfter    time.Time `db:"run_after" json:"run_after"`
 	Attempts    int64     `db:"attempts" json:"attempts"`
 	MaxAttempts int64     `db:"max_attempts" json:"max_attempts"`
@@ -139,12 +174,24 @@ func (api *Api) AdminUpdateJob(
 	},
 ) (*struct{}, error) {
 	id := uuid.MustParse(input.ID)
-	j, err := api.app.Adapter().Job().FindJob(ctx, &stores.JobFilter{
+	j, err := api.app.JobStore().FindJob(ctx, &stores.JobFilter{
 		Ids: []uuid.UUID{id},
 	})
 	if err != nil {
 		return nil, err
 	}
+	if j == nil {
+		return nil, huma.Error404NotFound("Job not found")
+	}
+	// cancelling goes through the job manager so that a running job gets its
+	// context cancelled instead of having its row overwritten.
+	if input.Body.Status == JobStatusCancelled && j.Status != models.JobStatusCancelled {
+		err = api.app.JobManager().Cancel(ctx, id)
+		if errors.Is(err, jobs.ErrJobNotCancellable) {
+			return nil, huma.Error409Conflict("Only pending or processing jobs can be cancelled")
+		}
+		return nil, err
+	}
 	j.Kind = input.Body.Kind
 	j.UniqueKey = input.Body.UniqueKey
 	j.Payload = []byte(input.Body.Payload)
@@ -153,9 +200,165 @@ func (api *Api) AdminUpdateJob(
 	j.Attempts = input.Body.Attempts
 	j.MaxAttempts = input.Body.MaxAttempts
 	j.LastError = input.Body.LastError
-	_, err = api.app.Adapter().Job().UpdateJob(ctx, j)
+	_, err = api.app.JobStore().UpdateJob(ctx, j)
 	if err != nil {
 		return nil, err
 	}
 	return nil, nil
 }
+
+// JobGroupStatus is the state of a job group derived from its jobs.
+type JobGroupStatus string
+
+const (
+	JobGroupStatusPending JobGroupStatus = "pending"
+	JobGroupStatusRunning JobGroupStatus = "running"
+	JobGroupStatusDone    JobGroupStatus = "done"
+	JobGroupStatusFailed  JobGroupStatus = "failed"
+)
+
+type JobGroup struct {
+	GroupID    uuid.UUID      `json:"group_id"`
+	Status     JobGroupStatus `json:"status" enum:"pending,running,done,failed"`
+	Total      int64          `json:"total"`
+	Pending    int64          `json:"pending"`
+	Processing int64          `json:"processing"`
+	Done       int64          `json:"done"`
+	Failed     int64          `json:"failed"`
+	Cancelled  int64          `json:"cancelled"`
+	CreatedAt  time.Time      `json:"created_at"`
+	FinishedAt *time.Time     `json:"finished_at"`
+	Jobs       []*Job         `json:"jobs"`
+}
+
+func ToJobGroup(g *models.JobGroup) *JobGroup {
+	if g == nil {
+		return nil
+	}
+	group := &JobGroup{
+		GroupID:    g.GroupID,
+		Total:      g.Total,
+		Pending:    g.Pending,
+		Processing: g.Processing,
+		Done:       g.Done,
+		Failed:     g.Failed,
+		Cancelled:  g.Cancelled,
+		CreatedAt:  g.CreatedAt,
+	}
+	switch {
+	case g.Pending+g.Processing == g.Total:
+		group.Status = JobGroupStatusPending
+		if g.Processing > 0 {
+			group.Status = JobGroupStatusRunning
+		}
+	case g.Pending+g.Processing > 0:
+		group.Status = JobGroupStatusRunning
+	case g.Failed+g.Cancelled > 0:
+		group.Status = JobGroupStatusFailed
+		group.FinishedAt = g.FinishedAt
+	default:
+		group.Status = JobGroupStatusDone
+		group.FinishedAt = g.FinishedAt
+	}
+	return group
+}
+
+type FindJobGroupInput struct {
+	ID string `path:"group-id" required:"true" format:"uuid"`
+}
+
+func (api *Api) AdminGetJobGroup(
+	ctx context.Context,
+	input *FindJobGroupInput,
+) (*ApiOutput[*JobGroup], error) {
+	id := uuid.MustParse(input.ID)
+	g, err := api.app.JobStore().GetJobGroup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if g == nil {
+		return nil, huma.Error404NotFound("job group not found")
+	}
+	filter := &stores.JobFilter{
+		GroupIds: []uuid.UUID{id},
+	}
+	filter.PerPage = g.Total
+	filter.SortBy = "created_at"
+	filter.SortOrder = "asc"
+	jobs, err := api.app.JobStore().FindJobs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	group := ToJobGroup(g)
+	group.Jobs = mapper.Map(jobs, ToJob)
+	return &ApiOutput[*JobGroup]{Body: group}, nil
+}
+
+type JobStatsInput struct {
+	Hours int64 `query:"hours" default:"24" minimum:"1" maximum:"720" doc:"Run durations are computed over the jobs finished in the last hours"`
+}
+
+func (api *Api) AdminGetJobStats(
+	ctx context.Context,
+	input *JobStatsInput,
+) (*ApiOutput[*models.JobStats], error) {
+	since := time.Now().Add(-time.Duration(input.Hours) * time.Hour)
+	stats, err := api.app.JobStore().GetJobStats(ctx, since, services.JobStatsWindows...)
+	if err != nil {
+		return nil, err
+	}
+	return &ApiOutput[*models.JobStats]{Body: stats}, nil
+}
+
+type JobStatsSseInput struct {
+}
+
+func (api *Api) BindAdminJobStatsSse(humapi huma.API) {
+	hanlder := sse.ServeSSE(
+		func(ctx context.Context, f sse.Sender, input *JobStatsSseInput) sse.Client {
+			return sse.NewClient(sse.AdminJobStatsChannel, f, slog.Default(), func() any {
+				return &PingMessage{
+					Message: "ping",
+				}
+			})
+		},
+		func(ctx context.Context, cf context.CancelFunc, c sse.Client) {
+			api.app.SseManager().RegisterClient(ctx, cf, c)
+			// send a first snapshot instead of waiting for the next tick
+			stats, err := api.app.JobStore().GetJobStats(
+				ctx,
+				time.Now().Add(-24*time.Hour),
+				services.JobStatsWindows...,
+			)
+			if err != nil {
+				slog.ErrorContext(ctx, "failed to get job stats", slog.Any("error", err))
+				return
+			}
+			// nolint:errcheck
+			c.Write(sse.Message{Data: services.JobStatsSseEvent{JobStats: stats}})
+		},
+		nil,
+		func(c sse.Client) {
+			api.app.SseManager().UnregisterClient(c)
+		},
+		30*time.Second,
+	)
+	sse.Register(
+		humapi,
+		huma.Operation{
+			OperationID: "admin-jobs-stats-sse",
+			Method:      http.MethodGet,
+			Path:        "/jobs/stats/sse",
+			Summary:     "Admin job stats sse",
+			Description: "Stream job stats",
+			Tags:        []string{"Admin", "Jobs"},
+			Errors:      []int{http.StatusInternalServerError, http.StatusBadRequest},
+			Security:    []map[string][]string{{shared.BearerAuthSecurityKey: {}}},
+		},
+		map[string]any{
+			"job_stats": &services.JobStatsSseEvent{},
+			"ping":      &PingMessage{},
+		},
+		hanlder,
+	)
+}
@@ -0,0 +1,84 @@
+package apis
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/auth/oauth"
+	"github.com/tkahng/playground/internal/database"
+	"github.com/tkahng/playground/internal/models"
+)
+
+type OidcProviderCreateDto struct {
+	Name         string   `json:"name" pattern:"^[a-z0-9][a-z0-9_-]*$" maxLength:"63" required:"true" doc:"the provider users sign in with, it can not be changed"`
+	DisplayName  string   `json:"display_name" required:"false"`
+	Issuer       string   `json:"issuer" format:"uri" required:"true" doc:"the issuer serving /.well-known/openid-configuration"`
+	ClientID     string   `json:"client_id" minLength:"1" required:"true"`
+	ClientSecret string   `json:"client_secret" minLength:"1" required:"true"`
+	Scopes       []string `json:"scopes,omitempty" required:"false" doc:"defaults to openid, email and profile"`
+	Enabled      *bool    `json:"enabled,omitempty" required:"false" default:"true"`
+}
+
+func (api *Api) AdminOidcProviders(ctx context.Context, input *struct{}) (*ApiOutput[[]*models.OidcProvider], error) {
+	providers, err := api.App().Adapter().OidcProvider().ListOidcProviders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &ApiOutput[[]*models.OidcProvider]{Body: providers}, nil
+}
+
+func (api *Api) AdminOidcProvidersCreate(ctx context.Context, input *struct {
+	Body OidcProviderCreateDto
+}) (*ApiOutput[*models.OidcProvider], error) {
+	if err := oauth.ValidateOIDCName(input.Body.Name); err != nil {
+		return nil, huma.Error400BadRequest(err.Error())
+	}
+	if oauth.NewProviderByName(input.Body.Name) != nil {
+		return nil, huma.Error409Conflict("provider already exists")
+	}
+	// only issuers that can be discovered are added
+	discoverCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if _, err := oauth.Discover(discoverCtx, http.DefaultClient, input.Body.Issuer); err != nil {
+		return nil, huma.Error400BadRequest(err.Error())
+	}
+	provider := &models.OidcProvider{
+		Name:         input.Body.Name,
+		DisplayName:  input.Body.DisplayName,
+		Issuer:       input.Body.Issuer,
+		ClientID:     input.Body.ClientID,
+		ClientSecret: input.Body.ClientSecret,
+		Scopes:       input.Body.Scopes,
+		Enabled:      input.Body.Enabled == nil || *input.Body.Enabled,
+	}
+	if provider.DisplayName == "" {
+		provider.DisplayName = provider.Name
+	}
+	if len(provider.Scopes) == 0 {
+		provider.Scopes = []string{"openid", "email", "profile"}
+	}
+	provider, err := api.App().Adapter().OidcProvider().CreateOidcProvider(ctx, provider)
+	if err != nil {
+		if database.IsUniqConstraintErr(err) {
+			return nil, huma.Error409Conflict("provider already exists")
+		}
+		return nil, err
+	}
+	return &ApiOutput[*models.OidcProvider]{Body: provider}, nil
+}
+
+func (api *Api) AdminOidcProvidersDelete(ctx context.Context, input *struct {
+	ProviderID string `path:"provider-id" format:"uuid" required:"true"`
+}) (*struct{}, error) {
+	id, err := uuid.Parse(input.ProviderID)
+	if err != nil {
+		return nil, huma.Error400BadRequest("invalid provider id")
+	}
+	if err := api.App().Adapter().OidcProvider().DeleteOidcProvider(ctx, id); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/google/uuid"
 	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/services"
 	"github.com/tkahng/playground/internal/stores"
 	"github.com/tkahng/playground/internal/tools/mapper"
 	"github.com/tkahng/playground/internal/tools/utils"
@@ -308,3 +309,45 @@ func (api *Api) AdminPermissionsGet(ctx context.Context, input *struct {
 		Body: FromModelPermission(permission),
 	}, nil
 }
+
+func (api *Api) AdminUserPermissionExplain(ctx context.Context, input *struct {
+	UserId     string `path:"user-id" format:"uuid" required:"true"`
+	Permission string `query:"permission" minLength:"1" required:"true"`
+}) (*ApiOutput[*services.PermissionExplanation], error) {
+	id, err := uuid.Parse(input.UserId)
+	if err != nil {
+		return nil, err
+	}
+	explanation, err := api.App().Rbac().ExplainPermission(ctx, id, input.Permission)
+	if err != nil {
+		return nil, err
+	}
+	return &ApiOutput[*services.PermissionExplanation]{
+		Body: explanation,
+	}, nil
+}
+
+type PermissionCheckInput struct {
+	UserID     uuid.UUID `json:"user_id" format:"uuid" required:"true"`
+	Permission string    `json:"permission" minLength:"1" required:"true"`
+}
+
+func (api *Api) AdminPermissionsCheck(ctx context.Context, input *struct {
+	Body struct {
+		Checks []PermissionCheckInput `json:"checks" minItems:"1" maxItems:"50" required:"true"`
+	}
+}) (*ApiOutput[[]*services.PermissionExplanation], error) {
+	checks := mapper.Map(input.Body.Checks, func(check PermissionCheckInput) services.PermissionCheck {
+		return services.PermissionCheck{
+			UserID:     check.UserID,
+			Permission: check.Permission,
+		}
+	})
+	results, err := api.App().Rbac().CheckPermissions(ctx, checks...)
+	if err != nil {
+		return nil, err
+	}
+	return &ApiOutput[[]*services.PermissionExplanation]{
+		Body: results,
+	}, nil
+}
@@ -13,7 +13,10 @@ import (
 )
 
 func BindMiddlewares(api huma.API, app core.App) {
+	api.UseMiddleware(middleware.IpAddressMiddleware(api, app))
+	api.UseMiddleware(middleware.UserAgentMiddleware(api))
 	api.UseMiddleware(middleware.AuthMiddleware(api, app))
+	api.UseMiddleware(middleware.ImpersonationMiddleware(api, app))
 	api.UseMiddleware(middleware.RequireAuthMiddleware(api))
 }
 
@@ -78,7 +81,15 @@ func BindApis(api huma.API, appApi *Api) {
 	// ---- Teams
 	BindTeamsApi(api, appApi)
 
+	// ---- sse topics
+	appApi.BindSseTopics(api)
+
+	// ---- websocket
+	appApi.BindWebsocket(api)
+
 	// ---- notifications
+	BindNotificationPreferencesApi(api, appApi)
+
 	// sse.Register(
 	// 	api,
 	// 	huma.Operation{
@@ -103,6 +114,9 @@ func BindApis(api huma.API, appApi *Api) {
 	// ---- task routes -------------------------------------------------------------------------------------------------
 	BindTaskApi(api, appApi)
 
+	// ---- games
+	BindSticksApi(api, appApi)
+
 	// stripe routes -------------------------------------------------------------------------------------------------
 
 	BindStripeApi(api, appApi)
@@ -43,7 +43,7 @@ func NewServer(opt *conf.EnvConfig) (http.Handler, huma.API) {
 		// AllowOriginFunc:  func(r *http.Request, origin string) bool { return true },
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		ExposedHeaders:   []string{"Link", shared.ImpersonatedByHeader},
 		AllowCredentials: true,
 	}))
 	r.Use(httplog.RequestLogger(logger.GetDefaultLogger(), &httplog.Options{
@@ -5,6 +5,7 @@ import (
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/tkahng/playground/internal/middleware"
+	"github.com/tkahng/playground/internal/models"
 	"github.com/tkahng/playground/internal/shared"
 )
 
@@ -13,6 +14,12 @@ func BindTaskApi(api huma.API, appApi *Api) {
 	teamFromTask := middleware.TeamInfoFromTask(api, appApi.App())
 	teamFromProject := middleware.TeamInfoFromTaskProject(api, appApi.App())
 	teamFromPath := middleware.TeamInfoFromParam(api, appApi.App())
+	canCreateProject := middleware.RequireTeamPermissionsMiddleware(api, appApi.App(), models.TeamPermissionProjectCreate)
+	canUpdateProject := middleware.RequireTeamPermissionsMiddleware(api, appApi.App(), models.TeamPermissionProjectUpdate)
+	canDeleteProject := middleware.RequireTeamPermissionsMiddleware(api, appApi.App(), models.TeamPermissionProjectDelete)
+	canCreateTask := middleware.RequireTeamPermissionsMiddleware(api, appApi.App(), models.TeamPermissionTaskCreate)
+	canUpdateTask := middleware.RequireTeamPermissionsMiddleware(api, appApi.App(), models.TeamPermissionTaskUpdate)
+	canDeleteTask := middleware.RequireTeamPermissionsMiddleware(api, appApi.App(), models.TeamPermissionTaskDelete)
 
 	taskGroup := huma.NewGroup(api)
 	// taskGroup.UseMiddleware(checkTaskOwnerMiddleware)
@@ -53,6 +60,7 @@ func BindTaskApi(api huma.API, appApi *Api) {
 			}},
 			Middlewares: huma.Middlewares{
 				teamFromTask,
+				canUpdateTask,
 			},
 		},
 		appApi.TaskUpdate,
@@ -74,6 +82,7 @@ func BindTaskApi(api huma.API, appApi *Api) {
 			}},
 			Middlewares: huma.Middlewares{
 				teamFromTask,
+				canUpdateTask,
 			},
 		},
 		appApi.UpdateTaskPositionStatus,
@@ -94,6 +103,7 @@ func BindTaskApi(api huma.API, appApi *Api) {
 			}},
 			Middlewares: huma.Middlewares{
 				teamFromTask,
+				canDeleteTask,
 			},
 		},
 		appApi.TaskDelete,
@@ -157,6 +167,7 @@ func BindTaskApi(api huma.API, appApi *Api) {
 			}},
 			Middlewares: huma.Middlewares{
 				teamFromPath,
+				canCreateProject,
 			},
 		},
 		appApi.TeamTaskProjectCreate,
@@ -177,6 +188,7 @@ func BindTaskApi(api huma.API, appApi *Api) {
 			}},
 			Middlewares: huma.Middlewares{
 				teamFromPath,
+				canCreateProject,
 			},
 		},
 		appApi.TeamTaskProjectCreateWithAi,
@@ -197,6 +209,7 @@ func BindTaskApi(api huma.API, appApi *Api) {
 			}},
 			Middlewares: huma.Middlewares{
 				teamFromProject,
+				canUpdateProject,
 			},
 		},
 		appApi.TeamTaskProjectUpdate,
@@ -217,6 +230,7 @@ func BindTaskApi(api huma.API, appApi *Api) {
 			}},
 			Middlewares: huma.Middlewares{
 				teamFromProject,
+				canDeleteProject,
 			},
 		},
 		appApi.TeamTaskProjectDelete,
@@ -257,8 +271,69 @@ func BindTaskApi(api huma.API, appApi *Api) {
 			}},
 			Middlewares: huma.Middlewares{
 				teamFromProject,
+				canCreateTask,
 			},
 		},
 		appApi.TeamTaskProjectTasksCreate,
 	)
+	// task project presence list
+	huma.Register(
+		taskProjectGroup,
+		huma.Operation{
+			OperationID: "task-project-presence-list",
+			Method:      http.MethodGet,
+			Path:        "/task-projects/{task-project-id}/presence",
+			Summary:     "Task project presence list",
+			Description: "Who is currently viewing or editing the task project",
+			Tags:        []string{"Task"},
+			Errors:      []int{http.StatusNotFound, http.StatusBadRequest},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+			Middlewares: huma.Middlewares{
+				teamFromProject,
+			},
+		},
+		appApi.TaskProjectPresenceList,
+	)
+	// task project presence update
+	huma.Register(
+		taskProjectGroup,
+		huma.Operation{
+			OperationID: "task-project-presence-update",
+			Method:      http.MethodPut,
+			Path:        "/task-projects/{task-project-id}/presence",
+			Summary:     "Task project presence update",
+			Description: "Mark the current member as viewing or editing the task project",
+			Tags:        []string{"Task"},
+			Errors:      []int{http.StatusNotFound, http.StatusBadRequest},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+			Middlewares: huma.Middlewares{
+				teamFromProject,
+			},
+		},
+		appApi.TaskProjectPresenceUpdate,
+	)
+	// task project presence delete
+	huma.Register(
+		taskProjectGroup,
+		huma.Operation{
+			OperationID: "task-project-presence-delete",
+			Method:      http.MethodDelete,
+			Path:        "/task-projects/{task-project-id}/presence",
+			Summary:     "Task project presence delete",
+			Description: "Remove the current member from the task project presence",
+			Tags:        []string{"Task"},
+			Errors:      []int{http.StatusNotFound, http.StatusBadRequest},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+			Middlewares: huma.Middlewares{
+				teamFromProject,
+			},
+		},
+		appApi.TaskProjectPresenceDelete,
+	)
 }
@@ -0,0 +1,111 @@
+package apis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/contextstore"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/services"
+	"github.com/tkahng/playground/internal/tools/mapper"
+)
+
+type TaskProjectPresence struct {
+	ProjectID    uuid.UUID                `json:"project_id"`
+	TeamMemberID uuid.UUID                `json:"team_member_id"`
+	TaskID       *uuid.UUID               `json:"task_id,omitempty"`
+	State        models.TaskPresenceState `json:"state" enum:"viewing,editing"`
+	ExpiresAt    time.Time                `json:"expires_at"`
+	UpdatedAt    time.Time                `json:"updated_at"`
+}
+
+func FromModelTaskProjectPresence(presence *models.TaskProjectPresence) *TaskProjectPresence {
+	if presence == nil {
+		return nil
+	}
+	return &TaskProjectPresence{
+		ProjectID:    presence.ProjectID,
+		TeamMemberID: presence.TeamMemberID,
+		TaskID:       presence.TaskID,
+		State:        presence.State,
+		ExpiresAt:    presence.ExpiresAt,
+		UpdatedAt:    presence.UpdatedAt,
+	}
+}
+
+type TaskProjectPresenceInput struct {
+	TaskProjectID string `path:"task-project-id" json:"task_project_id" required:"true" format:"uuid"`
+}
+
+type TaskProjectPresenceDTO struct {
+	TaskID *uuid.UUID               `json:"task_id,omitempty" required:"false" format:"uuid"`
+	State  models.TaskPresenceState `json:"state" required:"false" enum:"viewing,editing" default:"viewing"`
+}
+
+type UpdateTaskProjectPresenceInput struct {
+	TaskProjectPresenceInput
+	Body TaskProjectPresenceDTO `json:"body" required:"true"`
+}
+
+func (api *Api) TaskProjectPresenceList(ctx context.Context, input *TaskProjectPresenceInput) (*ApiOutput[[]*TaskProjectPresence], error) {
+	teamInfo := contextstore.GetContextTeamInfo(ctx)
+	if teamInfo == nil {
+		return nil, huma.Error401Unauthorized("no team info")
+	}
+	projectID, err := uuid.Parse(input.TaskProjectID)
+	if err != nil {
+		return nil, huma.Error400BadRequest("Invalid task project id")
+	}
+	presences, err := api.App().Task().FindPresences(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return &ApiOutput[[]*TaskProjectPresence]{
+		Body: mapper.Map(presences, FromModelTaskProjectPresence),
+	}, nil
+}
+
+// TaskProjectPresenceUpdate marks the current member as on the board. Clients
+// call it again before the presence expires to stay listed.
+func (api *Api) TaskProjectPresenceUpdate(ctx context.Context, input *UpdateTaskProjectPresenceInput) (*ApiOutput[*TaskProjectPresence], error) {
+	teamInfo := contextstore.GetContextTeamInfo(ctx)
+	if teamInfo == nil {
+		return nil, huma.Error401Unauthorized("no team info")
+	}
+	projectID, err := uuid.Parse(input.TaskProjectID)
+	if err != nil {
+		return nil, huma.Error400BadRequest("Invalid task project id")
+	}
+	state := input.Body.State
+	if state == "" {
+		state = models.TaskPresenceStateViewing
+	}
+	if state == models.TaskPresenceStateEditing && input.Body.TaskID == nil {
+		return nil, huma.Error400BadRequest("task_id is required when editing")
+	}
+	presence, err := api.App().Task().UpdatePresence(ctx, projectID, teamInfo.Member.ID, input.Body.TaskID, state)
+	if err != nil {
+		if errors.Is(err, services.ErrTaskNotFound) {
+			return nil, huma.Error404NotFound("Task not found")
+		}
+		return nil, err
+	}
+	return &ApiOutput[*TaskProjectPresence]{
+		Body: FromModelTaskProjectPresence(presence),
+	}, nil
+}
+
+func (api *Api) TaskProjectPresenceDelete(ctx context.Context, input *TaskProjectPresenceInput) (*struct{}, error) {
+	teamInfo := contextstore.GetContextTeamInfo(ctx)
+	if teamInfo == nil {
+		return nil, huma.Error401Unauthorized("no team info")
+	}
+	projectID, err := uuid.Parse(input.TaskProjectID)
+	if err != nil {
+		return nil, huma.Error400BadRequest("Invalid task project id")
+	}
+	return nil, api.App().Task().LeavePresence(ctx, projectID, teamInfo.Member.ID)
+}
@@ -2,16 +2,17 @@ package apis
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/google/uuid"
 	"github.com/tkahng/playground/internal/contextstore"
 	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/services"
 	"github.com/tkahng/playground/internal/stores"
 	"github.com/tkahng/playground/internal/tools/mapper"
 	"github.com/tkahng/playground/internal/tools/utils"
-	"github.com/tkahng/playground/internal/workers"
 )
 
 type Task struct {
@@ -156,71 +157,12 @@ func (api *Api) TaskUpdate(ctx context.Context, input *UpdateTaskInput) (*struct
 	if err != nil {
 		return nil, huma.Error400BadRequest("Invalid task ID")
 	}
-	task, err := api.App().Adapter().Task().FindTaskByID(ctx, id)
+	_, err = api.App().Task().UpdateTask(ctx, id, teamInfo.Member.ID, &input.Body)
 	if err != nil {
-		return nil, err
-	}
-
-	if task == nil {
-		return nil, huma.Error404NotFound("Task not found")
-	}
-	previousStatus := task.Status
-	previousDueDate := task.EndAt
-	previousAssignee := task.AssigneeID
-
-	task.Name = input.Body.Name
-	task.Description = input.Body.Description
-	task.Status = models.TaskStatus(input.Body.Status)
-	task.StartAt = input.Body.StartAt
-	task.EndAt = input.Body.EndAt
-
-	task.AssigneeID = input.Body.AssigneeID
-	task.ReporterID = input.Body.ReporterID
-	task.ParentID = input.Body.ParentID
-
-	err = api.App().Adapter().Task().UpdateTask(ctx, task)
-	if err != nil {
-		return nil, err
-	}
-
-	newAssignee := previousAssignee == nil && input.Body.AssigneeID != nil
-	differentAssignee := previousAssignee != nil && input.Body.AssigneeID != nil && *previousAssignee != *input.Body.AssigneeID
-	if newAssignee || differentAssignee {
-		err = api.App().JobService().EnqueAssignedToTaskJob(ctx, &workers.AssignedToTasJobArgs{
-			TaskID:              task.ID,
-			AssignedByMemeberID: teamInfo.Member.ID,
-			AssigneeMemberID:    *input.Body.AssigneeID,
-		})
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	newDueDate := previousDueDate == nil && task.EndAt != nil
-	differentDueDate := previousDueDate != nil && task.EndAt != nil && *previousDueDate != *task.EndAt
-	if newDueDate || differentDueDate {
-		dueDate := *task.EndAt
-		if dueDate.Before(time.Now()) {
-			dueDate = time.Now().Add(10 * time.Second)
-		}
-		err = api.App().JobService().EnqueTaskDueJob(ctx, &workers.TaskDueTodayJobArgs{
-			TaskID:  task.ID,
-			DueDate: dueDate,
-		})
-		if err != nil {
-			return nil, err
-		}
-	}
-	newDoneStatus := previousStatus != task.Status && task.Status == models.TaskStatusDone
-	if newDoneStatus {
-		err = api.App().JobService().EnqueueTaskCompletedJob(ctx, &workers.TaskCompletedJobArgs{
-			TaskID:              id,
-			CompletedByMemberID: teamInfo.Member.ID,
-			CompletedAt:         time.Now(),
-		})
-		if err != nil {
-			return nil, err
+		if errors.Is(err, services.ErrTaskNotFound) {
+			return nil, huma.Error404NotFound("Task not found")
 		}
+		return nil, err
 	}
 	return nil, nil
 }
@@ -239,29 +181,12 @@ func (api *Api) UpdateTaskPositionStatus(ctx context.Context, input *TaskPositio
 	if teamInfo == nil {
 		return nil, huma.Error401Unauthorized("team info not found")
 	}
-	task, err := api.App().Adapter().Task().FindTaskByID(ctx, id)
+	err = api.App().Task().UpdateTaskRankStatus(ctx, id, teamInfo.Member.ID, input.Body.Position, models.TaskStatus(input.Body.Status))
 	if err != nil {
-		return nil, err
-	}
-	if task == nil {
-		return nil, huma.Error404NotFound("Task not found")
-	}
-	err = api.App().Task().UpdateTaskRankStatus(ctx, id, input.Body.Position, models.TaskStatus(input.Body.Status))
-	if err != nil {
-		return nil, err
-	}
-
-	if models.TaskStatus(input.Body.Status) == models.TaskStatusDone {
-		if task.Status != models.TaskStatusDone {
-			err = api.App().JobService().EnqueueTaskCompletedJob(ctx, &workers.TaskCompletedJobArgs{
-				TaskID:              id,
-				CompletedByMemberID: teamInfo.Member.ID,
-				CompletedAt:         time.Now(),
-			})
-			if err != nil {
-				return nil, err
-			}
+		if errors.Is(err, services.ErrTaskNotFound) {
+			return nil, huma.Error404NotFound("Task not found")
 		}
+		return nil, err
 	}
 	return nil, nil
 }
@@ -270,16 +195,19 @@ func (api *Api) TaskDelete(ctx context.Context, input *struct {
 	TaskID string `path:"task-id"`
 }) (*struct{}, error) {
 
-	userInfo := contextstore.GetContextUserInfo(ctx)
-	if userInfo == nil {
-		return nil, huma.Error401Unauthorized("Unauthorized")
+	teamInfo := contextstore.GetContextTeamInfo(ctx)
+	if teamInfo == nil {
+		return nil, huma.Error401Unauthorized("team info not found")
 	}
 	id, err := uuid.Parse(input.TaskID)
 	if err != nil {
 		return nil, huma.Error400BadRequest("Invalid task ID")
 	}
-	err = api.App().Adapter().Task().DeleteTask(ctx, id)
+	err = api.App().Task().DeleteTask(ctx, id, teamInfo.Member.ID)
 	if err != nil {
+		if errors.Is(err, services.ErrTaskNotFound) {
+			return nil, huma.Error404NotFound("Task not found")
+		}
 		return nil, err
 	}
 	return nil, nil
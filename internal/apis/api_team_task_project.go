@@ -14,7 +14,6 @@ import (
 	"github.com/tkahng/playground/internal/tools/ai/googleai"
 	"github.com/tkahng/playground/internal/tools/mapper"
 	"github.com/tkahng/playground/internal/tools/utils"
-	"github.com/tkahng/playground/internal/workers"
 )
 
 type TaskProject struct {
@@ -346,19 +345,6 @@ func (api *Api) TeamTaskProjectTasksCreate(ctx context.Context, input *ApiCreate
 	if err != nil {
 		return nil, err
 	}
-	if task.EndAt != nil {
-		taskDue := *task.EndAt
-		if taskDue.Before(time.Now()) {
-			taskDue = time.Now().Add(10 * time.Second)
-		}
-		err = api.App().JobService().EnqueTaskDueJob(ctx, &workers.TaskDueTodayJobArgs{
-			TaskID:  task.ID,
-			DueDate: taskDue,
-		})
-		if err != nil {
-			return nil, huma.Error500InternalServerError("Failed to create task project update date job")
-		}
-	}
 	err = api.App().Adapter().Task().UpdateTaskProjectUpdateDate(ctx, parsedProjectID)
 	if err != nil {
 		return nil, huma.Error500InternalServerError("Failed to update task project update date")
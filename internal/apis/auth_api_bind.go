@@ -4,10 +4,12 @@ import (
 	"net/http"
 
 	"github.com/danielgtaylor/huma/v2"
+	"github.com/tkahng/playground/internal/middleware"
 	"github.com/tkahng/playground/internal/shared"
 )
 
 func BindAuthApi(api huma.API, appApi *Api) {
+	refuseImpersonation := middleware.RefuseImpersonationMiddleware(api)
 	huma.Register(
 		api,
 		huma.Operation{
@@ -17,7 +19,7 @@ func BindAuthApi(api huma.API, appApi *Api) {
 			Summary:     "Sign up",
 			Description: "Count the number of colors for all themes",
 			Tags:        []string{"Auth"},
-			Errors:      []int{http.StatusNotFound},
+			Errors:      []int{http.StatusNotFound, http.StatusTooManyRequests},
 		},
 		appApi.SignUp,
 	)
@@ -31,7 +33,7 @@ func BindAuthApi(api huma.API, appApi *Api) {
 			Summary:     "Sign in",
 			Description: "Count the number of colors for all themes",
 			Tags:        []string{"Auth"},
-			Errors:      []int{http.StatusNotFound},
+			Errors:      []int{http.StatusNotFound, http.StatusTooManyRequests},
 		},
 		appApi.SignIn,
 	)
@@ -62,10 +64,13 @@ func BindAuthApi(api huma.API, appApi *Api) {
 			Summary:     "Me Update",
 			Description: "Me Update",
 			Tags:        []string{"Auth"},
-			Errors:      []int{http.StatusUnauthorized, http.StatusNotFound},
+			Errors:      []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
 			Security: []map[string][]string{{
 				shared.BearerAuthSecurityKey: {},
 			}},
+			Middlewares: huma.Middlewares{
+				refuseImpersonation,
+			},
 		},
 		appApi.MeUpdate,
 	)
@@ -79,13 +84,143 @@ func BindAuthApi(api huma.API, appApi *Api) {
 			Summary:     "Me delete",
 			Description: "Me delete",
 			Tags:        []string{"Auth", "Me"},
-			Errors:      []int{http.StatusUnauthorized, http.StatusNotFound},
+			Errors:      []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
 			Security: []map[string][]string{{
 				shared.BearerAuthSecurityKey: {},
 			}},
+			Middlewares: huma.Middlewares{
+				refuseImpersonation,
+			},
 		},
 		appApi.MeDelete,
 	)
+	// me sessions -------------------------------------------------------------
+	huma.Register(
+		api,
+		huma.Operation{
+			OperationID: "me-sessions",
+			Method:      http.MethodGet,
+			Path:        "/auth/me/sessions",
+			Summary:     "Me sessions",
+			Description: "List the devices the user is signed in on",
+			Tags:        []string{"Auth", "Me"},
+			Errors:      []int{http.StatusUnauthorized, http.StatusNotFound},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+		},
+		appApi.MeSessions,
+	)
+	huma.Register(
+		api,
+		huma.Operation{
+			OperationID: "me-session-delete",
+			Method:      http.MethodDelete,
+			Path:        "/auth/me/sessions/{session-id}",
+			Summary:     "Me session delete",
+			Description: "Sign a device out, its tokens are rejected from the next request on",
+			Tags:        []string{"Auth", "Me"},
+			Errors:      []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusBadRequest},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+			Middlewares: huma.Middlewares{
+				refuseImpersonation,
+			},
+		},
+		appApi.MeSessionDelete,
+	)
+	huma.Register(
+		api,
+		huma.Operation{
+			OperationID: "me-sessions-delete",
+			Method:      http.MethodDelete,
+			Path:        "/auth/me/sessions",
+			Summary:     "Sign out everywhere",
+			Description: "Sign out of every device, including this one",
+			Tags:        []string{"Auth", "Me"},
+			Errors:      []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+			Middlewares: huma.Middlewares{
+				refuseImpersonation,
+			},
+		},
+		appApi.MeSessionsDelete,
+	)
+	// me accounts -------------------------------------------------------------
+	huma.Register(
+		api,
+		huma.Operation{
+			OperationID: "me-accounts",
+			Method:      http.MethodGet,
+			Path:        "/auth/me/accounts",
+			Summary:     "Me accounts",
+			Description: "List the providers the user can sign in with",
+			Tags:        []string{"Auth", "Me"},
+			Errors:      []int{http.StatusUnauthorized, http.StatusNotFound},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+		},
+		appApi.MeAccounts,
+	)
+	huma.Register(
+		api,
+		huma.Operation{
+			OperationID: "me-account-link",
+			Method:      http.MethodPost,
+			Path:        "/auth/me/accounts",
+			Summary:     "Me account link",
+			Description: "Get the authorization url that links a provider account to the user, the callback redirects without signing in",
+			Tags:        []string{"Auth", "Me", "OAuth2"},
+			Errors:      []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusBadRequest},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+			Middlewares: huma.Middlewares{
+				refuseImpersonation,
+			},
+		},
+		appApi.MeAccountLink,
+	)
+	huma.Register(
+		api,
+		huma.Operation{
+			OperationID: "me-account-unlink",
+			Method:      http.MethodDelete,
+			Path:        "/auth/me/accounts/{provider}",
+			Summary:     "Me account unlink",
+			Description: "Unlink a provider account, the last way to sign in cannot be unlinked",
+			Tags:        []string{"Auth", "Me"},
+			Errors:      []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+			Middlewares: huma.Middlewares{
+				refuseImpersonation,
+			},
+		},
+		appApi.MeAccountUnlink,
+	)
+	// end impersonation -------------------------------------------------------------
+	huma.Register(
+		api,
+		huma.Operation{
+			OperationID: "end-impersonation",
+			Method:      http.MethodDelete,
+			Path:        "/auth/impersonation",
+			Summary:     "End impersonation",
+			Description: "End the impersonation of the access token and get the tokens of the superuser session back",
+			Tags:        []string{"Auth", "Impersonation"},
+			Errors:      []int{http.StatusUnauthorized, http.StatusBadRequest},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+		},
+		appApi.EndImpersonation,
+	)
 	// refresh token -------------------------------------------------------------
 	huma.Register(
 		api,
@@ -127,7 +262,7 @@ func BindAuthApi(api huma.API, appApi *Api) {
 			Summary:     "Verify",
 			Description: "Verify",
 			Tags:        []string{"Auth", "Verify"},
-			Errors:      []int{http.StatusNotFound, http.StatusBadRequest},
+			Errors:      []int{http.StatusNotFound, http.StatusBadRequest, http.StatusTooManyRequests},
 		},
 		appApi.Verify,
 	)
@@ -142,7 +277,7 @@ func BindAuthApi(api huma.API, appApi *Api) {
 			Summary:     "Verify",
 			Description: "Verify",
 			Tags:        []string{"Auth", "Verify"},
-			Errors:      []int{http.StatusNotFound, http.StatusBadRequest},
+			Errors:      []int{http.StatusNotFound, http.StatusBadRequest, http.StatusTooManyRequests},
 		},
 		appApi.VerifyPost,
 	)
@@ -163,6 +298,37 @@ func BindAuthApi(api huma.API, appApi *Api) {
 		},
 		appApi.RequestVerification,
 	)
+	// magic link -------------------------------------------------------------
+	huma.Register(
+		api,
+		huma.Operation{
+			OperationID: "magic-link",
+			Method:      http.MethodPost,
+			Path:        "/auth/magic-link",
+			Summary:     "Request magic link",
+			Description: "Email a sign in link and code, emails without a user sign up by redeeming it",
+			Tags:        []string{"Auth"},
+			Errors:      []int{http.StatusForbidden, http.StatusTooManyRequests},
+			Middlewares: huma.Middlewares{
+				refuseImpersonation,
+			},
+		},
+		appApi.MagicLink,
+	)
+	// magic link verify -------------------------------------------------------------
+	huma.Register(
+		api,
+		huma.Operation{
+			OperationID: "magic-link-verify",
+			Method:      http.MethodPost,
+			Path:        "/auth/magic-link/verify",
+			Summary:     "Redeem magic link",
+			Description: "Sign in with the link or the code of a magic link email",
+			Tags:        []string{"Auth"},
+			Errors:      []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests},
+		},
+		appApi.MagicLinkVerify,
+	)
 	// request password reset -------------------------------------------------------------
 	huma.Register(
 		api,
@@ -173,7 +339,7 @@ func BindAuthApi(api huma.API, appApi *Api) {
 			Summary:     "Request password reset",
 			Description: "Request password reset",
 			Tags:        []string{"Auth"},
-			Errors:      []int{http.StatusNotFound},
+			Errors:      []int{http.StatusNotFound, http.StatusTooManyRequests},
 		},
 		appApi.RequestPasswordReset,
 	)
@@ -215,7 +381,10 @@ func BindAuthApi(api huma.API, appApi *Api) {
 			Summary:     "Reset Password",
 			Description: "Reset Password",
 			Tags:        []string{"Auth"},
-			Errors:      []int{http.StatusNotFound},
+			Errors:      []int{http.StatusNotFound, http.StatusForbidden},
+			Middlewares: huma.Middlewares{
+				refuseImpersonation,
+			},
 		},
 		appApi.ResetPassword,
 	)
@@ -229,7 +398,7 @@ func BindAuthApi(api huma.API, appApi *Api) {
 			Summary:     "OAuth2 Callback (GET)",
 			Description: "Handle OAuth2 callback (GET)",
 			Tags:        []string{"Auth", "OAuth2"},
-			Errors:      []int{http.StatusNotFound},
+			Errors:      []int{http.StatusNotFound, http.StatusConflict},
 		},
 		appApi.OAuth2CallbackGet,
 	)
@@ -243,7 +412,7 @@ func BindAuthApi(api huma.API, appApi *Api) {
 			Summary:     "OAuth2 Callback (POST)",
 			Description: "Handle OAuth2 callback (POST)",
 			Tags:        []string{"Auth", "OAuth2"},
-			Errors:      []int{http.StatusNotFound},
+			Errors:      []int{http.StatusNotFound, http.StatusConflict},
 		},
 		appApi.OAuth2CallbackPost,
 	)
@@ -261,4 +430,18 @@ func BindAuthApi(api huma.API, appApi *Api) {
 		},
 		appApi.OAuth2AuthorizationUrl,
 	)
+
+	huma.Register(
+		api,
+		huma.Operation{
+			OperationID: "sso-url",
+			Method:      http.MethodGet,
+			Path:        "/auth/sso-url",
+			Summary:     "SSO URL",
+			Description: "Get the authorization URL of the IdP of the team that verified the domain of an email",
+			Tags:        []string{"Auth", "OAuth2"},
+			Errors:      []int{http.StatusNotFound},
+		},
+		appApi.SsoUrl,
+	)
 }
@@ -0,0 +1,30 @@
+package apis
+
+import (
+	"context"
+	"errors"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/tkahng/playground/internal/contextstore"
+	"github.com/tkahng/playground/internal/services"
+)
+
+func (api *Api) EndImpersonation(ctx context.Context, input *struct{}) (*AuthenticatedInfoResponse, error) {
+	info := contextstore.GetContextUserInfo(ctx)
+	if info == nil {
+		return nil, huma.Error401Unauthorized("unauthorized")
+	}
+	tokens, err := api.App().Auth().EndImpersonation(ctx, info)
+	if err != nil {
+		if errors.Is(err, services.ErrNotImpersonating) {
+			return nil, huma.Error400BadRequest(err.Error())
+		}
+		if errors.Is(err, services.ErrImpersonationEnded) || errors.Is(err, services.ErrSessionRevoked) {
+			return nil, huma.Error401Unauthorized(err.Error())
+		}
+		return nil, err
+	}
+	return &AuthenticatedInfoResponse{
+		Body: *ToApiUserInfoTokens(tokens),
+	}, nil
+}
@@ -0,0 +1,121 @@
+package apis_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/humatest"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/tkahng/playground/internal/apis"
+	"github.com/tkahng/playground/internal/conf"
+	"github.com/tkahng/playground/internal/core"
+	"github.com/tkahng/playground/internal/middleware"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/services"
+	"github.com/tkahng/playground/internal/shared"
+)
+
+func TestImpersonation_RefusesLastingChanges(t *testing.T) {
+	admin := uuid.New()
+	info := &models.UserInfo{
+		User:          models.User{ID: uuid.New(), Email: "impersonated@example.com"},
+		Impersonation: &models.Impersonation{ID: uuid.New(), ImpersonatorID: admin, Expires: time.Now().Add(time.Minute)},
+	}
+	var recorded []string
+	auth := &services.AuthServiceDecorator{
+		HandleAccessTokenFunc: func(ctx context.Context, token string) (*models.UserInfo, error) {
+			return info, nil
+		},
+		RecordImpersonatedRequestFunc: func(ctx context.Context, info *models.UserInfo, method string, path string) (uuid.UUID, error) {
+			recorded = append(recorded, method+" "+path)
+			return uuid.New(), nil
+		},
+		CompleteImpersonatedRequestFunc: func(ctx context.Context, logId uuid.UUID, status int) error {
+			return nil
+		},
+		CreateLinkAccountUrlFunc: func(ctx context.Context, userId uuid.UUID, provider models.Providers, redirectUrl string) (string, error) {
+			t.Fatal("impersonating superusers cannot link accounts")
+			return "", nil
+		},
+	}
+	cfg := conf.ZeroEnvConfig()
+	app := &core.BaseAppDecorator{
+		AuthFunc: func() services.AuthService { return auth },
+		CfgFunc:  func() *conf.EnvConfig { return &cfg },
+	}
+	_, api := humatest.New(t)
+	apis.BindMiddlewares(api, app)
+	apis.BindAuthApi(api, apis.NewApi(app))
+
+	resp := api.Post("/auth/me/accounts", "Authorization: Bearer token", map[string]any{"provider": "github"})
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Equal(t, admin.String(), resp.Header().Get(shared.ImpersonatedByHeader))
+
+	resp = api.Delete("/auth/me/accounts/github", "Authorization: Bearer token")
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Equal(t, []string{"POST /auth/me/accounts", "DELETE /auth/me/accounts/github"}, recorded)
+
+	// changes to the account and its sessions outlive the impersonation too
+	for _, resp := range []*httptest.ResponseRecorder{
+		api.Put("/auth/me", "Authorization: Bearer token", map[string]any{"name": "renamed"}),
+		api.Delete("/auth/me", "Authorization: Bearer token"),
+		api.Delete("/auth/me/sessions/"+uuid.NewString(), "Authorization: Bearer token"),
+		api.Delete("/auth/me/sessions", "Authorization: Bearer token"),
+		api.Post("/auth/magic-link", "Authorization: Bearer token", map[string]any{"email": info.User.Email}),
+	} {
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	}
+}
+
+func TestImpersonation_AuditsBeforeHandling(t *testing.T) {
+	info := &models.UserInfo{
+		User:          models.User{ID: uuid.New(), Email: "impersonated@example.com"},
+		Impersonation: &models.Impersonation{ID: uuid.New(), ImpersonatorID: uuid.New(), Expires: time.Now().Add(time.Minute)},
+	}
+	logId := uuid.New()
+	var recorded []string
+	completed := map[uuid.UUID]int{}
+	auth := &services.AuthServiceDecorator{
+		HandleAccessTokenFunc: func(ctx context.Context, token string) (*models.UserInfo, error) {
+			return info, nil
+		},
+		RecordImpersonatedRequestFunc: func(ctx context.Context, info *models.UserInfo, method string, path string) (uuid.UUID, error) {
+			recorded = append(recorded, method+" "+path)
+			return logId, nil
+		},
+		CompleteImpersonatedRequestFunc: func(ctx context.Context, id uuid.UUID, status int) error {
+			completed[id] = status
+			return nil
+		},
+	}
+	cfg := conf.ZeroEnvConfig()
+	app := &core.BaseAppDecorator{
+		AuthFunc: func() services.AuthService { return auth },
+		CfgFunc:  func() *conf.EnvConfig { return &cfg },
+	}
+	_, api := humatest.New(t)
+	apis.BindMiddlewares(api, app)
+	// authenticated like the websocket, by the operation instead of Security
+	huma.Register(api, huma.Operation{
+		OperationID: "stream",
+		Method:      http.MethodGet,
+		Path:        "/stream",
+		Middlewares: huma.Middlewares{
+			middleware.RequireTokenAuthMiddleware(api, app, middleware.HumaWebsocketTokenFuncs...),
+		},
+	}, func(ctx context.Context, input *struct{}) (*struct{}, error) {
+		assert.Equal(t, []string{"GET /stream"}, recorded, "the request is recorded before it is handled")
+		assert.Empty(t, completed)
+		return nil, nil
+	})
+
+	resp := api.Get("/stream?access_token=token")
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Equal(t, []string{"GET /stream"}, recorded, "requests are recorded once")
+	assert.Equal(t, map[uuid.UUID]int{logId: http.StatusNoContent}, completed)
+}
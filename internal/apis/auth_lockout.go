@@ -0,0 +1,36 @@
+package apis
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/services"
+)
+
+// loginThrottled turns refused attempts into a 429 telling the client when to
+// retry, it returns nil for other errors.
+func loginThrottled(err error) error {
+	var throttled *services.LoginThrottledError
+	if !errors.As(err, &throttled) {
+		return nil
+	}
+	seconds := int(throttled.RetryAfter().Seconds())
+	return huma.ErrorWithHeaders(
+		huma.Error429TooManyRequests(throttled.Error()),
+		http.Header{"Retry-After": []string{strconv.Itoa(seconds)}},
+	)
+}
+
+func (api *Api) AdminUserUnlock(ctx context.Context, input *struct {
+	UserID string `path:"user-id" format:"uuid" required:"true"`
+}) (*struct{}, error) {
+	userID, err := uuid.Parse(input.UserID)
+	if err != nil {
+		return nil, huma.Error400BadRequest("invalid user id")
+	}
+	return nil, api.App().Auth().UnlockUser(ctx, userID)
+}
@@ -0,0 +1,67 @@
+package apis
+
+import (
+	"context"
+	"errors"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/services"
+)
+
+type MagicLinkInput struct {
+	Email string `json:"email" form:"email" format:"email" required:"true" example:"tkahng+01@gmail.com"`
+}
+
+// MagicLink emails a sign in link and code. It succeeds for emails without a
+// user too, they sign up by redeeming it.
+func (api *Api) MagicLink(ctx context.Context, input *struct{ Body *MagicLinkInput }) (*struct{}, error) {
+	err := api.App().Auth().HandleMagicLinkRequest(ctx, input.Body.Email)
+	if err != nil {
+		if err := ssoForbidden(err); err != nil {
+			return nil, err
+		}
+		if err := loginThrottled(err); err != nil {
+			return nil, err
+		}
+		return nil, err
+	}
+	return nil, nil
+}
+
+type MagicLinkVerifyInput struct {
+	Token string `json:"token,omitempty" form:"token" required:"false" doc:"the token of the link"`
+	Email string `json:"email,omitempty" form:"email" required:"false" doc:"the email the code was sent to"`
+	Code  string `json:"code,omitempty" form:"code" required:"false" doc:"the code of the email"`
+}
+
+// MagicLinkVerify signs in with the link or the code of a magic link email.
+func (api *Api) MagicLinkVerify(ctx context.Context, input *struct{ Body *MagicLinkVerifyInput }) (*AuthenticatedInfoResponse, error) {
+	action := api.App().Auth()
+	body := input.Body
+	var tokens *models.UserInfoTokens
+	var err error
+	switch {
+	case body.Token != "":
+		tokens, err = action.HandleMagicLinkToken(ctx, body.Token)
+	case body.Email != "" && body.Code != "":
+		tokens, err = action.HandleMagicLinkCode(ctx, body.Email, body.Code)
+	default:
+		return nil, huma.Error400BadRequest("either the token or the email and code are required")
+	}
+	if err != nil {
+		if err := ssoForbidden(err); err != nil {
+			return nil, err
+		}
+		if err := loginThrottled(err); err != nil {
+			return nil, err
+		}
+		if errors.Is(err, services.ErrMagicLinkInvalid) {
+			return nil, huma.Error401Unauthorized(services.ErrMagicLinkInvalid.Error())
+		}
+		return nil, err
+	}
+	return &AuthenticatedInfoResponse{
+		Body: *ToApiUserInfoTokens(tokens),
+	}, nil
+}
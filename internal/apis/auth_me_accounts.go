@@ -0,0 +1,59 @@
+package apis
+
+import (
+	"context"
+	"errors"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/tkahng/playground/internal/contextstore"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/services"
+	"github.com/tkahng/playground/internal/tools/mapper"
+)
+
+func (api *Api) MeAccounts(ctx context.Context, input *struct{}) (*ApiOutput[[]*UserAccountOutput], error) {
+	claims := contextstore.GetContextUserInfo(ctx)
+	if claims == nil {
+		return nil, huma.Error404NotFound("User not found")
+	}
+	accounts, err := api.App().Auth().Accounts(ctx, claims.User.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &ApiOutput[[]*UserAccountOutput]{
+		Body: mapper.Map(accounts, FromModelUserAccountOutput),
+	}, nil
+}
+
+func (api *Api) MeAccountLink(ctx context.Context, input *struct {
+	Body OAuth2AuthorizationUrlInput
+}) (*OAuth2AuthorizationUrlOutput, error) {
+	claims := contextstore.GetContextUserInfo(ctx)
+	if claims == nil {
+		return nil, huma.Error404NotFound("User not found")
+	}
+	res, err := api.App().Auth().CreateLinkAccountUrl(ctx, claims.User.ID, input.Body.Provider, input.Body.RedirectTo)
+	if err != nil {
+		return nil, err
+	}
+	output := &OAuth2AuthorizationUrlOutput{}
+	output.Body.Url = res
+	return output, nil
+}
+
+func (api *Api) MeAccountUnlink(ctx context.Context, input *struct {
+	Provider models.Providers `path:"provider" pattern:"^[a-z0-9][a-z0-9_:-]*$" maxLength:"63" required:"true"`
+}) (*struct{}, error) {
+	claims := contextstore.GetContextUserInfo(ctx)
+	if claims == nil {
+		return nil, huma.Error404NotFound("User not found")
+	}
+	err := api.App().Auth().UnlinkAccount(ctx, claims.User.ID, input.Provider)
+	if errors.Is(err, services.ErrAccountNotFound) {
+		return nil, huma.Error404NotFound(err.Error())
+	}
+	if errors.Is(err, services.ErrLastSignInMethod) {
+		return nil, huma.Error409Conflict(err.Error())
+	}
+	return nil, err
+}
@@ -2,12 +2,16 @@ package apis
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/tkahng/playground/internal/auth/oauth"
 	"github.com/tkahng/playground/internal/models"
 	"github.com/tkahng/playground/internal/services"
+	"github.com/tkahng/playground/internal/shared"
 )
 
 func (api *Api) OAuth2CallbackPost(ctx context.Context, input *OAuth2CallbackInput) (*AuthenticatedInfoResponse, error) {
@@ -21,9 +25,12 @@ func (api *Api) OAuth2CallbackPost(ctx context.Context, input *OAuth2CallbackInp
 	if err != nil {
 		return nil, err
 	}
-	q := uri.Query()
-	q.Add(string(models.TokenTypesRefreshToken), dto.Tokens.RefreshToken)
-	uri.RawQuery = q.Encode()
+	// link flows only attach the account, they issue no tokens
+	if dto.Tokens.RefreshToken != "" {
+		q := uri.Query()
+		q.Add(string(models.TokenTypesRefreshToken), dto.Tokens.RefreshToken)
+		uri.RawQuery = q.Encode()
+	}
 	fmt.Println(uri.String())
 
 	return &AuthenticatedInfoResponse{
@@ -53,9 +60,12 @@ func (api *Api) OAuth2CallbackGet(ctx context.Context, input *OAuth2CallbackInpu
 	if err != nil {
 		return nil, err
 	}
-	q := uri.Query()
-	q.Add(string(models.TokenTypesRefreshToken), dto.Tokens.RefreshToken)
-	uri.RawQuery = q.Encode()
+	// link flows only attach the account, they issue no tokens
+	if dto.Tokens.RefreshToken != "" {
+		q := uri.Query()
+		q.Add(string(models.TokenTypesRefreshToken), dto.Tokens.RefreshToken)
+		uri.RawQuery = q.Encode()
+	}
 	fmt.Println(uri.String())
 
 	return &OAuth2CallbackGetResponse{
@@ -105,6 +115,9 @@ func OAuth2Callback(ctx context.Context, api *Api, input *OAuth2CallbackInput) (
 	}
 	authUser, err := action.FetchAuthUser(ctx, input.Code, parsedState)
 	if err != nil {
+		if errors.Is(err, oauth.ErrOIDCEmailNotVerified) {
+			return nil, huma.Error403Forbidden(err.Error())
+		}
 		return nil, fmt.Errorf("error at Oatuh2Callback: %w", err)
 	}
 	params := &services.AuthenticationInput{
@@ -118,8 +131,20 @@ func OAuth2Callback(ctx context.Context, api *Api, input *OAuth2CallbackInput) (
 		AccessToken:       &authUser.AccessToken,
 		RefreshToken:      &authUser.RefreshToken,
 	}
+	if parsedState.Intent == shared.StateIntentLink {
+		return linkAccountCallback(ctx, api, parsedState, params)
+	}
 	user, err := action.Authenticate(ctx, params)
 	if err != nil {
+		if err := ssoForbidden(err); err != nil {
+			return nil, err
+		}
+		if errors.Is(err, services.ErrEmailRequired) {
+			return nil, huma.Error400BadRequest(err.Error())
+		}
+		if errors.Is(err, services.ErrProviderAccountMismatch) {
+			return nil, huma.Error409Conflict(err.Error())
+		}
 		return nil, fmt.Errorf("error at Oatuh2Callback: %w", err)
 
 	}
@@ -132,3 +157,25 @@ func OAuth2Callback(ctx context.Context, api *Api, input *OAuth2CallbackInput) (
 		RedirectTo:        parsedState.RedirectTo,
 	}, nil
 }
+
+// linkAccountCallback finishes a link flow started from the profile, the
+// provider account is attached to the user that started it. No tokens are
+// issued, whoever holds the state of the flow must not sign in as the user.
+func linkAccountCallback(ctx context.Context, api *Api, parsedState *shared.ProviderStateClaims, params *services.AuthenticationInput) (*CallbackOutput, error) {
+	action := api.App().Auth()
+	user, err := action.LinkAccount(ctx, parsedState.UserId, params)
+	if err != nil {
+		if errors.Is(err, services.ErrAccountAlreadyLinked) || errors.Is(err, services.ErrAccountLinkedToOtherUser) {
+			return nil, huma.Error409Conflict(err.Error())
+		}
+		return nil, fmt.Errorf("error linking account: %w", err)
+	}
+	return &CallbackOutput{
+		ApiUserInfoTokens: ApiUserInfoTokens{
+			ApiUserInfo: ApiUserInfo{
+				User: *FromUserModel(user),
+			},
+		},
+		RedirectTo: parsedState.RedirectTo,
+	}, nil
+}
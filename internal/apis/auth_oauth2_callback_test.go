@@ -0,0 +1,60 @@
+package apis_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkahng/playground/internal/apis"
+	"github.com/tkahng/playground/internal/auth/oauth"
+	"github.com/tkahng/playground/internal/core"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/services"
+	"github.com/tkahng/playground/internal/shared"
+)
+
+func TestOAuth2Callback_LinkIssuesNoTokens(t *testing.T) {
+	ctx := context.Background()
+	user := &models.User{ID: uuid.New(), Email: "link@example.com"}
+	var linked *services.AuthenticationInput
+	auth := &services.AuthServiceDecorator{
+		VerifyStateTokenFunc: func(ctx context.Context, token string) (*shared.ProviderStateClaims, error) {
+			return &shared.ProviderStateClaims{ProviderStatePayload: shared.ProviderStatePayload{
+				Intent:     shared.StateIntentLink,
+				UserId:     user.ID,
+				Type:       models.TokenTypesStateToken,
+				Provider:   models.ProvidersGithub,
+				RedirectTo: "https://app.example.com/settings",
+			}}, nil
+		},
+		FetchAuthUserFunc: func(ctx context.Context, code string, parsedState *shared.ProviderStateClaims) (*oauth.AuthUser, error) {
+			return &oauth.AuthUser{Id: "github-1", Email: "attacker@example.com"}, nil
+		},
+		LinkAccountFunc: func(ctx context.Context, userId uuid.UUID, params *services.AuthenticationInput) (*models.User, error) {
+			linked = params
+			return user, nil
+		},
+		CreateAuthTokensFromEmailFunc: func(ctx context.Context, email string) (*models.UserInfoTokens, error) {
+			t.Fatal("the link flow must not issue tokens")
+			return nil, nil
+		},
+	}
+	appApi := apis.NewApi(&core.BaseAppDecorator{
+		AuthFunc: func() services.AuthService { return auth },
+	})
+
+	output, err := apis.OAuth2Callback(ctx, appApi, &apis.OAuth2CallbackInput{Code: "code", State: "state"})
+	require.NoError(t, err)
+	require.NotNil(t, linked)
+	assert.Equal(t, "github-1", linked.ProviderAccountID)
+	assert.Equal(t, user.ID, output.User.ID)
+	assert.Empty(t, output.Tokens.AccessToken)
+	assert.Empty(t, output.Tokens.RefreshToken)
+	assert.Equal(t, "https://app.example.com/settings", output.RedirectTo)
+
+	redirect, err := appApi.OAuth2CallbackGet(ctx, &apis.OAuth2CallbackInput{Code: "code", State: "state"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://app.example.com/settings", redirect.Url)
+}
@@ -8,7 +8,7 @@ import (
 )
 
 type OAuth2AuthorizationUrlInput struct {
-	Provider   models.Providers `json:"provider"  query:"provider" form:"provider" enum:"google,github" required:"true"`
+	Provider   models.Providers `json:"provider"  query:"provider" form:"provider" pattern:"^[a-z0-9][a-z0-9_-]*$" maxLength:"63" required:"true" doc:"google, github or the name of an OIDC provider"`
 	RedirectTo string           `json:"redirect_to" query:"redirect_to" form:"redirect_to" format:"uri" required:"false"`
 }
 
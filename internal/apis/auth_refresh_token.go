@@ -2,6 +2,10 @@ package apis
 
 import (
 	"context"
+	"errors"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/tkahng/playground/internal/services"
 )
 
 type RefreshTokenInput struct {
@@ -12,6 +16,9 @@ func (api *Api) RefreshToken(ctx context.Context, input *struct{ Body *RefreshTo
 	action := api.App().Auth()
 	claims, err := action.HandleRefreshToken(ctx, input.Body.RefreshToken)
 	if err != nil {
+		if errors.Is(err, services.ErrRefreshTokenReused) || errors.Is(err, services.ErrSessionRevoked) {
+			return nil, huma.Error401Unauthorized(err.Error())
+		}
 		return nil, err
 	}
 
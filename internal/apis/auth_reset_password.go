@@ -27,6 +27,9 @@ func (api *Api) RequestPasswordReset(ctx context.Context, input *struct{ Body *R
 	action := api.App().Auth()
 	err = action.HandlePasswordResetRequest(ctx, input.Body.Email)
 	if err != nil {
+		if err := loginThrottled(err); err != nil {
+			return nil, err
+		}
 		return nil, err
 	}
 	return nil, nil
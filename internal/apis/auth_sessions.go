@@ -0,0 +1,132 @@
+package apis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/contextstore"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/services"
+	"github.com/tkahng/playground/internal/tools/mapper"
+)
+
+type UserSession struct {
+	ID         uuid.UUID `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	Device     string    `json:"device"`
+	IPAddress  string    `json:"ip_address"`
+	City       string    `json:"city"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	Expires    time.Time `json:"expires"`
+	// Current is the session of the request.
+	Current bool `json:"current"`
+}
+
+func FromUserSessionModel(current uuid.UUID) func(s *models.UserSession) *UserSession {
+	return func(s *models.UserSession) *UserSession {
+		return &UserSession{
+			ID:         s.ID,
+			UserAgent:  s.UserAgent,
+			Device:     s.Device,
+			IPAddress:  s.IPAddress,
+			City:       s.City,
+			LastSeenAt: s.LastSeenAt,
+			CreatedAt:  s.CreatedAt,
+			Expires:    s.Expires,
+			Current:    current != uuid.Nil && s.ID == current,
+		}
+	}
+}
+
+type SessionIDInput struct {
+	SessionID string `path:"session-id" format:"uuid" required:"true"`
+}
+
+func (api *Api) MeSessions(ctx context.Context, input *struct{}) (*ApiOutput[[]*UserSession], error) {
+	claims := contextstore.GetContextUserInfo(ctx)
+	if claims == nil {
+		return nil, huma.Error404NotFound("User not found")
+	}
+	sessions, err := api.App().Auth().Sessions(ctx, claims.User.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &ApiOutput[[]*UserSession]{
+		Body: mapper.Map(sessions, FromUserSessionModel(claims.SessionID)),
+	}, nil
+}
+
+func (api *Api) MeSessionDelete(ctx context.Context, input *SessionIDInput) (*struct{}, error) {
+	claims := contextstore.GetContextUserInfo(ctx)
+	if claims == nil {
+		return nil, huma.Error404NotFound("User not found")
+	}
+	return nil, api.revokeSession(ctx, claims.User.ID, input.SessionID)
+}
+
+// MeSessionsDelete signs the user out everywhere, including the session of
+// the request.
+func (api *Api) MeSessionsDelete(ctx context.Context, input *struct{}) (*struct{}, error) {
+	claims := contextstore.GetContextUserInfo(ctx)
+	if claims == nil {
+		return nil, huma.Error404NotFound("User not found")
+	}
+	return nil, api.App().Auth().RevokeSessions(ctx, claims.User.ID)
+}
+
+func (api *Api) AdminUserSessions(ctx context.Context, input *struct {
+	UserID string `path:"user-id" format:"uuid" required:"true"`
+}) (*ApiOutput[[]*UserSession], error) {
+	userID, err := uuid.Parse(input.UserID)
+	if err != nil {
+		return nil, huma.Error400BadRequest("invalid user id")
+	}
+	sessions, err := api.App().Auth().Sessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var current uuid.UUID
+	if claims := contextstore.GetContextUserInfo(ctx); claims != nil {
+		current = claims.SessionID
+	}
+	return &ApiOutput[[]*UserSession]{
+		Body: mapper.Map(sessions, FromUserSessionModel(current)),
+	}, nil
+}
+
+func (api *Api) AdminUserSessionDelete(ctx context.Context, input *struct {
+	UserID    string `path:"user-id" format:"uuid" required:"true"`
+	SessionID string `path:"session-id" format:"uuid" required:"true"`
+}) (*struct{}, error) {
+	userID, err := uuid.Parse(input.UserID)
+	if err != nil {
+		return nil, huma.Error400BadRequest("invalid user id")
+	}
+	return nil, api.revokeSession(ctx, userID, input.SessionID)
+}
+
+func (api *Api) AdminUserSessionsDelete(ctx context.Context, input *struct {
+	UserID string `path:"user-id" format:"uuid" required:"true"`
+}) (*struct{}, error) {
+	userID, err := uuid.Parse(input.UserID)
+	if err != nil {
+		return nil, huma.Error400BadRequest("invalid user id")
+	}
+	return nil, api.App().Auth().RevokeSessions(ctx, userID)
+}
+
+func (api *Api) revokeSession(ctx context.Context, userID uuid.UUID, rawSessionID string) error {
+	sessionID, err := uuid.Parse(rawSessionID)
+	if err != nil {
+		return huma.Error400BadRequest("invalid session id")
+	}
+	err = api.App().Auth().RevokeSession(ctx, userID, sessionID)
+	if errors.Is(err, services.ErrSessionNotFound) {
+		return huma.Error404NotFound(err.Error())
+	}
+	return err
+}
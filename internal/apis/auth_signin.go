@@ -38,6 +38,12 @@ func (api *Api) SignIn(ctx context.Context, input *struct{ Body *SigninDto }) (*
 	}
 	user, err := action.Authenticate(ctx, params)
 	if err != nil {
+		if err := ssoForbidden(err); err != nil {
+			return nil, err
+		}
+		if err := loginThrottled(err); err != nil {
+			return nil, err
+		}
 		return nil, fmt.Errorf("error authenticating user: %w", err)
 	}
 	dto, err := action.CreateAuthTokensFromEmail(ctx, user.Email)
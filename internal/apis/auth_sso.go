@@ -0,0 +1,38 @@
+package apis
+
+import (
+	"context"
+	"errors"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/tkahng/playground/internal/services"
+)
+
+type SsoUrlInput struct {
+	Email      string `json:"email" query:"email" form:"email" format:"email" required:"true"`
+	RedirectTo string `json:"redirect_to" query:"redirect_to" form:"redirect_to" format:"uri" required:"false"`
+}
+
+// SsoUrl starts a sign in through the IdP of the team that verified the
+// domain of the email.
+func (api *Api) SsoUrl(ctx context.Context, input *SsoUrlInput) (*OAuth2AuthorizationUrlOutput, error) {
+	res, err := api.App().Auth().CreateSsoUrl(ctx, input.Email, input.RedirectTo)
+	if err != nil {
+		if errors.Is(err, services.ErrSsoNotConfigured) {
+			return nil, huma.Error404NotFound(err.Error())
+		}
+		return nil, err
+	}
+	output := &OAuth2AuthorizationUrlOutput{}
+	output.Body.Url = res
+	return output, nil
+}
+
+// ssoForbidden turns the errors of the SSO enforcement of teams into a 403,
+// it returns nil for other errors.
+func ssoForbidden(err error) error {
+	if errors.Is(err, services.ErrSsoRequired) || errors.Is(err, services.ErrSsoEmailNotAllowed) {
+		return huma.Error403Forbidden(err.Error())
+	}
+	return nil
+}
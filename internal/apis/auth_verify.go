@@ -20,6 +20,9 @@ func verify(api *Api, ctx context.Context, input *OtpInput) (*struct{}, error) {
 	action := api.App().Auth()
 	err := action.HandleVerificationToken(ctx, input.Token)
 	if err != nil {
+		if err := loginThrottled(err); err != nil {
+			return nil, err
+		}
 		return nil, err
 	}
 	return nil, nil
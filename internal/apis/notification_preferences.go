@@ -0,0 +1,179 @@
+package apis
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/contextstore"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/notification"
+	"github.com/tkahng/playground/internal/services"
+	"github.com/tkahng/playground/internal/shared"
+	"github.com/tkahng/playground/internal/stores"
+	"github.com/tkahng/playground/internal/tools/mapper"
+)
+
+type NotificationPreference struct {
+	ID          uuid.UUID                   `json:"id"`
+	UserID      uuid.UUID                   `json:"user_id"`
+	TeamID      *uuid.UUID                  `json:"team_id,omitempty"`
+	Type        string                      `json:"type"`
+	Delivery    models.NotificationDelivery `json:"delivery" enum:"in_app,email,both,none"`
+	DailyDigest bool                        `json:"daily_digest"`
+	CreatedAt   time.Time                   `json:"created_at"`
+	UpdatedAt   time.Time                   `json:"updated_at"`
+}
+
+func FromModelNotificationPreference(preference *models.NotificationPreference) *NotificationPreference {
+	if preference == nil {
+		return nil
+	}
+	return &NotificationPreference{
+		ID:          preference.ID,
+		UserID:      preference.UserID,
+		TeamID:      preference.TeamID,
+		Type:        preference.Type,
+		Delivery:    preference.Delivery,
+		DailyDigest: preference.DailyDigest,
+		CreatedAt:   preference.CreatedAt,
+		UpdatedAt:   preference.UpdatedAt,
+	}
+}
+
+type NotificationPreferenceDto struct {
+	TeamID      *uuid.UUID                  `json:"team_id,omitempty" required:"false" doc:"leave empty to apply to every team"`
+	Type        string                      `json:"type" required:"true" enum:"assigned_to_task,new_team_member,task_completed,task_due_today"`
+	Delivery    models.NotificationDelivery `json:"delivery" required:"true" enum:"in_app,email,both,none"`
+	DailyDigest bool                        `json:"daily_digest" required:"false"`
+}
+
+type UpdateNotificationPreferenceInput struct {
+	Body NotificationPreferenceDto `json:"body" required:"true"`
+}
+
+type DeleteNotificationPreferenceInput struct {
+	TeamID string `query:"team_id" required:"false" format:"uuid" doc:"leave empty to reset the preference for every team"`
+	Type   string `query:"type" required:"true" enum:"assigned_to_task,new_team_member,task_completed,task_due_today"`
+}
+
+// checkNotificationPreferenceTeam makes sure the user is a member of the team
+// a preference is set for.
+func (api *Api) checkNotificationPreferenceTeam(ctx context.Context, userID uuid.UUID, teamID *uuid.UUID) error {
+	if teamID == nil {
+		return nil
+	}
+	member, err := api.App().Adapter().TeamMember().FindTeamMember(ctx, &stores.TeamMemberFilter{
+		UserIds: []uuid.UUID{userID},
+		TeamIds: []uuid.UUID{*teamID},
+	})
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		return huma.Error403Forbidden("not a member of the team")
+	}
+	return nil
+}
+
+func (api *Api) NotificationPreferencesList(ctx context.Context, input *struct{}) (*ApiOutput[[]*NotificationPreference], error) {
+	userInfo := contextstore.GetContextUserInfo(ctx)
+	if userInfo == nil {
+		return nil, huma.Error401Unauthorized("unauthorized")
+	}
+	preferences, err := api.App().Adapter().Notification().FindNotificationPreferences(ctx, []uuid.UUID{userInfo.User.ID})
+	if err != nil {
+		return nil, err
+	}
+	return &ApiOutput[[]*NotificationPreference]{
+		Body: mapper.Map(preferences, FromModelNotificationPreference),
+	}, nil
+}
+
+func (api *Api) NotificationPreferenceUpdate(ctx context.Context, input *UpdateNotificationPreferenceInput) (*ApiOutput[*NotificationPreference], error) {
+	userInfo := contextstore.GetContextUserInfo(ctx)
+	if userInfo == nil {
+		return nil, huma.Error401Unauthorized("unauthorized")
+	}
+	if !slices.Contains(notification.Kinds(), input.Body.Type) {
+		return nil, huma.Error400BadRequest("unknown notification type " + input.Body.Type)
+	}
+	if err := api.checkNotificationPreferenceTeam(ctx, userInfo.User.ID, input.Body.TeamID); err != nil {
+		return nil, err
+	}
+	preference, err := api.App().Adapter().Notification().UpsertNotificationPreference(ctx, &models.NotificationPreference{
+		UserID:      userInfo.User.ID,
+		TeamID:      input.Body.TeamID,
+		Type:        input.Body.Type,
+		Delivery:    input.Body.Delivery,
+		DailyDigest: input.Body.DailyDigest,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ApiOutput[*NotificationPreference]{
+		Body: FromModelNotificationPreference(preference),
+	}, nil
+}
+
+func (api *Api) NotificationPreferenceDelete(ctx context.Context, input *DeleteNotificationPreferenceInput) (*struct{}, error) {
+	userInfo := contextstore.GetContextUserInfo(ctx)
+	if userInfo == nil {
+		return nil, huma.Error401Unauthorized("unauthorized")
+	}
+	var teamID *uuid.UUID
+	if input.TeamID != "" {
+		id, err := uuid.Parse(input.TeamID)
+		if err != nil {
+			return nil, huma.Error400BadRequest("invalid team id")
+		}
+		teamID = &id
+	}
+	count, err := api.App().Adapter().Notification().DeleteNotificationPreference(ctx, userInfo.User.ID, teamID, input.Type)
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, huma.Error404NotFound("notification preference not found")
+	}
+	return nil, nil
+}
+
+type NotificationUnsubscribeInput struct {
+	Token string `query:"token" required:"true"`
+}
+
+type NotificationUnsubscribeOutput struct {
+	Body shared.UnsubscribePayload `json:"body"`
+}
+
+// NotificationUnsubscribe turns off the emails an unsubscribe link was sent
+// for. The signed token is the only credential, so the link works in one click.
+func (api *Api) NotificationUnsubscribe(ctx context.Context, input *NotificationUnsubscribeInput) (*NotificationUnsubscribeOutput, error) {
+	payload, err := api.App().NotificationMail().Unsubscribe(ctx, input.Token)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidUnsubscribeToken) {
+			return nil, huma.Error400BadRequest("invalid or expired unsubscribe link")
+		}
+		return nil, err
+	}
+	return &NotificationUnsubscribeOutput{
+		Body: *payload,
+	}, nil
+}
+
+// NotificationUnsubscribeLinksRevoke invalidates the unsubscribe links sent to
+// the current user, for example after forwarding one by mistake.
+func (api *Api) NotificationUnsubscribeLinksRevoke(ctx context.Context, input *struct{}) (*struct{}, error) {
+	userInfo := contextstore.GetContextUserInfo(ctx)
+	if userInfo == nil {
+		return nil, huma.Error401Unauthorized("unauthorized")
+	}
+	if err := api.App().NotificationMail().RevokeUnsubscribeLinks(ctx, userInfo.User.ID); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
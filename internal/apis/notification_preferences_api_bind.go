@@ -0,0 +1,94 @@
+package apis
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/tkahng/playground/internal/shared"
+)
+
+func BindNotificationPreferencesApi(api huma.API, appApi *Api) {
+	preferencesGroup := huma.NewGroup(api)
+	huma.Register(
+		preferencesGroup,
+		huma.Operation{
+			OperationID: "notification-preferences-list",
+			Method:      http.MethodGet,
+			Path:        "/notification-preferences",
+			Summary:     "Notification preferences list",
+			Description: "List the notification preferences of the current user",
+			Tags:        []string{"Notification Preferences"},
+			Errors:      []int{http.StatusUnauthorized},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+		},
+		appApi.NotificationPreferencesList,
+	)
+	huma.Register(
+		preferencesGroup,
+		huma.Operation{
+			OperationID: "notification-preference-update",
+			Method:      http.MethodPut,
+			Path:        "/notification-preferences",
+			Summary:     "Notification preference update",
+			Description: "Choose how notifications of a type are delivered, for one team or every team",
+			Tags:        []string{"Notification Preferences"},
+			Errors:      []int{http.StatusBadRequest, http.StatusForbidden},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+		},
+		appApi.NotificationPreferenceUpdate,
+	)
+	huma.Register(
+		preferencesGroup,
+		huma.Operation{
+			OperationID: "notification-preference-delete",
+			Method:      http.MethodDelete,
+			Path:        "/notification-preferences",
+			Summary:     "Notification preference delete",
+			Description: "Reset the delivery of a notification type to the default",
+			Tags:        []string{"Notification Preferences"},
+			Errors:      []int{http.StatusBadRequest, http.StatusNotFound},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+		},
+		appApi.NotificationPreferenceDelete,
+	)
+	huma.Register(
+		preferencesGroup,
+		huma.Operation{
+			OperationID: "notification-unsubscribe-links-revoke",
+			Method:      http.MethodDelete,
+			Path:        "/notification-preferences/unsubscribe-links",
+			Summary:     "Notification unsubscribe links revoke",
+			Description: "Invalidate every unsubscribe link sent to the current user so far",
+			Tags:        []string{"Notification Preferences"},
+			Errors:      []int{http.StatusUnauthorized},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+		},
+		appApi.NotificationUnsubscribeLinksRevoke,
+	)
+	// one click unsubscribe links of notification emails, mail clients may
+	// follow them with either method
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		huma.Register(
+			preferencesGroup,
+			huma.Operation{
+				OperationID: "notification-unsubscribe-" + strings.ToLower(method),
+				Method:      method,
+				Path:        "/notification-preferences/unsubscribe",
+				Summary:     "Notification unsubscribe",
+				Description: "Turn off the notification emails an unsubscribe link was sent for",
+				Tags:        []string{"Notification Preferences"},
+				Errors:      []int{http.StatusBadRequest},
+			},
+			appApi.NotificationUnsubscribe,
+		)
+	}
+}
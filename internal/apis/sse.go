@@ -1 +1,274 @@
 package apis
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/contextstore"
+	"github.com/tkahng/playground/internal/notification"
+	"github.com/tkahng/playground/internal/services"
+	"github.com/tkahng/playground/internal/shared"
+	"github.com/tkahng/playground/internal/stores"
+	"github.com/tkahng/playground/internal/tools/sse"
+	"github.com/tkahng/playground/internal/userreaction"
+)
+
+type SseTopicsInput struct {
+	Topics      []string `query:"topics" required:"false" maxItems:"20" uniqueItems:"true" doc:"topics to subscribe to, eg. team_member:<id>, project:<id> or reactions"`
+	AccessToken string   `query:"access_token" required:"false"`
+	LastEventID int64    `header:"Last-Event-ID" required:"false"`
+}
+
+type SseTopicsDto struct {
+	Subscribe   []string `json:"subscribe,omitempty" maxItems:"20" uniqueItems:"true" required:"false"`
+	Unsubscribe []string `json:"unsubscribe,omitempty" maxItems:"20" uniqueItems:"true" required:"false"`
+}
+
+type UpdateSseTopicsInput struct {
+	ClientID string       `path:"client-id" required:"true" format:"uuid"`
+	Body     SseTopicsDto `json:"body" required:"true"`
+}
+
+// authorizeSseTopic checks that the user may receive the messages of topic.
+// Team member topics are only open to the user of the member, project topics
+// to the members of the project's team and sticks game topics to the players.
+func (api *Api) authorizeSseTopic(ctx context.Context, userID uuid.UUID, topic string) error {
+	if topic == sse.UserReactionsChannel {
+		return nil
+	}
+	kind, rawID, ok := strings.Cut(topic, ":")
+	if !ok {
+		return huma.Error400BadRequest("unknown topic " + topic)
+	}
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return huma.Error400BadRequest("invalid id in topic " + topic)
+	}
+	// games only live in memory, their topics are open to their players
+	if kind == "sticks_game" {
+		if !api.app.Sticks().IsPlayer(rawID, userID) {
+			return huma.Error403Forbidden("not allowed to subscribe to " + topic)
+		}
+		return nil
+	}
+	filter := &stores.TeamMemberFilter{
+		UserIds: []uuid.UUID{userID},
+	}
+	switch kind {
+	case "team_member":
+		filter.Ids = []uuid.UUID{id}
+	case "project":
+		project, err := api.app.Adapter().Task().FindTaskProjectByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if project == nil {
+			return huma.Error403Forbidden("not allowed to subscribe to " + topic)
+		}
+		filter.TeamIds = []uuid.UUID{project.TeamID}
+	default:
+		return huma.Error400BadRequest("unknown topic " + topic)
+	}
+	member, err := api.app.Adapter().TeamMember().FindTeamMember(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		return huma.Error403Forbidden("not allowed to subscribe to " + topic)
+	}
+	return nil
+}
+
+func (api *Api) authorizeSseTopics(ctx context.Context, userID uuid.UUID, topics []string) error {
+	for _, topic := range topics {
+		if err := api.authorizeSseTopic(ctx, userID, topic); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckSseTopics unsubscribes the clients of this instance from the team
+// topics their user may no longer receive, such as those of a team they were
+// removed from. Topics are only authorized when they are subscribed to, so
+// open connections would otherwise keep them until they reconnect.
+func (api *Api) CheckSseTopics(ctx context.Context) {
+	manager := api.app.SseManager()
+	for _, c := range manager.Clients() {
+		userID, err := uuid.Parse(c.Owner())
+		if err != nil {
+			continue
+		}
+		var revoked []string
+		for _, topic := range c.Topics() {
+			kind, _, _ := strings.Cut(topic, ":")
+			if kind != "team_member" && kind != "project" {
+				continue
+			}
+			err := api.authorizeSseTopic(ctx, userID, topic)
+			var se huma.StatusError
+			if errors.As(err, &se) && se.GetStatus() == http.StatusForbidden {
+				revoked = append(revoked, topic)
+			} else if err != nil {
+				slog.ErrorContext(ctx, "error checking sse topic", slog.String("topic", topic), slog.Any("error", err))
+			}
+		}
+		if len(revoked) == 0 {
+			continue
+		}
+		err = manager.UpdateTopics(c.ID(), c.Owner(), nil, revoked)
+		if err != nil && !errors.Is(err, sse.ErrClientNotFound) {
+			slog.ErrorContext(ctx, "error revoking sse topics", slog.String("client_id", c.ID()), slog.Any("error", err))
+		}
+	}
+}
+
+// RunSseTopicChecks runs CheckSseTopics every interval until ctx is done.
+func (api *Api) RunSseTopicChecks(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			api.CheckSseTopics(ctx)
+		}
+	}
+}
+
+// sseTopicsMiddleware rejects the connection before the stream starts when a
+// topic in the query is not allowed.
+func (api *Api) sseTopicsMiddleware(humapi huma.API) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		rawCtx := ctx.Context()
+		userInfo := contextstore.GetContextUserInfo(rawCtx)
+		if userInfo == nil {
+			huma.WriteErr(humapi, ctx, http.StatusUnauthorized, "unauthorized at middleware", nil)
+			return
+		}
+		var topics []string
+		if raw := ctx.Query("topics"); raw != "" {
+			topics = strings.Split(raw, ",")
+		}
+		if err := api.authorizeSseTopics(rawCtx, userInfo.User.ID, topics); err != nil {
+			var se huma.StatusError
+			if errors.As(err, &se) {
+				huma.WriteErr(humapi, ctx, se.GetStatus(), se.Error())
+				return
+			}
+			huma.WriteErr(humapi, ctx, http.StatusInternalServerError, "error authorizing topics", err)
+			return
+		}
+		next(ctx)
+	}
+}
+
+func (api *Api) BindSseTopics(humapi huma.API) {
+	hanlder := sse.ServeSSE(
+		func(ctx context.Context, f sse.Sender, input *SseTopicsInput) sse.Client {
+			userInfo := contextstore.GetContextUserInfo(ctx)
+			id := uuid.NewString()
+			return sse.NewClient(sse.ClientChannel(id), f, slog.Default(), func() any {
+				return &PingMessage{
+					Message: "ping",
+				}
+			},
+				sse.WithID(id),
+				sse.WithOwner(userInfo.User.ID.String()),
+				sse.WithTopics(input.Topics...),
+			)
+		},
+		func(ctx context.Context, cf context.CancelFunc, c sse.Client) {
+			api.app.SseManager().RegisterClient(ctx, cf, c)
+			// tell the client its id so it can change its topics later
+			// nolint:errcheck
+			c.Write(sse.Message{Data: sse.TopicsEvent{ClientID: c.ID(), Topics: c.Topics()}})
+		},
+		func(ctx context.Context, input *SseTopicsInput, c sse.Client) ([]sse.Message, error) {
+			return sse.ReplayTopics(api.app.SseManager(), c.Topics(), input.LastEventID), nil
+		},
+		func(c sse.Client) {
+			api.app.SseManager().UnregisterClient(c)
+		},
+		30*time.Second,
+	)
+	sse.Register(
+		humapi,
+		huma.Operation{
+			OperationID: "sse-topics",
+			Method:      http.MethodGet,
+			Path:        "/sse",
+			Summary:     "sse-topics",
+			Description: "Server sent events for every subscribed topic on a single connection",
+			Tags:        []string{"Events"},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+			Middlewares: huma.Middlewares{
+				api.sseTopicsMiddleware(humapi),
+			},
+			Errors: []int{http.StatusInternalServerError, http.StatusBadRequest, http.StatusForbidden},
+		},
+		map[string]any{
+			"topics":                     &sse.TopicsEvent{},
+			"task_completed":             &notification.NotificationPayload[notification.TaskCompletedNotificationData]{},
+			"task_due_today":             &notification.NotificationPayload[notification.TaskDueTodayNotificationData]{},
+			"new_team_member":            &notification.NotificationPayload[notification.NewTeamMemberNotificationData]{},
+			"assigned_to_task":           &notification.NotificationPayload[notification.AssignedToTaskNotificationData]{},
+			"latest_user_reaction_stats": &userreaction.LatestUserReactionStatsSseEvent{},
+			"task_board":                 &services.TaskBoardSseEvent{},
+			"task_presence":              &services.TaskPresenceSseEvent{},
+			"unread_count":               &services.UnreadCountSseEvent{},
+			"sticks_game":                &services.SticksGameSseEvent{},
+			"ping":                       &PingMessage{},
+		},
+		hanlder,
+	)
+
+	huma.Register(
+		humapi,
+		huma.Operation{
+			OperationID: "sse-topics-update",
+			Method:      http.MethodPut,
+			Path:        "/sse/{client-id}/topics",
+			Summary:     "sse-topics-update",
+			Description: "Subscribe an open sse connection to topics or unsubscribe it",
+			Tags:        []string{"Events"},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+			Errors: []int{http.StatusNotFound, http.StatusBadRequest, http.StatusForbidden},
+		},
+		api.UpdateSseTopics,
+	)
+}
+
+func (api *Api) UpdateSseTopics(ctx context.Context, input *UpdateSseTopicsInput) (*struct{}, error) {
+	userInfo := contextstore.GetContextUserInfo(ctx)
+	if userInfo == nil {
+		return nil, huma.Error401Unauthorized("unauthorized")
+	}
+	if err := api.authorizeSseTopics(ctx, userInfo.User.ID, input.Body.Subscribe); err != nil {
+		return nil, err
+	}
+	err := api.app.SseManager().UpdateTopics(
+		input.ClientID,
+		userInfo.User.ID.String(),
+		input.Body.Subscribe,
+		input.Body.Unsubscribe,
+	)
+	switch {
+	case errors.Is(err, sse.ErrClientNotFound):
+		return nil, huma.Error404NotFound("sse connection not found")
+	case errors.Is(err, sse.ErrNotClientOwner):
+		return nil, huma.Error403Forbidden("sse connection belongs to another user")
+	}
+	return nil, err
+}
@@ -0,0 +1,57 @@
+package apis_test
+
+import (
+	"context"
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	humasse "github.com/danielgtaylor/huma/v2/sse"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkahng/playground/internal/apis"
+	"github.com/tkahng/playground/internal/core"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/stores"
+	"github.com/tkahng/playground/internal/tools/sse"
+)
+
+func TestCheckSseTopics(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sseManager := sse.NewManager(nil)
+	go sseManager.Run(ctx)
+
+	user := &models.User{ID: uuid.New()}
+	member := &models.TeamMember{ID: uuid.New(), TeamID: uuid.New(), UserID: &user.ID}
+	var removed atomic.Bool
+	adapter := stores.NewAdapterDecorators()
+	adapter.TeamMemberFunc.FindTeamMemberFunc = func(ctx context.Context, filter *stores.TeamMemberFilter) (*models.TeamMember, error) {
+		if !removed.Load() && slices.Contains(filter.Ids, member.ID) && slices.Contains(filter.UserIds, user.ID) {
+			return member, nil
+		}
+		return nil, nil
+	}
+	appApi := apis.NewApi(&core.BaseAppDecorator{
+		AdapterFunc:    func() stores.StorageAdapterInterface { return adapter },
+		SseManagerFunc: func() sse.Manager { return sseManager },
+	})
+
+	memberTopic := "team_member:" + member.ID.String()
+	c := sse.NewClient("sse_client:1", func(humasse.Message) error { return nil }, nil, nil,
+		sse.WithID("1"),
+		sse.WithOwner(user.ID.String()),
+		sse.WithTopics(memberTopic, sse.UserReactionsChannel),
+	)
+	sseManager.RegisterClient(ctx, func() {}, c)
+	require.Len(t, sseManager.Clients(), 1)
+
+	appApi.CheckSseTopics(ctx)
+	assert.True(t, c.Subscribed(memberTopic), "members keep their topics")
+
+	removed.Store(true)
+	appApi.CheckSseTopics(ctx)
+	assert.False(t, c.Subscribed(memberTopic), "removed members lose the topics of the team")
+	assert.True(t, c.Subscribed(sse.UserReactionsChannel))
+}
@@ -6,10 +6,12 @@ import (
 
 	"github.com/tkahng/playground/internal/contextstore"
 	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/services"
 )
 
 type UserStats struct {
-	Task models.TaskStats `json:"task_stats" db:"task_stats"`
+	Task   models.TaskStats     `json:"task_stats" db:"task_stats"`
+	Sticks services.SticksStats `json:"sticks_stats"`
 }
 
 type StatsResponse struct {
@@ -27,7 +29,8 @@ func (api *Api) Stats(ctx context.Context, input *struct{}) (*StatsResponse, err
 	}
 	return &StatsResponse{
 		Body: &UserStats{
-			Task: *stats,
+			Task:   *stats,
+			Sticks: api.App().Sticks().Stats(),
 		},
 	}, nil
 }
@@ -0,0 +1,164 @@
+package apis
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/tkahng/playground/internal/contextstore"
+	"github.com/tkahng/playground/internal/games/sticks"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/services"
+)
+
+type SticksGameInput struct {
+	GameID string `path:"game-id" required:"true" format:"uuid"`
+}
+
+type SticksAttackDto struct {
+	AttackWithLeft bool `json:"attack_with_left" doc:"attack with the left hand, otherwise the right"`
+	AttackLeft     bool `json:"attack_left" doc:"attack the left hand of the opponent, otherwise the right"`
+}
+
+type SticksAttackInput struct {
+	SticksGameInput
+	Body SticksAttackDto
+}
+
+type SticksSplitDto struct {
+	FromLeft bool `json:"from_left" doc:"move points from the left hand to the right, otherwise the other way"`
+	Points   int  `json:"points" minimum:"1" maximum:"4"`
+}
+
+type SticksSplitInput struct {
+	SticksGameInput
+	Body SticksSplitDto
+}
+
+type SticksJoinInput struct {
+	Bot string `query:"bot" enum:"easy,medium,hard" default:"medium" doc:"difficulty of the bot played after waiting too long for an opponent"`
+}
+
+type SticksLeaderboardInput struct {
+	Limit int `query:"limit" minimum:"1" maximum:"100" default:"10"`
+}
+
+// sticksError maps the errors of games and matchmaking to http errors.
+func sticksError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, services.ErrGameNotFound):
+		return huma.Error404NotFound("game not found")
+	case errors.Is(err, sticks.ErrNotYourTurn), errors.Is(err, sticks.ErrNotInProgress):
+		return huma.Error409Conflict(err.Error())
+	case errors.Is(err, sticks.ErrIllegalMove):
+		return huma.Error400BadRequest(err.Error())
+	case errors.Is(err, sticks.ErrMatchmakingTimeout),
+		errors.Is(err, sticks.ErrMatchmakingCanceled),
+		errors.Is(err, context.DeadlineExceeded):
+		return huma.NewError(http.StatusRequestTimeout, "no opponent found")
+	case errors.Is(err, sticks.ErrAtCapacity),
+		errors.Is(err, sticks.ErrQueueFull),
+		errors.Is(err, sticks.ErrBrokerStopped):
+		return huma.Error503ServiceUnavailable(err.Error())
+	}
+	return err
+}
+
+// sticksDifficulty parses the difficulty of a bot, empty is medium.
+func sticksDifficulty(bot string) (sticks.Difficulty, error) {
+	if bot == "" {
+		return sticks.DifficultyMedium, nil
+	}
+	difficulty, err := sticks.ParseDifficulty(bot)
+	if err != nil {
+		return "", huma.Error400BadRequest(err.Error())
+	}
+	return difficulty, nil
+}
+
+func (api *Api) SticksJoin(ctx context.Context, input *SticksJoinInput) (*ApiOutput[*sticks.GameView], error) {
+	userInfo := contextstore.GetContextUserInfo(ctx)
+	if userInfo == nil {
+		return nil, huma.Error401Unauthorized("unauthorized")
+	}
+	difficulty, err := sticksDifficulty(input.Bot)
+	if err != nil {
+		return nil, err
+	}
+	view, err := api.App().Sticks().Join(ctx, &userInfo.User, difficulty)
+	if err != nil {
+		return nil, sticksError(err)
+	}
+	return &ApiOutput[*sticks.GameView]{Body: view}, nil
+}
+
+func (api *Api) SticksCurrentGame(ctx context.Context, input *struct{}) (*ApiOutput[*sticks.GameView], error) {
+	userInfo := contextstore.GetContextUserInfo(ctx)
+	if userInfo == nil {
+		return nil, huma.Error401Unauthorized("unauthorized")
+	}
+	view, err := api.App().Sticks().CurrentGame(ctx, userInfo.User.ID)
+	if err != nil {
+		return nil, sticksError(err)
+	}
+	return &ApiOutput[*sticks.GameView]{Body: view}, nil
+}
+
+func (api *Api) SticksGame(ctx context.Context, input *SticksGameInput) (*ApiOutput[*sticks.GameView], error) {
+	userInfo := contextstore.GetContextUserInfo(ctx)
+	if userInfo == nil {
+		return nil, huma.Error401Unauthorized("unauthorized")
+	}
+	view, err := api.App().Sticks().Game(ctx, userInfo.User.ID, input.GameID)
+	if err != nil {
+		return nil, sticksError(err)
+	}
+	return &ApiOutput[*sticks.GameView]{Body: view}, nil
+}
+
+func (api *Api) SticksAttack(ctx context.Context, input *SticksAttackInput) (*ApiOutput[*sticks.GameView], error) {
+	userInfo := contextstore.GetContextUserInfo(ctx)
+	if userInfo == nil {
+		return nil, huma.Error401Unauthorized("unauthorized")
+	}
+	view, err := api.App().Sticks().Attack(ctx, userInfo.User.ID, input.GameID, input.Body.AttackWithLeft, input.Body.AttackLeft)
+	if err != nil {
+		return nil, sticksError(err)
+	}
+	return &ApiOutput[*sticks.GameView]{Body: view}, nil
+}
+
+func (api *Api) SticksSplit(ctx context.Context, input *SticksSplitInput) (*ApiOutput[*sticks.GameView], error) {
+	userInfo := contextstore.GetContextUserInfo(ctx)
+	if userInfo == nil {
+		return nil, huma.Error401Unauthorized("unauthorized")
+	}
+	view, err := api.App().Sticks().Split(ctx, userInfo.User.ID, input.GameID, input.Body.FromLeft, input.Body.Points)
+	if err != nil {
+		return nil, sticksError(err)
+	}
+	return &ApiOutput[*sticks.GameView]{Body: view}, nil
+}
+
+func (api *Api) SticksRating(ctx context.Context, input *struct{}) (*ApiOutput[*models.GameRating], error) {
+	userInfo := contextstore.GetContextUserInfo(ctx)
+	if userInfo == nil {
+		return nil, huma.Error401Unauthorized("unauthorized")
+	}
+	rating, err := api.App().Sticks().Rating(ctx, userInfo.User.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &ApiOutput[*models.GameRating]{Body: rating}, nil
+}
+
+func (api *Api) SticksLeaderboard(ctx context.Context, input *SticksLeaderboardInput) (*ApiOutput[[]*models.GameRating], error) {
+	ratings, err := api.App().Sticks().Leaderboard(ctx, input.Limit)
+	if err != nil {
+		return nil, err
+	}
+	return &ApiOutput[[]*models.GameRating]{Body: ratings}, nil
+}
@@ -0,0 +1,124 @@
+package apis
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/tkahng/playground/internal/shared"
+)
+
+func BindSticksApi(api huma.API, appApi *Api) {
+	sticksGroup := huma.NewGroup(api)
+	huma.Register(
+		sticksGroup,
+		huma.Operation{
+			OperationID: "sticks-matchmaking",
+			Method:      http.MethodPost,
+			Path:        "/games/sticks/matchmaking",
+			Summary:     "Sticks matchmaking",
+			Description: "Wait for an opponent and get the new game, a player already in a game gets it back. Players that wait too long play a bot of the bot difficulty. The state of the game is sent on the sticks_game:<id> topic after every move.",
+			Tags:        []string{"Sticks"},
+			Errors:      []int{http.StatusRequestTimeout, http.StatusServiceUnavailable},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+		},
+		appApi.SticksJoin,
+	)
+	huma.Register(
+		sticksGroup,
+		huma.Operation{
+			OperationID: "sticks-current-game",
+			Method:      http.MethodGet,
+			Path:        "/games/sticks/current",
+			Summary:     "Sticks current game",
+			Description: "Get the game the current user is playing",
+			Tags:        []string{"Sticks"},
+			Errors:      []int{http.StatusNotFound},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+		},
+		appApi.SticksCurrentGame,
+	)
+	huma.Register(
+		sticksGroup,
+		huma.Operation{
+			OperationID: "sticks-rating",
+			Method:      http.MethodGet,
+			Path:        "/games/sticks/rating",
+			Summary:     "Sticks rating",
+			Description: "Get the rating, wins and losses of the current user",
+			Tags:        []string{"Sticks"},
+			Errors:      []int{http.StatusUnauthorized},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+		},
+		appApi.SticksRating,
+	)
+	huma.Register(
+		sticksGroup,
+		huma.Operation{
+			OperationID: "sticks-leaderboard",
+			Method:      http.MethodGet,
+			Path:        "/games/sticks/leaderboard",
+			Summary:     "Sticks leaderboard",
+			Description: "List the best rated players",
+			Tags:        []string{"Sticks"},
+			Errors:      []int{http.StatusUnauthorized},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+		},
+		appApi.SticksLeaderboard,
+	)
+	huma.Register(
+		sticksGroup,
+		huma.Operation{
+			OperationID: "sticks-game",
+			Method:      http.MethodGet,
+			Path:        "/games/sticks/{game-id}",
+			Summary:     "Sticks game",
+			Description: "Get the state of a game of the current user",
+			Tags:        []string{"Sticks"},
+			Errors:      []int{http.StatusNotFound},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+		},
+		appApi.SticksGame,
+	)
+	huma.Register(
+		sticksGroup,
+		huma.Operation{
+			OperationID: "sticks-attack",
+			Method:      http.MethodPost,
+			Path:        "/games/sticks/{game-id}/attack",
+			Summary:     "Sticks attack",
+			Description: "Add the fingers of one of your hands to a hand of the opponent",
+			Tags:        []string{"Sticks"},
+			Errors:      []int{http.StatusNotFound, http.StatusBadRequest, http.StatusConflict},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+		},
+		appApi.SticksAttack,
+	)
+	huma.Register(
+		sticksGroup,
+		huma.Operation{
+			OperationID: "sticks-split",
+			Method:      http.MethodPost,
+			Path:        "/games/sticks/{game-id}/split",
+			Summary:     "Sticks split",
+			Description: "Move points from one of your hands to the other",
+			Tags:        []string{"Sticks"},
+			Errors:      []int{http.StatusNotFound, http.StatusBadRequest, http.StatusConflict},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+		},
+		appApi.SticksSplit,
+	)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/tkahng/playground/internal/middleware"
+	"github.com/tkahng/playground/internal/models"
 	"github.com/tkahng/playground/internal/shared"
 )
 
@@ -13,6 +14,7 @@ func BindStripeApi(api huma.API, appApi *Api) {
 	selectCustomerFromTeam := middleware.SelectCustomerFromTeam(api, appApi.App())
 	selectOrCreateOwnerCustomerFromTeam := middleware.SelectOrCreateOwnerCustomerFromTeam(api, appApi.App())
 	teamInfoFromParam := middleware.TeamInfoFromParam(api, appApi.App())
+	canManageBilling := middleware.RequireTeamPermissionsMiddleware(api, appApi.App(), models.TeamPermissionBillingManage)
 	stripeGroup := huma.NewGroup(api)
 
 	// stripe webhook
@@ -152,6 +154,7 @@ func BindStripeApi(api huma.API, appApi *Api) {
 			Security:    []map[string][]string{{shared.BearerAuthSecurityKey: {}}},
 			Middlewares: huma.Middlewares{
 				teamInfoFromParam,
+				canManageBilling,
 				selectOrCreateOwnerCustomerFromTeam,
 			},
 		},
@@ -171,6 +174,7 @@ func BindStripeApi(api huma.API, appApi *Api) {
 			Security:    []map[string][]string{{shared.BearerAuthSecurityKey: {}}},
 			Middlewares: huma.Middlewares{
 				teamInfoFromParam,
+				canManageBilling,
 				selectOrCreateOwnerCustomerFromTeam,
 			},
 		},
@@ -2,30 +2,33 @@ package apis
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
-	humasse "github.com/danielgtaylor/huma/v2/sse"
 	"github.com/google/uuid"
 	"github.com/tkahng/playground/internal/contextstore"
 	"github.com/tkahng/playground/internal/middleware"
 	"github.com/tkahng/playground/internal/models"
 	"github.com/tkahng/playground/internal/notification"
+	"github.com/tkahng/playground/internal/services"
 	"github.com/tkahng/playground/internal/shared"
 	"github.com/tkahng/playground/internal/stores"
 	"github.com/tkahng/playground/internal/tools/mapper"
 	"github.com/tkahng/playground/internal/tools/sse"
+	"github.com/tkahng/playground/internal/tools/types"
 )
 
 func TeamChannel(teamMemberId string) string {
-	return "team_member_id:" + teamMemberId
+	return sse.TeamMemberChannel(teamMemberId)
 }
 
 type TeamMemberSseInput struct {
 	TeamMemberID string `path:"team-member-id"`
 	AccessToken  string `query:"access_token"`
+	LastEventID  int64  `header:"Last-Event-ID" required:"false"`
 }
 
 type MiddlewareFunc func(ctx huma.Context, next func(huma.Context))
@@ -33,23 +36,30 @@ type MiddlewareFunc func(ctx huma.Context, next func(huma.Context))
 func (api *Api) BindTeamMembersSseEvents(humapi huma.API) {
 	membermiddleware := middleware.TeamInfoFromTeamMemberID(humapi, api.App())
 	hanlder := sse.ServeSSE(
-		func(ctx context.Context, f func(any) error, input *TeamMemberSseInput) sse.Client {
+		func(ctx context.Context, f sse.Sender, input *TeamMemberSseInput) sse.Client {
 			teamInfo := contextstore.GetContextTeamInfo(ctx)
 			return sse.NewClient(TeamChannel(teamInfo.Member.ID.String()), f, slog.Default(), func() any {
 				return &PingMessage{
 					Message: "ping",
 				}
-			})
+			}, sse.WithSeqIDs())
 		},
 		func(ctx context.Context, cf context.CancelFunc, c sse.Client) {
 			api.app.SseManager().RegisterClient(ctx, cf, c)
 		},
+		func(ctx context.Context, input *TeamMemberSseInput, c sse.Client) ([]sse.Message, error) {
+			if input.LastEventID <= 0 {
+				return nil, nil
+			}
+			teamInfo := contextstore.GetContextTeamInfo(ctx)
+			return api.missedNotifications(ctx, teamInfo.Member.ID, input.LastEventID)
+		},
 		func(c sse.Client) {
 			api.app.SseManager().UnregisterClient(c)
 		},
 		30*time.Second,
 	)
-	humasse.Register(
+	sse.Register(
 		humapi,
 		huma.Operation{
 			OperationID: "team-members-sse-team-member-notifications",
@@ -71,6 +81,7 @@ func (api *Api) BindTeamMembersSseEvents(humapi huma.API) {
 			"task_due_today":   &notification.NotificationPayload[notification.TaskDueTodayNotificationData]{},
 			"new_team_member":  &notification.NotificationPayload[notification.NewTeamMemberNotificationData]{},
 			"assigned_to_task": &notification.NotificationPayload[notification.AssignedToTaskNotificationData]{},
+			"unread_count":     &services.UnreadCountSseEvent{},
 			"ping":             &PingMessage{},
 		},
 		hanlder,
@@ -78,6 +89,36 @@ func (api *Api) BindTeamMembersSseEvents(humapi huma.API) {
 
 }
 
+// maxReplayedNotifications bounds the notifications resent to a reconnecting
+// client.
+const maxReplayedNotifications = 100
+
+// missedNotifications returns the notifications of the team member created
+// after lastEventID, the seq of the last notification the client received.
+func (api *Api) missedNotifications(ctx context.Context, teamMemberID uuid.UUID, lastEventID int64) ([]sse.Message, error) {
+	filter := &stores.NotificationFilter{
+		TeamMemberIds: []uuid.UUID{teamMemberID},
+		SeqAfter:      types.OptionalParam[int64]{IsSet: true, Value: lastEventID},
+	}
+	filter.PerPage = maxReplayedNotifications
+	filter.SortBy = "seq"
+	filter.SortOrder = "asc"
+	notifications, err := api.app.Adapter().Notification().FindNotifications(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]sse.Message, 0, len(notifications))
+	for _, n := range notifications {
+		data, err := notification.DecodePayload(n.Type, n.Payload)
+		if err != nil {
+			slog.ErrorContext(ctx, "error decoding notification", slog.Any("error", err), slog.String("id", n.ID.String()))
+			continue
+		}
+		messages = append(messages, sse.Message{Seq: n.Seq, Event: n.Type, Data: data})
+	}
+	return messages, nil
+}
+
 type PingMessage struct {
 	Message string `json:"message"`
 }
@@ -90,6 +131,25 @@ type TeamMembersNotificationsInput struct {
 	PaginatedInput
 	SortParams
 	TeamMemberID string `path:"team-member-id" required:"true" format:"uuid"`
+	NotificationsFilterParams
+}
+
+// NotificationsFilterParams narrows the notifications of a team member.
+type NotificationsFilterParams struct {
+	Types         []string                       `query:"types,omitempty" required:"false" uniqueItems:"true" enum:"assigned_to_task,new_team_member,task_completed,task_due_today"`
+	Read          types.OptionalParam[bool]      `query:"read,omitempty" required:"false"`
+	CreatedAfter  types.OptionalParam[time.Time] `query:"created_after,omitempty" required:"false"`
+	CreatedBefore types.OptionalParam[time.Time] `query:"created_before,omitempty" required:"false"`
+}
+
+func (p *NotificationsFilterParams) filter(teamMemberID uuid.UUID) *stores.NotificationFilter {
+	return &stores.NotificationFilter{
+		TeamMemberIds: []uuid.UUID{teamMemberID},
+		Types:         p.Types,
+		Read:          p.Read,
+		CreatedAfter:  p.CreatedAfter,
+		CreatedBefore: p.CreatedBefore,
+	}
 }
 
 type Notification struct {
@@ -105,6 +165,7 @@ type Notification struct {
 	TeamID       *uuid.UUID     `db:"team_id" json:"team_id,omitempty"`
 	Metadata     map[string]any `db:"metadata" json:"metadata"`
 	Type         string         `db:"type" json:"type"`
+	Seq          int64          `db:"seq" json:"seq"`
 	User         *ApiUser       `db:"user" src:"user_id" dest:"id" table:"users" json:"user,omitempty"`
 	TeamMember   *TeamMember    `db:"team_member" src:"team_member_id" dest:"id" table:"team_members" json:"team_member,omitempty"`
 	Team         *Team          `db:"team" src:"team_id" dest:"id" table:"teams" json:"team,omitempty"`
@@ -123,6 +184,7 @@ func FromModelNotification(notification *models.Notification) *Notification {
 		TeamID:       notification.TeamID,
 		Metadata:     notification.Metadata,
 		Type:         notification.Type,
+		Seq:          notification.Seq,
 		User:         FromUserModel(notification.User),
 		TeamMember:   FromTeamMemberModel(notification.TeamMember),
 		Team:         FromTeamModel(notification.Team),
@@ -152,11 +214,7 @@ func (api *Api) BindFindTeamMembersNotifications(aapi huma.API) {
 			if teamInfo == nil {
 				return nil, huma.Error401Unauthorized("no team info")
 			}
-			filter := &stores.NotificationFilter{
-				TeamMemberIds: []uuid.UUID{
-					teamInfo.Member.ID,
-				},
-			}
+			filter := input.filter(teamInfo.Member.ID)
 			filter.Page = input.Page
 			filter.PerPage = input.PerPage
 			filter.SortBy = input.SortBy
@@ -212,25 +270,112 @@ func (api *Api) BindReadTeamMembersNotifications(aapi huma.API) {
 			if err != nil {
 				return nil, err
 			}
-			notification, err := api.App().Adapter().Notification().FindNotification(ctx, &stores.NotificationFilter{
-				Ids: []uuid.UUID{
-					notificationID,
-				},
-				TeamMemberIds: []uuid.UUID{
-					teamInfo.Member.ID,
-				},
-			})
+			err = api.App().Notification().MarkRead(ctx, teamInfo.Member.ID, notificationID)
 			if err != nil {
+				if errors.Is(err, services.ErrNotificationNotFound) {
+					return nil, huma.Error404NotFound("notification not found")
+				}
 				return nil, err
 			}
-			now := time.Now()
-			notification.ReadAt = &now
-			err = api.App().Adapter().Notification().UpdateNotification(ctx, notification)
+
+			return nil, nil
+		},
+	)
+}
+
+type ReadAllTeamMembersNotificationsInput struct {
+	TeamMemberID  string                         `path:"team-member-id" required:"true" format:"uuid"`
+	Types         []string                       `query:"types,omitempty" required:"false" uniqueItems:"true" enum:"assigned_to_task,new_team_member,task_completed,task_due_today"`
+	CreatedAfter  types.OptionalParam[time.Time] `query:"created_after,omitempty" required:"false"`
+	CreatedBefore types.OptionalParam[time.Time] `query:"created_before,omitempty" required:"false"`
+}
+
+type ReadAllTeamMembersNotificationsOutput struct {
+	Updated int64 `json:"updated"`
+}
+
+func (api *Api) BindReadAllTeamMembersNotifications(aapi huma.API) {
+	teamMemberMiddleware := middleware.TeamInfoFromTeamMemberID(aapi, api.app)
+	huma.Register(
+		aapi,
+		huma.Operation{
+			OperationID: "read-all-team-members-notifications",
+			Method:      http.MethodPost,
+			Path:        "/team-members/{team-member-id}/notifications/read-all",
+			Summary:     "read-all-team-members-notifications",
+			Description: "mark the unread notifications of a team member as read, optionally only of some types or created in a date range",
+			Tags:        []string{"Team Members"},
+			Errors:      []int{http.StatusInternalServerError, http.StatusBadRequest},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+			Middlewares: huma.Middlewares{
+				teamMemberMiddleware,
+			},
+		},
+		func(ctx context.Context, input *ReadAllTeamMembersNotificationsInput) (*ApiOutput[*ReadAllTeamMembersNotificationsOutput], error) {
+			teamInfo := contextstore.GetContextTeamInfo(ctx)
+			if teamInfo == nil {
+				return nil, huma.Error401Unauthorized("unauthorized")
+			}
+			updated, err := api.App().Notification().MarkAllRead(ctx, teamInfo.Member.ID, &stores.NotificationFilter{
+				Types:         input.Types,
+				CreatedAfter:  input.CreatedAfter,
+				CreatedBefore: input.CreatedBefore,
+			})
 			if err != nil {
 				return nil, err
 			}
+			return &ApiOutput[*ReadAllTeamMembersNotificationsOutput]{
+				Body: &ReadAllTeamMembersNotificationsOutput{
+					Updated: updated,
+				},
+			}, nil
+		},
+	)
+}
 
-			return nil, nil
+type TeamMembersUnreadNotificationsInput struct {
+	TeamMemberID string `path:"team-member-id" required:"true" format:"uuid"`
+}
+
+type UnreadNotificationsCount struct {
+	Count int64 `json:"count"`
+}
+
+func (api *Api) BindTeamMembersUnreadNotificationsCount(aapi huma.API) {
+	teamMemberMiddleware := middleware.TeamInfoFromTeamMemberID(aapi, api.app)
+	huma.Register(
+		aapi,
+		huma.Operation{
+			OperationID: "team-members-unread-notifications-count",
+			Method:      http.MethodGet,
+			Path:        "/team-members/{team-member-id}/notifications/unread-count",
+			Summary:     "team-members-unread-notifications-count",
+			Description: "count the unread notifications of a team member",
+			Tags:        []string{"Team Members"},
+			Errors:      []int{http.StatusInternalServerError, http.StatusBadRequest},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+			Middlewares: huma.Middlewares{
+				teamMemberMiddleware,
+			},
+		},
+		func(ctx context.Context, input *TeamMembersUnreadNotificationsInput) (*ApiOutput[*UnreadNotificationsCount], error) {
+			teamInfo := contextstore.GetContextTeamInfo(ctx)
+			if teamInfo == nil {
+				return nil, huma.Error401Unauthorized("unauthorized")
+			}
+			count, err := api.App().Notification().UnreadCount(ctx, teamInfo.Member.ID)
+			if err != nil {
+				return nil, err
+			}
+			return &ApiOutput[*UnreadNotificationsCount]{
+				Body: &UnreadNotificationsCount{
+					Count: count,
+				},
+			}, nil
 		},
 	)
 }
@@ -263,15 +408,11 @@ func (api *Api) BindDeleteTeamMembersNotifications(aapi huma.API) {
 			if err != nil {
 				return nil, err
 			}
-			_, err = api.App().Adapter().Notification().DeleteNotifications(ctx, &stores.NotificationFilter{
-				Ids: []uuid.UUID{
-					notificationID,
-				},
-				TeamMemberIds: []uuid.UUID{
-					teamInfo.Member.ID,
-				},
-			})
+			err = api.App().Notification().DeleteNotification(ctx, teamInfo.Member.ID, notificationID)
 			if err != nil {
+				if errors.Is(err, services.ErrNotificationNotFound) {
+					return nil, huma.Error404NotFound("notification not found")
+				}
 				return nil, err
 			}
 			return nil, nil
@@ -0,0 +1,204 @@
+package apis
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/contextstore"
+	"github.com/tkahng/playground/internal/middleware"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/services"
+	"github.com/tkahng/playground/internal/shared"
+)
+
+type TeamRoleDto struct {
+	Name        string   `json:"name" minLength:"1" maxLength:"64" required:"true" doc:"naming a role member or guest overrides the default role"`
+	Description *string  `json:"description,omitempty" required:"false"`
+	Permissions []string `json:"permissions" required:"true" enum:"team:update,team:delete,member:invite,role:manage,sso:manage,billing:manage,project:create,project:update,project:delete,task:create,task:update,task:delete"`
+}
+
+type TeamRoleInput struct {
+	TeamID string `path:"team-id" format:"uuid" required:"true"`
+	RoleID string `path:"role-id" format:"uuid" required:"true"`
+}
+
+type TeamMemberRolesInput struct {
+	TeamID       string `path:"team-id" format:"uuid" required:"true"`
+	TeamMemberID string `path:"team-member-id" format:"uuid" required:"true"`
+}
+
+func (api *Api) BindTeamRoles(aapi huma.API) {
+	teamInfoMiddleware := middleware.TeamInfoFromParam(aapi, api.App())
+	requireMember := middleware.RequireTeamMemberRolesMiddleware(aapi)
+	canManageRoles := middleware.RequireTeamPermissionsMiddleware(aapi, api.App(), models.TeamPermissionRoleManage)
+	operation := func(id, method, path, description string, mw func(huma.Context, func(huma.Context))) huma.Operation {
+		return huma.Operation{
+			OperationID: id,
+			Method:      method,
+			Path:        path,
+			Summary:     id,
+			Description: description,
+			Tags:        []string{"Teams", "Roles"},
+			Errors:      []int{http.StatusInternalServerError, http.StatusBadRequest, http.StatusNotFound, http.StatusConflict},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+			Middlewares: huma.Middlewares{
+				teamInfoMiddleware,
+				mw,
+			},
+		}
+	}
+	huma.Register(aapi, operation("get-team-roles", http.MethodGet, "/teams/{team-id}/roles", "get the roles of a team and the default roles it does not override", requireMember), api.TeamRoles)
+	huma.Register(aapi, operation("create-team-role", http.MethodPost, "/teams/{team-id}/roles", "create a role of a team", canManageRoles), api.TeamRoleCreate)
+	huma.Register(aapi, operation("update-team-role", http.MethodPut, "/teams/{team-id}/roles/{role-id}", "update a role of a team", canManageRoles), api.TeamRoleUpdate)
+	huma.Register(aapi, operation("delete-team-role", http.MethodDelete, "/teams/{team-id}/roles/{role-id}", "delete a role of a team", canManageRoles), api.TeamRoleDelete)
+	huma.Register(aapi, operation("get-team-member-roles", http.MethodGet, "/teams/{team-id}/team-members/{team-member-id}/roles", "get the roles assigned to a team member", requireMember), api.TeamMemberRoles)
+	huma.Register(aapi, operation("assign-team-member-role", http.MethodPost, "/teams/{team-id}/team-members/{team-member-id}/roles", "assign a role to a team member, in the team or in one of its task projects", canManageRoles), api.TeamMemberRoleAssign)
+	huma.Register(aapi, operation("unassign-team-member-role", http.MethodDelete, "/teams/{team-id}/team-members/{team-member-id}/roles/{assignment-id}", "remove a role assigned to a team member", canManageRoles), api.TeamMemberRoleUnassign)
+}
+
+func teamRoleError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrTeamRoleNotFound),
+		errors.Is(err, services.ErrTeamRoleAssignmentNotFound),
+		errors.Is(err, services.ErrTeamMemberNotFound),
+		errors.Is(err, services.ErrTaskProjectNotFound):
+		return huma.Error404NotFound(err.Error())
+	case errors.Is(err, services.ErrTeamRoleInvalid),
+		errors.Is(err, services.ErrTeamRoleReserved),
+		errors.Is(err, services.ErrTeamRoleOwner):
+		return huma.Error400BadRequest(err.Error())
+	case errors.Is(err, services.ErrTeamRoleTaken), errors.Is(err, services.ErrTeamRoleAssigned):
+		return huma.Error409Conflict(err.Error())
+	}
+	return err
+}
+
+func (api *Api) TeamRoles(ctx context.Context, input *struct {
+	TeamID string `path:"team-id" format:"uuid" required:"true"`
+}) (*ApiOutput[[]*models.TeamRole], error) {
+	info := contextstore.GetContextTeamInfo(ctx)
+	if info == nil {
+		return nil, huma.Error401Unauthorized("unauthorized")
+	}
+	roles, err := api.App().TeamRoles().Roles(ctx, info.Team.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &ApiOutput[[]*models.TeamRole]{Body: roles}, nil
+}
+
+func (api *Api) TeamRoleCreate(ctx context.Context, input *struct {
+	TeamID string `path:"team-id" format:"uuid" required:"true"`
+	Body   TeamRoleDto
+}) (*ApiOutput[*models.TeamRole], error) {
+	info := contextstore.GetContextTeamInfo(ctx)
+	if info == nil {
+		return nil, huma.Error401Unauthorized("unauthorized")
+	}
+	role, err := api.App().TeamRoles().CreateRole(ctx, info.Team.ID, &services.TeamRoleInput{
+		Name:        input.Body.Name,
+		Description: input.Body.Description,
+		Permissions: input.Body.Permissions,
+	})
+	if err != nil {
+		return nil, teamRoleError(err)
+	}
+	return &ApiOutput[*models.TeamRole]{Body: role}, nil
+}
+
+func (api *Api) TeamRoleUpdate(ctx context.Context, input *struct {
+	TeamRoleInput
+	Body TeamRoleDto
+}) (*ApiOutput[*models.TeamRole], error) {
+	info := contextstore.GetContextTeamInfo(ctx)
+	if info == nil {
+		return nil, huma.Error401Unauthorized("unauthorized")
+	}
+	roleID, err := uuid.Parse(input.RoleID)
+	if err != nil {
+		return nil, huma.Error400BadRequest("invalid role id")
+	}
+	role, err := api.App().TeamRoles().UpdateRole(ctx, info.Team.ID, roleID, &services.TeamRoleInput{
+		Name:        input.Body.Name,
+		Description: input.Body.Description,
+		Permissions: input.Body.Permissions,
+	})
+	if err != nil {
+		return nil, teamRoleError(err)
+	}
+	return &ApiOutput[*models.TeamRole]{Body: role}, nil
+}
+
+func (api *Api) TeamRoleDelete(ctx context.Context, input *TeamRoleInput) (*struct{}, error) {
+	info := contextstore.GetContextTeamInfo(ctx)
+	if info == nil {
+		return nil, huma.Error401Unauthorized("unauthorized")
+	}
+	roleID, err := uuid.Parse(input.RoleID)
+	if err != nil {
+		return nil, huma.Error400BadRequest("invalid role id")
+	}
+	return nil, teamRoleError(api.App().TeamRoles().DeleteRole(ctx, info.Team.ID, roleID))
+}
+
+func (api *Api) TeamMemberRoles(ctx context.Context, input *TeamMemberRolesInput) (*ApiOutput[[]*models.TeamRoleAssignment], error) {
+	info := contextstore.GetContextTeamInfo(ctx)
+	if info == nil {
+		return nil, huma.Error401Unauthorized("unauthorized")
+	}
+	memberID, err := uuid.Parse(input.TeamMemberID)
+	if err != nil {
+		return nil, huma.Error400BadRequest("invalid team member id")
+	}
+	assignments, err := api.App().TeamRoles().Assignments(ctx, info.Team.ID, memberID)
+	if err != nil {
+		return nil, teamRoleError(err)
+	}
+	return &ApiOutput[[]*models.TeamRoleAssignment]{Body: assignments}, nil
+}
+
+func (api *Api) TeamMemberRoleAssign(ctx context.Context, input *struct {
+	TeamMemberRolesInput
+	Body struct {
+		RoleID        uuid.UUID  `json:"role_id" format:"uuid" required:"true"`
+		TaskProjectID *uuid.UUID `json:"task_project_id,omitempty" format:"uuid" required:"false" doc:"limits the role to a task project of the team"`
+	}
+}) (*ApiOutput[*models.TeamRoleAssignment], error) {
+	info := contextstore.GetContextTeamInfo(ctx)
+	if info == nil {
+		return nil, huma.Error401Unauthorized("unauthorized")
+	}
+	memberID, err := uuid.Parse(input.TeamMemberID)
+	if err != nil {
+		return nil, huma.Error400BadRequest("invalid team member id")
+	}
+	assignment, err := api.App().TeamRoles().AssignRole(ctx, info.Team.ID, memberID, input.Body.RoleID, input.Body.TaskProjectID)
+	if err != nil {
+		return nil, teamRoleError(err)
+	}
+	return &ApiOutput[*models.TeamRoleAssignment]{Body: assignment}, nil
+}
+
+func (api *Api) TeamMemberRoleUnassign(ctx context.Context, input *struct {
+	TeamMemberRolesInput
+	AssignmentID string `path:"assignment-id" format:"uuid" required:"true"`
+}) (*struct{}, error) {
+	info := contextstore.GetContextTeamInfo(ctx)
+	if info == nil {
+		return nil, huma.Error401Unauthorized("unauthorized")
+	}
+	memberID, err := uuid.Parse(input.TeamMemberID)
+	if err != nil {
+		return nil, huma.Error400BadRequest("invalid team member id")
+	}
+	assignmentID, err := uuid.Parse(input.AssignmentID)
+	if err != nil {
+		return nil, huma.Error400BadRequest("invalid assignment id")
+	}
+	return nil, teamRoleError(api.App().TeamRoles().UnassignRole(ctx, info.Team.ID, memberID, assignmentID))
+}
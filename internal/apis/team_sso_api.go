@@ -0,0 +1,187 @@
+package apis
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/auth/oauth"
+	"github.com/tkahng/playground/internal/contextstore"
+	"github.com/tkahng/playground/internal/database"
+	"github.com/tkahng/playground/internal/middleware"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/services"
+	"github.com/tkahng/playground/internal/shared"
+)
+
+type TeamSsoConfigDto struct {
+	Issuer       string                `json:"issuer" format:"uri" required:"true" doc:"the https issuer of a public host serving /.well-known/openid-configuration"`
+	ClientID     string                `json:"client_id" minLength:"1" required:"true"`
+	ClientSecret string                `json:"client_secret" required:"false" doc:"keeps the saved secret when blank"`
+	Scopes       []string              `json:"scopes,omitempty" required:"false" doc:"defaults to openid, email and profile"`
+	DefaultRole  models.TeamMemberRole `json:"default_role" enum:"owner,member,guest" default:"member" required:"false" doc:"the role of members signing in the first time"`
+	Enforced     bool                  `json:"enforced" required:"false" doc:"users of the verified domains can only sign in through the IdP"`
+}
+
+type TeamSsoDomainInput struct {
+	TeamID   string `path:"team-id" format:"uuid" required:"true"`
+	DomainID string `path:"domain-id" format:"uuid" required:"true"`
+}
+
+func (api *Api) BindTeamSso(aapi huma.API) {
+	teamInfoMiddleware := middleware.TeamInfoFromParam(aapi, api.App())
+	canManageSso := middleware.RequireTeamPermissionsMiddleware(aapi, api.App(), models.TeamPermissionSsoManage)
+	operation := func(id, method, path, description string) huma.Operation {
+		return huma.Operation{
+			OperationID: id,
+			Method:      method,
+			Path:        path,
+			Summary:     id,
+			Description: description,
+			Tags:        []string{"Teams", "SSO"},
+			Errors:      []int{http.StatusInternalServerError, http.StatusBadRequest, http.StatusNotFound, http.StatusConflict},
+			Security: []map[string][]string{{
+				shared.BearerAuthSecurityKey: {},
+			}},
+			Middlewares: huma.Middlewares{
+				teamInfoMiddleware,
+				canManageSso,
+			},
+		}
+	}
+	huma.Register(aapi, operation("get-team-sso", http.MethodGet, "/teams/{team-id}/sso", "get the IdP of a team"), api.TeamSsoConfig)
+	huma.Register(aapi, operation("save-team-sso", http.MethodPut, "/teams/{team-id}/sso", "set the IdP of a team"), api.TeamSsoConfigSave)
+	huma.Register(aapi, operation("delete-team-sso", http.MethodDelete, "/teams/{team-id}/sso", "remove the IdP of a team"), api.TeamSsoConfigDelete)
+	huma.Register(aapi, operation("get-team-sso-domains", http.MethodGet, "/teams/{team-id}/sso/domains", "get the email domains of a team"), api.TeamSsoDomains)
+	huma.Register(aapi, operation("create-team-sso-domain", http.MethodPost, "/teams/{team-id}/sso/domains", "add an email domain to a team, it is verified by a TXT record"), api.TeamSsoDomainCreate)
+	huma.Register(aapi, operation("verify-team-sso-domain", http.MethodPost, "/teams/{team-id}/sso/domains/{domain-id}/verify", "verify an email domain of a team"), api.TeamSsoDomainVerify)
+	huma.Register(aapi, operation("delete-team-sso-domain", http.MethodDelete, "/teams/{team-id}/sso/domains/{domain-id}", "remove an email domain of a team"), api.TeamSsoDomainDelete)
+}
+
+func teamSsoError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrSsoNotConfigured), errors.Is(err, services.ErrSsoDomainNotFound):
+		return huma.Error404NotFound(err.Error())
+	case errors.Is(err, services.ErrSsoDomainInvalid), errors.Is(err, services.ErrSsoDomainNotVerified):
+		return huma.Error400BadRequest(err.Error())
+	case errors.Is(err, services.ErrSsoDomainTaken), database.IsUniqConstraintErr(err):
+		return huma.Error409Conflict(err.Error())
+	}
+	return err
+}
+
+func (api *Api) TeamSsoConfig(ctx context.Context, input *struct {
+	TeamID string `path:"team-id" format:"uuid" required:"true"`
+}) (*ApiOutput[*models.TeamSsoConfig], error) {
+	info := contextstore.GetContextTeamInfo(ctx)
+	if info == nil {
+		return nil, huma.Error401Unauthorized("unauthorized")
+	}
+	config, err := api.App().TeamSso().Config(ctx, info.Team.ID)
+	if err != nil {
+		return nil, teamSsoError(err)
+	}
+	return &ApiOutput[*models.TeamSsoConfig]{Body: config}, nil
+}
+
+func (api *Api) TeamSsoConfigSave(ctx context.Context, input *struct {
+	TeamID string `path:"team-id" format:"uuid" required:"true"`
+	Body   TeamSsoConfigDto
+}) (*ApiOutput[*models.TeamSsoConfig], error) {
+	info := contextstore.GetContextTeamInfo(ctx)
+	if info == nil {
+		return nil, huma.Error401Unauthorized("unauthorized")
+	}
+	config, err := api.App().TeamSso().SaveConfig(ctx, info.Team.ID, &services.TeamSsoConfigInput{
+		Issuer:       input.Body.Issuer,
+		ClientID:     input.Body.ClientID,
+		ClientSecret: input.Body.ClientSecret,
+		Scopes:       input.Body.Scopes,
+		DefaultRole:  input.Body.DefaultRole,
+		Enforced:     input.Body.Enforced,
+	})
+	if err != nil {
+		var discoveryErr *services.SsoDiscoveryError
+		if errors.As(err, &discoveryErr) {
+			slog.WarnContext(ctx, "team sso issuer discovery failed", slog.Any("error", discoveryErr.Err))
+			return nil, huma.Error400BadRequest(err.Error())
+		}
+		if errors.Is(err, oauth.ErrIssuerNotPublic) {
+			return nil, huma.Error400BadRequest(err.Error())
+		}
+		return nil, err
+	}
+	return &ApiOutput[*models.TeamSsoConfig]{Body: config}, nil
+}
+
+func (api *Api) TeamSsoConfigDelete(ctx context.Context, input *struct {
+	TeamID string `path:"team-id" format:"uuid" required:"true"`
+}) (*struct{}, error) {
+	info := contextstore.GetContextTeamInfo(ctx)
+	if info == nil {
+		return nil, huma.Error401Unauthorized("unauthorized")
+	}
+	return nil, api.App().TeamSso().DeleteConfig(ctx, info.Team.ID)
+}
+
+func (api *Api) TeamSsoDomains(ctx context.Context, input *struct {
+	TeamID string `path:"team-id" format:"uuid" required:"true"`
+}) (*ApiOutput[[]*models.TeamSsoDomain], error) {
+	info := contextstore.GetContextTeamInfo(ctx)
+	if info == nil {
+		return nil, huma.Error401Unauthorized("unauthorized")
+	}
+	domains, err := api.App().TeamSso().Domains(ctx, info.Team.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &ApiOutput[[]*models.TeamSsoDomain]{Body: domains}, nil
+}
+
+func (api *Api) TeamSsoDomainCreate(ctx context.Context, input *struct {
+	TeamID string `path:"team-id" format:"uuid" required:"true"`
+	Body   struct {
+		Domain string `json:"domain" minLength:"3" maxLength:"253" required:"true" example:"example.com"`
+	}
+}) (*ApiOutput[*models.TeamSsoDomain], error) {
+	info := contextstore.GetContextTeamInfo(ctx)
+	if info == nil {
+		return nil, huma.Error401Unauthorized("unauthorized")
+	}
+	domain, err := api.App().TeamSso().AddDomain(ctx, info.Team.ID, input.Body.Domain)
+	if err != nil {
+		return nil, teamSsoError(err)
+	}
+	return &ApiOutput[*models.TeamSsoDomain]{Body: domain}, nil
+}
+
+func (api *Api) TeamSsoDomainVerify(ctx context.Context, input *TeamSsoDomainInput) (*ApiOutput[*models.TeamSsoDomain], error) {
+	info := contextstore.GetContextTeamInfo(ctx)
+	if info == nil {
+		return nil, huma.Error401Unauthorized("unauthorized")
+	}
+	domainID, err := uuid.Parse(input.DomainID)
+	if err != nil {
+		return nil, huma.Error400BadRequest("invalid domain id")
+	}
+	domain, err := api.App().TeamSso().VerifyDomain(ctx, info.Team.ID, domainID)
+	if err != nil {
+		return nil, teamSsoError(err)
+	}
+	return &ApiOutput[*models.TeamSsoDomain]{Body: domain}, nil
+}
+
+func (api *Api) TeamSsoDomainDelete(ctx context.Context, input *TeamSsoDomainInput) (*struct{}, error) {
+	info := contextstore.GetContextTeamInfo(ctx)
+	if info == nil {
+		return nil, huma.Error401Unauthorized("unauthorized")
+	}
+	domainID, err := uuid.Parse(input.DomainID)
+	if err != nil {
+		return nil, huma.Error400BadRequest("invalid domain id")
+	}
+	return nil, api.App().TeamSso().RemoveDomain(ctx, info.Team.ID, domainID)
+}
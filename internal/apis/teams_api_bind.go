@@ -13,7 +13,9 @@ func BindTeamsApi(api huma.API, appApi *Api) {
 	teamInfoMiddleware := middleware.TeamInfoFromParam(api, appApi.App())
 	teamInfoSlugMiddleware := middleware.TeamInfoFromTeamSlug(api, appApi.App())
 	requireMember := middleware.RequireTeamMemberRolesMiddleware(api)
-	requiredOwnerMember := middleware.RequireTeamMemberRolesMiddleware(api, models.TeamMemberRoleOwner)
+	canUpdateTeam := middleware.RequireTeamPermissionsMiddleware(api, appApi.App(), models.TeamPermissionTeamUpdate)
+	canDeleteTeam := middleware.RequireTeamPermissionsMiddleware(api, appApi.App(), models.TeamPermissionTeamDelete)
+	canInviteMembers := middleware.RequireTeamPermissionsMiddleware(api, appApi.App(), models.TeamPermissionMemberInvite)
 	checkTeamDelete := middleware.TeamCanDelete(api, appApi.App())
 	emailVerified := middleware.EmailVerifiedMiddleware(api)
 	teamsGroup := huma.NewGroup(api)
@@ -151,7 +153,7 @@ func BindTeamsApi(api huma.API, appApi *Api) {
 			}},
 			Middlewares: huma.Middlewares{
 				teamInfoMiddleware,
-				requiredOwnerMember,
+				canUpdateTeam,
 			},
 		},
 		appApi.UpdateTeam,
@@ -173,7 +175,7 @@ func BindTeamsApi(api huma.API, appApi *Api) {
 			}},
 			Middlewares: huma.Middlewares{
 				teamInfoMiddleware,
-				requiredOwnerMember,
+				canDeleteTeam,
 				checkTeamDelete,
 			},
 		},
@@ -198,7 +200,7 @@ func BindTeamsApi(api huma.API, appApi *Api) {
 			}},
 			Middlewares: huma.Middlewares{
 				teamInfoMiddleware,
-				requiredOwnerMember,
+				canInviteMembers,
 			},
 		},
 		appApi.CreateInvitation,
@@ -220,7 +222,7 @@ func BindTeamsApi(api huma.API, appApi *Api) {
 			}},
 			Middlewares: huma.Middlewares{
 				teamInfoMiddleware,
-				requiredOwnerMember,
+				canInviteMembers,
 			},
 		},
 		appApi.CencelInvitation,
@@ -349,7 +351,15 @@ func BindTeamsApi(api huma.API, appApi *Api) {
 
 	appApi.BindReadTeamMembersNotifications(teamsGroup)
 
+	appApi.BindReadAllTeamMembersNotifications(teamsGroup)
+
+	appApi.BindTeamMembersUnreadNotificationsCount(teamsGroup)
+
 	appApi.BindDeleteTeamMembersNotifications(teamsGroup)
 
 	appApi.BindFindTeamMemberByID(teamsGroup)
+
+	appApi.BindTeamSso(teamsGroup)
+
+	appApi.BindTeamRoles(teamsGroup)
 }
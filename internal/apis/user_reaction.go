@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
-	humasse "github.com/danielgtaylor/huma/v2/sse"
 	"github.com/go-chi/httprate"
 	"github.com/tkahng/playground/internal/contextstore"
 	"github.com/tkahng/playground/internal/middleware"
@@ -27,7 +26,7 @@ type UserReactionInput struct {
 }
 
 func (a *Api) BindCreateUserReaction(aapi huma.API) {
-	ipMiddleware := middleware.IpAddressMiddleware(aapi)
+	ipMiddleware := middleware.IpAddressMiddleware(aapi, a.App())
 	rateLimitByIp := middleware.HumaChiMiddleware(httprate.LimitByIP(1, 1*time.Second))
 	huma.Register(
 		aapi,
@@ -130,11 +129,12 @@ func (a *Api) BindGetLatestUserReactionStats(aapi huma.API) {
 }
 
 type UserReactionSseInput struct {
+	LastEventID int64 `header:"Last-Event-ID" required:"false"`
 }
 
 func (api *Api) BindUserReactionSse(humapi huma.API) {
 	hanlder := sse.ServeSSE(
-		func(ctx context.Context, f func(any) error, input *UserReactionSseInput) sse.Client {
+		func(ctx context.Context, f sse.Sender, input *UserReactionSseInput) sse.Client {
 			return sse.NewClient(sse.UserReactionsChannel, f, slog.Default(), func() any {
 				return &PingMessage{
 					Message: "ping",
@@ -144,12 +144,15 @@ func (api *Api) BindUserReactionSse(humapi huma.API) {
 		func(ctx context.Context, cf context.CancelFunc, c sse.Client) {
 			api.app.SseManager().RegisterClient(ctx, cf, c)
 		},
+		func(ctx context.Context, input *UserReactionSseInput, c sse.Client) ([]sse.Message, error) {
+			return api.app.SseManager().Replay(c.Channel(), input.LastEventID), nil
+		},
 		func(c sse.Client) {
 			api.app.SseManager().UnregisterClient(c)
 		},
 		30*time.Second,
 	)
-	humasse.Register(
+	sse.Register(
 		humapi,
 		huma.Operation{
 			OperationID: "user-reaction-sse",
@@ -0,0 +1,304 @@
+package apis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	humasse "github.com/danielgtaylor/huma/v2/sse"
+	"github.com/google/uuid"
+	gws "github.com/gorilla/websocket"
+	"github.com/tkahng/playground/internal/contextstore"
+	"github.com/tkahng/playground/internal/middleware"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/tools/sse"
+	"github.com/tkahng/playground/internal/tools/websocket"
+)
+
+// Requests a websocket client can send, every request is answered with an
+// ack or an error carrying the id of the request.
+const (
+	WsRequestSubscribe   = "subscribe"
+	WsRequestUnsubscribe = "unsubscribe"
+	WsRequestPing        = "ping"
+	// WsRequestSticksJoin waits for an opponent, the ack carries the game and
+	// subscribes to its topic.
+	WsRequestSticksJoin   = "sticks_join"
+	WsRequestSticksAttack = "sticks_attack"
+	WsRequestSticksSplit  = "sticks_split"
+)
+
+// Messages the server sends over a websocket.
+const (
+	WsMessageAck   = "ack"
+	WsMessageError = "error"
+	WsMessageEvent = "event"
+)
+
+const (
+	// wsMaxTopics matches the topics an sse connection may subscribe to at once.
+	wsMaxTopics = 20
+	wsReadLimit = 8 * 1024
+	wsPongWait  = 60 * time.Second
+	wsPing      = 30 * time.Second
+)
+
+type WsRequest struct {
+	ID     string   `json:"id,omitempty"`
+	Type   string   `json:"type"`
+	Topics []string `json:"topics,omitempty"`
+	// LastEventID replays the buffered events of the subscribed topics sent
+	// after it, like the Last-Event-ID header of sse.
+	LastEventID int64 `json:"last_event_id,omitempty"`
+	// GameID, Attack and Split are the move of sticks requests.
+	GameID string           `json:"game_id,omitempty"`
+	Attack *SticksAttackDto `json:"attack,omitempty"`
+	Split  *SticksSplitDto  `json:"split,omitempty"`
+	// Bot is the difficulty of the bot a sticks_join falls back to, medium
+	// when empty.
+	Bot string `json:"bot,omitempty"`
+}
+
+type WsError struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+type WsMessage struct {
+	Type string `json:"type"`
+	// ID is the id of the request an ack or error answers.
+	ID       string   `json:"id,omitempty"`
+	ClientID string   `json:"client_id,omitempty"`
+	Topics   []string `json:"topics,omitempty"`
+	Error    *WsError `json:"error,omitempty"`
+	// EventID, Event and Data are set on events, they are the id, event name
+	// and data the same message has over sse.
+	EventID int64  `json:"event_id,omitempty"`
+	Event   string `json:"event,omitempty"`
+	Data    any    `json:"data,omitempty"`
+}
+
+type WebsocketInput struct {
+	Topics      []string `query:"topics" required:"false" maxItems:"20" uniqueItems:"true" doc:"topics to subscribe to, eg. team_member:<id>, project:<id> or reactions"`
+	AccessToken string   `query:"access_token" required:"false"`
+}
+
+func (api *Api) BindWebsocket(humapi huma.API) {
+	huma.Register(
+		humapi,
+		huma.Operation{
+			OperationID: "websocket",
+			Method:      http.MethodGet,
+			Path:        "/ws",
+			Summary:     "websocket",
+			Description: "Websocket carrying the sse topics. The token is read from the Authorization header, the access_token cookie or query. " +
+				"Clients send subscribe, unsubscribe and ping requests and get an ack or error for each, events arrive as event messages. " +
+				"Sticks is played with sticks_join, sticks_attack and sticks_split requests, bot picks the bot difficulty of sticks_join.",
+			Tags: []string{"Events"},
+			Middlewares: huma.Middlewares{
+				middleware.RequireTokenAuthMiddleware(humapi, api.app, middleware.HumaWebsocketTokenFuncs...),
+				api.sseTopicsMiddleware(humapi),
+			},
+			Errors: []int{http.StatusUnauthorized, http.StatusBadRequest, http.StatusForbidden},
+		},
+		api.Websocket,
+	)
+}
+
+func (api *Api) Websocket(ctx context.Context, input *WebsocketInput) (*huma.StreamResponse, error) {
+	userInfo := contextstore.GetContextUserInfo(ctx)
+	if userInfo == nil {
+		return nil, huma.Error401Unauthorized("unauthorized")
+	}
+	// the connection outlives the upgrade request
+	handler := api.websocketHandler(context.WithoutCancel(ctx), &userInfo.User, input.Topics)
+	return &huma.StreamResponse{
+		Body: func(ctx huma.Context) {
+			r, w := humachi.Unwrap(ctx)
+			handler(w, r)
+		},
+	}, nil
+}
+
+// websocketHandler upgrades a connection of the user. The connection is
+// registered with the sse manager like an sse topics client, so it receives
+// the same messages and its topics can also be changed over http.
+func (api *Api) websocketHandler(ctx context.Context, user *models.User, topics []string) http.HandlerFunc {
+	var hosts []string
+	if appUrl, err := url.Parse(api.app.Config().AppUrl); err == nil {
+		hosts = append(hosts, appUrl.Host)
+	}
+	var (
+		topicsClient sse.Client
+		destroyOnce  sync.Once
+		wsClient     websocket.Client
+		// connCtx is done once the connection closed
+		connCtx context.Context
+	)
+	destroy := func() {
+		destroyOnce.Do(func() {
+			api.app.SseManager().UnregisterClient(topicsClient)
+			api.app.WebsocketManager().UnregisterClient(wsClient)
+		})
+	}
+	return websocket.ServeWS(
+		websocket.SameOriginUpgrader(hosts...),
+		websocket.SetupConn(wsReadLimit, wsPongWait),
+		func(conn *gws.Conn) websocket.Client {
+			c := websocket.NewClient(conn)
+			// nolint:errcheck
+			c.SetLogger(slog.Default())
+			return c
+		},
+		func(cctx context.Context, cf context.CancelFunc, c websocket.Client) {
+			wsClient, connCtx = c, cctx
+			api.app.WebsocketManager().RegisterClient(connCtx, cf, c)
+			id := uuid.NewString()
+			topicsClient = sse.NewClient(sse.ClientChannel(id), wsSender(c), slog.Default(), nil,
+				sse.WithID(id),
+				sse.WithOwner(user.ID.String()),
+				sse.WithTopics(topics...),
+			)
+			sseCtx, sseCancel := context.WithCancel(connCtx)
+			api.app.SseManager().RegisterClient(sseCtx, sseCancel, topicsClient)
+			go topicsClient.WriteForever(sseCtx, func(sse.Client) { destroy() }, wsPing)
+			writeWsMessage(c, WsMessage{Type: WsMessageAck, ClientID: id, Topics: topicsClient.Topics()})
+		},
+		func(websocket.Client) {
+			destroy()
+		},
+		wsPing,
+		[]websocket.MessageHandler{
+			func(c websocket.Client, payload []byte) {
+				api.handleWsRequest(ctx, connCtx, user, c, topicsClient, payload)
+			},
+		},
+	)
+}
+
+// wsSender writes the messages of an sse client to a websocket as events.
+func wsSender(c websocket.Client) sse.Sender {
+	return func(m humasse.Message) error {
+		b, err := json.Marshal(WsMessage{
+			Type:    WsMessageEvent,
+			EventID: int64(m.ID),
+			Event:   sse.EventName(m.Data),
+			Data:    m.Data,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = c.Write(b)
+		return err
+	}
+}
+
+func writeWsMessage(c websocket.Client, msg WsMessage) {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		slog.Error("error encoding websocket message", slog.Any("error", err))
+		return
+	}
+	if _, err := c.Write(b); err != nil {
+		slog.Error("error writing websocket message", slog.Any("error", err))
+	}
+}
+
+func wsErrorMessage(id string, err error) WsMessage {
+	msg := WsMessage{
+		Type:  WsMessageError,
+		ID:    id,
+		Error: &WsError{Status: http.StatusInternalServerError, Message: "internal error"},
+	}
+	var se huma.StatusError
+	if errors.As(err, &se) {
+		msg.Error = &WsError{Status: se.GetStatus(), Message: se.Error()}
+	}
+	return msg
+}
+
+// handleWsRequest answers one request of a websocket client.
+func (api *Api) handleWsRequest(ctx context.Context, connCtx context.Context, user *models.User, c websocket.Client, topicsClient sse.Client, payload []byte) {
+	var req WsRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		writeWsMessage(c, wsErrorMessage("", huma.Error400BadRequest("invalid request")))
+		return
+	}
+	switch req.Type {
+	case WsRequestPing:
+		writeWsMessage(c, WsMessage{Type: WsMessageAck, ID: req.ID})
+	case WsRequestSubscribe:
+		if len(req.Topics) == 0 || len(req.Topics) > wsMaxTopics {
+			writeWsMessage(c, wsErrorMessage(req.ID, huma.Error400BadRequest("between 1 and 20 topics are required")))
+			return
+		}
+		if err := api.authorizeSseTopics(ctx, user.ID, req.Topics); err != nil {
+			writeWsMessage(c, wsErrorMessage(req.ID, err))
+			return
+		}
+		topicsClient.Subscribe(req.Topics...)
+		writeWsMessage(c, WsMessage{Type: WsMessageAck, ID: req.ID, ClientID: topicsClient.ID(), Topics: topicsClient.Topics()})
+		if req.LastEventID > 0 {
+			for _, m := range sse.ReplayTopics(api.app.SseManager(), req.Topics, req.LastEventID) {
+				if err := topicsClient.Write(m); err != nil {
+					slog.ErrorContext(ctx, "error replaying websocket messages", slog.Any("error", err))
+					return
+				}
+			}
+		}
+	case WsRequestUnsubscribe:
+		topicsClient.Unsubscribe(req.Topics...)
+		writeWsMessage(c, WsMessage{Type: WsMessageAck, ID: req.ID, ClientID: topicsClient.ID(), Topics: topicsClient.Topics()})
+	case WsRequestSticksJoin:
+		difficulty, err := sticksDifficulty(req.Bot)
+		if err != nil {
+			writeWsMessage(c, wsErrorMessage(req.ID, err))
+			return
+		}
+		// matchmaking blocks, the connection keeps reading meanwhile
+		go func() {
+			joinCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			stop := context.AfterFunc(connCtx, cancel)
+			defer stop()
+			view, err := api.app.Sticks().Join(joinCtx, user, difficulty)
+			if err != nil {
+				writeWsMessage(c, wsErrorMessage(req.ID, sticksError(err)))
+				return
+			}
+			topicsClient.Subscribe(sse.SticksGameChannel(view.ID))
+			writeWsMessage(c, WsMessage{Type: WsMessageAck, ID: req.ID, ClientID: topicsClient.ID(), Topics: topicsClient.Topics(), Data: view})
+		}()
+	case WsRequestSticksAttack:
+		if req.Attack == nil {
+			writeWsMessage(c, wsErrorMessage(req.ID, huma.Error400BadRequest("attack is required")))
+			return
+		}
+		view, err := api.app.Sticks().Attack(ctx, user.ID, req.GameID, req.Attack.AttackWithLeft, req.Attack.AttackLeft)
+		if err != nil {
+			writeWsMessage(c, wsErrorMessage(req.ID, sticksError(err)))
+			return
+		}
+		writeWsMessage(c, WsMessage{Type: WsMessageAck, ID: req.ID, Data: view})
+	case WsRequestSticksSplit:
+		if req.Split == nil {
+			writeWsMessage(c, wsErrorMessage(req.ID, huma.Error400BadRequest("split is required")))
+			return
+		}
+		view, err := api.app.Sticks().Split(ctx, user.ID, req.GameID, req.Split.FromLeft, req.Split.Points)
+		if err != nil {
+			writeWsMessage(c, wsErrorMessage(req.ID, sticksError(err)))
+			return
+		}
+		writeWsMessage(c, WsMessage{Type: WsMessageAck, ID: req.ID, Data: view})
+	default:
+		writeWsMessage(c, wsErrorMessage(req.ID, huma.Error400BadRequest("unknown request type "+req.Type)))
+	}
+}
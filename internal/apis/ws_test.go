@@ -0,0 +1,252 @@
+package apis_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	gws "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkahng/playground/internal/apis"
+	"github.com/tkahng/playground/internal/conf"
+	"github.com/tkahng/playground/internal/core"
+	"github.com/tkahng/playground/internal/games/sticks"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/services"
+	"github.com/tkahng/playground/internal/stores"
+	"github.com/tkahng/playground/internal/tools/sse"
+	"github.com/tkahng/playground/internal/tools/websocket"
+)
+
+// newWebsocketTestServer serves the websocket for users, the access token of
+// a user is their id.
+func newWebsocketTestServer(t *testing.T, member *models.TeamMember, users ...*models.User) (*httptest.Server, sse.Manager) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	sseManager := sse.NewManager(nil)
+	go sseManager.Run(ctx)
+	wsManager := websocket.NewManager()
+	go wsManager.Run(ctx)
+
+	cfg := conf.ZeroEnvConfig()
+	adapter := stores.NewAdapterDecorators()
+	adapter.TeamMemberFunc.FindTeamMemberFunc = func(ctx context.Context, filter *stores.TeamMemberFilter) (*models.TeamMember, error) {
+		if slices.Contains(filter.Ids, member.ID) && slices.Contains(filter.UserIds, *member.UserID) {
+			return member, nil
+		}
+		return nil, nil
+	}
+	adapter.RunInTxFunc = func(fn func(tx stores.StorageAdapterInterface) error) error {
+		return fn(adapter)
+	}
+	adapter.GameFunc.LockGameRatingsFunc = func(ctx context.Context, game string, userIDs []uuid.UUID) ([]*models.GameRating, error) {
+		var ratings []*models.GameRating
+		for _, id := range userIDs {
+			ratings = append(ratings, &models.GameRating{UserID: id, Game: game, Rating: 1200})
+		}
+		return ratings, nil
+	}
+	adapter.GameFunc.UpdateGameRatingFunc = func(ctx context.Context, rating *models.GameRating) error {
+		return nil
+	}
+	adapter.GameFunc.CreateGameResultFunc = func(ctx context.Context, result *models.GameResult) (*models.GameResult, error) {
+		return result, nil
+	}
+	broker := sticks.NewGameBroker(10)
+	broker.Start()
+	t.Cleanup(broker.Stop)
+	sticksService := services.NewSticksService(adapter, sseManager, broker, 0)
+	auth := &services.AuthServiceDecorator{
+		HandleAccessTokenFunc: func(ctx context.Context, token string) (*models.UserInfo, error) {
+			for _, user := range users {
+				if token == user.ID.String() {
+					return &models.UserInfo{User: *user}, nil
+				}
+			}
+			return nil, errors.New("invalid token")
+		},
+	}
+	app := &core.BaseAppDecorator{
+		AuthFunc:             func() services.AuthService { return auth },
+		AdapterFunc:          func() stores.StorageAdapterInterface { return adapter },
+		CfgFunc:              func() *conf.EnvConfig { return &cfg },
+		SseManagerFunc:       func() sse.Manager { return sseManager },
+		WebsocketManagerFunc: func() websocket.Manager { return wsManager },
+		SticksFunc:           func() services.SticksService { return sticksService },
+	}
+
+	r := chi.NewMux()
+	api := humachi.New(r, huma.DefaultConfig("test", "1.0.0"))
+	appApi := apis.NewApi(app)
+	apis.BindMiddlewares(api, app)
+	appApi.BindSseTopics(api)
+	appApi.BindWebsocket(api)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return server, sseManager
+}
+
+func dialWebsocket(t *testing.T, server *httptest.Server, header http.Header) (*gws.Conn, *http.Response, error) {
+	conn, resp, err := gws.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", header)
+	if conn != nil {
+		t.Cleanup(func() { conn.Close() })
+	}
+	return conn, resp, err
+}
+
+func readWsMessage(t *testing.T, conn *gws.Conn) apis.WsMessage {
+	var msg apis.WsMessage
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	require.NoError(t, conn.ReadJSON(&msg))
+	return msg
+}
+
+func TestWebsocket(t *testing.T) {
+	user := &models.User{ID: uuid.New(), Email: "ws@example.com"}
+	member := &models.TeamMember{ID: uuid.New(), TeamID: uuid.New(), UserID: &user.ID}
+	server, sseManager := newWebsocketTestServer(t, member, user)
+
+	t.Run("rejects connections without a token", func(t *testing.T) {
+		_, resp, err := dialWebsocket(t, server, nil)
+		require.Error(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("speaks the protocol", func(t *testing.T) {
+		header := http.Header{}
+		header.Set("Cookie", "access_token="+user.ID.String())
+		conn, _, err := dialWebsocket(t, server, header)
+		require.NoError(t, err)
+
+		welcome := readWsMessage(t, conn)
+		assert.Equal(t, apis.WsMessageAck, welcome.Type)
+		assert.NotEmpty(t, welcome.ClientID)
+
+		require.NoError(t, conn.WriteJSON(apis.WsRequest{ID: "1", Type: apis.WsRequestPing}))
+		pong := readWsMessage(t, conn)
+		assert.Equal(t, apis.WsMessageAck, pong.Type)
+		assert.Equal(t, "1", pong.ID)
+
+		topic := sse.TeamMemberChannel(member.ID.String())
+		require.NoError(t, conn.WriteJSON(apis.WsRequest{ID: "2", Type: apis.WsRequestSubscribe, Topics: []string{topic}}))
+		subscribed := readWsMessage(t, conn)
+		assert.Equal(t, apis.WsMessageAck, subscribed.Type)
+		assert.Equal(t, "2", subscribed.ID)
+		assert.Equal(t, []string{topic}, subscribed.Topics)
+
+		require.NoError(t, sseManager.SendMessage(topic, sse.Message{
+			ID:   7,
+			Data: &services.UnreadCountSseEvent{TeamMemberID: member.ID, Count: 3},
+		}))
+		event := readWsMessage(t, conn)
+		assert.Equal(t, apis.WsMessageEvent, event.Type)
+		assert.Equal(t, "unread_count", event.Event)
+		assert.Equal(t, int64(7), event.EventID)
+
+		other := sse.TeamMemberChannel(uuid.NewString())
+		require.NoError(t, conn.WriteJSON(apis.WsRequest{ID: "3", Type: apis.WsRequestSubscribe, Topics: []string{other}}))
+		forbidden := readWsMessage(t, conn)
+		assert.Equal(t, apis.WsMessageError, forbidden.Type)
+		assert.Equal(t, "3", forbidden.ID)
+		require.NotNil(t, forbidden.Error)
+		assert.Equal(t, http.StatusForbidden, forbidden.Error.Status)
+
+		require.NoError(t, conn.WriteJSON(apis.WsRequest{ID: "4", Type: apis.WsRequestUnsubscribe, Topics: []string{topic}}))
+		unsubscribed := readWsMessage(t, conn)
+		assert.Equal(t, apis.WsMessageAck, unsubscribed.Type)
+		assert.Empty(t, unsubscribed.Topics)
+
+		require.NoError(t, conn.WriteJSON(apis.WsRequest{ID: "5", Type: "shout"}))
+		unknown := readWsMessage(t, conn)
+		assert.Equal(t, apis.WsMessageError, unknown.Type)
+		assert.Equal(t, http.StatusBadRequest, unknown.Error.Status)
+	})
+}
+
+func dialWebsocketAs(t *testing.T, server *httptest.Server, user *models.User) *gws.Conn {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+user.ID.String())
+	conn, _, err := dialWebsocket(t, server, header)
+	require.NoError(t, err)
+	welcome := readWsMessage(t, conn)
+	require.Equal(t, apis.WsMessageAck, welcome.Type)
+	return conn
+}
+
+// readWsAck skips events until the answer of the request with id.
+func readWsAck(t *testing.T, conn *gws.Conn, id string) apis.WsMessage {
+	for {
+		msg := readWsMessage(t, conn)
+		if msg.Type != apis.WsMessageEvent {
+			require.Equal(t, id, msg.ID)
+			return msg
+		}
+	}
+}
+
+func TestWebsocketSticks(t *testing.T) {
+	alice := &models.User{ID: uuid.New()}
+	bob := &models.User{ID: uuid.New()}
+	member := &models.TeamMember{ID: uuid.New(), UserID: &alice.ID}
+	server, _ := newWebsocketTestServer(t, member, alice, bob)
+
+	conns := map[string]*gws.Conn{
+		alice.ID.String(): dialWebsocketAs(t, server, alice),
+		bob.ID.String():   dialWebsocketAs(t, server, bob),
+	}
+	for _, conn := range conns {
+		require.NoError(t, conn.WriteJSON(apis.WsRequest{ID: "join", Type: apis.WsRequestSticksJoin}))
+	}
+	var gameID, current string
+	for _, conn := range conns {
+		joined := readWsAck(t, conn, "join")
+		require.Equal(t, apis.WsMessageAck, joined.Type)
+		game := joined.Data.(map[string]any)
+		gameID, current = game["id"].(string), game["current_player_id"].(string)
+		assert.Contains(t, joined.Topics, sse.SticksGameChannel(gameID))
+	}
+
+	var waiting string
+	for id := range conns {
+		if id != current {
+			waiting = id
+		}
+	}
+	require.NoError(t, conns[waiting].WriteJSON(apis.WsRequest{
+		ID:     "early",
+		Type:   apis.WsRequestSticksAttack,
+		GameID: gameID,
+		Attack: &apis.SticksAttackDto{AttackWithLeft: true, AttackLeft: true},
+	}))
+	early := readWsAck(t, conns[waiting], "early")
+	require.Equal(t, apis.WsMessageError, early.Type)
+	assert.Equal(t, http.StatusConflict, early.Error.Status)
+
+	require.NoError(t, conns[current].WriteJSON(apis.WsRequest{
+		ID:     "attack",
+		Type:   apis.WsRequestSticksAttack,
+		GameID: gameID,
+		Attack: &apis.SticksAttackDto{AttackWithLeft: true, AttackLeft: true},
+	}))
+	attacked := readWsAck(t, conns[current], "attack")
+	require.Equal(t, apis.WsMessageAck, attacked.Type)
+
+	// the opponent sees the move on the game topic
+	event := readWsMessage(t, conns[waiting])
+	assert.Equal(t, apis.WsMessageEvent, event.Type)
+	assert.Equal(t, "sticks_game", event.Event)
+	game := event.Data.(map[string]any)["game"].(map[string]any)
+	assert.Equal(t, waiting, game["current_player_id"])
+}
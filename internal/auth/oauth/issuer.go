@@ -0,0 +1,73 @@
+package oauth
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrIssuerNotPublic is returned for issuers that are not https urls of
+// public hosts, they could point requests of the server at internal services.
+var ErrIssuerNotPublic = errors.New("issuer must be an https url of a public host")
+
+// ValidatePublicIssuer checks the issuer is an https url whose host is not
+// a loopback, private or link-local address. Host names are checked when
+// NewPublicHTTPClient dials them.
+func ValidatePublicIssuer(issuer string) error {
+	u, err := url.Parse(issuer)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" || u.User != nil {
+		return ErrIssuerNotPublic
+	}
+	if ip := net.ParseIP(u.Hostname()); ip != nil && !isPublicIP(ip) {
+		return ErrIssuerNotPublic
+	}
+	return nil
+}
+
+// NewPublicHTTPClient returns a client for issuers that users configure. It
+// checks the resolved address of every connection, so redirects and DNS
+// records pointing at internal hosts are refused too.
+func NewPublicHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return ErrIssuerNotPublic
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// a proxy would be dialed instead of the issuer
+	transport.Proxy = nil
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if req.URL.Scheme != "https" {
+				return ErrIssuerNotPublic
+			}
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			return nil
+		},
+	}
+}
+
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsUnspecified()
+}
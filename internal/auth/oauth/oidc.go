@@ -0,0 +1,476 @@
+package oauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+// DiscoveryPath is where an issuer serves its OpenID Connect discovery
+// document.
+const DiscoveryPath = "/.well-known/openid-configuration"
+
+// ErrOIDCEmailNotVerified refuses sign ins through issuers that did not
+// verify the email of the user, users are matched by their email.
+var ErrOIDCEmailNotVerified = errors.New("OIDC id token has no verified email")
+
+// jwksRefreshInterval limits how often the keys of an issuer are fetched
+// again for an id token signed with an unknown key.
+const jwksRefreshInterval = time.Minute
+
+// OIDCDiscovery is the part of an OpenID Connect discovery document the
+// provider uses.
+type OIDCDiscovery struct {
+	Issuer                           string   `json:"issuer"`
+	AuthorizationEndpoint            string   `json:"authorization_endpoint"`
+	TokenEndpoint                    string   `json:"token_endpoint"`
+	UserinfoEndpoint                 string   `json:"userinfo_endpoint"`
+	JwksURI                          string   `json:"jwks_uri"`
+	IDTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported"`
+}
+
+// OIDCOptions configure a generic OpenID Connect provider like Keycloak, Okta
+// or Azure AD. The endpoints come from the discovery document of the issuer.
+type OIDCOptions struct {
+	// Name identifies the provider in urls, state tokens and user accounts.
+	Name         string
+	DisplayName  string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// Scopes default to openid, email and profile.
+	Scopes      []string
+	RedirectURL string
+	// HTTPClient is used for discovery and keys, it defaults to a client
+	// with a timeout.
+	HTTPClient *http.Client
+}
+
+// OIDCConfig is a provider that signs users in with OpenID Connect. The
+// discovery document and signing keys of the issuer are fetched on first
+// use and cached.
+type OIDCConfig struct {
+	options OIDCOptions
+
+	mu        sync.Mutex
+	discovery *OIDCDiscovery
+	keys      map[string]crypto.PublicKey
+	keysAt    time.Time
+}
+
+var _ NonceProvider = (*OIDCConfig)(nil)
+
+func NewOIDCProvider(opts OIDCOptions) *OIDCConfig {
+	if len(opts.Scopes) == 0 {
+		opts.Scopes = []string{"openid", "email", "profile"}
+	} else if !slices.Contains(opts.Scopes, "openid") {
+		opts.Scopes = append([]string{"openid"}, opts.Scopes...)
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if opts.DisplayName == "" {
+		opts.DisplayName = opts.Name
+	}
+	return &OIDCConfig{options: opts}
+}
+
+func (p *OIDCConfig) Name() string {
+	return p.options.Name
+}
+
+func (p *OIDCConfig) DisplayName() string {
+	return p.options.DisplayName
+}
+
+func (p *OIDCConfig) Issuer() string {
+	return p.options.Issuer
+}
+
+func (p *OIDCConfig) Active() bool {
+	return true
+}
+
+// Pkce implements ProviderConfig. Issuers without PKCE ignore the params.
+func (p *OIDCConfig) Pkce() bool {
+	return true
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	// the body is not part of errors, they can reach the users that
+	// configured the url
+	if res.StatusCode >= 400 {
+		return fmt.Errorf("failed to fetch %s: status %d", url, res.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// Discover fetches the discovery document of an issuer and checks that it
+// belongs to the issuer.
+func Discover(ctx context.Context, client *http.Client, issuer string) (*OIDCDiscovery, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	var discovery OIDCDiscovery
+	if err := getJSON(ctx, client, strings.TrimSuffix(issuer, "/")+DiscoveryPath, &discovery); err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC issuer %s: %w", issuer, err)
+	}
+	if discovery.Issuer != issuer {
+		return nil, fmt.Errorf("OIDC discovery issuer %q does not match %q", discovery.Issuer, issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JwksURI == "" {
+		return nil, fmt.Errorf("OIDC discovery of %s misses endpoints", issuer)
+	}
+	return &discovery, nil
+}
+
+// Discovery returns the discovery document of the issuer, failures are not
+// cached so an issuer that was down is tried again.
+func (p *OIDCConfig) Discovery(ctx context.Context) (*OIDCDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+	discovery, err := Discover(ctx, p.options.HTTPClient, p.options.Issuer)
+	if err != nil {
+		return nil, err
+	}
+	p.discovery = discovery
+	return discovery, nil
+}
+
+func (p *OIDCConfig) oauth2Provider(ctx context.Context) (*OAuth2ProviderConfig, error) {
+	discovery, err := p.Discovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &OAuth2ProviderConfig{
+		ClientID:     p.options.ClientID,
+		ClientSecret: p.options.ClientSecret,
+		Enabled:      true,
+		AuthURL:      discovery.AuthorizationEndpoint,
+		TokenURL:     discovery.TokenEndpoint,
+		PKCE:         true,
+		UserInfoURL:  discovery.UserinfoEndpoint,
+		Name:         p.options.DisplayName,
+		Scopes:       p.options.Scopes,
+		RedirectURL:  p.options.RedirectURL,
+	}, nil
+}
+
+// BuildAuthURL implements ProviderConfig, it is empty when the issuer can not
+// be discovered.
+func (p *OIDCConfig) BuildAuthURL(state string, opts ...oauth2.AuthCodeOption) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	provider, err := p.oauth2Provider(ctx)
+	if err != nil {
+		slog.Error("error building OIDC auth url", slog.String("provider", p.options.Name), slog.Any("error", err))
+		return ""
+	}
+	return provider.BuildAuthURL(state, opts...)
+}
+
+// FetchTokenOptions implements ProviderConfig.
+func (p *OIDCConfig) FetchTokenOptions(verifier string) []oauth2.AuthCodeOption {
+	return []oauth2.AuthCodeOption{
+		oauth2.SetAuthURLParam("code_verifier", verifier),
+	}
+}
+
+// FetchToken implements ProviderConfig.
+func (p *OIDCConfig) FetchToken(ctx context.Context, code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
+	provider, err := p.oauth2Provider(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return provider.FetchToken(ctx, code, opts...)
+}
+
+// Client implements ProviderConfig.
+func (p *OIDCConfig) Client(ctx context.Context, token *oauth2.Token) *http.Client {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.options.HTTPClient)
+	return oauth2.NewClient(ctx, oauth2.StaticTokenSource(token))
+}
+
+// FetchRawUserInfo implements ProviderConfig.
+func (p *OIDCConfig) FetchRawUserInfo(ctx context.Context, token *oauth2.Token) ([]byte, error) {
+	provider, err := p.oauth2Provider(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if provider.UserInfoURL == "" {
+		return nil, fmt.Errorf("OIDC issuer %s has no userinfo endpoint", p.options.Issuer)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, provider.UserInfoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := p.Client(ctx, token)
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	// like getJSON the body is kept out of errors
+	if res.StatusCode >= 400 {
+		return nil, fmt.Errorf("failed to fetch OIDC userinfo via %s: status %d", provider.UserInfoURL, res.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(res.Body, 1<<20))
+}
+
+// FetchAuthUser implements ProviderConfig. Id tokens are only accepted with
+// the nonce of their auth request, use FetchAuthUserWithNonce.
+func (p *OIDCConfig) FetchAuthUser(ctx context.Context, token *oauth2.Token) (*AuthUser, error) {
+	return p.FetchAuthUserWithNonce(ctx, token, "")
+}
+
+// FetchAuthUserWithNonce implements NonceProvider. The user comes from the
+// claims of the verified id token, users without an email verified by the
+// issuer are refused.
+func (p *OIDCConfig) FetchAuthUserWithNonce(ctx context.Context, token *oauth2.Token, nonce string) (*AuthUser, error) {
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, errors.New("OIDC token response has no id_token")
+	}
+	claims, err := p.VerifyIDToken(ctx, rawIDToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+	var extracted struct {
+		Id                string `json:"sub"`
+		Name              string `json:"name"`
+		PreferredUsername string `json:"preferred_username"`
+		Email             string `json:"email"`
+		EmailVerified     any    `json:"email_verified"`
+		Picture           string `json:"picture"`
+	}
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &extracted); err != nil {
+		return nil, err
+	}
+	if extracted.Id == "" {
+		return nil, errors.New("OIDC id token has no subject")
+	}
+
+	user := &AuthUser{
+		Id:           extracted.Id,
+		Name:         extracted.Name,
+		Username:     extracted.PreferredUsername,
+		AvatarURL:    extracted.Picture,
+		RawUser:      claims,
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		Expiry:       token.Expiry,
+	}
+	if user.Username == "" {
+		user.Username = extracted.Name
+	}
+	// some issuers send the flag as a string
+	switch verified := extracted.EmailVerified.(type) {
+	case bool:
+		if verified {
+			user.Email = extracted.Email
+		}
+	case string:
+		if verified == "true" {
+			user.Email = extracted.Email
+		}
+	}
+	if user.Email == "" {
+		return nil, ErrOIDCEmailNotVerified
+	}
+	return user, nil
+}
+
+// VerifyIDToken checks the signature of an id token against the keys of the
+// issuer, its issuer, audience, expiry and the nonce of its auth request, and
+// returns its claims.
+func (p *OIDCConfig) VerifyIDToken(ctx context.Context, rawIDToken string, nonce string) (jwt.MapClaims, error) {
+	discovery, err := p.Discovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	algs := slices.DeleteFunc(slices.Clone(discovery.IDTokenSigningAlgValuesSupported), func(alg string) bool {
+		return alg == "none" || strings.HasPrefix(alg, "HS")
+	})
+	if len(algs) == 0 {
+		algs = []string{"RS256"}
+	}
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(
+		rawIDToken,
+		claims,
+		func(token *jwt.Token) (any, error) {
+			kid, _ := token.Header["kid"].(string)
+			return p.key(ctx, discovery.JwksURI, kid)
+		},
+		jwt.WithValidMethods(algs),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(p.options.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OIDC id token: %w", err)
+	}
+	if azp, ok := claims["azp"].(string); ok && azp != p.options.ClientID {
+		return nil, fmt.Errorf("invalid OIDC id token: authorized party %q is not the client", azp)
+	}
+	if claimed, _ := claims["nonce"].(string); nonce == "" || subtle.ConstantTimeCompare([]byte(claimed), []byte(nonce)) != 1 {
+		return nil, errors.New("invalid OIDC id token: nonce does not match the auth request")
+	}
+	return claims, nil
+}
+
+// key returns the signing key with the id, the keys are fetched again when
+// the issuer rotated them.
+func (p *OIDCConfig) key(ctx context.Context, jwksURI string, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key := lookupKey(p.keys, kid); key != nil {
+		return key, nil
+	}
+	if time.Since(p.keysAt) < jwksRefreshInterval {
+		return nil, fmt.Errorf("unknown OIDC signing key %q", kid)
+	}
+	keys, err := fetchJWKS(ctx, p.options.HTTPClient, jwksURI)
+	if err != nil {
+		return nil, err
+	}
+	p.keys, p.keysAt = keys, time.Now()
+	if key := lookupKey(p.keys, kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown OIDC signing key %q", kid)
+}
+
+// lookupKey finds a key by id, tokens without an id need a single key.
+func lookupKey(keys map[string]crypto.PublicKey, kid string) crypto.PublicKey {
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key
+		}
+	}
+	return keys[kid]
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// fetchJWKS fetches the signing keys of an issuer by id, keys of unknown
+// types are skipped.
+func fetchJWKS(ctx context.Context, client *http.Client, jwksURI string) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := getJSON(ctx, client, jwksURI, &set); err != nil {
+		return nil, fmt.Errorf("failed to fetch OIDC keys: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			slog.WarnContext(ctx, "skipping OIDC key", slog.String("kid", jwk.Kid), slog.Any("error", err))
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
@@ -0,0 +1,251 @@
+package oauth_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkahng/playground/internal/auth/oauth"
+	"golang.org/x/oauth2"
+)
+
+// fakeIssuer is an OpenID Connect issuer that answers every code with an id
+// token of its claims.
+type fakeIssuer struct {
+	*httptest.Server
+	key    *rsa.PrivateKey
+	claims func(issuer string) jwt.MapClaims
+	// signer signs id tokens, it defaults to the key of the issuer
+	signer *rsa.PrivateKey
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	issuer := &fakeIssuer{key: key}
+	issuer.claims = func(iss string) jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":                iss,
+			"sub":                "user-1",
+			"aud":                "client-1",
+			"exp":                time.Now().Add(time.Hour).Unix(),
+			"iat":                time.Now().Unix(),
+			"email":              "oidc@example.com",
+			"email_verified":     true,
+			"name":               "Oidc User",
+			"preferred_username": "oidc",
+			"picture":            "https://example.com/avatar.png",
+			"nonce":              "nonce-1",
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{
+			"issuer":                                issuer.URL,
+			"authorization_endpoint":                issuer.URL + "/authorize",
+			"token_endpoint":                        issuer.URL + "/token",
+			"userinfo_endpoint":                     issuer.URL + "/userinfo",
+			"jwks_uri":                              issuer.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "key-1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "code-1" || r.FormValue("code_verifier") != "verifier-1" {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, issuer.claims(issuer.URL))
+		token.Header["kid"] = "key-1"
+		signer := issuer.signer
+		if signer == nil {
+			signer = key
+		}
+		idToken, err := token.SignedString(signer)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, map[string]any{
+			"access_token": "access-1",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     idToken,
+		})
+	})
+	issuer.Server = httptest.NewServer(mux)
+	t.Cleanup(issuer.Close)
+	return issuer
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (f *fakeIssuer) provider() *oauth.OIDCConfig {
+	return oauth.NewOIDCProvider(oauth.OIDCOptions{
+		Name:         "keycloak",
+		Issuer:       f.URL,
+		ClientID:     "client-1",
+		ClientSecret: "secret-1",
+		RedirectURL:  "https://app.example.com/auth/callback",
+		HTTPClient:   f.Client(),
+	})
+}
+
+func (f *fakeIssuer) signIn(t *testing.T, provider *oauth.OIDCConfig) (*oauth.AuthUser, error) {
+	ctx := context.Background()
+	token, err := provider.FetchToken(ctx, "code-1", provider.FetchTokenOptions("verifier-1")...)
+	require.NoError(t, err)
+	return provider.FetchAuthUserWithNonce(ctx, token, "nonce-1")
+}
+
+func TestOIDCConfig_SignIn(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	provider := issuer.provider()
+
+	authURL, err := url.Parse(provider.BuildAuthURL("state-1"))
+	require.NoError(t, err)
+	assert.Equal(t, issuer.URL+"/authorize", authURL.Scheme+"://"+authURL.Host+authURL.Path)
+	assert.Equal(t, "client-1", authURL.Query().Get("client_id"))
+	assert.Equal(t, "state-1", authURL.Query().Get("state"))
+	assert.Equal(t, "openid email profile", authURL.Query().Get("scope"))
+
+	user, err := issuer.signIn(t, provider)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", user.Id)
+	assert.Equal(t, "oidc@example.com", user.Email)
+	assert.Equal(t, "Oidc User", user.Name)
+	assert.Equal(t, "oidc", user.Username)
+	assert.Equal(t, "https://example.com/avatar.png", user.AvatarURL)
+	assert.Equal(t, "access-1", user.AccessToken)
+
+	// id tokens are only accepted with the nonce of their auth request
+	token, err := provider.FetchToken(context.Background(), "code-1", provider.FetchTokenOptions("verifier-1")...)
+	require.NoError(t, err)
+	_, err = provider.FetchAuthUser(context.Background(), token)
+	assert.Error(t, err)
+}
+
+func TestOIDCConfig_UnverifiedEmail(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	claims := issuer.claims
+	issuer.claims = func(iss string) jwt.MapClaims {
+		c := claims(iss)
+		c["email_verified"] = false
+		return c
+	}
+	_, err := issuer.signIn(t, issuer.provider())
+	assert.ErrorIs(t, err, oauth.ErrOIDCEmailNotVerified)
+
+	issuer.claims = func(iss string) jwt.MapClaims {
+		c := claims(iss)
+		delete(c, "email")
+		return c
+	}
+	_, err = issuer.signIn(t, issuer.provider())
+	assert.ErrorIs(t, err, oauth.ErrOIDCEmailNotVerified)
+}
+
+func TestOIDCConfig_RejectsInvalidIDTokens(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	tests := []struct {
+		name   string
+		modify func(c jwt.MapClaims)
+		signer *rsa.PrivateKey
+	}{
+		{name: "other audience", modify: func(c jwt.MapClaims) { c["aud"] = "client-2" }},
+		{name: "other issuer", modify: func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }},
+		{name: "expired", modify: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{name: "no expiry", modify: func(c jwt.MapClaims) { delete(c, "exp") }},
+		{name: "other authorized party", modify: func(c jwt.MapClaims) { c["azp"] = "client-2" }},
+		{name: "no subject", modify: func(c jwt.MapClaims) { delete(c, "sub") }},
+		{name: "other nonce", modify: func(c jwt.MapClaims) { c["nonce"] = "nonce-2" }},
+		{name: "no nonce", modify: func(c jwt.MapClaims) { delete(c, "nonce") }},
+		{name: "signed by another key", signer: otherKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer := newFakeIssuer(t)
+			claims := issuer.claims
+			issuer.claims = func(iss string) jwt.MapClaims {
+				c := claims(iss)
+				if tt.modify != nil {
+					tt.modify(c)
+				}
+				return c
+			}
+			issuer.signer = tt.signer
+			_, err := issuer.signIn(t, issuer.provider())
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestOIDCConfig_UserInfoErrors(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	issuer.Config.Handler.(*http.ServeMux).HandleFunc("GET /userinfo", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "internal details", http.StatusBadGateway)
+	})
+
+	_, err := issuer.provider().FetchRawUserInfo(context.Background(), &oauth2.Token{AccessToken: "access-1"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "502")
+	assert.NotContains(t, err.Error(), "internal details", "responses of the issuer are kept out of errors")
+}
+
+func TestDiscover(t *testing.T) {
+	issuer := newFakeIssuer(t)
+
+	discovery, err := oauth.Discover(context.Background(), issuer.Client(), issuer.URL)
+	require.NoError(t, err)
+	assert.Equal(t, issuer.URL+"/jwks", discovery.JwksURI)
+
+	// the document must belong to the issuer it was fetched from
+	_, err = oauth.Discover(context.Background(), issuer.Client(), issuer.URL+"/")
+	assert.Error(t, err)
+
+	provider := oauth.NewOIDCProvider(oauth.OIDCOptions{Name: "down", Issuer: issuer.URL + "/missing", ClientID: "client-1"})
+	assert.Empty(t, provider.BuildAuthURL("state-1"))
+}
+
+func TestRegisterOIDCProvider(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	opts := oauth.OIDCOptions{Issuer: issuer.URL, ClientID: "client-1"}
+
+	for _, name := range []string{"github", "credentials", "Okta", ""} {
+		opts.Name = name
+		assert.Error(t, oauth.RegisterOIDCProvider(opts), name)
+	}
+
+	opts.Name = "okta-" + strings.ToLower(t.Name())
+	require.NoError(t, oauth.RegisterOIDCProvider(opts))
+	t.Cleanup(func() { delete(oauth.Providers, opts.Name) })
+	provider := oauth.NewProviderByName(opts.Name)
+	require.NotNil(t, provider)
+	assert.True(t, provider.Active())
+	assert.Same(t, provider, oauth.NewProviderByName(opts.Name))
+}
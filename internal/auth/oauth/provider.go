@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"regexp"
+	"slices"
 
 	"github.com/tkahng/playground/internal/conf"
 	"golang.org/x/oauth2"
@@ -22,6 +25,14 @@ type ProviderConfig interface {
 	FetchTokenOptions(verifier string) []oauth2.AuthCodeOption
 }
 
+// NonceProvider is a provider whose id tokens carry the nonce sent with the
+// auth request. The nonce is kept in the state token, so id tokens issued for
+// another flow are refused.
+type NonceProvider interface {
+	ProviderConfig
+	FetchAuthUserWithNonce(ctx context.Context, token *oauth2.Token, nonce string) (*AuthUser, error)
+}
+
 // func
 
 func OAuth2ConfigFromEnv(cfg conf.EnvConfig) {
@@ -64,11 +75,58 @@ func OAuth2ConfigFromEnv(cfg conf.EnvConfig) {
 				}}
 		})
 	}
+	for _, provider := range cfg.OidcProviders {
+		err := RegisterOIDCProvider(OIDCOptions{
+			Name:         provider.Name,
+			DisplayName:  provider.DisplayName,
+			Issuer:       provider.Issuer,
+			ClientID:     provider.ClientID,
+			ClientSecret: provider.ClientSecret,
+			Scopes:       provider.Scopes,
+			RedirectURL:  cfg.AppUrl + cfg.AuthCallback,
+		})
+		if err != nil {
+			slog.Error("error registering OIDC provider", slog.String("provider", provider.Name), slog.Any("error", err))
+		}
+	}
 }
 
 const NameGithub = "github"
 const NameGoogle = "google"
 
+// ReservedNames are the names of built in providers, OIDC providers can not
+// use them.
+var ReservedNames = []string{NameGithub, NameGoogle, "apple", "facebook", "credentials"}
+
+var oidcNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// ValidateOIDCName checks that name can identify an OIDC provider.
+func ValidateOIDCName(name string) error {
+	if !oidcNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid OIDC provider name %q", name)
+	}
+	if slices.Contains(ReservedNames, name) {
+		return fmt.Errorf("OIDC provider name %q is reserved", name)
+	}
+	return nil
+}
+
+// RegisterOIDCProvider adds an OIDC provider to Providers. The provider is
+// shared so its discovery document and keys are only fetched once.
+func RegisterOIDCProvider(opts OIDCOptions) error {
+	if err := ValidateOIDCName(opts.Name); err != nil {
+		return err
+	}
+	if opts.Issuer == "" || opts.ClientID == "" {
+		return fmt.Errorf("OIDC provider %q needs an issuer and a client id", opts.Name)
+	}
+	provider := NewOIDCProvider(opts)
+	Providers[opts.Name] = wrapFactory(func() *OIDCConfig {
+		return provider
+	})
+	return nil
+}
+
 type OAuth2ProviderConfig struct {
 	ClientID     string
 	ClientSecret string
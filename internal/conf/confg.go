@@ -1,6 +1,9 @@
 package conf
 
 import (
+	"encoding/json"
+	"time"
+
 	"github.com/caarlos0/env/v11"
 	_ "github.com/joho/godotenv/autoload"
 )
@@ -8,6 +11,14 @@ import (
 type JobsConfig struct {
 	PollerInterval int64 `env:"POLLER_INTERVAL" envDefault:"1"` // Default
 	JobTimeout     int64 `env:"JOB_TIMEOUT" envDefault:"30"`
+	// JobsBackend selects where jobs are queued, "db" or "memory". Only the
+	// job queue moves to memory, the stores, the sse manager and its
+	// listener still need Postgres.
+	JobsBackend string `env:"JOBS_BACKEND" envDefault:"db"`
+	// NotificationDigestHour is the UTC hour the daily notification digest is sent at.
+	NotificationDigestHour int `env:"NOTIFICATION_DIGEST_HOUR" envDefault:"8"`
+	// NotificationRetentionDays is how long read notifications are kept, zero keeps them forever.
+	NotificationRetentionDays int `env:"NOTIFICATION_RETENTION_DAYS" envDefault:"30"`
 }
 
 // Duration: 3600, // 1hr
@@ -25,6 +36,10 @@ type AppConfig struct {
 	SenderAddress string `env:"SENDER_ADDRESS" envDefault:"Hb4k@notifications.k2dv.io"`
 	EncryptionKey string `env:"ENCRYPTION_KEY" envDefault:"12345678901234567890123456789012"` //
 	AppEnv        string `env:"APP_ENV" envDefault:"dev"`
+	// TrustedProxies are the ip addresses or CIDR ranges of the proxies in
+	// front of the server, the client ip is only read from their
+	// X-Forwarded-For headers.
+	TrustedProxies []string `env:"TRUSTED_PROXIES" envDefault:""`
 }
 
 type DBConfig struct {
@@ -52,9 +67,34 @@ type GoogleConfig struct {
 	GoogleClientId     string `env:"GOOGLE_CLIENT_ID" required:"false"`
 	GoogleClientSecret string `env:"GOOGLE_CLIENT_SECRET" required:"false"`
 }
+
+// OIDCProviderConfig is an OpenID Connect provider like Keycloak, Okta or
+// Azure AD.
+type OIDCProviderConfig struct {
+	// Name identifies the provider, like the github or google providers.
+	Name         string   `json:"name"`
+	DisplayName  string   `json:"display_name"`
+	Issuer       string   `json:"issuer"`
+	ClientID     string   `json:"client_id"`
+	ClientSecret string   `json:"client_secret"`
+	Scopes       []string `json:"scopes"`
+}
+
+// OIDCProviders is set from a json array of providers.
+type OIDCProviders []OIDCProviderConfig
+
+func (p *OIDCProviders) UnmarshalText(text []byte) error {
+	return json.Unmarshal(text, (*[]OIDCProviderConfig)(p))
+}
+
+type OIDCConfig struct {
+	OidcProviders OIDCProviders `env:"OIDC_PROVIDERS" required:"false"`
+}
+
 type OAuth2Config struct {
 	GithubConfig
 	GoogleConfig
+	OIDCConfig
 	AuthCallback string `env:"AUTH_CALLBACK" envDefault:"/api/auth/callback"`
 }
 
@@ -65,6 +105,37 @@ type StripeConfig struct {
 	StripeAppUrl string `env:"APP_URL" envDefault:"http://localhost:5173"`
 }
 
+type GamesConfig struct {
+	// SticksMaxGames is how many sticks games may run at once.
+	SticksMaxGames int `env:"STICKS_MAX_GAMES" envDefault:"100"`
+	// SticksBotWait is how long a player waits for a human opponent before
+	// playing a bot, zero never matches bots.
+	SticksBotWait time.Duration `env:"STICKS_BOT_WAIT" envDefault:"10s"`
+}
+
+// LoginThrottleConfig slows down guessing of passwords and tokens. A zero
+// window turns throttling off.
+type LoginThrottleConfig struct {
+	// LoginFailureWindow is how long failures are remembered.
+	LoginFailureWindow time.Duration `env:"LOGIN_FAILURE_WINDOW" envDefault:"15m"`
+	// LoginFreeAttempts is how many failures an ip address has before it
+	// has to wait between attempts.
+	LoginFreeAttempts int `env:"LOGIN_FREE_ATTEMPTS" envDefault:"3"`
+	// LoginDelay is the first wait, it doubles with every further failure
+	// up to LoginMaxDelay.
+	LoginDelay    time.Duration `env:"LOGIN_DELAY" envDefault:"1s"`
+	LoginMaxDelay time.Duration `env:"LOGIN_MAX_DELAY" envDefault:"1m"`
+	// LoginMaxFailures is how many failed sign ins from any ip address lock
+	// an account for LoginLockoutDuration, zero never locks accounts.
+	LoginMaxFailures     int           `env:"LOGIN_MAX_FAILURES" envDefault:"10"`
+	LoginLockoutDuration time.Duration `env:"LOGIN_LOCKOUT_DURATION" envDefault:"15m"`
+	// LoginMaxRequests is how many password reset and magic link emails an
+	// ip address may request for an email within LoginFailureWindow. Further
+	// requests are refused, the account and other ip addresses are not
+	// affected.
+	LoginMaxRequests int `env:"LOGIN_MAX_REQUESTS" envDefault:"5"`
+}
+
 type AiConfig struct {
 	GoogleGeminiApiKey string `env:"GOOGLE_GEMINI_API_KEY" required:"true"`
 }
@@ -94,6 +165,8 @@ type EnvConfig struct {
 	StorageConfig
 	AiConfig
 	SmtpConfig
+	GamesConfig
+	LoginThrottleConfig
 	AuthOptions
 }
 
@@ -32,6 +32,11 @@ type AuthOptions struct {
 	RefreshToken       TokenOption `form:"refresh_token" json:"refresh_token"`
 	StateToken         TokenOption `form:"state_token" json:"state_token"`
 	InviteToken        TokenOption `form:"invite_token" json:"invite_token"`
+	UnsubscribeToken   TokenOption `form:"unsubscribe_token" json:"unsubscribe_token"`
+	MagicLinkToken     TokenOption `form:"magic_link_token" json:"magic_link_token"`
+	// ImpersonationToken sets how long impersonation access tokens last, they
+	// are signed like access tokens.
+	ImpersonationToken TokenOption `form:"impersonation_token" json:"impersonation_token"`
 }
 
 func NewTokenOptions() AuthOptions {
@@ -66,5 +71,20 @@ func NewTokenOptions() AuthOptions {
 			Secret:   string(models.TokenTypesInviteToken),
 			Duration: 604800, // 7days
 		},
+		UnsubscribeToken: TokenOption{
+			Type:     models.TokenTypesUnsubscribeToken,
+			Secret:   string(models.TokenTypesUnsubscribeToken),
+			Duration: 7776000, // 90days
+		},
+		MagicLinkToken: TokenOption{
+			Type:     models.TokenTypesMagicLinkToken,
+			Secret:   string(models.TokenTypesMagicLinkToken),
+			Duration: 900, // 15min
+		},
+		ImpersonationToken: TokenOption{
+			Type:     models.TokenTypesAccessToken,
+			Secret:   string(models.TokenTypesAccessToken),
+			Duration: 900, // 15min
+		},
 	}
 }
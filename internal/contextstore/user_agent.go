@@ -0,0 +1,16 @@
+package contextstore
+
+import "context"
+
+const (
+	contextKeyUserAgent contextKey = "user_agent"
+)
+
+func SetContextUserAgent(ctx context.Context, userAgent string) context.Context {
+	return context.WithValue(ctx, contextKeyUserAgent, userAgent)
+}
+
+func GetContextUserAgent(ctx context.Context) string {
+	userAgent, _ := ctx.Value(contextKeyUserAgent).(string)
+	return userAgent
+}
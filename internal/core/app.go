@@ -11,7 +11,9 @@ import (
 	"github.com/tkahng/playground/internal/services"
 	"github.com/tkahng/playground/internal/stores"
 	"github.com/tkahng/playground/internal/tools/filesystem"
+	"github.com/tkahng/playground/internal/tools/notifier"
 	"github.com/tkahng/playground/internal/tools/sse"
+	"github.com/tkahng/playground/internal/tools/websocket"
 )
 
 type App interface {
@@ -34,6 +36,8 @@ type App interface {
 	JobManager() jobs.JobManager
 
 	JobService() services.JobService
+	// JobStore reads the jobs and their stats from the configured backend.
+	JobStore() services.JobAdminStore
 	// fs -------------------------------------------------------------------------------------
 
 	Fs() filesystem.FileSystem
@@ -50,14 +54,28 @@ type App interface {
 
 	TeamInvitation() services.TeamInvitationService
 
+	TeamSso() services.TeamSsoService
+
+	TeamRoles() services.TeamRoleService
+
 	Checker() services.ConstraintChecker
 
 	Task() services.TaskService
 
 	NotificationPublisher() services.Notifier
 
+	NotificationMail() services.NotificationMailService
+
+	Notification() services.NotificationService
+
+	Sticks() services.SticksService
+
 	SseManager() sse.Manager
 
+	WebsocketManager() websocket.Manager
+
+	Notifier() notifier.Notifier
+
 	EventManager() events.EventManager
 
 	RunBackgroundProcesses(ctx context.Context)
@@ -13,7 +13,9 @@ import (
 
 	"github.com/tkahng/playground/internal/tools/filesystem"
 	"github.com/tkahng/playground/internal/tools/logger"
+	"github.com/tkahng/playground/internal/tools/notifier"
 	"github.com/tkahng/playground/internal/tools/sse"
+	"github.com/tkahng/playground/internal/tools/websocket"
 )
 
 var _ App = (*BaseApp)(nil)
@@ -31,6 +33,7 @@ type BaseApp struct {
 
 	jobManager jobs.JobManager
 	jobService services.JobService
+	jobStore   services.JobAdminStore
 
 	payment services.PaymentService
 
@@ -42,13 +45,24 @@ type BaseApp struct {
 
 	team           services.TeamService
 	teamInvitation services.TeamInvitationService
+	teamSso        services.TeamSsoService
+	teamRoles      services.TeamRoleService
 
 	notifierPublisher services.Notifier
+	notificationMail  services.NotificationMailService
+	notification      services.NotificationService
+
+	sticks services.SticksService
 
 	fs filesystem.FileSystem
 
 	sseManager sse.Manager
 
+	wsManager websocket.Manager
+
+	listener notifier.Listener
+	notifier notifier.Notifier
+
 	eventManager events.EventManager
 }
 
@@ -76,6 +90,54 @@ func (app *BaseApp) NotificationPublisher() services.Notifier {
 	return app.notifierPublisher
 }
 
+// NotificationMail implements App.
+func (app *BaseApp) NotificationMail() services.NotificationMailService {
+	if app.notificationMail == nil {
+		panic("notification mail service not initialized")
+	}
+	return app.notificationMail
+}
+
+// Notification implements App.
+func (app *BaseApp) Notification() services.NotificationService {
+	if app.notification == nil {
+		panic("notification service not initialized")
+	}
+	return app.notification
+}
+
+// Sticks implements App.
+func (app *BaseApp) Sticks() services.SticksService {
+	if app.sticks == nil {
+		panic("sticks service not initialized")
+	}
+	return app.sticks
+}
+
+// TeamSso implements App.
+func (app *BaseApp) TeamSso() services.TeamSsoService {
+	if app.teamSso == nil {
+		panic("team sso service not initialized")
+	}
+	return app.teamSso
+}
+
+// TeamRoles implements App.
+func (app *BaseApp) TeamRoles() services.TeamRoleService {
+	if app.teamRoles == nil {
+		panic("team role service not initialized")
+	}
+	return app.teamRoles
+}
+
+// WebsocketManager implements App.
+func (app *BaseApp) WebsocketManager() websocket.Manager {
+	if app.wsManager == nil {
+		panic("websocket manager not initialized")
+	}
+	return app.wsManager
+}
+
 // SseManager implements App.
 func (app *BaseApp) SseManager() sse.Manager {
 	if app.sseManager == nil {
@@ -84,6 +146,14 @@ func (app *BaseApp) SseManager() sse.Manager {
 	return app.sseManager
 }
 
+// Notifier implements App.
+func (app *BaseApp) Notifier() notifier.Notifier {
+	if app.notifier == nil {
+		panic("notifier not initialized")
+	}
+	return app.notifier
+}
+
 // check settings -------------------------------------------------------------------------------------
 func (app *BaseApp) Config() *conf.EnvConfig {
 	if app.cfg == nil {
@@ -143,6 +213,14 @@ func (app *BaseApp) JobManager() jobs.JobManager {
 	return app.jobManager
 }
 
+// JobStore implements App.
+func (app *BaseApp) JobStore() services.JobAdminStore {
+	if app.jobStore == nil {
+		panic("job store not initialized")
+	}
+	return app.jobStore
+}
+
 // JobService implements App.
 func (app *BaseApp) JobService() services.JobService {
 	if app.jobService == nil {
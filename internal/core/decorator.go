@@ -13,7 +13,9 @@ import (
 	"github.com/tkahng/playground/internal/tools/filesystem"
 	"github.com/tkahng/playground/internal/tools/logger"
 	"github.com/tkahng/playground/internal/tools/mailer"
+	"github.com/tkahng/playground/internal/tools/notifier"
 	"github.com/tkahng/playground/internal/tools/sse"
+	"github.com/tkahng/playground/internal/tools/websocket"
 )
 
 var _ App = (*BaseAppDecorator)(nil)
@@ -48,11 +50,19 @@ type BaseAppDecorator struct {
 	TeamInvitationFunc         func() services.TeamInvitationService
 	JobManagerFunc             func() jobs.JobManager
 	JobServiceFunc             func() services.JobService
+	JobStoreFunc               func() services.JobAdminStore
 	LifecycleFunc              func() Lifecycle
 	LoggerFunc                 func() *slog.Logger
 	BootstrapFunc              func() error
 	SseManagerFunc             func() sse.Manager
+	WebsocketManagerFunc       func() websocket.Manager
+	NotifierFunc               func() notifier.Notifier
 	NotificationPublisherFunc  func() services.Notifier
+	NotificationMailFunc       func() services.NotificationMailService
+	NotificationFunc           func() services.NotificationService
+	SticksFunc                 func() services.SticksService
+	TeamSsoFunc                func() services.TeamSsoService
+	TeamRolesFunc              func() services.TeamRoleService
 	EventManagerFunc           func() events.EventManager
 	InitializePrimitivesFunc   func()
 	RegisterWorkersFunc        func()
@@ -152,6 +162,22 @@ func (b *BaseAppDecorator) NotificationPublisher() services.Notifier {
 	return b.app.NotificationPublisher()
 }
 
+// NotificationMail implements App.
+func (b *BaseAppDecorator) NotificationMail() services.NotificationMailService {
+	if b.NotificationMailFunc != nil {
+		return b.NotificationMailFunc()
+	}
+	return b.app.NotificationMail()
+}
+
+// Notification implements App.
+func (b *BaseAppDecorator) Notification() services.NotificationService {
+	if b.NotificationFunc != nil {
+		return b.NotificationFunc()
+	}
+	return b.app.Notification()
+}
+
 // SseManager implements App.
 func (b *BaseAppDecorator) SseManager() sse.Manager {
 	if b.SseManagerFunc != nil {
@@ -160,6 +186,46 @@ func (b *BaseAppDecorator) SseManager() sse.Manager {
 	return b.app.SseManager()
 }
 
+// TeamSso implements App.
+func (b *BaseAppDecorator) TeamSso() services.TeamSsoService {
+	if b.TeamSsoFunc != nil {
+		return b.TeamSsoFunc()
+	}
+	return b.app.TeamSso()
+}
+
+// TeamRoles implements App.
+func (b *BaseAppDecorator) TeamRoles() services.TeamRoleService {
+	if b.TeamRolesFunc != nil {
+		return b.TeamRolesFunc()
+	}
+	return b.app.TeamRoles()
+}
+
+// Sticks implements App.
+func (b *BaseAppDecorator) Sticks() services.SticksService {
+	if b.SticksFunc != nil {
+		return b.SticksFunc()
+	}
+	return b.app.Sticks()
+}
+
+// WebsocketManager implements App.
+func (b *BaseAppDecorator) WebsocketManager() websocket.Manager {
+	if b.WebsocketManagerFunc != nil {
+		return b.WebsocketManagerFunc()
+	}
+	return b.app.WebsocketManager()
+}
+
+// Notifier implements App.
+func (b *BaseAppDecorator) Notifier() notifier.Notifier {
+	if b.NotifierFunc != nil {
+		return b.NotifierFunc()
+	}
+	return b.app.Notifier()
+}
+
 // Logger implements App.
 func (b *BaseAppDecorator) Logger() *slog.Logger {
 	if b.LoggerFunc != nil {
@@ -195,6 +261,14 @@ func (b *BaseAppDecorator) JobService() services.JobService {
 	return b.app.JobService()
 }
 
+// JobStore implements App.
+func (b *BaseAppDecorator) JobStore() services.JobAdminStore {
+	if b.JobStoreFunc != nil {
+		return b.JobStoreFunc()
+	}
+	return b.app.JobStore()
+}
+
 // JobManager implements App.
 func (b *BaseAppDecorator) JobManager() jobs.JobManager {
 	if b.JobManagerFunc != nil {
@@ -4,21 +4,48 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/ThreeDotsLabs/watermill/components/cqrs"
+	"github.com/tkahng/playground/internal/auth/oauth"
 	"github.com/tkahng/playground/internal/conf"
 	"github.com/tkahng/playground/internal/database"
 	"github.com/tkahng/playground/internal/events"
+	"github.com/tkahng/playground/internal/games/sticks"
 	"github.com/tkahng/playground/internal/jobs"
 	"github.com/tkahng/playground/internal/services"
 	"github.com/tkahng/playground/internal/stores"
 	"github.com/tkahng/playground/internal/tools/di"
 	"github.com/tkahng/playground/internal/tools/logger"
+	"github.com/tkahng/playground/internal/tools/notifier"
 	"github.com/tkahng/playground/internal/tools/sse"
+	"github.com/tkahng/playground/internal/tools/websocket"
 	"github.com/tkahng/playground/internal/userreaction"
 )
 
 func (app *BaseApp) RunBackgroundProcesses(firstCtx context.Context) {
+	go func() {
+		app.Logger().Info("Starting notifier")
+		if err := app.listener.Connect(firstCtx); err != nil {
+			app.Logger().ErrorContext(
+				firstCtx,
+				"error connecting notifier listener",
+				slog.Any("error", err),
+			)
+			return
+		}
+		// nolint:errcheck
+		defer app.listener.Close(context.Background())
+		if err := app.Notifier().Run(firstCtx); err != nil {
+			app.Logger().ErrorContext(
+				firstCtx,
+				"error running notifier",
+				slog.Any("error", err),
+			)
+			return
+		}
+	}()
+
 	go func() {
 		app.Logger().Info("Starting poller")
 		if err := app.JobManager().Run(firstCtx); err != nil {
@@ -35,6 +62,43 @@ func (app *BaseApp) RunBackgroundProcesses(firstCtx context.Context) {
 		app.Logger().Info("Starting sse manager")
 		app.SseManager().Run(firstCtx)
 	}()
+	go func() {
+		app.Logger().Info("Starting websocket manager")
+		app.WebsocketManager().Run(firstCtx)
+	}()
+	go func() {
+		app.Logger().Info("Starting sticks matchmaking")
+		app.Sticks().Run(firstCtx)
+	}()
+	go func() {
+		app.Logger().Info("Starting job stats publisher")
+		services.NewJobStatsPublisher(
+			app.Logger(),
+			app.JobStore(),
+			app.SseManager(),
+		).Run(firstCtx, 5*time.Second)
+	}()
+	go func() {
+		// the digest job schedules the next day itself, this only makes sure
+		// there is one after a fresh start
+		if err := app.NotificationMail().ScheduleDigest(firstCtx, time.Now()); err != nil {
+			app.Logger().ErrorContext(
+				firstCtx,
+				"error scheduling notification digest",
+				slog.Any("error", err),
+			)
+		}
+	}()
+	go func() {
+		// like the digest, the retention job schedules the next day itself
+		if err := app.Notification().ScheduleRetention(firstCtx, time.Now()); err != nil {
+			app.Logger().ErrorContext(
+				firstCtx,
+				"error scheduling notification retention",
+				slog.Any("error", err),
+			)
+		}
+	}()
 	go func() {
 		app.Logger().Info("Starting event manager")
 		if err := app.EventManager().Run(firstCtx); err != nil {
@@ -89,6 +153,11 @@ func (app *BaseApp) SetDb() {
 	queries := database.CreateQueries(app.cfg.Db.DatabaseUrl)
 
 	if err := queries.Pool().Ping(context.Background()); err != nil {
+		if app.cfg.JobsBackend == "memory" {
+			// only the job queue is kept in memory, serving without a
+			// database is not supported
+			panic(fmt.Errorf("failed to ping db, JOBS_BACKEND=memory still needs Postgres for everything but jobs: %w", err))
+		}
 		panic(fmt.Errorf("failed to ping db: %w", err))
 	}
 
@@ -109,21 +178,63 @@ func (app *BaseApp) SetBasicServices() {
 	app.checker = services.NewConstraintCheckerService(adapter)
 
 	app.eventManager = events.NewEventManager(logger)
-	app.sseManager = sse.NewManager(logger)
 
 	app.mailService = services.NewOtpMailService(
 		cfg,
 		adapter,
 	)
 
-	app.jobManager = jobs.NewDbJobManager(dbx)
+	app.listener = notifier.NewListener(dbx)
+	app.notifier = notifier.NewNotifier(logger, app.listener)
+	app.sseManager = sse.NewPgManager(logger, dbx, app.notifier)
+	app.wsManager = websocket.NewManager()
+
+	jobOpts := []jobs.PollerOptsFunc{
+		jobs.WithIntervalS(cfg.PollerInterval),
+		jobs.WithTimeout(cfg.JobTimeout),
+	}
+	// the memory backend only keeps the job queue out of Postgres, the
+	// adapter and the sse manager above still need the database
+	switch cfg.JobsBackend {
+	case "memory":
+		manager := jobs.NewMemoryJobManager(jobOpts...)
+		app.jobManager = manager
+		app.jobStore = manager.Store
+	default:
+		app.jobManager = jobs.NewDbJobManager(dbx, append(jobOpts, jobs.WithNotifier(app.notifier))...)
+		app.jobStore = adapter.Job()
+	}
 	app.jobService = services.NewJobService(app.jobManager)
 	app.notifierPublisher = services.NewDbNotificationPublisher(
 		app.sseManager,
 		app.team,
 		adapter,
+		app.jobService,
+	)
+	app.notificationMail = services.NewNotificationMailService(
+		cfg,
+		adapter,
+		app.jobService,
+	)
+	app.notification = services.NewNotificationService(
+		cfg,
+		adapter,
+		app.sseManager,
+		app.jobService,
+	)
+	app.teamSso = services.NewTeamSsoService(adapter)
+	app.teamRoles = services.NewTeamRoleService(adapter)
+	app.sticks = services.NewSticksService(
+		adapter,
+		app.sseManager,
+		sticks.NewGameBroker(cfg.SticksMaxGames),
+		cfg.SticksBotWait,
+	)
+	app.task = services.NewTaskService(
+		adapter,
+		app.jobService,
+		services.WithTaskBoard(services.NewTaskBoardPublisher(app.sseManager)),
 	)
-	app.task = services.NewTaskService(adapter, app.jobService)
 }
 func (app *BaseApp) SetIntegrationServices() {
 	adapter := app.Adapter()
@@ -137,6 +248,7 @@ func (app *BaseApp) SetIntegrationServices() {
 	client := services.NewPaymentClient(cfg.StripeConfig)
 	app.payment = services.NewPaymentService(client, adapter)
 	app.teamInvitation = services.NewInvitationService(adapter, *cfg, jobService)
+	oauth.OAuth2ConfigFromEnv(*cfg)
 	app.auth = services.NewAuthService(
 		cfg,
 		jobService,
@@ -145,5 +257,5 @@ func (app *BaseApp) SetIntegrationServices() {
 }
 
 func (app *BaseApp) RegisterWorkers() {
-	app.JobService().RegisterWorkers(app.mailService, app.Payment(), app.NotificationPublisher())
+	app.JobService().RegisterWorkers(app.mailService, app.Payment(), app.NotificationPublisher(), app.NotificationMail(), app.Notification())
 }
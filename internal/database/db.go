@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"log/slog"
+	"sync"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -80,6 +81,48 @@ var _ Dbx = (*txQueries)(nil)
 
 type txQueries struct {
 	db pgx.Tx
+	// parent is the transaction a savepoint was started in, its hooks are
+	// handed to the parent on commit.
+	parent *txQueries
+
+	mu          sync.Mutex
+	afterCommit []func()
+}
+
+func newTxQueries(dbx Dbx, tx pgx.Tx) *txQueries {
+	parent, _ := dbx.(*txQueries)
+	return &txQueries{db: tx, parent: parent}
+}
+
+// AfterCommit runs fn once the transaction of db has committed, fn is dropped
+// when it rolls back. Outside of a transaction fn runs at once.
+func AfterCommit(db Dbx, fn func()) {
+	tx, ok := db.(*txQueries)
+	if !ok {
+		fn()
+		return
+	}
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.afterCommit = append(tx.afterCommit, fn)
+}
+
+// committed runs the hooks of the transaction, or of a savepoint hands them
+// to its transaction.
+func (v *txQueries) committed() {
+	v.mu.Lock()
+	hooks := v.afterCommit
+	v.afterCommit = nil
+	v.mu.Unlock()
+	if v.parent != nil {
+		for _, fn := range hooks {
+			AfterCommit(v.parent, fn)
+		}
+		return
+	}
+	for _, fn := range hooks {
+		fn()
+	}
 }
 
 // Acquire implements Dbx.
@@ -108,7 +151,11 @@ func (v *txQueries) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResult
 }
 
 func (v *txQueries) Commit(ctx context.Context) error {
-	return v.db.Commit(ctx)
+	if err := v.db.Commit(ctx); err != nil {
+		return err
+	}
+	v.committed()
+	return nil
 }
 
 func NewTxQueries(tx pgx.Tx) *txQueries {
@@ -144,9 +191,10 @@ func WithTx(dbx Dbx, fn func(tx Dbx) error) error {
 		}
 	}()
 
-	err = fn(&txQueries{db: tx})
+	txq := newTxQueries(dbx, tx)
+	err = fn(txq)
 	if err == nil {
-		if err := tx.Commit(ctx); err != nil {
+		if err := txq.Commit(ctx); err != nil {
 			slog.ErrorContext(ctx, "error committing transaction", slog.Any("error", err))
 			return err
 		}
@@ -176,10 +224,11 @@ func WithTxContext(dbx Dbx, ctx context.Context, fn func(context.Context) error)
 			}
 		}
 	}()
-	txCtx := setContextTx(ctx, &txQueries{db: tx})
+	txq := newTxQueries(dbx, tx)
+	txCtx := setContextTx(ctx, txq)
 	err = fn(txCtx)
 	if err == nil {
-		if err := tx.Commit(ctx); err != nil {
+		if err := txq.Commit(ctx); err != nil {
 			slog.ErrorContext(ctx, "error committing transaction", slog.Any("error", err))
 			return err
 		}
@@ -0,0 +1,10 @@
+-- migrate:up transaction:false
+ALTER TYPE public.job_status ADD VALUE IF NOT EXISTS 'cancelled';
+ALTER TABLE public.jobs
+    ADD COLUMN IF NOT EXISTS result JSONB,
+    ADD COLUMN IF NOT EXISTS progress INT NOT NULL DEFAULT 0;
+-- migrate:down
+-- enum values cannot be dropped, 'cancelled' stays on public.job_status.
+ALTER TABLE public.jobs
+    DROP COLUMN IF EXISTS progress,
+    DROP COLUMN IF EXISTS result;
@@ -0,0 +1,11 @@
+-- migrate:up
+ALTER TABLE public.jobs
+    ADD COLUMN IF NOT EXISTS started_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS finished_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS jobs_finished_at_idx ON public.jobs (finished_at)
+WHERE finished_at IS NOT NULL;
+-- migrate:down
+DROP INDEX IF EXISTS jobs_finished_at_idx;
+ALTER TABLE public.jobs
+    DROP COLUMN IF EXISTS finished_at,
+    DROP COLUMN IF EXISTS started_at;
@@ -0,0 +1,15 @@
+-- migrate:up
+ALTER TABLE public.jobs
+    ADD COLUMN IF NOT EXISTS group_id UUID,
+    ADD COLUMN IF NOT EXISTS parent_ids UUID [],
+    ADD COLUMN IF NOT EXISTS run_on_parent_failure BOOLEAN NOT NULL DEFAULT false;
+CREATE INDEX IF NOT EXISTS jobs_group_id_idx ON public.jobs (group_id)
+WHERE group_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS jobs_parent_ids_idx ON public.jobs USING gin (parent_ids);
+-- migrate:down
+DROP INDEX IF EXISTS jobs_parent_ids_idx;
+DROP INDEX IF EXISTS jobs_group_id_idx;
+ALTER TABLE public.jobs
+    DROP COLUMN IF EXISTS run_on_parent_failure,
+    DROP COLUMN IF EXISTS parent_ids,
+    DROP COLUMN IF EXISTS group_id;
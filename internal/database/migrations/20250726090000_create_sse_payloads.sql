@@ -0,0 +1,9 @@
+-- migrate:up
+CREATE TABLE IF NOT EXISTS public.sse_payloads (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp()
+);
+CREATE INDEX IF NOT EXISTS sse_payloads_created_at_idx ON public.sse_payloads (created_at);
+-- migrate:down
+DROP TABLE IF EXISTS public.sse_payloads;
@@ -0,0 +1,35 @@
+-- migrate:up
+CREATE SEQUENCE IF NOT EXISTS public.notifications_seq_seq;
+ALTER TABLE public.notifications
+ADD COLUMN IF NOT EXISTS seq BIGINT;
+UPDATE public.notifications
+SET seq = ordered.seq
+FROM (
+        SELECT id,
+            nextval('public.notifications_seq_seq') AS seq
+        FROM (
+                SELECT id
+                FROM public.notifications
+                ORDER BY created_at,
+                    id
+            ) AS n
+    ) AS ordered
+WHERE notifications.id = ordered.id;
+ALTER TABLE public.notifications
+ALTER COLUMN seq
+SET NOT NULL;
+-- the repository inserts every column, so seq is always taken from the
+-- sequence instead of a column default
+CREATE OR REPLACE FUNCTION public.set_notification_seq() RETURNS TRIGGER AS $BODY$ BEGIN NEW.seq := nextval('public.notifications_seq_seq');
+RETURN NEW;
+END;
+$BODY$ LANGUAGE plpgsql;
+CREATE OR REPLACE TRIGGER trigger_set_notification_seq BEFORE
+INSERT ON public.notifications FOR EACH ROW EXECUTE PROCEDURE public.set_notification_seq();
+CREATE UNIQUE INDEX IF NOT EXISTS notifications_seq_idx ON public.notifications (seq);
+CREATE INDEX IF NOT EXISTS notifications_team_member_id_seq_idx ON public.notifications (team_member_id, seq);
+-- migrate:down
+DROP TRIGGER IF EXISTS trigger_set_notification_seq ON public.notifications;
+DROP FUNCTION IF EXISTS public.set_notification_seq;
+ALTER TABLE public.notifications DROP COLUMN IF EXISTS seq;
+DROP SEQUENCE IF EXISTS public.notifications_seq_seq;
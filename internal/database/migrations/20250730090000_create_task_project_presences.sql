@@ -0,0 +1,13 @@
+-- migrate:up
+CREATE TABLE IF NOT EXISTS public.task_project_presences (
+    project_id UUID NOT NULL REFERENCES public.task_projects (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    team_member_id UUID NOT NULL REFERENCES public.team_members (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    task_id UUID REFERENCES public.tasks (id) ON UPDATE CASCADE ON DELETE SET NULL,
+    state TEXT NOT NULL CHECK (state IN ('viewing', 'editing')),
+    expires_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (project_id, team_member_id)
+);
+CREATE INDEX IF NOT EXISTS task_project_presences_expires_at_idx ON public.task_project_presences (expires_at);
+-- migrate:down
+DROP TABLE IF EXISTS public.task_project_presences;
@@ -0,0 +1,18 @@
+-- migrate:up
+CREATE TABLE IF NOT EXISTS public.notification_preferences (
+    id UUID NOT NULL PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES public.users (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    team_id UUID REFERENCES public.teams (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    delivery TEXT NOT NULL DEFAULT 'in_app' CHECK (delivery IN ('in_app', 'email', 'both', 'none')),
+    daily_digest BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- a preference without a team applies to every team of the user
+    CONSTRAINT notification_preferences_user_id_team_id_type_key UNIQUE NULLS NOT DISTINCT (user_id, team_id, type)
+);
+CREATE TRIGGER handle_notification_preferences_updated_at BEFORE
+UPDATE ON public.notification_preferences FOR EACH ROW EXECUTE PROCEDURE set_current_timestamp_updated_at();
+-- migrate:down
+DROP TRIGGER IF EXISTS handle_notification_preferences_updated_at ON public.notification_preferences;
+DROP TABLE IF EXISTS public.notification_preferences;
@@ -0,0 +1,10 @@
+-- migrate:up
+-- unread counts and mark-all-read only touch the unread notifications of a member
+CREATE INDEX IF NOT EXISTS notifications_team_member_id_unread_idx ON public.notifications (team_member_id, type)
+WHERE read_at IS NULL;
+-- retention prunes read notifications by age
+CREATE INDEX IF NOT EXISTS notifications_created_at_read_idx ON public.notifications (created_at)
+WHERE read_at IS NOT NULL;
+-- migrate:down
+DROP INDEX IF EXISTS notifications_created_at_read_idx;
+DROP INDEX IF EXISTS notifications_team_member_id_unread_idx;
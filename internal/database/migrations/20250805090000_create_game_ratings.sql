@@ -0,0 +1,36 @@
+-- migrate:up
+CREATE TABLE IF NOT EXISTS public.game_ratings (
+    user_id UUID NOT NULL REFERENCES public.users (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    game TEXT NOT NULL,
+    rating INTEGER NOT NULL DEFAULT 1200,
+    wins INTEGER NOT NULL DEFAULT 0,
+    losses INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, game)
+);
+CREATE INDEX IF NOT EXISTS game_ratings_game_rating_idx ON public.game_ratings (game, rating DESC);
+CREATE TRIGGER handle_game_ratings_updated_at BEFORE
+UPDATE ON public.game_ratings FOR EACH ROW EXECUTE PROCEDURE set_current_timestamp_updated_at();
+
+CREATE TABLE IF NOT EXISTS public.game_results (
+    id UUID NOT NULL PRIMARY KEY DEFAULT gen_random_uuid(),
+    game TEXT NOT NULL,
+    game_id TEXT NOT NULL,
+    winner_id UUID NOT NULL REFERENCES public.users (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    loser_id UUID NOT NULL REFERENCES public.users (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    -- the ratings after the game
+    winner_rating INTEGER NOT NULL,
+    loser_rating INTEGER NOT NULL,
+    rating_change INTEGER NOT NULL,
+    moves INTEGER NOT NULL DEFAULT 0,
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT game_results_game_game_id_key UNIQUE (game, game_id)
+);
+CREATE INDEX IF NOT EXISTS game_results_winner_id_idx ON public.game_results (winner_id);
+CREATE INDEX IF NOT EXISTS game_results_loser_id_idx ON public.game_results (loser_id);
+-- migrate:down
+DROP TABLE IF EXISTS public.game_results;
+DROP TRIGGER IF EXISTS handle_game_ratings_updated_at ON public.game_ratings;
+DROP TABLE IF EXISTS public.game_ratings;
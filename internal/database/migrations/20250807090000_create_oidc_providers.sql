@@ -0,0 +1,34 @@
+-- migrate:up
+-- OIDC providers are named by admins, so accounts keep the name as text
+ALTER TABLE public.user_accounts ALTER COLUMN provider TYPE TEXT USING provider::TEXT;
+DROP TYPE IF EXISTS public.providers;
+
+CREATE TABLE IF NOT EXISTS public.oidc_providers (
+    id UUID NOT NULL PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    display_name TEXT NOT NULL,
+    issuer TEXT NOT NULL,
+    client_id TEXT NOT NULL,
+    client_secret TEXT NOT NULL,
+    scopes TEXT [] NOT NULL DEFAULT '{openid,email,profile}',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT oidc_providers_name_key UNIQUE (name)
+);
+CREATE TRIGGER handle_oidc_providers_updated_at BEFORE
+UPDATE ON public.oidc_providers FOR EACH ROW EXECUTE PROCEDURE set_current_timestamp_updated_at();
+-- migrate:down
+DROP TRIGGER IF EXISTS handle_oidc_providers_updated_at ON public.oidc_providers;
+DROP TABLE IF EXISTS public.oidc_providers;
+
+CREATE TYPE public.providers AS ENUM (
+    'google',
+    'apple',
+    'facebook',
+    'github',
+    'credentials'
+);
+DELETE FROM public.user_accounts
+WHERE provider NOT IN ('google', 'apple', 'facebook', 'github', 'credentials');
+ALTER TABLE public.user_accounts ALTER COLUMN provider TYPE public.providers USING provider::public.providers;
@@ -0,0 +1,33 @@
+-- migrate:up
+CREATE TABLE IF NOT EXISTS public.team_sso_configs (
+    team_id UUID NOT NULL PRIMARY KEY REFERENCES public.teams (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    issuer TEXT NOT NULL,
+    client_id TEXT NOT NULL,
+    client_secret TEXT NOT NULL,
+    scopes TEXT [] NOT NULL DEFAULT '{openid,email,profile}',
+    -- the role of members provisioned on their first sign in
+    default_role public.team_member_role NOT NULL DEFAULT 'member',
+    -- enforced configs only allow sign ins through the IdP for verified domains
+    enforced BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE TRIGGER handle_team_sso_configs_updated_at BEFORE
+UPDATE ON public.team_sso_configs FOR EACH ROW EXECUTE PROCEDURE set_current_timestamp_updated_at();
+
+CREATE TABLE IF NOT EXISTS public.team_sso_domains (
+    id UUID NOT NULL PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id UUID NOT NULL REFERENCES public.teams (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    domain TEXT NOT NULL,
+    verification_token TEXT NOT NULL,
+    verified_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT team_sso_domains_team_id_domain_key UNIQUE (team_id, domain)
+);
+-- teams can claim the same domain, only one can verify it
+CREATE UNIQUE INDEX IF NOT EXISTS team_sso_domains_verified_domain_idx ON public.team_sso_domains (domain)
+WHERE verified_at IS NOT NULL;
+-- migrate:down
+DROP TABLE IF EXISTS public.team_sso_domains;
+DROP TRIGGER IF EXISTS handle_team_sso_configs_updated_at ON public.team_sso_configs;
+DROP TABLE IF EXISTS public.team_sso_configs;
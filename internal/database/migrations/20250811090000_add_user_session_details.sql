@@ -0,0 +1,25 @@
+-- migrate:up
+ALTER TABLE public.user_sessions
+ADD COLUMN IF NOT EXISTS user_agent TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS device TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS ip_address TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS city TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS user_sessions_user_id_idx ON public.user_sessions (user_id);
+
+-- refresh tokens belong to the session they were issued for and go with it
+ALTER TABLE public.tokens
+ADD COLUMN IF NOT EXISTS session_id UUID REFERENCES public.user_sessions (id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+-- migrate:down
+ALTER TABLE public.tokens DROP COLUMN IF EXISTS session_id;
+
+DROP INDEX IF EXISTS user_sessions_user_id_idx;
+
+ALTER TABLE public.user_sessions
+DROP COLUMN IF EXISTS last_seen_at,
+DROP COLUMN IF EXISTS city,
+DROP COLUMN IF EXISTS ip_address,
+DROP COLUMN IF EXISTS device,
+DROP COLUMN IF EXISTS user_agent;
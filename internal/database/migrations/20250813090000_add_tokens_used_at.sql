@@ -0,0 +1,7 @@
+-- migrate:up
+-- refresh tokens are kept once used, so presenting one again is detected
+ALTER TABLE public.tokens
+ADD COLUMN IF NOT EXISTS used_at TIMESTAMPTZ;
+
+-- migrate:down
+ALTER TABLE public.tokens DROP COLUMN IF EXISTS used_at;
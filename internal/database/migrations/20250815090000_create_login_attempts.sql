@@ -0,0 +1,14 @@
+-- migrate:up
+-- failed sign ins, password reset requests and verifications by email and ip,
+-- kept in the database so every replica throttles the same attempts
+CREATE TABLE IF NOT EXISTS public.login_attempts (
+    action TEXT NOT NULL,
+    email TEXT NOT NULL DEFAULT '',
+    ip_address TEXT NOT NULL DEFAULT '',
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (action, email, ip_address)
+);
+-- migrate:down
+DROP TABLE IF EXISTS public.login_attempts;
@@ -0,0 +1,8 @@
+-- migrate:up transaction:false
+ALTER TYPE public.token_types ADD VALUE IF NOT EXISTS 'magic_link_token';
+-- magic link codes are looked up by the email they were sent to
+CREATE INDEX IF NOT EXISTS tokens_identifier_otp_idx ON public.tokens (lower(identifier), otp)
+WHERE otp IS NOT NULL;
+-- migrate:down
+-- enum values cannot be dropped, 'magic_link_token' stays on public.token_types.
+DROP INDEX IF EXISTS tokens_identifier_otp_idx;
@@ -0,0 +1,52 @@
+-- migrate:up
+CREATE TABLE IF NOT EXISTS public.team_roles (
+    id UUID NOT NULL PRIMARY KEY DEFAULT gen_random_uuid(),
+    -- default roles have no team, a team role of the same name overrides them
+    team_id UUID REFERENCES public.teams (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT,
+    permissions TEXT [] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT team_roles_team_id_name_key UNIQUE (team_id, name)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS team_roles_default_name_idx ON public.team_roles (name)
+WHERE team_id IS NULL;
+CREATE TRIGGER handle_team_roles_updated_at BEFORE
+UPDATE ON public.team_roles FOR EACH ROW EXECUTE PROCEDURE set_current_timestamp_updated_at();
+
+CREATE TABLE IF NOT EXISTS public.team_role_assignments (
+    id UUID NOT NULL PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_member_id UUID NOT NULL REFERENCES public.team_members (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    team_role_id UUID NOT NULL REFERENCES public.team_roles (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    -- assignments without a project apply to the whole team
+    task_project_id UUID REFERENCES public.task_projects (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS team_role_assignments_member_role_project_idx ON public.team_role_assignments (
+    team_member_id,
+    team_role_id,
+    COALESCE(task_project_id, '00000000-0000-0000-0000-000000000000'::uuid)
+);
+
+-- the default roles grant what the owner, member and guest roles allowed
+INSERT INTO public.team_roles (name, description, permissions)
+VALUES (
+        'owner',
+        'Manages the team, its members and its billing',
+        '{team:update,team:delete,member:invite,role:manage,sso:manage,billing:manage,project:create,project:update,project:delete,task:create,task:update,task:delete}'
+    ),
+    (
+        'member',
+        'Works on the projects and tasks of the team',
+        '{billing:manage,project:create,project:update,project:delete,task:create,task:update,task:delete}'
+    ),
+    (
+        'guest',
+        'Works on the projects and tasks of the team',
+        '{billing:manage,project:create,project:update,project:delete,task:create,task:update,task:delete}'
+    ) ON CONFLICT DO NOTHING;
+-- migrate:down
+DROP TABLE IF EXISTS public.team_role_assignments;
+DROP TRIGGER IF EXISTS handle_team_roles_updated_at ON public.team_roles;
+DROP TABLE IF EXISTS public.team_roles;
@@ -0,0 +1,28 @@
+-- migrate:up
+CREATE TABLE IF NOT EXISTS public.impersonations (
+    id UUID NOT NULL PRIMARY KEY DEFAULT gen_random_uuid(),
+    -- the superuser acting as the user
+    impersonator_id UUID NOT NULL REFERENCES public.users (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES public.users (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    -- the session of the superuser, it is restored when the impersonation ends
+    session_id UUID REFERENCES public.user_sessions (id) ON UPDATE CASCADE ON DELETE SET NULL,
+    expires TIMESTAMPTZ NOT NULL,
+    ended_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS impersonations_user_id_idx ON public.impersonations (user_id);
+
+CREATE TABLE IF NOT EXISTS public.audit_logs (
+    id UUID NOT NULL PRIMARY KEY DEFAULT gen_random_uuid(),
+    ip TEXT,
+    -- the email of the user acting
+    email TEXT,
+    audit_log TEXT NOT NULL,
+    attributes JSONB NOT NULL DEFAULT '{}',
+    creation_date TIMESTAMPTZ NOT NULL DEFAULT now(),
+    modification_date TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS audit_logs_impersonation_id_idx ON public.audit_logs ((attributes ->> 'impersonation_id'), creation_date);
+-- migrate:down
+DROP TABLE IF EXISTS public.audit_logs;
+DROP TABLE IF EXISTS public.impersonations;
@@ -0,0 +1,19 @@
+-- migrate:up
+-- notifications delivered by email only, kept for the daily digest
+CREATE TABLE IF NOT EXISTS public.notification_digest_items (
+    id UUID NOT NULL PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_member_id UUID NOT NULL REFERENCES public.team_members (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS notification_digest_items_team_member_id_idx ON public.notification_digest_items (team_member_id, created_at);
+-- unsubscribe links carry the version of their user, bumping it revokes them
+CREATE TABLE IF NOT EXISTS public.notification_unsubscribe_versions (
+    user_id UUID NOT NULL PRIMARY KEY REFERENCES public.users (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    version BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+-- migrate:down
+DROP TABLE IF EXISTS public.notification_unsubscribe_versions;
+DROP TABLE IF EXISTS public.notification_digest_items;
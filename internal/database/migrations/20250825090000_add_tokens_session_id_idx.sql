@@ -0,0 +1,5 @@
+-- migrate:up
+-- refresh tokens are rotated and pruned by session
+CREATE INDEX IF NOT EXISTS tokens_session_id_idx ON public.tokens (session_id, created_at) WHERE session_id IS NOT NULL;
+-- migrate:down
+DROP INDEX IF EXISTS public.tokens_session_id_idx;
@@ -0,0 +1,10 @@
+-- migrate:up
+CREATE SEQUENCE IF NOT EXISTS public.sse_message_id_seq;
+-- ids used to be clock based, start above them so reconnecting clients still
+-- get what they missed
+SELECT setval(
+        'public.sse_message_id_seq',
+        (extract(epoch FROM clock_timestamp()) * 1000000)::BIGINT
+    );
+-- migrate:down
+DROP SEQUENCE IF EXISTS public.sse_message_id_seq;
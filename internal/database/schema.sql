@@ -18,7 +18,8 @@ CREATE TYPE public.job_status AS ENUM (
     'pending',
     'processing',
     'done',
-    'failed'
+    'failed',
+    'cancelled'
 );
 
 
@@ -32,19 +33,6 @@ CREATE TYPE public.provider_types AS ENUM (
 );
 
 
---
--- Name: providers; Type: TYPE; Schema: public; Owner: -
---
-
-CREATE TYPE public.providers AS ENUM (
-    'google',
-    'apple',
-    'facebook',
-    'github',
-    'credentials'
-);
-
-
 --
 -- Name: stripe_customer_type; Type: TYPE; Schema: public; Owner: -
 --
@@ -151,7 +139,8 @@ CREATE TYPE public.token_types AS ENUM (
     'refresh_token',
     'verification_token',
     'password_reset_token',
-    'state_token'
+    'state_token',
+    'magic_link_token'
 );
 
 
@@ -179,6 +168,18 @@ END;
 $$;
 
 
+--
+-- Name: set_notification_seq(); Type: FUNCTION; Schema: public; Owner: -
+--
+
+CREATE FUNCTION public.set_notification_seq() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$ BEGIN NEW.seq := nextval('public.notifications_seq_seq');
+RETURN NEW;
+END;
+$$;
+
+
 --
 -- Name: set_current_timestamp_updated_at(); Type: FUNCTION; Schema: public; Owner: -
 --
@@ -226,6 +227,70 @@ CREATE TABLE public.app_params (
 );
 
 
+--
+-- Name: audit_logs; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.audit_logs (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    ip text,
+    email text,
+    audit_log text NOT NULL,
+    attributes jsonb DEFAULT '{}'::jsonb NOT NULL,
+    creation_date timestamp with time zone DEFAULT now() NOT NULL,
+    modification_date timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: game_ratings; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.game_ratings (
+    user_id uuid NOT NULL,
+    game text NOT NULL,
+    rating integer DEFAULT 1200 NOT NULL,
+    wins integer DEFAULT 0 NOT NULL,
+    losses integer DEFAULT 0 NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: game_results; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.game_results (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    game text NOT NULL,
+    game_id text NOT NULL,
+    winner_id uuid NOT NULL,
+    loser_id uuid NOT NULL,
+    winner_rating integer NOT NULL,
+    loser_rating integer NOT NULL,
+    rating_change integer NOT NULL,
+    moves integer DEFAULT 0 NOT NULL,
+    started_at timestamp with time zone NOT NULL,
+    finished_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: impersonations; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.impersonations (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    impersonator_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    session_id uuid,
+    expires timestamp with time zone NOT NULL,
+    ended_at timestamp with time zone,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
 --
 -- Name: jobs; Type: TABLE; Schema: public; Owner: -
 --
@@ -241,7 +306,28 @@ CREATE TABLE public.jobs (
     max_attempts integer DEFAULT 3 NOT NULL,
     last_error text,
     created_at timestamp with time zone DEFAULT clock_timestamp() NOT NULL,
-    updated_at timestamp with time zone DEFAULT clock_timestamp() NOT NULL
+    updated_at timestamp with time zone DEFAULT clock_timestamp() NOT NULL,
+    result jsonb,
+    progress integer DEFAULT 0 NOT NULL,
+    started_at timestamp with time zone,
+    finished_at timestamp with time zone,
+    group_id uuid,
+    parent_ids uuid[],
+    run_on_parent_failure boolean DEFAULT false NOT NULL
+);
+
+
+--
+-- Name: login_attempts; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.login_attempts (
+    action text NOT NULL,
+    email text DEFAULT ''::text NOT NULL,
+    ip_address text DEFAULT ''::text NOT NULL,
+    failures integer DEFAULT 0 NOT NULL,
+    last_failed_at timestamp with time zone DEFAULT now() NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
 );
 
 
@@ -278,6 +364,47 @@ CREATE TABLE public.media (
 );
 
 
+--
+-- Name: notification_digest_items; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.notification_digest_items (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    team_member_id uuid NOT NULL,
+    type text NOT NULL,
+    payload jsonb NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: notification_preferences; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.notification_preferences (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    user_id uuid NOT NULL,
+    team_id uuid,
+    type text NOT NULL,
+    delivery text DEFAULT 'in_app'::text NOT NULL,
+    daily_digest boolean DEFAULT false NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT notification_preferences_delivery_check CHECK ((delivery = ANY (ARRAY['in_app'::text, 'email'::text, 'both'::text, 'none'::text])))
+);
+
+
+--
+-- Name: notification_unsubscribe_versions; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.notification_unsubscribe_versions (
+    user_id uuid NOT NULL,
+    version bigint DEFAULT 0 NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
 --
 -- Name: notifications; Type: TABLE; Schema: public; Owner: -
 --
@@ -293,7 +420,38 @@ CREATE TABLE public.notifications (
     team_member_id uuid,
     team_id uuid,
     metadata jsonb DEFAULT '{}'::jsonb NOT NULL,
-    type text NOT NULL
+    type text NOT NULL,
+    seq bigint NOT NULL
+);
+
+
+--
+-- Name: notifications_seq_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE public.notifications_seq_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: oidc_providers; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.oidc_providers (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    name text NOT NULL,
+    display_name text NOT NULL,
+    issuer text NOT NULL,
+    client_id text NOT NULL,
+    client_secret text NOT NULL,
+    scopes text[] DEFAULT '{openid,email,profile}'::text[] NOT NULL,
+    enabled boolean DEFAULT true NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
 );
 
 
@@ -362,6 +520,29 @@ CREATE TABLE public.schema_migrations (
 );
 
 
+--
+-- Name: sse_message_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE public.sse_message_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: sse_payloads; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.sse_payloads (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    payload jsonb NOT NULL,
+    created_at timestamp with time zone DEFAULT clock_timestamp() NOT NULL
+);
+
+
 --
 -- Name: stripe_customers; Type: TABLE; Schema: public; Owner: -
 --
@@ -461,6 +642,21 @@ CREATE TABLE public.stripe_webhook_events (
 );
 
 
+--
+-- Name: task_project_presences; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.task_project_presences (
+    project_id uuid NOT NULL,
+    team_member_id uuid NOT NULL,
+    task_id uuid,
+    state text NOT NULL,
+    expires_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT task_project_presences_state_check CHECK ((state = ANY (ARRAY['viewing'::text, 'editing'::text])))
+);
+
+
 --
 -- Name: task_projects; Type: TABLE; Schema: public; Owner: -
 --
@@ -540,6 +736,65 @@ CREATE TABLE public.team_members (
 );
 
 
+--
+-- Name: team_role_assignments; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.team_role_assignments (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    team_member_id uuid NOT NULL,
+    team_role_id uuid NOT NULL,
+    task_project_id uuid,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: team_roles; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.team_roles (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    team_id uuid,
+    name text NOT NULL,
+    description text,
+    permissions text[] DEFAULT '{}'::text[] NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: team_sso_configs; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.team_sso_configs (
+    team_id uuid NOT NULL,
+    issuer text NOT NULL,
+    client_id text NOT NULL,
+    client_secret text NOT NULL,
+    scopes text[] DEFAULT '{openid,email,profile}'::text[] NOT NULL,
+    default_role public.team_member_role DEFAULT 'member'::public.team_member_role NOT NULL,
+    enforced boolean DEFAULT false NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: team_sso_domains; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.team_sso_domains (
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    team_id uuid NOT NULL,
+    domain text NOT NULL,
+    verification_token text NOT NULL,
+    verified_at timestamp with time zone,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
 --
 -- Name: teams; Type: TABLE; Schema: public; Owner: -
 --
@@ -567,6 +822,8 @@ CREATE TABLE public.tokens (
     token text NOT NULL,
     created_at timestamp with time zone DEFAULT now() NOT NULL,
     updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    session_id uuid,
+    used_at timestamp with time zone,
     CONSTRAINT tokens_type_identifier_token_not_empty CHECK ((public.not_empty(identifier) AND public.not_empty(token)))
 );
 
@@ -579,7 +836,7 @@ CREATE TABLE public.user_accounts (
     id uuid DEFAULT gen_random_uuid() NOT NULL,
     user_id uuid NOT NULL,
     type public.provider_types NOT NULL,
-    provider public.providers NOT NULL,
+    provider text NOT NULL,
     provider_account_id character varying(255) NOT NULL,
     password text,
     refresh_token text,
@@ -643,6 +900,11 @@ CREATE TABLE public.user_sessions (
     session_token character varying(255) NOT NULL,
     created_at timestamp with time zone DEFAULT now() NOT NULL,
     updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    user_agent text DEFAULT ''::text NOT NULL,
+    device text DEFAULT ''::text NOT NULL,
+    ip_address text DEFAULT ''::text NOT NULL,
+    city text DEFAULT ''::text NOT NULL,
+    last_seen_at timestamp with time zone DEFAULT now() NOT NULL,
     CONSTRAINT user_sessions_token_not_empty CHECK (public.not_empty((session_token)::text))
 );
 
@@ -686,6 +948,46 @@ ALTER TABLE ONLY public.app_params
     ADD CONSTRAINT app_params_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: audit_logs audit_logs_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.audit_logs
+    ADD CONSTRAINT audit_logs_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: game_ratings game_ratings_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.game_ratings
+    ADD CONSTRAINT game_ratings_pkey PRIMARY KEY (user_id, game);
+
+
+--
+-- Name: game_results game_results_game_game_id_key; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.game_results
+    ADD CONSTRAINT game_results_game_game_id_key UNIQUE (game, game_id);
+
+
+--
+-- Name: game_results game_results_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.game_results
+    ADD CONSTRAINT game_results_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: impersonations impersonations_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.impersonations
+    ADD CONSTRAINT impersonations_pkey PRIMARY KEY (id);
+
+
 --
 -- Name: jobs jobs_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -694,6 +996,14 @@ ALTER TABLE ONLY public.jobs
     ADD CONSTRAINT jobs_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: login_attempts login_attempts_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.login_attempts
+    ADD CONSTRAINT login_attempts_pkey PRIMARY KEY (action, email, ip_address);
+
+
 --
 -- Name: logs logs_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -718,6 +1028,38 @@ ALTER TABLE ONLY public.media
     ADD CONSTRAINT media_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: notification_digest_items notification_digest_items_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.notification_digest_items
+    ADD CONSTRAINT notification_digest_items_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: notification_preferences notification_preferences_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.notification_preferences
+    ADD CONSTRAINT notification_preferences_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: notification_preferences notification_preferences_user_id_team_id_type_key; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.notification_preferences
+    ADD CONSTRAINT notification_preferences_user_id_team_id_type_key UNIQUE NULLS NOT DISTINCT (user_id, team_id, type);
+
+
+--
+-- Name: notification_unsubscribe_versions notification_unsubscribe_versions_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.notification_unsubscribe_versions
+    ADD CONSTRAINT notification_unsubscribe_versions_pkey PRIMARY KEY (user_id);
+
+
 --
 -- Name: notifications notifications_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -726,6 +1068,22 @@ ALTER TABLE ONLY public.notifications
     ADD CONSTRAINT notifications_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: oidc_providers oidc_providers_name_key; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.oidc_providers
+    ADD CONSTRAINT oidc_providers_name_key UNIQUE (name);
+
+
+--
+-- Name: oidc_providers oidc_providers_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.oidc_providers
+    ADD CONSTRAINT oidc_providers_pkey PRIMARY KEY (id);
+
+
 --
 -- Name: permissions permissions_name_key; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -790,6 +1148,14 @@ ALTER TABLE ONLY public.schema_migrations
     ADD CONSTRAINT schema_migrations_pkey PRIMARY KEY (version);
 
 
+--
+-- Name: sse_payloads sse_payloads_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.sse_payloads
+    ADD CONSTRAINT sse_payloads_pkey PRIMARY KEY (id);
+
+
 --
 -- Name: stripe_customers stripe_customers_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -846,6 +1212,14 @@ ALTER TABLE ONLY public.stripe_webhook_events
     ADD CONSTRAINT stripe_webhook_events_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: task_project_presences task_project_presences_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.task_project_presences
+    ADD CONSTRAINT task_project_presences_pkey PRIMARY KEY (project_id, team_member_id);
+
+
 --
 -- Name: task_projects task_projects_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -902,6 +1276,54 @@ ALTER TABLE ONLY public.team_members
     ADD CONSTRAINT team_members_user_id_team_id UNIQUE (user_id, team_id);
 
 
+--
+-- Name: team_role_assignments team_role_assignments_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.team_role_assignments
+    ADD CONSTRAINT team_role_assignments_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: team_roles team_roles_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.team_roles
+    ADD CONSTRAINT team_roles_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: team_roles team_roles_team_id_name_key; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.team_roles
+    ADD CONSTRAINT team_roles_team_id_name_key UNIQUE (team_id, name);
+
+
+--
+-- Name: team_sso_configs team_sso_configs_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.team_sso_configs
+    ADD CONSTRAINT team_sso_configs_pkey PRIMARY KEY (team_id);
+
+
+--
+-- Name: team_sso_domains team_sso_domains_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.team_sso_domains
+    ADD CONSTRAINT team_sso_domains_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: team_sso_domains team_sso_domains_team_id_domain_key; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.team_sso_domains
+    ADD CONSTRAINT team_sso_domains_team_id_domain_key UNIQUE (team_id, domain);
+
+
 --
 -- Name: teams teams_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -1014,6 +1436,34 @@ ALTER TABLE ONLY public.users
     ADD CONSTRAINT users_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: audit_logs_impersonation_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX audit_logs_impersonation_id_idx ON public.audit_logs USING btree (((attributes ->> 'impersonation_id'::text)), creation_date);
+
+
+--
+-- Name: game_ratings_game_rating_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX game_ratings_game_rating_idx ON public.game_ratings USING btree (game, rating DESC);
+
+
+--
+-- Name: game_results_loser_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX game_results_loser_id_idx ON public.game_results USING btree (loser_id);
+
+
+--
+-- Name: game_results_winner_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX game_results_winner_id_idx ON public.game_results USING btree (winner_id);
+
+
 --
 -- Name: idx_logs_created_at; Type: INDEX; Schema: public; Owner: -
 --
@@ -1042,6 +1492,34 @@ CREATE INDEX idx_logs_level ON public.logs USING btree (level);
 CREATE INDEX idx_logs_source ON public.logs USING btree (source);
 
 
+--
+-- Name: impersonations_user_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX impersonations_user_id_idx ON public.impersonations USING btree (user_id);
+
+
+--
+-- Name: jobs_finished_at_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX jobs_finished_at_idx ON public.jobs USING btree (finished_at) WHERE (finished_at IS NOT NULL);
+
+
+--
+-- Name: jobs_group_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX jobs_group_id_idx ON public.jobs USING btree (group_id) WHERE (group_id IS NOT NULL);
+
+
+--
+-- Name: jobs_parent_ids_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX jobs_parent_ids_idx ON public.jobs USING gin (parent_ids);
+
+
 --
 -- Name: jobs_polling_idx; Type: INDEX; Schema: public; Owner: -
 --
@@ -1049,6 +1527,90 @@ CREATE INDEX idx_logs_source ON public.logs USING btree (source);
 CREATE INDEX jobs_polling_idx ON public.jobs USING btree (status, run_after, attempts);
 
 
+--
+-- Name: notification_digest_items_team_member_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX notification_digest_items_team_member_id_idx ON public.notification_digest_items USING btree (team_member_id, created_at);
+
+
+--
+-- Name: notifications_created_at_read_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX notifications_created_at_read_idx ON public.notifications USING btree (created_at) WHERE (read_at IS NOT NULL);
+
+
+--
+-- Name: notifications_seq_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE UNIQUE INDEX notifications_seq_idx ON public.notifications USING btree (seq);
+
+
+--
+-- Name: notifications_team_member_id_seq_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX notifications_team_member_id_seq_idx ON public.notifications USING btree (team_member_id, seq);
+
+
+--
+-- Name: notifications_team_member_id_unread_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX notifications_team_member_id_unread_idx ON public.notifications USING btree (team_member_id, type) WHERE (read_at IS NULL);
+
+
+--
+-- Name: sse_payloads_created_at_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX sse_payloads_created_at_idx ON public.sse_payloads USING btree (created_at);
+
+
+--
+-- Name: task_project_presences_expires_at_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX task_project_presences_expires_at_idx ON public.task_project_presences USING btree (expires_at);
+
+
+--
+-- Name: team_role_assignments_member_role_project_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE UNIQUE INDEX team_role_assignments_member_role_project_idx ON public.team_role_assignments USING btree (team_member_id, team_role_id, COALESCE(task_project_id, '00000000-0000-0000-0000-000000000000'::uuid));
+
+
+--
+-- Name: team_roles_default_name_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE UNIQUE INDEX team_roles_default_name_idx ON public.team_roles USING btree (name) WHERE (team_id IS NULL);
+
+
+--
+-- Name: team_sso_domains_verified_domain_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE UNIQUE INDEX team_sso_domains_verified_domain_idx ON public.team_sso_domains USING btree (domain) WHERE (verified_at IS NOT NULL);
+
+
+--
+-- Name: tokens_identifier_otp_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX tokens_identifier_otp_idx ON public.tokens USING btree (lower(identifier), otp) WHERE (otp IS NOT NULL);
+
+
+--
+-- Name: tokens_session_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX tokens_session_id_idx ON public.tokens USING btree (session_id, created_at) WHERE (session_id IS NOT NULL);
+
+
 --
 -- Name: uniq_jobs_active_key; Type: INDEX; Schema: public; Owner: -
 --
@@ -1056,6 +1618,13 @@ CREATE INDEX jobs_polling_idx ON public.jobs USING btree (status, run_after, att
 CREATE UNIQUE INDEX uniq_jobs_active_key ON public.jobs USING btree (unique_key) WHERE (status = ANY (ARRAY['pending'::public.job_status, 'processing'::public.job_status]));
 
 
+--
+-- Name: user_sessions_user_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX user_sessions_user_id_idx ON public.user_sessions USING btree (user_id);
+
+
 --
 -- Name: app_params handle_app_params_updated_at; Type: TRIGGER; Schema: public; Owner: -
 --
@@ -1063,6 +1632,13 @@ CREATE UNIQUE INDEX uniq_jobs_active_key ON public.jobs USING btree (unique_key)
 CREATE TRIGGER handle_app_params_updated_at BEFORE UPDATE ON public.app_params FOR EACH ROW EXECUTE FUNCTION public.set_current_timestamp_updated_at();
 
 
+--
+-- Name: game_ratings handle_game_ratings_updated_at; Type: TRIGGER; Schema: public; Owner: -
+--
+
+CREATE TRIGGER handle_game_ratings_updated_at BEFORE UPDATE ON public.game_ratings FOR EACH ROW EXECUTE FUNCTION public.set_current_timestamp_updated_at();
+
+
 --
 -- Name: media handle_media_updated_at; Type: TRIGGER; Schema: public; Owner: -
 --
@@ -1070,6 +1646,20 @@ CREATE TRIGGER handle_app_params_updated_at BEFORE UPDATE ON public.app_params F
 CREATE TRIGGER handle_media_updated_at BEFORE UPDATE ON public.media FOR EACH ROW EXECUTE FUNCTION public.set_current_timestamp_updated_at();
 
 
+--
+-- Name: notification_preferences handle_notification_preferences_updated_at; Type: TRIGGER; Schema: public; Owner: -
+--
+
+CREATE TRIGGER handle_notification_preferences_updated_at BEFORE UPDATE ON public.notification_preferences FOR EACH ROW EXECUTE FUNCTION public.set_current_timestamp_updated_at();
+
+
+--
+-- Name: oidc_providers handle_oidc_providers_updated_at; Type: TRIGGER; Schema: public; Owner: -
+--
+
+CREATE TRIGGER handle_oidc_providers_updated_at BEFORE UPDATE ON public.oidc_providers FOR EACH ROW EXECUTE FUNCTION public.set_current_timestamp_updated_at();
+
+
 --
 -- Name: permissions handle_permissions_updated_at; Type: TRIGGER; Schema: public; Owner: -
 --
@@ -1140,6 +1730,20 @@ CREATE TRIGGER handle_tasks_updated_at BEFORE UPDATE ON public.tasks FOR EACH RO
 CREATE TRIGGER handle_team_members_updated_at BEFORE UPDATE ON public.team_members FOR EACH ROW EXECUTE FUNCTION public.set_current_timestamp_updated_at();
 
 
+--
+-- Name: team_roles handle_team_roles_updated_at; Type: TRIGGER; Schema: public; Owner: -
+--
+
+CREATE TRIGGER handle_team_roles_updated_at BEFORE UPDATE ON public.team_roles FOR EACH ROW EXECUTE FUNCTION public.set_current_timestamp_updated_at();
+
+
+--
+-- Name: team_sso_configs handle_team_sso_configs_updated_at; Type: TRIGGER; Schema: public; Owner: -
+--
+
+CREATE TRIGGER handle_team_sso_configs_updated_at BEFORE UPDATE ON public.team_sso_configs FOR EACH ROW EXECUTE FUNCTION public.set_current_timestamp_updated_at();
+
+
 --
 -- Name: teams handle_teams_updated_at; Type: TRIGGER; Schema: public; Owner: -
 --
@@ -1189,6 +1793,13 @@ CREATE TRIGGER handle_users_updated_at BEFORE UPDATE ON public.users FOR EACH RO
 CREATE TRIGGER trigger_notify_after_notification_insert AFTER INSERT ON public.notifications FOR EACH ROW EXECUTE FUNCTION public.notify_after_notification_insert();
 
 
+--
+-- Name: notifications trigger_set_notification_seq; Type: TRIGGER; Schema: public; Owner: -
+--
+
+CREATE TRIGGER trigger_set_notification_seq BEFORE INSERT ON public.notifications FOR EACH ROW EXECUTE FUNCTION public.set_notification_seq();
+
+
 --
 -- Name: ai_usages ai_usages_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
@@ -1221,6 +1832,54 @@ ALTER TABLE ONLY public.notifications
     ADD CONSTRAINT fk_notifications_user FOREIGN KEY (user_id) REFERENCES public.users(id) ON UPDATE CASCADE ON DELETE CASCADE;
 
 
+--
+-- Name: game_ratings game_ratings_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.game_ratings
+    ADD CONSTRAINT game_ratings_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: game_results game_results_loser_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.game_results
+    ADD CONSTRAINT game_results_loser_id_fkey FOREIGN KEY (loser_id) REFERENCES public.users(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: game_results game_results_winner_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.game_results
+    ADD CONSTRAINT game_results_winner_id_fkey FOREIGN KEY (winner_id) REFERENCES public.users(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: impersonations impersonations_impersonator_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.impersonations
+    ADD CONSTRAINT impersonations_impersonator_id_fkey FOREIGN KEY (impersonator_id) REFERENCES public.users(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: impersonations impersonations_session_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.impersonations
+    ADD CONSTRAINT impersonations_session_id_fkey FOREIGN KEY (session_id) REFERENCES public.user_sessions(id) ON UPDATE CASCADE ON DELETE SET NULL;
+
+
+--
+-- Name: impersonations impersonations_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.impersonations
+    ADD CONSTRAINT impersonations_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
 --
 -- Name: media media_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
@@ -1229,6 +1888,38 @@ ALTER TABLE ONLY public.media
     ADD CONSTRAINT media_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON UPDATE CASCADE ON DELETE SET NULL;
 
 
+--
+-- Name: notification_digest_items notification_digest_items_team_member_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.notification_digest_items
+    ADD CONSTRAINT notification_digest_items_team_member_id_fkey FOREIGN KEY (team_member_id) REFERENCES public.team_members(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: notification_preferences notification_preferences_team_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.notification_preferences
+    ADD CONSTRAINT notification_preferences_team_id_fkey FOREIGN KEY (team_id) REFERENCES public.teams(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: notification_preferences notification_preferences_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.notification_preferences
+    ADD CONSTRAINT notification_preferences_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: notification_unsubscribe_versions notification_unsubscribe_versions_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.notification_unsubscribe_versions
+    ADD CONSTRAINT notification_unsubscribe_versions_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
 --
 -- Name: product_permissions product_permissions_permission_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
@@ -1317,6 +2008,30 @@ ALTER TABLE ONLY public.stripe_subscriptions
     ADD CONSTRAINT stripe_subscriptions_stripe_customer_id_fkey FOREIGN KEY (stripe_customer_id) REFERENCES public.stripe_customers(id);
 
 
+--
+-- Name: task_project_presences task_project_presences_project_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.task_project_presences
+    ADD CONSTRAINT task_project_presences_project_id_fkey FOREIGN KEY (project_id) REFERENCES public.task_projects(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: task_project_presences task_project_presences_task_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.task_project_presences
+    ADD CONSTRAINT task_project_presences_task_id_fkey FOREIGN KEY (task_id) REFERENCES public.tasks(id) ON UPDATE CASCADE ON DELETE SET NULL;
+
+
+--
+-- Name: task_project_presences task_project_presences_team_member_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.task_project_presences
+    ADD CONSTRAINT task_project_presences_team_member_id_fkey FOREIGN KEY (team_member_id) REFERENCES public.team_members(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
 --
 -- Name: task_projects task_projects_assignee_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
@@ -1429,6 +2144,62 @@ ALTER TABLE ONLY public.team_members
     ADD CONSTRAINT team_members_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON UPDATE CASCADE ON DELETE SET NULL;
 
 
+--
+-- Name: team_role_assignments team_role_assignments_task_project_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.team_role_assignments
+    ADD CONSTRAINT team_role_assignments_task_project_id_fkey FOREIGN KEY (task_project_id) REFERENCES public.task_projects(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: team_role_assignments team_role_assignments_team_member_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.team_role_assignments
+    ADD CONSTRAINT team_role_assignments_team_member_id_fkey FOREIGN KEY (team_member_id) REFERENCES public.team_members(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: team_role_assignments team_role_assignments_team_role_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.team_role_assignments
+    ADD CONSTRAINT team_role_assignments_team_role_id_fkey FOREIGN KEY (team_role_id) REFERENCES public.team_roles(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: team_roles team_roles_team_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.team_roles
+    ADD CONSTRAINT team_roles_team_id_fkey FOREIGN KEY (team_id) REFERENCES public.teams(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: team_sso_configs team_sso_configs_team_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.team_sso_configs
+    ADD CONSTRAINT team_sso_configs_team_id_fkey FOREIGN KEY (team_id) REFERENCES public.teams(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: team_sso_domains team_sso_domains_team_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.team_sso_domains
+    ADD CONSTRAINT team_sso_domains_team_id_fkey FOREIGN KEY (team_id) REFERENCES public.teams(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+--
+-- Name: tokens tokens_session_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.tokens
+    ADD CONSTRAINT tokens_session_id_fkey FOREIGN KEY (session_id) REFERENCES public.user_sessions(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
 --
 -- Name: tokens tokens_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
@@ -1517,4 +2288,24 @@ INSERT INTO public.schema_migrations (version) VALUES
     ('20250419024345'),
     ('20250505071914'),
     ('20250523035749'),
-    ('20250717035205');
+    ('20250717035205'),
+    ('20250720090000'),
+    ('20250722090000'),
+    ('20250724090000'),
+    ('20250726090000'),
+    ('20250728090000'),
+    ('20250730090000'),
+    ('20250801090000'),
+    ('20250803090000'),
+    ('20250805090000'),
+    ('20250807090000'),
+    ('20250809090000'),
+    ('20250811090000'),
+    ('20250813090000'),
+    ('20250815090000'),
+    ('20250817090000'),
+    ('20250819090000'),
+    ('20250821090000'),
+    ('20250823090000'),
+    ('20250825090000'),
+    ('20250827090000');
@@ -0,0 +1,18 @@
+// Package games holds what the games in its subpackages share.
+package games
+
+import "math"
+
+const (
+	// DefaultRating is the rating of a player before their first game.
+	DefaultRating = 1200
+	// EloK is the most a rating can change after one game.
+	EloK = 32
+)
+
+// Elo returns the ratings of the winner and loser of a game after it.
+func Elo(winner, loser int) (int, int) {
+	expected := 1 / (1 + math.Pow(10, float64(loser-winner)/400))
+	change := int(math.Round(EloK * (1 - expected)))
+	return winner + change, loser - change
+}
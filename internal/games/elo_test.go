@@ -0,0 +1,25 @@
+package games
+
+import "testing"
+
+func TestElo(t *testing.T) {
+	tests := []struct {
+		name       string
+		winner     int
+		loser      int
+		wantWinner int
+		wantLoser  int
+	}{
+		{name: "equal ratings", winner: 1200, loser: 1200, wantWinner: 1216, wantLoser: 1184},
+		{name: "favourite wins", winner: 1600, loser: 1200, wantWinner: 1603, wantLoser: 1197},
+		{name: "underdog wins", winner: 1200, loser: 1600, wantWinner: 1229, wantLoser: 1571},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotWinner, gotLoser := Elo(tt.winner, tt.loser)
+			if gotWinner != tt.wantWinner || gotLoser != tt.wantLoser {
+				t.Errorf("Elo() = %d, %d, want %d, %d", gotWinner, gotLoser, tt.wantWinner, tt.wantLoser)
+			}
+		})
+	}
+}
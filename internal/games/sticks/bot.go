@@ -0,0 +1,101 @@
+package sticks
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// BotIDPrefix starts the player id of every bot.
+const BotIDPrefix = "bot:"
+
+// Difficulty is how well a bot plays.
+type Difficulty string
+
+const (
+	DifficultyEasy   Difficulty = "easy"
+	DifficultyMedium Difficulty = "medium"
+	DifficultyHard   Difficulty = "hard"
+)
+
+// ParseDifficulty returns the difficulty named s.
+func ParseDifficulty(s string) (Difficulty, error) {
+	switch d := Difficulty(s); d {
+	case DifficultyEasy, DifficultyMedium, DifficultyHard:
+		return d, nil
+	}
+	return "", fmt.Errorf("unknown difficulty %q", s)
+}
+
+// mistakeRate is how often a bot plays a random move instead of the best one.
+func (d Difficulty) mistakeRate() float64 {
+	switch d {
+	case DifficultyEasy:
+		return 0.75
+	case DifficultyMedium:
+		return 0.35
+	}
+	return 0
+}
+
+// IsBot reports whether the player with the id is a bot.
+func IsBot(playerID string) bool {
+	return strings.HasPrefix(playerID, BotIDPrefix)
+}
+
+// Bot plays sticks from the solution of the game. A hard bot plays perfectly
+// and never loses a won or drawn position, easier bots make random moves.
+type Bot struct {
+	Player     *Player
+	Difficulty Difficulty
+	solution   *Solution
+	rand       *rand.Rand
+}
+
+func NewBot(difficulty Difficulty) *Bot {
+	return &Bot{
+		Player:     NewPlayer(BotIDPrefix+uuid.NewString(), fmt.Sprintf("Bot (%s)", difficulty)),
+		Difficulty: difficulty,
+		solution:   Solve(),
+		rand:       rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+	}
+}
+
+// ChooseMove returns the move of the bot in state, it is false without legal
+// moves.
+func (b *Bot) ChooseMove(state State) (Move, bool) {
+	moves := state.Moves()
+	if len(moves) == 0 {
+		return Move{}, false
+	}
+	if b.rand.Float64() < b.Difficulty.mistakeRate() {
+		return moves[b.rand.IntN(len(moves))], true
+	}
+	return b.solution.BestMove(state)
+}
+
+// Play makes the move of the bot in a game where it is its turn.
+func (b *Bot) Play(g GameInterface) error {
+	current := g.GetCurrentPlayer()
+	if current == nil || current.ID != b.Player.ID {
+		return ErrNotYourTurn
+	}
+	move, ok := b.ChooseMove(NewState(current, g.GetOpponent()))
+	if !ok {
+		return fmt.Errorf("%w: no legal move", ErrIllegalMove)
+	}
+	return PlayMove(g, move)
+}
+
+// PlayMove makes a move in a game for the player whose turn it is.
+func PlayMove(g GameInterface, m Move) error {
+	switch m.Kind {
+	case MoveAttack:
+		return g.Attack(m.AttackWithLeft, m.AttackLeft)
+	case MoveSplit:
+		return g.Split(m.FromLeft, m.Points)
+	}
+	return fmt.Errorf("%w: unknown move %q", ErrIllegalMove, m.Kind)
+}
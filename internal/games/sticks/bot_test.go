@@ -0,0 +1,139 @@
+package sticks
+
+import (
+	"errors"
+	"math/rand/v2"
+	"testing"
+	"time"
+)
+
+func TestParseDifficulty(t *testing.T) {
+	for _, d := range []Difficulty{DifficultyEasy, DifficultyMedium, DifficultyHard} {
+		if got, err := ParseDifficulty(string(d)); err != nil || got != d {
+			t.Errorf("ParseDifficulty(%q) = %q, %v", d, got, err)
+		}
+	}
+	if _, err := ParseDifficulty("impossible"); err == nil {
+		t.Error("ParseDifficulty() of an unknown difficulty did not fail")
+	}
+}
+
+// newBotGame starts a game of a bot moving first against a player, with the
+// hands set when given.
+func newBotGame(t *testing.T, bot *Bot, hands *State) (*Game, *Player) {
+	t.Helper()
+	player := NewPlayer("player", "")
+	game := NewGame("game")
+	if err := errors.Join(game.AddPlayer(bot.Player), game.AddPlayer(player), game.StartGame()); err != nil {
+		t.Fatalf("starting game: %v", err)
+	}
+	if hands != nil {
+		bot.Player.LeftHand.Set(hands.Hands[0][0])
+		bot.Player.RightHand.Set(hands.Hands[0][1])
+		player.LeftHand.Set(hands.Hands[1][0])
+		player.RightHand.Set(hands.Hands[1][1])
+	}
+	return game, player
+}
+
+// playRandom plays a game of the bot against random moves, it returns the
+// winner or nil when the game did not end within maxMoves.
+func playRandom(t *testing.T, game *Game, bot *Bot, r *rand.Rand, maxMoves int) *Player {
+	t.Helper()
+	for range maxMoves {
+		if game.State == GameStateFinished {
+			return game.Winner
+		}
+		if game.GetCurrentPlayer() == bot.Player {
+			if err := bot.Play(game); err != nil {
+				t.Fatalf("Bot.Play() error = %v", err)
+			}
+			continue
+		}
+		moves := NewState(game.GetCurrentPlayer(), game.GetOpponent()).Moves()
+		if err := PlayMove(game, moves[r.IntN(len(moves))]); err != nil {
+			t.Fatalf("PlayMove() error = %v", err)
+		}
+	}
+	if game.State == GameStateFinished {
+		return game.Winner
+	}
+	return nil
+}
+
+func TestBot_HardNeverLoses(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	wins := 0
+	for range 200 {
+		bot := NewBot(DifficultyHard)
+		game, player := newBotGame(t, bot, nil)
+		winner := playRandom(t, game, bot, r, 200)
+		if winner == player {
+			t.Fatalf("hard bot lost against random moves")
+		}
+		if winner == bot.Player {
+			wins++
+		}
+	}
+	if wins == 0 {
+		t.Error("hard bot never beat random moves")
+	}
+}
+
+func TestBot_HardWinsFromWonPosition(t *testing.T) {
+	won := State{Hands: [2][2]int{{1, 1}, {1, 5}}}
+	if outcome, _ := Solve().Outcome(won); outcome != OutcomeWin {
+		t.Fatalf("test position is a %s", outcome)
+	}
+	r := rand.New(rand.NewPCG(3, 4))
+	for range 100 {
+		bot := NewBot(DifficultyHard)
+		game, _ := newBotGame(t, bot, &won)
+		if winner := playRandom(t, game, bot, r, 50); winner != bot.Player {
+			t.Fatalf("hard bot did not win a won position, winner %v", winner)
+		}
+	}
+}
+
+func TestBot_Play(t *testing.T) {
+	bot := NewBot(DifficultyEasy)
+	game, player := newBotGame(t, bot, nil)
+	if err := bot.Play(game); err != nil {
+		t.Fatalf("Bot.Play() error = %v", err)
+	}
+	if game.GetCurrentPlayer() != player {
+		t.Error("Bot.Play() did not end the turn of the bot")
+	}
+	if err := bot.Play(game); !errors.Is(err, ErrNotYourTurn) {
+		t.Errorf("Bot.Play() error = %v, want %v", err, ErrNotYourTurn)
+	}
+}
+
+func TestGameBroker_BotOpponent(t *testing.T) {
+	broker := NewGameBroker(10)
+	botMoves := make(chan struct{}, 10)
+	broker.SetBotOpponent(10*time.Millisecond, func(*Game) { botMoves <- struct{}{} })
+	broker.Start()
+	defer broker.Stop()
+
+	player := NewPlayer("player", "")
+	game, err := broker.RequestGameContext(t.Context(), player, WithBotDifficulty(DifficultyHard))
+	if err != nil {
+		t.Fatalf("GameBroker.RequestGameContext() error = %v", err)
+	}
+	view := game.View()
+	if !view.Players[1].Bot {
+		t.Fatalf("GameBroker.RequestGameContext() opponent %s is not a bot", view.Players[1].ID)
+	}
+	if err := game.AttackAs(player.ID, true, true); err != nil {
+		t.Fatalf("Game.AttackAs() error = %v", err)
+	}
+	select {
+	case <-botMoves:
+	case <-time.After(5 * time.Second):
+		t.Fatal("bot did not move")
+	}
+	if got := game.View().CurrentPlayerID; got != player.ID && game.View().State == GameStateInProgress {
+		t.Errorf("turn of %s after the bot moved, want %s", got, player.ID)
+	}
+}
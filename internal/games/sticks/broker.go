@@ -2,16 +2,42 @@ package sticks
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"log"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrQueueFull           = errors.New("matchmaking queue is full")
+	ErrBrokerStopped       = errors.New("broker is shutting down")
+	ErrMatchmakingTimeout  = errors.New("matchmaking timeout")
+	ErrMatchmakingCanceled = errors.New("matchmaking cancelled")
+	ErrAtCapacity          = errors.New("server at capacity")
 )
 
 // MatchmakingRequest represents a player's request to join a game
 type MatchmakingRequest struct {
 	Player   *Player
 	Response chan *MatchmakingResponse
+	// ctx is done once the player stopped waiting
+	ctx context.Context
+	// difficulty is the one of the bot the player gets without a human opponent
+	difficulty Difficulty
+}
+
+// RequestOption changes a matchmaking request.
+type RequestOption func(*MatchmakingRequest)
+
+// WithBotDifficulty picks the difficulty of the bot the player is matched
+// with when no human opponent is found.
+func WithBotDifficulty(difficulty Difficulty) RequestOption {
+	return func(r *MatchmakingRequest) {
+		r.difficulty = difficulty
+	}
 }
 
 // MatchmakingResponse contains the result of matchmaking
@@ -26,9 +52,15 @@ type GameBroker struct {
 	maxConcurrentGames int
 	matchmakingTimeout time.Duration
 	gameTimeout        time.Duration
+	// botWait is how long a player waits before a bot is matched, zero
+	// disables bots
+	botWait   time.Duration
+	onBotMove func(*Game)
 
 	// Matchmaking queue
 	queue chan *MatchmakingRequest
+	// waiting is 1 while a player waits for an opponent
+	waiting atomic.Int32
 
 	// Active games tracking
 	activeGames map[string]*GameSession
@@ -70,6 +102,14 @@ func NewGameBroker(maxConcurrentGames int) *GameBroker {
 	}
 }
 
+// SetBotOpponent matches players that waited for wait without a human
+// opponent with a bot, onMove is called after every move of a bot. It must be
+// called before Start.
+func (gb *GameBroker) SetBotOpponent(wait time.Duration, onMove func(*Game)) {
+	gb.botWait = wait
+	gb.onBotMove = onMove
+}
+
 // Start begins the matchmaking broker
 func (gb *GameBroker) Start() {
 	gb.wg.Add(3)
@@ -88,8 +128,8 @@ func (gb *GameBroker) Start() {
 
 // Stop gracefully shuts down the broker
 func (gb *GameBroker) Stop() {
+	// the queue is left open, a closed queue would panic concurrent requests
 	gb.cancel()
-	close(gb.queue)
 	gb.wg.Wait()
 
 	// Clean up remaining games
@@ -104,11 +144,24 @@ func (gb *GameBroker) Stop() {
 
 // RequestGame adds a player to the matchmaking queue
 func (gb *GameBroker) RequestGame(player *Player) (*Game, error) {
+	return gb.RequestGameContext(context.Background(), player)
+}
+
+// RequestGameContext adds a player to the matchmaking queue until a game is
+// found or ctx is done. A player that stopped waiting is never matched.
+func (gb *GameBroker) RequestGameContext(ctx context.Context, player *Player, opts ...RequestOption) (*Game, error) {
 	responseChan := make(chan *MatchmakingResponse, 1)
 
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 	request := &MatchmakingRequest{
-		Player:   player,
-		Response: responseChan,
+		Player:     player,
+		Response:   responseChan,
+		ctx:        ctx,
+		difficulty: DifficultyMedium,
+	}
+	for _, opt := range opts {
+		opt(request)
 	}
 
 	// Try to add to queue with timeout
@@ -116,9 +169,11 @@ func (gb *GameBroker) RequestGame(player *Player) (*Game, error) {
 	case gb.queue <- request:
 		// Successfully queued
 	case <-time.After(5 * time.Second):
-		return nil, fmt.Errorf("matchmaking queue is full")
+		return nil, ErrQueueFull
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	case <-gb.ctx.Done():
-		return nil, fmt.Errorf("broker is shutting down")
+		return nil, ErrBrokerStopped
 	}
 
 	// Wait for response
@@ -126,9 +181,11 @@ func (gb *GameBroker) RequestGame(player *Player) (*Game, error) {
 	case response := <-responseChan:
 		return response.Game, response.Error
 	case <-time.After(gb.matchmakingTimeout):
-		return nil, fmt.Errorf("matchmaking timeout")
+		return nil, ErrMatchmakingTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	case <-gb.ctx.Done():
-		return nil, fmt.Errorf("broker is shutting down")
+		return nil, ErrBrokerStopped
 	}
 }
 
@@ -136,7 +193,12 @@ func (gb *GameBroker) RequestGame(player *Player) (*Game, error) {
 func (gb *GameBroker) matchmakingWorker() {
 	defer gb.wg.Done()
 
-	var waitingPlayer *MatchmakingRequest
+	var (
+		waitingPlayer *MatchmakingRequest
+		// botTimer fires when the waiting player gets a bot
+		botTimer <-chan time.Time
+	)
+	defer gb.waiting.Store(0)
 
 	for {
 		select {
@@ -150,21 +212,49 @@ func (gb *GameBroker) matchmakingWorker() {
 				return
 			}
 
+			if waitingPlayer != nil && waitingPlayer.ctx.Err() != nil {
+				// The waiting player gave up
+				waitingPlayer = nil
+			}
+			if waitingPlayer != nil && waitingPlayer.Player.ID == request.Player.ID {
+				// The same player asked again, only the newest request waits
+				waitingPlayer.Response <- &MatchmakingResponse{Error: ErrMatchmakingCanceled}
+				waitingPlayer = nil
+			}
+
 			if waitingPlayer == nil {
 				// First player waiting
 				waitingPlayer = request
+				if gb.botWait > 0 {
+					botTimer = time.After(gb.botWait)
+				}
 				log.Printf("Player %s waiting for match", request.Player.ID)
 			} else {
 				// Second player arrived, create game
 				gb.createGame(waitingPlayer, request)
 				waitingPlayer = nil
+				botTimer = nil
+			}
+			if waitingPlayer != nil {
+				gb.waiting.Store(1)
+			} else {
+				gb.waiting.Store(0)
 			}
 
+		case <-botTimer:
+			botTimer = nil
+			if waitingPlayer == nil || waitingPlayer.ctx.Err() != nil {
+				continue
+			}
+			gb.createBotGame(waitingPlayer)
+			waitingPlayer = nil
+			gb.waiting.Store(0)
+
 		case <-gb.ctx.Done():
 			// Send cancellation to waiting player
 			if waitingPlayer != nil {
 				waitingPlayer.Response <- &MatchmakingResponse{
-					Error: fmt.Errorf("matchmaking cancelled"),
+					Error: ErrMatchmakingCanceled,
 					Game:  nil,
 				}
 			}
@@ -173,8 +263,48 @@ func (gb *GameBroker) matchmakingWorker() {
 	}
 }
 
-// createGame creates a new game between two players
-func (gb *GameBroker) createGame(player1Req, player2Req *MatchmakingRequest) {
+// createBotGame creates a game between a player and a bot
+func (gb *GameBroker) createBotGame(playerReq *MatchmakingRequest) {
+	bot := NewBot(playerReq.difficulty)
+	botReq := &MatchmakingRequest{
+		Player:   bot.Player,
+		Response: make(chan *MatchmakingResponse, 1),
+		ctx:      gb.ctx,
+	}
+	if session := gb.createGame(playerReq, botReq); session != nil {
+		go gb.runBot(session, bot)
+	}
+}
+
+// runBot plays the moves of a bot until its game ends
+func (gb *GameBroker) runBot(session *GameSession, bot *Bot) {
+	game := session.Game
+	for {
+		view := game.View()
+		if view.State != GameStateInProgress {
+			return
+		}
+		if view.CurrentPlayerID == bot.Player.ID {
+			if err := bot.Play(game); err != nil {
+				log.Printf("Bot %s failed to move in game %s: %v", bot.Player.ID, game.ID, err)
+				return
+			}
+			if gb.onBotMove != nil {
+				gb.onBotMove(game)
+			}
+			continue
+		}
+		select {
+		case <-game.Changes():
+		case <-session.Context.Done():
+			return
+		}
+	}
+}
+
+// createGame creates a new game between two players, the session is nil when
+// it failed
+func (gb *GameBroker) createGame(player1Req, player2Req *MatchmakingRequest) *GameSession {
 	// Check if we can create a new game (concurrency limit)
 	select {
 	case gb.gameSemaphore <- struct{}{}:
@@ -182,38 +312,38 @@ func (gb *GameBroker) createGame(player1Req, player2Req *MatchmakingRequest) {
 	default:
 		// No slots available
 		player1Req.Response <- &MatchmakingResponse{
-			Error: fmt.Errorf("server at capacity"),
+			Error: ErrAtCapacity,
 			Game:  nil,
 		}
 		player2Req.Response <- &MatchmakingResponse{
-			Error: fmt.Errorf("server at capacity"),
+			Error: ErrAtCapacity,
 			Game:  nil,
 		}
-		return
+		return nil
 	}
 
 	// Create game
-	gameID := fmt.Sprintf("game_%d", time.Now().UnixNano())
+	gameID := uuid.NewString()
 	game := NewGame(gameID)
 
 	// Add players to game
 	if err := game.AddPlayer(player1Req.Player); err != nil {
 		gb.respondWithError(player1Req, player2Req, err)
 		<-gb.gameSemaphore // Release slot
-		return
+		return nil
 	}
 
 	if err := game.AddPlayer(player2Req.Player); err != nil {
 		gb.respondWithError(player1Req, player2Req, err)
 		<-gb.gameSemaphore // Release slot
-		return
+		return nil
 	}
 
 	// Start game
 	if err := game.StartGame(); err != nil {
 		gb.respondWithError(player1Req, player2Req, err)
 		<-gb.gameSemaphore // Release slot
-		return
+		return nil
 	}
 
 	// Create game session
@@ -243,6 +373,7 @@ func (gb *GameBroker) createGame(player1Req, player2Req *MatchmakingRequest) {
 
 	log.Printf("Created game %s between %s and %s",
 		gameID, player1Req.Player.ID, player2Req.Player.ID)
+	return session
 }
 
 // manageGameSession handles a single game's lifecycle
@@ -268,15 +399,15 @@ func (gb *GameBroker) manageGameSession(session *GameSession) {
 		select {
 		case <-ticker.C:
 			// Check if game is finished
-			if session.Game.State == GameStateFinished {
+			if view := session.Game.View(); view.State == GameStateFinished {
 				log.Printf("Game %s finished, winner: %s",
-					session.Game.ID, session.Game.Winner.ID)
+					session.Game.ID, view.WinnerID)
 				return
 			}
 
 		case <-session.Context.Done():
 			// Game timeout or cancellation
-			session.Game.State = GameStateFinished
+			session.Game.finish()
 			log.Printf("Game %s timed out or cancelled", session.Game.ID)
 			return
 		}
@@ -338,7 +469,7 @@ func (gb *GameBroker) logMetrics() {
 	activeCount := len(gb.activeGames)
 	gb.gamesMutex.RUnlock()
 
-	queueSize := len(gb.queue)
+	queueSize := gb.GetQueueSize()
 	availableSlots := len(gb.gameSemaphore)
 
 	log.Printf("Broker metrics - Active games: %d, Queue: %d, Available slots: %d",
@@ -365,9 +496,9 @@ func (gb *GameBroker) GetActiveGameCount() int {
 	return len(gb.activeGames)
 }
 
-// GetQueueSize returns the current queue size
+// GetQueueSize returns the number of players waiting for a game
 func (gb *GameBroker) GetQueueSize() int {
-	return len(gb.queue)
+	return len(gb.queue) + int(gb.waiting.Load())
 }
 
 // GetAvailableSlots returns the number of available game slots
@@ -375,6 +506,18 @@ func (gb *GameBroker) GetAvailableSlots() int {
 	return len(gb.gameSemaphore)
 }
 
+// FindPlayerGame returns the active game session of a player
+func (gb *GameBroker) FindPlayerGame(playerID string) (*GameSession, bool) {
+	gb.gamesMutex.RLock()
+	defer gb.gamesMutex.RUnlock()
+	for _, session := range gb.activeGames {
+		if session.Game.HasPlayer(playerID) {
+			return session, true
+		}
+	}
+	return nil, false
+}
+
 // GetGameSession returns a game session by ID
 func (gb *GameBroker) GetGameSession(gameID string) (*GameSession, bool) {
 	gb.gamesMutex.RLock()
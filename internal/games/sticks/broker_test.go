@@ -0,0 +1,54 @@
+package sticks
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGameBroker_RequestGame(t *testing.T) {
+	broker := NewGameBroker(10)
+	broker.Start()
+	defer broker.Stop()
+
+	games := make(chan *Game, 2)
+	for _, p := range []*Player{NewPlayer("player 1", ""), NewPlayer("player 2", "")} {
+		go func() {
+			game, err := broker.RequestGame(p)
+			if err != nil {
+				t.Errorf("GameBroker.RequestGame() error = %v", err)
+			}
+			games <- game
+		}()
+	}
+	g1, g2 := <-games, <-games
+	if g1 == nil || g1 != g2 {
+		t.Fatalf("GameBroker.RequestGame() players got different games")
+	}
+	if _, ok := broker.FindPlayerGame("player 1"); !ok {
+		t.Errorf("GameBroker.FindPlayerGame() game of player 1 not found")
+	}
+	if got := broker.GetActiveGameCount(); got != 1 {
+		t.Errorf("GameBroker.GetActiveGameCount() = %d, want 1", got)
+	}
+}
+
+func TestGameBroker_RequestGameContext(t *testing.T) {
+	broker := NewGameBroker(10)
+	broker.Start()
+	defer broker.Stop()
+
+	// the first player gives up before anyone else arrives
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := broker.RequestGameContext(ctx, NewPlayer("player 1", "")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("GameBroker.RequestGameContext() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel2()
+	if game, err := broker.RequestGameContext(ctx2, NewPlayer("player 2", "")); err == nil {
+		t.Fatalf("GameBroker.RequestGameContext() matched with a player that left, game %s", game.ID)
+	}
+}
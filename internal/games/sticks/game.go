@@ -1,11 +1,18 @@
 package sticks
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 )
 
+var (
+	ErrNotInProgress = errors.New("game is not in progress")
+	ErrNotYourTurn   = errors.New("it is not your turn")
+	ErrIllegalMove   = errors.New("illegal move")
+)
+
 // GameState represents the current state of the game
 type GameState string
 
@@ -34,8 +41,10 @@ type Game struct {
 	CurrentTurn int       `json:"currentTurn"` // 0 for player1, 1 for player2
 	State       GameState `json:"state"`
 	Winner      *Player   `json:"winner,omitempty"`
+	Moves       int       `json:"moves"`
 	CreatedAt   time.Time `json:"createdAt"`
 	mutex       *sync.RWMutex
+	changes     chan struct{}
 }
 
 // PrintScore implements GameInterface.
@@ -66,6 +75,7 @@ func NewGame(id string) *Game {
 		CurrentTurn: 0,
 		Winner:      nil,
 		mutex:       &sync.RWMutex{},
+		changes:     make(chan struct{}, 1),
 	}
 }
 
@@ -74,41 +84,43 @@ func (g *Game) EndTurn() {
 }
 
 // Attack implements GameInterface.
-// Attack performs an attack move
+// Attack performs an attack move of the current player
 func (g *Game) Attack(attackerIsLeft bool, defenderIsLeft bool) error {
-	fmt.Printf("attacking\n")
 	g.mutex.Lock()
 	defer g.mutex.Unlock()
+	return g.attack(attackerIsLeft, defenderIsLeft)
+}
 
-	fmt.Printf("locked\n")
+// AttackAs performs an attack move if it is the turn of the player
+func (g *Game) AttackAs(playerID string, attackerIsLeft bool, defenderIsLeft bool) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if err := g.checkTurn(playerID); err != nil {
+		return err
+	}
+	return g.attack(attackerIsLeft, defenderIsLeft)
+}
 
+func (g *Game) attack(attackerIsLeft bool, defenderIsLeft bool) error {
 	if g.State != GameStateInProgress {
-		fmt.Printf("game is not in progress\n")
-		return fmt.Errorf("game is not in progress")
+		return ErrNotInProgress
 	}
 
-	fmt.Printf("getting players\n")
-
 	// Get players directly without calling methods that acquire locks
-	var attacker, defender *Player
-	if g.CurrentTurn == 0 {
-		attacker = g.Player1
-		defender = g.Player2
-	} else {
-		attacker = g.Player2
-		defender = g.Player1
-	}
-
-	fmt.Printf("got players\n")
+	attacker, defender := g.players()
 
 	attackerHand := attacker.GetHand(attackerIsLeft)
 	defenderHand := defender.GetHand(defenderIsLeft)
+	if !attackerHand.Alive() || attackerHand.fingers == 0 {
+		return fmt.Errorf("%w: attacking hand has no fingers", ErrIllegalMove)
+	}
 
 	// Perform attack
 	err := attackerHand.Attack(defenderHand)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %w", ErrIllegalMove, err)
 	}
+	g.Moves++
 
 	// Check if game is over
 	if !defender.Alive() {
@@ -117,41 +129,118 @@ func (g *Game) Attack(attackerIsLeft bool, defenderIsLeft bool) error {
 	} else {
 		// Switch turns
 		g.EndTurn()
+		g.checkStuck()
 	}
+	g.changed()
 
 	return nil
 }
 
-// Split performs a split move
-func (g *Game) Split(fromLeft bool, newLeftPoints int) error {
+// Split moves points from one hand of the current player to the other
+func (g *Game) Split(fromLeft bool, points int) error {
 	g.mutex.Lock()
 	defer g.mutex.Unlock()
+	return g.split(fromLeft, points)
+}
+
+// SplitAs performs a split move if it is the turn of the player
+func (g *Game) SplitAs(playerID string, fromLeft bool, points int) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if err := g.checkTurn(playerID); err != nil {
+		return err
+	}
+	return g.split(fromLeft, points)
+}
 
+func (g *Game) split(fromLeft bool, points int) error {
 	if g.State != GameStateInProgress {
-		return fmt.Errorf("game is not in progress")
+		return ErrNotInProgress
 	}
 
 	// Get current player directly without calling methods that acquire locks
-	var player *Player
-	if g.CurrentTurn == 0 {
-		player = g.Player1
-	} else {
-		player = g.Player2
-	}
+	player, _ := g.players()
 
 	from := player.GetHand(fromLeft)
 	other := player.GetHand(!fromLeft)
-	err := other.Take(from, newLeftPoints)
+	if points <= 0 {
+		return fmt.Errorf("%w: split at least one point", ErrIllegalMove)
+	}
+	if !from.Alive() || !other.Alive() || other.fingers+points >= 5 {
+		return fmt.Errorf("%w: both hands must stay alive", ErrIllegalMove)
+	}
+	err := other.Take(from, points)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %w", ErrIllegalMove, err)
 	}
+	g.Moves++
 
 	// Switch turns
 	g.EndTurn()
+	g.checkStuck()
+	g.changed()
 
 	return nil
 }
 
+// checkStuck finishes the game when the player to move has no legal move,
+// they lose like a player whose hands are both out.
+func (g *Game) checkStuck() {
+	current, opponent := g.players()
+	if len(NewState(current, opponent).Moves()) == 0 {
+		g.State = GameStateFinished
+		g.Winner = opponent
+	}
+}
+
+// changed wakes up whoever waits on Changes.
+func (g *Game) changed() {
+	select {
+	case g.changes <- struct{}{}:
+	default:
+	}
+}
+
+// Changes receives after moves, changes made while nobody receives are
+// merged. It is meant for a single receiver such as a bot.
+func (g *Game) Changes() <-chan struct{} {
+	return g.changes
+}
+
+// players returns the current player and their opponent, the caller holds
+// the lock
+func (g *Game) players() (current *Player, opponent *Player) {
+	if g.CurrentTurn == 0 {
+		return g.Player1, g.Player2
+	}
+	return g.Player2, g.Player1
+}
+
+func (g *Game) checkTurn(playerID string) error {
+	if g.State != GameStateInProgress {
+		return ErrNotInProgress
+	}
+	if current, _ := g.players(); current.ID != playerID {
+		return ErrNotYourTurn
+	}
+	return nil
+}
+
+// finish ends the game without a winner
+func (g *Game) finish() {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.State = GameStateFinished
+}
+
+// HasPlayer reports whether the player takes part in the game
+func (g *Game) HasPlayer(playerID string) bool {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	return (g.Player1 != nil && g.Player1.ID == playerID) ||
+		(g.Player2 != nil && g.Player2.ID == playerID)
+}
+
 // StartGame implements GameInterface.
 func (g *Game) StartGame() error {
 	g.mutex.Lock()
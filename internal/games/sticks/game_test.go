@@ -116,3 +116,59 @@ func TestGame_Attack(t *testing.T) {
 	// game.
 	game.PrintScore()
 }
+
+func newStartedGame(t *testing.T) *Game {
+	t.Helper()
+	game := NewGame("game")
+	if err := errors.Join(game.AddPlayer(NewPlayer("player 1", "")), game.AddPlayer(NewPlayer("player 2", ""))); err != nil {
+		t.Fatalf("Game.AddPlayer() error = %v", err)
+	}
+	if err := game.StartGame(); err != nil {
+		t.Fatalf("Game.StartGame() error = %v", err)
+	}
+	return game
+}
+
+func TestGame_AttackAs(t *testing.T) {
+	game := newStartedGame(t)
+
+	if err := game.AttackAs("player 2", true, true); !errors.Is(err, ErrNotYourTurn) {
+		t.Errorf("Game.AttackAs() error = %v, want %v", err, ErrNotYourTurn)
+	}
+	if err := game.AttackAs("player 1", true, true); err != nil {
+		t.Errorf("Game.AttackAs() error = %v", err)
+	}
+	if err := game.AttackAs("player 1", true, true); !errors.Is(err, ErrNotYourTurn) {
+		t.Errorf("Game.AttackAs() error = %v, want %v", err, ErrNotYourTurn)
+	}
+
+	view := game.View()
+	if view.CurrentPlayerID != "player 2" || view.Moves != 1 {
+		t.Errorf("Game.View() = %+v, want turn of player 2 after 1 move", view)
+	}
+	if view.Players[1].LeftHand != 2 {
+		t.Errorf("Game.View() left hand of player 2 = %d, want 2", view.Players[1].LeftHand)
+	}
+}
+
+func TestGame_SplitAs(t *testing.T) {
+	tests := []struct {
+		name     string
+		fromLeft bool
+		points   int
+		wantErr  error
+	}{
+		{name: "move one point", fromLeft: true, points: 1},
+		{name: "move nothing", fromLeft: true, points: 0, wantErr: ErrIllegalMove},
+		{name: "move more than the hand has", fromLeft: false, points: 2, wantErr: ErrIllegalMove},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := newStartedGame(t)
+			err := game.SplitAs("player 1", tt.fromLeft, tt.points)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Game.SplitAs() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package sticks
+
+import "sync"
+
+// MoveKind is the kind of a move.
+type MoveKind string
+
+const (
+	MoveAttack MoveKind = "attack"
+	MoveSplit  MoveKind = "split"
+)
+
+// Move is a move of the player whose turn it is. Attacks use AttackWithLeft
+// and AttackLeft, splits FromLeft and Points.
+type Move struct {
+	Kind           MoveKind `json:"kind"`
+	AttackWithLeft bool     `json:"attack_with_left,omitempty"`
+	AttackLeft     bool     `json:"attack_left,omitempty"`
+	FromLeft       bool     `json:"from_left,omitempty"`
+	Points         int      `json:"points,omitempty"`
+}
+
+// State is a position seen by the player to move. Hands[0] are the hands of
+// that player, Hands[1] of the opponent, each left then right. A hand with 5
+// fingers is out.
+type State struct {
+	Hands [2][2]int
+}
+
+// NewState returns the state of a game with current to move.
+func NewState(current, opponent *Player) State {
+	return State{Hands: [2][2]int{
+		{current.LeftHand.Fingers(), current.RightHand.Fingers()},
+		{opponent.LeftHand.Fingers(), opponent.RightHand.Fingers()},
+	}}
+}
+
+func hand(left bool) int {
+	if left {
+		return 0
+	}
+	return 1
+}
+
+// Moves returns the legal moves of the player to move, a player without
+// moves has lost. The rules are the ones Game enforces.
+func (s State) Moves() []Move {
+	var moves []Move
+	own, opp := s.Hands[0], s.Hands[1]
+	for _, withLeft := range []bool{true, false} {
+		fingers := own[hand(withLeft)]
+		if fingers == 0 || fingers >= 5 {
+			continue
+		}
+		for _, left := range []bool{true, false} {
+			if opp[hand(left)] < 5 {
+				moves = append(moves, Move{Kind: MoveAttack, AttackWithLeft: withLeft, AttackLeft: left})
+			}
+		}
+	}
+	for _, fromLeft := range []bool{true, false} {
+		from, to := own[hand(fromLeft)], own[hand(!fromLeft)]
+		if from >= 5 || to >= 5 {
+			continue
+		}
+		for points := 1; points <= from && to+points < 5; points++ {
+			moves = append(moves, Move{Kind: MoveSplit, FromLeft: fromLeft, Points: points})
+		}
+	}
+	return moves
+}
+
+// Apply returns the state after a legal move, seen by the opponent who moves
+// next.
+func (s State) Apply(m Move) State {
+	own, opp := s.Hands[0], s.Hands[1]
+	switch m.Kind {
+	case MoveAttack:
+		opp[hand(m.AttackLeft)] = min(opp[hand(m.AttackLeft)]+own[hand(m.AttackWithLeft)], 5)
+	case MoveSplit:
+		own[hand(m.FromLeft)] -= m.Points
+		own[hand(!m.FromLeft)] += m.Points
+	}
+	return State{Hands: [2][2]int{opp, own}}
+}
+
+func (s State) index() int {
+	return ((s.Hands[0][0]*6+s.Hands[0][1])*6+s.Hands[1][0])*6 + s.Hands[1][1]
+}
+
+const stateCount = 6 * 6 * 6 * 6
+
+func stateAt(i int) State {
+	return State{Hands: [2][2]int{
+		{i / 216, i / 36 % 6},
+		{i / 6 % 6, i % 6},
+	}}
+}
+
+// Outcome is the result of perfect play for the player to move.
+type Outcome int8
+
+const (
+	OutcomeDraw Outcome = iota
+	OutcomeWin
+	OutcomeLoss
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeWin:
+		return "win"
+	case OutcomeLoss:
+		return "loss"
+	}
+	return "draw"
+}
+
+// Solution holds the outcome of every state under perfect play. Games can
+// cycle forever, states that neither side can force are draws.
+type Solution struct {
+	outcomes [stateCount]Outcome
+	// depths is the retrograde pass a win or loss was found in. Every winning
+	// move leads to a loss of a lower depth, so following them ends the game.
+	depths [stateCount]int
+}
+
+var (
+	solution     *Solution
+	solutionOnce sync.Once
+)
+
+// Solve returns the solution of sticks, it is computed once.
+func Solve() *Solution {
+	solutionOnce.Do(func() {
+		solution = solve()
+	})
+	return solution
+}
+
+// solve runs a retrograde analysis: states without moves are lost, a state
+// is won if a move leads to a lost state and lost if every move leads to a
+// won state. Each pass only uses what earlier passes found.
+func solve() *Solution {
+	s := &Solution{}
+	known := [stateCount]bool{}
+	for i := range stateCount {
+		if len(stateAt(i).Moves()) == 0 {
+			s.outcomes[i], known[i] = OutcomeLoss, true
+		}
+	}
+	for pass := 1; ; pass++ {
+		prevKnown, prev := known, s.outcomes
+		changed := false
+		for i := range stateCount {
+			if prevKnown[i] {
+				continue
+			}
+			allWon := true
+			for _, m := range stateAt(i).Moves() {
+				next := stateAt(i).Apply(m).index()
+				if prevKnown[next] && prev[next] == OutcomeLoss {
+					s.outcomes[i], s.depths[i], known[i] = OutcomeWin, pass, true
+					break
+				}
+				if !prevKnown[next] || prev[next] != OutcomeWin {
+					allWon = false
+				}
+			}
+			if !known[i] && allWon {
+				s.outcomes[i], s.depths[i], known[i] = OutcomeLoss, pass, true
+			}
+			changed = changed || known[i]
+		}
+		if !changed {
+			return s
+		}
+	}
+}
+
+// Outcome returns the outcome of the state for the player to move and the
+// depth it was found at.
+func (s *Solution) Outcome(state State) (Outcome, int) {
+	i := state.index()
+	return s.outcomes[i], s.depths[i]
+}
+
+// BestMove returns a move of perfect play: the fastest win, a move that
+// keeps a draw or the slowest loss. It is false without legal moves.
+func (s *Solution) BestMove(state State) (Move, bool) {
+	var (
+		best      Move
+		bestScore int
+		found     bool
+	)
+	for _, m := range state.Moves() {
+		outcome, depth := s.Outcome(state.Apply(m))
+		// scores are from the view of the player to move, higher is better
+		var score int
+		switch outcome {
+		case OutcomeLoss:
+			score = 2*stateCount - depth
+		case OutcomeDraw:
+			score = 0
+		case OutcomeWin:
+			score = -2*stateCount + depth
+		}
+		if !found || score > bestScore {
+			best, bestScore, found = m, score, true
+		}
+	}
+	return best, found
+}
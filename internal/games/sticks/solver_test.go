@@ -0,0 +1,109 @@
+package sticks
+
+import (
+	"testing"
+)
+
+func TestState_Moves(t *testing.T) {
+	tests := []struct {
+		name  string
+		state State
+		want  int
+	}{
+		{name: "start", state: State{Hands: [2][2]int{{1, 1}, {1, 1}}}, want: 6},
+		{name: "no fingers to attack or split with", state: State{Hands: [2][2]int{{0, 5}, {1, 1}}}, want: 0},
+		{name: "both hands out", state: State{Hands: [2][2]int{{5, 5}, {1, 1}}}, want: 0},
+		{name: "only the live hand of the opponent", state: State{Hands: [2][2]int{{2, 5}, {5, 3}}}, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := len(tt.state.Moves()); got != tt.want {
+				t.Errorf("State.Moves() = %d moves, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSolve_StartIsADraw(t *testing.T) {
+	outcome, _ := Solve().Outcome(State{Hands: [2][2]int{{1, 1}, {1, 1}}})
+	if outcome != OutcomeDraw {
+		t.Errorf("Solution.Outcome() of the start = %s, want %s", outcome, OutcomeDraw)
+	}
+}
+
+// TestSolve_PerfectPlayWinsEveryWonPosition plays the best move from every
+// won state against every reply of the opponent until the opponent is out of
+// moves.
+func TestSolve_PerfectPlayWinsEveryWonPosition(t *testing.T) {
+	solution := Solve()
+	won := map[State]bool{}
+	var wins func(state State) bool
+	wins = func(state State) bool {
+		if done, ok := won[state]; ok {
+			return done
+		}
+		outcome, depth := solution.Outcome(state)
+		if outcome != OutcomeWin {
+			t.Errorf("state %v is %s, want %s", state.Hands, outcome, OutcomeWin)
+			return false
+		}
+		move, ok := solution.BestMove(state)
+		if !ok {
+			t.Errorf("no best move in won state %v", state.Hands)
+			return false
+		}
+		next := state.Apply(move)
+		for _, reply := range next.Moves() {
+			after := next.Apply(reply)
+			// every reply leaves a win that is closer, so this ends
+			if _, afterDepth := solution.Outcome(after); afterDepth >= depth {
+				t.Errorf("win of %v does not get closer after %v", state.Hands, reply)
+				return false
+			}
+			if !wins(after) {
+				return false
+			}
+		}
+		won[state] = true
+		return true
+	}
+
+	count := 0
+	for i := range stateCount {
+		state := stateAt(i)
+		if outcome, _ := solution.Outcome(state); outcome == OutcomeWin {
+			count++
+			if !wins(state) {
+				t.Fatalf("perfect play lost the won state %v", state.Hands)
+			}
+		}
+	}
+	if count == 0 {
+		t.Fatal("no won states")
+	}
+}
+
+// TestSolve_PerfectPlayKeepsDraws checks that the best move of a drawn state
+// never gives the opponent a win.
+func TestSolve_PerfectPlayKeepsDraws(t *testing.T) {
+	solution := Solve()
+	for i := range stateCount {
+		state := stateAt(i)
+		if outcome, _ := solution.Outcome(state); outcome != OutcomeDraw {
+			continue
+		}
+		move, ok := solution.BestMove(state)
+		if !ok {
+			t.Fatalf("no best move in drawn state %v", state.Hands)
+		}
+		next := state.Apply(move)
+		if outcome, _ := solution.Outcome(next); outcome != OutcomeDraw {
+			t.Fatalf("best move of drawn state %v leaves the opponent a %s", state.Hands, outcome)
+		}
+		for _, reply := range next.Moves() {
+			if outcome, _ := solution.Outcome(next.Apply(reply)); outcome == OutcomeLoss {
+				t.Fatalf("reply %v to drawn state %v wins for the opponent", reply, state.Hands)
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package sticks
+
+import "time"
+
+// PlayerView is the state of a player sent to clients. A hand with 5
+// fingers is out.
+type PlayerView struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	LeftHand  int    `json:"left_hand"`
+	RightHand int    `json:"right_hand"`
+	Bot       bool   `json:"bot"`
+}
+
+// GameView is a snapshot of a game that is safe to share while the game goes
+// on.
+type GameView struct {
+	ID              string       `json:"id"`
+	State           GameState    `json:"state"`
+	Players         []PlayerView `json:"players"`
+	CurrentPlayerID string       `json:"current_player_id,omitempty"`
+	WinnerID        string       `json:"winner_id,omitempty"`
+	Moves           int          `json:"moves"`
+	CreatedAt       time.Time    `json:"created_at"`
+}
+
+// Fingers returns the fingers of the hand, capped at 5 once it is out.
+func (h *Hand) Fingers() int {
+	return min(h.fingers, 5)
+}
+
+func newPlayerView(p *Player) PlayerView {
+	return PlayerView{
+		ID:        p.ID,
+		Name:      p.Name,
+		LeftHand:  p.LeftHand.Fingers(),
+		RightHand: p.RightHand.Fingers(),
+		Bot:       IsBot(p.ID),
+	}
+}
+
+// View returns a snapshot of the game.
+func (g *Game) View() *GameView {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	view := &GameView{
+		ID:        g.ID,
+		State:     g.State,
+		Moves:     g.Moves,
+		CreatedAt: g.CreatedAt,
+	}
+	for _, p := range []*Player{g.Player1, g.Player2} {
+		if p != nil {
+			view.Players = append(view.Players, newPlayerView(p))
+		}
+	}
+	if g.State == GameStateInProgress {
+		current, _ := g.players()
+		view.CurrentPlayerID = current.ID
+	}
+	if g.Winner != nil {
+		view.WinnerID = g.Winner.ID
+	}
+	return view
+}
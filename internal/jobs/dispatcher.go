@@ -6,10 +6,24 @@ import (
 	"fmt"
 	"log/slog"
 	"runtime/debug"
+	"time"
 
 	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/tools/hook"
 )
 
+// JobEvent is passed to the dispatcher lifecycle hooks.
+type JobEvent struct {
+	hook.Event
+	Context context.Context
+	Job     *models.JobRow
+	// StartedAt is the time the dispatcher received the job.
+	StartedAt time.Time
+	// Duration and Error are only set for OnSuccess and OnFailure.
+	Duration time.Duration
+	Error    error
+}
+
 // Dispatcher routes jobs to their appropriate handlers based on job kind.
 type Dispatcher interface {
 	// Dispatch executes the job with the appropriate handler.
@@ -18,10 +32,22 @@ type Dispatcher interface {
 	// SetHandler registers a handler for a specific job kind.
 	// Panics if a handler is already registered for the kind.
 	SetHandler(kind string, handler func(context.Context, *models.JobRow) error)
+
+	// OnStart is triggered before a job is handed to its handler.
+	OnStart() *hook.Hook[*JobEvent]
+
+	// OnSuccess is triggered after a handler completed without error.
+	OnSuccess() *hook.Hook[*JobEvent]
+
+	// OnFailure is triggered after a handler returned an error or panicked.
+	OnFailure() *hook.Hook[*JobEvent]
 }
 
 type dispatcher struct {
-	handlers map[string]func(context.Context, *models.JobRow) error
+	handlers  map[string]func(context.Context, *models.JobRow) error
+	onStart   *hook.Hook[*JobEvent]
+	onSuccess *hook.Hook[*JobEvent]
+	onFailure *hook.Hook[*JobEvent]
 }
 
 var _ Dispatcher = (*dispatcher)(nil)
@@ -36,10 +62,28 @@ func (d *dispatcher) SetHandler(kind string, handler func(context.Context, *mode
 
 func NewDispatcher() Dispatcher {
 	return &dispatcher{
-		handlers: make(map[string]func(context.Context, *models.JobRow) error),
+		handlers:  make(map[string]func(context.Context, *models.JobRow) error),
+		onStart:   &hook.Hook[*JobEvent]{},
+		onSuccess: &hook.Hook[*JobEvent]{},
+		onFailure: &hook.Hook[*JobEvent]{},
 	}
 }
 
+// OnStart implements Dispatcher.
+func (d *dispatcher) OnStart() *hook.Hook[*JobEvent] {
+	return d.onStart
+}
+
+// OnSuccess implements Dispatcher.
+func (d *dispatcher) OnSuccess() *hook.Hook[*JobEvent] {
+	return d.onSuccess
+}
+
+// OnFailure implements Dispatcher.
+func (d *dispatcher) OnFailure() *hook.Hook[*JobEvent] {
+	return d.onFailure
+}
+
 func RegisterWorker[T JobArgs](d Dispatcher, worker Worker[T]) {
 	var zero T
 	kind := zero.Kind()
@@ -56,6 +100,41 @@ func RegisterWorker[T JobArgs](d Dispatcher, worker Worker[T]) {
 }
 
 func (d *dispatcher) Dispatch(ctx context.Context, row *models.JobRow) error {
+	startedAt := time.Now()
+	d.trigger(d.onStart, &JobEvent{Context: ctx, Job: row, StartedAt: startedAt})
+
+	err := d.dispatch(ctx, row)
+
+	event := &JobEvent{
+		Context:   ctx,
+		Job:       row,
+		StartedAt: startedAt,
+		Duration:  time.Since(startedAt),
+		Error:     err,
+	}
+	if err != nil {
+		d.trigger(d.onFailure, event)
+	} else {
+		d.trigger(d.onSuccess, event)
+	}
+	return err
+}
+
+// trigger runs the hook handlers. Hook errors are logged and never change the
+// outcome of the job.
+func (d *dispatcher) trigger(h *hook.Hook[*JobEvent], event *JobEvent) {
+	if err := h.Trigger(event); err != nil {
+		slog.ErrorContext(
+			event.Context,
+			"job hook error",
+			slog.String("kind", event.Job.Kind),
+			slog.String("job_id", event.Job.ID.String()),
+			slog.Any("error", err),
+		)
+	}
+}
+
+func (d *dispatcher) dispatch(ctx context.Context, row *models.JobRow) error {
 	handler, ok := d.handlers[row.Kind]
 	if !ok {
 		slog.Error(
@@ -100,6 +179,21 @@ type DispatchDecorator struct {
 	DispatchFunc   func(ctx context.Context, row *models.JobRow) error
 }
 
+// OnStart implements Dispatcher.
+func (d *DispatchDecorator) OnStart() *hook.Hook[*JobEvent] {
+	return d.Delegate.OnStart()
+}
+
+// OnSuccess implements Dispatcher.
+func (d *DispatchDecorator) OnSuccess() *hook.Hook[*JobEvent] {
+	return d.Delegate.OnSuccess()
+}
+
+// OnFailure implements Dispatcher.
+func (d *DispatchDecorator) OnFailure() *hook.Hook[*JobEvent] {
+	return d.Delegate.OnFailure()
+}
+
 func (d *DispatchDecorator) Dispatch(ctx context.Context, row *models.JobRow) error {
 	if d.DispatchFunc != nil {
 		return d.DispatchFunc(ctx, row)
@@ -0,0 +1,95 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/tkahng/playground/internal/models"
+)
+
+func TestDispatcher_Hooks(t *testing.T) {
+	tests := []struct {
+		name        string
+		workErr     error
+		wantSuccess int
+		wantFailure int
+	}{
+		{
+			name:        "success triggers OnStart and OnSuccess",
+			workErr:     nil,
+			wantSuccess: 1,
+			wantFailure: 0,
+		},
+		{
+			name:        "failure triggers OnStart and OnFailure",
+			workErr:     errors.New("boom"),
+			wantSuccess: 0,
+			wantFailure: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDispatcher()
+			worker := &EmailWorker{
+				WorkFunc: func(ctx context.Context, job *Job[EmailJobArgs]) error {
+					return tt.workErr
+				},
+			}
+			RegisterWorker(d, worker)
+
+			var started, succeeded, failed int
+			var failedErr error
+			d.OnStart().BindFunc(func(e *JobEvent) error {
+				started++
+				return e.Next()
+			})
+			d.OnSuccess().BindFunc(func(e *JobEvent) error {
+				succeeded++
+				return e.Next()
+			})
+			d.OnFailure().BindFunc(func(e *JobEvent) error {
+				failed++
+				failedErr = e.Error
+				return e.Next()
+			})
+
+			err := d.Dispatch(context.Background(), &models.JobRow{
+				ID:      uuid.New(),
+				Kind:    EmailJobArgs{}.Kind(),
+				Payload: []byte(`{"recipient":"a@example.com"}`),
+			})
+			assert.ErrorIs(t, err, tt.workErr)
+			assert.Equal(t, 1, started)
+			assert.Equal(t, tt.wantSuccess, succeeded)
+			assert.Equal(t, tt.wantFailure, failed)
+			assert.ErrorIs(t, failedErr, tt.workErr)
+		})
+	}
+}
+
+func TestDispatcher_HookErrorDoesNotFailJob(t *testing.T) {
+	d := NewDispatcher()
+	RegisterWorker(d, &EmailWorker{
+		WorkFunc: func(ctx context.Context, job *Job[EmailJobArgs]) error {
+			return nil
+		},
+	})
+	d.OnStart().BindFunc(func(e *JobEvent) error {
+		return errors.New("metrics backend down")
+	})
+
+	err := d.Dispatch(context.Background(), &models.JobRow{
+		ID:      uuid.New(),
+		Kind:    EmailJobArgs{}.Kind(),
+		Payload: []byte(`{}`),
+	})
+	assert.NoError(t, err)
+}
+
+func TestRecordOutput_WithoutRecorder(t *testing.T) {
+	assert.ErrorIs(t, RecordOutput(context.Background(), map[string]int{"count": 1}), ErrNoJobRecorder)
+	assert.ErrorIs(t, RecordProgress(context.Background(), 50), ErrNoJobRecorder)
+}
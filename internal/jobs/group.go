@@ -0,0 +1,117 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrParentJobFailed is recorded as the last error of the jobs that were
+	// cancelled because one of their parents failed or was cancelled.
+	ErrParentJobFailed = errors.New("parent job did not succeed")
+
+	// ErrGroupUniqueKey is returned when a job of a group has a unique key.
+	// Deduplication would merge the job into an existing row and leave its
+	// dependants waiting on an id that was never inserted.
+	ErrGroupUniqueKey = errors.New("jobs in a group cannot have a unique key")
+)
+
+// Group is a set of jobs enqueued together whose execution order is given by
+// parent jobs. A job is only claimed once all of its parents are done, and it
+// is cancelled when one of them fails. A group can have a completion callback
+// that runs once every other job of the group finished, whatever its status.
+//
+//	g := jobs.NewGroup()
+//	tasks := g.Chain(
+//		&jobs.EnqueueParams{Args: importArgs, MaxAttempts: 3},
+//		&jobs.EnqueueParams{Args: createTasksArgs, MaxAttempts: 3},
+//	)
+//	g.Add(&jobs.EnqueueParams{Args: notifyArgs, MaxAttempts: 3}, tasks)
+//	g.Add(&jobs.EnqueueParams{Args: quantityArgs, MaxAttempts: 3}, tasks)
+//	g.OnComplete(&jobs.EnqueueParams{Args: reportArgs, MaxAttempts: 3})
+//	err := g.Enqueue(ctx, manager)
+type Group struct {
+	ID       uuid.UUID
+	jobs     []*EnqueueParams
+	callback *EnqueueParams
+}
+
+func NewGroup() *Group {
+	return &Group{
+		ID: uuid.Must(uuid.NewV7()),
+	}
+}
+
+// Add adds a job to the group that runs once all parents are done. The
+// parents must be jobs returned by Add or Chain on the same group. The job is
+// returned so it can be used as the parent of other jobs.
+func (g *Group) Add(params *EnqueueParams, parents ...*EnqueueParams) *EnqueueParams {
+	if params.ID == uuid.Nil {
+		params.ID = uuid.Must(uuid.NewV7())
+	}
+	params.GroupID = &g.ID
+	for _, parent := range parents {
+		params.ParentIDs = append(params.ParentIDs, parent.ID)
+	}
+	g.jobs = append(g.jobs, params)
+	return params
+}
+
+// Chain adds jobs that run one after the other and returns the last one.
+func (g *Group) Chain(params ...*EnqueueParams) *EnqueueParams {
+	var last *EnqueueParams
+	for _, p := range params {
+		if last == nil {
+			last = g.Add(p)
+		} else {
+			last = g.Add(p, last)
+		}
+	}
+	return last
+}
+
+// OnComplete sets the callback job of the group. It is enqueued last, with
+// every other job of the group as parent.
+func (g *Group) OnComplete(params *EnqueueParams) {
+	g.callback = params
+}
+
+// Jobs returns the jobs of the group in the order they were added, followed
+// by the completion callback.
+func (g *Group) Jobs() ([]*EnqueueParams, error) {
+	jobs := make([]*EnqueueParams, 0, len(g.jobs)+1)
+	for _, job := range g.jobs {
+		if job.UniqueKey != nil {
+			return nil, ErrGroupUniqueKey
+		}
+		jobs = append(jobs, job)
+	}
+	if g.callback != nil {
+		if g.callback.UniqueKey != nil {
+			return nil, ErrGroupUniqueKey
+		}
+		callback := g.callback
+		if callback.ID == uuid.Nil {
+			callback.ID = uuid.Must(uuid.NewV7())
+		}
+		callback.GroupID = &g.ID
+		callback.ParentIDs = make([]uuid.UUID, 0, len(g.jobs))
+		for _, job := range g.jobs {
+			callback.ParentIDs = append(callback.ParentIDs, job.ID)
+		}
+		callback.RunOnParentFailure = true
+		jobs = append(jobs, callback)
+	}
+	return jobs, nil
+}
+
+// Enqueue enqueues every job of the group through e.
+func (g *Group) Enqueue(ctx context.Context, e Enqueuer) error {
+	jobs, err := g.Jobs()
+	if err != nil {
+		return err
+	}
+	return e.EnqueueMany(ctx, jobs...)
+}
@@ -0,0 +1,126 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/tools/types"
+)
+
+func emailJob(recipient string) *EnqueueParams {
+	return &EnqueueParams{
+		Args:        EmailJobArgs{Recipient: recipient},
+		RunAfter:    time.Now(),
+		MaxAttempts: 1,
+	}
+}
+
+func TestGroup_Jobs(t *testing.T) {
+	g := NewGroup()
+	first := g.Chain(emailJob("a"), emailJob("b"))
+	fanOut := g.Add(emailJob("c"), first)
+	g.OnComplete(emailJob("done"))
+
+	jobs, err := g.Jobs()
+	require.NoError(t, err)
+	require.Len(t, jobs, 4)
+	assert.Empty(t, jobs[0].ParentIDs)
+	assert.Equal(t, []uuid.UUID{jobs[0].ID}, jobs[1].ParentIDs)
+	assert.Equal(t, []uuid.UUID{first.ID}, fanOut.ParentIDs)
+
+	callback := jobs[3]
+	assert.True(t, callback.RunOnParentFailure)
+	assert.Equal(t, []uuid.UUID{jobs[0].ID, jobs[1].ID, jobs[2].ID}, callback.ParentIDs)
+	for _, job := range jobs {
+		assert.Equal(t, g.ID, *job.GroupID)
+	}
+
+	again, err := g.Jobs()
+	require.NoError(t, err)
+	assert.Len(t, again[3].ParentIDs, 3, "callback parents are not duplicated")
+
+	g.Add(&EnqueueParams{Args: EmailJobArgs{}, UniqueKey: types.Pointer("key")})
+	_, err = g.Jobs()
+	assert.ErrorIs(t, err, ErrGroupUniqueKey)
+}
+
+func TestMemoryJobManager_Group(t *testing.T) {
+	tests := []struct {
+		name     string
+		failing  string
+		wantRun  []string
+		wantJobs map[string]models.JobStatus
+	}{
+		{
+			name:    "jobs run in dependency order and callback runs last",
+			wantRun: []string{"import", "tasks", "notify", "quantity", "report"},
+			wantJobs: map[string]models.JobStatus{
+				"import":   models.JobStatusDone,
+				"tasks":    models.JobStatusDone,
+				"notify":   models.JobStatusDone,
+				"quantity": models.JobStatusDone,
+				"report":   models.JobStatusDone,
+			},
+		},
+		{
+			name:    "failed parent cancels dependants but not the callback",
+			failing: "tasks",
+			wantRun: []string{"import", "tasks", "report"},
+			wantJobs: map[string]models.JobStatus{
+				"import":   models.JobStatusDone,
+				"tasks":    models.JobStatusFailed,
+				"notify":   models.JobStatusCancelled,
+				"quantity": models.JobStatusCancelled,
+				"report":   models.JobStatusDone,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			manager := NewMemoryJobManager(WithSize(10))
+			var mu sync.Mutex
+			var ran []string
+			RegisterWorker(manager.dispatcher, &EmailWorker{
+				WorkFunc: func(ctx context.Context, job *Job[EmailJobArgs]) error {
+					mu.Lock()
+					defer mu.Unlock()
+					ran = append(ran, job.Args.Recipient)
+					if job.Args.Recipient == tt.failing {
+						return errors.New("boom")
+					}
+					return nil
+				},
+			})
+
+			g := NewGroup()
+			tasks := g.Chain(emailJob("import"), emailJob("tasks"))
+			g.Add(emailJob("notify"), tasks)
+			g.Add(emailJob("quantity"), tasks)
+			g.OnComplete(emailJob("report"))
+			require.NoError(t, g.Enqueue(ctx, manager))
+
+			for range 5 {
+				require.NoError(t, manager.PollOnce(ctx))
+			}
+
+			assert.Equal(t, tt.wantRun[:2], ran[:2])
+			assert.ElementsMatch(t, tt.wantRun, ran)
+			assert.Equal(t, "report", ran[len(ran)-1])
+
+			for _, row := range manager.Store.Jobs() {
+				var args EmailJobArgs
+				require.NoError(t, json.Unmarshal(row.Payload, &args))
+				assert.Equal(t, tt.wantJobs[args.Recipient], row.Status, args.Recipient)
+			}
+		})
+	}
+}
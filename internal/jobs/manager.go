@@ -2,20 +2,31 @@ package jobs
 
 import (
 	"context"
+	"errors"
 
+	"github.com/google/uuid"
 	"github.com/tkahng/playground/internal/database"
 	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/tools/hook"
 )
 
+// ErrJobNotCancellable is returned when cancelling a job that is not pending or processing.
+var ErrJobNotCancellable = errors.New("job is not pending or processing")
+
 type DbJobManager struct {
 	store      JobStore
 	poller     Poller
 	dispatcher Dispatcher
 }
 
-// WithTx implements JobManager.
+// WithTx implements JobManager. The returned manager writes jobs through db
+// and shares the dispatcher and poller of j.
 func (j *DbJobManager) WithTx(db database.Dbx) JobManager {
-	return NewDbJobManager(db)
+	return &DbJobManager{
+		store:      NewDbJobStore(db),
+		poller:     j.poller,
+		dispatcher: j.dispatcher,
+	}
 }
 
 // PollOnce implements JobManager.
@@ -33,6 +44,33 @@ func (j *DbJobManager) SetHandler(kind string, handler func(context.Context, *mo
 	j.dispatcher.SetHandler(kind, handler)
 }
 
+// OnStart implements JobManager.
+func (j *DbJobManager) OnStart() *hook.Hook[*JobEvent] {
+	return j.dispatcher.OnStart()
+}
+
+// OnSuccess implements JobManager.
+func (j *DbJobManager) OnSuccess() *hook.Hook[*JobEvent] {
+	return j.dispatcher.OnSuccess()
+}
+
+// OnFailure implements JobManager.
+func (j *DbJobManager) OnFailure() *hook.Hook[*JobEvent] {
+	return j.dispatcher.OnFailure()
+}
+
+// Cancel implements JobManager.
+func (j *DbJobManager) Cancel(ctx context.Context, id uuid.UUID) error {
+	ok, err := j.store.CancelJob(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrJobNotCancellable
+	}
+	return nil
+}
+
 // Enqueue implements JobManagerInterface.
 func (j *DbJobManager) Enqueue(ctx context.Context, args *EnqueueParams) error {
 	return j.store.SaveJob(ctx, args)
@@ -52,15 +90,29 @@ type JobManager interface {
 	Dispatcher
 	Enqueuer
 	Poller
+	// Cancel marks a pending or processing job as cancelled. A running job has
+	// its context cancelled with ErrJobCancelled by the poller executing it.
+	Cancel(ctx context.Context, id uuid.UUID) error
+	// WithTx returns a JobManager that enqueues through db. Passing the
+	// transaction of a domain write makes the job part of that write: it is
+	// only visible to pollers once the transaction commits and it is discarded
+	// when the transaction rolls back.
+	//
+	//	err := adapter.RunInTx(func(tx stores.StorageAdapterInterface) error {
+	//		if err := tx.Task().UpdateTask(ctx, task); err != nil {
+	//			return err
+	//		}
+	//		return manager.WithTx(tx.Db()).Enqueue(ctx, params)
+	//	})
 	WithTx(db database.Dbx) JobManager
 }
 
 var _ JobManager = (*DbJobManager)(nil)
 
-func NewDbJobManager(dbx database.Dbx) *DbJobManager {
+func NewDbJobManager(dbx database.Dbx, opts ...PollerOptsFunc) *DbJobManager {
 	store := NewDbJobStore(dbx)
 	dispatcher := NewDispatcher()
-	poller := NewDbPoller(store, dispatcher)
+	poller := NewDbPoller(store, dispatcher, opts...)
 	return &DbJobManager{
 		store:      store,
 		poller:     poller,
@@ -78,9 +130,42 @@ type DbJobManagerDecorator struct {
 	EnqueueManyFunc func(ctx context.Context, jobs ...*EnqueueParams) error
 	RunFunc         func(ctx context.Context) error
 	DispatchFunc    func(ctx context.Context, row *models.JobRow) error
+	CancelFunc      func(ctx context.Context, id uuid.UUID) error
 	WithTxFunc      func(db database.Dbx) JobManager
 }
 
+// OnStart implements JobManager.
+func (d *DbJobManagerDecorator) OnStart() *hook.Hook[*JobEvent] {
+	if d.Dispatcher != nil {
+		return d.Dispatcher.OnStart()
+	}
+	return d.Delegate.OnStart()
+}
+
+// OnSuccess implements JobManager.
+func (d *DbJobManagerDecorator) OnSuccess() *hook.Hook[*JobEvent] {
+	if d.Dispatcher != nil {
+		return d.Dispatcher.OnSuccess()
+	}
+	return d.Delegate.OnSuccess()
+}
+
+// OnFailure implements JobManager.
+func (d *DbJobManagerDecorator) OnFailure() *hook.Hook[*JobEvent] {
+	if d.Dispatcher != nil {
+		return d.Dispatcher.OnFailure()
+	}
+	return d.Delegate.OnFailure()
+}
+
+// Cancel implements JobManager.
+func (d *DbJobManagerDecorator) Cancel(ctx context.Context, id uuid.UUID) error {
+	if d.CancelFunc != nil {
+		return d.CancelFunc(ctx, id)
+	}
+	return d.Delegate.Cancel(ctx, id)
+}
+
 // WithTx implements JobManager.
 func (d *DbJobManagerDecorator) WithTx(db database.Dbx) JobManager {
 	if d.WithTxFunc != nil {
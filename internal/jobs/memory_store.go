@@ -0,0 +1,685 @@
+package jobs
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/database"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/stores"
+)
+
+// MemoryJobStore is a JobStore that keeps jobs in process memory. It follows
+// the semantics of DbJobStore: unique keys are deduplicated among pending and
+// processing jobs, only pending jobs whose run_after has passed and that have
+// attempts left are claimed, and RunInTx rolls back every change made by fn
+// when it returns an error.
+//
+// It is meant for tests and for running without a database, jobs are lost
+// when the process exits.
+type MemoryJobStore struct {
+	*memoryJobs
+	// journal holds the rows as they were before the running transaction
+	// first wrote them, a nil row was created by the transaction. It is nil
+	// outside of RunInTx.
+	journal map[uuid.UUID]*models.JobRow
+}
+
+// memoryJobs is the state shared by a MemoryJobStore and its transactions.
+type memoryJobs struct {
+	mu       sync.Mutex
+	jobs     map[uuid.UUID]*models.JobRow
+	now      func() time.Time
+	onCancel func(id uuid.UUID)
+}
+
+var _ JobStore = (*MemoryJobStore)(nil)
+
+func NewMemoryJobStore() *MemoryJobStore {
+	return &MemoryJobStore{
+		memoryJobs: &memoryJobs{
+			jobs: make(map[uuid.UUID]*models.JobRow),
+			now:  time.Now,
+		},
+	}
+}
+
+// Jobs returns a copy of every stored job ordered by creation.
+func (s *MemoryJobStore) Jobs() []*models.JobRow {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rows := make([]*models.JobRow, 0, len(s.jobs))
+	for _, row := range s.jobs {
+		c := *row
+		rows = append(rows, &c)
+	}
+	slices.SortFunc(rows, func(a, b *models.JobRow) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return rows
+}
+
+// SaveJob implements JobStore.
+func (s *MemoryJobStore) SaveJob(ctx context.Context, job *EnqueueParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.save(job)
+}
+
+// SaveManyJobs implements JobStore.
+func (s *MemoryJobStore) SaveManyJobs(ctx context.Context, jobs ...*EnqueueParams) error {
+	return s.RunInTx(ctx, func(js JobStore) error {
+		tx := js.(*MemoryJobStore)
+		tx.mu.Lock()
+		defer tx.mu.Unlock()
+		for i, job := range jobs {
+			if err := tx.save(job); err != nil {
+				return fmt.Errorf("job %d: %w", i, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (s *MemoryJobStore) save(job *EnqueueParams) error {
+	if job == nil {
+		return errors.New("job is nil")
+	}
+	payload, err := json.Marshal(job.Args)
+	if err != nil {
+		return fmt.Errorf("marshal args: %w", err)
+	}
+	now := s.now()
+
+	// same as ON CONFLICT (unique_key) WHERE status IN ('pending', 'processing')
+	if job.UniqueKey != nil {
+		for _, row := range s.jobs {
+			if row.UniqueKey == nil || *row.UniqueKey != *job.UniqueKey {
+				continue
+			}
+			if row.Status != models.JobStatusPending && row.Status != models.JobStatusProcessing {
+				continue
+			}
+			s.record(row.ID)
+			row.Payload = payload
+			row.Status = models.JobStatusPending
+			row.RunAfter = job.RunAfter
+			row.Attempts = 0
+			row.MaxAttempts = int64(job.MaxAttempts)
+			row.CreatedAt = now
+			row.UpdatedAt = now
+			return nil
+		}
+	}
+
+	id, err := jobID(job)
+	if err != nil {
+		return fmt.Errorf("generate uuid: %w", err)
+	}
+	s.record(id)
+	var uniqueKey *string
+	if job.UniqueKey != nil {
+		key := *job.UniqueKey
+		uniqueKey = &key
+	}
+	s.jobs[id] = &models.JobRow{
+		ID:          id,
+		Kind:        job.Args.Kind(),
+		UniqueKey:   uniqueKey,
+		Payload:     payload,
+		Status:      models.JobStatusPending,
+		RunAfter:    job.RunAfter,
+		Attempts:    0,
+		MaxAttempts: int64(job.MaxAttempts),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+
+		GroupID:            job.GroupID,
+		ParentIDs:          slices.Clone(job.ParentIDs),
+		RunOnParentFailure: job.RunOnParentFailure,
+	}
+	return nil
+}
+
+// ClaimPendingJobs implements JobStore.
+func (s *MemoryJobStore) ClaimPendingJobs(ctx context.Context, limit int) ([]*models.JobRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+
+	var pending []*models.JobRow
+	for _, row := range s.jobs {
+		if row.Status == models.JobStatusPending && !row.RunAfter.After(now) && row.Attempts < row.MaxAttempts && s.parentsReady(row) {
+			pending = append(pending, row)
+		}
+	}
+	slices.SortFunc(pending, func(a, b *models.JobRow) int {
+		return a.RunAfter.Compare(b.RunAfter)
+	})
+	if len(pending) > limit {
+		pending = pending[:limit]
+	}
+
+	claimed := make([]*models.JobRow, 0, len(pending))
+	for _, row := range pending {
+		s.record(row.ID)
+		row.Status = models.JobStatusProcessing
+		row.Attempts++
+		row.UpdatedAt = now
+		row.StartedAt = &now
+		row.FinishedAt = nil
+		c := *row
+		claimed = append(claimed, &c)
+	}
+	return claimed, nil
+}
+
+// MarkDone implements JobStore.
+func (s *MemoryJobStore) MarkDone(ctx context.Context, id uuid.UUID) error {
+	return s.update(id, func(row *models.JobRow) {
+		if row.Status == models.JobStatusCancelled {
+			return
+		}
+		row.Status = models.JobStatusDone
+		row.Progress = 100
+		row.FinishedAt = s.finishedAt()
+	})
+}
+
+// MarkFailed implements JobStore.
+func (s *MemoryJobStore) MarkFailed(ctx context.Context, id uuid.UUID, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	row, ok := s.jobs[id]
+	if !ok || row.Attempts < row.MaxAttempts || row.Status == models.JobStatusCancelled {
+		return nil
+	}
+	s.record(id)
+	row.Status = models.JobStatusFailed
+	row.LastError = &reason
+	row.FinishedAt = s.finishedAt()
+	row.UpdatedAt = s.now()
+	s.cancelDependants(id)
+	return nil
+}
+
+// RescheduleJob implements JobStore.
+func (s *MemoryJobStore) RescheduleJob(ctx context.Context, id uuid.UUID, delay time.Duration) error {
+	return s.update(id, func(row *models.JobRow) {
+		if row.Status == models.JobStatusCancelled {
+			return
+		}
+		row.Status = models.JobStatusPending
+		row.RunAfter = s.now().Add(delay)
+	})
+}
+
+// SaveResult implements JobStore.
+func (s *MemoryJobStore) SaveResult(ctx context.Context, id uuid.UUID, result []byte) error {
+	return s.update(id, func(row *models.JobRow) {
+		row.Result = slices.Clone(result)
+	})
+}
+
+// UpdateProgress implements JobStore.
+func (s *MemoryJobStore) UpdateProgress(ctx context.Context, id uuid.UUID, progress int) error {
+	return s.update(id, func(row *models.JobRow) {
+		if row.Status != models.JobStatusProcessing {
+			return
+		}
+		row.Progress = int64(progress)
+	})
+}
+
+// CancelJob implements JobStore.
+func (s *MemoryJobStore) CancelJob(ctx context.Context, id uuid.UUID) (bool, error) {
+	s.mu.Lock()
+	row, ok := s.jobs[id]
+	cancelled := ok && (row.Status == models.JobStatusPending || row.Status == models.JobStatusProcessing)
+	if cancelled {
+		s.record(id)
+		row.Status = models.JobStatusCancelled
+		row.UpdatedAt = s.now()
+		row.FinishedAt = s.finishedAt()
+		s.cancelDependants(id)
+	}
+	onCancel := s.onCancel
+	s.mu.Unlock()
+
+	if cancelled && onCancel != nil {
+		onCancel(id)
+	}
+	return cancelled, nil
+}
+
+// RunInTx implements JobStore. fn receives a store that journals the rows it
+// writes, a rollback restores only those rows so the writes of concurrent
+// callers are kept. Its RunInTx joins the running transaction.
+func (s *MemoryJobStore) RunInTx(ctx context.Context, fn func(JobStore) error) error {
+	if s.journal != nil {
+		return fn(s)
+	}
+	tx := &MemoryJobStore{
+		memoryJobs: s.memoryJobs,
+		journal:    make(map[uuid.UUID]*models.JobRow),
+	}
+	err := fn(tx)
+	if err != nil {
+		s.mu.Lock()
+		for id, row := range tx.journal {
+			if row == nil {
+				delete(s.jobs, id)
+			} else {
+				s.jobs[id] = row
+			}
+		}
+		s.mu.Unlock()
+	}
+	return err
+}
+
+// record journals the row before the running transaction first writes it,
+// s.mu must be held.
+func (s *MemoryJobStore) record(id uuid.UUID) {
+	if s.journal == nil {
+		return
+	}
+	if _, ok := s.journal[id]; ok {
+		return
+	}
+	row, ok := s.jobs[id]
+	if !ok {
+		s.journal[id] = nil
+		return
+	}
+	c := *row
+	s.journal[id] = &c
+}
+
+func (s *MemoryJobStore) update(id uuid.UUID, fn func(row *models.JobRow)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	row, ok := s.jobs[id]
+	if !ok {
+		return nil
+	}
+	s.record(id)
+	fn(row)
+	row.UpdatedAt = s.now()
+	return nil
+}
+
+// parentsReady mirrors the parent check of DbJobStore.ClaimPendingJobs.
+func (s *MemoryJobStore) parentsReady(row *models.JobRow) bool {
+	for _, id := range row.ParentIDs {
+		parent, ok := s.jobs[id]
+		if !ok {
+			return false
+		}
+		switch parent.Status {
+		case models.JobStatusDone:
+		case models.JobStatusFailed, models.JobStatusCancelled:
+			if !row.RunOnParentFailure {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// cancelDependants mirrors DbJobStore.cancelDependants, s.mu must be held.
+func (s *MemoryJobStore) cancelDependants(id uuid.UUID) {
+	reason := ErrParentJobFailed.Error()
+	queue := []uuid.UUID{id}
+	for len(queue) > 0 {
+		parentID := queue[0]
+		queue = queue[1:]
+		for _, row := range s.jobs {
+			if row.Status != models.JobStatusPending || row.RunOnParentFailure || !slices.Contains(row.ParentIDs, parentID) {
+				continue
+			}
+			s.record(row.ID)
+			row.Status = models.JobStatusCancelled
+			row.LastError = &reason
+			row.UpdatedAt = s.now()
+			row.FinishedAt = s.finishedAt()
+			queue = append(queue, row.ID)
+		}
+	}
+}
+
+func (s *MemoryJobStore) finishedAt() *time.Time {
+	now := s.now()
+	return &now
+}
+
+// FindJob mirrors stores.DbJobStore.FindJob, it returns nil when no job
+// matches the filter.
+func (s *MemoryJobStore) FindJob(ctx context.Context, filter *stores.JobFilter) (*models.JobRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rows := s.match(filter)
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	c := *rows[0]
+	return &c, nil
+}
+
+// FindJobs mirrors stores.DbJobStore.FindJobs, jobs are ordered by creation
+// unless the filter sorts by another column.
+func (s *MemoryJobStore) FindJobs(ctx context.Context, filter *stores.JobFilter) ([]*models.JobRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rows := s.match(filter)
+	sortBy, sortOrder := filter.Sort()
+	slices.SortStableFunc(rows, func(a, b *models.JobRow) int {
+		c := compareJobs(a, b, sortBy)
+		if strings.EqualFold(sortOrder, "desc") {
+			return -c
+		}
+		return c
+	})
+	limit, offset := filter.LimitOffset()
+	rows = rows[min(offset, len(rows)):]
+	rows = rows[:min(limit, len(rows))]
+	jobs := make([]*models.JobRow, 0, len(rows))
+	for _, row := range rows {
+		c := *row
+		jobs = append(jobs, &c)
+	}
+	return jobs, nil
+}
+
+// CountJobs mirrors stores.DbJobStore.CountJobs.
+func (s *MemoryJobStore) CountJobs(ctx context.Context, filter *stores.JobFilter) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int64(len(s.match(filter))), nil
+}
+
+// UpdateJob mirrors stores.DbJobStore.UpdateJob, it returns nil when the job
+// does not exist.
+func (s *MemoryJobStore) UpdateJob(ctx context.Context, job *models.JobRow) (*models.JobRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if job == nil {
+		return nil, errors.New("job is nil")
+	}
+	if _, ok := s.jobs[job.ID]; !ok {
+		return nil, nil
+	}
+	s.record(job.ID)
+	row := *job
+	row.UpdatedAt = s.now()
+	s.jobs[job.ID] = &row
+	c := row
+	return &c, nil
+}
+
+// GetJobGroup mirrors stores.DbJobStore.GetJobGroup, it returns nil when no
+// job belongs to the group.
+func (s *MemoryJobStore) GetJobGroup(ctx context.Context, groupID uuid.UUID) (*models.JobGroup, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var group *models.JobGroup
+	for _, row := range s.jobs {
+		if row.GroupID == nil || *row.GroupID != groupID {
+			continue
+		}
+		if group == nil {
+			group = &models.JobGroup{GroupID: groupID, CreatedAt: row.CreatedAt}
+		}
+		group.Total++
+		switch row.Status {
+		case models.JobStatusPending:
+			group.Pending++
+		case models.JobStatusProcessing:
+			group.Processing++
+		case models.JobStatusDone:
+			group.Done++
+		case models.JobStatusFailed:
+			group.Failed++
+		case models.JobStatusCancelled:
+			group.Cancelled++
+		}
+		if row.CreatedAt.Before(group.CreatedAt) {
+			group.CreatedAt = row.CreatedAt
+		}
+		if row.FinishedAt != nil && (group.FinishedAt == nil || row.FinishedAt.After(*group.FinishedAt)) {
+			finishedAt := *row.FinishedAt
+			group.FinishedAt = &finishedAt
+		}
+	}
+	return group, nil
+}
+
+// match returns the jobs matching the filter like stores.DbJobStore.filter,
+// s.mu must be held.
+func (s *MemoryJobStore) match(filter *stores.JobFilter) []*models.JobRow {
+	var rows []*models.JobRow
+	for _, row := range s.jobs {
+		if filter != nil {
+			if len(filter.Ids) > 0 && !slices.Contains(filter.Ids, row.ID) {
+				continue
+			}
+			if len(filter.Kinds) > 0 && !slices.Contains(filter.Kinds, row.Kind) {
+				continue
+			}
+			if len(filter.UniqueKeys) > 0 && (row.UniqueKey == nil || !slices.Contains(filter.UniqueKeys, *row.UniqueKey)) {
+				continue
+			}
+			if len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, row.Status) {
+				continue
+			}
+			if filter.RunAfter.IsSet && row.RunAfter.Before(filter.RunAfter.Value) {
+				continue
+			}
+			if filter.Attempt.IsSet && row.Attempts < filter.Attempt.Value {
+				continue
+			}
+			if len(filter.LastErrors) > 0 && (row.LastError == nil || !slices.Contains(filter.LastErrors, *row.LastError)) {
+				continue
+			}
+			if len(filter.GroupIds) > 0 && (row.GroupID == nil || !slices.Contains(filter.GroupIds, *row.GroupID)) {
+				continue
+			}
+		}
+		rows = append(rows, row)
+	}
+	slices.SortFunc(rows, func(a, b *models.JobRow) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return rows
+}
+
+// compareJobs orders jobs by the sort columns of the admin apis, other
+// columns keep the order of creation.
+func compareJobs(a, b *models.JobRow, sortBy string) int {
+	switch sortBy {
+	case "kind":
+		return strings.Compare(a.Kind, b.Kind)
+	case "status":
+		return strings.Compare(string(a.Status), string(b.Status))
+	case "run_after":
+		return a.RunAfter.Compare(b.RunAfter)
+	case "attempts":
+		return cmp.Compare(a.Attempts, b.Attempts)
+	case "updated_at":
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	case "created_at":
+		return a.CreatedAt.Compare(b.CreatedAt)
+	}
+	return 0
+}
+
+// MemoryJobManager is a JobManager backed by a MemoryJobStore.
+type MemoryJobManager struct {
+	*DbJobManager
+	Store *MemoryJobStore
+}
+
+var _ JobManager = (*MemoryJobManager)(nil)
+
+// WithTx implements JobManager. Memory jobs cannot join a database
+// transaction, the returned manager saves them once db commits and drops
+// them when it rolls back.
+func (m *MemoryJobManager) WithTx(db database.Dbx) JobManager {
+	return &DbJobManager{
+		store:      &memoryTxJobStore{MemoryJobStore: m.Store, db: db},
+		poller:     m.poller,
+		dispatcher: m.dispatcher,
+	}
+}
+
+// memoryTxJobStore defers the jobs saved through a database transaction until
+// it commits. The args are checked up front, a job failing later only logs.
+type memoryTxJobStore struct {
+	*MemoryJobStore
+	db database.Dbx
+}
+
+// SaveJob implements JobStore.
+func (s *memoryTxJobStore) SaveJob(ctx context.Context, job *EnqueueParams) error {
+	return s.SaveManyJobs(ctx, job)
+}
+
+// SaveManyJobs implements JobStore.
+func (s *memoryTxJobStore) SaveManyJobs(ctx context.Context, jobs ...*EnqueueParams) error {
+	for i, job := range jobs {
+		if job == nil {
+			return fmt.Errorf("job %d: job is nil", i)
+		}
+		if _, err := json.Marshal(job.Args); err != nil {
+			return fmt.Errorf("job %d: marshal args: %w", i, err)
+		}
+	}
+	ctx = context.WithoutCancel(ctx)
+	database.AfterCommit(s.db, func() {
+		if err := s.MemoryJobStore.SaveManyJobs(ctx, jobs...); err != nil {
+			slog.ErrorContext(ctx, "error saving jobs after commit", slog.Any("error", err))
+		}
+	})
+	return nil
+}
+
+func NewMemoryJobManager(opts ...PollerOptsFunc) *MemoryJobManager {
+	store := NewMemoryJobStore()
+	dispatcher := NewDispatcher()
+	poller := NewDbPoller(store, dispatcher, opts...)
+	store.onCancel = func(id uuid.UUID) {
+		poller.CancelRunning(id)
+	}
+	return &MemoryJobManager{
+		DbJobManager: &DbJobManager{
+			store:      store,
+			poller:     poller,
+			dispatcher: dispatcher,
+		},
+		Store: store,
+	}
+}
+
+// GetJobStats mirrors stores.DbJobStore.GetJobStats for the jobs kept in
+// memory.
+func (s *MemoryJobStore) GetJobStats(ctx context.Context, since time.Time, windows ...time.Duration) (*models.JobStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+
+	kinds := map[string]*models.JobKindStats{}
+	durations := map[string][]float64{}
+	var oldest *time.Time
+	for _, row := range s.jobs {
+		kind, ok := kinds[row.Kind]
+		if !ok {
+			kind = &models.JobKindStats{Kind: row.Kind}
+			kinds[row.Kind] = kind
+		}
+		switch row.Status {
+		case models.JobStatusPending:
+			kind.Pending++
+			if !row.RunAfter.After(now) && (oldest == nil || row.RunAfter.Before(*oldest)) {
+				runAfter := row.RunAfter
+				oldest = &runAfter
+			}
+		case models.JobStatusProcessing:
+			kind.Processing++
+		case models.JobStatusDone:
+			kind.Done++
+		case models.JobStatusFailed:
+			kind.Failed++
+		case models.JobStatusCancelled:
+			kind.Cancelled++
+		}
+		if finished(row) && row.StartedAt != nil && !row.FinishedAt.Before(since) {
+			durations[row.Kind] = append(durations[row.Kind], float64(row.FinishedAt.Sub(*row.StartedAt).Microseconds())/1000)
+		}
+	}
+
+	stats := &models.JobStats{
+		Kinds:        make([]models.JobKindStats, 0, len(kinds)),
+		FailureRates: make([]models.JobFailureRate, 0, len(windows)),
+		GeneratedAt:  time.Now(),
+	}
+	for _, name := range slices.Sorted(maps.Keys(kinds)) {
+		kind := kinds[name]
+		slices.Sort(durations[name])
+		kind.P50DurationMs = percentile(durations[name], 0.5)
+		kind.P95DurationMs = percentile(durations[name], 0.95)
+		stats.Kinds = append(stats.Kinds, *kind)
+	}
+	windows = slices.Clone(windows)
+	slices.Sort(windows)
+	for _, window := range windows {
+		rate := models.JobFailureRate{WindowSeconds: int64(window.Seconds())}
+		for _, row := range s.jobs {
+			if !finished(row) || row.FinishedAt.Before(now.Add(-window)) {
+				continue
+			}
+			rate.Finished++
+			if row.Status == models.JobStatusFailed {
+				rate.Failed++
+			}
+		}
+		if rate.Finished > 0 {
+			rate.Rate = float64(rate.Failed) / float64(rate.Finished)
+		}
+		stats.FailureRates = append(stats.FailureRates, rate)
+	}
+	if oldest != nil {
+		stats.OldestPending = oldest
+		stats.QueueLagSeconds = max(stats.GeneratedAt.Sub(*oldest).Seconds(), 0)
+	}
+	return stats, nil
+}
+
+func finished(row *models.JobRow) bool {
+	return (row.Status == models.JobStatusDone || row.Status == models.JobStatusFailed) && row.FinishedAt != nil
+}
+
+// percentile interpolates like percentile_cont, sorted must be sorted.
+func percentile(sorted []float64, p float64) *float64 {
+	if len(sorted) == 0 {
+		return nil
+	}
+	pos := p * float64(len(sorted)-1)
+	lower := int(pos)
+	value := sorted[lower]
+	if lower+1 < len(sorted) {
+		value += (pos - float64(lower)) * (sorted[lower+1] - sorted[lower])
+	}
+	return &value
+}
@@ -0,0 +1,341 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkahng/playground/internal/database"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/stores"
+)
+
+func TestMemoryJobStore_SaveJob(t *testing.T) {
+	ctx := context.Background()
+	key := "welcome-email"
+	tests := []struct {
+		name      string
+		jobs      []*EnqueueParams
+		wantCount int
+	}{
+		{
+			name: "jobs without unique key are all saved",
+			jobs: []*EnqueueParams{
+				{Args: EmailJobArgs{}, RunAfter: time.Now(), MaxAttempts: 3},
+				{Args: EmailJobArgs{}, RunAfter: time.Now(), MaxAttempts: 3},
+			},
+			wantCount: 2,
+		},
+		{
+			name: "pending job with same unique key is replaced",
+			jobs: []*EnqueueParams{
+				{Args: EmailJobArgs{}, UniqueKey: &key, RunAfter: time.Now(), MaxAttempts: 3},
+				{Args: EmailJobArgs{}, UniqueKey: &key, RunAfter: time.Now(), MaxAttempts: 5},
+			},
+			wantCount: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryJobStore()
+			for _, job := range tt.jobs {
+				require.NoError(t, store.SaveJob(ctx, job))
+			}
+			rows := store.Jobs()
+			assert.Len(t, rows, tt.wantCount)
+			last := tt.jobs[len(tt.jobs)-1]
+			assert.Equal(t, int64(last.MaxAttempts), rows[len(rows)-1].MaxAttempts)
+		})
+	}
+}
+
+func TestMemoryJobStore_ClaimPendingJobs(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryJobStore()
+	now := time.Now()
+	require.NoError(t, store.SaveManyJobs(ctx,
+		&EnqueueParams{Args: EmailJobArgs{Recipient: "due"}, RunAfter: now.Add(-time.Minute), MaxAttempts: 3},
+		&EnqueueParams{Args: EmailJobArgs{Recipient: "later"}, RunAfter: now.Add(time.Hour), MaxAttempts: 3},
+		&EnqueueParams{Args: EmailJobArgs{Recipient: "exhausted"}, RunAfter: now.Add(-time.Minute), MaxAttempts: 0},
+	))
+
+	claimed, err := store.ClaimPendingJobs(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, models.JobStatusProcessing, claimed[0].Status)
+	assert.Equal(t, int64(1), claimed[0].Attempts)
+	assert.JSONEq(t, `{"recipient":"due","subject":"","body":""}`, string(claimed[0].Payload))
+
+	claimed, err = store.ClaimPendingJobs(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed, "processing jobs are not claimed twice")
+}
+
+func TestMemoryJobStore_RunInTxRollsBack(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryJobStore()
+	require.NoError(t, store.SaveJob(ctx, &EnqueueParams{Args: EmailJobArgs{}, RunAfter: time.Now(), MaxAttempts: 1}))
+
+	wantErr := errors.New("rollback")
+	err := store.RunInTx(ctx, func(js JobStore) error {
+		if _, err := js.ClaimPendingJobs(ctx, 1); err != nil {
+			return err
+		}
+		if err := js.SaveJob(ctx, &EnqueueParams{Args: EmailJobArgs{}, RunAfter: time.Now(), MaxAttempts: 1}); err != nil {
+			return err
+		}
+		return wantErr
+	})
+	assert.ErrorIs(t, err, wantErr)
+
+	rows := store.Jobs()
+	require.Len(t, rows, 1)
+	assert.Equal(t, models.JobStatusPending, rows[0].Status)
+	assert.Equal(t, int64(0), rows[0].Attempts)
+}
+
+func TestMemoryJobStore_RunInTxKeepsConcurrentWrites(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryJobStore()
+	require.NoError(t, store.SaveJob(ctx, &EnqueueParams{Args: EmailJobArgs{Recipient: "claimed"}, RunAfter: time.Now(), MaxAttempts: 1}))
+
+	wantErr := errors.New("rollback")
+	err := store.RunInTx(ctx, func(js JobStore) error {
+		if err := js.SaveJob(ctx, &EnqueueParams{Args: EmailJobArgs{Recipient: "rolled back"}, RunAfter: time.Now().Add(time.Hour), MaxAttempts: 1}); err != nil {
+			return err
+		}
+		// writes made outside of the transaction while it runs
+		claimed, err := store.ClaimPendingJobs(ctx, 10)
+		if err != nil {
+			return err
+		}
+		require.Len(t, claimed, 1)
+		if err := store.SaveJob(ctx, &EnqueueParams{Args: EmailJobArgs{Recipient: "enqueued"}, RunAfter: time.Now(), MaxAttempts: 1}); err != nil {
+			return err
+		}
+		return wantErr
+	})
+	assert.ErrorIs(t, err, wantErr)
+
+	rows := store.Jobs()
+	require.Len(t, rows, 2)
+	assert.JSONEq(t, `{"recipient":"claimed","subject":"","body":""}`, string(rows[0].Payload))
+	assert.Equal(t, models.JobStatusProcessing, rows[0].Status, "the claim is kept")
+	assert.JSONEq(t, `{"recipient":"enqueued","subject":"","body":""}`, string(rows[1].Payload))
+}
+
+func TestMemoryJobManager_PollOnce(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name         string
+		maxAttempts  int
+		workErr      error
+		wantStatus   models.JobStatus
+		wantAttempts int64
+	}{
+		{
+			name:         "successful job is marked done",
+			maxAttempts:  3,
+			wantStatus:   models.JobStatusDone,
+			wantAttempts: 1,
+		},
+		{
+			name:         "failing job with attempts left is rescheduled",
+			maxAttempts:  3,
+			workErr:      errors.New("smtp down"),
+			wantStatus:   models.JobStatusPending,
+			wantAttempts: 1,
+		},
+		{
+			name:         "failing job on last attempt is marked failed",
+			maxAttempts:  1,
+			workErr:      errors.New("smtp down"),
+			wantStatus:   models.JobStatusFailed,
+			wantAttempts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewMemoryJobManager(WithSize(2))
+			RegisterWorker(manager.dispatcher, &EmailWorker{
+				WorkFunc: func(ctx context.Context, job *Job[EmailJobArgs]) error {
+					return tt.workErr
+				},
+			})
+			require.NoError(t, manager.Enqueue(ctx, &EnqueueParams{
+				Args:        EmailJobArgs{Recipient: "a@example.com"},
+				RunAfter:    time.Now(),
+				MaxAttempts: tt.maxAttempts,
+			}))
+
+			require.NoError(t, manager.PollOnce(ctx))
+
+			rows := manager.Store.Jobs()
+			require.Len(t, rows, 1)
+			assert.Equal(t, tt.wantStatus, rows[0].Status)
+			assert.Equal(t, tt.wantAttempts, rows[0].Attempts)
+		})
+	}
+}
+
+func TestMemoryJobManager_Cancel(t *testing.T) {
+	ctx := context.Background()
+	manager := NewMemoryJobManager()
+
+	started := make(chan struct{})
+	var cause error
+	RegisterWorker(manager.dispatcher, &EmailWorker{
+		WorkFunc: func(ctx context.Context, job *Job[EmailJobArgs]) error {
+			close(started)
+			<-ctx.Done()
+			cause = context.Cause(ctx)
+			return ctx.Err()
+		},
+	})
+	require.NoError(t, manager.Enqueue(ctx, &EnqueueParams{
+		Args:        EmailJobArgs{},
+		RunAfter:    time.Now(),
+		MaxAttempts: 1,
+	}))
+
+	done := make(chan error)
+	go func() {
+		done <- manager.PollOnce(ctx)
+	}()
+	<-started
+
+	id := manager.Store.Jobs()[0].ID
+	require.NoError(t, manager.Cancel(ctx, id))
+	require.NoError(t, <-done)
+
+	assert.ErrorIs(t, cause, ErrJobCancelled)
+	assert.Equal(t, models.JobStatusCancelled, manager.Store.Jobs()[0].Status)
+	assert.ErrorIs(t, manager.Cancel(ctx, id), ErrJobNotCancellable)
+}
+
+// fakeTx commits and rolls back without a database.
+type fakeTx struct{ pgx.Tx }
+
+func (fakeTx) Commit(ctx context.Context) error   { return nil }
+func (fakeTx) Rollback(ctx context.Context) error { return nil }
+
+type fakeDb struct{ database.Dbx }
+
+func (fakeDb) Begin(ctx context.Context) (pgx.Tx, error) { return fakeTx{}, nil }
+
+func TestMemoryJobManager_WithTx(t *testing.T) {
+	ctx := context.Background()
+	manager := NewMemoryJobManager()
+	enqueue := func(recipient string, fail error) error {
+		return database.WithTx(fakeDb{}, func(tx database.Dbx) error {
+			err := manager.WithTx(tx).Enqueue(ctx, &EnqueueParams{
+				Args:        EmailJobArgs{Recipient: recipient},
+				RunAfter:    time.Now(),
+				MaxAttempts: 1,
+			})
+			require.NoError(t, err)
+			assert.Empty(t, manager.Store.Jobs(), "jobs are saved on commit")
+			return fail
+		})
+	}
+
+	require.Error(t, enqueue("rolled back", errors.New("boom")))
+	assert.Empty(t, manager.Store.Jobs())
+
+	require.NoError(t, enqueue("committed", nil))
+	rows := manager.Store.Jobs()
+	require.Len(t, rows, 1)
+	assert.Contains(t, string(rows[0].Payload), `"committed"`)
+}
+
+func TestMemoryJobStore_GetJobStats(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryJobStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	require.NoError(t, store.SaveManyJobs(ctx,
+		&EnqueueParams{Args: EmailJobArgs{Recipient: "done"}, RunAfter: now.Add(-time.Hour), MaxAttempts: 1},
+		&EnqueueParams{Args: EmailJobArgs{Recipient: "failed"}, RunAfter: now.Add(-time.Hour), MaxAttempts: 1},
+	))
+	claimed, err := store.ClaimPendingJobs(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	now = now.Add(time.Second)
+	for _, row := range claimed {
+		if strings.Contains(string(row.Payload), `"done"`) {
+			require.NoError(t, store.MarkDone(ctx, row.ID))
+		} else {
+			require.NoError(t, store.MarkFailed(ctx, row.ID, "boom"))
+		}
+	}
+	require.NoError(t, store.SaveJob(ctx, &EnqueueParams{Args: EmailJobArgs{Recipient: "pending"}, RunAfter: now.Add(-time.Minute), MaxAttempts: 1}))
+
+	stats, err := store.GetJobStats(ctx, now.Add(-24*time.Hour), time.Hour, 5*time.Minute)
+	require.NoError(t, err)
+	require.Len(t, stats.Kinds, 1)
+	kind := stats.Kinds[0]
+	assert.Equal(t, EmailJobArgs{}.Kind(), kind.Kind)
+	assert.Equal(t, int64(1), kind.Pending)
+	assert.Equal(t, int64(1), kind.Done)
+	assert.Equal(t, int64(1), kind.Failed)
+	require.NotNil(t, kind.P50DurationMs)
+	assert.InDelta(t, 1000, *kind.P50DurationMs, 1)
+	require.Len(t, stats.FailureRates, 2)
+	assert.Equal(t, int64(300), stats.FailureRates[0].WindowSeconds, "windows are ordered")
+	assert.Equal(t, int64(2), stats.FailureRates[0].Finished)
+	assert.InDelta(t, 0.5, stats.FailureRates[0].Rate, 0.001)
+	require.NotNil(t, stats.OldestPending)
+	assert.Equal(t, now.Add(-time.Minute), *stats.OldestPending)
+}
+
+func TestMemoryJobStore_FindJobs(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryJobStore()
+	now := time.Now()
+	store.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	groupID := uuid.New()
+	for _, recipient := range []string{"first", "second", "third"} {
+		require.NoError(t, store.SaveJob(ctx, &EnqueueParams{Args: EmailJobArgs{Recipient: recipient}, RunAfter: now, MaxAttempts: 1, GroupID: &groupID}))
+	}
+	require.NoError(t, store.SaveJob(ctx, &EnqueueParams{Args: EmailJobArgs{Recipient: "other"}, RunAfter: now, MaxAttempts: 1}))
+
+	filter := &stores.JobFilter{GroupIds: []uuid.UUID{groupID}}
+	filter.PerPage = 2
+	filter.Page = 1
+	filter.SortBy = "created_at"
+	filter.SortOrder = "desc"
+	rows, err := store.FindJobs(ctx, filter)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Contains(t, string(rows[0].Payload), `"first"`)
+	count, err := store.CountJobs(ctx, filter)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	row, err := store.FindJob(ctx, &stores.JobFilter{Ids: []uuid.UUID{rows[0].ID}})
+	require.NoError(t, err)
+	require.NotNil(t, row)
+	row.Status = models.JobStatusFailed
+	_, err = store.UpdateJob(ctx, row)
+	require.NoError(t, err)
+
+	group, err := store.GetJobGroup(ctx, groupID)
+	require.NoError(t, err)
+	require.NotNil(t, group)
+	assert.Equal(t, int64(3), group.Total)
+	assert.Equal(t, int64(2), group.Pending)
+	assert.Equal(t, int64(1), group.Failed)
+
+	missing, err := store.GetJobGroup(ctx, uuid.New())
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
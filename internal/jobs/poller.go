@@ -2,15 +2,19 @@ package jobs
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/tools/notifier"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -32,6 +36,7 @@ type pollerOpts struct {
 	Interval time.Duration
 	Timeout  time.Duration
 	Size     int
+	Notifier notifier.Notifier
 }
 type PollerOptsFunc func(*pollerOpts)
 
@@ -59,6 +64,15 @@ func WithSize(size int) PollerOptsFunc {
 	}
 }
 
+// WithNotifier makes the poller listen on JobCancelChannel and cancel the
+// context of running jobs when they are cancelled. Without a notifier a
+// cancelled job keeps running and only its final status update is skipped.
+func WithNotifier(n notifier.Notifier) PollerOptsFunc {
+	return func(opts *pollerOpts) {
+		opts.Notifier = n
+	}
+}
+
 type Poller interface {
 	Run(ctx context.Context) error
 	PollOnce(ctx context.Context) error
@@ -68,6 +82,8 @@ type DbPoller struct {
 	Store      JobStore
 	Dispatcher Dispatcher
 	opts       pollerOpts
+	mu         sync.Mutex
+	running    map[uuid.UUID]context.CancelCauseFunc
 }
 
 var _ Poller = (*DbPoller)(nil)
@@ -81,6 +97,7 @@ func NewDbPoller(store JobStore, dispatcher Dispatcher, opts ...PollerOptsFunc)
 			Timeout:  30 * time.Second,
 			Size:     1,
 		},
+		running: make(map[uuid.UUID]context.CancelCauseFunc),
 	}
 	for _, opt := range opts {
 		opt(&p.opts)
@@ -92,6 +109,13 @@ func (p *DbPoller) Run(ctx context.Context) error {
 	ticker := time.NewTicker(p.opts.Interval)
 	defer ticker.Stop()
 
+	if p.opts.Notifier != nil {
+		sub := p.opts.Notifier.Subscribe(JobCancelChannel)
+		// ctx is cancelled by the time Run returns
+		defer sub.Unlisten(context.WithoutCancel(ctx))
+		go p.listenForCancellations(ctx, sub)
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -135,29 +159,48 @@ func (p *DbPoller) PollOnce(ctx context.Context) error {
 			defer func() { <-sem }()
 
 			// Set timeout for this job
-			jobCtx, cancel := context.WithTimeout(gctx, p.opts.Timeout)
+			timeoutCtx, cancel := context.WithTimeout(gctx, p.opts.Timeout)
 			defer cancel()
+			jobCtx, cancelJob := context.WithCancelCause(timeoutCtx)
+			defer cancelJob(nil)
+
+			p.track(job.ID, cancelJob)
+			defer p.untrack(job.ID)
 
-			dispatchErr := p.Dispatcher.Dispatch(jobCtx, job)
+			recorder := &jobRecorder{id: job.ID, store: p.Store}
+			dispatchErr := p.Dispatcher.Dispatch(setContextRecorder(jobCtx, recorder), job)
 
-			// Use new transaction to mark result
-			markErr := p.Store.RunInTx(jobCtx, func(js JobStore) error {
+			if errors.Is(context.Cause(jobCtx), ErrJobCancelled) {
+				// the row was already marked as cancelled, nothing left to update
+				slog.InfoContext(gctx, "job cancelled", "job_id", job.ID.String())
+				return nil
+			}
+
+			// Use new transaction and context to mark result, the job context may be done
+			markCtx, markCancel := context.WithTimeout(gctx, p.opts.Timeout)
+			defer markCancel()
+			markErr := p.Store.RunInTx(markCtx, func(js JobStore) error {
 				if dispatchErr != nil {
-					slog.ErrorContext(jobCtx, "job failed", "error", dispatchErr, "job_id", job.ID.String())
+					slog.ErrorContext(markCtx, "job failed", "error", dispatchErr, "job_id", job.ID.String())
 
 					if job.Attempts >= job.MaxAttempts {
-						return js.MarkFailed(jobCtx, job.ID, dispatchErr.Error())
+						return js.MarkFailed(markCtx, job.ID, dispatchErr.Error())
 					}
 					// Reschedule with exponential backoff
 					delay := time.Duration(math.Pow(2, float64(job.Attempts))) * time.Second
-					return js.RescheduleJob(jobCtx, job.ID, delay)
+					return js.RescheduleJob(markCtx, job.ID, delay)
 				}
 
-				return js.MarkDone(jobCtx, job.ID)
+				if output := recorder.Output(); output != nil {
+					if err := js.SaveResult(markCtx, job.ID, output); err != nil {
+						return err
+					}
+				}
+				return js.MarkDone(markCtx, job.ID)
 
 			})
 			if markErr != nil {
-				slog.ErrorContext(jobCtx, "error updating job status", "error", markErr, "job_id", job.ID.String())
+				slog.ErrorContext(markCtx, "error updating job status", "error", markErr, "job_id", job.ID.String())
 			}
 
 			return nil // always return nil to allow others to proceed
@@ -167,6 +210,48 @@ func (p *DbPoller) PollOnce(ctx context.Context) error {
 	return g.Wait()
 }
 
+func (p *DbPoller) track(id uuid.UUID, cancel context.CancelCauseFunc) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.running[id] = cancel
+}
+
+func (p *DbPoller) untrack(id uuid.UUID) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.running, id)
+}
+
+// CancelRunning cancels the context of a job running in this poller. It
+// reports whether the job was found.
+func (p *DbPoller) CancelRunning(id uuid.UUID) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	cancel, ok := p.running[id]
+	if ok {
+		cancel(ErrJobCancelled)
+	}
+	return ok
+}
+
+func (p *DbPoller) listenForCancellations(ctx context.Context, sub notifier.Subscription) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case payload := <-sub.NotificationC():
+			id, err := uuid.ParseBytes(payload)
+			if err != nil {
+				slog.ErrorContext(ctx, "invalid job cancellation payload", "payload", string(payload), "error", err)
+				continue
+			}
+			if p.CancelRunning(id) {
+				slog.InfoContext(ctx, "cancelling running job", "job_id", id.String())
+			}
+		}
+	}
+}
+
 type DbPollerDecorator struct {
 	Delegate     *DbPoller
 	RunFunc      func(ctx context.Context) error
@@ -7,7 +7,9 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
 	"github.com/tkahng/playground/internal/database"
+	"github.com/tkahng/playground/internal/models"
 	"github.com/tkahng/playground/internal/repository"
 	"github.com/tkahng/playground/internal/stores"
 	"github.com/tkahng/playground/internal/test"
@@ -169,3 +171,104 @@ func setupJobs(dbx database.Dbx) *TestJobService {
 		Worker:     emailWorker,
 	}
 }
+
+func newTestPollerStore(row *models.JobRow) (*JobStoreDecorator, map[string]int) {
+	calls := map[string]int{}
+	mu := sync.Mutex{}
+	record := func(name string) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls[name]++
+	}
+	store := NewJobStoreDecorator()
+	store.RunInTxFunc = func(ctx context.Context, fn func(JobStore) error) error {
+		return fn(store)
+	}
+	store.ClaimPendingJobsFunc = func(ctx context.Context, limit int) ([]*models.JobRow, error) {
+		return []*models.JobRow{row}, nil
+	}
+	store.MarkDoneFunc = func(ctx context.Context, id uuid.UUID) error {
+		record("MarkDone")
+		return nil
+	}
+	store.MarkFailedFunc = func(ctx context.Context, id uuid.UUID, reason string) error {
+		record("MarkFailed")
+		return nil
+	}
+	store.RescheduleJobFunc = func(ctx context.Context, id uuid.UUID, delay time.Duration) error {
+		record("RescheduleJob")
+		return nil
+	}
+	store.UpdateProgressFunc = func(ctx context.Context, id uuid.UUID, progress int) error {
+		record("UpdateProgress")
+		return nil
+	}
+	return store, calls
+}
+
+func TestDbPoller_PollOnce_RecordsOutput(t *testing.T) {
+	row := &models.JobRow{
+		ID:          uuid.New(),
+		Kind:        EmailJobArgs{}.Kind(),
+		Payload:     []byte(`{}`),
+		Attempts:    1,
+		MaxAttempts: 1,
+	}
+	store, calls := newTestPollerStore(row)
+	var saved []byte
+	store.SaveResultFunc = func(ctx context.Context, id uuid.UUID, result []byte) error {
+		saved = result
+		return nil
+	}
+	dispatcher := NewDispatcher()
+	RegisterWorker(dispatcher, &EmailWorker{
+		WorkFunc: func(ctx context.Context, job *Job[EmailJobArgs]) error {
+			if err := RecordProgress(ctx, 150); err != nil {
+				return err
+			}
+			return RecordOutput(ctx, map[string]int{"sent": 1})
+		},
+	})
+	poller := NewDbPoller(store, dispatcher, WithTimeout(2))
+
+	err := poller.PollOnce(context.Background())
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"sent":1}`, string(saved))
+	assert.Equal(t, 1, calls["UpdateProgress"])
+	assert.Equal(t, 1, calls["MarkDone"])
+}
+
+func TestDbPoller_CancelRunning(t *testing.T) {
+	row := &models.JobRow{
+		ID:          uuid.New(),
+		Kind:        EmailJobArgs{}.Kind(),
+		Payload:     []byte(`{}`),
+		Attempts:    1,
+		MaxAttempts: 3,
+	}
+	store, calls := newTestPollerStore(row)
+	started := make(chan struct{})
+	var cause error
+	dispatcher := NewDispatcher()
+	RegisterWorker(dispatcher, &EmailWorker{
+		WorkFunc: func(ctx context.Context, job *Job[EmailJobArgs]) error {
+			close(started)
+			<-ctx.Done()
+			cause = context.Cause(ctx)
+			return ctx.Err()
+		},
+	})
+	poller := NewDbPoller(store, dispatcher, WithTimeout(5))
+
+	go func() {
+		<-started
+		assert.True(t, poller.CancelRunning(row.ID))
+	}()
+	err := poller.PollOnce(context.Background())
+	assert.NoError(t, err)
+	assert.ErrorIs(t, cause, ErrJobCancelled)
+	assert.Zero(t, calls["MarkDone"])
+	assert.Zero(t, calls["MarkFailed"])
+	assert.Zero(t, calls["RescheduleJob"])
+	assert.False(t, poller.CancelRunning(row.ID))
+}
@@ -0,0 +1,83 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrJobCancelled is the cause attached to a job context that was cancelled
+	// through JobManager.Cancel.
+	ErrJobCancelled = errors.New("job cancelled")
+
+	// ErrNoJobRecorder is returned by RecordOutput and RecordProgress when the
+	// context was not created by a poller.
+	ErrNoJobRecorder = errors.New("no job recorder in context")
+)
+
+type contextKey string
+
+const (
+	recorderContextKey contextKey = "job_recorder"
+)
+
+// jobRecorder collects the output of a running job. The poller stores it on
+// the job context and persists the output once the job is marked done.
+type jobRecorder struct {
+	mu     sync.Mutex
+	id     uuid.UUID
+	store  JobStore
+	output []byte
+}
+
+func setContextRecorder(ctx context.Context, r *jobRecorder) context.Context {
+	return context.WithValue(ctx, recorderContextKey, r)
+}
+
+func getContextRecorder(ctx context.Context) *jobRecorder {
+	if r, ok := ctx.Value(recorderContextKey).(*jobRecorder); ok {
+		return r
+	} else {
+		return nil
+	}
+}
+
+func (r *jobRecorder) Output() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.output
+}
+
+// RecordOutput stores a JSON encodable value as the result of the running job.
+// The value is written to the job row when the job completes successfully.
+// Calling it more than once replaces the previous output.
+func RecordOutput(ctx context.Context, output any) error {
+	r := getContextRecorder(ctx)
+	if r == nil {
+		return ErrNoJobRecorder
+	}
+	raw, err := json.Marshal(output)
+	if err != nil {
+		return fmt.Errorf("marshal output: %w", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.output = raw
+	return nil
+}
+
+// RecordProgress writes the completion percentage of the running job to the
+// job row. Values are clamped to the 0-100 range.
+func RecordProgress(ctx context.Context, progress int) error {
+	r := getContextRecorder(ctx)
+	if r == nil {
+		return ErrNoJobRecorder
+	}
+	progress = max(0, min(progress, 100))
+	return r.store.UpdateProgress(ctx, r.id, progress)
+}
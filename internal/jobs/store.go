@@ -22,6 +22,12 @@ type EnqueueParams struct {
 	UniqueKey   *string   // Optional unique key for deduplication
 	RunAfter    time.Time // When the job should become available for processing
 	MaxAttempts int       // Maximum number of attempts before marking as failed
+
+	// Workflow fields, usually set through a Group.
+	ID                 uuid.UUID   // Optional job id, generated when zero
+	GroupID            *uuid.UUID  // Group the job belongs to
+	ParentIDs          []uuid.UUID // Jobs that must be done before this one is claimed
+	RunOnParentFailure bool        // Claim once parents finished, even if they failed
 }
 type Enqueuer interface {
 	// Enqueue adds a single job to the queue and returns its time-ordered UUIDv7
@@ -34,6 +40,10 @@ type Enqueuer interface {
 
 const maxBatchSize = 1000
 
+// JobCancelChannel is the notifier channel on which the ids of cancelled jobs
+// are published.
+const JobCancelChannel = "jobs_cancel"
+
 type JobStore interface {
 	SaveJob(ctx context.Context, args *EnqueueParams) error
 	SaveManyJobs(ctx context.Context, jobs ...*EnqueueParams) error
@@ -41,6 +51,9 @@ type JobStore interface {
 	MarkDone(ctx context.Context, id uuid.UUID) error
 	MarkFailed(ctx context.Context, id uuid.UUID, reason string) error
 	RescheduleJob(ctx context.Context, id uuid.UUID, delay time.Duration) error
+	SaveResult(ctx context.Context, id uuid.UUID, result []byte) error
+	UpdateProgress(ctx context.Context, id uuid.UUID, progress int) error
+	CancelJob(ctx context.Context, id uuid.UUID) (bool, error)
 	RunInTx(ctx context.Context, fn func(JobStore) error) error
 }
 type DbJobStore struct {
@@ -48,8 +61,9 @@ type DbJobStore struct {
 }
 
 const query string = `
-		INSERT INTO jobs (id, kind, unique_key, payload, status, run_after, attempts, max_attempts, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, 'pending', $5, 0, $6, clock_timestamp(), clock_timestamp())
+		INSERT INTO jobs (id, kind, unique_key, payload, status, run_after, attempts, max_attempts, created_at, updated_at,
+			group_id, parent_ids, run_on_parent_failure)
+		VALUES ($1, $2, $3, $4, 'pending', $5, 0, $6, clock_timestamp(), clock_timestamp(), $7, $8, $9)
 		ON CONFLICT (unique_key)
 		WHERE status IN ('pending', 'processing')
 		DO UPDATE SET
@@ -70,12 +84,13 @@ func (s *DbJobStore) SaveJob(ctx context.Context, job *EnqueueParams) error {
 	}
 
 	// Generate time-ordered UUIDv7 for better database performance
-	id, err := uuid.NewV7()
+	id, err := jobID(job)
 	if err != nil {
 		return fmt.Errorf("generate uuid: %w", err)
 	}
 
-	_, err = s.db.Exec(ctx, query, id, job.Args.Kind(), job.UniqueKey, payload, job.RunAfter, job.MaxAttempts)
+	_, err = s.db.Exec(ctx, query, id, job.Args.Kind(), job.UniqueKey, payload, job.RunAfter, job.MaxAttempts,
+		job.GroupID, job.ParentIDs, job.RunOnParentFailure)
 
 	return err
 }
@@ -131,16 +146,25 @@ func (e *DbJobStore) addJobToBatch(batch *pgx.Batch, job *EnqueueParams) error {
 		return fmt.Errorf("marshal args: %w", err)
 	}
 
-	id, err := uuid.NewV7()
+	id, err := jobID(job)
 	if err != nil {
 		return fmt.Errorf("generate uuid: %w", err)
 	}
 
-	batch.Queue(query, id, job.Args.Kind(), job.UniqueKey, payload, job.RunAfter, job.MaxAttempts)
+	batch.Queue(query, id, job.Args.Kind(), job.UniqueKey, payload, job.RunAfter, job.MaxAttempts,
+		job.GroupID, job.ParentIDs, job.RunOnParentFailure)
 
 	return nil
 }
 
+// jobID returns the id set on the job or a new UUIDv7.
+func jobID(job *EnqueueParams) (uuid.UUID, error) {
+	if job.ID != uuid.Nil {
+		return job.ID, nil
+	}
+	return uuid.NewV7()
+}
+
 // executeBatch sends the batch to the database and verifies all operations succeeded
 func (e *DbJobStore) executeBatch(ctx context.Context, tx pgx.Tx, batch *pgx.Batch, expectedResults int) error {
 	br := tx.SendBatch(ctx, batch)
@@ -183,15 +207,23 @@ func NewDbJobStore(db Db) *DbJobStore {
 
 func (s *DbJobStore) ClaimPendingJobs(ctx context.Context, limit int) ([]*models.JobRow, error) {
 	rows, err := s.db.Query(ctx, `
-		UPDATE jobs SET status='processing', updated_at=clock_timestamp(), attempts=attempts+1
+		UPDATE jobs SET status='processing', updated_at=clock_timestamp(), attempts=attempts+1,
+			started_at=clock_timestamp(), finished_at=NULL
 		WHERE id IN (
-			SELECT id FROM jobs
-			WHERE status='pending' AND run_after <= clock_timestamp() AND attempts < max_attempts
-			ORDER BY run_after
+			SELECT j.id FROM jobs j
+			WHERE j.status='pending' AND j.run_after <= clock_timestamp() AND j.attempts < j.max_attempts
+			AND NOT EXISTS (
+				SELECT 1 FROM unnest(j.parent_ids) AS p(id)
+				LEFT JOIN jobs parent ON parent.id = p.id
+				WHERE parent.id IS NULL
+					OR (parent.status <> 'done' AND NOT (j.run_on_parent_failure AND parent.status IN ('failed', 'cancelled')))
+			)
+			ORDER BY j.run_after
 			LIMIT $1
-			FOR UPDATE SKIP LOCKED
+			FOR UPDATE OF j SKIP LOCKED
 		)
-		RETURNING id, kind, unique_key, payload, status, run_after, attempts, max_attempts, last_error, created_at, updated_at
+		RETURNING id, kind, unique_key, payload, status, run_after, attempts, max_attempts, last_error, created_at, updated_at, result, progress,
+			started_at, finished_at, group_id, parent_ids, run_on_parent_failure
 	`, limit)
 	if err != nil {
 		return nil, err
@@ -204,6 +236,8 @@ func (s *DbJobStore) ClaimPendingJobs(ctx context.Context, limit int) ([]*models
 		if err := rows.Scan(
 			&row.ID, &row.Kind, &row.UniqueKey, &row.Payload, &row.Status, &row.RunAfter,
 			&row.Attempts, &row.MaxAttempts, &row.LastError, &row.CreatedAt, &row.UpdatedAt,
+			&row.Result, &row.Progress, &row.StartedAt, &row.FinishedAt,
+			&row.GroupID, &row.ParentIDs, &row.RunOnParentFailure,
 		); err != nil {
 			return nil, err
 		}
@@ -214,27 +248,90 @@ func (s *DbJobStore) ClaimPendingJobs(ctx context.Context, limit int) ([]*models
 
 func (s *DbJobStore) MarkDone(ctx context.Context, id uuid.UUID) error {
 	_, err := s.db.Exec(ctx, `
-		UPDATE jobs SET status='done', updated_at=clock_timestamp() WHERE id=$1
+		UPDATE jobs SET status='done', progress=100, updated_at=clock_timestamp(), finished_at=clock_timestamp()
+		WHERE id=$1 AND status <> 'cancelled'
 	`, id)
 	return err
 }
 
 func (s *DbJobStore) MarkFailed(ctx context.Context, id uuid.UUID, reason string) error {
-	_, err := s.db.Exec(ctx, `
-		UPDATE jobs SET status='failed', last_error=$2, updated_at=clock_timestamp()
-		WHERE id=$1 AND attempts >= max_attempts
+	tag, err := s.db.Exec(ctx, `
+		UPDATE jobs SET status='failed', last_error=$2, updated_at=clock_timestamp(), finished_at=clock_timestamp()
+		WHERE id=$1 AND attempts >= max_attempts AND status <> 'cancelled'
 	`, id, reason)
+	if err != nil || tag.RowsAffected() == 0 {
+		return err
+	}
+	return s.cancelDependants(ctx, id)
+}
+
+// cancelDependants cancels the pending jobs that can no longer run because
+// the job with the given id did not succeed, following the parent chain.
+// Jobs with run_on_parent_failure are left to run once their parents finish.
+func (s *DbJobStore) cancelDependants(ctx context.Context, id uuid.UUID) error {
+	_, err := s.db.Exec(ctx, `
+		WITH RECURSIVE dependants AS (
+			SELECT j.id FROM jobs j
+			WHERE j.parent_ids @> ARRAY[$1::uuid] AND j.status = 'pending' AND NOT j.run_on_parent_failure
+			UNION
+			SELECT j.id FROM jobs j
+			JOIN dependants d ON j.parent_ids @> ARRAY[d.id]
+			WHERE j.status = 'pending' AND NOT j.run_on_parent_failure
+		)
+		UPDATE jobs SET status = 'cancelled', last_error = $2,
+			updated_at = clock_timestamp(), finished_at = clock_timestamp()
+		WHERE id IN (SELECT id FROM dependants)
+	`, id, ErrParentJobFailed.Error())
 	return err
 }
 
 func (s *DbJobStore) RescheduleJob(ctx context.Context, id uuid.UUID, delay time.Duration) error {
 	_, err := s.db.Exec(ctx, `
 		UPDATE jobs SET run_after = clock_timestamp() + $2, updated_at = clock_timestamp(), status = 'pending'
-		WHERE id = $1
+		WHERE id = $1 AND status <> 'cancelled'
 	`, id, delay)
 	return err
 }
 
+// SaveResult stores the JSON output recorded by a worker on the job row.
+func (s *DbJobStore) SaveResult(ctx context.Context, id uuid.UUID, result []byte) error {
+	_, err := s.db.Exec(ctx, `
+		UPDATE jobs SET result = $2, updated_at = clock_timestamp() WHERE id = $1
+	`, id, result)
+	return err
+}
+
+// UpdateProgress stores the completion percentage reported by a running worker.
+func (s *DbJobStore) UpdateProgress(ctx context.Context, id uuid.UUID, progress int) error {
+	_, err := s.db.Exec(ctx, `
+		UPDATE jobs SET progress = $2, updated_at = clock_timestamp()
+		WHERE id = $1 AND status = 'processing'
+	`, id, progress)
+	return err
+}
+
+// CancelJob marks a pending or processing job as cancelled and publishes the
+// job id on JobCancelChannel so that the poller running it can cancel its
+// context. The notification is delivered when the surrounding transaction
+// commits. It reports whether a job was cancelled.
+func (s *DbJobStore) CancelJob(ctx context.Context, id uuid.UUID) (bool, error) {
+	tag, err := s.db.Exec(ctx, `
+		WITH cancelled AS (
+			UPDATE jobs SET status = 'cancelled', updated_at = clock_timestamp(), finished_at = clock_timestamp()
+			WHERE id = $1 AND status IN ('pending', 'processing')
+			RETURNING id
+		)
+		SELECT pg_notify($2, id::text) FROM cancelled
+	`, id, JobCancelChannel)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+	return true, s.cancelDependants(ctx, id)
+}
+
 type JobStoreDecorator struct {
 	Job                  *models.JobRow
 	Delegate             JobStore
@@ -245,6 +342,9 @@ type JobStoreDecorator struct {
 	RescheduleJobFunc    func(ctx context.Context, id uuid.UUID, delay time.Duration) error
 	SaveJobFunc          func(ctx context.Context, args *EnqueueParams) error
 	SaveManyJobsFunc     func(ctx context.Context, jobs ...*EnqueueParams) error
+	SaveResultFunc       func(ctx context.Context, id uuid.UUID, result []byte) error
+	UpdateProgressFunc   func(ctx context.Context, id uuid.UUID, progress int) error
+	CancelJobFunc        func(ctx context.Context, id uuid.UUID) (bool, error)
 }
 
 // SaveManyJobs implements JobStore.
@@ -307,6 +407,27 @@ func (d *JobStoreDecorator) RescheduleJob(ctx context.Context, id uuid.UUID, del
 	return d.Delegate.RescheduleJob(ctx, id, delay)
 }
 
+func (d *JobStoreDecorator) SaveResult(ctx context.Context, id uuid.UUID, result []byte) error {
+	if d.SaveResultFunc != nil {
+		return d.SaveResultFunc(ctx, id, result)
+	}
+	return d.Delegate.SaveResult(ctx, id, result)
+}
+
+func (d *JobStoreDecorator) UpdateProgress(ctx context.Context, id uuid.UUID, progress int) error {
+	if d.UpdateProgressFunc != nil {
+		return d.UpdateProgressFunc(ctx, id, progress)
+	}
+	return d.Delegate.UpdateProgress(ctx, id, progress)
+}
+
+func (d *JobStoreDecorator) CancelJob(ctx context.Context, id uuid.UUID) (bool, error) {
+	if d.CancelJobFunc != nil {
+		return d.CancelJobFunc(ctx, id)
+	}
+	return d.Delegate.CancelJob(ctx, id)
+}
+
 type testJob struct {
 	Message string
 }
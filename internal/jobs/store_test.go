@@ -3,6 +3,7 @@ package jobs
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
@@ -491,3 +492,181 @@ func TestDbJobStore_RescheduleJob(t *testing.T) {
 		}
 	})
 }
+
+func TestDbJobStore_CancelJob(t *testing.T) {
+	test.Parallel(t)
+	test.SkipIfShort(t)
+	test.WithTx(t, func(ctx context.Context, db database.Dbx) {
+		s := &DbJobStore{
+			db: db,
+		}
+		err := s.SaveJob(ctx, &EnqueueParams{
+			Args: EmailJobArgs{
+				Recipient: "recipient",
+				Subject:   "subject",
+				Body:      "body",
+			},
+			RunAfter:    time.Now(),
+			MaxAttempts: 3,
+		})
+		if err != nil {
+			t.Fatalf("DbJobStore.SaveJob() error = %v", err)
+		}
+		claimed, err := s.ClaimPendingJobs(ctx, 1)
+		if err != nil || len(claimed) != 1 {
+			t.Fatalf("DbJobStore.ClaimPendingJobs() got = %v, error = %v", len(claimed), err)
+		}
+		id := claimed[0].ID
+
+		ok, err := s.CancelJob(ctx, id)
+		if err != nil {
+			t.Fatalf("DbJobStore.CancelJob() error = %v", err)
+		}
+		if !ok {
+			t.Errorf("DbJobStore.CancelJob() got = %v, want %v", ok, true)
+		}
+		// a cancelled job is not rescheduled or marked done by the poller
+		if err := s.RescheduleJob(ctx, id, time.Second); err != nil {
+			t.Errorf("DbJobStore.RescheduleJob() error = %v", err)
+		}
+		if err := s.MarkDone(ctx, id); err != nil {
+			t.Errorf("DbJobStore.MarkDone() error = %v", err)
+		}
+		got, err := repository.Job.GetOne(ctx, db, &map[string]any{
+			"id": map[string]any{
+				"_eq": id,
+			},
+		})
+		if err != nil {
+			t.Fatalf("repository.Job.GetOne() error = %v", err)
+		}
+		if got.Status != models.JobStatusCancelled {
+			t.Errorf("DbJobStore.CancelJob() got = %v, want %v", got.Status, models.JobStatusCancelled)
+		}
+
+		ok, err = s.CancelJob(ctx, id)
+		if err != nil {
+			t.Fatalf("DbJobStore.CancelJob() error = %v", err)
+		}
+		if ok {
+			t.Errorf("DbJobStore.CancelJob() on cancelled job got = %v, want %v", ok, false)
+		}
+	})
+}
+
+func TestDbJobManager_WithTx(t *testing.T) {
+	test.Parallel(t)
+	test.SkipIfShort(t)
+	test.WithTx(t, func(ctx context.Context, db database.Dbx) {
+		manager := NewDbJobManager(db)
+		RegisterWorker(manager, &EmailWorker{
+			WorkFunc: func(ctx context.Context, job *Job[EmailJobArgs]) error {
+				return nil
+			},
+		})
+		subject := uuid.NewString()
+		enqueue := func(m JobManager) error {
+			return m.Enqueue(ctx, &EnqueueParams{
+				Args: EmailJobArgs{
+					Recipient: "recipient",
+					Subject:   subject,
+				},
+				RunAfter:    time.Now(),
+				MaxAttempts: 1,
+			})
+		}
+		count := func() int64 {
+			var c int64
+			err := db.QueryRow(ctx, "SELECT count(*) FROM jobs WHERE payload->>'subject' = $1", subject).Scan(&c)
+			if err != nil {
+				t.Fatalf("count jobs error = %v", err)
+			}
+			return c
+		}
+
+		rollbackErr := errors.New("domain write failed")
+		err := db.RunInTx(func(tx database.Dbx) error {
+			if err := enqueue(manager.WithTx(tx)); err != nil {
+				return err
+			}
+			return rollbackErr
+		})
+		if !errors.Is(err, rollbackErr) {
+			t.Fatalf("RunInTx() error = %v, want %v", err, rollbackErr)
+		}
+		if got := count(); got != 0 {
+			t.Errorf("WithTx() jobs after rollback = %v, want %v", got, 0)
+		}
+
+		err = db.RunInTx(func(tx database.Dbx) error {
+			txManager := manager.WithTx(tx)
+			// the tx manager keeps the registered workers
+			if err := txManager.Dispatch(ctx, &models.JobRow{
+				ID:      uuid.New(),
+				Kind:    EmailJobArgs{}.Kind(),
+				Payload: []byte(`{}`),
+			}); err != nil {
+				return err
+			}
+			return enqueue(txManager)
+		})
+		if err != nil {
+			t.Fatalf("RunInTx() error = %v", err)
+		}
+		if got := count(); got != 1 {
+			t.Errorf("WithTx() jobs after commit = %v, want %v", got, 1)
+		}
+	})
+}
+
+func TestDbJobStore_ClaimPendingJobs_Group(t *testing.T) {
+	test.Parallel(t)
+	test.SkipIfShort(t)
+	test.WithTx(t, func(ctx context.Context, db database.Dbx) {
+		s := &DbJobStore{
+			db: db,
+		}
+		g := NewGroup()
+		parent := g.Add(&EnqueueParams{Args: EmailJobArgs{Recipient: "parent"}, RunAfter: time.Now(), MaxAttempts: 1})
+		child := g.Add(&EnqueueParams{Args: EmailJobArgs{Recipient: "child"}, RunAfter: time.Now(), MaxAttempts: 1}, parent)
+		g.OnComplete(&EnqueueParams{Args: EmailJobArgs{Recipient: "callback"}, RunAfter: time.Now(), MaxAttempts: 1})
+		jobs, err := g.Jobs()
+		if err != nil {
+			t.Fatalf("Group.Jobs() error = %v", err)
+		}
+		if err := s.SaveManyJobs(ctx, jobs...); err != nil {
+			t.Fatalf("DbJobStore.SaveManyJobs() error = %v", err)
+		}
+
+		claimed, err := s.ClaimPendingJobs(ctx, 10)
+		if err != nil {
+			t.Fatalf("DbJobStore.ClaimPendingJobs() error = %v", err)
+		}
+		if len(claimed) != 1 || claimed[0].ID != parent.ID {
+			t.Fatalf("DbJobStore.ClaimPendingJobs() got = %v, want only the parent", len(claimed))
+		}
+
+		// a failed parent cancels the child, the callback becomes claimable
+		if err := s.MarkFailed(ctx, parent.ID, "boom"); err != nil {
+			t.Fatalf("DbJobStore.MarkFailed() error = %v", err)
+		}
+		got, err := repository.Job.GetOne(ctx, db, &map[string]any{
+			"id": map[string]any{
+				"_eq": child.ID,
+			},
+		})
+		if err != nil {
+			t.Fatalf("repository.Job.GetOne() error = %v", err)
+		}
+		if got.Status != models.JobStatusCancelled {
+			t.Errorf("child status got = %v, want %v", got.Status, models.JobStatusCancelled)
+		}
+		claimed, err = s.ClaimPendingJobs(ctx, 10)
+		if err != nil {
+			t.Fatalf("DbJobStore.ClaimPendingJobs() error = %v", err)
+		}
+		if len(claimed) != 1 || !claimed[0].RunOnParentFailure {
+			t.Errorf("DbJobStore.ClaimPendingJobs() got = %v, want the callback", len(claimed))
+		}
+	})
+}
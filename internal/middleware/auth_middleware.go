@@ -38,15 +38,7 @@ func AuthMiddleware(api huma.API, app core.App) func(ctx huma.Context, next func
 			next(ctx)
 			return
 		}
-		var token string
-		for idx, f := range HumaTokenFuncs {
-			index := idx
-			token = f(ctx)
-			if len(token) > 0 {
-				slog.InfoContext(ctxx, "found token", slog.Int("index", index), slog.String("token", token))
-				break
-			}
-		}
+		token := findToken(ctx, HumaTokenFuncs)
 		if len(token) == 0 {
 			next(ctx)
 			return
@@ -63,6 +55,44 @@ func AuthMiddleware(api huma.API, app core.App) func(ctx huma.Context, next func
 	}
 }
 
+func findToken(ctx huma.Context, tokenFuncs []func(huma.Context) string) string {
+	for idx, f := range tokenFuncs {
+		index := idx
+		token := f(ctx)
+		if len(token) > 0 {
+			slog.InfoContext(ctx.Context(), "found token", slog.Int("index", index), slog.String("token", token))
+			return token
+		}
+	}
+	return ""
+}
+
+// RequireTokenAuthMiddleware authenticates operations that have no Security,
+// such as websocket upgrades, with tokenFuncs and rejects unauthenticated
+// requests. Impersonated requests it authenticates go through
+// ImpersonationMiddleware, which ran before the user was known.
+func RequireTokenAuthMiddleware(api huma.API, app core.App, tokenFuncs ...func(huma.Context) string) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		if contextstore.GetContextUserInfo(ctx.Context()) != nil {
+			next(ctx)
+			return
+		}
+		token := findToken(ctx, tokenFuncs)
+		if len(token) == 0 {
+			huma.WriteErr(api, ctx, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		user, err := app.Auth().HandleAccessToken(ctx.Context(), token)
+		if err != nil {
+			slog.ErrorContext(ctx.Context(), "failed to handle access token", slog.Any("error", err))
+			huma.WriteErr(api, ctx, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		ctx = huma.WithContext(ctx, contextstore.SetContextUserInfo(ctx.Context(), user))
+		ImpersonationMiddleware(api, app)(ctx, next)
+	}
+}
+
 func RequireAuthMiddleware(api huma.API) func(ctx huma.Context, next func(huma.Context)) {
 	return func(ctx huma.Context, next func(huma.Context)) {
 		if ctx.Operation().Security == nil {
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/tkahng/playground/internal/contextstore"
+	"github.com/tkahng/playground/internal/core"
+	"github.com/tkahng/playground/internal/shared"
+)
+
+// ImpersonationMiddleware flags the responses of impersonated requests with
+// the ImpersonatedByHeader and writes every such request to the audit log.
+// The entry is written before the request is handled, so streams are logged
+// when they start, and requests that cannot be logged are refused. The
+// status is added once the handler returns.
+func ImpersonationMiddleware(api huma.API, app core.App) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		info := contextstore.GetContextUserInfo(ctx.Context())
+		if info == nil || info.Impersonation == nil {
+			next(ctx)
+			return
+		}
+		logId, err := app.Auth().RecordImpersonatedRequest(ctx.Context(), info, ctx.Method(), ctx.URL().Path)
+		if err != nil {
+			slog.ErrorContext(ctx.Context(), "failed to record impersonated request", slog.Any("error", err))
+			huma.WriteErr(api, ctx, http.StatusInternalServerError, "failed to record impersonated request")
+			return
+		}
+		ctx.SetHeader(shared.ImpersonatedByHeader, info.Impersonation.ImpersonatorID.String())
+		next(ctx)
+		// the request context ends with streams such as websockets
+		err = app.Auth().CompleteImpersonatedRequest(context.WithoutCancel(ctx.Context()), logId, ctx.Status())
+		if err != nil {
+			slog.ErrorContext(ctx.Context(), "failed to complete impersonated request", slog.Any("error", err))
+		}
+	}
+}
+
+// RefuseImpersonationMiddleware refuses operations that change the account,
+// how the user signs in or their sessions, such as linking accounts or
+// deleting the user, to impersonating superusers. Their effects would outlive
+// the impersonation and escape its audit log.
+func RefuseImpersonationMiddleware(api huma.API) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		info := contextstore.GetContextUserInfo(ctx.Context())
+		if info != nil && info.Impersonation != nil {
+			huma.WriteErr(api, ctx, http.StatusForbidden, "not allowed while impersonating")
+			return
+		}
+		next(ctx)
+	}
+}
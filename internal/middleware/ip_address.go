@@ -2,65 +2,23 @@ package middleware
 
 import (
 	"log/slog"
+	"net"
+	"net/netip"
+	"strings"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/tkahng/playground/internal/contextstore"
+	"github.com/tkahng/playground/internal/core"
 )
 
-type HeadersInput struct {
-	Header string
-	Split  bool
-}
-
-var headers = []HeadersInput{
-	{
-		Header: "X-Client-IP",
-	},
-	{
-		Header: "X-Forwarded-For",
-		Split:  true,
-	},
-	{
-		Header: "X-Forwarded",
-		Split:  true,
-	},
-	{
-		Header: "Forwarded-For",
-		Split:  true,
-	},
-	{
-		Header: "Forwarded",
-		Split:  true,
-	},
-	{
-		Header: "CF-Connecting-IP",
-	},
-	{
-		Header: "Fastly-Client-Ip",
-	},
-	{
-		Header: "True-Client-Ip",
-	},
-	{
-		Header: "X-Real-IP",
-	},
-	{
-		Header: "X-Cluster-Client-IP",
-	},
-}
-
-func IpAddressMiddleware(api huma.API) func(ctx huma.Context, next func(huma.Context)) {
+// IpAddressMiddleware keeps the ip address of the client in the context,
+// sign ins are throttled by it and sessions and audit logs record it. The
+// forwarding headers are only read from the trusted proxies of the config,
+// clients could send any address in them.
+func IpAddressMiddleware(api huma.API, app core.App) func(ctx huma.Context, next func(huma.Context)) {
+	trusted := parseTrustedProxies(app.Config().TrustedProxies)
 	return func(ctx huma.Context, next func(huma.Context)) {
-		var ip string
-		for idx, header := range headers {
-			index := idx
-			ipHeader := ctx.Header(header.Header)
-			if len(ipHeader) > 0 {
-				slog.InfoContext(ctx.Context(), "found ip", slog.Int("index", index), slog.String("ip", ip))
-				ip = ipHeader
-				break
-			}
-		}
+		ip := clientIP(ctx.RemoteAddr(), ctx.Header("X-Forwarded-For"), ctx.Header("X-Real-IP"), trusted)
 		if len(ip) == 0 {
 			next(ctx)
 			return
@@ -70,3 +28,72 @@ func IpAddressMiddleware(api huma.API) func(ctx huma.Context, next func(huma.Con
 		next(ctx)
 	}
 }
+
+// parseTrustedProxies parses ip addresses and CIDR ranges, invalid entries
+// are skipped so their headers are not trusted.
+func parseTrustedProxies(proxies []string) []netip.Prefix {
+	var trusted []netip.Prefix
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if prefix, err := netip.ParsePrefix(proxy); err == nil {
+			trusted = append(trusted, prefix.Masked())
+			continue
+		}
+		if addr, err := netip.ParseAddr(proxy); err == nil {
+			addr = addr.Unmap()
+			trusted = append(trusted, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		slog.Warn("ignoring invalid trusted proxy", slog.String("proxy", proxy))
+	}
+	return trusted
+}
+
+// clientIP returns the address of the connection, or when it is a trusted
+// proxy the rightmost address of X-Forwarded-For that is not one. Addresses
+// left of it were added by the client and are not used.
+func clientIP(remoteAddr string, forwardedFor string, realIP string, trusted []netip.Prefix) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	remote, err := netip.ParseAddr(host)
+	if err != nil {
+		return ""
+	}
+	remote = remote.Unmap()
+	if !isTrustedProxy(remote, trusted) {
+		return remote.String()
+	}
+	if forwardedFor == "" {
+		if addr, err := netip.ParseAddr(strings.TrimSpace(realIP)); err == nil {
+			return addr.Unmap().String()
+		}
+		return remote.String()
+	}
+	client := remote
+	hops := strings.Split(forwardedFor, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		client = addr.Unmap()
+		if !isTrustedProxy(client, trusted) {
+			break
+		}
+	}
+	return client.String()
+}
+
+func isTrustedProxy(addr netip.Addr, trusted []netip.Prefix) bool {
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted := parseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1", "not-an-ip"})
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		realIP       string
+		want         string
+	}{
+		{name: "direct clients use their connection", remoteAddr: "203.0.113.7:5123", want: "203.0.113.7"},
+		{name: "headers of untrusted clients are ignored", remoteAddr: "203.0.113.7:5123", forwardedFor: "198.51.100.1", realIP: "198.51.100.2", want: "203.0.113.7"},
+		{name: "trusted proxies forward the client", remoteAddr: "10.0.0.2:80", forwardedFor: "198.51.100.1", want: "198.51.100.1"},
+		{name: "addresses sent by the client are skipped", remoteAddr: "10.0.0.2:80", forwardedFor: "1.2.3.4, 198.51.100.1, 192.168.1.1", want: "198.51.100.1"},
+		{name: "invalid hops stop the walk", remoteAddr: "10.0.0.2:80", forwardedFor: "198.51.100.1, garbage, 10.0.0.3", want: "10.0.0.3"},
+		{name: "real ip without forwarded for", remoteAddr: "192.168.1.1:80", realIP: "198.51.100.2", want: "198.51.100.2"},
+		{name: "ipv4 mapped connections", remoteAddr: "[::ffff:10.0.0.2]:80", forwardedFor: "198.51.100.1", want: "198.51.100.1"},
+		{name: "unparsable connections", remoteAddr: "pipe", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, clientIP(tt.remoteAddr, tt.forwardedFor, tt.realIP, trusted))
+		})
+	}
+}
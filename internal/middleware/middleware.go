@@ -46,3 +46,11 @@ var HumaTokenFuncs = []func(huma.Context) string{
 	HumaTokenFromHeader,
 	HumaTokenFromQuery,
 }
+
+// HumaWebsocketTokenFuncs are the token sources of websocket upgrades.
+// Browsers cannot set headers on them, so the cookie is checked as well.
+var HumaWebsocketTokenFuncs = []func(huma.Context) string{
+	HumaTokenFromHeader,
+	HumaTokenFromCookie,
+	HumaTokenFromQuery,
+}
@@ -249,6 +249,60 @@ func RequireTeamMemberRolesMiddleware(api huma.API, roles ...models.TeamMemberRo
 	}
 }
 
+// RequireTeamPermissionsMiddleware lets members of the team in context through
+// when their roles grant one of the permissions. Roles assigned in a task
+// project count on routes of the project and of its tasks.
+func RequireTeamPermissionsMiddleware(api huma.API, app core.App, permissions ...string) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		rawCtx := ctx.Context()
+		info := contextstore.GetContextTeamInfo(rawCtx)
+		if info == nil {
+			huma.WriteErr(api, ctx, http.StatusForbidden, "missing team membership", nil)
+			return
+		}
+		projectID, err := taskProjectIDFromParams(ctx, app)
+		if err != nil {
+			huma.WriteErr(api, ctx, http.StatusInternalServerError, "error getting project", err)
+			return
+		}
+		ok, err := app.TeamRoles().Can(rawCtx, &info.Member, projectID, permissions...)
+		if err != nil {
+			huma.WriteErr(api, ctx, http.StatusInternalServerError, "error checking team permissions", err)
+			return
+		}
+		if !ok {
+			huma.WriteErr(
+				api,
+				ctx,
+				http.StatusForbidden,
+				fmt.Sprintf("You do not have the required team permissions: %v", permissions),
+			)
+			return
+		}
+		next(ctx)
+	}
+}
+
+// taskProjectIDFromParams returns the task project of the task-project-id or
+// task-id param, nil on routes without them.
+func taskProjectIDFromParams(ctx huma.Context, app core.App) (*uuid.UUID, error) {
+	if projectID, err := uuid.Parse(ctx.Param("task-project-id")); err == nil {
+		return &projectID, nil
+	}
+	taskID, err := uuid.Parse(ctx.Param("task-id"))
+	if err != nil {
+		return nil, nil
+	}
+	task, err := app.Adapter().Task().FindTaskByID(ctx.Context(), taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, nil
+	}
+	return &task.ProjectID, nil
+}
+
 func LatestTeamMiddleware(api huma.API, app core.App) func(ctx huma.Context, next func(huma.Context)) {
 	return func(ctx huma.Context, next func(huma.Context)) {
 		rawCtx := ctx.Context()
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/tkahng/playground/internal/contextstore"
+)
+
+// UserAgentMiddleware keeps the user agent of the request in the context,
+// sessions record it on sign in.
+func UserAgentMiddleware(api huma.API) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		userAgent := ctx.Header("User-Agent")
+		if len(userAgent) == 0 {
+			next(ctx)
+			return
+		}
+		ctx = huma.WithContext(ctx, contextstore.SetContextUserAgent(ctx.Context(), userAgent))
+		next(ctx)
+	}
+}
@@ -7,7 +7,15 @@ import (
 	"github.com/tkahng/playground/internal/tools/types"
 )
 
+// Audit logs of impersonations, their attributes carry the impersonation_id.
+const (
+	AuditLogImpersonationStarted = "impersonation.started"
+	AuditLogImpersonationRequest = "impersonation.request"
+	AuditLogImpersonationEnded   = "impersonation.ended"
+)
+
 type AuditLog struct {
+	_            struct{}           `db:"audit_logs" json:"-"`
 	ID           uuid.UUID          `db:"id,pk" json:"id"`
 	IP           *string            `db:"ip" json:"ip,omitempty"`
 	Email        *string            `db:"email" json:"email,omitempty"`
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GameSticks is the game name the ratings and results of sticks are stored under.
+const GameSticks = "sticks"
+
+// GameRating is the rating and record of a user in a game.
+type GameRating struct {
+	_         struct{}  `db:"game_ratings" json:"-"`
+	UserID    uuid.UUID `db:"user_id" json:"user_id"`
+	Game      string    `db:"game" json:"game"`
+	Rating    int       `db:"rating" json:"rating"`
+	Wins      int       `db:"wins" json:"wins"`
+	Losses    int       `db:"losses" json:"losses"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// GameResult is a finished game, the ratings are the ones after the game.
+type GameResult struct {
+	_            struct{}  `db:"game_results" json:"-"`
+	ID           uuid.UUID `db:"id,pk" json:"id"`
+	Game         string    `db:"game" json:"game"`
+	GameID       string    `db:"game_id" json:"game_id"`
+	WinnerID     uuid.UUID `db:"winner_id" json:"winner_id"`
+	LoserID      uuid.UUID `db:"loser_id" json:"loser_id"`
+	WinnerRating int       `db:"winner_rating" json:"winner_rating"`
+	LoserRating  int       `db:"loser_rating" json:"loser_rating"`
+	RatingChange int       `db:"rating_change" json:"rating_change"`
+	Moves        int       `db:"moves" json:"moves"`
+	StartedAt    time.Time `db:"started_at" json:"started_at"`
+	FinishedAt   time.Time `db:"finished_at" json:"finished_at"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Impersonation is a superuser acting as a user. Its access token is
+// rejected once it is ended, expires or the session of the superuser is
+// revoked.
+type Impersonation struct {
+	_                 struct{}   `db:"impersonations" json:"-"`
+	ID                uuid.UUID  `db:"id" json:"id"`
+	ImpersonatorID    uuid.UUID  `db:"impersonator_id" json:"impersonator_id"`
+	ImpersonatorEmail string     `db:"impersonator_email" json:"impersonator_email"`
+	UserID            uuid.UUID  `db:"user_id" json:"user_id"`
+	SessionID         *uuid.UUID `db:"session_id" json:"-"`
+	Expires           time.Time  `db:"expires" json:"expires"`
+	EndedAt           *time.Time `db:"ended_at" json:"ended_at"`
+	CreatedAt         time.Time  `db:"created_at" json:"created_at"`
+}
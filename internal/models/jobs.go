@@ -6,7 +6,7 @@ import (
 	"github.com/google/uuid"
 )
 
-// 'pending', 'processing', 'done', 'failed'
+// 'pending', 'processing', 'done', 'failed', 'cancelled'
 type JobStatus string
 
 const (
@@ -14,19 +14,71 @@ const (
 	JobStatusProcessing JobStatus = "processing"
 	JobStatusDone       JobStatus = "done"
 	JobStatusFailed     JobStatus = "failed"
+	JobStatusCancelled  JobStatus = "cancelled"
 )
 
 type JobRow struct {
-	_           struct{}  `db:"jobs" json:"-"`
-	ID          uuid.UUID `db:"id" json:"id"`
-	Kind        string    `db:"kind" json:"kind"`
-	UniqueKey   *string   `db:"unique_key" json:"unique_key"`
-	Payload     []byte    `db:"payload" json:"payload"`
-	Status      JobStatus `db:"status" json:"status"`
-	RunAfter    time.Time `db:"run_after" json:"run_after"`
-	Attempts    int64     `db:"attempts" json:"attempts"`
-	MaxAttempts int64     `db:"max_attempts" json:"max_attempts"`
-	LastError   *string   `db:"last_error" json:"last_error"`
-	CreatedAt   time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+	_                  struct{}    `db:"jobs" json:"-"`
+	ID                 uuid.UUID   `db:"id" json:"id"`
+	Kind               string      `db:"kind" json:"kind"`
+	UniqueKey          *string     `db:"unique_key" json:"unique_key"`
+	Payload            []byte      `db:"payload" json:"payload"`
+	Status             JobStatus   `db:"status" json:"status"`
+	RunAfter           time.Time   `db:"run_after" json:"run_after"`
+	Attempts           int64       `db:"attempts" json:"attempts"`
+	MaxAttempts        int64       `db:"max_attempts" json:"max_attempts"`
+	LastError          *string     `db:"last_error" json:"last_error"`
+	CreatedAt          time.Time   `db:"created_at" json:"created_at"`
+	UpdatedAt          time.Time   `db:"updated_at" json:"updated_at"`
+	Result             []byte      `db:"result" json:"result"`
+	Progress           int64       `db:"progress" json:"progress"`
+	StartedAt          *time.Time  `db:"started_at" json:"started_at"`
+	FinishedAt         *time.Time  `db:"finished_at" json:"finished_at"`
+	GroupID            *uuid.UUID  `db:"group_id" json:"group_id"`
+	ParentIDs          []uuid.UUID `db:"parent_ids" json:"parent_ids"`
+	RunOnParentFailure bool        `db:"run_on_parent_failure" json:"run_on_parent_failure"`
+}
+
+// JobKindStats holds the job counts per status of a single kind, and the run
+// durations of the jobs of that kind that finished since the requested time.
+type JobKindStats struct {
+	Kind          string   `db:"kind" json:"kind"`
+	Pending       int64    `db:"pending" json:"pending"`
+	Processing    int64    `db:"processing" json:"processing"`
+	Done          int64    `db:"done" json:"done"`
+	Failed        int64    `db:"failed" json:"failed"`
+	Cancelled     int64    `db:"cancelled" json:"cancelled"`
+	P50DurationMs *float64 `db:"p50_duration_ms" json:"p50_duration_ms"`
+	P95DurationMs *float64 `db:"p95_duration_ms" json:"p95_duration_ms"`
+}
+
+// JobFailureRate counts the jobs that finished within the last WindowSeconds.
+type JobFailureRate struct {
+	WindowSeconds int64   `db:"window_seconds" json:"window_seconds"`
+	Finished      int64   `db:"finished" json:"finished"`
+	Failed        int64   `db:"failed" json:"failed"`
+	Rate          float64 `db:"rate" json:"rate"`
+}
+
+type JobStats struct {
+	Kinds        []JobKindStats   `json:"kinds"`
+	FailureRates []JobFailureRate `json:"failure_rates"`
+	// QueueLagSeconds is the time since the oldest due pending job became
+	// runnable, 0 when no job is waiting.
+	QueueLagSeconds float64    `json:"queue_lag_seconds"`
+	OldestPending   *time.Time `json:"oldest_pending"`
+	GeneratedAt     time.Time  `json:"generated_at"`
+}
+
+// JobGroup aggregates the jobs enqueued through a jobs.Group.
+type JobGroup struct {
+	GroupID    uuid.UUID  `db:"group_id" json:"group_id"`
+	Total      int64      `db:"total" json:"total"`
+	Pending    int64      `db:"pending" json:"pending"`
+	Processing int64      `db:"processing" json:"processing"`
+	Done       int64      `db:"done" json:"done"`
+	Failed     int64      `db:"failed" json:"failed"`
+	Cancelled  int64      `db:"cancelled" json:"cancelled"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	FinishedAt *time.Time `db:"finished_at" json:"finished_at"`
 }
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// LoginAttemptAction is what a login attempt tried to do.
+type LoginAttemptAction string
+
+const (
+	LoginAttemptActionSignin LoginAttemptAction = "signin"
+	// LoginAttemptActionPasswordReset and LoginAttemptActionMagicLink count
+	// requests for their emails instead of failures.
+	LoginAttemptActionPasswordReset LoginAttemptAction = "password-reset"
+	LoginAttemptActionVerify        LoginAttemptAction = "verify"
+	LoginAttemptActionMagicLink     LoginAttemptAction = "magic-link"
+	// LoginAttemptActionMagicLinkCode counts wrong magic link codes, apart
+	// from the failed sign ins with a password.
+	LoginAttemptActionMagicLinkCode LoginAttemptAction = "magic-link-code"
+)
+
+// LoginAttempt counts the failures of an action for an email from an ip
+// address. Actions without an email, like verifying a token, have an empty
+// one.
+type LoginAttempt struct {
+	Action       LoginAttemptAction `db:"action" json:"action"`
+	Email        string             `db:"email" json:"email"`
+	IPAddress    string             `db:"ip_address" json:"ip_address"`
+	Failures     int                `db:"failures" json:"failures"`
+	LastFailedAt time.Time          `db:"last_failed_at" json:"last_failed_at"`
+	CreatedAt    time.Time          `db:"created_at" json:"created_at"`
+}
@@ -17,6 +17,8 @@ type Token struct {
 	Token      string     `db:"token" json:"token"`
 	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
 	UpdatedAt  time.Time  `db:"updated_at" json:"updated_at"`
+	SessionID  *uuid.UUID `db:"session_id" json:"session_id"`
+	UsedAt     *time.Time `db:"used_at" json:"used_at"`
 	User       *User      `db:"users" src:"user_id" dest:"id" table:"users" json:"user,omitempty"`
 }
 
@@ -31,6 +33,12 @@ const (
 	TokenTypesVerificationToken     TokenTypes = "verification_token"
 	TokenTypesPasswordResetToken    TokenTypes = "password_reset_token"
 	TokenTypesStateToken            TokenTypes = "state_token"
+	// TokenTypesMagicLinkToken signs users in without a password, by a
+	// link or its code.
+	TokenTypesMagicLinkToken TokenTypes = "magic_link_token"
+	// TokenTypesUnsubscribeToken signs the unsubscribe links of notification
+	// emails, it is never stored.
+	TokenTypesUnsubscribeToken TokenTypes = "unsubscribe_token"
 )
 
 type Medium struct {
@@ -10,7 +10,7 @@ import (
 //
 // Taxonomy:
 //   - Notification
-//   - Channel eg: "team_member_id:bbde432f-1553-4c7d-bf34-ffe020683f56"
+//   - Channel eg: "team_member:bbde432f-1553-4c7d-bf34-ffe020683f56"
 //   - Type eg: "comment_reply"
 type Notification struct {
 	_            struct{}       `db:"notifications" json:"-"`
@@ -25,7 +25,56 @@ type Notification struct {
 	TeamID       *uuid.UUID     `db:"team_id" json:"team_id,omitempty"`
 	Metadata     map[string]any `db:"metadata" json:"metadata"`
 	Type         string         `db:"type" json:"type"`
+	Seq          int64          `db:"seq" json:"seq"`
 	User         *User          `db:"user" src:"user_id" dest:"id" table:"users" json:"user,omitempty"`
 	TeamMember   *TeamMember    `db:"team_member" src:"team_member_id" dest:"id" table:"team_members" json:"team_member,omitempty"`
 	Team         *Team          `db:"team" src:"team_id" dest:"id" table:"teams" json:"team,omitempty"`
 }
+
+// NotificationDelivery is where a notification is delivered to.
+type NotificationDelivery string
+
+const (
+	NotificationDeliveryInApp NotificationDelivery = "in_app"
+	NotificationDeliveryEmail NotificationDelivery = "email"
+	NotificationDeliveryBoth  NotificationDelivery = "both"
+	NotificationDeliveryNone  NotificationDelivery = "none"
+)
+
+// InApp reports whether a notification row is stored and pushed over sse.
+func (d NotificationDelivery) InApp() bool {
+	return d == NotificationDeliveryInApp || d == NotificationDeliveryBoth
+}
+
+// Email reports whether the notification is sent by email.
+func (d NotificationDelivery) Email() bool {
+	return d == NotificationDeliveryEmail || d == NotificationDeliveryBoth
+}
+
+// NotificationPreference is how a user wants to receive notifications of a
+// type. A preference without a team applies to every team of the user, a
+// preference of a team overrides it.
+type NotificationPreference struct {
+	_        struct{}             `db:"notification_preferences" json:"-"`
+	ID       uuid.UUID            `db:"id,pk" json:"id"`
+	UserID   uuid.UUID            `db:"user_id" json:"user_id"`
+	TeamID   *uuid.UUID           `db:"team_id" json:"team_id,omitempty"`
+	Type     string               `db:"type" json:"type"`
+	Delivery NotificationDelivery `db:"delivery" json:"delivery" enum:"in_app,email,both,none"`
+	// DailyDigest also lists the unread notifications of the type, and those
+	// delivered by email only, in the daily digest email.
+	DailyDigest bool      `db:"daily_digest" json:"daily_digest"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// NotificationDigestItem keeps a notification delivered by email only, which
+// has no Notification, for the daily digest.
+type NotificationDigestItem struct {
+	_            struct{}  `db:"notification_digest_items" json:"-"`
+	ID           uuid.UUID `db:"id,pk" json:"id"`
+	TeamMemberID uuid.UUID `db:"team_member_id" json:"team_member_id"`
+	Type         string    `db:"type" json:"type"`
+	Payload      []byte    `db:"payload" json:"payload"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+}
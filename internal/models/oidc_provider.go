@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OidcProvider is an OpenID Connect provider added by an admin. Users sign in
+// with it under its name, like with the github or google providers.
+type OidcProvider struct {
+	_            struct{}  `db:"oidc_providers" json:"-"`
+	ID           uuid.UUID `db:"id,pk" json:"id"`
+	Name         string    `db:"name" json:"name"`
+	DisplayName  string    `db:"display_name" json:"display_name"`
+	Issuer       string    `db:"issuer" json:"issuer"`
+	ClientID     string    `db:"client_id" json:"client_id"`
+	ClientSecret string    `db:"client_secret" json:"-"`
+	Scopes       []string  `db:"scopes" json:"scopes"`
+	Enabled      bool      `db:"enabled" json:"enabled"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+}
@@ -90,6 +90,28 @@ type PermissionSource struct {
 	IsDirectly  bool        `db:"is_directly_assigned" json:"is_directly_assigned"`
 }
 
+type PermissionGrantSource string
+
+const (
+	PermissionGrantSourceDirect  PermissionGrantSource = "direct"
+	PermissionGrantSourceRole    PermissionGrantSource = "role"
+	PermissionGrantSourceProduct PermissionGrantSource = "product"
+)
+
+// PermissionGrant is one path granting a permission to a user: assigned
+// directly, through a role of the user, or through a product of an active
+// subscription of the user, with or without a role of the product.
+type PermissionGrant struct {
+	PermissionID   uuid.UUID             `db:"permission_id" json:"permission_id"`
+	Permission     string                `db:"permission" json:"permission"`
+	Source         PermissionGrantSource `db:"source" json:"source" enum:"direct,role,product"`
+	RoleID         *uuid.UUID            `db:"role_id" json:"role_id"`
+	RoleName       *string               `db:"role_name" json:"role_name"`
+	ProductID      *string               `db:"product_id" json:"product_id"`
+	ProductName    *string               `db:"product_name" json:"product_name"`
+	SubscriptionID *string               `db:"subscription_id" json:"subscription_id"`
+}
+
 type UserRole struct {
 	_      struct{}  `db:"user_roles" json:"-"`
 	UserID uuid.UUID `db:"user_id" json:"user_id"`
@@ -68,6 +68,26 @@ const (
 
 type TaskProjectStatus string
 
+// TaskPresenceState is what a team member is doing on a task project board.
+type TaskPresenceState string
+
+const (
+	TaskPresenceStateViewing TaskPresenceState = "viewing"
+	TaskPresenceStateEditing TaskPresenceState = "editing"
+)
+
+// TaskProjectPresence is a team member currently on a task project board,
+// TaskID is set while a single task is open. Rows past ExpiresAt are stale.
+type TaskProjectPresence struct {
+	_            struct{}          `db:"task_project_presences" json:"-"`
+	ProjectID    uuid.UUID         `db:"project_id" json:"project_id"`
+	TeamMemberID uuid.UUID         `db:"team_member_id" json:"team_member_id"`
+	TaskID       *uuid.UUID        `db:"task_id" json:"task_id,omitempty"`
+	State        TaskPresenceState `db:"state" json:"state" enum:"viewing,editing"`
+	ExpiresAt    time.Time         `db:"expires_at" json:"expires_at"`
+	UpdatedAt    time.Time         `db:"updated_at" json:"updated_at"`
+}
+
 type TaskStats struct {
 	TotalProjects     int64 `db:"total_projects" json:"total_projects"`
 	CompletedProjects int64 `db:"completed_projects" json:"completed_projects"`
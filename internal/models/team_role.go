@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Team permissions are granted to team members by team roles.
+const (
+	TeamPermissionTeamUpdate    = "team:update"
+	TeamPermissionTeamDelete    = "team:delete"
+	TeamPermissionMemberInvite  = "member:invite"
+	TeamPermissionRoleManage    = "role:manage"
+	TeamPermissionSsoManage     = "sso:manage"
+	TeamPermissionBillingManage = "billing:manage"
+	TeamPermissionProjectCreate = "project:create"
+	TeamPermissionProjectUpdate = "project:update"
+	TeamPermissionProjectDelete = "project:delete"
+	TeamPermissionTaskCreate    = "task:create"
+	TeamPermissionTaskUpdate    = "task:update"
+	TeamPermissionTaskDelete    = "task:delete"
+)
+
+// TeamPermissions are the permissions team roles can grant.
+var TeamPermissions = []string{
+	TeamPermissionTeamUpdate,
+	TeamPermissionTeamDelete,
+	TeamPermissionMemberInvite,
+	TeamPermissionRoleManage,
+	TeamPermissionSsoManage,
+	TeamPermissionBillingManage,
+	TeamPermissionProjectCreate,
+	TeamPermissionProjectUpdate,
+	TeamPermissionProjectDelete,
+	TeamPermissionTaskCreate,
+	TeamPermissionTaskUpdate,
+	TeamPermissionTaskDelete,
+}
+
+// TeamRole is a set of team permissions. Default roles have no team and are
+// named after the TeamMemberRole every member has, a role of a team with the
+// same name overrides the default for the team.
+type TeamRole struct {
+	_           struct{}   `db:"team_roles" json:"-"`
+	ID          uuid.UUID  `db:"id" json:"id"`
+	TeamID      *uuid.UUID `db:"team_id" json:"team_id"`
+	Name        string     `db:"name" json:"name"`
+	Description *string    `db:"description" json:"description"`
+	Permissions []string   `db:"permissions" json:"permissions"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// TeamRoleAssignment grants a role to a team member on top of the role of
+// the member, in the whole team or in one task project.
+type TeamRoleAssignment struct {
+	_             struct{}   `db:"team_role_assignments" json:"-"`
+	ID            uuid.UUID  `db:"id" json:"id"`
+	TeamMemberID  uuid.UUID  `db:"team_member_id" json:"team_member_id"`
+	TeamRoleID    uuid.UUID  `db:"team_role_id" json:"team_role_id"`
+	TaskProjectID *uuid.UUID `db:"task_project_id" json:"task_project_id"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// teamSsoProviderPrefix starts the provider of accounts signed in through the
+// IdP of a team.
+const teamSsoProviderPrefix = "sso:"
+
+// TeamSsoProvider is the provider of accounts signed in through the IdP of
+// the team.
+func TeamSsoProvider(teamID uuid.UUID) Providers {
+	return Providers(teamSsoProviderPrefix + teamID.String())
+}
+
+// ParseTeamSsoProvider returns the team of a team SSO provider.
+func ParseTeamSsoProvider(provider Providers) (uuid.UUID, bool) {
+	raw, ok := strings.CutPrefix(string(provider), teamSsoProviderPrefix)
+	if !ok {
+		return uuid.Nil, false
+	}
+	teamID, err := uuid.Parse(raw)
+	return teamID, err == nil
+}
+
+// TeamSsoConfig is the OIDC IdP members of a team sign in with.
+type TeamSsoConfig struct {
+	_            struct{}       `db:"team_sso_configs" json:"-"`
+	TeamID       uuid.UUID      `db:"team_id,pk" json:"team_id"`
+	Issuer       string         `db:"issuer" json:"issuer"`
+	ClientID     string         `db:"client_id" json:"client_id"`
+	ClientSecret string         `db:"client_secret" json:"-"`
+	Scopes       []string       `db:"scopes" json:"scopes"`
+	DefaultRole  TeamMemberRole `db:"default_role" json:"default_role" enum:"owner,member,guest"`
+	Enforced     bool           `db:"enforced" json:"enforced"`
+	CreatedAt    time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+// TeamSsoDomain is an email domain claimed by a team. Users of a verified
+// domain are members of the team when they sign in through its IdP.
+type TeamSsoDomain struct {
+	_                 struct{}   `db:"team_sso_domains" json:"-"`
+	ID                uuid.UUID  `db:"id,pk" json:"id"`
+	TeamID            uuid.UUID  `db:"team_id" json:"team_id"`
+	Domain            string     `db:"domain" json:"domain"`
+	VerificationToken string     `db:"verification_token" json:"verification_token"`
+	VerifiedAt        *time.Time `db:"verified_at" json:"verified_at"`
+	CreatedAt         time.Time  `db:"created_at" json:"created_at"`
+}
@@ -70,6 +70,10 @@ type UserInfo struct {
 	Roles       []string    `db:"roles" json:"roles"`
 	Permissions []string    `db:"permissions" json:"permissions"`
 	Providers   []Providers `db:"providers" json:"providers" enum:"google,apple,facebook,github,credentials"`
+	// SessionID is the session of the access token the info was read from.
+	SessionID uuid.UUID `db:"-" json:"-"`
+	// Impersonation is set when a superuser acts as the user.
+	Impersonation *Impersonation `db:"-" json:"-"`
 }
 type UserInfoTokens struct {
 	UserInfo
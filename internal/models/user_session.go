@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserSession is a sign in of a user on a device. Access and refresh tokens
+// carry its id, deleting it signs the device out.
+type UserSession struct {
+	ID           uuid.UUID `db:"id" json:"id"`
+	UserID       uuid.UUID `db:"user_id" json:"user_id"`
+	Expires      time.Time `db:"expires" json:"expires"`
+	SessionToken string    `db:"session_token" json:"-"`
+	UserAgent    string    `db:"user_agent" json:"user_agent"`
+	Device       string    `db:"device" json:"device"`
+	IPAddress    string    `db:"ip_address" json:"ip_address"`
+	City         string    `db:"city" json:"city"`
+	LastSeenAt   time.Time `db:"last_seen_at" json:"last_seen_at"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+}
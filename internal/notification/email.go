@@ -0,0 +1,67 @@
+package notification
+
+import (
+	"fmt"
+	"time"
+)
+
+// EmailData is what the email template of a notification is rendered with.
+// Payload is the NotificationPayload of the notification's kind.
+type EmailData struct {
+	AppName        string
+	AppUrl         string
+	Payload        any
+	UnsubscribeURL string
+}
+
+// DigestItem is one notification of a digest email.
+type DigestItem struct {
+	Type      string
+	Title     string
+	Body      string
+	CreatedAt time.Time
+}
+
+// DigestEmailData is what the daily digest email is rendered with.
+type DigestEmailData struct {
+	AppName        string
+	AppUrl         string
+	TeamName       string
+	Items          []DigestItem
+	UnsubscribeURL string
+}
+
+const emailFooter = `
+<p><a href="{{ .AppUrl }}">Open {{ .AppName }}</a></p>
+<p style="font-size:12px;color:#666"><a href="{{ .UnsubscribeURL }}">Unsubscribe</a> from these emails.</p>`
+
+var emailTemplates = map[string]string{
+	AssignedToTaskNotificationData{}.Kind(): `<h2>{{ .Payload.Notification.Title }}</h2>
+<p>{{ .Payload.Notification.Body }}</p>` + emailFooter,
+	NewTeamMemberNotificationData{}.Kind(): `<h2>{{ .Payload.Notification.Title }}</h2>
+<p>{{ .Payload.Notification.Body }}</p>
+<p>Say hello to {{ .Payload.Data.Email }}.</p>` + emailFooter,
+	TaskCompletedNotificationData{}.Kind(): `<h2>{{ .Payload.Notification.Title }}</h2>
+<p>{{ .Payload.Notification.Body }}</p>
+<p>Completed at {{ .Payload.Data.CompletedAt.Format "Jan 2, 2006 15:04 MST" }}.</p>` + emailFooter,
+	TaskDueTodayNotificationData{}.Kind(): `<h2>{{ .Payload.Notification.Title }}</h2>
+<p>{{ .Payload.Notification.Body }}</p>
+<p>Due {{ .Payload.Data.DueDate.Format "Jan 2, 2006 15:04 MST" }}.</p>` + emailFooter,
+}
+
+// EmailTemplate returns the email template of a notification kind.
+func EmailTemplate(kind string) (string, error) {
+	tmpl, ok := emailTemplates[kind]
+	if !ok {
+		return "", fmt.Errorf("no email template for notification kind %q", kind)
+	}
+	return tmpl, nil
+}
+
+const DigestEmailTemplate = `<h2>Your daily digest for {{ .TeamName }}</h2>
+<p>You have {{ len .Items }} unread notifications.</p>
+<ul>
+{{- range .Items }}
+<li><strong>{{ .Title }}</strong> {{ .Body }} <small>{{ .CreatedAt.Format "Jan 2 15:04" }}</small></li>
+{{- end }}
+</ul>` + emailFooter
@@ -1,5 +1,10 @@
 package notification
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 type NotificationContent struct {
 	Title string `json:"title"`
 	Body  string `json:"body"`
@@ -23,3 +28,49 @@ func NewNotificationPayload[T NotificationData](title, body string, data T) *Not
 		Data: data,
 	}
 }
+
+// Kinds returns the kind of every notification.
+func Kinds() []string {
+	return []string{
+		AssignedToTaskNotificationData{}.Kind(),
+		NewTeamMemberNotificationData{}.Kind(),
+		TaskCompletedNotificationData{}.Kind(),
+		TaskDueTodayNotificationData{}.Kind(),
+	}
+}
+
+// DecodePayload decodes a stored notification payload into the
+// NotificationPayload of its kind, so it is sent under the same event name as
+// when it was created.
+func DecodePayload(kind string, raw []byte) (any, error) {
+	switch kind {
+	case AssignedToTaskNotificationData{}.Kind():
+		return decodePayload[AssignedToTaskNotificationData](raw)
+	case NewTeamMemberNotificationData{}.Kind():
+		return decodePayload[NewTeamMemberNotificationData](raw)
+	case TaskCompletedNotificationData{}.Kind():
+		return decodePayload[TaskCompletedNotificationData](raw)
+	case TaskDueTodayNotificationData{}.Kind():
+		return decodePayload[TaskDueTodayNotificationData](raw)
+	}
+	return nil, fmt.Errorf("unknown notification kind %q", kind)
+}
+
+// DecodeContent decodes the title and body of a stored notification payload.
+func DecodeContent(raw []byte) (NotificationContent, error) {
+	var payload struct {
+		Notification NotificationContent `json:"notification"`
+	}
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return NotificationContent{}, err
+	}
+	return payload.Notification, nil
+}
+
+func decodePayload[T NotificationData](raw []byte) (*NotificationPayload[T], error) {
+	var payload NotificationPayload[T]
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, err
+	}
+	return &payload, nil
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/database"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/shared"
+	"github.com/tkahng/playground/internal/stores"
+)
+
+var (
+	ErrAccountNotFound = errors.New("account not found")
+	// ErrAccountAlreadyLinked is returned when the user already has another
+	// account of the provider.
+	ErrAccountAlreadyLinked = errors.New("an account of this provider is already linked")
+	// ErrAccountLinkedToOtherUser is returned when the provider account signs
+	// another user in.
+	ErrAccountLinkedToOtherUser = errors.New("this account is linked to another user")
+	// ErrLastSignInMethod refuses to unlink the only account of a user.
+	ErrLastSignInMethod = errors.New("cannot unlink the last sign in method")
+)
+
+// Accounts implements AuthService.
+func (app *BaseAuthService) Accounts(ctx context.Context, userId uuid.UUID) ([]*models.UserAccount, error) {
+	accounts, err := app.adapter.UserAccount().GetUserAccounts(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("error getting user accounts: %w", err)
+	}
+	if len(accounts) == 0 {
+		return nil, nil
+	}
+	return accounts[0], nil
+}
+
+// CreateLinkAccountUrl implements AuthService.
+func (app *BaseAuthService) CreateLinkAccountUrl(ctx context.Context, userId uuid.UUID, provider models.Providers, redirectUrl string) (string, error) {
+	return app.createOAuthUrl(ctx, &shared.ProviderStatePayload{
+		Intent:     shared.StateIntentLink,
+		UserId:     userId,
+		Provider:   provider,
+		RedirectTo: redirectUrl,
+	})
+}
+
+// LinkAccount implements AuthService. The email of the provider account does
+// not have to match the email of the user, linking it again is a no-op.
+func (app *BaseAuthService) LinkAccount(ctx context.Context, userId uuid.UUID, params *AuthenticationInput) (*models.User, error) {
+	user, err := app.adapter.User().FindUserByID(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("error finding user: %w", err)
+	}
+	if user == nil {
+		return nil, shared.ErrUserNotFound
+	}
+	account, err := app.adapter.UserAccount().FindUserAccount(ctx, &stores.UserAccountFilter{
+		UserIds:   []uuid.UUID{userId},
+		Providers: []models.Providers{params.Provider},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error finding user account: %w", err)
+	}
+	if account != nil {
+		if account.ProviderAccountID != params.ProviderAccountID {
+			return nil, ErrAccountAlreadyLinked
+		}
+		return user, nil
+	}
+	params.UserId = &userId
+	_, err = app.CreateAccountFromUser(ctx, params, app.adapter)
+	if err != nil {
+		if database.IsUniqConstraintErr(err) {
+			return nil, ErrAccountLinkedToOtherUser
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// UnlinkAccount implements AuthService. Only linked accounts count as ways to
+// sign in, a magic link proves the mailbox and is left for account recovery.
+// The accounts are locked while they are counted, concurrent unlinks of the
+// user count the accounts the other one left.
+func (app *BaseAuthService) UnlinkAccount(ctx context.Context, userId uuid.UUID, provider models.Providers) error {
+	return app.adapter.RunInTx(func(tx stores.StorageAdapterInterface) error {
+		accounts, err := tx.UserAccount().LockUserAccounts(ctx, userId)
+		if err != nil {
+			return err
+		}
+		var found bool
+		var remaining int
+		for _, account := range accounts {
+			switch {
+			case account.Provider == provider:
+				found = true
+			case account.Type == models.ProviderTypeOAuth:
+				remaining++
+			case account.Type == models.ProviderTypeCredentials && account.Password != nil:
+				remaining++
+			}
+		}
+		if !found {
+			return ErrAccountNotFound
+		}
+		if remaining == 0 {
+			return ErrLastSignInMethod
+		}
+		if err := tx.UserAccount().UnlinkAccount(ctx, userId, provider); err != nil {
+			return fmt.Errorf("error unlinking account: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkahng/playground/internal/models"
+)
+
+func linkInput(provider models.Providers, providerAccountID string) *AuthenticationInput {
+	return &AuthenticationInput{
+		Email:             "someone-else@example.com",
+		Provider:          provider,
+		Type:              models.ProviderTypeOAuth,
+		ProviderAccountID: providerAccountID,
+	}
+}
+
+func TestLinkAccount(t *testing.T) {
+	ctx := context.Background()
+	app := newTestAuthService()
+	user := &app.users.add("linked@example.com", nil).User
+	app.users.add("other@example.com", nil, &models.UserAccount{Provider: models.ProvidersGoogle, ProviderAccountID: "google-other"})
+
+	linked, err := app.LinkAccount(ctx, user.ID, linkInput(models.ProvidersGithub, "github-1"))
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, linked.ID, "the account belongs to the user of the flow, not its email")
+	linked, err = app.LinkAccount(ctx, user.ID, linkInput(models.ProvidersGithub, "github-1"))
+	require.NoError(t, err, "linking again is a no-op")
+	assert.Equal(t, user.ID, linked.ID)
+
+	_, err = app.LinkAccount(ctx, user.ID, linkInput(models.ProvidersGithub, "github-2"))
+	assert.ErrorIs(t, err, ErrAccountAlreadyLinked)
+	_, err = app.LinkAccount(ctx, user.ID, linkInput(models.ProvidersGoogle, "google-other"))
+	assert.ErrorIs(t, err, ErrAccountLinkedToOtherUser)
+
+	list, err := app.Accounts(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, models.ProvidersGithub, list[0].Provider)
+	assert.Empty(t, app.sessions.sessions, "linking does not sign the user in")
+}
+
+func TestUnlinkAccount(t *testing.T) {
+	ctx := context.Background()
+	app := newTestAuthService()
+	// a credentials account without a password cannot sign in
+	credentials := &models.UserAccount{Provider: models.ProvidersCredentials, Type: models.ProviderTypeCredentials}
+	user := &app.users.add("linked@example.com", nil,
+		&models.UserAccount{Provider: models.ProvidersGithub, Type: models.ProviderTypeOAuth, ProviderAccountID: "github-1"},
+		&models.UserAccount{Provider: models.ProvidersGoogle, Type: models.ProviderTypeOAuth, ProviderAccountID: "google-1"},
+		credentials,
+	).User
+
+	assert.ErrorIs(t, app.UnlinkAccount(ctx, user.ID, models.ProvidersApple), ErrAccountNotFound)
+	require.NoError(t, app.UnlinkAccount(ctx, user.ID, models.ProvidersGithub))
+	assert.ErrorIs(t, app.UnlinkAccount(ctx, user.ID, models.ProvidersGoogle), ErrLastSignInMethod)
+
+	password := "hashed"
+	credentials.Password = &password
+	require.NoError(t, app.UnlinkAccount(ctx, user.ID, models.ProvidersGoogle))
+	assert.ErrorIs(t, app.UnlinkAccount(ctx, user.ID, models.ProvidersCredentials), ErrLastSignInMethod)
+
+	list, err := app.Accounts(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, models.ProvidersCredentials, list[0].Provider)
+}
+
+func TestUnlinkAccount_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	app := newTestAuthService()
+	user := &app.users.add("linked@example.com", nil,
+		&models.UserAccount{Provider: models.ProvidersGithub, Type: models.ProviderTypeOAuth, ProviderAccountID: "github-1"},
+		&models.UserAccount{Provider: models.ProvidersGoogle, Type: models.ProviderTypeOAuth, ProviderAccountID: "google-1"},
+	).User
+
+	errs := make(chan error, 2)
+	for _, provider := range []models.Providers{models.ProvidersGithub, models.ProvidersGoogle} {
+		go func() {
+			errs <- app.UnlinkAccount(ctx, user.ID, provider)
+		}()
+	}
+	var refused int
+	for range 2 {
+		if err := <-errs; err != nil {
+			assert.ErrorIs(t, err, ErrLastSignInMethod)
+			refused++
+		}
+	}
+	assert.Equal(t, 1, refused, "one of the accounts is kept to sign in with")
+	list, err := app.Accounts(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+}
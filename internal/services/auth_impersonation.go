@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/contextstore"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/shared"
+	"github.com/tkahng/playground/internal/tools/types"
+)
+
+var (
+	ErrImpersonateSelf      = errors.New("cannot impersonate yourself")
+	ErrImpersonateSuperUser = errors.New("cannot impersonate a super user")
+	ErrNotImpersonating     = errors.New("not impersonating a user")
+	ErrAlreadyImpersonating = errors.New("already impersonating a user")
+	// ErrImpersonationEnded rejects the tokens of impersonations that ended
+	// or expired.
+	ErrImpersonationEnded = errors.New("impersonation ended")
+)
+
+// Impersonate implements AuthService. The access token lasts for the
+// ImpersonationToken duration and has no refresh token, it belongs to the
+// session of the superuser.
+func (app *BaseAuthService) Impersonate(ctx context.Context, impersonator *models.UserInfo, userId uuid.UUID) (*models.UserInfoTokens, error) {
+	if impersonator.Impersonation != nil {
+		return nil, ErrAlreadyImpersonating
+	}
+	if impersonator.User.ID == userId {
+		return nil, ErrImpersonateSelf
+	}
+	user, err := app.adapter.User().FindUserByID(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("error finding user: %w", err)
+	}
+	if user == nil {
+		return nil, shared.ErrUserNotFound
+	}
+	info, err := app.adapter.User().GetUserInfo(ctx, user.Email)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(info.Permissions, shared.PermissionNameAdmin) {
+		return nil, ErrImpersonateSuperUser
+	}
+	opts := app.config.AuthOptions
+	impersonation := &models.Impersonation{
+		ImpersonatorID: impersonator.User.ID,
+		UserID:         user.ID,
+		Expires:        opts.ImpersonationToken.Expires(),
+	}
+	if impersonator.SessionID != uuid.Nil {
+		impersonation.SessionID = &impersonator.SessionID
+	}
+	impersonation, err = app.adapter.Impersonation().CreateImpersonation(ctx, impersonation)
+	if err != nil {
+		return nil, fmt.Errorf("error creating impersonation: %w", err)
+	}
+	claims := shared.AuthenticationClaims{
+		Type: models.TokenTypesAccessToken,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(impersonation.Expires),
+		},
+		AuthenticationPayload: shared.AuthenticationPayload{
+			UserId:          info.User.ID,
+			Email:           info.User.Email,
+			Roles:           info.Roles,
+			Permissions:     info.Permissions,
+			SessionID:       impersonator.SessionID,
+			ImpersonatorId:  impersonation.ImpersonatorID,
+			ImpersonationId: impersonation.ID,
+		},
+	}
+	token, err := app.token.CreateJwtToken(claims, opts.AccessToken.Secret)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := app.auditImpersonation(ctx, impersonation, models.AuditLogImpersonationStarted, nil); err != nil {
+		return nil, err
+	}
+	info.Impersonation = impersonation
+	return &models.UserInfoTokens{
+		UserInfo: *info,
+		Tokens: models.TokenDto{
+			AccessToken: token,
+			ExpiresIn:   opts.ImpersonationToken.Duration,
+			TokenType:   "Bearer",
+		},
+	}, nil
+}
+
+// checkImpersonation rejects the tokens of impersonations that ended and of
+// revoked sessions of the superuser.
+func (app *BaseAuthService) checkImpersonation(ctx context.Context, claims *shared.AuthenticationClaims) (*models.Impersonation, error) {
+	impersonation, err := app.adapter.Impersonation().FindImpersonation(ctx, claims.ImpersonationId)
+	if err != nil {
+		return nil, err
+	}
+	if impersonation == nil || impersonation.UserID != claims.UserId || impersonation.ImpersonatorID != claims.ImpersonatorId {
+		return nil, ErrImpersonationEnded
+	}
+	if err := app.checkSession(ctx, claims.ImpersonatorId, claims.SessionID); err != nil {
+		return nil, err
+	}
+	return impersonation, nil
+}
+
+// EndImpersonation implements AuthService. The superuser gets tokens of
+// the session the impersonation started from.
+func (app *BaseAuthService) EndImpersonation(ctx context.Context, info *models.UserInfo) (*models.UserInfoTokens, error) {
+	if info.Impersonation == nil {
+		return nil, ErrNotImpersonating
+	}
+	impersonation, err := app.adapter.Impersonation().EndImpersonation(ctx, info.Impersonation.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error ending impersonation: %w", err)
+	}
+	if impersonation == nil {
+		return nil, ErrImpersonationEnded
+	}
+	if _, err := app.auditImpersonation(ctx, impersonation, models.AuditLogImpersonationEnded, nil); err != nil {
+		return nil, err
+	}
+	impersonator, err := app.adapter.User().GetUserInfo(ctx, impersonation.ImpersonatorEmail)
+	if err != nil {
+		return nil, err
+	}
+	if impersonation.SessionID == nil {
+		return app.CreateAuthTokens(ctx, impersonator)
+	}
+	return app.createSessionTokens(ctx, impersonator, *impersonation.SessionID)
+}
+
+// RecordImpersonatedRequest implements AuthService.
+func (app *BaseAuthService) RecordImpersonatedRequest(ctx context.Context, info *models.UserInfo, method string, path string) (uuid.UUID, error) {
+	if info.Impersonation == nil {
+		return uuid.Nil, ErrNotImpersonating
+	}
+	log, err := app.auditImpersonation(ctx, info.Impersonation, models.AuditLogImpersonationRequest, map[string]any{
+		"method": method,
+		"path":   path,
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return log.ID, nil
+}
+
+// CompleteImpersonatedRequest implements AuthService.
+func (app *BaseAuthService) CompleteImpersonatedRequest(ctx context.Context, logId uuid.UUID, status int) error {
+	err := app.adapter.AuditLog().UpdateAuditLogAttributes(ctx, logId, types.JSONMap[any]{"status": status})
+	if err != nil {
+		return fmt.Errorf("error updating audit log: %w", err)
+	}
+	return nil
+}
+
+// ListImpersonations implements AuthService.
+func (app *BaseAuthService) ListImpersonations(ctx context.Context, userId uuid.UUID) ([]*models.Impersonation, error) {
+	return app.adapter.Impersonation().ListUserImpersonations(ctx, userId)
+}
+
+// ImpersonationAuditLogs implements AuthService.
+func (app *BaseAuthService) ImpersonationAuditLogs(ctx context.Context, impersonationId uuid.UUID) ([]*models.AuditLog, error) {
+	return app.adapter.AuditLog().ListImpersonationAuditLogs(ctx, impersonationId)
+}
+
+// auditImpersonation writes an audit log of the superuser for the
+// impersonation.
+func (app *BaseAuthService) auditImpersonation(ctx context.Context, impersonation *models.Impersonation, event string, attributes map[string]any) (*models.AuditLog, error) {
+	log := &models.AuditLog{
+		Email:    types.Pointer(impersonation.ImpersonatorEmail),
+		AuditLog: event,
+		Attributes: types.JSONMap[any]{
+			"impersonation_id": impersonation.ID.String(),
+			"impersonator_id":  impersonation.ImpersonatorID.String(),
+			"user_id":          impersonation.UserID.String(),
+		},
+	}
+	if ip := contextstore.GetContextIPAddress(ctx); ip != "" {
+		log.IP = types.Pointer(ip)
+	}
+	for key, value := range attributes {
+		log.Attributes[key] = value
+	}
+	log, err := app.adapter.AuditLog().CreateAuditLog(ctx, log)
+	if err != nil {
+		return nil, fmt.Errorf("error writing audit log: %w", err)
+	}
+	return log, nil
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/shared"
+	"github.com/tkahng/playground/internal/tools/types"
+)
+
+// withImpersonations keeps impersonations in memory and their audit logs in
+// app.auditLogs.
+func withImpersonations() testAuthOption {
+	return func(app *testAuthService) {
+		impersonations := map[uuid.UUID]*models.Impersonation{}
+		app.decorators.ImpersonationFunc.CreateImpersonationFunc = func(ctx context.Context, impersonation *models.Impersonation) (*models.Impersonation, error) {
+			created := *impersonation
+			created.ID = uuid.New()
+			if impersonator := app.users.byID(created.ImpersonatorID); impersonator != nil {
+				created.ImpersonatorEmail = impersonator.Email
+			}
+			created.CreatedAt = time.Now()
+			impersonations[created.ID] = &created
+			return &created, nil
+		}
+		app.decorators.ImpersonationFunc.FindImpersonationFunc = func(ctx context.Context, id uuid.UUID) (*models.Impersonation, error) {
+			impersonation := impersonations[id]
+			if impersonation == nil || impersonation.EndedAt != nil || impersonation.Expires.Before(time.Now()) {
+				return nil, nil
+			}
+			return impersonation, nil
+		}
+		app.decorators.ImpersonationFunc.EndImpersonationFunc = func(ctx context.Context, id uuid.UUID) (*models.Impersonation, error) {
+			impersonation := impersonations[id]
+			if impersonation == nil || impersonation.EndedAt != nil {
+				return nil, nil
+			}
+			now := time.Now()
+			impersonation.EndedAt = &now
+			return impersonation, nil
+		}
+		app.decorators.AuditLogFunc.CreateAuditLogFunc = func(ctx context.Context, log *models.AuditLog) (*models.AuditLog, error) {
+			log.ID = uuid.New()
+			app.auditLogs = append(app.auditLogs, log)
+			return log, nil
+		}
+		app.decorators.AuditLogFunc.UpdateAuditLogAttributesFunc = func(ctx context.Context, id uuid.UUID, attributes types.JSONMap[any]) error {
+			for _, log := range app.auditLogs {
+				if log.ID == id {
+					maps.Copy(log.Attributes, attributes)
+				}
+			}
+			return nil
+		}
+	}
+}
+
+func TestImpersonation(t *testing.T) {
+	ctx := context.Background()
+	app := newTestAuthService(withImpersonations())
+	admin := app.users.add("admin@example.com", []string{shared.PermissionNameAdmin})
+	user := app.users.add("customer@example.com", []string{"basic"})
+
+	adminTokens, err := app.CreateAuthTokens(ctx, admin)
+	require.NoError(t, err)
+	adminInfo, err := app.HandleAccessToken(ctx, adminTokens.Tokens.AccessToken)
+	require.NoError(t, err)
+
+	_, err = app.Impersonate(ctx, adminInfo, admin.User.ID)
+	assert.ErrorIs(t, err, ErrImpersonateSelf)
+	_, err = app.Impersonate(ctx, adminInfo, uuid.New())
+	assert.ErrorIs(t, err, shared.ErrUserNotFound)
+
+	tokens, err := app.Impersonate(ctx, adminInfo, user.User.ID)
+	require.NoError(t, err)
+	assert.Empty(t, tokens.Tokens.RefreshToken, "impersonations cannot be refreshed")
+	assert.Equal(t, int64(900), tokens.Tokens.ExpiresIn)
+
+	info, err := app.HandleAccessToken(ctx, tokens.Tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, user.User.ID, info.User.ID)
+	assert.Equal(t, []string{"basic"}, info.Permissions)
+	require.NotNil(t, info.Impersonation)
+	assert.Equal(t, admin.User.ID, info.Impersonation.ImpersonatorID)
+	assert.Equal(t, adminInfo.SessionID, info.SessionID)
+
+	_, err = app.Impersonate(ctx, info, admin.User.ID)
+	assert.ErrorIs(t, err, ErrAlreadyImpersonating)
+
+	logId, err := app.RecordImpersonatedRequest(ctx, info, "GET", "/api/auth/me")
+	require.NoError(t, err)
+	require.NoError(t, app.CompleteImpersonatedRequest(ctx, logId, 200))
+	_, err = app.RecordImpersonatedRequest(ctx, adminInfo, "GET", "/api/auth/me")
+	assert.ErrorIs(t, err, ErrNotImpersonating)
+
+	restored, err := app.EndImpersonation(ctx, info)
+	require.NoError(t, err)
+	assert.Equal(t, admin.User.ID, restored.User.ID)
+	assert.NotEmpty(t, restored.Tokens.RefreshToken)
+	restoredInfo, err := app.HandleAccessToken(ctx, restored.Tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, adminInfo.SessionID, restoredInfo.SessionID, "the session of the superuser is restored")
+
+	_, err = app.HandleAccessToken(ctx, tokens.Tokens.AccessToken)
+	assert.ErrorIs(t, err, ErrImpersonationEnded)
+	_, err = app.EndImpersonation(ctx, info)
+	assert.ErrorIs(t, err, ErrImpersonationEnded)
+
+	require.Len(t, app.auditLogs, 3)
+	for _, log := range app.auditLogs {
+		assert.Equal(t, admin.User.Email, *log.Email)
+		assert.Equal(t, info.Impersonation.ID.String(), log.Attributes["impersonation_id"])
+	}
+	assert.Equal(t, models.AuditLogImpersonationStarted, app.auditLogs[0].AuditLog)
+	assert.Equal(t, models.AuditLogImpersonationRequest, app.auditLogs[1].AuditLog)
+	assert.Equal(t, "/api/auth/me", app.auditLogs[1].Attributes["path"])
+	assert.Equal(t, 200, app.auditLogs[1].Attributes["status"], "the status is added once the request is handled")
+	assert.Equal(t, models.AuditLogImpersonationEnded, app.auditLogs[2].AuditLog)
+}
+
+func TestImpersonation_SuperUsersAndRevokedSessions(t *testing.T) {
+	ctx := context.Background()
+	app := newTestAuthService(withImpersonations())
+	admin := app.users.add("admin@example.com", []string{shared.PermissionNameAdmin})
+	user := app.users.add("customer@example.com", []string{"basic"})
+	adminTokens, err := app.CreateAuthTokens(ctx, admin)
+	require.NoError(t, err)
+	adminInfo, err := app.HandleAccessToken(ctx, adminTokens.Tokens.AccessToken)
+	require.NoError(t, err)
+
+	user.Permissions = []string{shared.PermissionNameAdmin}
+	_, err = app.Impersonate(ctx, adminInfo, user.User.ID)
+	assert.ErrorIs(t, err, ErrImpersonateSuperUser)
+	user.Permissions = []string{"basic"}
+
+	tokens, err := app.Impersonate(ctx, adminInfo, user.User.ID)
+	require.NoError(t, err)
+	require.NoError(t, app.RevokeSession(ctx, admin.User.ID, adminInfo.SessionID))
+	_, err = app.HandleAccessToken(ctx, tokens.Tokens.AccessToken)
+	assert.ErrorIs(t, err, ErrSessionRevoked, "signing the superuser out ends the impersonation")
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/contextstore"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/shared"
+	"github.com/tkahng/playground/internal/tools/mailer"
+	"github.com/tkahng/playground/internal/workers"
+)
+
+// ErrLoginThrottled is matched by every LoginThrottledError.
+var ErrLoginThrottled = errors.New("too many attempts")
+
+// LoginThrottledError refuses an attempt until RetryAt, because the ip
+// address failed too often or the account is locked.
+type LoginThrottledError struct {
+	RetryAt time.Time
+	Locked  bool
+}
+
+func (e *LoginThrottledError) Error() string {
+	if e.Locked {
+		return "account locked after too many failed sign ins, try again later"
+	}
+	return "too many attempts, try again later"
+}
+
+func (e *LoginThrottledError) Is(target error) bool {
+	return target == ErrLoginThrottled
+}
+
+// RetryAfter is how long the client has to wait, at least a second.
+func (e *LoginThrottledError) RetryAfter() time.Duration {
+	return max(time.Until(e.RetryAt).Round(time.Second), time.Second)
+}
+
+// loginDelay is the wait after the given number of failures of an ip address,
+// it doubles with every failure past the free attempts.
+func (app *BaseAuthService) loginDelay(failures int) time.Duration {
+	opts := app.config.LoginThrottleConfig
+	if failures < opts.LoginFreeAttempts || opts.LoginDelay <= 0 {
+		return 0
+	}
+	delay := opts.LoginDelay
+	for range failures - opts.LoginFreeAttempts {
+		delay *= 2
+		if opts.LoginMaxDelay > 0 && delay >= opts.LoginMaxDelay {
+			return opts.LoginMaxDelay
+		}
+	}
+	return delay
+}
+
+// checkLoginAttempt refuses an attempt at the action for the email when the
+// ip address of the request has to wait or the account is locked.
+func (app *BaseAuthService) checkLoginAttempt(ctx context.Context, action models.LoginAttemptAction, email string) error {
+	opts := app.config.LoginThrottleConfig
+	if opts.LoginFailureWindow <= 0 {
+		return nil
+	}
+	email = strings.ToLower(email)
+	since := time.Now().Add(-opts.LoginFailureWindow)
+	attempt, err := app.adapter.LoginAttempt().FindLoginAttempt(ctx, action, email, contextstore.GetContextIPAddress(ctx), since)
+	if err != nil {
+		return fmt.Errorf("error finding login attempt: %w", err)
+	}
+	if attempt != nil {
+		retryAt := attempt.LastFailedAt.Add(app.loginDelay(attempt.Failures))
+		if time.Now().Before(retryAt) {
+			return &LoginThrottledError{RetryAt: retryAt}
+		}
+	}
+	if !lockable(action) || opts.LoginMaxFailures <= 0 {
+		return nil
+	}
+	lockedUntil, err := app.lockedUntil(ctx, action, email)
+	if err != nil {
+		return err
+	}
+	if lockedUntil != nil && time.Now().Before(*lockedUntil) {
+		return &LoginThrottledError{RetryAt: *lockedUntil, Locked: true}
+	}
+	return nil
+}
+
+// lockable reports whether failures of the action lock it for the email
+// across ip addresses, not only for the ip address that failed.
+func lockable(action models.LoginAttemptAction) bool {
+	return action == models.LoginAttemptActionSignin || action == models.LoginAttemptActionMagicLinkCode
+}
+
+// lockedUntil returns when the lock of the action for an account ends, nil
+// when the failures of all ip addresses are below the limit.
+func (app *BaseAuthService) lockedUntil(ctx context.Context, action models.LoginAttemptAction, email string) (*time.Time, error) {
+	opts := app.config.LoginThrottleConfig
+	since := time.Now().Add(-opts.LoginFailureWindow)
+	attempts, err := app.adapter.LoginAttempt().ListLoginAttempts(ctx, action, email, since)
+	if err != nil {
+		return nil, fmt.Errorf("error listing login attempts: %w", err)
+	}
+	var failures int
+	var last time.Time
+	for _, attempt := range attempts {
+		failures += attempt.Failures
+		if attempt.LastFailedAt.After(last) {
+			last = attempt.LastFailedAt
+		}
+	}
+	if failures < opts.LoginMaxFailures {
+		return nil, nil
+	}
+	until := last.Add(opts.LoginLockoutDuration)
+	return &until, nil
+}
+
+// recordLoginFailure counts a failed attempt of the ip address of the request.
+// The sign in that locks the account of a user emails them about it.
+func (app *BaseAuthService) recordLoginFailure(ctx context.Context, action models.LoginAttemptAction, email string, userID *uuid.UUID) error {
+	opts := app.config.LoginThrottleConfig
+	if opts.LoginFailureWindow <= 0 {
+		return nil
+	}
+	email = strings.ToLower(email)
+	ip := contextstore.GetContextIPAddress(ctx)
+	since := time.Now().Add(-opts.LoginFailureWindow)
+	_, err := app.adapter.LoginAttempt().RecordLoginFailure(ctx, action, email, ip, since)
+	if err != nil {
+		return fmt.Errorf("error recording login failure: %w", err)
+	}
+	if action != models.LoginAttemptActionSignin || opts.LoginMaxFailures <= 0 || userID == nil {
+		return nil
+	}
+	attempts, err := app.adapter.LoginAttempt().ListLoginAttempts(ctx, action, email, since)
+	if err != nil {
+		return fmt.Errorf("error listing login attempts: %w", err)
+	}
+	var failures int
+	for _, attempt := range attempts {
+		failures += attempt.Failures
+	}
+	if failures != opts.LoginMaxFailures {
+		return nil
+	}
+	slog.WarnContext(ctx, "account locked after failed sign ins",
+		slog.String("user_id", userID.String()),
+		slog.String("ip_address", ip),
+	)
+	err = app.jobService.EnqueueSecurityAlertJob(ctx, &workers.SecurityAlertJobArgs{
+		UserID:    *userID,
+		Alert:     mailer.SecurityAlertAccountLocked,
+		Device:    SessionDevice(contextstore.GetContextUserAgent(ctx)),
+		IPAddress: ip,
+		City:      sessionCity(ip),
+		At:        time.Now(),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "error enqueueing security alert", slog.Any("error", err))
+	}
+	return nil
+}
+
+// limitEmailRequest counts a request for an email to the address, the ip
+// address of the request is refused after LoginMaxRequests of them. Unlike
+// failed sign ins the requests never lock the account, so knowing an email
+// is not enough to keep its user from resetting their password.
+func (app *BaseAuthService) limitEmailRequest(ctx context.Context, action models.LoginAttemptAction, email string) error {
+	opts := app.config.LoginThrottleConfig
+	if opts.LoginFailureWindow <= 0 || opts.LoginMaxRequests <= 0 {
+		return nil
+	}
+	email = strings.ToLower(email)
+	ip := contextstore.GetContextIPAddress(ctx)
+	since := time.Now().Add(-opts.LoginFailureWindow)
+	attempt, err := app.adapter.LoginAttempt().FindLoginAttempt(ctx, action, email, ip, since)
+	if err != nil {
+		return fmt.Errorf("error finding login attempt: %w", err)
+	}
+	if attempt != nil && attempt.Failures >= opts.LoginMaxRequests {
+		return &LoginThrottledError{RetryAt: attempt.LastFailedAt.Add(opts.LoginFailureWindow)}
+	}
+	_, err = app.adapter.LoginAttempt().RecordLoginFailure(ctx, action, email, ip, since)
+	if err != nil {
+		return fmt.Errorf("error recording login attempt: %w", err)
+	}
+	return nil
+}
+
+// clearLoginFailures forgets the failures of the ip address of the request
+// after it succeeded.
+func (app *BaseAuthService) clearLoginFailures(ctx context.Context, action models.LoginAttemptAction, email string) error {
+	if app.config.LoginFailureWindow <= 0 {
+		return nil
+	}
+	ip := contextstore.GetContextIPAddress(ctx)
+	err := app.adapter.LoginAttempt().DeleteLoginAttempts(ctx, action, strings.ToLower(email), &ip)
+	if err != nil {
+		return fmt.Errorf("error deleting login attempts: %w", err)
+	}
+	return nil
+}
+
+// UnlockUser implements AuthService.
+func (app *BaseAuthService) UnlockUser(ctx context.Context, userId uuid.UUID) error {
+	user, err := app.adapter.User().FindUserByID(ctx, userId)
+	if err != nil {
+		return fmt.Errorf("error finding user: %w", err)
+	}
+	if user == nil {
+		return shared.ErrUserNotFound
+	}
+	for _, action := range []models.LoginAttemptAction{models.LoginAttemptActionSignin, models.LoginAttemptActionMagicLinkCode} {
+		err = app.adapter.LoginAttempt().DeleteLoginAttempts(ctx, action, strings.ToLower(user.Email), nil)
+		if err != nil {
+			return fmt.Errorf("error deleting login attempts: %w", err)
+		}
+	}
+	return nil
+}
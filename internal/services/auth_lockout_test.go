@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkahng/playground/internal/conf"
+	"github.com/tkahng/playground/internal/contextstore"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/shared"
+	"github.com/tkahng/playground/internal/tools/mailer"
+)
+
+func signinFrom(app *testAuthService, ip string, email string, password string) error {
+	ctx := contextstore.SetContextIPAddress(context.Background(), ip)
+	_, err := app.Authenticate(ctx, &AuthenticationInput{
+		Email:    email,
+		Password: &password,
+		Provider: models.ProvidersCredentials,
+		Type:     models.ProviderTypeCredentials,
+	})
+	return err
+}
+
+func TestLoginDelay(t *testing.T) {
+	app := &BaseAuthService{config: &conf.EnvConfig{LoginThrottleConfig: conf.LoginThrottleConfig{
+		LoginFreeAttempts: 2,
+		LoginDelay:        time.Second,
+		LoginMaxDelay:     4 * time.Second,
+	}}}
+	tests := map[int]time.Duration{
+		0:  0,
+		1:  0,
+		2:  time.Second,
+		3:  2 * time.Second,
+		4:  4 * time.Second,
+		10: 4 * time.Second,
+	}
+	for failures, delay := range tests {
+		assert.Equal(t, delay, app.loginDelay(failures), "%d failures", failures)
+	}
+}
+
+func TestLoginThrottle_ProgressiveDelay(t *testing.T) {
+	app := newTestAuthService(withLoginThrottle(conf.LoginThrottleConfig{
+		LoginFailureWindow: time.Minute,
+		LoginFreeAttempts:  2,
+		LoginDelay:         time.Minute,
+	}))
+	user := &app.users.add("locked@example.com", nil, credentialsAccount("correct-password")).User
+
+	for range 2 {
+		assert.ErrorIs(t, signinFrom(app, "10.0.0.1", user.Email, "wrong"), shared.ErrPasswordIncorrect)
+	}
+	err := signinFrom(app, "10.0.0.1", user.Email, "correct-password")
+	var throttled *LoginThrottledError
+	require.ErrorAs(t, err, &throttled)
+	assert.False(t, throttled.Locked)
+	assert.InDelta(t, time.Minute.Seconds(), throttled.RetryAfter().Seconds(), 1)
+
+	// other ip addresses are not slowed down, the email is not locked
+	assert.NoError(t, signinFrom(app, "10.0.0.2", user.Email, "correct-password"))
+}
+
+func TestLoginThrottle_Lockout(t *testing.T) {
+	app := newTestAuthService(withLoginThrottle(conf.LoginThrottleConfig{
+		LoginFailureWindow:   time.Minute,
+		LoginMaxFailures:     3,
+		LoginLockoutDuration: time.Minute,
+	}))
+	user := &app.users.add("locked@example.com", nil, credentialsAccount("correct-password")).User
+
+	// a success forgets the failures of its ip address
+	assert.Error(t, signinFrom(app, "10.0.0.1", user.Email, "wrong"))
+	assert.NoError(t, signinFrom(app, "10.0.0.1", user.Email, "correct-password"))
+
+	// failures of every ip address lock the account
+	for _, ip := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"} {
+		assert.ErrorIs(t, signinFrom(app, ip, "LOCKED@example.com", "wrong"), shared.ErrPasswordIncorrect)
+	}
+	err := signinFrom(app, "10.0.0.4", user.Email, "correct-password")
+	var throttled *LoginThrottledError
+	require.ErrorAs(t, err, &throttled)
+	assert.True(t, throttled.Locked)
+	assert.ErrorIs(t, err, ErrLoginThrottled)
+
+	require.Len(t, app.alerts, 1)
+	assert.Equal(t, user.ID, app.alerts[0].UserID)
+	assert.Equal(t, mailer.SecurityAlertAccountLocked, app.alerts[0].Alert)
+	assert.Equal(t, "10.0.0.3", app.alerts[0].IPAddress)
+
+	require.NoError(t, app.UnlockUser(context.Background(), user.ID))
+	assert.NoError(t, signinFrom(app, "10.0.0.4", user.Email, "correct-password"))
+}
+
+func TestLoginThrottle_EmailRequests(t *testing.T) {
+	app := newTestAuthService(withLoginThrottle(conf.LoginThrottleConfig{
+		LoginFailureWindow:   time.Minute,
+		LoginMaxRequests:     2,
+		LoginMaxFailures:     1,
+		LoginLockoutDuration: time.Minute,
+	}))
+	user := &app.users.add("locked@example.com", nil, credentialsAccount("correct-password")).User
+	ctx := contextstore.SetContextIPAddress(context.Background(), "10.0.0.1")
+
+	require.NoError(t, app.HandlePasswordResetRequest(ctx, user.Email))
+	require.NoError(t, app.HandlePasswordResetRequest(ctx, user.Email))
+	err := app.HandlePasswordResetRequest(ctx, user.Email)
+	var throttled *LoginThrottledError
+	require.ErrorAs(t, err, &throttled)
+	assert.False(t, throttled.Locked)
+	require.NoError(t, app.HandleMagicLinkRequest(ctx, user.Email), "magic links are counted apart")
+
+	// requests do not lock the user out
+	other := contextstore.SetContextIPAddress(context.Background(), "10.0.0.2")
+	assert.NoError(t, app.HandlePasswordResetRequest(other, user.Email))
+	assert.NoError(t, signinFrom(app, "10.0.0.1", user.Email, "correct-password"))
+	assert.Empty(t, app.alerts)
+}
+
+func TestLoginThrottle_UnknownEmails(t *testing.T) {
+	app := newTestAuthService(withLoginThrottle(conf.LoginThrottleConfig{
+		LoginFailureWindow:   time.Minute,
+		LoginMaxFailures:     2,
+		LoginLockoutDuration: time.Minute,
+	}))
+	// a user without a password fails like an email without a user
+	app.users.add("nopassword@example.com", nil, &models.UserAccount{Provider: models.ProvidersCredentials, Type: models.ProviderTypeCredentials})
+
+	for _, ip := range []string{"10.0.0.1", "10.0.0.2"} {
+		assert.Error(t, signinFrom(app, ip, "nopassword@example.com", "guess"))
+	}
+	assert.ErrorIs(t, signinFrom(app, "10.0.0.3", "nopassword@example.com", "guess"), ErrLoginThrottled)
+}
+
+func TestLoginThrottle_VerificationTokens(t *testing.T) {
+	app := newTestAuthService(withLoginThrottle(conf.LoginThrottleConfig{
+		LoginFailureWindow: time.Minute,
+		LoginFreeAttempts:  1,
+		LoginDelay:         time.Minute,
+	}))
+	ctx := contextstore.SetContextIPAddress(context.Background(), "10.0.0.1")
+
+	err := app.HandleVerificationToken(ctx, "guess")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrLoginThrottled)
+	assert.ErrorIs(t, app.HandleVerificationToken(ctx, "guess"), ErrLoginThrottled)
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/shared"
+	"github.com/tkahng/playground/internal/stores"
+	"github.com/tkahng/playground/internal/tools/mailer"
+	"github.com/tkahng/playground/internal/workers"
+)
+
+// ErrMagicLinkInvalid is returned for magic links and codes that are wrong,
+// expired or already used.
+var ErrMagicLinkInvalid = errors.New("invalid or expired magic link")
+
+// HandleMagicLinkRequest implements AuthService.
+func (app *BaseAuthService) HandleMagicLinkRequest(ctx context.Context, email string) error {
+	if err := app.limitEmailRequest(ctx, models.LoginAttemptActionMagicLink, email); err != nil {
+		return err
+	}
+	if _, err := app.checkTeamSso(ctx, &AuthenticationInput{Email: email}); err != nil {
+		return err
+	}
+	err := app.jobService.EnqueueOtpMailJob(ctx, &workers.OtpEmailJobArgs{
+		Type:  mailer.EmailTypeMagicLink,
+		Email: email,
+	})
+	if err != nil {
+		return fmt.Errorf("error sending magic link email: %w", err)
+	}
+	return nil
+}
+
+// HandleMagicLinkToken implements AuthService.
+func (app *BaseAuthService) HandleMagicLinkToken(ctx context.Context, token string) (*models.UserInfoTokens, error) {
+	if err := app.checkLoginAttempt(ctx, models.LoginAttemptActionVerify, ""); err != nil {
+		return nil, err
+	}
+	var claims shared.OtpClaims
+	err := app.token.ParseToken(token, app.config.MagicLinkToken, &claims)
+	if err != nil {
+		return nil, errors.Join(
+			ErrMagicLinkInvalid,
+			app.recordLoginFailure(ctx, models.LoginAttemptActionVerify, "", nil),
+		)
+	}
+	stored, err := app.adapter.Token().GetToken(ctx, claims.Token)
+	if err != nil || stored.Type != models.TokenTypesMagicLinkToken {
+		return nil, errors.Join(
+			ErrMagicLinkInvalid,
+			app.recordLoginFailure(ctx, models.LoginAttemptActionVerify, "", nil),
+		)
+	}
+	return app.redeemMagicLink(ctx, stored)
+}
+
+// HandleMagicLinkCode implements AuthService.
+func (app *BaseAuthService) HandleMagicLinkCode(ctx context.Context, email string, code string) (*models.UserInfoTokens, error) {
+	// codes are short, their failures lock the codes of the email like failed
+	// sign ins lock the password
+	if err := app.checkLoginAttempt(ctx, models.LoginAttemptActionMagicLinkCode, email); err != nil {
+		return nil, err
+	}
+	stored, err := app.adapter.Token().FindOtpToken(ctx, models.TokenTypesMagicLinkToken, email, code)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		return nil, errors.Join(ErrMagicLinkInvalid, app.recordLoginFailure(ctx, models.LoginAttemptActionMagicLinkCode, email, nil))
+	}
+	if err := app.clearLoginFailures(ctx, models.LoginAttemptActionMagicLinkCode, email); err != nil {
+		return nil, err
+	}
+	return app.redeemMagicLink(ctx, stored)
+}
+
+// redeemMagicLink uses up the token and signs its email in, signing it up
+// when it has no user yet. Like an oauth sign in, the link verifies the email.
+func (app *BaseAuthService) redeemMagicLink(ctx context.Context, stored *models.Token) (*models.UserInfoTokens, error) {
+	used, err := app.adapter.Token().UseToken(ctx, stored.Token)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, ErrMagicLinkInvalid
+	}
+	if err := app.adapter.Token().DeleteToken(ctx, stored.Token); err != nil {
+		return nil, fmt.Errorf("error deleting token: %w", err)
+	}
+	now := time.Now()
+	params := &AuthenticationInput{
+		Email:           stored.Identifier,
+		Type:            models.ProviderTypeOAuth,
+		EmailVerifiedAt: &now,
+	}
+	if _, err := app.checkTeamSso(ctx, params); err != nil {
+		return nil, err
+	}
+	user, err := app.adapter.User().FindUser(ctx, &stores.UserFilter{Emails: []string{stored.Identifier}})
+	if err != nil {
+		return nil, fmt.Errorf("error getting user: %w", err)
+	}
+	if user == nil {
+		user, err = app.adapter.User().CreateUser(ctx, &models.User{
+			Email:           stored.Identifier,
+			EmailVerifiedAt: &now,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error creating user: %w", err)
+		}
+	} else {
+		// someone may have signed up with the email and a password before
+		resetPassword, err := app.CheckAndResetCredentialsPassword(ctx, user, params, app.adapter)
+		if err != nil {
+			return nil, err
+		}
+		if err := app.UpdateUserEmailVerifiedAt(ctx, user, params, app.adapter); err != nil {
+			return nil, err
+		}
+		if resetPassword {
+			err := app.jobService.EnqueueOtpMailJob(ctx, &workers.OtpEmailJobArgs{
+				UserID: user.ID,
+				Type:   mailer.EmailTypeSecurityPasswordReset,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return app.CreateAuthTokensFromEmail(ctx, user.Email)
+}
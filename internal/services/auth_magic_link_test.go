@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkahng/playground/internal/conf"
+	"github.com/tkahng/playground/internal/contextstore"
+)
+
+var magicLinkPattern = regexp.MustCompile(`href="([^"]+)"`)
+
+// magicLink returns the token of the link and the code of the last email.
+func (c *captureMailer) magicLink(t *testing.T) (string, string) {
+	require.NotEmpty(t, c.messages)
+	body := c.messages[len(c.messages)-1].Body
+	match := magicLinkPattern.FindStringSubmatch(body)
+	require.Len(t, match, 2, body)
+	link, err := url.Parse(strings.ReplaceAll(match[1], "&amp;", "&"))
+	require.NoError(t, err)
+	code := body[strings.Index(body, "enter the code: ")+len("enter the code: "):]
+	return link.Query().Get("token"), code[:6]
+}
+
+func TestMagicLink_SignUp(t *testing.T) {
+	ctx := context.Background()
+	app := newTestAuthService()
+	mails := app.mails
+
+	require.NoError(t, app.HandleMagicLinkRequest(ctx, "new@example.com"))
+	require.Len(t, mails.messages, 1)
+	assert.Equal(t, "new@example.com", mails.messages[0].To)
+	assert.Contains(t, mails.messages[0].Body, "https://app.example.com/magic-link?token=")
+	assert.Nil(t, app.users.byEmail("new@example.com"), "users are created when the link is redeemed")
+
+	token, _ := mails.magicLink(t)
+	tokens, err := app.HandleMagicLinkToken(ctx, token)
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.Tokens.AccessToken)
+	assert.Equal(t, "new@example.com", tokens.User.Email)
+	created := app.users.byEmail("new@example.com")
+	require.NotNil(t, created)
+	assert.NotNil(t, created.EmailVerifiedAt)
+
+	// links are used once
+	_, err = app.HandleMagicLinkToken(ctx, token)
+	assert.ErrorIs(t, err, ErrMagicLinkInvalid)
+	_, err = app.HandleMagicLinkToken(ctx, "not-a-token")
+	assert.ErrorIs(t, err, ErrMagicLinkInvalid)
+}
+
+func TestMagicLink_Code(t *testing.T) {
+	ctx := context.Background()
+	app := newTestAuthService()
+	mails := app.mails
+	existing := app.users.add("existing@example.com", nil)
+
+	require.NoError(t, app.HandleMagicLinkRequest(ctx, "existing@example.com"))
+	_, code := mails.magicLink(t)
+	assert.Len(t, code, 6)
+
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+	_, err := app.HandleMagicLinkCode(ctx, "existing@example.com", wrong)
+	assert.ErrorIs(t, err, ErrMagicLinkInvalid)
+	_, err = app.HandleMagicLinkCode(ctx, "other@example.com", code)
+	assert.ErrorIs(t, err, ErrMagicLinkInvalid)
+
+	tokens, err := app.HandleMagicLinkCode(ctx, "Existing@example.com", code)
+	require.NoError(t, err)
+	assert.Equal(t, existing.User.ID, tokens.User.ID)
+	assert.NotNil(t, app.users.byEmail("existing@example.com").EmailVerifiedAt)
+
+	_, err = app.HandleMagicLinkCode(ctx, "existing@example.com", code)
+	assert.ErrorIs(t, err, ErrMagicLinkInvalid)
+}
+
+func TestMagicLink_NewRequestReplacesCode(t *testing.T) {
+	ctx := context.Background()
+	app := newTestAuthService()
+	mails := app.mails
+	app.users.add("existing@example.com", nil)
+
+	require.NoError(t, app.HandleMagicLinkRequest(ctx, "existing@example.com"))
+	_, first := mails.magicLink(t)
+	require.NoError(t, app.HandleMagicLinkRequest(ctx, "Existing@example.com"))
+	_, second := mails.magicLink(t)
+	assert.Len(t, app.sessions.tokens, 1, "only the latest link is kept")
+
+	if first != second {
+		_, err := app.HandleMagicLinkCode(ctx, "existing@example.com", first)
+		assert.ErrorIs(t, err, ErrMagicLinkInvalid)
+	}
+	_, err := app.HandleMagicLinkCode(ctx, "existing@example.com", second)
+	assert.NoError(t, err)
+}
+
+func TestMagicLink_CodesAreThrottled(t *testing.T) {
+	ctx := context.Background()
+	app := newTestAuthService(withLoginThrottle(conf.LoginThrottleConfig{
+		LoginFailureWindow:   time.Minute,
+		LoginMaxFailures:     2,
+		LoginLockoutDuration: time.Minute,
+	}))
+	mails := app.mails
+	app.users.add("guessed@example.com", nil, credentialsAccount("correct-password"))
+
+	require.NoError(t, app.HandleMagicLinkRequest(ctx, "guessed@example.com"))
+	_, code := mails.magicLink(t)
+	var guesses int
+	for _, guess := range []string{"000000", "111111", "222222"} {
+		if guess != code && guesses < 2 {
+			guesses++
+			_, err := app.HandleMagicLinkCode(ctx, "guessed@example.com", guess)
+			assert.ErrorIs(t, err, ErrMagicLinkInvalid)
+		}
+	}
+	_, err := app.HandleMagicLinkCode(ctx, "guessed@example.com", code)
+	assert.ErrorIs(t, err, ErrLoginThrottled)
+	// the codes of the email are locked from every ip address
+	_, err = app.HandleMagicLinkCode(contextstore.SetContextIPAddress(ctx, "10.0.0.2"), "guessed@example.com", code)
+	assert.ErrorIs(t, err, ErrLoginThrottled)
+
+	// wrong codes do not lock the password
+	assert.NoError(t, signinFrom(app, "10.0.0.2", "guessed@example.com", "correct-password"))
+}
@@ -0,0 +1,490 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/conf"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/shared"
+	"github.com/tkahng/playground/internal/stores"
+	"github.com/tkahng/playground/internal/tools/mailer"
+	"github.com/tkahng/playground/internal/workers"
+)
+
+// testAuthService is an auth service on in memory sessions, users and login
+// attempts. Its fields let tests seed the stores and read what was recorded.
+type testAuthService struct {
+	*BaseAuthService
+	decorators *stores.StorageAdapterDecorator
+	jobs       *JobServiceDecorator
+	sessions   *memorySessions
+	users      *memoryUsers
+	mails      *captureMailer
+	alerts     []*workers.SecurityAlertJobArgs
+	auditLogs  []*models.AuditLog
+}
+
+type testAuthOption func(*testAuthService)
+
+// withLoginThrottle throttles failed sign ins like the config does.
+func withLoginThrottle(throttle conf.LoginThrottleConfig) testAuthOption {
+	return func(app *testAuthService) {
+		app.config.LoginThrottleConfig = throttle
+	}
+}
+
+// newTestAuthService returns an auth service without team SSO whose magic
+// link mails are sent right away to app.mails. Passwords are stored as is.
+func newTestAuthService(opts ...testAuthOption) *testAuthService {
+	adapter := stores.NewAdapterDecorators()
+	adapter.TeamSsoFunc.FindVerifiedTeamSsoDomainFunc = func(ctx context.Context, domain string) (*models.TeamSsoDomain, error) {
+		return nil, nil
+	}
+	passwords := NewPasswordServiceDecorator()
+	passwords.HashPasswordFunc = func(password string) (string, error) {
+		return password, nil
+	}
+	passwords.VerifyPasswordFunc = func(hashedPassword, password string) (bool, error) {
+		return hashedPassword == password, nil
+	}
+	config := conf.NewEnvConfig()
+	config.AppUrl = "https://app.example.com"
+	app := &testAuthService{
+		decorators: adapter,
+		jobs:       NewJobServiceDecorator(nil),
+		sessions:   newMemorySessions(adapter),
+		users:      newMemoryUsers(adapter),
+		mails:      &captureMailer{},
+	}
+	newMemoryLoginAttempts(adapter)
+	app.BaseAuthService = &BaseAuthService{
+		adapter:    adapter,
+		password:   passwords,
+		token:      NewJwtService(),
+		config:     config,
+		jobService: app.jobs,
+	}
+	mail := &DbOtpMailService{options: config, adapter: adapter, mail: app.mails, token: NewJwtService()}
+	app.jobs.EnqueueOtpMailJobFunc = func(ctx context.Context, args *workers.OtpEmailJobArgs) error {
+		if args.Type == mailer.EmailTypeMagicLink {
+			return mail.SendMagicLinkEmail(ctx, args.Email)
+		}
+		return nil
+	}
+	var mu sync.Mutex
+	app.jobs.EnqueueSecurityAlertJobFunc = func(ctx context.Context, args *workers.SecurityAlertJobArgs) error {
+		mu.Lock()
+		defer mu.Unlock()
+		app.alerts = append(app.alerts, args)
+		return nil
+	}
+	for _, opt := range opts {
+		opt(app)
+	}
+	return app
+}
+
+type captureMailer struct {
+	messages []*mailer.Message
+}
+
+func (c *captureMailer) Send(message *mailer.Message) error {
+	c.messages = append(c.messages, message)
+	return nil
+}
+
+// memorySessions keeps the sessions and tokens of an adapter in memory,
+// deleting a session deletes its tokens like the foreign key does.
+type memorySessions struct {
+	mu       sync.Mutex
+	sessions map[uuid.UUID]*models.UserSession
+	tokens   map[string]*models.Token
+}
+
+func newMemorySessions(adapter *stores.StorageAdapterDecorator) *memorySessions {
+	m := &memorySessions{
+		sessions: map[uuid.UUID]*models.UserSession{},
+		tokens:   map[string]*models.Token{},
+	}
+	adapter.UserSessionFunc.CreateUserSessionFunc = func(ctx context.Context, session *models.UserSession) (*models.UserSession, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		created := *session
+		created.ID = uuid.New()
+		created.LastSeenAt = time.Now()
+		m.sessions[created.ID] = &created
+		return &created, nil
+	}
+	adapter.UserSessionFunc.FindUserSessionFunc = func(ctx context.Context, id uuid.UUID) (*models.UserSession, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		return m.sessions[id], nil
+	}
+	adapter.UserSessionFunc.TouchUserSessionFunc = func(ctx context.Context, id uuid.UUID) error {
+		return nil
+	}
+	adapter.UserSessionFunc.ExtendUserSessionFunc = func(ctx context.Context, id uuid.UUID, expires time.Time) (*models.UserSession, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		session := m.sessions[id]
+		if session != nil {
+			session.Expires = expires
+		}
+		return session, nil
+	}
+	adapter.UserSessionFunc.ListUserSessionsFunc = func(ctx context.Context, userID uuid.UUID) ([]*models.UserSession, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		var sessions []*models.UserSession
+		for _, session := range m.sessions {
+			if session.UserID == userID {
+				sessions = append(sessions, session)
+			}
+		}
+		return sessions, nil
+	}
+	adapter.UserSessionFunc.DeleteUserSessionFunc = func(ctx context.Context, userID uuid.UUID, id uuid.UUID) (bool, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		session := m.sessions[id]
+		if session == nil || session.UserID != userID {
+			return false, nil
+		}
+		m.delete(id)
+		return true, nil
+	}
+	adapter.UserSessionFunc.DeleteUserSessionsFunc = func(ctx context.Context, userID uuid.UUID) error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for id, session := range m.sessions {
+			if session.UserID == userID {
+				m.delete(id)
+			}
+		}
+		return nil
+	}
+	adapter.TokenFunc.SaveTokenFunc = func(ctx context.Context, token *stores.CreateTokenDTO) error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.tokens[token.Token] = &models.Token{Type: token.Type, Token: token.Token, Identifier: token.Identifier, Otp: token.Otp, UserID: token.UserID, SessionID: token.SessionID, Expires: token.Expires, CreatedAt: time.Now()}
+		return nil
+	}
+	adapter.TokenFunc.GetTokenFunc = func(ctx context.Context, token string) (*models.Token, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if t, ok := m.tokens[token]; ok {
+			stored := *t
+			return &stored, nil
+		}
+		return nil, shared.ErrTokenNotFound
+	}
+	adapter.TokenFunc.UseTokenFunc = func(ctx context.Context, token string) (bool, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		t, ok := m.tokens[token]
+		if !ok || t.UsedAt != nil {
+			return false, nil
+		}
+		now := time.Now()
+		t.UsedAt = &now
+		return true, nil
+	}
+	adapter.TokenFunc.DeleteTokenFunc = func(ctx context.Context, token string) error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.tokens, token)
+		return nil
+	}
+	adapter.TokenFunc.FindOtpTokenFunc = func(ctx context.Context, tokenType models.TokenTypes, identifier string, otp string) (*models.Token, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for _, token := range m.tokens {
+			if token.Type == tokenType && strings.EqualFold(token.Identifier, identifier) && token.Otp != nil && *token.Otp == otp {
+				found := *token
+				return &found, nil
+			}
+		}
+		return nil, nil
+	}
+	adapter.TokenFunc.FindNextSessionTokenFunc = func(ctx context.Context, sessionID uuid.UUID, tokenType models.TokenTypes, createdAfter time.Time) (*models.Token, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		var next *models.Token
+		for _, t := range m.tokens {
+			if t.SessionID != nil && *t.SessionID == sessionID && t.Type == tokenType && t.CreatedAt.After(createdAfter) &&
+				(next == nil || t.CreatedAt.Before(next.CreatedAt)) {
+				next = t
+			}
+		}
+		if next == nil {
+			return nil, nil
+		}
+		found := *next
+		return &found, nil
+	}
+	adapter.TokenFunc.DeleteUsedSessionTokensFunc = func(ctx context.Context, sessionID uuid.UUID, tokenType models.TokenTypes, usedBefore time.Time) error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for key, t := range m.tokens {
+			if t.SessionID != nil && *t.SessionID == sessionID && t.Type == tokenType && t.UsedAt != nil && t.UsedAt.Before(usedBefore) {
+				delete(m.tokens, key)
+			}
+		}
+		return nil
+	}
+	adapter.TokenFunc.DeleteIdentifierTokensFunc = func(ctx context.Context, tokenType models.TokenTypes, identifier string) error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for key, t := range m.tokens {
+			if t.Type == tokenType && strings.EqualFold(t.Identifier, identifier) {
+				delete(m.tokens, key)
+			}
+		}
+		return nil
+	}
+	// like the row lock of UseToken, refreshes of one token run one by one
+	var txMu sync.Mutex
+	adapter.RunInTxFunc = func(fn func(tx stores.StorageAdapterInterface) error) error {
+		txMu.Lock()
+		defer txMu.Unlock()
+		return fn(adapter)
+	}
+	return m
+}
+
+func (m *memorySessions) delete(id uuid.UUID) {
+	delete(m.sessions, id)
+	for key, token := range m.tokens {
+		if token.SessionID != nil && *token.SessionID == id {
+			delete(m.tokens, key)
+		}
+	}
+}
+
+// memoryUsers keeps the users of an adapter and their accounts in memory,
+// like the database emails are unique regardless of case and a provider
+// account belongs to a single user.
+type memoryUsers struct {
+	mu       sync.Mutex
+	users    []*models.UserInfo
+	accounts []*models.UserAccount
+}
+
+func newMemoryUsers(adapter *stores.StorageAdapterDecorator) *memoryUsers {
+	m := &memoryUsers{}
+	adapter.UserFunc.FindUserFunc = func(ctx context.Context, filter *stores.UserFilter) (*models.User, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for _, info := range m.users {
+			if (len(filter.Emails) == 0 || strings.EqualFold(info.User.Email, filter.Emails[0])) &&
+				(len(filter.Ids) == 0 || info.User.ID == filter.Ids[0]) {
+				user := info.User
+				return &user, nil
+			}
+		}
+		return nil, nil
+	}
+	adapter.UserFunc.FindUserByIDFunc = func(ctx context.Context, id uuid.UUID) (*models.User, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for _, info := range m.users {
+			if info.User.ID == id {
+				user := info.User
+				return &user, nil
+			}
+		}
+		return nil, nil
+	}
+	adapter.UserFunc.CreateUserFunc = func(ctx context.Context, user *models.User) (*models.User, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		created := *user
+		created.ID = uuid.New()
+		m.users = append(m.users, &models.UserInfo{User: created})
+		return &created, nil
+	}
+	adapter.UserFunc.UpdateUserFunc = func(ctx context.Context, user *models.User) error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for _, info := range m.users {
+			if info.User.ID == user.ID {
+				info.User = *user
+			}
+		}
+		return nil
+	}
+	adapter.UserFunc.GetUserInfoFunc = func(ctx context.Context, email string) (*models.UserInfo, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for _, info := range m.users {
+			if strings.EqualFold(info.User.Email, email) {
+				found := *info
+				return &found, nil
+			}
+		}
+		return nil, shared.ErrUserNotFound
+	}
+	adapter.UserAccountFunc.GetUserAccountsFunc = func(ctx context.Context, userIds ...uuid.UUID) ([][]*models.UserAccount, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		var found []*models.UserAccount
+		for _, account := range m.accounts {
+			if account.UserID == userIds[0] {
+				found = append(found, account)
+			}
+		}
+		return [][]*models.UserAccount{found}, nil
+	}
+	adapter.UserAccountFunc.FindUserAccountFunc = func(ctx context.Context, filter *stores.UserAccountFilter) (*models.UserAccount, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for _, account := range m.accounts {
+			if account.UserID == filter.UserIds[0] && account.Provider == filter.Providers[0] {
+				return account, nil
+			}
+		}
+		return nil, nil
+	}
+	adapter.UserAccountFunc.CreateUserAccountFunc = func(ctx context.Context, account *models.UserAccount) (*models.UserAccount, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for _, existing := range m.accounts {
+			if existing.Provider == account.Provider && existing.ProviderAccountID == account.ProviderAccountID {
+				return nil, errors.New(`duplicate key value violates unique constraint (SQLSTATE 23505)`)
+			}
+		}
+		created := *account
+		created.ID = uuid.New()
+		m.accounts = append(m.accounts, &created)
+		return &created, nil
+	}
+	adapter.UserAccountFunc.LockUserAccountsFunc = func(ctx context.Context, userId uuid.UUID) ([]*models.UserAccount, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		var found []*models.UserAccount
+		for _, account := range m.accounts {
+			if account.UserID == userId {
+				found = append(found, account)
+			}
+		}
+		return found, nil
+	}
+	adapter.UserAccountFunc.UnlinkAccountFunc = func(ctx context.Context, userId uuid.UUID, provider models.Providers) error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		var kept []*models.UserAccount
+		for _, account := range m.accounts {
+			if account.UserID != userId || account.Provider != provider {
+				kept = append(kept, account)
+			}
+		}
+		m.accounts = kept
+		return nil
+	}
+	return m
+}
+
+// add stores the user with its accounts and returns it, later changes to it
+// are seen by the stores.
+func (m *memoryUsers) add(email string, permissions []string, accounts ...*models.UserAccount) *models.UserInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	info := &models.UserInfo{User: models.User{ID: uuid.New(), Email: email}, Permissions: permissions}
+	m.users = append(m.users, info)
+	for _, account := range accounts {
+		account.UserID = info.User.ID
+		m.accounts = append(m.accounts, account)
+	}
+	return info
+}
+
+// credentialsAccount returns an account signing in with the password.
+func credentialsAccount(password string) *models.UserAccount {
+	return &models.UserAccount{Provider: models.ProvidersCredentials, Type: models.ProviderTypeCredentials, Password: &password}
+}
+
+// byID returns the user with the id, nil when there is none.
+func (m *memoryUsers) byID(id uuid.UUID) *models.User {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, info := range m.users {
+		if info.User.ID == id {
+			return &info.User
+		}
+	}
+	return nil
+}
+
+// byEmail returns the user with the email, nil when there is none.
+func (m *memoryUsers) byEmail(email string) *models.User {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, info := range m.users {
+		if strings.EqualFold(info.User.Email, email) {
+			return &info.User
+		}
+	}
+	return nil
+}
+
+// memoryLoginAttempts keeps the login attempts of an adapter in memory.
+type memoryLoginAttempts struct {
+	mu       sync.Mutex
+	attempts map[[3]string]*models.LoginAttempt
+}
+
+func newMemoryLoginAttempts(adapter *stores.StorageAdapterDecorator) *memoryLoginAttempts {
+	m := &memoryLoginAttempts{attempts: map[[3]string]*models.LoginAttempt{}}
+	adapter.LoginAttemptFunc.FindLoginAttemptFunc = func(ctx context.Context, action models.LoginAttemptAction, email string, ipAddress string, since time.Time) (*models.LoginAttempt, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		attempt := m.attempts[[3]string{string(action), email, ipAddress}]
+		if attempt == nil || !attempt.LastFailedAt.After(since) {
+			return nil, nil
+		}
+		found := *attempt
+		return &found, nil
+	}
+	adapter.LoginAttemptFunc.ListLoginAttemptsFunc = func(ctx context.Context, action models.LoginAttemptAction, email string, since time.Time) ([]*models.LoginAttempt, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		var attempts []*models.LoginAttempt
+		for _, attempt := range m.attempts {
+			if attempt.Action == action && attempt.Email == email && attempt.LastFailedAt.After(since) {
+				found := *attempt
+				attempts = append(attempts, &found)
+			}
+		}
+		return attempts, nil
+	}
+	adapter.LoginAttemptFunc.RecordLoginFailureFunc = func(ctx context.Context, action models.LoginAttemptAction, email string, ipAddress string, since time.Time) (*models.LoginAttempt, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		key := [3]string{string(action), email, ipAddress}
+		attempt := m.attempts[key]
+		if attempt == nil || !attempt.LastFailedAt.After(since) {
+			attempt = &models.LoginAttempt{Action: action, Email: email, IPAddress: ipAddress, CreatedAt: time.Now()}
+			m.attempts[key] = attempt
+		}
+		attempt.Failures++
+		attempt.LastFailedAt = time.Now()
+		found := *attempt
+		return &found, nil
+	}
+	adapter.LoginAttemptFunc.DeleteLoginAttemptsFunc = func(ctx context.Context, action models.LoginAttemptAction, email string, ipAddress *string) error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for key, attempt := range m.attempts {
+			if attempt.Action == action && attempt.Email == email && (ipAddress == nil || attempt.IPAddress == *ipAddress) {
+				delete(m.attempts, key)
+			}
+		}
+		return nil
+	}
+	return m
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -35,13 +37,55 @@ type AuthService interface {
 	HandleVerificationToken(ctx context.Context, token string) error
 	HandlePasswordResetToken(ctx context.Context, token, password string) error
 	HandleCheckResetPasswordToken(ctx context.Context, token string) error
+	// HandleMagicLinkRequest emails a sign in link and code, to users that do
+	// not exist yet too.
+	HandleMagicLinkRequest(ctx context.Context, email string) error
+	// HandleMagicLinkToken signs in with the link of a magic link email.
+	HandleMagicLinkToken(ctx context.Context, token string) (*models.UserInfoTokens, error)
+	// HandleMagicLinkCode signs in with the code of a magic link email.
+	HandleMagicLinkCode(ctx context.Context, email string, code string) (*models.UserInfoTokens, error)
 	Signout(ctx context.Context, token string) error
 	ResetPassword(ctx context.Context, userId uuid.UUID, oldPassword, newPassword string) error
+	Sessions(ctx context.Context, userId uuid.UUID) ([]*models.UserSession, error)
+	// RevokeSession signs a device of the user out, its access tokens are
+	// rejected from the next request on.
+	RevokeSession(ctx context.Context, userId uuid.UUID, sessionId uuid.UUID) error
+	// RevokeSessions signs the user out everywhere.
+	RevokeSessions(ctx context.Context, userId uuid.UUID) error
+	// UnlockUser forgets the failed sign ins of a user, lifting the lock of
+	// the account.
+	UnlockUser(ctx context.Context, userId uuid.UUID) error
+	// Accounts lists the sign in methods linked to the user.
+	Accounts(ctx context.Context, userId uuid.UUID) ([]*models.UserAccount, error)
+	// CreateLinkAccountUrl starts an oauth flow that links the provider
+	// account to the user instead of signing in.
+	CreateLinkAccountUrl(ctx context.Context, userId uuid.UUID, provider models.Providers, redirectUrl string) (string, error)
+	// LinkAccount attaches the provider account of params to the user.
+	LinkAccount(ctx context.Context, userId uuid.UUID, params *AuthenticationInput) (*models.User, error)
+	// UnlinkAccount removes a sign in method, unless it is the last one.
+	UnlinkAccount(ctx context.Context, userId uuid.UUID, provider models.Providers) error
+	// Impersonate issues a short lived access token acting as the user, on
+	// behalf of the superuser impersonator.
+	Impersonate(ctx context.Context, impersonator *models.UserInfo, userId uuid.UUID) (*models.UserInfoTokens, error)
+	// EndImpersonation ends the impersonation of info and restores the
+	// session of the superuser.
+	EndImpersonation(ctx context.Context, info *models.UserInfo) (*models.UserInfoTokens, error)
+	// RecordImpersonatedRequest writes a request made while impersonating to
+	// the audit log before it is handled and returns the id of the entry.
+	RecordImpersonatedRequest(ctx context.Context, info *models.UserInfo, method string, path string) (uuid.UUID, error)
+	// CompleteImpersonatedRequest adds the response status to the audit log
+	// entry of a recorded request.
+	CompleteImpersonatedRequest(ctx context.Context, logId uuid.UUID, status int) error
+	ListImpersonations(ctx context.Context, userId uuid.UUID) ([]*models.Impersonation, error)
+	ImpersonationAuditLogs(ctx context.Context, impersonationId uuid.UUID) ([]*models.AuditLog, error)
 
 	// methods -----------------------------------------------------------------------------------------------------------
 
 	VerifyStateToken(ctx context.Context, token string) (*shared.ProviderStateClaims, error)
 	CreateOAuthUrl(ctx context.Context, provider models.Providers, redirectUrl string) (string, error)
+	// CreateSsoUrl starts a sign in through the IdP of the team that verified
+	// the domain of the email.
+	CreateSsoUrl(ctx context.Context, email string, redirectUrl string) (string, error)
 	CreateAndPersistStateToken(ctx context.Context, payload *shared.ProviderStatePayload) (string, error)
 	FetchAuthUser(ctx context.Context, code string, parsedState *shared.ProviderStateClaims) (*oauth.AuthUser, error)
 	VerifyAndParseOtpToken(ctx context.Context, emailType mailer.EmailType, token string) (*shared.OtpClaims, error)
@@ -52,12 +96,24 @@ type AuthService interface {
 
 var _ AuthService = (*BaseAuthService)(nil)
 
+var (
+	// ErrEmailRequired refuses sign ins without an email, users are matched
+	// by their email.
+	ErrEmailRequired = errors.New("email is required")
+	// ErrProviderAccountMismatch refuses sign ins with another account of a
+	// provider than the one linked to the user.
+	ErrProviderAccountMismatch = errors.New("provider account does not match the linked account")
+)
+
 type BaseAuthService struct {
 	token      JwtService
 	password   PasswordService
 	config     *conf.EnvConfig
 	adapter    stores.StorageAdapterInterface
 	jobService JobService
+	// oidcProviders caches the OIDC providers of the DB by name, so their
+	// discovery documents and keys are fetched once.
+	oidcProviders sync.Map
 }
 
 func NewAuthService(
@@ -86,28 +142,72 @@ func (app *BaseAuthService) Token() JwtService {
 	return app.token
 }
 
+type cachedOidcProvider struct {
+	updatedAt time.Time
+	provider  *oauth.OIDCConfig
+}
+
+// oauthProvider returns the enabled provider with the name. Providers that
+// are not built in or set in env are looked up in the OIDC providers of the DB.
+func (app *BaseAuthService) oauthProvider(ctx context.Context, name models.Providers) (oauth.ProviderConfig, error) {
+	if provider := oauth.NewProviderByName(string(name)); provider != nil {
+		if !provider.Active() {
+			return nil, fmt.Errorf("provider %v is not enabled", name)
+		}
+		return provider, nil
+	}
+	if teamID, ok := models.ParseTeamSsoProvider(name); ok {
+		return app.teamSsoProvider(ctx, teamID)
+	}
+	row, err := app.adapter.OidcProvider().FindOidcProviderByName(ctx, string(name))
+	if err != nil {
+		return nil, err
+	}
+	if row == nil {
+		return nil, fmt.Errorf("provider %v not found", name)
+	}
+	if !row.Enabled {
+		return nil, fmt.Errorf("provider %v is not enabled", name)
+	}
+	if cached, ok := app.oidcProviders.Load(row.Name); ok && cached.(*cachedOidcProvider).updatedAt.Equal(row.UpdatedAt) {
+		return cached.(*cachedOidcProvider).provider, nil
+	}
+	provider := oauth.NewOIDCProvider(oauth.OIDCOptions{
+		Name:         row.Name,
+		DisplayName:  row.DisplayName,
+		Issuer:       row.Issuer,
+		ClientID:     row.ClientID,
+		ClientSecret: row.ClientSecret,
+		Scopes:       row.Scopes,
+		RedirectURL:  app.config.AppUrl + app.config.AuthCallback,
+	})
+	app.oidcProviders.Store(row.Name, &cachedOidcProvider{updatedAt: row.UpdatedAt, provider: provider})
+	return provider, nil
+}
+
 // CreateOAuthUrl implements AuthService.
 func (app *BaseAuthService) CreateOAuthUrl(ctx context.Context, providerName models.Providers, redirectUrl string) (string, error) {
-	redirectTo := redirectUrl
-	if redirectTo == "" {
-		redirectTo = app.config.AppUrl
-	}
-	provider := oauth.NewProviderByName(string(providerName))
-	if provider == nil {
-		return "", fmt.Errorf("provider %v not found", providerName)
+	return app.createOAuthUrl(ctx, &shared.ProviderStatePayload{
+		Provider:   providerName,
+		RedirectTo: redirectUrl,
+	})
+}
+
+// createOAuthUrl persists the state of the flow in info and builds the url of
+// its provider.
+func (app *BaseAuthService) createOAuthUrl(ctx context.Context, info *shared.ProviderStatePayload) (string, error) {
+	if info.RedirectTo == "" {
+		info.RedirectTo = app.config.AppUrl
 	}
-	if !provider.Active() {
-		return "", fmt.Errorf("provider %v is not enabled", providerName)
+	provider, err := app.oauthProvider(ctx, info.Provider)
+	if err != nil {
+		return "", err
 	}
 	urlOpts := []oauth2.AuthCodeOption{
 		oauth2.AccessTypeOffline,
 	}
-	info := &shared.ProviderStatePayload{
-		Type:       models.TokenTypesStateToken,
-		Provider:   providerName,
-		RedirectTo: redirectTo,
-		Token:      security.GenerateTokenKey(),
-	}
+	info.Type = models.TokenTypesStateToken
+	info.Token = security.GenerateTokenKey()
 	if provider.Pkce() {
 
 		info.CodeVerifier = security.RandomString(43)
@@ -118,6 +218,10 @@ func (app *BaseAuthService) CreateOAuthUrl(ctx context.Context, providerName mod
 			oauth2.SetAuthURLParam("code_challenge_method", info.CodeChallengeMethod),
 		)
 	}
+	if _, ok := provider.(oauth.NonceProvider); ok {
+		info.Nonce = security.RandomString(32)
+		urlOpts = append(urlOpts, oauth2.SetAuthURLParam("nonce", info.Nonce))
+	}
 	state, err := app.CreateAndPersistStateToken(ctx, info)
 	if err != nil {
 		return "", err
@@ -131,12 +235,9 @@ func (app *BaseAuthService) CreateOAuthUrl(ctx context.Context, providerName mod
 
 // FetchAuthUser implements Authenticator.
 func (app *BaseAuthService) FetchAuthUser(ctx context.Context, code string, parsedState *shared.ProviderStateClaims) (*oauth.AuthUser, error) {
-	var provider = oauth.NewProviderByName(parsedState.Provider.String())
-	if provider == nil {
-		return nil, fmt.Errorf("provider %v not found", parsedState.Provider)
-	}
-	if !provider.Active() {
-		return nil, fmt.Errorf("provider %v is not enabled", parsedState.Provider)
+	provider, err := app.oauthProvider(ctx, parsedState.Provider)
+	if err != nil {
+		return nil, err
 	}
 	opts := provider.FetchTokenOptions(parsedState.CodeVerifier)
 
@@ -147,7 +248,12 @@ func (app *BaseAuthService) FetchAuthUser(ctx context.Context, code string, pars
 	}
 
 	// fetch external auth user
-	authUser, err := provider.FetchAuthUser(ctx, token)
+	var authUser *oauth.AuthUser
+	if nonceProvider, ok := provider.(oauth.NonceProvider); ok {
+		authUser, err = nonceProvider.FetchAuthUserWithNonce(ctx, token, parsedState.Nonce)
+	} else {
+		authUser, err = provider.FetchAuthUser(ctx, token)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch OAuth2 user. %w", err)
 	}
@@ -196,12 +302,19 @@ func (app *BaseAuthService) Signout(ctx context.Context, token string) error {
 	if err != nil {
 		return fmt.Errorf("error verifying refresh token: %w", err)
 	}
-	_, err = app.adapter.Token().GetToken(ctx, token) // corrected 'tokne' to 'token'
-	// _, err = app.authStore.GetToken(ctx, token) // corrected 'tokne' to 'token'
+	_, err = app.adapter.Token().GetToken(ctx, claims.Token)
 	if err != nil {
 		return err
 	}
-	err = app.adapter.Token().DeleteToken(ctx, token) // corrected to use 'app.token'
+	// ending the session also deletes its refresh tokens
+	if claims.SessionID != uuid.Nil {
+		_, err = app.adapter.UserSession().DeleteUserSession(ctx, claims.UserId, claims.SessionID)
+		if err != nil {
+			return fmt.Errorf("error at deleting session: %w", err)
+		}
+		return nil
+	}
+	err = app.adapter.Token().DeleteToken(ctx, claims.Token)
 	if err != nil {
 		return fmt.Errorf("error at deleting token: %w", err)
 	}
@@ -209,8 +322,12 @@ func (app *BaseAuthService) Signout(ctx context.Context, token string) error {
 }
 
 // HandlePasswordResetRequest implements AuthActions.
+// Requests are limited by email and ip, so the emails of a user can not be
+// flooded with reset links.
 func (app *BaseAuthService) HandlePasswordResetRequest(ctx context.Context, email string) error {
-
+	if err := app.limitEmailRequest(ctx, models.LoginAttemptActionPasswordReset, email); err != nil {
+		return err
+	}
 	user, err := app.adapter.User().FindUser(
 		ctx,
 		&stores.UserFilter{
@@ -282,70 +399,75 @@ func (app *BaseAuthService) CreateAuthTokensFromEmail(ctx context.Context, email
 	return app.CreateAuthTokens(ctx, user)
 }
 
+// CreateAuthTokens signs the user in on a new session.
 func (app *BaseAuthService) CreateAuthTokens(ctx context.Context, payload *models.UserInfo) (*models.UserInfoTokens, error) {
 	if payload == nil {
 		return nil, fmt.Errorf("payload is nil")
 	}
-
-	opts := app.config.AuthOptions
-
-	authToken, err := func() (string, error) {
-		claims := shared.AuthenticationClaims{
-			Type: models.TokenTypesAccessToken,
-			RegisteredClaims: jwt.RegisteredClaims{
-				ExpiresAt: opts.AccessToken.ExpiresAt(),
-			},
-			AuthenticationPayload: shared.AuthenticationPayload{
-				UserId:      payload.User.ID,
-				Email:       payload.User.Email,
-				Roles:       payload.Roles,
-				Permissions: payload.Permissions,
-			},
-		}
-		token, err := app.token.CreateJwtToken(claims, opts.AccessToken.Secret)
-		if err != nil {
-			return token, err
-		}
-		return token, nil
-	}()
+	session, err := app.createSession(ctx, payload.User.ID)
 	if err != nil {
 		return nil, err
 	}
+	return app.createSessionTokens(ctx, payload, session.ID)
+}
 
+// createSessionTokens issues the access and refresh tokens of a session.
+func (app *BaseAuthService) createSessionTokens(ctx context.Context, payload *models.UserInfo, sessionID uuid.UUID) (*models.UserInfoTokens, error) {
 	tokenKey := security.GenerateTokenKey()
+	err := app.saveRefreshToken(ctx, app.adapter, payload.User.ID, payload.User.Email, sessionID, tokenKey)
+	if err != nil {
+		return nil, err
+	}
+	return app.signSessionTokens(payload, sessionID, tokenKey)
+}
 
-	refreshToken, err := func() (string, error) {
+// saveRefreshToken stores the refresh token with the key for the session.
+func (app *BaseAuthService) saveRefreshToken(ctx context.Context, adapter stores.StorageAdapterInterface, userID uuid.UUID, email string, sessionID uuid.UUID, tokenKey string) error {
+	return adapter.Token().SaveToken(
+		ctx,
+		&stores.CreateTokenDTO{
+			Type:       models.TokenTypesRefreshToken,
+			Identifier: email,
+			Expires:    app.config.AuthOptions.RefreshToken.Expires(),
+			Token:      tokenKey,
+			UserID:     &userID,
+			SessionID:  &sessionID,
+		},
+	)
+}
 
-		claims := shared.RefreshTokenClaims{
-			Type:             models.TokenTypesRefreshToken,
-			RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: opts.RefreshToken.ExpiresAt()},
-			RefreshTokenPayload: shared.RefreshTokenPayload{
-				UserId: payload.User.ID,
-				Email:  payload.User.Email,
-				Token:  tokenKey,
-			},
-		}
+// signSessionTokens signs an access token and the refresh token with the
+// stored key for the session.
+func (app *BaseAuthService) signSessionTokens(payload *models.UserInfo, sessionID uuid.UUID, tokenKey string) (*models.UserInfoTokens, error) {
+	opts := app.config.AuthOptions
 
-		token, err := app.token.CreateJwtToken(claims, opts.RefreshToken.Secret)
-		if err != nil {
-			return token, err
-		}
-		err = app.adapter.Token().SaveToken(
-			ctx,
-			&stores.CreateTokenDTO{
-				Type:       models.TokenTypesRefreshToken,
-				Identifier: claims.Email,
-				Expires:    opts.RefreshToken.Expires(),
-				Token:      claims.Token,
-				UserID:     &claims.UserId,
-			},
-		)
-		if err != nil {
-			return token, err
-		}
-		return token, nil
-	}()
+	authToken, err := app.token.CreateJwtToken(shared.AuthenticationClaims{
+		Type: models.TokenTypesAccessToken,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: opts.AccessToken.ExpiresAt(),
+		},
+		AuthenticationPayload: shared.AuthenticationPayload{
+			UserId:      payload.User.ID,
+			Email:       payload.User.Email,
+			Roles:       payload.Roles,
+			Permissions: payload.Permissions,
+			SessionID:   sessionID,
+		},
+	}, opts.AccessToken.Secret)
+	if err != nil {
+		return nil, err
+	}
 
+	refreshToken, err := app.token.CreateJwtToken(shared.RefreshTokenClaims{
+		Type:             models.TokenTypesRefreshToken,
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: opts.RefreshToken.ExpiresAt()},
+		RefreshTokenPayload: shared.RefreshTokenPayload{
+			UserId:    payload.User.ID,
+			Email:     payload.User.Email,
+			Token:     tokenKey,
+			SessionID: sessionID,
+		},
+	}, opts.RefreshToken.Secret)
 	if err != nil {
 		return nil, err
 	}
@@ -454,10 +576,32 @@ func (app *BaseAuthService) HandleAccessToken(ctx context.Context, token string)
 	if err != nil {
 		return nil, fmt.Errorf("error verifying access token: %w", err)
 	}
-	return app.adapter.User().GetUserInfo(ctx, claims.Email)
+	// impersonation tokens belong to the session of the superuser
+	var impersonation *models.Impersonation
+	if claims.ImpersonationId != uuid.Nil {
+		impersonation, err = app.checkImpersonation(ctx, &claims)
+		if err != nil {
+			return nil, err
+		}
+	} else if err := app.checkSession(ctx, claims.UserId, claims.SessionID); err != nil {
+		// access tokens of revoked sessions are rejected before they expire
+		return nil, err
+	}
+	info, err := app.adapter.User().GetUserInfo(ctx, claims.Email)
+	if err != nil {
+		return nil, err
+	}
+	info.SessionID = claims.SessionID
+	info.Impersonation = impersonation
+	return info, nil
 }
 
-// HandleRefreshToken implements AuthActions.
+// HandleRefreshToken implements AuthActions. Every refresh rotates the
+// refresh token inside the family of its session. A used token presented
+// again was stolen from the device or by it, so the session is revoked and
+// the user alerted. The token the session was just rotated from returns the
+// same successor for refreshTokenGrace, clients refreshing from two tabs at
+// once or retrying a lost response would revoke their own session.
 func (app *BaseAuthService) HandleRefreshToken(ctx context.Context, token string) (*models.UserInfoTokens, error) {
 	opts := app.config.AuthOptions
 	var claims shared.RefreshTokenClaims
@@ -465,30 +609,67 @@ func (app *BaseAuthService) HandleRefreshToken(ctx context.Context, token string
 	if err != nil {
 		return nil, fmt.Errorf("error verifying refresh token: %w", err)
 	}
-	_, err = app.adapter.Token().GetToken(ctx, claims.Token)
+	// tokens issued before sessions were recorded have no family, they are
+	// exchanged for a new session
+	if claims.SessionID == uuid.Nil {
+		_, err := app.adapter.Token().GetToken(ctx, claims.Token)
+		if err != nil {
+			return nil, fmt.Errorf("error getting token: %w", err)
+		}
+		err = app.adapter.Token().DeleteToken(ctx, claims.Token)
+		if err != nil {
+			return nil, fmt.Errorf("error deleting token: %w", err)
+		}
+		info, err := app.adapter.User().GetUserInfo(ctx, claims.Email)
+		if err != nil {
+			return nil, err
+		}
+		return app.CreateAuthTokens(ctx, info)
+	}
+	info, err := app.adapter.User().GetUserInfo(ctx, claims.Email)
 	if err != nil {
-		return nil, fmt.Errorf("error getting token: %w", err) // corrected to return nil before the error
+		return nil, err
+	}
+	var tokenKey string
+	err = app.adapter.RunInTx(func(tx stores.StorageAdapterInterface) error {
+		tokenKey, err = app.rotateRefreshToken(ctx, tx, &claims)
+		return err
+	})
+	if errors.Is(err, ErrRefreshTokenReused) {
+		return nil, app.revokeTokenFamily(ctx, &claims)
 	}
-	err = app.adapter.Token().DeleteToken(ctx, claims.Token)
 	if err != nil {
-		return nil, fmt.Errorf("error deleting token: %w", err)
+		return nil, err
 	}
-	info, err := app.adapter.User().GetUserInfo(ctx, claims.Email)
+	session, err := app.adapter.UserSession().ExtendUserSession(ctx, claims.SessionID, opts.RefreshToken.Expires())
 	if err != nil {
 		return nil, err
 	}
-
-	return app.CreateAuthTokens(ctx, info)
+	if session == nil {
+		return nil, ErrSessionRevoked
+	}
+	return app.signSessionTokens(info, session.ID, tokenKey)
 }
 
+// HandleVerificationToken verifies the email of a user, invalid tokens are
+// throttled by ip.
 func (app *BaseAuthService) HandleVerificationToken(ctx context.Context, token string) error {
+	if err := app.checkLoginAttempt(ctx, models.LoginAttemptActionVerify, ""); err != nil {
+		return err
+	}
 	claims, err := app.VerifyAndParseOtpToken(ctx, mailer.EmailTypeVerify, token)
 	if err != nil {
-		return fmt.Errorf("error verifying verification token: %w", err)
+		return errors.Join(
+			fmt.Errorf("error verifying verification token: %w", err),
+			app.recordLoginFailure(ctx, models.LoginAttemptActionVerify, "", nil),
+		)
 	}
 	_, err = app.adapter.Token().GetToken(ctx, claims.Token)
 	if err != nil {
-		return fmt.Errorf("error getting token: %w", err)
+		return errors.Join(
+			fmt.Errorf("error getting token: %w", err),
+			app.recordLoginFailure(ctx, models.LoginAttemptActionVerify, "", nil),
+		)
 	}
 	err = app.adapter.Token().DeleteToken(ctx, claims.Token)
 	if err != nil {
@@ -627,7 +808,49 @@ type AuthenticationInput struct {
 	RefreshToken      *string
 }
 
+// Authenticate signs a user in or up. Users of a domain whose team enforces
+// SSO must use the IdP of the team, users signed in through it become
+// members of the team. Failed password sign ins are throttled by email and
+// ip.
 func (app *BaseAuthService) Authenticate(ctx context.Context, params *AuthenticationInput) (*models.User, error) {
+	if strings.TrimSpace(params.Email) == "" {
+		return nil, ErrEmailRequired
+	}
+	credentials := params.Type == models.ProviderTypeCredentials
+	if credentials {
+		if err := app.checkLoginAttempt(ctx, models.LoginAttemptActionSignin, params.Email); err != nil {
+			return nil, err
+		}
+	}
+	sso, err := app.checkTeamSso(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	user, err := app.authenticate(ctx, params)
+	if err != nil {
+		// failures are counted by email whether or not it has a user, so
+		// guesses at emails without an account are throttled too
+		if credentials {
+			if err := app.recordLoginFailure(ctx, models.LoginAttemptActionSignin, params.Email, params.UserId); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+	if credentials {
+		if err := app.clearLoginFailures(ctx, models.LoginAttemptActionSignin, params.Email); err != nil {
+			return nil, err
+		}
+	}
+	if sso != nil {
+		if err := app.provisionTeamSsoMember(ctx, user, sso); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+func (app *BaseAuthService) authenticate(ctx context.Context, params *AuthenticationInput) (*models.User, error) {
 	var user *models.User
 	var account *models.UserAccount
 	var err error
@@ -669,8 +892,10 @@ func (app *BaseAuthService) Authenticate(ctx context.Context, params *Authentica
 		if match, err := app.password.VerifyPassword(*account.Password, *params.Password); err != nil {
 			return nil, fmt.Errorf("error at comparing password: %w", err)
 		} else if !match {
-			return nil, fmt.Errorf("password is incorrect")
+			return nil, shared.ErrPasswordIncorrect
 		}
+	} else if account.ProviderAccountID != params.ProviderAccountID {
+		return nil, ErrProviderAccountMismatch
 	}
 	return user, nil
 }
@@ -13,25 +13,43 @@ import (
 )
 
 type AuthServiceDecorator struct {
-	Delegate                       *BaseAuthService
-	PasswordFunc                   func() PasswordService
-	TokenFunc                      func() JwtService
-	CreateOAuthUrlFunc             func(ctx context.Context, provider models.Providers, redirectUrl string) (string, error)
-	AuthenticateFunc               func(ctx context.Context, params *AuthenticationInput) (*models.User, error)
-	CheckResetPasswordTokenFunc    func(ctx context.Context, token string) error
-	HandleAccessTokenFunc          func(ctx context.Context, token string) (*models.UserInfo, error)
-	HandleRefreshTokenFunc         func(ctx context.Context, token string) (*models.UserInfoTokens, error)
-	HandlePasswordResetRequestFunc func(ctx context.Context, email string) error
-	HandlePasswordResetTokenFunc   func(ctx context.Context, token string, password string) error
-	HandleVerificationTokenFunc    func(ctx context.Context, token string) error
-	SignoutFunc                    func(ctx context.Context, token string) error
-	ResetPasswordFunc              func(ctx context.Context, userId uuid.UUID, oldPassword string, newPassword string) error
-	SendOtpEmailFunc               func(emailType mailer.EmailType, ctx context.Context, user *models.User, adapter stores.StorageAdapterInterface) error
-	VerifyAndParseOtpTokenFunc     func(ctx context.Context, emailType mailer.EmailType, token string) (*shared.OtpClaims, error)
-	VerifyStateTokenFunc           func(ctx context.Context, token string) (*shared.ProviderStateClaims, error)
-	CreateAndPersistStateTokenFunc func(ctx context.Context, payload *shared.ProviderStatePayload) (string, error)
-	CreateAuthTokensFromEmailFunc  func(ctx context.Context, email string) (*models.UserInfoTokens, error)
-	FetchAuthUserFunc              func(ctx context.Context, code string, parsedState *shared.ProviderStateClaims) (*oauth.AuthUser, error)
+	Delegate                        *BaseAuthService
+	PasswordFunc                    func() PasswordService
+	TokenFunc                       func() JwtService
+	CreateOAuthUrlFunc              func(ctx context.Context, provider models.Providers, redirectUrl string) (string, error)
+	CreateSsoUrlFunc                func(ctx context.Context, email string, redirectUrl string) (string, error)
+	AuthenticateFunc                func(ctx context.Context, params *AuthenticationInput) (*models.User, error)
+	CheckResetPasswordTokenFunc     func(ctx context.Context, token string) error
+	HandleAccessTokenFunc           func(ctx context.Context, token string) (*models.UserInfo, error)
+	HandleRefreshTokenFunc          func(ctx context.Context, token string) (*models.UserInfoTokens, error)
+	HandlePasswordResetRequestFunc  func(ctx context.Context, email string) error
+	HandlePasswordResetTokenFunc    func(ctx context.Context, token string, password string) error
+	HandleVerificationTokenFunc     func(ctx context.Context, token string) error
+	SignoutFunc                     func(ctx context.Context, token string) error
+	SessionsFunc                    func(ctx context.Context, userId uuid.UUID) ([]*models.UserSession, error)
+	RevokeSessionFunc               func(ctx context.Context, userId uuid.UUID, sessionId uuid.UUID) error
+	RevokeSessionsFunc              func(ctx context.Context, userId uuid.UUID) error
+	UnlockUserFunc                  func(ctx context.Context, userId uuid.UUID) error
+	AccountsFunc                    func(ctx context.Context, userId uuid.UUID) ([]*models.UserAccount, error)
+	CreateLinkAccountUrlFunc        func(ctx context.Context, userId uuid.UUID, provider models.Providers, redirectUrl string) (string, error)
+	LinkAccountFunc                 func(ctx context.Context, userId uuid.UUID, params *AuthenticationInput) (*models.User, error)
+	UnlinkAccountFunc               func(ctx context.Context, userId uuid.UUID, provider models.Providers) error
+	ImpersonateFunc                 func(ctx context.Context, impersonator *models.UserInfo, userId uuid.UUID) (*models.UserInfoTokens, error)
+	EndImpersonationFunc            func(ctx context.Context, info *models.UserInfo) (*models.UserInfoTokens, error)
+	RecordImpersonatedRequestFunc   func(ctx context.Context, info *models.UserInfo, method string, path string) (uuid.UUID, error)
+	CompleteImpersonatedRequestFunc func(ctx context.Context, logId uuid.UUID, status int) error
+	ListImpersonationsFunc          func(ctx context.Context, userId uuid.UUID) ([]*models.Impersonation, error)
+	ImpersonationAuditLogsFunc      func(ctx context.Context, impersonationId uuid.UUID) ([]*models.AuditLog, error)
+	HandleMagicLinkRequestFunc      func(ctx context.Context, email string) error
+	HandleMagicLinkTokenFunc        func(ctx context.Context, token string) (*models.UserInfoTokens, error)
+	HandleMagicLinkCodeFunc         func(ctx context.Context, email string, code string) (*models.UserInfoTokens, error)
+	ResetPasswordFunc               func(ctx context.Context, userId uuid.UUID, oldPassword string, newPassword string) error
+	SendOtpEmailFunc                func(emailType mailer.EmailType, ctx context.Context, user *models.User, adapter stores.StorageAdapterInterface) error
+	VerifyAndParseOtpTokenFunc      func(ctx context.Context, emailType mailer.EmailType, token string) (*shared.OtpClaims, error)
+	VerifyStateTokenFunc            func(ctx context.Context, token string) (*shared.ProviderStateClaims, error)
+	CreateAndPersistStateTokenFunc  func(ctx context.Context, payload *shared.ProviderStatePayload) (string, error)
+	CreateAuthTokensFromEmailFunc   func(ctx context.Context, email string) (*models.UserInfoTokens, error)
+	FetchAuthUserFunc               func(ctx context.Context, code string, parsedState *shared.ProviderStateClaims) (*oauth.AuthUser, error)
 }
 
 func NewAuthServiceDecorator(
@@ -79,6 +97,14 @@ func (a *AuthServiceDecorator) CreateOAuthUrl(ctx context.Context, provider mode
 	return a.Delegate.CreateOAuthUrl(ctx, provider, redirectUrl)
 }
 
+// CreateSsoUrl implements AuthService.
+func (a *AuthServiceDecorator) CreateSsoUrl(ctx context.Context, email string, redirectUrl string) (string, error) {
+	if a.CreateSsoUrlFunc != nil {
+		return a.CreateSsoUrlFunc(ctx, email, redirectUrl)
+	}
+	return a.Delegate.CreateSsoUrl(ctx, email, redirectUrl)
+}
+
 // Authenticate implements AuthService.
 func (a *AuthServiceDecorator) Authenticate(ctx context.Context, params *AuthenticationInput) (*models.User, error) {
 	if a.AuthenticateFunc != nil {
@@ -198,3 +224,139 @@ func (a *AuthServiceDecorator) VerifyStateToken(ctx context.Context, token strin
 	}
 	return a.Delegate.VerifyStateToken(ctx, token)
 }
+
+// Sessions implements AuthService.
+func (a *AuthServiceDecorator) Sessions(ctx context.Context, userId uuid.UUID) ([]*models.UserSession, error) {
+	if a.SessionsFunc != nil {
+		return a.SessionsFunc(ctx, userId)
+	}
+	return a.Delegate.Sessions(ctx, userId)
+}
+
+// RevokeSession implements AuthService.
+func (a *AuthServiceDecorator) RevokeSession(ctx context.Context, userId uuid.UUID, sessionId uuid.UUID) error {
+	if a.RevokeSessionFunc != nil {
+		return a.RevokeSessionFunc(ctx, userId, sessionId)
+	}
+	return a.Delegate.RevokeSession(ctx, userId, sessionId)
+}
+
+// RevokeSessions implements AuthService.
+func (a *AuthServiceDecorator) RevokeSessions(ctx context.Context, userId uuid.UUID) error {
+	if a.RevokeSessionsFunc != nil {
+		return a.RevokeSessionsFunc(ctx, userId)
+	}
+	return a.Delegate.RevokeSessions(ctx, userId)
+}
+
+// HandleMagicLinkRequest implements AuthService.
+func (a *AuthServiceDecorator) HandleMagicLinkRequest(ctx context.Context, email string) error {
+	if a.HandleMagicLinkRequestFunc != nil {
+		return a.HandleMagicLinkRequestFunc(ctx, email)
+	}
+	return a.Delegate.HandleMagicLinkRequest(ctx, email)
+}
+
+// HandleMagicLinkToken implements AuthService.
+func (a *AuthServiceDecorator) HandleMagicLinkToken(ctx context.Context, token string) (*models.UserInfoTokens, error) {
+	if a.HandleMagicLinkTokenFunc != nil {
+		return a.HandleMagicLinkTokenFunc(ctx, token)
+	}
+	return a.Delegate.HandleMagicLinkToken(ctx, token)
+}
+
+// HandleMagicLinkCode implements AuthService.
+func (a *AuthServiceDecorator) HandleMagicLinkCode(ctx context.Context, email string, code string) (*models.UserInfoTokens, error) {
+	if a.HandleMagicLinkCodeFunc != nil {
+		return a.HandleMagicLinkCodeFunc(ctx, email, code)
+	}
+	return a.Delegate.HandleMagicLinkCode(ctx, email, code)
+}
+
+// UnlockUser implements AuthService.
+func (a *AuthServiceDecorator) UnlockUser(ctx context.Context, userId uuid.UUID) error {
+	if a.UnlockUserFunc != nil {
+		return a.UnlockUserFunc(ctx, userId)
+	}
+	return a.Delegate.UnlockUser(ctx, userId)
+}
+
+// Accounts implements AuthService.
+func (a *AuthServiceDecorator) Accounts(ctx context.Context, userId uuid.UUID) ([]*models.UserAccount, error) {
+	if a.AccountsFunc != nil {
+		return a.AccountsFunc(ctx, userId)
+	}
+	return a.Delegate.Accounts(ctx, userId)
+}
+
+// CreateLinkAccountUrl implements AuthService.
+func (a *AuthServiceDecorator) CreateLinkAccountUrl(ctx context.Context, userId uuid.UUID, provider models.Providers, redirectUrl string) (string, error) {
+	if a.CreateLinkAccountUrlFunc != nil {
+		return a.CreateLinkAccountUrlFunc(ctx, userId, provider, redirectUrl)
+	}
+	return a.Delegate.CreateLinkAccountUrl(ctx, userId, provider, redirectUrl)
+}
+
+// LinkAccount implements AuthService.
+func (a *AuthServiceDecorator) LinkAccount(ctx context.Context, userId uuid.UUID, params *AuthenticationInput) (*models.User, error) {
+	if a.LinkAccountFunc != nil {
+		return a.LinkAccountFunc(ctx, userId, params)
+	}
+	return a.Delegate.LinkAccount(ctx, userId, params)
+}
+
+// UnlinkAccount implements AuthService.
+func (a *AuthServiceDecorator) UnlinkAccount(ctx context.Context, userId uuid.UUID, provider models.Providers) error {
+	if a.UnlinkAccountFunc != nil {
+		return a.UnlinkAccountFunc(ctx, userId, provider)
+	}
+	return a.Delegate.UnlinkAccount(ctx, userId, provider)
+}
+
+// Impersonate implements AuthService.
+func (a *AuthServiceDecorator) Impersonate(ctx context.Context, impersonator *models.UserInfo, userId uuid.UUID) (*models.UserInfoTokens, error) {
+	if a.ImpersonateFunc != nil {
+		return a.ImpersonateFunc(ctx, impersonator, userId)
+	}
+	return a.Delegate.Impersonate(ctx, impersonator, userId)
+}
+
+// EndImpersonation implements AuthService.
+func (a *AuthServiceDecorator) EndImpersonation(ctx context.Context, info *models.UserInfo) (*models.UserInfoTokens, error) {
+	if a.EndImpersonationFunc != nil {
+		return a.EndImpersonationFunc(ctx, info)
+	}
+	return a.Delegate.EndImpersonation(ctx, info)
+}
+
+// RecordImpersonatedRequest implements AuthService.
+func (a *AuthServiceDecorator) RecordImpersonatedRequest(ctx context.Context, info *models.UserInfo, method string, path string) (uuid.UUID, error) {
+	if a.RecordImpersonatedRequestFunc != nil {
+		return a.RecordImpersonatedRequestFunc(ctx, info, method, path)
+	}
+	return a.Delegate.RecordImpersonatedRequest(ctx, info, method, path)
+}
+
+// CompleteImpersonatedRequest implements AuthService.
+func (a *AuthServiceDecorator) CompleteImpersonatedRequest(ctx context.Context, logId uuid.UUID, status int) error {
+	if a.CompleteImpersonatedRequestFunc != nil {
+		return a.CompleteImpersonatedRequestFunc(ctx, logId, status)
+	}
+	return a.Delegate.CompleteImpersonatedRequest(ctx, logId, status)
+}
+
+// ListImpersonations implements AuthService.
+func (a *AuthServiceDecorator) ListImpersonations(ctx context.Context, userId uuid.UUID) ([]*models.Impersonation, error) {
+	if a.ListImpersonationsFunc != nil {
+		return a.ListImpersonationsFunc(ctx, userId)
+	}
+	return a.Delegate.ListImpersonations(ctx, userId)
+}
+
+// ImpersonationAuditLogs implements AuthService.
+func (a *AuthServiceDecorator) ImpersonationAuditLogs(ctx context.Context, impersonationId uuid.UUID) ([]*models.AuditLog, error) {
+	if a.ImpersonationAuditLogsFunc != nil {
+		return a.ImpersonationAuditLogsFunc(ctx, impersonationId)
+	}
+	return a.Delegate.ImpersonationAuditLogs(ctx, impersonationId)
+}
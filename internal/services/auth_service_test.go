@@ -2,16 +2,24 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkahng/playground/internal/auth/oauth"
 	"github.com/tkahng/playground/internal/conf"
 	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/shared"
 	"github.com/tkahng/playground/internal/stores"
 	"github.com/tkahng/playground/internal/tools/mailer"
 	"github.com/tkahng/playground/internal/tools/types"
@@ -79,6 +87,10 @@ func TestHandleRefreshToken(t *testing.T) {
 				adapter.TokenFunc.SaveTokenFunc = func(ctx context.Context, token *stores.CreateTokenDTO) error {
 					return nil
 				}
+				adapter.UserSessionFunc.CreateUserSessionFunc = func(ctx context.Context, session *models.UserSession) (*models.UserSession, error) {
+					session.ID = uuid.New()
+					return session, nil
+				}
 			},
 			expectedError: false,
 		},
@@ -267,6 +279,11 @@ func TestAuthenticate(t *testing.T) {
 		jobService: jobService,
 	}
 
+	// no team verified the domain of the test email
+	storeDecorator.TeamSsoFunc.FindVerifiedTeamSsoDomainFunc = func(ctx context.Context, domain string) (*models.TeamSsoDomain, error) {
+		return nil, nil
+	}
+
 	testUserId := uuid.New()
 	testEmail := "test@example.com"
 	testPasswordStr := "password123"
@@ -512,3 +529,92 @@ func TestAuthenticate(t *testing.T) {
 		})
 	}
 }
+
+func TestOauthProvider(t *testing.T) {
+	ctx := context.Background()
+	adapter := stores.NewAdapterDecorators()
+	app := &BaseAuthService{
+		adapter: adapter,
+		config:  &conf.EnvConfig{},
+	}
+	row := &models.OidcProvider{
+		Name:      "keycloak",
+		Issuer:    "https://keycloak.example.com/realms/acme",
+		ClientID:  "client",
+		Enabled:   true,
+		UpdatedAt: time.Now(),
+	}
+	adapter.OidcProviderFunc.FindOidcProviderByNameFunc = func(ctx context.Context, name string) (*models.OidcProvider, error) {
+		if name == row.Name {
+			return row, nil
+		}
+		return nil, nil
+	}
+
+	_, err := app.oauthProvider(ctx, "okta")
+	assert.Error(t, err, "unknown providers are not found")
+
+	provider, err := app.oauthProvider(ctx, "keycloak")
+	assert.NoError(t, err)
+	oidc, ok := provider.(*oauth.OIDCConfig)
+	if assert.True(t, ok) {
+		assert.Equal(t, row.Issuer, oidc.Issuer())
+	}
+	again, _ := app.oauthProvider(ctx, "keycloak")
+	assert.Same(t, provider, again, "providers are cached until they change")
+
+	row.UpdatedAt = row.UpdatedAt.Add(time.Second)
+	changed, _ := app.oauthProvider(ctx, "keycloak")
+	assert.NotSame(t, provider, changed)
+
+	row.Enabled = false
+	_, err = app.oauthProvider(ctx, "keycloak")
+	assert.Error(t, err, "disabled providers can not be used")
+}
+
+func TestAuthenticate_OAuthAccounts(t *testing.T) {
+	ctx := context.Background()
+	app := newTestAuthService()
+	app.users.add("", nil, &models.UserAccount{Provider: models.ProvidersGoogle, Type: models.ProviderTypeOAuth, ProviderAccountID: "google-0"})
+	user := app.users.add("oauth@example.com", nil, &models.UserAccount{Provider: models.ProvidersGoogle, Type: models.ProviderTypeOAuth, ProviderAccountID: "google-1"})
+
+	// an IdP returning no email must not match the users without one
+	for _, email := range []string{"", " "} {
+		_, err := app.Authenticate(ctx, &AuthenticationInput{Email: email, Provider: models.ProvidersGoogle, Type: models.ProviderTypeOAuth, ProviderAccountID: "google-2"})
+		assert.ErrorIs(t, err, ErrEmailRequired, "users without an email are not matched")
+	}
+
+	_, err := app.Authenticate(ctx, &AuthenticationInput{Email: user.User.Email, Provider: models.ProvidersGoogle, Type: models.ProviderTypeOAuth, ProviderAccountID: "google-2"})
+	assert.ErrorIs(t, err, ErrProviderAccountMismatch, "another account of the provider does not sign the user in")
+
+	signedIn, err := app.Authenticate(ctx, &AuthenticationInput{Email: user.User.Email, Provider: models.ProvidersGoogle, Type: models.ProviderTypeOAuth, ProviderAccountID: "google-1"})
+	require.NoError(t, err)
+	assert.Equal(t, user.User.ID, signedIn.ID)
+}
+
+func TestCreateOAuthUrl_OidcNonce(t *testing.T) {
+	var issuer *httptest.Server
+	issuer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                 issuer.URL,
+			"authorization_endpoint": issuer.URL + "/authorize",
+			"token_endpoint":         issuer.URL + "/token",
+			"jwks_uri":               issuer.URL + "/jwks",
+		})
+	}))
+	t.Cleanup(issuer.Close)
+	name := "nonce-" + strings.ToLower(t.Name())
+	require.NoError(t, oauth.RegisterOIDCProvider(oauth.OIDCOptions{Name: name, Issuer: issuer.URL, ClientID: "client-1"}))
+	t.Cleanup(func() { delete(oauth.Providers, name) })
+
+	app := newTestAuthService()
+	authURL, err := app.CreateOAuthUrl(context.Background(), models.Providers(name), "")
+	require.NoError(t, err)
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	var claims shared.ProviderStateClaims
+	require.NoError(t, app.token.ParseToken(parsed.Query().Get("state"), app.config.StateToken, &claims))
+	assert.NotEmpty(t, claims.Nonce)
+	assert.Equal(t, claims.Nonce, parsed.Query().Get("nonce"), "the id token is checked against the nonce of the state")
+}
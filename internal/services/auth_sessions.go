@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/contextstore"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/shared"
+	"github.com/tkahng/playground/internal/stores"
+	"github.com/tkahng/playground/internal/tools/geoip"
+	"github.com/tkahng/playground/internal/tools/mailer"
+	"github.com/tkahng/playground/internal/tools/security"
+	"github.com/tkahng/playground/internal/workers"
+)
+
+var (
+	ErrSessionRevoked  = errors.New("session revoked")
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrRefreshTokenReused is returned for refresh tokens that were already
+	// exchanged, their session is revoked.
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+)
+
+// refreshTokenGrace is how long the refresh token a session was just rotated
+// from stays valid.
+const refreshTokenGrace = 10 * time.Second
+
+// rotateRefreshToken uses the refresh token and returns the key of the token
+// it is rotated to. Within refreshTokenGrace of its use the token returns the
+// same successor again, so concurrent refreshes do not branch the session.
+// Other uses, or uses of pruned tokens, return ErrRefreshTokenReused.
+func (app *BaseAuthService) rotateRefreshToken(ctx context.Context, tx stores.StorageAdapterInterface, claims *shared.RefreshTokenClaims) (string, error) {
+	// the row lock of the update serializes refreshes with one token
+	used, err := tx.Token().UseToken(ctx, claims.Token)
+	if err != nil {
+		return "", err
+	}
+	if used {
+		tokenKey := security.GenerateTokenKey()
+		if err := app.saveRefreshToken(ctx, tx, claims.UserId, claims.Email, claims.SessionID, tokenKey); err != nil {
+			return "", err
+		}
+		// replays of the pruned tokens are told apart by the token being gone
+		err = tx.Token().DeleteUsedSessionTokens(ctx, claims.SessionID, models.TokenTypesRefreshToken, time.Now().Add(-refreshTokenGrace))
+		return tokenKey, err
+	}
+	stored, err := tx.Token().GetToken(ctx, claims.Token)
+	if errors.Is(err, shared.ErrTokenNotFound) {
+		return "", ErrRefreshTokenReused
+	}
+	if err != nil {
+		return "", fmt.Errorf("error getting token: %w", err)
+	}
+	if stored.UsedAt == nil || time.Since(*stored.UsedAt) > refreshTokenGrace {
+		return "", ErrRefreshTokenReused
+	}
+	successor, err := tx.Token().FindNextSessionToken(ctx, claims.SessionID, models.TokenTypesRefreshToken, stored.CreatedAt)
+	if err != nil {
+		return "", err
+	}
+	if successor == nil || successor.UsedAt != nil {
+		return "", ErrRefreshTokenReused
+	}
+	return successor.Token, nil
+}
+
+// sessionCity looks the city of an ip up, it is empty when it is unknown.
+var sessionCity = func(ip string) string {
+	if ip == "" {
+		return ""
+	}
+	city, err := geoip.City(ip)
+	if err != nil {
+		return ""
+	}
+	return city.City.Names.English
+}
+
+var (
+	sessionBrowsers = []struct{ token, name string }{
+		// the order matters, most user agents also claim to be Safari
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"CriOS/", "Chrome"},
+		{"Safari/", "Safari"},
+	}
+	sessionSystems = []struct{ token, name string }{
+		{"iPhone", "iPhone"},
+		{"iPad", "iPad"},
+		{"Android", "Android"},
+		{"Mac OS X", "macOS"},
+		{"Windows", "Windows"},
+		{"CrOS", "ChromeOS"},
+		{"Linux", "Linux"},
+	}
+)
+
+// SessionDevice names the browser and system of a user agent, such as
+// "Firefox on Windows".
+func SessionDevice(userAgent string) string {
+	var browser, system string
+	for _, b := range sessionBrowsers {
+		if strings.Contains(userAgent, b.token) {
+			browser = b.name
+			break
+		}
+	}
+	for _, s := range sessionSystems {
+		if strings.Contains(userAgent, s.token) {
+			system = s.name
+			break
+		}
+	}
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	}
+	// api clients such as curl/8.0
+	name, _, _ := strings.Cut(userAgent, "/")
+	return name
+}
+
+// createSession records a sign in of the user with the client of the request.
+func (app *BaseAuthService) createSession(ctx context.Context, userID uuid.UUID) (*models.UserSession, error) {
+	userAgent := contextstore.GetContextUserAgent(ctx)
+	ip := contextstore.GetContextIPAddress(ctx)
+	return app.adapter.UserSession().CreateUserSession(ctx, &models.UserSession{
+		UserID:       userID,
+		Expires:      app.config.AuthOptions.RefreshToken.Expires(),
+		SessionToken: security.GenerateTokenKey(),
+		UserAgent:    userAgent,
+		Device:       SessionDevice(userAgent),
+		IPAddress:    ip,
+		City:         sessionCity(ip),
+	})
+}
+
+// checkSession rejects the tokens of revoked sessions and records the
+// session as seen. Tokens issued before sessions were recorded have none.
+func (app *BaseAuthService) checkSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error {
+	if sessionID == uuid.Nil {
+		return nil
+	}
+	session, err := app.adapter.UserSession().FindUserSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.UserID != userID {
+		return ErrSessionRevoked
+	}
+	if err := app.adapter.UserSession().TouchUserSession(ctx, sessionID); err != nil {
+		slog.ErrorContext(ctx, "error updating last seen of session", slog.Any("error", err))
+	}
+	return nil
+}
+
+// Sessions implements AuthService.
+func (app *BaseAuthService) Sessions(ctx context.Context, userId uuid.UUID) ([]*models.UserSession, error) {
+	return app.adapter.UserSession().ListUserSessions(ctx, userId)
+}
+
+// RevokeSession implements AuthService.
+func (app *BaseAuthService) RevokeSession(ctx context.Context, userId uuid.UUID, sessionId uuid.UUID) error {
+	ok, err := app.adapter.UserSession().DeleteUserSession(ctx, userId, sessionId)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeSessions implements AuthService.
+func (app *BaseAuthService) RevokeSessions(ctx context.Context, userId uuid.UUID) error {
+	return app.adapter.UserSession().DeleteUserSessions(ctx, userId)
+}
+
+// revokeTokenFamily signs out the session of a refresh token that was used
+// twice and emails the user about it. It returns ErrRefreshTokenReused.
+func (app *BaseAuthService) revokeTokenFamily(ctx context.Context, claims *shared.RefreshTokenClaims) error {
+	slog.WarnContext(ctx, "refresh token reused, revoking its session",
+		slog.String("user_id", claims.UserId.String()),
+		slog.String("session_id", claims.SessionID.String()),
+	)
+	session, err := app.adapter.UserSession().FindUserSession(ctx, claims.SessionID)
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		// revoked by an earlier replay
+		return ErrRefreshTokenReused
+	}
+	if _, err := app.adapter.UserSession().DeleteUserSession(ctx, claims.UserId, session.ID); err != nil {
+		return err
+	}
+	err = app.jobService.EnqueueSecurityAlertJob(ctx, &workers.SecurityAlertJobArgs{
+		UserID:    claims.UserId,
+		Alert:     mailer.SecurityAlertRefreshTokenReuse,
+		Device:    session.Device,
+		IPAddress: session.IPAddress,
+		City:      session.City,
+		At:        time.Now(),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "error enqueueing security alert", slog.Any("error", err))
+	}
+	return ErrRefreshTokenReused
+}
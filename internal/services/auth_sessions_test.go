@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkahng/playground/internal/contextstore"
+	"github.com/tkahng/playground/internal/tools/mailer"
+)
+
+func TestSessions_RecordTheClient(t *testing.T) {
+	app := newTestAuthService()
+	user := app.users.add("session@example.com", nil)
+	city := sessionCity
+	t.Cleanup(func() { sessionCity = city })
+	sessionCity = func(ip string) string {
+		if ip == "5.182.16.30" {
+			return "Berlin"
+		}
+		return ""
+	}
+
+	ctx := contextstore.SetContextIPAddress(context.Background(), "5.182.16.30")
+	ctx = contextstore.SetContextUserAgent(ctx, "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:128.0) Gecko/20100101 Firefox/128.0")
+	tokens, err := app.CreateAuthTokens(ctx, user)
+	require.NoError(t, err)
+
+	info, err := app.HandleAccessToken(context.Background(), tokens.Tokens.AccessToken)
+	require.NoError(t, err)
+	require.NotEqual(t, uuid.Nil, info.SessionID)
+
+	sessions, err := app.Sessions(ctx, user.User.ID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, info.SessionID, sessions[0].ID)
+	assert.Equal(t, "Firefox on Windows", sessions[0].Device)
+	assert.Equal(t, "5.182.16.30", sessions[0].IPAddress)
+	assert.Equal(t, "Berlin", sessions[0].City)
+}
+
+func TestSessions_Revoke(t *testing.T) {
+	ctx := context.Background()
+	app := newTestAuthService()
+	user := app.users.add("session@example.com", nil)
+
+	laptop, err := app.CreateAuthTokens(ctx, user)
+	require.NoError(t, err)
+	phone, err := app.CreateAuthTokens(ctx, user)
+	require.NoError(t, err)
+
+	// refreshing keeps the session
+	refreshed, err := app.HandleRefreshToken(ctx, laptop.Tokens.RefreshToken)
+	require.NoError(t, err)
+	laptopInfo, err := app.HandleAccessToken(ctx, laptop.Tokens.AccessToken)
+	require.NoError(t, err)
+	refreshedInfo, err := app.HandleAccessToken(ctx, refreshed.Tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, laptopInfo.SessionID, refreshedInfo.SessionID)
+
+	assert.ErrorIs(t, app.RevokeSession(ctx, uuid.New(), laptopInfo.SessionID), ErrSessionNotFound, "users only revoke their own sessions")
+	require.NoError(t, app.RevokeSession(ctx, user.User.ID, laptopInfo.SessionID))
+
+	// the access token is rejected before it expires
+	_, err = app.HandleAccessToken(ctx, refreshed.Tokens.AccessToken)
+	assert.ErrorIs(t, err, ErrSessionRevoked)
+	_, err = app.HandleRefreshToken(ctx, refreshed.Tokens.RefreshToken)
+	assert.Error(t, err)
+
+	// other devices stay signed in
+	_, err = app.HandleAccessToken(ctx, phone.Tokens.AccessToken)
+	assert.NoError(t, err)
+
+	require.NoError(t, app.RevokeSessions(ctx, user.User.ID))
+	_, err = app.HandleAccessToken(ctx, phone.Tokens.AccessToken)
+	assert.ErrorIs(t, err, ErrSessionRevoked)
+}
+
+func TestSessions_Signout(t *testing.T) {
+	ctx := context.Background()
+	app := newTestAuthService()
+	user := app.users.add("session@example.com", nil)
+
+	tokens, err := app.CreateAuthTokens(ctx, user)
+	require.NoError(t, err)
+	require.NoError(t, app.Signout(ctx, tokens.Tokens.RefreshToken))
+
+	assert.Empty(t, app.sessions.sessions)
+	assert.Empty(t, app.sessions.tokens)
+	_, err = app.HandleAccessToken(ctx, tokens.Tokens.AccessToken)
+	assert.ErrorIs(t, err, ErrSessionRevoked)
+}
+
+func TestSessions_RefreshTokenReuse(t *testing.T) {
+	app := newTestAuthService()
+	user := app.users.add("session@example.com", nil)
+	ctx := contextstore.SetContextIPAddress(context.Background(), "5.182.16.30")
+	ctx = contextstore.SetContextUserAgent(ctx, "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:128.0) Gecko/20100101 Firefox/128.0")
+	tokens, err := app.CreateAuthTokens(ctx, user)
+	require.NoError(t, err)
+	other, err := app.CreateAuthTokens(ctx, user)
+	require.NoError(t, err)
+
+	// every refresh rotates the refresh token
+	rotated, err := app.HandleRefreshToken(ctx, tokens.Tokens.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, tokens.Tokens.RefreshToken, rotated.Tokens.RefreshToken)
+	rotatedAgain, err := app.HandleRefreshToken(ctx, rotated.Tokens.RefreshToken)
+	require.NoError(t, err)
+	assert.Empty(t, app.alerts)
+
+	// replaying a used token revokes the whole family
+	_, err = app.HandleRefreshToken(ctx, tokens.Tokens.RefreshToken)
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	_, err = app.HandleRefreshToken(ctx, rotatedAgain.Tokens.RefreshToken)
+	assert.Error(t, err)
+	_, err = app.HandleAccessToken(ctx, rotatedAgain.Tokens.AccessToken)
+	assert.ErrorIs(t, err, ErrSessionRevoked)
+
+	require.Len(t, app.alerts, 1)
+	alert := app.alerts[0]
+	assert.Equal(t, user.User.ID, alert.UserID)
+	assert.Equal(t, mailer.SecurityAlertRefreshTokenReuse, alert.Alert)
+	assert.Equal(t, "Firefox on Windows", alert.Device)
+	assert.Equal(t, "5.182.16.30", alert.IPAddress)
+
+	// other sessions are untouched
+	_, err = app.HandleRefreshToken(ctx, other.Tokens.RefreshToken)
+	assert.NoError(t, err)
+}
+
+func TestSessions_ConcurrentRefresh(t *testing.T) {
+	ctx := context.Background()
+	app := newTestAuthService()
+	user := app.users.add("session@example.com", nil)
+	tokens, err := app.CreateAuthTokens(ctx, user)
+	require.NoError(t, err)
+
+	errs := make(chan error, 2)
+	for range 2 {
+		go func() {
+			_, err := app.HandleRefreshToken(ctx, tokens.Tokens.RefreshToken)
+			errs <- err
+		}()
+	}
+	for range 2 {
+		assert.NoError(t, <-errs, "the previous token is accepted within the grace window")
+	}
+	assert.Empty(t, app.alerts)
+}
+
+func TestSessions_RefreshTokenGrace(t *testing.T) {
+	ctx := context.Background()
+	app := newTestAuthService()
+	user := app.users.add("session@example.com", nil)
+	tokens, err := app.CreateAuthTokens(ctx, user)
+	require.NoError(t, err)
+
+	_, err = app.HandleRefreshToken(ctx, tokens.Tokens.RefreshToken)
+	require.NoError(t, err)
+	// a retry whose response was lost gets the same successor, the session
+	// does not branch
+	retried, err := app.HandleRefreshToken(ctx, tokens.Tokens.RefreshToken)
+	require.NoError(t, err)
+	assert.Len(t, app.sessions.tokens, 2)
+	assert.Empty(t, app.alerts)
+
+	// after the window the previous token is reuse
+	app.sessions.mu.Lock()
+	for _, token := range app.sessions.tokens {
+		if token.UsedAt != nil {
+			usedAt := time.Now().Add(-refreshTokenGrace - time.Second)
+			token.UsedAt = &usedAt
+		}
+	}
+	app.sessions.mu.Unlock()
+	_, err = app.HandleRefreshToken(ctx, tokens.Tokens.RefreshToken)
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	assert.Len(t, app.alerts, 1)
+	_, err = app.HandleAccessToken(ctx, retried.Tokens.AccessToken)
+	assert.ErrorIs(t, err, ErrSessionRevoked)
+}
+
+func TestSessions_RefreshTokenReplayAfterRotation(t *testing.T) {
+	ctx := context.Background()
+	app := newTestAuthService()
+	user := app.users.add("session@example.com", nil)
+	tokens, err := app.CreateAuthTokens(ctx, user)
+	require.NoError(t, err)
+
+	rotated, err := app.HandleRefreshToken(ctx, tokens.Tokens.RefreshToken)
+	require.NoError(t, err)
+	_, err = app.HandleRefreshToken(ctx, rotated.Tokens.RefreshToken)
+	require.NoError(t, err)
+
+	// once the successor was used a replay is reuse even within the window
+	_, err = app.HandleRefreshToken(ctx, tokens.Tokens.RefreshToken)
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	assert.Len(t, app.alerts, 1)
+}
+
+func TestSessions_RefreshTokenPruning(t *testing.T) {
+	ctx := context.Background()
+	app := newTestAuthService()
+	user := app.users.add("session@example.com", nil)
+	tokens, err := app.CreateAuthTokens(ctx, user)
+	require.NoError(t, err)
+	rotated, err := app.HandleRefreshToken(ctx, tokens.Tokens.RefreshToken)
+	require.NoError(t, err)
+
+	app.sessions.mu.Lock()
+	for _, token := range app.sessions.tokens {
+		if token.UsedAt != nil {
+			usedAt := time.Now().Add(-refreshTokenGrace - time.Second)
+			token.UsedAt = &usedAt
+		}
+	}
+	app.sessions.mu.Unlock()
+	// the next rotation prunes the tokens used before the window
+	_, err = app.HandleRefreshToken(ctx, rotated.Tokens.RefreshToken)
+	require.NoError(t, err)
+	assert.Len(t, app.sessions.tokens, 2)
+
+	// a replay of a pruned token is still reuse
+	_, err = app.HandleRefreshToken(ctx, tokens.Tokens.RefreshToken)
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	assert.Len(t, app.alerts, 1)
+}
+
+func TestSessionDevice(t *testing.T) {
+	tests := map[string]string{
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36":                   "Chrome on macOS",
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36 Edg/126.0.0.0":           "Edge on Windows",
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1": "Safari on iPhone",
+		"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Mobile Safari/537.36":                   "Chrome on Android",
+		"curl/8.7.1": "curl",
+		"":           "",
+	}
+	for userAgent, device := range tests {
+		assert.Equal(t, device, SessionDevice(userAgent), userAgent)
+	}
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/auth/oauth"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/stores"
+	"github.com/tkahng/playground/internal/workers"
+)
+
+var (
+	// ErrSsoRequired is returned when a user of a domain whose team enforces
+	// SSO signs in another way.
+	ErrSsoRequired = errors.New("sign in through the sso of your team")
+	// ErrSsoNotConfigured is returned for emails of domains without a team IdP.
+	ErrSsoNotConfigured = errors.New("sso is not configured")
+	// ErrSsoEmailNotAllowed is returned when the IdP of a team signs in an
+	// email of a domain the team did not verify.
+	ErrSsoEmailNotAllowed = errors.New("email domain is not verified by the team")
+)
+
+// EmailDomain returns the lower cased domain of an email.
+func EmailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSuffix(email[at+1:], "."))
+}
+
+// teamSsoForEmail returns the IdP of the team that verified the domain of the
+// email, or nil.
+func (app *BaseAuthService) teamSsoForEmail(ctx context.Context, email string) (*models.TeamSsoConfig, error) {
+	domain := EmailDomain(email)
+	if domain == "" {
+		return nil, nil
+	}
+	verified, err := app.adapter.TeamSso().FindVerifiedTeamSsoDomain(ctx, domain)
+	if err != nil || verified == nil {
+		return nil, err
+	}
+	return app.adapter.TeamSso().FindTeamSsoConfig(ctx, verified.TeamID)
+}
+
+// checkTeamSso enforces the SSO of teams. Users of an enforced domain only
+// sign in through the IdP of its team, and the IdP of a team only signs in
+// users of the domains the team verified, so it can not take over other
+// accounts. It returns the IdP of sign ins through one.
+func (app *BaseAuthService) checkTeamSso(ctx context.Context, params *AuthenticationInput) (*models.TeamSsoConfig, error) {
+	config, err := app.teamSsoForEmail(ctx, params.Email)
+	if err != nil {
+		return nil, err
+	}
+	if teamID, ok := models.ParseTeamSsoProvider(params.Provider); ok {
+		if config == nil || config.TeamID != teamID {
+			return nil, ErrSsoEmailNotAllowed
+		}
+		return config, nil
+	}
+	if config != nil && config.Enforced {
+		return nil, ErrSsoRequired
+	}
+	return nil, nil
+}
+
+// provisionTeamSsoMember makes a user signed in through the IdP of a team a
+// member of it with the default role of the IdP.
+func (app *BaseAuthService) provisionTeamSsoMember(ctx context.Context, user *models.User, config *models.TeamSsoConfig) error {
+	member, err := app.adapter.TeamMember().FindTeamMember(ctx, &stores.TeamMemberFilter{
+		TeamIds: []uuid.UUID{config.TeamID},
+		UserIds: []uuid.UUID{user.ID},
+	})
+	if err != nil || member != nil {
+		return err
+	}
+	member, err = app.adapter.TeamMember().CreateTeamMember(ctx, config.TeamID, user.ID, config.DefaultRole, false)
+	if err != nil {
+		return err
+	}
+	err = app.jobService.EnqueueRefreshSubscriptionQuantityJob(ctx, &workers.RefreshSubscriptionQuantityJobArgs{
+		TeamID: member.TeamID,
+	})
+	if err != nil {
+		return err
+	}
+	return app.jobService.EnqueueTeamMemberAddedJob(ctx, &workers.NewMemberNotificationJobArgs{
+		TeamMemberID: member.ID,
+	})
+}
+
+// teamSsoProvider returns the IdP of the team as an OIDC provider.
+func (app *BaseAuthService) teamSsoProvider(ctx context.Context, teamID uuid.UUID) (oauth.ProviderConfig, error) {
+	config, err := app.adapter.TeamSso().FindTeamSsoConfig(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, ErrSsoNotConfigured
+	}
+	name := string(models.TeamSsoProvider(teamID))
+	if cached, ok := app.oidcProviders.Load(name); ok && cached.(*cachedOidcProvider).updatedAt.Equal(config.UpdatedAt) {
+		return cached.(*cachedOidcProvider).provider, nil
+	}
+	provider := oauth.NewOIDCProvider(oauth.OIDCOptions{
+		Name:         name,
+		DisplayName:  "SSO",
+		Issuer:       config.Issuer,
+		ClientID:     config.ClientID,
+		ClientSecret: config.ClientSecret,
+		Scopes:       config.Scopes,
+		RedirectURL:  app.config.AppUrl + app.config.AuthCallback,
+		// teams configure the issuer, it must not reach internal hosts
+		HTTPClient: oauth.NewPublicHTTPClient(10 * time.Second),
+	})
+	app.oidcProviders.Store(name, &cachedOidcProvider{updatedAt: config.UpdatedAt, provider: provider})
+	return provider, nil
+}
+
+// CreateSsoUrl implements AuthService.
+func (app *BaseAuthService) CreateSsoUrl(ctx context.Context, email string, redirectUrl string) (string, error) {
+	config, err := app.teamSsoForEmail(ctx, email)
+	if err != nil {
+		return "", err
+	}
+	if config == nil {
+		return "", ErrSsoNotConfigured
+	}
+	return app.CreateOAuthUrl(ctx, models.TeamSsoProvider(config.TeamID), redirectUrl)
+}
@@ -12,6 +12,9 @@ import (
 )
 
 type JobService interface {
+	// WithTx returns a JobService that enqueues through db. Use it with the
+	// transaction of a domain write so the job commits or rolls back with it,
+	// see jobs.JobManager.WithTx.
 	WithTx(db database.Dbx) JobService
 
 	EnqueueTaskCompletedJob(ctx context.Context, job *workers.TaskCompletedJobArgs) error
@@ -20,8 +23,13 @@ type JobService interface {
 	EnqueueTeamMemberAddedJob(ctx context.Context, job *workers.NewMemberNotificationJobArgs) error
 	EnqueueRefreshSubscriptionQuantityJob(ctx context.Context, job *workers.RefreshSubscriptionQuantityJobArgs) error
 	EnqueueOtpMailJob(ctx context.Context, args *workers.OtpEmailJobArgs) error
+	EnqueueSecurityAlertJob(ctx context.Context, args *workers.SecurityAlertJobArgs) error
 	EnqueueTeamInvitationJob(ctx context.Context, args *workers.TeamInvitationJobArgs) error
-	RegisterWorkers(mail OtpMailService, paymentService PaymentService, notification Notifier)
+	EnqueueNotificationEmailJob(ctx context.Context, args *workers.NotificationEmailJobArgs) error
+	EnqueueNotificationDigestJob(ctx context.Context, args *workers.NotificationDigestJobArgs, runAfter time.Time) error
+	EnqueueNotificationDigestEmailJob(ctx context.Context, args *workers.NotificationDigestEmailJobArgs) error
+	EnqueueNotificationRetentionJob(ctx context.Context, args *workers.NotificationRetentionJobArgs, runAfter time.Time) error
+	RegisterWorkers(mail OtpMailService, paymentService PaymentService, notification Notifier, notificationMail NotificationMailService, notificationService NotificationService)
 }
 
 type DbJobService struct {
@@ -91,15 +99,61 @@ func (d *DbJobService) EnqueueTeamInvitationJob(ctx context.Context, args *worke
 	})
 }
 
+// EnqueueNotificationEmailJob implements JobService.
+func (d *DbJobService) EnqueueNotificationEmailJob(ctx context.Context, args *workers.NotificationEmailJobArgs) error {
+	return d.manager.Enqueue(ctx, &jobs.EnqueueParams{
+		Args:        args,
+		RunAfter:    time.Now(),
+		MaxAttempts: 3,
+	})
+}
+
+// EnqueueNotificationDigestJob implements JobService. There is at most one
+// digest job per day, enqueueing it again only moves it.
+func (d *DbJobService) EnqueueNotificationDigestJob(ctx context.Context, args *workers.NotificationDigestJobArgs, runAfter time.Time) error {
+	return d.manager.Enqueue(ctx, &jobs.EnqueueParams{
+		Args:        args,
+		UniqueKey:   types.Pointer("notification_digest:" + args.Day.Format(time.DateOnly)),
+		RunAfter:    runAfter,
+		MaxAttempts: 3,
+	})
+}
+
+// EnqueueNotificationDigestEmailJob implements JobService.
+func (d *DbJobService) EnqueueNotificationDigestEmailJob(ctx context.Context, args *workers.NotificationDigestEmailJobArgs) error {
+	return d.manager.Enqueue(ctx, &jobs.EnqueueParams{
+		Args:        args,
+		UniqueKey:   types.Pointer("notification_digest_email:" + args.TeamMemberID.String() + ":" + args.Since.Format(time.DateOnly)),
+		RunAfter:    time.Now(),
+		MaxAttempts: 3,
+	})
+}
+
+// EnqueueNotificationRetentionJob implements JobService. There is at most one
+// retention job per day.
+func (d *DbJobService) EnqueueNotificationRetentionJob(ctx context.Context, args *workers.NotificationRetentionJobArgs, runAfter time.Time) error {
+	return d.manager.Enqueue(ctx, &jobs.EnqueueParams{
+		Args:        args,
+		UniqueKey:   types.Pointer("notification_retention:" + args.Day.Format(time.DateOnly)),
+		RunAfter:    runAfter,
+		MaxAttempts: 3,
+	})
+}
+
 // RegisterWorkers implements JobService.
-func (d *DbJobService) RegisterWorkers(mail OtpMailService, paymentService PaymentService, notification Notifier) {
+func (d *DbJobService) RegisterWorkers(mail OtpMailService, paymentService PaymentService, notification Notifier, notificationMail NotificationMailService, notificationService NotificationService) {
 	jobs.RegisterWorker(d.manager, workers.NewOtpEmailWorker(mail))
 	jobs.RegisterWorker(d.manager, workers.NewTeamInvitationWorker(mail))
+	jobs.RegisterWorker(d.manager, workers.NewSecurityAlertWorker(mail))
 	jobs.RegisterWorker(d.manager, workers.NewRefreshSubscriptionQuantityWorker(paymentService))
 	jobs.RegisterWorker(d.manager, workers.NewNewMemberNotificationWorker(notification))
 	jobs.RegisterWorker(d.manager, NewAssignedToTaskWorker(notification))
 	jobs.RegisterWorker(d.manager, NewTaskDueTodayWorker(notification))
 	jobs.RegisterWorker(d.manager, NewTaskCompletedWorker(notification))
+	jobs.RegisterWorker(d.manager, NewNotificationEmailWorker(notificationMail))
+	jobs.RegisterWorker(d.manager, NewNotificationDigestWorker(notificationMail))
+	jobs.RegisterWorker(d.manager, NewNotificationDigestEmailWorker(notificationMail))
+	jobs.RegisterWorker(d.manager, NewNotificationRetentionWorker(notificationService))
 }
 
 // EnqueueOtpMailJob implements JobService.
@@ -111,6 +165,15 @@ func (d *DbJobService) EnqueueOtpMailJob(ctx context.Context, job *workers.OtpEm
 	})
 }
 
+// EnqueueSecurityAlertJob implements JobService.
+func (d *DbJobService) EnqueueSecurityAlertJob(ctx context.Context, args *workers.SecurityAlertJobArgs) error {
+	return d.manager.Enqueue(ctx, &jobs.EnqueueParams{
+		Args:        args,
+		RunAfter:    time.Now(),
+		MaxAttempts: 3,
+	})
+}
+
 func NewJobService(manager jobs.JobManager) JobService {
 	return &DbJobService{
 		manager: manager,
@@ -120,14 +183,63 @@ func NewJobService(manager jobs.JobManager) JobService {
 type JobServiceDecorator struct {
 	Delegate                                  JobService
 	EnqueueOtpMailJobFunc                     func(ctx context.Context, job *workers.OtpEmailJobArgs) error
+	EnqueueSecurityAlertJobFunc               func(ctx context.Context, args *workers.SecurityAlertJobArgs) error
 	EnqueueTeamInvitationFunc                 func(ctx context.Context, job *workers.TeamInvitationJobArgs) error
-	RegisterWorkersFunc                       func(mail OtpMailService, paymentService PaymentService, notification Notifier)
+	RegisterWorkersFunc                       func(mail OtpMailService, paymentService PaymentService, notification Notifier, notificationMail NotificationMailService, notificationService NotificationService)
 	EnqueueTeamMemberAddedJobFunc             func(ctx context.Context, job *workers.NewMemberNotificationJobArgs) error
 	WithTxFunc                                func(db database.Dbx) JobService
 	EnqueueRefreshSubscriptionQuantityJobFunc func(ctx context.Context, job *workers.RefreshSubscriptionQuantityJobArgs) error
 	EnqueAssignedToTaskJobFunc                func(ctx context.Context, job *workers.AssignedToTasJobArgs) error
 	EnqueTaskDueJobFunc                       func(ctx context.Context, job *workers.TaskDueTodayJobArgs) error
 	EnqueueTaskCompletedJobFunc               func(ctx context.Context, job *workers.TaskCompletedJobArgs) error
+	EnqueueNotificationEmailJobFunc           func(ctx context.Context, args *workers.NotificationEmailJobArgs) error
+	EnqueueNotificationDigestJobFunc          func(ctx context.Context, args *workers.NotificationDigestJobArgs, runAfter time.Time) error
+	EnqueueNotificationDigestEmailJobFunc     func(ctx context.Context, args *workers.NotificationDigestEmailJobArgs) error
+	EnqueueNotificationRetentionJobFunc       func(ctx context.Context, args *workers.NotificationRetentionJobArgs, runAfter time.Time) error
+}
+
+// EnqueueNotificationRetentionJob implements JobService.
+func (j *JobServiceDecorator) EnqueueNotificationRetentionJob(ctx context.Context, args *workers.NotificationRetentionJobArgs, runAfter time.Time) error {
+	if j.EnqueueNotificationRetentionJobFunc != nil {
+		return j.EnqueueNotificationRetentionJobFunc(ctx, args, runAfter)
+	}
+	if j.Delegate == nil {
+		return errors.New("delegate for EnqueueNotificationRetentionJob in JobService is nil")
+	}
+	return j.Delegate.EnqueueNotificationRetentionJob(ctx, args, runAfter)
+}
+
+// EnqueueNotificationEmailJob implements JobService.
+func (j *JobServiceDecorator) EnqueueNotificationEmailJob(ctx context.Context, args *workers.NotificationEmailJobArgs) error {
+	if j.EnqueueNotificationEmailJobFunc != nil {
+		return j.EnqueueNotificationEmailJobFunc(ctx, args)
+	}
+	if j.Delegate == nil {
+		return errors.New("delegate for EnqueueNotificationEmailJob in JobService is nil")
+	}
+	return j.Delegate.EnqueueNotificationEmailJob(ctx, args)
+}
+
+// EnqueueNotificationDigestJob implements JobService.
+func (j *JobServiceDecorator) EnqueueNotificationDigestJob(ctx context.Context, args *workers.NotificationDigestJobArgs, runAfter time.Time) error {
+	if j.EnqueueNotificationDigestJobFunc != nil {
+		return j.EnqueueNotificationDigestJobFunc(ctx, args, runAfter)
+	}
+	if j.Delegate == nil {
+		return errors.New("delegate for EnqueueNotificationDigestJob in JobService is nil")
+	}
+	return j.Delegate.EnqueueNotificationDigestJob(ctx, args, runAfter)
+}
+
+// EnqueueNotificationDigestEmailJob implements JobService.
+func (j *JobServiceDecorator) EnqueueNotificationDigestEmailJob(ctx context.Context, args *workers.NotificationDigestEmailJobArgs) error {
+	if j.EnqueueNotificationDigestEmailJobFunc != nil {
+		return j.EnqueueNotificationDigestEmailJobFunc(ctx, args)
+	}
+	if j.Delegate == nil {
+		return errors.New("delegate for EnqueueNotificationDigestEmailJob in JobService is nil")
+	}
+	return j.Delegate.EnqueueNotificationDigestEmailJob(ctx, args)
 }
 
 // EnqueueTaskCompletedJob implements JobService.
@@ -199,11 +311,11 @@ func (j *JobServiceDecorator) EnqueueTeamInvitationJob(ctx context.Context, args
 }
 
 // RegisterWorkers implements JobService.
-func (j *JobServiceDecorator) RegisterWorkers(mail OtpMailService, paymentService PaymentService, notification Notifier) {
+func (j *JobServiceDecorator) RegisterWorkers(mail OtpMailService, paymentService PaymentService, notification Notifier, notificationMail NotificationMailService, notificationService NotificationService) {
 	if j.RegisterWorkersFunc != nil {
-		j.RegisterWorkersFunc(mail, paymentService, notification)
+		j.RegisterWorkersFunc(mail, paymentService, notification, notificationMail, notificationService)
 	}
-	j.Delegate.RegisterWorkers(mail, paymentService, notification)
+	j.Delegate.RegisterWorkers(mail, paymentService, notification, notificationMail, notificationService)
 }
 
 // EnqueueOtpMailJob implements JobService.
@@ -214,6 +326,14 @@ func (j *JobServiceDecorator) EnqueueOtpMailJob(ctx context.Context, job *worker
 	return j.Delegate.EnqueueOtpMailJob(ctx, job)
 }
 
+// EnqueueSecurityAlertJob implements JobService.
+func (j *JobServiceDecorator) EnqueueSecurityAlertJob(ctx context.Context, args *workers.SecurityAlertJobArgs) error {
+	if j.EnqueueSecurityAlertJobFunc != nil {
+		return j.EnqueueSecurityAlertJobFunc(ctx, args)
+	}
+	return j.Delegate.EnqueueSecurityAlertJob(ctx, args)
+}
+
 func NewJobServiceDecorator(enqueuer jobs.JobManager) *JobServiceDecorator {
 	var delegate JobService
 	if enqueuer != nil {
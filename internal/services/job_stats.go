@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/stores"
+	"github.com/tkahng/playground/internal/tools/sse"
+)
+
+// JobStatsWindows are the sliding windows failure rates are reported for.
+var JobStatsWindows = []time.Duration{
+	5 * time.Minute,
+	time.Hour,
+	24 * time.Hour,
+}
+
+// JobStatsStore computes job stats, the database store or the memory store
+// of the configured jobs backend.
+type JobStatsStore interface {
+	GetJobStats(ctx context.Context, since time.Time, windows ...time.Duration) (*models.JobStats, error)
+}
+
+// JobAdminStore reads and updates the jobs of the configured backend for the
+// admin apis.
+type JobAdminStore interface {
+	JobStatsStore
+	FindJob(ctx context.Context, filter *stores.JobFilter) (*models.JobRow, error)
+	FindJobs(ctx context.Context, filter *stores.JobFilter) ([]*models.JobRow, error)
+	CountJobs(ctx context.Context, filter *stores.JobFilter) (int64, error)
+	UpdateJob(ctx context.Context, job *models.JobRow) (*models.JobRow, error)
+	GetJobGroup(ctx context.Context, groupID uuid.UUID) (*models.JobGroup, error)
+}
+
+type JobStatsSseEvent struct {
+	JobStats *models.JobStats `json:"job_stats"`
+}
+
+// JobStatsPublisher periodically sends job stats to the clients subscribed
+// to sse.AdminJobStatsChannel. Stats are only computed while at least one
+// client is connected.
+type JobStatsPublisher struct {
+	logger     *slog.Logger
+	store      JobStatsStore
+	sseManager sse.Manager
+}
+
+func NewJobStatsPublisher(logger *slog.Logger, store JobStatsStore, sseManager sse.Manager) *JobStatsPublisher {
+	return &JobStatsPublisher{
+		logger:     logger,
+		store:      store,
+		sseManager: sseManager,
+	}
+}
+
+// Stats returns the stats of the jobs that finished in the last 24 hours.
+func (p *JobStatsPublisher) Stats(ctx context.Context) (*models.JobStats, error) {
+	return p.store.GetJobStats(ctx, time.Now().Add(-24*time.Hour), JobStatsWindows...)
+}
+
+func (p *JobStatsPublisher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !p.hasSubscribers() {
+				continue
+			}
+			if err := p.Publish(ctx); err != nil {
+				p.logger.ErrorContext(ctx, "failed to publish job stats", slog.Any("error", err))
+			}
+		}
+	}
+}
+
+// Publish computes the current stats and sends them to every subscriber.
+func (p *JobStatsPublisher) Publish(ctx context.Context) error {
+	stats, err := p.Stats(ctx)
+	if err != nil {
+		return err
+	}
+	return p.sseManager.Send(sse.AdminJobStatsChannel, JobStatsSseEvent{
+		JobStats: stats,
+	})
+}
+
+func (p *JobStatsPublisher) hasSubscribers() bool {
+	for _, c := range p.sseManager.Clients() {
+		if c.Subscribed(sse.AdminJobStatsChannel) {
+			return true
+		}
+	}
+	return false
+}
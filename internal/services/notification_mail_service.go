@@ -0,0 +1,433 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/conf"
+	"github.com/tkahng/playground/internal/jobs"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/notification"
+	"github.com/tkahng/playground/internal/shared"
+	"github.com/tkahng/playground/internal/stores"
+	"github.com/tkahng/playground/internal/tools/mailer"
+	"github.com/tkahng/playground/internal/tools/types"
+	"github.com/tkahng/playground/internal/workers"
+)
+
+// UnsubscribePath is where the unsubscribe links of notification emails point to.
+const UnsubscribePath = "/api/notification-preferences/unsubscribe"
+
+// maxDigestItems caps the notifications listed in one digest email.
+const maxDigestItems = 50
+
+var ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe token")
+
+// ErrUnsubscribeTokenRevoked is wrapped in ErrInvalidUnsubscribeToken for
+// tokens issued before the user revoked their unsubscribe links.
+var ErrUnsubscribeTokenRevoked = errors.New("unsubscribe token revoked")
+
+type NotificationMailService interface {
+	// SendNotificationEmail sends one notification by email if the member
+	// still wants it.
+	SendNotificationEmail(ctx context.Context, args *workers.NotificationEmailJobArgs) error
+	// ScheduleDigest enqueues the next daily digest after now.
+	ScheduleDigest(ctx context.Context, now time.Time) error
+	// SendDigests enqueues a digest email for every member with unread
+	// notifications or notifications delivered by email only, and schedules
+	// the digest of the next day.
+	SendDigests(ctx context.Context, args *workers.NotificationDigestJobArgs) error
+	SendDigestEmail(ctx context.Context, args *workers.NotificationDigestEmailJobArgs) error
+	// Unsubscribe turns off what the unsubscribe token was issued for.
+	Unsubscribe(ctx context.Context, token string) (*shared.UnsubscribePayload, error)
+	// RevokeUnsubscribeLinks invalidates every unsubscribe link sent to the
+	// user so far.
+	RevokeUnsubscribeLinks(ctx context.Context, userID uuid.UUID) error
+}
+
+var _ NotificationMailService = (*DbNotificationMailService)(nil)
+
+type DbNotificationMailService struct {
+	options    *conf.EnvConfig
+	adapter    stores.StorageAdapterInterface
+	mail       mailer.Mailer
+	token      JwtService
+	jobService JobService
+}
+
+func NewNotificationMailService(
+	opts *conf.EnvConfig,
+	adapter stores.StorageAdapterInterface,
+	jobService JobService,
+) *DbNotificationMailService {
+	return &DbNotificationMailService{
+		options:    opts,
+		adapter:    adapter,
+		mail:       newMailer(opts),
+		token:      NewJwtService(),
+		jobService: jobService,
+	}
+}
+
+// NextDigestRun returns the day of the first digest after now and when it is
+// sent, at hour UTC.
+func NextDigestRun(now time.Time, hour int) (day time.Time, runAt time.Time) {
+	now = now.UTC()
+	day = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	runAt = day.Add(time.Duration(hour) * time.Hour)
+	if !runAt.After(now) {
+		day = day.AddDate(0, 0, 1)
+		runAt = runAt.AddDate(0, 0, 1)
+	}
+	return day, runAt
+}
+
+// ScheduleDigest implements NotificationMailService.
+func (s *DbNotificationMailService) ScheduleDigest(ctx context.Context, now time.Time) error {
+	day, runAt := NextDigestRun(now, s.options.NotificationDigestHour)
+	return s.jobService.EnqueueNotificationDigestJob(ctx, &workers.NotificationDigestJobArgs{Day: day}, runAt)
+}
+
+// SendDigests implements NotificationMailService.
+func (s *DbNotificationMailService) SendDigests(ctx context.Context, args *workers.NotificationDigestJobArgs) error {
+	runAt := args.Day.Add(time.Duration(s.options.NotificationDigestHour) * time.Hour)
+	since := runAt.AddDate(0, 0, -1)
+	// the digests of the previous days listed the older items
+	if _, err := s.adapter.Notification().DeleteNotificationDigestItems(ctx, since); err != nil {
+		return err
+	}
+	memberIDs, err := s.adapter.Notification().FindDigestTeamMemberIDs(ctx, since)
+	if err != nil {
+		return err
+	}
+	for _, memberID := range memberIDs {
+		err = s.jobService.EnqueueNotificationDigestEmailJob(ctx, &workers.NotificationDigestEmailJobArgs{
+			TeamMemberID: memberID,
+			Since:        since,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	next := args.Day.AddDate(0, 0, 1)
+	return s.jobService.EnqueueNotificationDigestJob(
+		ctx,
+		&workers.NotificationDigestJobArgs{Day: next},
+		next.Add(time.Duration(s.options.NotificationDigestHour)*time.Hour),
+	)
+}
+
+// recipient finds the member with its user and the preferences of the user.
+func (s *DbNotificationMailService) recipient(ctx context.Context, teamMemberID uuid.UUID) (*models.TeamMember, *models.User, []*models.NotificationPreference, error) {
+	member, err := s.adapter.TeamMember().FindTeamMember(ctx, &stores.TeamMemberFilter{
+		Ids: []uuid.UUID{teamMemberID},
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if member == nil || member.UserID == nil {
+		return nil, nil, nil, nil
+	}
+	user, err := s.adapter.User().FindUserByID(ctx, *member.UserID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if user == nil {
+		return nil, nil, nil, nil
+	}
+	preferences, err := s.adapter.Notification().FindNotificationPreferences(ctx, []uuid.UUID{user.ID})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return member, user, preferences, nil
+}
+
+// SendNotificationEmail implements NotificationMailService.
+func (s *DbNotificationMailService) SendNotificationEmail(ctx context.Context, args *workers.NotificationEmailJobArgs) error {
+	member, user, preferences, err := s.recipient(ctx, args.TeamMemberID)
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		return nil
+	}
+	// the member may have unsubscribed since the job was enqueued
+	preference := ResolveNotificationPreference(preferences, user.ID, member.TeamID, args.NotificationType)
+	if !preference.Delivery.Email() {
+		return nil
+	}
+	payload, err := notification.DecodePayload(args.NotificationType, args.Payload)
+	if err != nil {
+		return err
+	}
+	content, err := notification.DecodeContent(args.Payload)
+	if err != nil {
+		return err
+	}
+	tmpl, err := notification.EmailTemplate(args.NotificationType)
+	if err != nil {
+		return err
+	}
+	unsubscribeURL, err := s.unsubscribeURL(ctx, shared.UnsubscribePayload{
+		UserId:           user.ID,
+		TeamId:           &member.TeamID,
+		NotificationType: args.NotificationType,
+	})
+	if err != nil {
+		return err
+	}
+	appOpts := s.options.AppConfig
+	return s.mail.Send(&mailer.Message{
+		From:    appOpts.SenderAddress,
+		To:      user.Email,
+		Subject: fmt.Sprintf("%s - %s", appOpts.AppName, content.Title),
+		Body: mailer.GenerateBody("body", tmpl, &notification.EmailData{
+			AppName:        appOpts.AppName,
+			AppUrl:         appOpts.AppUrl,
+			Payload:        payload,
+			UnsubscribeURL: unsubscribeURL,
+		}),
+	})
+}
+
+// SendDigestEmail implements NotificationMailService.
+func (s *DbNotificationMailService) SendDigestEmail(ctx context.Context, args *workers.NotificationDigestEmailJobArgs) error {
+	member, user, preferences, err := s.recipient(ctx, args.TeamMemberID)
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		return nil
+	}
+	filter := &stores.NotificationFilter{
+		TeamMemberIds: []uuid.UUID{member.ID},
+	}
+	filter.Read = types.OptionalParam[bool]{IsSet: true, Value: false}
+	filter.CreatedAfter = types.OptionalParam[time.Time]{IsSet: true, Value: args.Since}
+	filter.SortBy = "created_at"
+	filter.SortOrder = "desc"
+	filter.PerPage = maxDigestItems
+	notifications, err := s.adapter.Notification().FindNotifications(ctx, filter)
+	if err != nil {
+		return err
+	}
+	emailed, err := s.adapter.Notification().FindNotificationDigestItems(ctx, member.ID, args.Since, maxDigestItems)
+	if err != nil {
+		return err
+	}
+	var items []notification.DigestItem
+	add := func(kind string, payload []byte, createdAt time.Time) error {
+		preference := ResolveNotificationPreference(preferences, user.ID, member.TeamID, kind)
+		if !preference.DailyDigest {
+			return nil
+		}
+		content, err := notification.DecodeContent(payload)
+		if err != nil {
+			return err
+		}
+		items = append(items, notification.DigestItem{
+			Type:      kind,
+			Title:     content.Title,
+			Body:      content.Body,
+			CreatedAt: createdAt,
+		})
+		return nil
+	}
+	for _, n := range notifications {
+		if err := add(n.Type, n.Payload, n.CreatedAt); err != nil {
+			return err
+		}
+	}
+	for _, item := range emailed {
+		if err := add(item.Type, item.Payload, item.CreatedAt); err != nil {
+			return err
+		}
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	slices.SortStableFunc(items, func(a, b notification.DigestItem) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	if len(items) > maxDigestItems {
+		items = items[:maxDigestItems]
+	}
+	team, err := s.adapter.TeamGroup().FindTeamByID(ctx, member.TeamID)
+	if err != nil {
+		return err
+	}
+	teamName := ""
+	if team != nil {
+		teamName = team.Name
+	}
+	unsubscribeURL, err := s.unsubscribeURL(ctx, shared.UnsubscribePayload{
+		UserId: user.ID,
+		TeamId: &member.TeamID,
+		Digest: true,
+	})
+	if err != nil {
+		return err
+	}
+	appOpts := s.options.AppConfig
+	return s.mail.Send(&mailer.Message{
+		From:    appOpts.SenderAddress,
+		To:      user.Email,
+		Subject: fmt.Sprintf("%s - Your daily digest for %s", appOpts.AppName, teamName),
+		Body: mailer.GenerateBody("body", notification.DigestEmailTemplate, &notification.DigestEmailData{
+			AppName:        appOpts.AppName,
+			AppUrl:         appOpts.AppUrl,
+			TeamName:       teamName,
+			Items:          items,
+			UnsubscribeURL: unsubscribeURL,
+		}),
+	})
+}
+
+func (s *DbNotificationMailService) unsubscribeURL(ctx context.Context, payload shared.UnsubscribePayload) (string, error) {
+	version, err := s.adapter.Notification().FindUnsubscribeVersion(ctx, payload.UserId)
+	if err != nil {
+		return "", err
+	}
+	opts := s.options.UnsubscribeToken
+	claims := shared.UnsubscribeClaims{
+		Type:               opts.Type,
+		Version:            version,
+		UnsubscribePayload: payload,
+	}
+	claims.ExpiresAt = opts.ExpiresAt()
+	token, err := s.token.CreateJwtToken(claims, opts.Secret)
+	if err != nil {
+		return "", err
+	}
+	appUrl, err := url.Parse(s.options.AppUrl)
+	if err != nil {
+		return "", err
+	}
+	unsubscribe := appUrl.JoinPath(UnsubscribePath)
+	unsubscribe.RawQuery = url.Values{"token": {token}}.Encode()
+	return unsubscribe.String(), nil
+}
+
+// Unsubscribe implements NotificationMailService. Emails of a type are turned
+// off by keeping only their in app delivery, the digest by turning it off for
+// every type of the team.
+func (s *DbNotificationMailService) Unsubscribe(ctx context.Context, token string) (*shared.UnsubscribePayload, error) {
+	var claims shared.UnsubscribeClaims
+	if err := s.token.ParseToken(token, s.options.UnsubscribeToken, &claims); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidUnsubscribeToken, err)
+	}
+	payload := claims.UnsubscribePayload
+	version, err := s.adapter.Notification().FindUnsubscribeVersion(ctx, payload.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Version != version {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidUnsubscribeToken, ErrUnsubscribeTokenRevoked)
+	}
+	teamID := uuid.Nil
+	if payload.TeamId != nil {
+		teamID = *payload.TeamId
+	}
+	preferences, err := s.adapter.Notification().FindNotificationPreferences(ctx, []uuid.UUID{payload.UserId})
+	if err != nil {
+		return nil, err
+	}
+	kinds := notification.Kinds()
+	if !payload.Digest {
+		kinds = []string{payload.NotificationType}
+	}
+	for _, kind := range kinds {
+		preference := ResolveNotificationPreference(preferences, payload.UserId, teamID, kind)
+		if payload.Digest {
+			if !preference.DailyDigest {
+				continue
+			}
+			preference.DailyDigest = false
+		} else {
+			if !preference.Delivery.Email() {
+				continue
+			}
+			preference.Delivery = withoutEmail(preference.Delivery)
+		}
+		_, err = s.adapter.Notification().UpsertNotificationPreference(ctx, &models.NotificationPreference{
+			UserID:      payload.UserId,
+			TeamID:      payload.TeamId,
+			Type:        kind,
+			Delivery:    preference.Delivery,
+			DailyDigest: preference.DailyDigest,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &payload, nil
+}
+
+// RevokeUnsubscribeLinks implements NotificationMailService.
+func (s *DbNotificationMailService) RevokeUnsubscribeLinks(ctx context.Context, userID uuid.UUID) error {
+	_, err := s.adapter.Notification().IncrementUnsubscribeVersion(ctx, userID)
+	return err
+}
+
+func withoutEmail(delivery models.NotificationDelivery) models.NotificationDelivery {
+	if delivery.InApp() {
+		return models.NotificationDeliveryInApp
+	}
+	return models.NotificationDeliveryNone
+}
+
+type NotificationEmailWorker struct {
+	service NotificationMailService
+}
+
+// Work implements workers.NotificationEmailJobWorker.
+func (w *NotificationEmailWorker) Work(ctx context.Context, job *jobs.Job[workers.NotificationEmailJobArgs]) error {
+	return w.service.SendNotificationEmail(ctx, &job.Args)
+}
+
+func NewNotificationEmailWorker(service NotificationMailService) *NotificationEmailWorker {
+	return &NotificationEmailWorker{
+		service: service,
+	}
+}
+
+var _ workers.NotificationEmailJobWorker = (*NotificationEmailWorker)(nil)
+
+type NotificationDigestWorker struct {
+	service NotificationMailService
+}
+
+// Work implements workers.NotificationDigestJobWorker.
+func (w *NotificationDigestWorker) Work(ctx context.Context, job *jobs.Job[workers.NotificationDigestJobArgs]) error {
+	return w.service.SendDigests(ctx, &job.Args)
+}
+
+func NewNotificationDigestWorker(service NotificationMailService) *NotificationDigestWorker {
+	return &NotificationDigestWorker{
+		service: service,
+	}
+}
+
+var _ workers.NotificationDigestJobWorker = (*NotificationDigestWorker)(nil)
+
+type NotificationDigestEmailWorker struct {
+	service NotificationMailService
+}
+
+// Work implements workers.NotificationDigestEmailJobWorker.
+func (w *NotificationDigestEmailWorker) Work(ctx context.Context, job *jobs.Job[workers.NotificationDigestEmailJobArgs]) error {
+	return w.service.SendDigestEmail(ctx, &job.Args)
+}
+
+func NewNotificationDigestEmailWorker(service NotificationMailService) *NotificationDigestEmailWorker {
+	return &NotificationDigestEmailWorker{
+		service: service,
+	}
+}
+
+var _ workers.NotificationDigestEmailJobWorker = (*NotificationDigestEmailWorker)(nil)
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkahng/playground/internal/conf"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/notification"
+	"github.com/tkahng/playground/internal/shared"
+	"github.com/tkahng/playground/internal/stores"
+	"github.com/tkahng/playground/internal/tools/mailer"
+	"github.com/tkahng/playground/internal/workers"
+)
+
+type recordingMailer struct {
+	messages []*mailer.Message
+}
+
+func (m *recordingMailer) Send(message *mailer.Message) error {
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+func TestNextDigestRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		now     time.Time
+		wantDay time.Time
+		wantRun time.Time
+	}{
+		{
+			name:    "before the hour runs today",
+			now:     time.Date(2025, 8, 1, 6, 30, 0, 0, time.UTC),
+			wantDay: time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC),
+			wantRun: time.Date(2025, 8, 1, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "at the hour runs tomorrow",
+			now:     time.Date(2025, 8, 1, 8, 0, 0, 0, time.UTC),
+			wantDay: time.Date(2025, 8, 2, 0, 0, 0, 0, time.UTC),
+			wantRun: time.Date(2025, 8, 2, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "other time zones use the utc day",
+			now:     time.Date(2025, 8, 1, 23, 0, 0, 0, time.FixedZone("UTC-9", -9*60*60)),
+			wantDay: time.Date(2025, 8, 3, 0, 0, 0, 0, time.UTC),
+			wantRun: time.Date(2025, 8, 3, 8, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			day, runAt := NextDigestRun(tt.now, 8)
+			assert.Equal(t, tt.wantDay, day)
+			assert.Equal(t, tt.wantRun, runAt)
+		})
+	}
+}
+
+func newTestNotificationMailService(preferences []*models.NotificationPreference) (*DbNotificationMailService, *stores.StorageAdapterDecorator, *recordingMailer) {
+	cfg := conf.ZeroEnvConfig()
+	cfg.AppName = "Playground"
+	cfg.AppUrl = "http://localhost:8080"
+	adapter := stores.NewAdapterDecorators()
+	adapter.NotificationFunc.FindPreferencesFunc = func(ctx context.Context, userIDs []uuid.UUID) ([]*models.NotificationPreference, error) {
+		return preferences, nil
+	}
+	versions := map[uuid.UUID]int64{}
+	adapter.NotificationFunc.FindUnsubscribeVersionFunc = func(ctx context.Context, userID uuid.UUID) (int64, error) {
+		return versions[userID], nil
+	}
+	adapter.NotificationFunc.IncrementUnsubscribeVersionFunc = func(ctx context.Context, userID uuid.UUID) (int64, error) {
+		versions[userID]++
+		return versions[userID], nil
+	}
+	m := &recordingMailer{}
+	service := NewNotificationMailService(&cfg, adapter, NewJobServiceDecorator(nil))
+	service.mail = m
+	return service, adapter, m
+}
+
+func TestNotificationMailService_SendNotificationEmail(t *testing.T) {
+	user := &models.User{ID: uuid.New(), Email: "member@example.com"}
+	member := &models.TeamMember{ID: uuid.New(), TeamID: uuid.New(), UserID: &user.ID}
+	payload, err := json.Marshal(notification.NewNotificationPayload(
+		"Task completed.",
+		"Write docs was completed today.",
+		notification.TaskCompletedNotificationData{TaskID: uuid.New(), CompletedAt: time.Now()},
+	))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		delivery models.NotificationDelivery
+		wantSent bool
+	}{
+		{name: "sent when email is preferred", delivery: models.NotificationDeliveryBoth, wantSent: true},
+		{name: "skipped after unsubscribing", delivery: models.NotificationDeliveryInApp, wantSent: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, adapter, m := newTestNotificationMailService([]*models.NotificationPreference{{
+				ID:       uuid.New(),
+				UserID:   user.ID,
+				Type:     "task_completed",
+				Delivery: tt.delivery,
+			}})
+			adapter.TeamMemberFunc.FindTeamMemberFunc = func(ctx context.Context, filter *stores.TeamMemberFilter) (*models.TeamMember, error) {
+				return member, nil
+			}
+			adapter.UserFunc.FindUserByIDFunc = func(ctx context.Context, userId uuid.UUID) (*models.User, error) {
+				return user, nil
+			}
+
+			err := service.SendNotificationEmail(context.Background(), &workers.NotificationEmailJobArgs{
+				TeamMemberID:     member.ID,
+				NotificationType: "task_completed",
+				Payload:          payload,
+			})
+			require.NoError(t, err)
+			if !tt.wantSent {
+				assert.Empty(t, m.messages)
+				return
+			}
+			require.Len(t, m.messages, 1)
+			assert.Equal(t, user.Email, m.messages[0].To)
+			assert.Equal(t, "Playground - Task completed.", m.messages[0].Subject)
+			assert.Contains(t, m.messages[0].Body, "Write docs was completed today.")
+			assert.Contains(t, m.messages[0].Body, UnsubscribePath+"?token=")
+		})
+	}
+}
+
+func TestNotificationMailService_Unsubscribe(t *testing.T) {
+	userID := uuid.New()
+	teamID := uuid.New()
+	tests := []struct {
+		name    string
+		payload shared.UnsubscribePayload
+		want    []*models.NotificationPreference
+	}{
+		{
+			name:    "type keeps in app delivery",
+			payload: shared.UnsubscribePayload{UserId: userID, TeamId: &teamID, NotificationType: "task_completed"},
+			want: []*models.NotificationPreference{
+				{UserID: userID, TeamID: &teamID, Type: "task_completed", Delivery: models.NotificationDeliveryInApp, DailyDigest: true},
+			},
+		},
+		{
+			name:    "digest is turned off for every type with it",
+			payload: shared.UnsubscribePayload{UserId: userID, TeamId: &teamID, Digest: true},
+			want: []*models.NotificationPreference{
+				{UserID: userID, TeamID: &teamID, Type: "task_completed", Delivery: models.NotificationDeliveryBoth, DailyDigest: false},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, adapter, _ := newTestNotificationMailService([]*models.NotificationPreference{{
+				ID:          uuid.New(),
+				UserID:      userID,
+				Type:        "task_completed",
+				Delivery:    models.NotificationDeliveryBoth,
+				DailyDigest: true,
+			}})
+			var got []*models.NotificationPreference
+			adapter.NotificationFunc.UpsertPreferenceFunc = func(ctx context.Context, preference *models.NotificationPreference) (*models.NotificationPreference, error) {
+				got = append(got, preference)
+				return preference, nil
+			}
+			link, err := service.unsubscribeURL(context.Background(), tt.payload)
+			require.NoError(t, err)
+			parsed, err := url.Parse(link)
+			require.NoError(t, err)
+			assert.True(t, strings.HasSuffix(parsed.Path, UnsubscribePath))
+
+			payload, err := service.Unsubscribe(context.Background(), parsed.Query().Get("token"))
+			require.NoError(t, err)
+			assert.Equal(t, tt.payload, *payload)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("invalid token", func(t *testing.T) {
+		service, _, _ := newTestNotificationMailService(nil)
+		_, err := service.Unsubscribe(context.Background(), "invalid.token")
+		assert.ErrorIs(t, err, ErrInvalidUnsubscribeToken)
+	})
+
+	t.Run("revoked links", func(t *testing.T) {
+		ctx := context.Background()
+		service, adapter, _ := newTestNotificationMailService(nil)
+		adapter.NotificationFunc.UpsertPreferenceFunc = func(ctx context.Context, preference *models.NotificationPreference) (*models.NotificationPreference, error) {
+			return preference, nil
+		}
+		payload := shared.UnsubscribePayload{UserId: userID, TeamId: &teamID, Digest: true}
+		revoked, err := service.unsubscribeURL(ctx, payload)
+		require.NoError(t, err)
+		require.NoError(t, service.RevokeUnsubscribeLinks(ctx, userID))
+		link, err := service.unsubscribeURL(ctx, payload)
+		require.NoError(t, err)
+
+		parsed, err := url.Parse(revoked)
+		require.NoError(t, err)
+		_, err = service.Unsubscribe(ctx, parsed.Query().Get("token"))
+		assert.ErrorIs(t, err, ErrInvalidUnsubscribeToken)
+		assert.ErrorIs(t, err, ErrUnsubscribeTokenRevoked)
+
+		parsed, err = url.Parse(link)
+		require.NoError(t, err)
+		_, err = service.Unsubscribe(ctx, parsed.Query().Get("token"))
+		assert.NoError(t, err, "links sent after revoking work")
+	})
+}
+
+func TestNotificationMailService_SendDigestEmail(t *testing.T) {
+	user := &models.User{ID: uuid.New(), Email: "member@example.com"}
+	member := &models.TeamMember{ID: uuid.New(), TeamID: uuid.New(), UserID: &user.ID}
+	newPayload := func(title string) []byte {
+		payload, err := json.Marshal(notification.NewNotificationPayload(
+			title,
+			title+" body",
+			notification.TaskCompletedNotificationData{TaskID: uuid.New(), CompletedAt: time.Now()},
+		))
+		require.NoError(t, err)
+		return payload
+	}
+	since := time.Now().Add(-24 * time.Hour)
+	service, adapter, m := newTestNotificationMailService([]*models.NotificationPreference{{
+		ID:          uuid.New(),
+		UserID:      user.ID,
+		Type:        "task_completed",
+		Delivery:    models.NotificationDeliveryEmail,
+		DailyDigest: true,
+	}})
+	adapter.TeamMemberFunc.FindTeamMemberFunc = func(ctx context.Context, filter *stores.TeamMemberFilter) (*models.TeamMember, error) {
+		return member, nil
+	}
+	adapter.UserFunc.FindUserByIDFunc = func(ctx context.Context, userId uuid.UUID) (*models.User, error) {
+		return user, nil
+	}
+	adapter.TeamGroupFunc.FindTeamByIDFunc = func(ctx context.Context, id uuid.UUID) (*models.Team, error) {
+		return &models.Team{ID: id, Name: "Acme"}, nil
+	}
+	adapter.NotificationFunc.FindNotificationsFunc = func(ctx context.Context, args *stores.NotificationFilter) ([]*models.Notification, error) {
+		// notifications delivered by email only have no in app notification
+		return nil, nil
+	}
+	adapter.NotificationFunc.FindDigestItemsFunc = func(ctx context.Context, teamMemberID uuid.UUID, after time.Time, limit int) ([]*models.NotificationDigestItem, error) {
+		assert.Equal(t, member.ID, teamMemberID)
+		assert.Equal(t, since, after)
+		return []*models.NotificationDigestItem{{
+			ID:           uuid.New(),
+			TeamMemberID: member.ID,
+			Type:         "task_completed",
+			Payload:      newPayload("Emailed task"),
+			CreatedAt:    time.Now(),
+		}}, nil
+	}
+
+	err := service.SendDigestEmail(context.Background(), &workers.NotificationDigestEmailJobArgs{
+		TeamMemberID: member.ID,
+		Since:        since,
+	})
+	require.NoError(t, err)
+	require.Len(t, m.messages, 1)
+	assert.Contains(t, m.messages[0].Body, "Emailed task")
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/tools/sse"
+	"github.com/tkahng/playground/internal/workers"
+)
+
+// DefaultNotificationDelivery is used for the types a user has no preference for.
+const DefaultNotificationDelivery = models.NotificationDeliveryInApp
+
+// ResolveNotificationPreference picks the preference of the user for kind in
+// team. The team's preference wins over the one for every team, without either
+// the notification is delivered in app only.
+func ResolveNotificationPreference(preferences []*models.NotificationPreference, userID uuid.UUID, teamID uuid.UUID, kind string) models.NotificationPreference {
+	resolved := models.NotificationPreference{
+		UserID:   userID,
+		Type:     kind,
+		Delivery: DefaultNotificationDelivery,
+	}
+	for _, preference := range preferences {
+		if preference.UserID != userID || preference.Type != kind {
+			continue
+		}
+		if preference.TeamID == nil {
+			if resolved.ID == uuid.Nil {
+				resolved = *preference
+			}
+			continue
+		}
+		if *preference.TeamID == teamID {
+			return *preference
+		}
+	}
+	return resolved
+}
+
+type notificationRecipient struct {
+	Member     *models.TeamMember
+	Preference models.NotificationPreference
+}
+
+// recipients resolves the preference of every member for kind and drops the
+// members that do not want to be notified at all.
+func (d *DbNotifier) recipients(ctx context.Context, members []*models.TeamMember, kind string) ([]notificationRecipient, error) {
+	var userIDs []uuid.UUID
+	for _, member := range members {
+		if member.UserID != nil {
+			userIDs = append(userIDs, *member.UserID)
+		}
+	}
+	preferences, err := d.adapter.Notification().FindNotificationPreferences(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	var recipients []notificationRecipient
+	for _, member := range members {
+		preference := models.NotificationPreference{
+			Type:     kind,
+			Delivery: DefaultNotificationDelivery,
+		}
+		if member.UserID != nil {
+			preference = ResolveNotificationPreference(preferences, *member.UserID, member.TeamID, kind)
+		}
+		if preference.Delivery == models.NotificationDeliveryNone {
+			continue
+		}
+		recipients = append(recipients, notificationRecipient{
+			Member:     member,
+			Preference: preference,
+		})
+	}
+	return recipients, nil
+}
+
+// fanOut delivers the notification to every recipient the way they prefer.
+func (d *DbNotifier) fanOut(ctx context.Context, recipients []notificationRecipient, kind string, payload any) error {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	if err := d.notifyInApp(ctx, recipients, kind, payloadBytes, payload); err != nil {
+		return err
+	}
+	return d.notifyEmail(ctx, recipients, kind, payloadBytes)
+}
+
+// notifyInApp stores a notification for every recipient that wants it in app
+// and pushes it to their sse channel.
+func (d *DbNotifier) notifyInApp(ctx context.Context, recipients []notificationRecipient, kind string, payloadBytes []byte, payload any) error {
+	var notifications []models.Notification
+	for _, recipient := range recipients {
+		if !recipient.Preference.Delivery.InApp() {
+			continue
+		}
+		notifications = append(notifications, models.Notification{
+			TeamMemberID: &recipient.Member.ID,
+			Channel:      sse.TeamMemberChannel(recipient.Member.ID.String()),
+			Type:         kind,
+			Payload:      payloadBytes,
+			Metadata:     map[string]any{},
+		})
+	}
+	created, err := d.adapter.Notification().CreateManyNotifications(ctx, notifications)
+	if err != nil {
+		return err
+	}
+	for _, notification := range created {
+		if notification.TeamMemberID == nil {
+			continue
+		}
+		err = d.sseManager.SendMessage(
+			sse.TeamMemberChannel(notification.TeamMemberID.String()),
+			sse.Message{Seq: notification.Seq, Data: payload},
+		)
+		if err != nil {
+			slog.ErrorContext(
+				ctx,
+				"error sending notification",
+				slog.Any("error", err),
+			)
+		}
+		publishUnreadCount(ctx, d.adapter, d.sseManager, *notification.TeamMemberID)
+	}
+	return nil
+}
+
+// notifyEmail enqueues an email for every recipient that wants one, the job
+// retries sending on its own. Recipients without the in app notification get
+// a digest item instead, for their daily digest.
+func (d *DbNotifier) notifyEmail(ctx context.Context, recipients []notificationRecipient, kind string, payloadBytes []byte) error {
+	var items []models.NotificationDigestItem
+	for _, recipient := range recipients {
+		if recipient.Preference.Delivery == models.NotificationDeliveryEmail && recipient.Preference.DailyDigest {
+			items = append(items, models.NotificationDigestItem{
+				TeamMemberID: recipient.Member.ID,
+				Type:         kind,
+				Payload:      payloadBytes,
+			})
+		}
+	}
+	if len(items) > 0 {
+		if err := d.adapter.Notification().CreateNotificationDigestItems(ctx, items); err != nil {
+			return err
+		}
+	}
+	for _, recipient := range recipients {
+		if !recipient.Preference.Delivery.Email() {
+			continue
+		}
+		err := d.jobService.EnqueueNotificationEmailJob(ctx, &workers.NotificationEmailJobArgs{
+			TeamMemberID:     recipient.Member.ID,
+			NotificationType: kind,
+			Payload:          payloadBytes,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/tkahng/playground/internal/models"
+)
+
+func TestResolveNotificationPreference(t *testing.T) {
+	userID := uuid.New()
+	teamID := uuid.New()
+	otherTeamID := uuid.New()
+	everyTeam := &models.NotificationPreference{
+		ID:       uuid.New(),
+		UserID:   userID,
+		Type:     "task_completed",
+		Delivery: models.NotificationDeliveryEmail,
+	}
+	team := &models.NotificationPreference{
+		ID:          uuid.New(),
+		UserID:      userID,
+		TeamID:      &teamID,
+		Type:        "task_completed",
+		Delivery:    models.NotificationDeliveryNone,
+		DailyDigest: true,
+	}
+	preferences := []*models.NotificationPreference{everyTeam, team}
+
+	tests := []struct {
+		name   string
+		userID uuid.UUID
+		teamID uuid.UUID
+		kind   string
+		want   models.NotificationDelivery
+		digest bool
+	}{
+		{
+			name:   "team preference wins",
+			userID: userID,
+			teamID: teamID,
+			kind:   "task_completed",
+			want:   models.NotificationDeliveryNone,
+			digest: true,
+		},
+		{
+			name:   "falls back to every team",
+			userID: userID,
+			teamID: otherTeamID,
+			kind:   "task_completed",
+			want:   models.NotificationDeliveryEmail,
+		},
+		{
+			name:   "defaults to in app for other types",
+			userID: userID,
+			teamID: teamID,
+			kind:   "task_due_today",
+			want:   DefaultNotificationDelivery,
+		},
+		{
+			name:   "ignores other users",
+			userID: uuid.New(),
+			teamID: teamID,
+			kind:   "task_completed",
+			want:   DefaultNotificationDelivery,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ResolveNotificationPreference(preferences, tt.userID, tt.teamID, tt.kind)
+			assert.Equal(t, tt.want, got.Delivery)
+			assert.Equal(t, tt.digest, got.DailyDigest)
+			assert.Equal(t, tt.kind, got.Type)
+		})
+	}
+}
+
+func TestNotificationDelivery(t *testing.T) {
+	assert.True(t, models.NotificationDeliveryInApp.InApp())
+	assert.False(t, models.NotificationDeliveryInApp.Email())
+	assert.True(t, models.NotificationDeliveryBoth.InApp())
+	assert.True(t, models.NotificationDeliveryBoth.Email())
+	assert.False(t, models.NotificationDeliveryEmail.InApp())
+	assert.False(t, models.NotificationDeliveryNone.InApp())
+	assert.False(t, models.NotificationDeliveryNone.Email())
+}
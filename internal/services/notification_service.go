@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/conf"
+	"github.com/tkahng/playground/internal/jobs"
+	"github.com/tkahng/playground/internal/stores"
+	"github.com/tkahng/playground/internal/tools/sse"
+	"github.com/tkahng/playground/internal/tools/types"
+	"github.com/tkahng/playground/internal/workers"
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// UnreadCountSseEvent is sent to the channel of a team member whenever the
+// number of their unread notifications changes.
+type UnreadCountSseEvent struct {
+	TeamMemberID uuid.UUID `json:"team_member_id"`
+	Count        int64     `json:"count"`
+}
+
+type NotificationService interface {
+	// MarkRead marks one notification of the team member as read.
+	MarkRead(ctx context.Context, teamMemberID uuid.UUID, notificationID uuid.UUID) error
+	// MarkAllRead marks the unread notifications of the team member matching
+	// filter as read and returns how many were updated.
+	MarkAllRead(ctx context.Context, teamMemberID uuid.UUID, filter *stores.NotificationFilter) (int64, error)
+	DeleteNotification(ctx context.Context, teamMemberID uuid.UUID, notificationID uuid.UUID) error
+	UnreadCount(ctx context.Context, teamMemberID uuid.UUID) (int64, error)
+	// ScheduleRetention enqueues the next retention run after now.
+	ScheduleRetention(ctx context.Context, now time.Time) error
+	// PruneReadNotifications deletes the read notifications older than the
+	// retention period and schedules the run of the next day.
+	PruneReadNotifications(ctx context.Context, args *workers.NotificationRetentionJobArgs) error
+}
+
+var _ NotificationService = (*DbNotificationService)(nil)
+
+type DbNotificationService struct {
+	options    *conf.EnvConfig
+	adapter    stores.StorageAdapterInterface
+	sseManager sse.Manager
+	jobService JobService
+}
+
+func NewNotificationService(
+	opts *conf.EnvConfig,
+	adapter stores.StorageAdapterInterface,
+	sseManager sse.Manager,
+	jobService JobService,
+) *DbNotificationService {
+	return &DbNotificationService{
+		options:    opts,
+		adapter:    adapter,
+		sseManager: sseManager,
+		jobService: jobService,
+	}
+}
+
+// unreadFilter matches the unread notifications of the team member.
+func unreadFilter(teamMemberID uuid.UUID) *stores.NotificationFilter {
+	return &stores.NotificationFilter{
+		TeamMemberIds: []uuid.UUID{teamMemberID},
+		Read:          types.OptionalParam[bool]{IsSet: true, Value: false},
+	}
+}
+
+// publishUnreadCount sends the current unread count of the team member to
+// their channel. Failures are only logged, the count is refreshed by the next
+// change or by asking for it.
+//
+// The count has no seq, connections replayed by notification seq receive it
+// without an id.
+func publishUnreadCount(ctx context.Context, adapter stores.StorageAdapterInterface, sseManager sse.Manager, teamMemberID uuid.UUID) {
+	count, err := adapter.Notification().CountNotification(ctx, unreadFilter(teamMemberID))
+	if err != nil {
+		slog.ErrorContext(ctx, "error counting unread notifications", slog.Any("error", err))
+		return
+	}
+	err = sseManager.SendMessage(
+		sse.TeamMemberChannel(teamMemberID.String()),
+		sse.Message{Data: &UnreadCountSseEvent{TeamMemberID: teamMemberID, Count: count}},
+	)
+	if err != nil {
+		slog.ErrorContext(ctx, "error sending unread count", slog.Any("error", err))
+	}
+}
+
+// MarkRead implements NotificationService.
+func (s *DbNotificationService) MarkRead(ctx context.Context, teamMemberID uuid.UUID, notificationID uuid.UUID) error {
+	notification, err := s.adapter.Notification().FindNotification(ctx, &stores.NotificationFilter{
+		Ids:           []uuid.UUID{notificationID},
+		TeamMemberIds: []uuid.UUID{teamMemberID},
+	})
+	if err != nil {
+		return err
+	}
+	if notification == nil {
+		return ErrNotificationNotFound
+	}
+	if notification.ReadAt != nil {
+		return nil
+	}
+	now := time.Now()
+	notification.ReadAt = &now
+	if err := s.adapter.Notification().UpdateNotification(ctx, notification); err != nil {
+		return err
+	}
+	publishUnreadCount(ctx, s.adapter, s.sseManager, teamMemberID)
+	return nil
+}
+
+// MarkAllRead implements NotificationService.
+func (s *DbNotificationService) MarkAllRead(ctx context.Context, teamMemberID uuid.UUID, filter *stores.NotificationFilter) (int64, error) {
+	args := stores.NotificationFilter{}
+	if filter != nil {
+		args = *filter
+	}
+	args.TeamMemberIds = []uuid.UUID{teamMemberID}
+	count, err := s.adapter.Notification().MarkNotificationsRead(ctx, &args, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	if count > 0 {
+		publishUnreadCount(ctx, s.adapter, s.sseManager, teamMemberID)
+	}
+	return count, nil
+}
+
+// DeleteNotification implements NotificationService.
+func (s *DbNotificationService) DeleteNotification(ctx context.Context, teamMemberID uuid.UUID, notificationID uuid.UUID) error {
+	count, err := s.adapter.Notification().DeleteNotifications(ctx, &stores.NotificationFilter{
+		Ids:           []uuid.UUID{notificationID},
+		TeamMemberIds: []uuid.UUID{teamMemberID},
+	})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrNotificationNotFound
+	}
+	publishUnreadCount(ctx, s.adapter, s.sseManager, teamMemberID)
+	return nil
+}
+
+// UnreadCount implements NotificationService.
+func (s *DbNotificationService) UnreadCount(ctx context.Context, teamMemberID uuid.UUID) (int64, error) {
+	return s.adapter.Notification().CountNotification(ctx, unreadFilter(teamMemberID))
+}
+
+// NextRetentionRun returns the day of the first retention run after now, runs
+// start at midnight UTC.
+func NextRetentionRun(now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+}
+
+// ScheduleRetention implements NotificationService.
+func (s *DbNotificationService) ScheduleRetention(ctx context.Context, now time.Time) error {
+	if s.options.NotificationRetentionDays <= 0 {
+		return nil
+	}
+	day := NextRetentionRun(now)
+	return s.jobService.EnqueueNotificationRetentionJob(ctx, &workers.NotificationRetentionJobArgs{Day: day}, day)
+}
+
+// PruneReadNotifications implements NotificationService.
+func (s *DbNotificationService) PruneReadNotifications(ctx context.Context, args *workers.NotificationRetentionJobArgs) error {
+	days := s.options.NotificationRetentionDays
+	if days <= 0 {
+		return nil
+	}
+	filter := &stores.NotificationFilter{
+		Read:          types.OptionalParam[bool]{IsSet: true, Value: true},
+		CreatedBefore: types.OptionalParam[time.Time]{IsSet: true, Value: args.Day.AddDate(0, 0, -days)},
+	}
+	count, err := s.adapter.Notification().DeleteNotifications(ctx, filter)
+	if err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "pruned read notifications", slog.Int64("count", count), slog.Int("retention_days", days))
+	next := args.Day.AddDate(0, 0, 1)
+	return s.jobService.EnqueueNotificationRetentionJob(ctx, &workers.NotificationRetentionJobArgs{Day: next}, next)
+}
+
+type NotificationRetentionWorker struct {
+	service NotificationService
+}
+
+// Work implements workers.NotificationRetentionJobWorker.
+func (w *NotificationRetentionWorker) Work(ctx context.Context, job *jobs.Job[workers.NotificationRetentionJobArgs]) error {
+	return w.service.PruneReadNotifications(ctx, &job.Args)
+}
+
+func NewNotificationRetentionWorker(service NotificationService) *NotificationRetentionWorker {
+	return &NotificationRetentionWorker{
+		service: service,
+	}
+}
+
+var _ workers.NotificationRetentionJobWorker = (*NotificationRetentionWorker)(nil)
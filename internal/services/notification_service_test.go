@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkahng/playground/internal/conf"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/stores"
+	"github.com/tkahng/playground/internal/tools/sse"
+	"github.com/tkahng/playground/internal/tools/types"
+	"github.com/tkahng/playground/internal/workers"
+)
+
+type recordingSseManager struct {
+	sse.Manager
+	channels []string
+	messages []sse.Message
+}
+
+func (m *recordingSseManager) SendMessage(channel string, msg sse.Message) error {
+	m.channels = append(m.channels, channel)
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+func newTestNotificationService(retentionDays int) (*DbNotificationService, *stores.StorageAdapterDecorator, *JobServiceDecorator, *recordingSseManager) {
+	cfg := conf.ZeroEnvConfig()
+	cfg.NotificationRetentionDays = retentionDays
+	adapter := stores.NewAdapterDecorators()
+	jobService := NewJobServiceDecorator(nil)
+	manager := &recordingSseManager{}
+	return NewNotificationService(&cfg, adapter, manager, jobService), adapter, jobService, manager
+}
+
+func TestNotificationService_MarkAllRead(t *testing.T) {
+	service, adapter, _, manager := newTestNotificationService(30)
+	memberID := uuid.New()
+	after := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	var marked *stores.NotificationFilter
+	adapter.NotificationFunc.MarkReadFunc = func(ctx context.Context, args *stores.NotificationFilter, readAt time.Time) (int64, error) {
+		marked = args
+		return 3, nil
+	}
+	var counted *stores.NotificationFilter
+	adapter.NotificationFunc.CountFunc = func(ctx context.Context, filter *stores.NotificationFilter) (int64, error) {
+		counted = filter
+		return 2, nil
+	}
+
+	filter := &stores.NotificationFilter{
+		Types:        []string{"task_completed"},
+		CreatedAfter: types.OptionalParam[time.Time]{IsSet: true, Value: after},
+	}
+	updated, err := service.MarkAllRead(context.Background(), memberID, filter)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), updated)
+
+	require.NotNil(t, marked)
+	assert.Equal(t, []uuid.UUID{memberID}, marked.TeamMemberIds)
+	assert.Equal(t, []string{"task_completed"}, marked.Types)
+	assert.Equal(t, after, marked.CreatedAfter.Value)
+
+	require.NotNil(t, counted)
+	assert.Equal(t, []uuid.UUID{memberID}, counted.TeamMemberIds)
+	assert.True(t, counted.Read.IsSet)
+	assert.False(t, counted.Read.Value)
+
+	require.Len(t, manager.messages, 1)
+	assert.Equal(t, sse.TeamMemberChannel(memberID.String()), manager.channels[0])
+	assert.Zero(t, manager.messages[0].Seq, "counts are not replayed by seq")
+	assert.Equal(t, &UnreadCountSseEvent{TeamMemberID: memberID, Count: 2}, manager.messages[0].Data)
+}
+
+func TestNotificationService_MarkRead(t *testing.T) {
+	t.Run("not found", func(t *testing.T) {
+		service, adapter, _, manager := newTestNotificationService(30)
+		adapter.NotificationFunc.FindNotificationFunc = func(ctx context.Context, args *stores.NotificationFilter) (*models.Notification, error) {
+			return nil, nil
+		}
+		err := service.MarkRead(context.Background(), uuid.New(), uuid.New())
+		assert.ErrorIs(t, err, ErrNotificationNotFound)
+		assert.Empty(t, manager.messages)
+	})
+
+	t.Run("already read keeps read_at", func(t *testing.T) {
+		service, adapter, _, manager := newTestNotificationService(30)
+		readAt := time.Now().Add(-time.Hour)
+		adapter.NotificationFunc.FindNotificationFunc = func(ctx context.Context, args *stores.NotificationFilter) (*models.Notification, error) {
+			return &models.Notification{ID: args.Ids[0], ReadAt: &readAt}, nil
+		}
+		adapter.NotificationFunc.UpdateFunc = func(ctx context.Context, notification *models.Notification) error {
+			t.Fatal("read notification updated")
+			return nil
+		}
+		err := service.MarkRead(context.Background(), uuid.New(), uuid.New())
+		assert.NoError(t, err)
+		assert.Empty(t, manager.messages)
+	})
+}
+
+func TestNotificationService_PruneReadNotifications(t *testing.T) {
+	day := time.Date(2025, 8, 10, 0, 0, 0, 0, time.UTC)
+
+	t.Run("deletes read notifications past retention", func(t *testing.T) {
+		service, adapter, jobService, _ := newTestNotificationService(7)
+		var deleted *stores.NotificationFilter
+		adapter.NotificationFunc.DeleteFunc = func(ctx context.Context, args *stores.NotificationFilter) (int64, error) {
+			deleted = args
+			return 5, nil
+		}
+		var next *workers.NotificationRetentionJobArgs
+		var runAfter time.Time
+		jobService.EnqueueNotificationRetentionJobFunc = func(ctx context.Context, args *workers.NotificationRetentionJobArgs, at time.Time) error {
+			next = args
+			runAfter = at
+			return nil
+		}
+
+		err := service.PruneReadNotifications(context.Background(), &workers.NotificationRetentionJobArgs{Day: day})
+		require.NoError(t, err)
+		require.NotNil(t, deleted)
+		assert.True(t, deleted.Read.IsSet)
+		assert.True(t, deleted.Read.Value)
+		assert.Equal(t, time.Date(2025, 8, 3, 0, 0, 0, 0, time.UTC), deleted.CreatedBefore.Value)
+		assert.Empty(t, deleted.TeamMemberIds)
+		require.NotNil(t, next)
+		assert.Equal(t, day.AddDate(0, 0, 1), next.Day)
+		assert.Equal(t, day.AddDate(0, 0, 1), runAfter)
+	})
+
+	t.Run("disabled keeps everything", func(t *testing.T) {
+		service, adapter, jobService, _ := newTestNotificationService(0)
+		adapter.NotificationFunc.DeleteFunc = func(ctx context.Context, args *stores.NotificationFilter) (int64, error) {
+			t.Fatal("notifications deleted with retention disabled")
+			return 0, nil
+		}
+		jobService.EnqueueNotificationRetentionJobFunc = func(ctx context.Context, args *workers.NotificationRetentionJobArgs, at time.Time) error {
+			t.Fatal("retention scheduled while disabled")
+			return nil
+		}
+		assert.NoError(t, service.PruneReadNotifications(context.Background(), &workers.NotificationRetentionJobArgs{Day: day}))
+		assert.NoError(t, service.ScheduleRetention(context.Background(), day))
+	})
+}
+
+func TestNextRetentionRun(t *testing.T) {
+	now := time.Date(2025, 8, 1, 23, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60))
+	assert.Equal(t, time.Date(2025, 8, 2, 0, 0, 0, 0, time.UTC), NextRetentionRun(now))
+}
@@ -2,10 +2,8 @@ package services
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"log/slog"
 	"time"
 
 	"github.com/google/uuid"
@@ -27,11 +25,12 @@ type Notifier interface {
 
 var _ Notifier = (*DbNotifier)(nil)
 
-func NewDbNotificationPublisher(sseManager sse.Manager, teamService TeamService, adapter stores.StorageAdapterInterface) *DbNotifier {
+func NewDbNotificationPublisher(sseManager sse.Manager, teamService TeamService, adapter stores.StorageAdapterInterface, jobService JobService) *DbNotifier {
 	return &DbNotifier{
 		sseManager:  sseManager,
 		teamService: teamService,
 		adapter:     adapter,
+		jobService:  jobService,
 	}
 }
 
@@ -39,6 +38,7 @@ type DbNotifier struct {
 	sseManager  sse.Manager
 	teamService TeamService
 	adapter     stores.StorageAdapterInterface
+	jobService  JobService
 }
 
 type AssignedToTaskWorker struct {
@@ -124,28 +124,11 @@ func (d *DbNotifier) NotifyAssignedToTask(ctx context.Context, taskID uuid.UUID,
 		assignerUser.Email+" has assigned you to a task.",
 		payload,
 	)
-	notificationPayloadBytes, err := json.Marshal(notifcationPaylod)
+	recipients, err := d.recipients(ctx, []*models.TeamMember{assigneeMember}, payload.Kind())
 	if err != nil {
 		return err
 	}
-	_, err = d.adapter.Notification().CreateNotification(ctx, &models.Notification{
-		TeamMemberID: &assigneeMember.ID,
-		Channel:      "team_member_id:" + assigneeMember.ID.String(),
-		Type:         payload.Kind(),
-		Payload:      notificationPayloadBytes,
-		Metadata:     map[string]any{},
-	})
-	if err != nil {
-		return err
-	}
-	err = d.sseManager.Send(
-		"team_member_id:"+assigneeMember.ID.String(),
-		notifcationPaylod,
-	)
-	if err != nil {
-		return err
-	}
-	return nil
+	return d.fanOut(ctx, recipients, payload.Kind(), notifcationPaylod)
 }
 
 type NewTeamMemberWorker struct {
@@ -198,44 +181,18 @@ func (d *DbNotifier) NotifyMembersOfNewMember(ctx context.Context, teamMemberID
 		payload.Email+" has joined your team.",
 		payload,
 	)
-	notificationPayloadBytes, err := json.Marshal(notifcationPaylod)
-	if err != nil {
-		return err
-	}
-	var notifications []models.Notification
+	var notifyMembers []*models.TeamMember
 	for _, member := range members {
 		if member.ID == teamMemberID {
 			continue
 		}
-		notification := models.Notification{
-			TeamMemberID: &member.ID,
-			Channel:      "team_member_id:" + member.ID.String(),
-			Type:         payload.Kind(),
-			Payload:      notificationPayloadBytes,
-			Metadata:     map[string]any{},
-		}
-		notifications = append(notifications, notification)
+		notifyMembers = append(notifyMembers, member)
 	}
-
-	_, err = d.adapter.Notification().InsertManyNotifications(ctx, notifications)
+	recipients, err := d.recipients(ctx, notifyMembers, payload.Kind())
 	if err != nil {
 		return err
 	}
-	for _, notification := range notifications {
-		if notification.TeamMemberID == nil {
-			continue
-		}
-		teamMemberID := *notification.TeamMemberID
-		err = d.sseManager.Send("team_member_id:"+teamMemberID.String(), notifcationPaylod)
-		if err != nil {
-			slog.ErrorContext(
-				ctx,
-				"error sending notification",
-				slog.Any("error", err),
-			)
-		}
-	}
-	return nil
+	return d.fanOut(ctx, recipients, payload.Kind(), notifcationPaylod)
 }
 
 // func isWithinLast24Hours(t *time.Time) bool {
@@ -306,10 +263,6 @@ func (d *DbNotifier) NotifyTaskDueToday(ctx context.Context, taskID uuid.UUID) e
 			task.Name+" is due today.",
 			payload,
 		)
-		notificationPayloadBytes, err := json.Marshal(notifcationPaylod)
-		if err != nil {
-			return err
-		}
 		var notifyMemberIds []uuid.UUID
 		if task.AssigneeID != nil {
 			notifyMemberIds = append(notifyMemberIds, *task.AssigneeID)
@@ -330,41 +283,15 @@ func (d *DbNotifier) NotifyTaskDueToday(ctx context.Context, taskID uuid.UUID) e
 		if err != nil {
 			return err
 		}
-		var notifications []models.Notification
-		for _, member := range notifyMembers {
-			notification := models.Notification{
-				TeamMemberID: &member.ID,
-				Channel:      "team_member_id:" + member.ID.String(),
-				Type:         payload.Kind(),
-				Payload:      notificationPayloadBytes,
-				Metadata:     map[string]any{},
-			}
-			notifications = append(notifications, notification)
-		}
-
-		_, err = d.adapter.Notification().InsertManyNotifications(ctx, notifications)
-		if err != nil {
-			return err
-		}
-		for _, notification := range notifications {
-			teamMemberID := *notification.TeamMemberID
-			err = d.sseManager.Send("team_member_id:"+teamMemberID.String(), notifcationPaylod)
-			if err != nil {
-				slog.ErrorContext(
-					ctx,
-					"error sending notification",
-					slog.Any("error", err),
-				)
-			}
-		}
+		recipients, err := d.recipients(ctx, notifyMembers, payload.Kind())
 		if err != nil {
 			return err
 		}
+		return d.fanOut(ctx, recipients, payload.Kind(), notifcationPaylod)
 	} else {
 		fmt.Println("task is not due today")
 		return nil
 	}
-	return nil
 }
 func (d *DbNotifier) NotifyTaskCompleted(ctx context.Context, taskID uuid.UUID, completedByMemberID uuid.UUID, completedAt time.Time) error {
 	// 1. find task
@@ -392,10 +319,6 @@ func (d *DbNotifier) NotifyTaskCompleted(ctx context.Context, taskID uuid.UUID,
 		task.Name+" was completed today.",
 		payload,
 	)
-	notificationPayloadBytes, err := json.Marshal(notifcationPaylod)
-	if err != nil {
-		return err
-	}
 	var notifyMemberIds []uuid.UUID
 	if task.AssigneeID != nil {
 		notifyMemberIds = append(notifyMemberIds, *task.AssigneeID)
@@ -416,35 +339,11 @@ func (d *DbNotifier) NotifyTaskCompleted(ctx context.Context, taskID uuid.UUID,
 	if err != nil {
 		return err
 	}
-	var notifications []models.Notification
-	for _, member := range notifyMembers {
-		notification := models.Notification{
-			TeamMemberID: &member.ID,
-			Channel:      "team_member_id:" + member.ID.String(),
-			Type:         payload.Kind(),
-			Payload:      notificationPayloadBytes,
-			Metadata:     map[string]any{},
-		}
-		notifications = append(notifications, notification)
-	}
-
-	_, err = d.adapter.Notification().InsertManyNotifications(ctx, notifications)
+	recipients, err := d.recipients(ctx, notifyMembers, payload.Kind())
 	if err != nil {
 		return err
 	}
-	for _, notification := range notifications {
-		teamMemberID := *notification.TeamMemberID
-		err = d.sseManager.Send("team_member_id:"+teamMemberID.String(), notifcationPaylod)
-		if err != nil {
-			slog.ErrorContext(
-				ctx,
-				"error sending notification",
-				slog.Any("error", err),
-			)
-		}
-	}
-
-	return nil
+	return d.fanOut(ctx, recipients, payload.Kind(), notifcationPaylod)
 }
 
 type TaskCompletedWorker struct {
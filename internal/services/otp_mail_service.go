@@ -18,6 +18,10 @@ import (
 type OtpMailService interface {
 	SendOtpEmail(ctx context.Context, emailType mailer.EmailType, userId uuid.UUID) error
 	SendTeamInvitationEmail(ctx context.Context, params *workers.TeamInvitationJobArgs) error
+	SendSecurityAlertEmail(ctx context.Context, params *workers.SecurityAlertJobArgs) error
+	// SendMagicLinkEmail sends a sign in link and code to the email, users
+	// that do not exist yet sign up with it.
+	SendMagicLinkEmail(ctx context.Context, email string) error
 }
 
 var _ OtpMailService = (*DbOtpMailService)(nil)
@@ -34,21 +38,24 @@ func NewOtpMailService(
 	opts *conf.EnvConfig,
 	adapter stores.StorageAdapterInterface,
 ) OtpMailService {
-	var m mailer.Mailer
-	if opts.ResendApiKey != "" {
-		m = mailer.NewResendMailer(opts.ResendConfig)
-	} else {
-		m = &mailer.LogMailer{}
-	}
 	return &DbOtpMailService{
 		options:  opts,
 		adapter:  adapter,
-		mail:     m,
+		mail:     newMailer(opts),
 		token:    NewJwtService(),
 		password: NewPasswordService(),
 	}
 }
 
+// newMailer sends through Resend when it is configured and logs the emails
+// otherwise.
+func newMailer(opts *conf.EnvConfig) mailer.Mailer {
+	if opts.ResendApiKey != "" {
+		return mailer.NewResendMailer(opts.ResendConfig)
+	}
+	return &mailer.LogMailer{}
+}
+
 func (app *DbOtpMailService) SendOtpEmail(ctx context.Context, emailType mailer.EmailType, userId uuid.UUID) error {
 	adapter := app.adapter
 	user, err := adapter.User().FindUserByID(ctx, userId)
@@ -90,6 +97,43 @@ func (app *DbOtpMailService) SendOtpEmail(ctx context.Context, emailType mailer.
 	claims.Otp = security.GenerateOtp(6)
 	claims.RedirectTo = appOpts.AppUrl
 
+	return app.sendOtpClaims(ctx, emailType, tokenOpts, claims, nil)
+}
+
+// SendMagicLinkEmail implements OtpMailService.
+func (app *DbOtpMailService) SendMagicLinkEmail(ctx context.Context, email string) error {
+	if email == "" {
+		return fmt.Errorf("email is empty")
+	}
+	user, err := app.adapter.User().FindUser(ctx, &stores.UserFilter{Emails: []string{email}})
+	if err != nil {
+		return err
+	}
+	tokenOpts := app.options.MagicLinkToken
+	claims := shared.OtpClaims{}
+	claims.ExpiresAt = tokenOpts.ExpiresAt()
+	claims.Type = tokenOpts.Type
+	claims.Email = email
+	if user != nil {
+		claims.UserId = user.ID
+		claims.Email = user.Email
+	}
+	claims.Token = security.GenerateTokenKey()
+	claims.Otp = security.GenerateOtp(6)
+	claims.RedirectTo = app.options.AppUrl
+
+	// only the latest link counts, every code left outstanding would be
+	// another guess at the six digits
+	err = app.adapter.Token().DeleteIdentifierTokens(ctx, tokenOpts.Type, claims.Email)
+	if err != nil {
+		return fmt.Errorf("error deleting magic link tokens: %w", err)
+	}
+	// the code is stored to be redeemed without the link
+	return app.sendOtpClaims(ctx, mailer.EmailTypeMagicLink, tokenOpts, claims, &claims.Otp)
+}
+
+// sendOtpClaims stores the token of the claims and emails its link and code.
+func (app *DbOtpMailService) sendOtpClaims(ctx context.Context, emailType mailer.EmailType, tokenOpts conf.TokenOption, claims shared.OtpClaims, otp *string) error {
 	tokenHash, err := app.token.CreateJwtToken(claims, tokenOpts.Secret)
 	if err != nil {
 		return fmt.Errorf("error at creating verification token: %w", err)
@@ -99,9 +143,12 @@ func (app *DbOtpMailService) SendOtpEmail(ctx context.Context, emailType mailer.
 		Token:      claims.Token,
 		Type:       models.TokenTypes(claims.Type),
 		Identifier: claims.Email,
-		UserID:     &claims.UserId,
+		Otp:        otp,
 	}
-	err = adapter.Token().SaveToken(ctx, dto)
+	if claims.UserId != uuid.Nil {
+		dto.UserID = &claims.UserId
+	}
+	err = app.adapter.Token().SaveToken(ctx, dto)
 	// err = app.authStore.SaveToken(ctx, dto)
 	if err != nil {
 		return fmt.Errorf("error at creating verification token: %w", err)
@@ -206,10 +253,64 @@ func (i *DbOtpMailService) SendTeamInvitationEmail(ctx context.Context, params *
 	return i.mail.Send(param.Message)
 }
 
+// SendSecurityAlertEmail implements OtpMailService.
+func (app *DbOtpMailService) SendSecurityAlertEmail(ctx context.Context, params *workers.SecurityAlertJobArgs) error {
+	if params == nil {
+		return fmt.Errorf("params is nil")
+	}
+	user, err := app.adapter.User().FindUserByID(ctx, params.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return fmt.Errorf("user is nil")
+	}
+	var template, subject string
+	switch params.Alert {
+	case mailer.SecurityAlertRefreshTokenReuse:
+		template = mailer.DefaultRefreshTokenReuseMail
+		subject = "A device was signed out"
+	case mailer.SecurityAlertAccountLocked:
+		template = mailer.DefaultAccountLockedMail
+		subject = "Suspicious sign in attempts"
+	default:
+		return fmt.Errorf("invalid security alert %q", params.Alert)
+	}
+	appOpts := app.options.AppConfig
+	body := mailer.GenerateBody("body", template, struct {
+		*workers.SecurityAlertJobArgs
+		SiteURL string
+	}{params, appOpts.AppUrl})
+	return app.mail.Send(&mailer.Message{
+		From:    appOpts.SenderAddress,
+		To:      user.Email,
+		Subject: fmt.Sprintf("%s - %s", appOpts.AppName, subject),
+		Body:    body,
+	})
+}
+
 type OtpMailDecorator struct {
-	Delegate                DbOtpMailService
-	SendOtpEmailFunc        func(ctx context.Context, emailType mailer.EmailType, userId uuid.UUID) error
-	SendInvitationEmailFunc func(ctx context.Context, params *workers.TeamInvitationJobArgs) error
+	Delegate                   DbOtpMailService
+	SendOtpEmailFunc           func(ctx context.Context, emailType mailer.EmailType, userId uuid.UUID) error
+	SendInvitationEmailFunc    func(ctx context.Context, params *workers.TeamInvitationJobArgs) error
+	SendSecurityAlertEmailFunc func(ctx context.Context, params *workers.SecurityAlertJobArgs) error
+	SendMagicLinkEmailFunc     func(ctx context.Context, email string) error
+}
+
+// SendMagicLinkEmail implements OtpMailService.
+func (o *OtpMailDecorator) SendMagicLinkEmail(ctx context.Context, email string) error {
+	if o.SendMagicLinkEmailFunc != nil {
+		return o.SendMagicLinkEmailFunc(ctx, email)
+	}
+	return o.Delegate.SendMagicLinkEmail(ctx, email)
+}
+
+// SendSecurityAlertEmail implements OtpMailService.
+func (o *OtpMailDecorator) SendSecurityAlertEmail(ctx context.Context, params *workers.SecurityAlertJobArgs) error {
+	if o.SendSecurityAlertEmailFunc != nil {
+		return o.SendSecurityAlertEmailFunc(ctx, params)
+	}
+	return o.Delegate.SendSecurityAlertEmail(ctx, params)
 }
 
 // SendTeamInvitationEmail implements OtpMailService.
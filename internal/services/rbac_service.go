@@ -1,9 +1,41 @@
 package services
 
-import "github.com/tkahng/playground/internal/stores"
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/shared"
+	"github.com/tkahng/playground/internal/stores"
+)
 
 type RBACService interface {
 	Adapter() stores.StorageAdapterInterface
+	// ExplainPermission lists every path granting the permission to the user.
+	// Granted matches what the permission checks of the api enforce.
+	ExplainPermission(ctx context.Context, userId uuid.UUID, permission string) (*PermissionExplanation, error)
+	// CheckPermissions evaluates the checks like ExplainPermission, users that
+	// do not exist are not granted anything.
+	CheckPermissions(ctx context.Context, checks ...PermissionCheck) ([]*PermissionExplanation, error)
+}
+
+type PermissionCheck struct {
+	UserID     uuid.UUID
+	Permission string
+}
+
+type PermissionExplanation struct {
+	UserID     uuid.UUID `json:"user_id"`
+	Permission string    `json:"permission"`
+	// Granted is whether the permission checks of the api let the user
+	// through, only direct and role grants count.
+	Granted bool                      `json:"granted"`
+	Grants  []*models.PermissionGrant `json:"grants"`
+	// ProductGrants come from subscriptions of the user, they are not
+	// enforced by the permission checks of the api yet.
+	ProductGrants []*models.PermissionGrant `json:"product_grants"`
 }
 
 type rbacService struct {
@@ -15,6 +47,72 @@ func (r *rbacService) Adapter() stores.StorageAdapterInterface {
 	return r.adapter
 }
 
+// ExplainPermission implements RBACService.
+func (r *rbacService) ExplainPermission(ctx context.Context, userId uuid.UUID, permission string) (*PermissionExplanation, error) {
+	user, err := r.adapter.User().FindUserByID(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("error finding user: %w", err)
+	}
+	if user == nil {
+		return nil, shared.ErrUserNotFound
+	}
+	return r.explain(ctx, user, permission)
+}
+
+// CheckPermissions implements RBACService.
+func (r *rbacService) CheckPermissions(ctx context.Context, checks ...PermissionCheck) ([]*PermissionExplanation, error) {
+	results := make([]*PermissionExplanation, len(checks))
+	for i, check := range checks {
+		user, err := r.adapter.User().FindUserByID(ctx, check.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("error finding user: %w", err)
+		}
+		if user == nil {
+			results[i] = &PermissionExplanation{
+				UserID:        check.UserID,
+				Permission:    check.Permission,
+				Grants:        []*models.PermissionGrant{},
+				ProductGrants: []*models.PermissionGrant{},
+			}
+			continue
+		}
+		explanation, err := r.explain(ctx, user, check.Permission)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = explanation
+	}
+	return results, nil
+}
+
+// explain decides Granted from the permissions of the user info, the ones
+// access tokens carry and the permission checks of the api read.
+func (r *rbacService) explain(ctx context.Context, user *models.User, permission string) (*PermissionExplanation, error) {
+	info, err := r.adapter.User().GetUserInfo(ctx, user.Email)
+	if err != nil {
+		return nil, fmt.Errorf("error getting user info: %w", err)
+	}
+	grants, err := r.adapter.Rbac().ListUserPermissionGrants(ctx, user.ID, permission)
+	if err != nil {
+		return nil, fmt.Errorf("error listing permission grants: %w", err)
+	}
+	explanation := &PermissionExplanation{
+		UserID:        user.ID,
+		Permission:    permission,
+		Granted:       slices.Contains(info.Permissions, permission),
+		Grants:        []*models.PermissionGrant{},
+		ProductGrants: []*models.PermissionGrant{},
+	}
+	for _, grant := range grants {
+		if grant.Source == models.PermissionGrantSourceProduct {
+			explanation.ProductGrants = append(explanation.ProductGrants, grant)
+		} else {
+			explanation.Grants = append(explanation.Grants, grant)
+		}
+	}
+	return explanation, nil
+}
+
 func NewRBACService(adapter stores.StorageAdapterInterface) RBACService {
 	return &rbacService{
 		adapter: adapter,
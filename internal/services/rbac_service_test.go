@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/shared"
+	"github.com/tkahng/playground/internal/stores"
+	"github.com/tkahng/playground/internal/tools/types"
+)
+
+func TestRBACServiceExplainPermission(t *testing.T) {
+	ctx := context.Background()
+	user := &models.User{ID: uuid.New(), Email: "explain@example.com"}
+	grant := &models.PermissionGrant{
+		Permission:     "pro",
+		Source:         models.PermissionGrantSourceProduct,
+		ProductID:      types.Pointer("prod_pro"),
+		SubscriptionID: types.Pointer("sub_1"),
+	}
+	adapter := stores.NewAdapterDecorators()
+	adapter.UserFunc.FindUserByIDFunc = func(ctx context.Context, id uuid.UUID) (*models.User, error) {
+		if id != user.ID {
+			return nil, nil
+		}
+		return user, nil
+	}
+	roleGrant := &models.PermissionGrant{
+		Permission: "basic",
+		Source:     models.PermissionGrantSourceRole,
+		RoleName:   types.Pointer("basic"),
+	}
+	adapter.RbacFunc.ListUserPermissionGrantsFunc = func(ctx context.Context, userId uuid.UUID, permissionName string) ([]*models.PermissionGrant, error) {
+		if userId != user.ID {
+			return nil, nil
+		}
+		switch permissionName {
+		case grant.Permission:
+			return []*models.PermissionGrant{grant}, nil
+		case roleGrant.Permission:
+			return []*models.PermissionGrant{roleGrant}, nil
+		}
+		return nil, nil
+	}
+	// the permissions the checks of the api enforce
+	adapter.UserFunc.GetUserInfoFunc = func(ctx context.Context, email string) (*models.UserInfo, error) {
+		return &models.UserInfo{User: *user, Permissions: []string{"basic"}}, nil
+	}
+	service := NewRBACService(adapter)
+
+	explanation, err := service.ExplainPermission(ctx, user.ID, "basic")
+	require.NoError(t, err)
+	assert.True(t, explanation.Granted)
+	assert.Equal(t, []*models.PermissionGrant{roleGrant}, explanation.Grants)
+	assert.Empty(t, explanation.ProductGrants)
+
+	explanation, err = service.ExplainPermission(ctx, user.ID, "pro")
+	require.NoError(t, err)
+	assert.False(t, explanation.Granted, "product grants are not enforced")
+	assert.Empty(t, explanation.Grants)
+	assert.Equal(t, []*models.PermissionGrant{grant}, explanation.ProductGrants)
+
+	explanation, err = service.ExplainPermission(ctx, user.ID, "advanced")
+	require.NoError(t, err)
+	assert.False(t, explanation.Granted)
+	assert.NotNil(t, explanation.Grants, "grants are listed as an empty array")
+
+	_, err = service.ExplainPermission(ctx, uuid.New(), "pro")
+	assert.ErrorIs(t, err, shared.ErrUserNotFound)
+
+	results, err := service.CheckPermissions(ctx,
+		PermissionCheck{UserID: user.ID, Permission: "basic"},
+		PermissionCheck{UserID: user.ID, Permission: "pro"},
+		PermissionCheck{UserID: uuid.New(), Permission: "basic"},
+	)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.True(t, results[0].Granted)
+	assert.False(t, results[1].Granted)
+	assert.False(t, results[2].Granted, "users that do not exist are not granted anything")
+}
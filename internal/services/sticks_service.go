@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/games"
+	"github.com/tkahng/playground/internal/games/sticks"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/stores"
+	"github.com/tkahng/playground/internal/tools/sse"
+)
+
+// ErrGameNotFound is returned for games that ended or the user does not play in.
+var ErrGameNotFound = errors.New("game not found")
+
+// SticksGameSseEvent is sent to the channel of a sticks game after it
+// started and after every move.
+type SticksGameSseEvent struct {
+	Game *sticks.GameView `json:"game"`
+}
+
+type SticksStats struct {
+	ActiveGames int `json:"active_games"`
+	QueueSize   int `json:"queue_size"`
+}
+
+type SticksService interface {
+	// Run starts matchmaking and stops it once ctx is done.
+	Run(ctx context.Context)
+	// Join waits for an opponent of the user until ctx is done, a bot of the
+	// difficulty plays users that wait too long. A user that is already
+	// playing gets their game back.
+	Join(ctx context.Context, user *models.User, difficulty sticks.Difficulty) (*sticks.GameView, error)
+	CurrentGame(ctx context.Context, userID uuid.UUID) (*sticks.GameView, error)
+	Game(ctx context.Context, userID uuid.UUID, gameID string) (*sticks.GameView, error)
+	Attack(ctx context.Context, userID uuid.UUID, gameID string, attackWithLeft bool, attackLeft bool) (*sticks.GameView, error)
+	Split(ctx context.Context, userID uuid.UUID, gameID string, fromLeft bool, points int) (*sticks.GameView, error)
+	// Rating returns the rating of the user, users that never finished a
+	// game have the default rating.
+	Rating(ctx context.Context, userID uuid.UUID) (*models.GameRating, error)
+	Leaderboard(ctx context.Context, limit int) ([]*models.GameRating, error)
+	Stats() SticksStats
+	IsPlayer(gameID string, userID uuid.UUID) bool
+}
+
+var _ SticksService = (*DbSticksService)(nil)
+
+type DbSticksService struct {
+	adapter    stores.StorageAdapterInterface
+	sseManager sse.Manager
+	broker     *sticks.GameBroker
+	botWait    time.Duration
+}
+
+// NewSticksService returns a service matching players on broker, players that
+// waited for botWait play a bot.
+func NewSticksService(adapter stores.StorageAdapterInterface, sseManager sse.Manager, broker *sticks.GameBroker, botWait time.Duration) *DbSticksService {
+	return &DbSticksService{
+		adapter:    adapter,
+		sseManager: sseManager,
+		broker:     broker,
+		botWait:    botWait,
+	}
+}
+
+// Run implements SticksService.
+func (s *DbSticksService) Run(ctx context.Context) {
+	s.broker.SetBotOpponent(s.botWait, func(g *sticks.Game) {
+		s.publish(g.View())
+	})
+	s.broker.Start()
+	<-ctx.Done()
+	s.broker.Stop()
+}
+
+func playerName(user *models.User) string {
+	if user.Name != nil && *user.Name != "" {
+		return *user.Name
+	}
+	return "anonymous"
+}
+
+// Join implements SticksService.
+func (s *DbSticksService) Join(ctx context.Context, user *models.User, difficulty sticks.Difficulty) (*sticks.GameView, error) {
+	if session, ok := s.broker.FindPlayerGame(user.ID.String()); ok {
+		return session.Game.View(), nil
+	}
+	game, err := s.broker.RequestGameContext(
+		ctx,
+		sticks.NewPlayer(user.ID.String(), playerName(user)),
+		sticks.WithBotDifficulty(difficulty),
+	)
+	if err != nil {
+		return nil, err
+	}
+	view := game.View()
+	s.publish(view)
+	return view, nil
+}
+
+// CurrentGame implements SticksService.
+func (s *DbSticksService) CurrentGame(ctx context.Context, userID uuid.UUID) (*sticks.GameView, error) {
+	session, ok := s.broker.FindPlayerGame(userID.String())
+	if !ok {
+		return nil, ErrGameNotFound
+	}
+	return session.Game.View(), nil
+}
+
+func (s *DbSticksService) session(userID uuid.UUID, gameID string) (*sticks.GameSession, error) {
+	session, ok := s.broker.GetGameSession(gameID)
+	if !ok || !session.Game.HasPlayer(userID.String()) {
+		return nil, ErrGameNotFound
+	}
+	return session, nil
+}
+
+// IsPlayer implements SticksService.
+func (s *DbSticksService) IsPlayer(gameID string, userID uuid.UUID) bool {
+	_, err := s.session(userID, gameID)
+	return err == nil
+}
+
+// Game implements SticksService.
+func (s *DbSticksService) Game(ctx context.Context, userID uuid.UUID, gameID string) (*sticks.GameView, error) {
+	session, err := s.session(userID, gameID)
+	if err != nil {
+		return nil, err
+	}
+	return session.Game.View(), nil
+}
+
+// Attack implements SticksService.
+func (s *DbSticksService) Attack(ctx context.Context, userID uuid.UUID, gameID string, attackWithLeft bool, attackLeft bool) (*sticks.GameView, error) {
+	return s.move(ctx, userID, gameID, func(g *sticks.Game) error {
+		return g.AttackAs(userID.String(), attackWithLeft, attackLeft)
+	})
+}
+
+// Split implements SticksService.
+func (s *DbSticksService) Split(ctx context.Context, userID uuid.UUID, gameID string, fromLeft bool, points int) (*sticks.GameView, error) {
+	return s.move(ctx, userID, gameID, func(g *sticks.Game) error {
+		return g.SplitAs(userID.String(), fromLeft, points)
+	})
+}
+
+// move plays a move of the user and shares the new state with both players.
+// Only the move that wins the game finishes it, so results are stored once.
+func (s *DbSticksService) move(ctx context.Context, userID uuid.UUID, gameID string, play func(g *sticks.Game) error) (*sticks.GameView, error) {
+	session, err := s.session(userID, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if err := play(session.Game); err != nil {
+		return nil, err
+	}
+	view := session.Game.View()
+	if view.State == sticks.GameStateFinished && view.WinnerID != "" && !hasBot(view) {
+		if err := s.recordResult(ctx, view); err != nil {
+			slog.ErrorContext(ctx, "error recording sticks result", slog.String("game_id", view.ID), slog.Any("error", err))
+		}
+	}
+	s.publish(view)
+	return view, nil
+}
+
+// hasBot reports whether a bot plays in the game, games against bots are
+// not rated.
+func hasBot(view *sticks.GameView) bool {
+	for _, p := range view.Players {
+		if p.Bot {
+			return true
+		}
+	}
+	return false
+}
+
+// recordResult stores the result of a finished game and updates the records
+// and ratings of both players.
+func (s *DbSticksService) recordResult(ctx context.Context, view *sticks.GameView) error {
+	winnerID, err := uuid.Parse(view.WinnerID)
+	if err != nil {
+		return err
+	}
+	var loserID uuid.UUID
+	for _, p := range view.Players {
+		if p.ID != view.WinnerID {
+			if loserID, err = uuid.Parse(p.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return s.adapter.RunInTx(func(tx stores.StorageAdapterInterface) error {
+		ratings, err := tx.Game().LockGameRatings(ctx, models.GameSticks, []uuid.UUID{winnerID, loserID})
+		if err != nil {
+			return err
+		}
+		var winner, loser *models.GameRating
+		for _, rating := range ratings {
+			switch rating.UserID {
+			case winnerID:
+				winner = rating
+			case loserID:
+				loser = rating
+			}
+		}
+		if winner == nil || loser == nil {
+			return errors.New("ratings of the players not found")
+		}
+		winnerRating, loserRating := games.Elo(winner.Rating, loser.Rating)
+		change := winnerRating - winner.Rating
+		winner.Rating, winner.Wins = winnerRating, winner.Wins+1
+		loser.Rating, loser.Losses = loserRating, loser.Losses+1
+		if err := tx.Game().UpdateGameRating(ctx, winner); err != nil {
+			return err
+		}
+		if err := tx.Game().UpdateGameRating(ctx, loser); err != nil {
+			return err
+		}
+		_, err = tx.Game().CreateGameResult(ctx, &models.GameResult{
+			Game:         models.GameSticks,
+			GameID:       view.ID,
+			WinnerID:     winnerID,
+			LoserID:      loserID,
+			WinnerRating: winnerRating,
+			LoserRating:  loserRating,
+			RatingChange: change,
+			Moves:        view.Moves,
+			StartedAt:    view.CreatedAt,
+		})
+		return err
+	})
+}
+
+func (s *DbSticksService) publish(view *sticks.GameView) {
+	err := s.sseManager.SendMessage(sse.SticksGameChannel(view.ID), sse.Message{Data: &SticksGameSseEvent{Game: view}})
+	if err != nil {
+		slog.Error("error sending sticks game", slog.String("game_id", view.ID), slog.Any("error", err))
+	}
+}
+
+// Rating implements SticksService.
+func (s *DbSticksService) Rating(ctx context.Context, userID uuid.UUID) (*models.GameRating, error) {
+	ratings, err := s.adapter.Game().FindGameRatings(ctx, models.GameSticks, []uuid.UUID{userID})
+	if err != nil {
+		return nil, err
+	}
+	if len(ratings) == 0 {
+		return &models.GameRating{UserID: userID, Game: models.GameSticks, Rating: games.DefaultRating}, nil
+	}
+	return ratings[0], nil
+}
+
+// Leaderboard implements SticksService.
+func (s *DbSticksService) Leaderboard(ctx context.Context, limit int) ([]*models.GameRating, error) {
+	return s.adapter.Game().ListTopGameRatings(ctx, models.GameSticks, limit)
+}
+
+// Stats implements SticksService.
+func (s *DbSticksService) Stats() SticksStats {
+	return SticksStats{
+		ActiveGames: s.broker.GetActiveGameCount(),
+		QueueSize:   s.broker.GetQueueSize(),
+	}
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkahng/playground/internal/games/sticks"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/stores"
+	"github.com/tkahng/playground/internal/tools/sse"
+)
+
+type syncSseManager struct {
+	sse.Manager
+	mu       sync.Mutex
+	channels []string
+}
+
+func (m *syncSseManager) SendMessage(channel string, msg sse.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.channels = append(m.channels, channel)
+	return nil
+}
+
+func newTestSticksService(t *testing.T) (*DbSticksService, *stores.StorageAdapterDecorator, *syncSseManager) {
+	adapter := stores.NewAdapterDecorators()
+	adapter.RunInTxFunc = func(fn func(tx stores.StorageAdapterInterface) error) error {
+		return fn(adapter)
+	}
+	manager := &syncSseManager{}
+	broker := sticks.NewGameBroker(10)
+	broker.Start()
+	t.Cleanup(broker.Stop)
+	return NewSticksService(adapter, manager, broker, 0), adapter, manager
+}
+
+func joinSticks(t *testing.T, service *DbSticksService, users ...*models.User) *sticks.GameView {
+	views := make(chan *sticks.GameView, len(users))
+	for _, user := range users {
+		go func() {
+			view, err := service.Join(context.Background(), user, sticks.DifficultyMedium)
+			assert.NoError(t, err)
+			views <- view
+		}()
+	}
+	var view *sticks.GameView
+	for range users {
+		view = <-views
+		require.NotNil(t, view)
+	}
+	return view
+}
+
+func TestSticksService_PlayToTheEnd(t *testing.T) {
+	service, adapter, manager := newTestSticksService(t)
+	alice := &models.User{ID: uuid.New()}
+	bob := &models.User{ID: uuid.New()}
+
+	ratings := map[uuid.UUID]*models.GameRating{}
+	adapter.GameFunc.LockGameRatingsFunc = func(ctx context.Context, game string, userIDs []uuid.UUID) ([]*models.GameRating, error) {
+		var res []*models.GameRating
+		for _, id := range userIDs {
+			res = append(res, &models.GameRating{UserID: id, Game: game, Rating: 1200})
+		}
+		return res, nil
+	}
+	adapter.GameFunc.UpdateGameRatingFunc = func(ctx context.Context, rating *models.GameRating) error {
+		ratings[rating.UserID] = rating
+		return nil
+	}
+	var result *models.GameResult
+	adapter.GameFunc.CreateGameResultFunc = func(ctx context.Context, r *models.GameResult) (*models.GameResult, error) {
+		result = r
+		return r, nil
+	}
+
+	view := joinSticks(t, service, alice, bob)
+	assert.Equal(t, sticks.GameStateInProgress, view.State)
+
+	first, err := uuid.Parse(view.CurrentPlayerID)
+	require.NoError(t, err)
+	second := alice.ID
+	if first == alice.ID {
+		second = bob.ID
+	}
+
+	_, err = service.Attack(context.Background(), second, view.ID, true, true)
+	assert.ErrorIs(t, err, sticks.ErrNotYourTurn)
+	_, err = service.Attack(context.Background(), uuid.New(), view.ID, true, true)
+	assert.ErrorIs(t, err, ErrGameNotFound)
+
+	moves := []struct {
+		player                     uuid.UUID
+		attackWithLeft, attackLeft bool
+	}{
+		{first, true, true},
+		{second, true, true},
+		{first, true, true},
+		{second, false, true},
+		{first, true, false},
+	}
+	for _, m := range moves {
+		view, err = service.Attack(context.Background(), m.player, view.ID, m.attackWithLeft, m.attackLeft)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, sticks.GameStateFinished, view.State)
+	assert.Equal(t, first.String(), view.WinnerID)
+
+	require.NotNil(t, result)
+	assert.Equal(t, first, result.WinnerID)
+	assert.Equal(t, second, result.LoserID)
+	assert.Equal(t, 16, result.RatingChange)
+	assert.Equal(t, 5, result.Moves)
+	assert.Equal(t, 1216, ratings[first].Rating)
+	assert.Equal(t, 1, ratings[first].Wins)
+	assert.Equal(t, 1184, ratings[second].Rating)
+	assert.Equal(t, 1, ratings[second].Losses)
+
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+	assert.Contains(t, manager.channels, sse.SticksGameChannel(view.ID))
+}
+
+func TestSticksService_PlayBot(t *testing.T) {
+	adapter := stores.NewAdapterDecorators()
+	adapter.GameFunc.LockGameRatingsFunc = func(ctx context.Context, game string, userIDs []uuid.UUID) ([]*models.GameRating, error) {
+		t.Error("games against bots are not rated")
+		return nil, nil
+	}
+	manager := &syncSseManager{}
+	service := NewSticksService(adapter, manager, sticks.NewGameBroker(10), 10*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		service.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	user := &models.User{ID: uuid.New()}
+	view, err := service.Join(context.Background(), user, sticks.DifficultyHard)
+	require.NoError(t, err)
+	require.Len(t, view.Players, 2)
+	assert.True(t, view.Players[1].Bot)
+
+	// the user moves first, then the bot answers until the game ends
+	for range 100 {
+		view, err = service.Game(context.Background(), user.ID, view.ID)
+		require.NoError(t, err)
+		if view.State == sticks.GameStateFinished {
+			break
+		}
+		if view.CurrentPlayerID != user.ID.String() {
+			time.Sleep(5 * time.Millisecond)
+			continue
+		}
+		state := sticks.State{Hands: [2][2]int{
+			{view.Players[0].LeftHand, view.Players[0].RightHand},
+			{view.Players[1].LeftHand, view.Players[1].RightHand},
+		}}
+		move := state.Moves()[0]
+		if move.Kind == sticks.MoveAttack {
+			_, err = service.Attack(context.Background(), user.ID, view.ID, move.AttackWithLeft, move.AttackLeft)
+		} else {
+			_, err = service.Split(context.Background(), user.ID, view.ID, move.FromLeft, move.Points)
+		}
+		require.NoError(t, err)
+	}
+	assert.Greater(t, view.Moves, 1)
+	assert.NotEqual(t, user.ID.String(), view.WinnerID, "the hard bot lost")
+}
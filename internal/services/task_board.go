@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/tools/sse"
+)
+
+// TaskPresenceTTL is how long a presence lasts without being refreshed.
+const TaskPresenceTTL = 30 * time.Second
+
+type TaskBoardEventKind string
+
+const (
+	TaskBoardEventCreated TaskBoardEventKind = "created"
+	TaskBoardEventUpdated TaskBoardEventKind = "updated"
+	TaskBoardEventMoved   TaskBoardEventKind = "moved"
+	TaskBoardEventDeleted TaskBoardEventKind = "deleted"
+)
+
+// TaskBoardSseEvent is sent to the project channel whenever a task of the
+// project changes. Status and Rank are the position of the task after the
+// change so boards can be patched without reloading, Task is omitted for
+// deleted tasks.
+type TaskBoardSseEvent struct {
+	Kind          TaskBoardEventKind `json:"kind" enum:"created,updated,moved,deleted"`
+	ProjectID     uuid.UUID          `json:"project_id"`
+	TaskID        uuid.UUID          `json:"task_id"`
+	Status        models.TaskStatus  `json:"status" enum:"todo,in_progress,done"`
+	Rank          float64            `json:"rank"`
+	Task          *models.Task       `json:"task,omitempty"`
+	ActorMemberID uuid.UUID          `json:"actor_member_id"`
+}
+
+// TaskPresenceSseEvent is sent to the project channel when a team member
+// starts or stops viewing or editing the board. Left is set once the member
+// has left, otherwise the presence lasts until ExpiresAt unless refreshed.
+type TaskPresenceSseEvent struct {
+	models.TaskProjectPresence
+	Left bool `json:"left"`
+}
+
+// TaskBoardPublisher broadcasts task and presence changes to everyone
+// subscribed to the project channel. A nil publisher drops every event.
+type TaskBoardPublisher struct {
+	sseManager sse.Manager
+}
+
+func NewTaskBoardPublisher(sseManager sse.Manager) *TaskBoardPublisher {
+	return &TaskBoardPublisher{
+		sseManager: sseManager,
+	}
+}
+
+// PublishTask sends a TaskBoardSseEvent for task.
+func (p *TaskBoardPublisher) PublishTask(ctx context.Context, kind TaskBoardEventKind, task *models.Task, actorMemberID uuid.UUID) {
+	if p == nil || task == nil {
+		return
+	}
+	event := TaskBoardSseEvent{
+		Kind:          kind,
+		ProjectID:     task.ProjectID,
+		TaskID:        task.ID,
+		Status:        task.Status,
+		Rank:          task.Rank,
+		ActorMemberID: actorMemberID,
+	}
+	if kind != TaskBoardEventDeleted {
+		event.Task = task
+	}
+	p.send(ctx, task.ProjectID, event)
+}
+
+// PublishPresence sends a TaskPresenceSseEvent for presence.
+func (p *TaskBoardPublisher) PublishPresence(ctx context.Context, presence *models.TaskProjectPresence, left bool) {
+	if p == nil || presence == nil {
+		return
+	}
+	p.send(ctx, presence.ProjectID, TaskPresenceSseEvent{
+		TaskProjectPresence: *presence,
+		Left:                left,
+	})
+}
+
+func (p *TaskBoardPublisher) send(ctx context.Context, projectID uuid.UUID, data any) {
+	// the change is already committed, a failed broadcast only means viewers
+	// have to reload
+	if err := p.sseManager.Send(sse.ProjectChannel(projectID.String()), data); err != nil {
+		slog.ErrorContext(ctx, "error sending task board event", slog.Any("error", err))
+	}
+}
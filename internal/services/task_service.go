@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/tkahng/playground/internal/models"
 	"github.com/tkahng/playground/internal/stores"
+	"github.com/tkahng/playground/internal/workers"
 )
 
 type TaskFields struct {
@@ -27,10 +28,31 @@ type TaskFields struct {
 	ParentID          *uuid.UUID        `db:"parent_id" json:"parent_id" nullable:"true"`
 }
 type TaskService interface {
+	// CreateTask creates the task and, when it has an end date, enqueues the
+	// due today notification job in the same transaction.
 	CreateTask(ctx context.Context, teamID uuid.UUID, projectID uuid.UUID, createdByMemberID uuid.UUID, input *TaskFields) (*models.Task, error)
 
 	// CreateTaskWithChildren(ctx context.Context, teamID uuid.UUID, projectID uuid.UUID, memberID uuid.UUID, input *shared.CreateTaskWithChildrenDTO) (*models.Task, error)
-	UpdateTaskRankStatus(ctx context.Context, taskID uuid.UUID, position int64, status models.TaskStatus) error
+
+	// UpdateTask updates the task and enqueues the assigned, due and completed
+	// notification jobs in the same transaction.
+	UpdateTask(ctx context.Context, taskID uuid.UUID, updatedByMemberID uuid.UUID, input *stores.UpdateTaskDto) (*models.Task, error)
+
+	// UpdateTaskRankStatus moves the task and, when it is moved to done,
+	// enqueues the completed notification job in the same transaction.
+	UpdateTaskRankStatus(ctx context.Context, taskID uuid.UUID, updatedByMemberID uuid.UUID, position int64, status models.TaskStatus) error
+
+	// DeleteTask deletes the task and tells the viewers of its project.
+	DeleteTask(ctx context.Context, taskID uuid.UUID, deletedByMemberID uuid.UUID) error
+
+	// UpdatePresence marks the team member as viewing the project board, or
+	// viewing or editing a single task of it, for TaskPresenceTTL.
+	UpdatePresence(ctx context.Context, projectID uuid.UUID, teamMemberID uuid.UUID, taskID *uuid.UUID, state models.TaskPresenceState) (*models.TaskProjectPresence, error)
+	// LeavePresence removes the presence of the team member on the project.
+	LeavePresence(ctx context.Context, projectID uuid.UUID, teamMemberID uuid.UUID) error
+	// FindPresences returns everyone currently on the project board.
+	FindPresences(ctx context.Context, projectID uuid.UUID) ([]*models.TaskProjectPresence, error)
+
 	CalculateNewPosition(ctx context.Context, groupID uuid.UUID, status models.TaskStatus, targetIndex int64, excludeID uuid.UUID) (float64, error)
 }
 type taskService struct {
@@ -38,6 +60,17 @@ type taskService struct {
 	adapter stores.StorageAdapterInterface
 
 	jobService JobService
+
+	board *TaskBoardPublisher
+}
+
+type TaskServiceOption func(*taskService)
+
+// WithTaskBoard broadcasts task and presence changes through publisher.
+func WithTaskBoard(publisher *TaskBoardPublisher) TaskServiceOption {
+	return func(s *taskService) {
+		s.board = publisher
+	}
 }
 
 // CreateTask implements TaskService.
@@ -56,18 +89,41 @@ func (s *taskService) CreateTask(ctx context.Context, teamID uuid.UUID, projectI
 		EndAt:             input.EndAt,
 		ParentID:          input.ParentID,
 	}
-	task, err := s.adapter.Task().CreateTask(ctx, &setter)
+	var task *models.Task
+	err := s.adapter.RunInTx(func(tx stores.StorageAdapterInterface) error {
+		var err error
+		task, err = tx.Task().CreateTask(ctx, &setter)
+		if err != nil {
+			return err
+		}
+		if task.EndAt == nil {
+			return nil
+		}
+		dueDate := *task.EndAt
+		if dueDate.Before(time.Now()) {
+			dueDate = time.Now().Add(10 * time.Second)
+		}
+		return s.jobService.WithTx(tx.Db()).EnqueTaskDueJob(ctx, &workers.TaskDueTodayJobArgs{
+			TaskID:  task.ID,
+			DueDate: dueDate,
+		})
+	})
 	if err != nil {
 		return nil, err
 	}
+	s.board.PublishTask(ctx, TaskBoardEventCreated, task, createdByMemberID)
 	return task, nil
 }
 
-func NewTaskService(adapter stores.StorageAdapterInterface, jobService JobService) TaskService {
-	return &taskService{
+func NewTaskService(adapter stores.StorageAdapterInterface, jobService JobService, opts ...TaskServiceOption) TaskService {
+	s := &taskService{
 		adapter:    adapter,
 		jobService: jobService,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 var _ TaskService = (*taskService)(nil)
@@ -84,33 +140,197 @@ type UpdateTaskDto struct {
 	ParentID    *uuid.UUID        `db:"parent_id" json:"parent_id" nullable:"true"`
 }
 
-func (s *taskService) UpdateTaskRankStatus(ctx context.Context, taskID uuid.UUID, position int64, status models.TaskStatus) error {
+var ErrTaskNotFound = errors.New("task not found")
+
+func (s *taskService) UpdateTask(ctx context.Context, taskID uuid.UUID, updatedByMemberID uuid.UUID, input *stores.UpdateTaskDto) (*models.Task, error) {
+	var task *models.Task
+	err := s.adapter.RunInTx(func(tx stores.StorageAdapterInterface) error {
+		var err error
+		task, err = tx.Task().FindTaskByID(ctx, taskID)
+		if err != nil {
+			return err
+		}
+		if task == nil {
+			return ErrTaskNotFound
+		}
+		previousStatus := task.Status
+		previousDueDate := task.EndAt
+		previousAssignee := task.AssigneeID
+
+		task.Name = input.Name
+		task.Description = input.Description
+		task.Status = input.Status
+		task.StartAt = input.StartAt
+		task.EndAt = input.EndAt
+		task.AssigneeID = input.AssigneeID
+		task.ReporterID = input.ReporterID
+		task.ParentID = input.ParentID
+
+		if err := tx.Task().UpdateTask(ctx, task); err != nil {
+			return err
+		}
+
+		// jobs are written through the same transaction, a rolled back update
+		// never leaves a notification behind.
+		jobService := s.jobService.WithTx(tx.Db())
+
+		newAssignee := previousAssignee == nil && input.AssigneeID != nil
+		differentAssignee := previousAssignee != nil && input.AssigneeID != nil && *previousAssignee != *input.AssigneeID
+		if newAssignee || differentAssignee {
+			err = jobService.EnqueAssignedToTaskJob(ctx, &workers.AssignedToTasJobArgs{
+				TaskID:              task.ID,
+				AssignedByMemeberID: updatedByMemberID,
+				AssigneeMemberID:    *input.AssigneeID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		newDueDate := previousDueDate == nil && task.EndAt != nil
+		differentDueDate := previousDueDate != nil && task.EndAt != nil && *previousDueDate != *task.EndAt
+		if newDueDate || differentDueDate {
+			dueDate := *task.EndAt
+			if dueDate.Before(time.Now()) {
+				dueDate = time.Now().Add(10 * time.Second)
+			}
+			err = jobService.EnqueTaskDueJob(ctx, &workers.TaskDueTodayJobArgs{
+				TaskID:  task.ID,
+				DueDate: dueDate,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if previousStatus != task.Status && task.Status == models.TaskStatusDone {
+			err = jobService.EnqueueTaskCompletedJob(ctx, &workers.TaskCompletedJobArgs{
+				TaskID:              task.ID,
+				CompletedByMemberID: updatedByMemberID,
+				CompletedAt:         time.Now(),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.board.PublishTask(ctx, TaskBoardEventUpdated, task, updatedByMemberID)
+	return task, nil
+}
+
+func (s *taskService) UpdateTaskRankStatus(ctx context.Context, taskID uuid.UUID, updatedByMemberID uuid.UUID, position int64, status models.TaskStatus) error {
+	var task *models.Task
+	err := s.adapter.RunInTx(func(tx stores.StorageAdapterInterface) error {
+		var err error
+		task, err = tx.Task().FindTaskByID(ctx, taskID)
+		if err != nil {
+			return err
+		}
+		if task == nil {
+			return ErrTaskNotFound
+		}
+		previousStatus := task.Status
+		rank, err := calculateNewPosition(ctx, tx, task.ProjectID, status, position, task.ID)
+		if err != nil {
+			return err
+		}
+		task.Rank = rank
+		task.Status = status
+		err = tx.Task().UpdateTask(ctx, task)
+		if err != nil {
+			return err
+		}
+		err = tx.Task().UpdateTaskProjectUpdateDate(ctx, task.ProjectID)
+		if err != nil {
+			return fmt.Errorf("failed to update task project update date: %w", err)
+		}
+		if previousStatus != models.TaskStatusDone && status == models.TaskStatusDone {
+			err = s.jobService.WithTx(tx.Db()).EnqueueTaskCompletedJob(ctx, &workers.TaskCompletedJobArgs{
+				TaskID:              task.ID,
+				CompletedByMemberID: updatedByMemberID,
+				CompletedAt:         time.Now(),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.board.PublishTask(ctx, TaskBoardEventMoved, task, updatedByMemberID)
+	return nil
+}
+
+func (s *taskService) DeleteTask(ctx context.Context, taskID uuid.UUID, deletedByMemberID uuid.UUID) error {
 	task, err := s.adapter.Task().FindTaskByID(ctx, taskID)
 	if err != nil {
 		return err
 	}
 	if task == nil {
-		return errors.New("task not found")
+		return ErrTaskNotFound
 	}
-	rank, err := s.CalculateNewPosition(ctx, task.ProjectID, status, position, task.ID)
-	if err != nil {
+	if err := s.adapter.Task().DeleteTask(ctx, taskID); err != nil {
 		return err
 	}
-	task.Rank = rank
-	task.Status = status
-	err = s.adapter.Task().UpdateTask(ctx, task)
+	s.board.PublishTask(ctx, TaskBoardEventDeleted, task, deletedByMemberID)
+	return nil
+}
+
+func (s *taskService) UpdatePresence(ctx context.Context, projectID uuid.UUID, teamMemberID uuid.UUID, taskID *uuid.UUID, state models.TaskPresenceState) (*models.TaskProjectPresence, error) {
+	if taskID != nil {
+		task, err := s.adapter.Task().FindTaskByID(ctx, *taskID)
+		if err != nil {
+			return nil, err
+		}
+		if task == nil || task.ProjectID != projectID {
+			return nil, ErrTaskNotFound
+		}
+	}
+	presence, err := s.adapter.Task().UpsertTaskProjectPresence(ctx, &models.TaskProjectPresence{
+		ProjectID:    projectID,
+		TeamMemberID: teamMemberID,
+		TaskID:       taskID,
+		State:        state,
+		ExpiresAt:    time.Now().Add(TaskPresenceTTL),
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	err = s.adapter.Task().UpdateTaskProjectUpdateDate(ctx, task.ProjectID)
+	s.board.PublishPresence(ctx, presence, false)
+	return presence, nil
+}
+
+func (s *taskService) LeavePresence(ctx context.Context, projectID uuid.UUID, teamMemberID uuid.UUID) error {
+	active, err := s.adapter.Task().DeleteTaskProjectPresence(ctx, projectID, teamMemberID)
 	if err != nil {
-		return fmt.Errorf("failed to update task project update date: %w", err)
+		return err
+	}
+	if active {
+		s.board.PublishPresence(ctx, &models.TaskProjectPresence{
+			ProjectID:    projectID,
+			TeamMemberID: teamMemberID,
+			UpdatedAt:    time.Now(),
+		}, true)
 	}
 	return nil
 }
 
+func (s *taskService) FindPresences(ctx context.Context, projectID uuid.UUID) ([]*models.TaskProjectPresence, error) {
+	return s.adapter.Task().FindTaskProjectPresences(ctx, projectID)
+}
+
 func (s *taskService) CalculateNewPosition(ctx context.Context, groupID uuid.UUID, status models.TaskStatus, targetIndex int64, excludeID uuid.UUID) (float64, error) {
-	count, err := s.adapter.Task().CountItems(ctx, groupID, status, excludeID)
+	return calculateNewPosition(ctx, s.adapter, groupID, status, targetIndex, excludeID)
+}
+
+func calculateNewPosition(ctx context.Context, adapter stores.StorageAdapterInterface, groupID uuid.UUID, status models.TaskStatus, targetIndex int64, excludeID uuid.UUID) (float64, error) {
+	count, err := adapter.Task().CountItems(ctx, groupID, status, excludeID)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count items: %w", err)
 	}
@@ -121,7 +341,7 @@ func (s *taskService) CalculateNewPosition(ctx context.Context, groupID uuid.UUI
 
 	if targetIndex <= 0 {
 		// Insert at beginning
-		firstPos, err := s.adapter.Task().GetTaskFirstPosition(ctx, groupID, status, excludeID)
+		firstPos, err := adapter.Task().GetTaskFirstPosition(ctx, groupID, status, excludeID)
 		if err != nil {
 			return 0, fmt.Errorf("failed to get first rank: %w", err)
 		}
@@ -130,7 +350,7 @@ func (s *taskService) CalculateNewPosition(ctx context.Context, groupID uuid.UUI
 
 	if targetIndex >= count {
 		// Insert at end
-		lastPos, err := s.adapter.Task().GetTaskLastPosition(ctx, groupID, status, excludeID)
+		lastPos, err := adapter.Task().GetTaskLastPosition(ctx, groupID, status, excludeID)
 		if err != nil {
 			return 0, fmt.Errorf("failed to get last rank: %w", err)
 		}
@@ -138,7 +358,7 @@ func (s *taskService) CalculateNewPosition(ctx context.Context, groupID uuid.UUI
 	}
 
 	// Insert between two ranks
-	ranks, err := s.adapter.Task().GetTaskPositions(ctx, groupID, status, excludeID, targetIndex-1)
+	ranks, err := adapter.Task().GetTaskPositions(ctx, groupID, status, excludeID, targetIndex-1)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get ranks: %w", err)
 	}
@@ -2,6 +2,7 @@ package services_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/google/uuid"
@@ -12,6 +13,7 @@ import (
 	"github.com/tkahng/playground/internal/stores"
 	"github.com/tkahng/playground/internal/test"
 	"github.com/tkahng/playground/internal/tools/types"
+	"github.com/tkahng/playground/internal/workers"
 )
 
 func TestDefineTaskOrderNumberByStatus(t *testing.T) {
@@ -155,3 +157,108 @@ func TestDefineTaskOrderNumberByStatus(t *testing.T) {
 		return test.ErrEndTest
 	})
 }
+
+func TestTaskService_UpdateTask_EnqueuesInTransaction(t *testing.T) {
+	test.SkipIfShort(t)
+	ctx, dbx := test.DbSetup()
+	_ = dbx.RunInTx(func(dbxx database.Dbx) error {
+		adapter := stores.NewStorageAdapter(dbxx)
+		user, err := adapter.User().CreateUser(ctx, &models.User{
+			Email: "tkahng+outbox@gmail.com",
+		})
+		if err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+		member, err := adapter.TeamMember().CreateTeamMemberFromUserAndSlug(ctx, user, "OutboxTeam", models.TeamMemberRoleOwner)
+		if err != nil {
+			t.Fatalf("failed to create team from user: %v", err)
+		}
+		taskProject, err := adapter.Task().CreateTaskProject(ctx, &stores.CreateTaskProjectDTO{
+			Name:     "Outbox Project",
+			Status:   models.TaskProjectStatusInProgress,
+			TeamID:   member.TeamID,
+			MemberID: member.ID,
+		})
+		if err != nil {
+			t.Fatalf("failed to create task project: %v", err)
+		}
+		newTask := func(name string) *models.Task {
+			task, err := adapter.Task().CreateTask(ctx, &models.Task{
+				Name:              name,
+				Status:            models.TaskStatusTodo,
+				Rank:              1000,
+				TeamID:            member.TeamID,
+				ProjectID:         taskProject.ID,
+				CreatedByMemberID: types.Pointer(member.ID),
+			})
+			if err != nil {
+				t.Fatalf("failed to create task: %v", err)
+			}
+			return task
+		}
+		countJobs := func(kind string, taskID uuid.UUID) int64 {
+			var count int64
+			err := dbxx.QueryRow(
+				ctx,
+				"SELECT count(*) FROM jobs WHERE kind = $1 AND payload->>'task_id' = $2",
+				kind,
+				taskID.String(),
+			).Scan(&count)
+			if err != nil {
+				t.Fatalf("failed to count jobs: %v", err)
+			}
+			return count
+		}
+
+		t.Run("completed job is committed with the task update", func(t *testing.T) {
+			task := newTask("Commit")
+			taskService := services.NewTaskService(adapter, services.NewJobService(jobs.NewDbJobManager(dbxx)))
+			_, err := taskService.UpdateTask(ctx, task.ID, member.ID, &stores.UpdateTaskDto{
+				Name:   task.Name,
+				Status: models.TaskStatusDone,
+			})
+			if err != nil {
+				t.Fatalf("UpdateTask() error = %v", err)
+			}
+			if got := countJobs(workers.TaskCompletedJobArgs{}.Kind(), task.ID); got != 1 {
+				t.Errorf("UpdateTask() task_completed jobs = %v, want %v", got, 1)
+			}
+		})
+
+		t.Run("rolled back update leaves no jobs behind", func(t *testing.T) {
+			task := newTask("Rollback")
+			jobService := services.NewJobServiceDecorator(jobs.NewDbJobManager(dbxx))
+			jobService.WithTxFunc = func(db database.Dbx) services.JobService {
+				txJobService := services.NewJobServiceDecorator(jobs.NewDbJobManager(db))
+				txJobService.EnqueueTaskCompletedJobFunc = func(ctx context.Context, job *workers.TaskCompletedJobArgs) error {
+					return errors.New("enqueue failed")
+				}
+				return txJobService
+			}
+			taskService := services.NewTaskService(adapter, jobService)
+			_, err := taskService.UpdateTask(ctx, task.ID, member.ID, &stores.UpdateTaskDto{
+				Name:       "Renamed",
+				Status:     models.TaskStatusDone,
+				AssigneeID: types.Pointer(member.ID),
+			})
+			if err == nil {
+				t.Fatalf("UpdateTask() error = nil, want error")
+			}
+			// the assigned job was enqueued before the failure and must be rolled back with the task update
+			if got := countJobs(workers.AssignedToTasJobArgs{}.Kind(), task.ID); got != 0 {
+				t.Errorf("UpdateTask() assigned_to_task jobs = %v, want %v", got, 0)
+			}
+			if got := countJobs(workers.TaskCompletedJobArgs{}.Kind(), task.ID); got != 0 {
+				t.Errorf("UpdateTask() task_completed jobs = %v, want %v", got, 0)
+			}
+			got, err := adapter.Task().FindTaskByID(ctx, task.ID)
+			if err != nil {
+				t.Fatalf("FindTaskByID() error = %v", err)
+			}
+			if got.Status != models.TaskStatusTodo || got.Name != "Rollback" {
+				t.Errorf("UpdateTask() task = %v %v, want unchanged", got.Name, got.Status)
+			}
+		})
+		return test.ErrEndTest
+	})
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/database"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/stores"
+)
+
+var (
+	ErrTeamRoleNotFound           = errors.New("team role not found")
+	ErrTeamRoleAssignmentNotFound = errors.New("team role assignment not found")
+	ErrTeamRoleTaken              = errors.New("the team has a role with this name")
+	ErrTeamRoleAssigned           = errors.New("the role is already assigned to the member")
+	// ErrTeamRoleReserved refuses to override the owner role, owners keep
+	// every permission.
+	ErrTeamRoleReserved = errors.New("the owner role cannot be changed")
+	// ErrTeamRoleOwner refuses to assign the owner role, ownership is the
+	// role of the team member.
+	ErrTeamRoleOwner       = errors.New("the owner role cannot be assigned")
+	ErrTeamRoleInvalid     = errors.New("invalid team role")
+	ErrTeamMemberNotFound  = errors.New("team member not found")
+	ErrTaskProjectNotFound = errors.New("task project not found")
+)
+
+// TeamAuthorizer decides what members can do in their team.
+type TeamAuthorizer interface {
+	// Permissions lists the team permissions of the member, with the roles
+	// assigned to it in the task project when projectID is not nil.
+	Permissions(ctx context.Context, member *models.TeamMember, projectID *uuid.UUID) ([]string, error)
+	// Can reports whether the member has one of the permissions.
+	Can(ctx context.Context, member *models.TeamMember, projectID *uuid.UUID, permissions ...string) (bool, error)
+}
+
+type TeamRoleInput struct {
+	Name        string
+	Description *string
+	Permissions []string
+}
+
+// TeamRoleService manages the roles of teams and their assignment to team
+// members.
+type TeamRoleService interface {
+	TeamAuthorizer
+	Roles(ctx context.Context, teamID uuid.UUID) ([]*models.TeamRole, error)
+	// CreateRole creates a role of the team. Naming it member or guest
+	// overrides the default role for the members of that role.
+	CreateRole(ctx context.Context, teamID uuid.UUID, input *TeamRoleInput) (*models.TeamRole, error)
+	UpdateRole(ctx context.Context, teamID uuid.UUID, roleID uuid.UUID, input *TeamRoleInput) (*models.TeamRole, error)
+	// DeleteRole deletes a role of the team, default roles cannot be deleted.
+	DeleteRole(ctx context.Context, teamID uuid.UUID, roleID uuid.UUID) error
+	Assignments(ctx context.Context, teamID uuid.UUID, memberID uuid.UUID) ([]*models.TeamRoleAssignment, error)
+	// AssignRole grants a role to a member of the team, in one of its task
+	// projects when projectID is not nil. The owner role is not assigned.
+	AssignRole(ctx context.Context, teamID uuid.UUID, memberID uuid.UUID, roleID uuid.UUID, projectID *uuid.UUID) (*models.TeamRoleAssignment, error)
+	UnassignRole(ctx context.Context, teamID uuid.UUID, memberID uuid.UUID, assignmentID uuid.UUID) error
+}
+
+type DbTeamRoleService struct {
+	adapter stores.StorageAdapterInterface
+}
+
+func NewTeamRoleService(adapter stores.StorageAdapterInterface) *DbTeamRoleService {
+	return &DbTeamRoleService{
+		adapter: adapter,
+	}
+}
+
+var _ TeamRoleService = (*DbTeamRoleService)(nil)
+
+// Permissions implements TeamAuthorizer.
+func (s *DbTeamRoleService) Permissions(ctx context.Context, member *models.TeamMember, projectID *uuid.UUID) ([]string, error) {
+	if member.Role == models.TeamMemberRoleOwner {
+		return slices.Clone(models.TeamPermissions), nil
+	}
+	permissions, err := s.adapter.TeamRole().ListTeamMemberPermissions(ctx, member, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing team member permissions: %w", err)
+	}
+	return permissions, nil
+}
+
+// Can implements TeamAuthorizer.
+func (s *DbTeamRoleService) Can(ctx context.Context, member *models.TeamMember, projectID *uuid.UUID, permissions ...string) (bool, error) {
+	if len(permissions) == 0 {
+		return true, nil
+	}
+	granted, err := s.Permissions(ctx, member, projectID)
+	if err != nil {
+		return false, err
+	}
+	for _, permission := range permissions {
+		if slices.Contains(granted, permission) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Roles implements TeamRoleService.
+func (s *DbTeamRoleService) Roles(ctx context.Context, teamID uuid.UUID) ([]*models.TeamRole, error) {
+	return s.adapter.TeamRole().ListTeamRoles(ctx, teamID)
+}
+
+// normalizeTeamRole trims the name of a role and checks its permissions.
+func normalizeTeamRole(input *TeamRoleInput) (*TeamRoleInput, error) {
+	name := strings.ToLower(strings.TrimSpace(input.Name))
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrTeamRoleInvalid)
+	}
+	if name == string(models.TeamMemberRoleOwner) {
+		return nil, ErrTeamRoleReserved
+	}
+	permissions := []string{}
+	for _, permission := range input.Permissions {
+		if !slices.Contains(models.TeamPermissions, permission) {
+			return nil, fmt.Errorf("%w: unknown permission %q", ErrTeamRoleInvalid, permission)
+		}
+		if !slices.Contains(permissions, permission) {
+			permissions = append(permissions, permission)
+		}
+	}
+	return &TeamRoleInput{Name: name, Description: input.Description, Permissions: permissions}, nil
+}
+
+// CreateRole implements TeamRoleService.
+func (s *DbTeamRoleService) CreateRole(ctx context.Context, teamID uuid.UUID, input *TeamRoleInput) (*models.TeamRole, error) {
+	input, err := normalizeTeamRole(input)
+	if err != nil {
+		return nil, err
+	}
+	role, err := s.adapter.TeamRole().CreateTeamRole(ctx, &models.TeamRole{
+		TeamID:      &teamID,
+		Name:        input.Name,
+		Description: input.Description,
+		Permissions: input.Permissions,
+	})
+	if err != nil {
+		if database.IsUniqConstraintErr(err) {
+			return nil, ErrTeamRoleTaken
+		}
+		return nil, err
+	}
+	return role, nil
+}
+
+// UpdateRole implements TeamRoleService.
+func (s *DbTeamRoleService) UpdateRole(ctx context.Context, teamID uuid.UUID, roleID uuid.UUID, input *TeamRoleInput) (*models.TeamRole, error) {
+	input, err := normalizeTeamRole(input)
+	if err != nil {
+		return nil, err
+	}
+	role, err := s.adapter.TeamRole().UpdateTeamRole(ctx, &models.TeamRole{
+		ID:          roleID,
+		TeamID:      &teamID,
+		Name:        input.Name,
+		Description: input.Description,
+		Permissions: input.Permissions,
+	})
+	if err != nil {
+		if database.IsUniqConstraintErr(err) {
+			return nil, ErrTeamRoleTaken
+		}
+		return nil, err
+	}
+	if role == nil {
+		return nil, ErrTeamRoleNotFound
+	}
+	return role, nil
+}
+
+// DeleteRole implements TeamRoleService.
+func (s *DbTeamRoleService) DeleteRole(ctx context.Context, teamID uuid.UUID, roleID uuid.UUID) error {
+	role, err := s.adapter.TeamRole().FindTeamRole(ctx, teamID, roleID)
+	if err != nil {
+		return err
+	}
+	if role == nil || role.TeamID == nil {
+		return ErrTeamRoleNotFound
+	}
+	return s.adapter.TeamRole().DeleteTeamRole(ctx, teamID, roleID)
+}
+
+// teamMember returns the member of the team with the id.
+func (s *DbTeamRoleService) teamMember(ctx context.Context, teamID uuid.UUID, memberID uuid.UUID) (*models.TeamMember, error) {
+	member, err := s.adapter.TeamMember().FindTeamMember(ctx, &stores.TeamMemberFilter{
+		Ids:     []uuid.UUID{memberID},
+		TeamIds: []uuid.UUID{teamID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error finding team member: %w", err)
+	}
+	if member == nil {
+		return nil, ErrTeamMemberNotFound
+	}
+	return member, nil
+}
+
+// Assignments implements TeamRoleService.
+func (s *DbTeamRoleService) Assignments(ctx context.Context, teamID uuid.UUID, memberID uuid.UUID) ([]*models.TeamRoleAssignment, error) {
+	if _, err := s.teamMember(ctx, teamID, memberID); err != nil {
+		return nil, err
+	}
+	return s.adapter.TeamRole().ListTeamRoleAssignments(ctx, memberID)
+}
+
+// AssignRole implements TeamRoleService.
+func (s *DbTeamRoleService) AssignRole(ctx context.Context, teamID uuid.UUID, memberID uuid.UUID, roleID uuid.UUID, projectID *uuid.UUID) (*models.TeamRoleAssignment, error) {
+	if _, err := s.teamMember(ctx, teamID, memberID); err != nil {
+		return nil, err
+	}
+	role, err := s.adapter.TeamRole().FindTeamRole(ctx, teamID, roleID)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, ErrTeamRoleNotFound
+	}
+	// default roles are found too, owners would get every permission
+	if role.Name == string(models.TeamMemberRoleOwner) {
+		return nil, ErrTeamRoleOwner
+	}
+	if projectID != nil {
+		project, err := s.adapter.Task().FindTaskProjectByID(ctx, *projectID)
+		if err != nil {
+			return nil, fmt.Errorf("error finding task project: %w", err)
+		}
+		if project == nil || project.TeamID != teamID {
+			return nil, ErrTaskProjectNotFound
+		}
+	}
+	assignment, err := s.adapter.TeamRole().CreateTeamRoleAssignment(ctx, &models.TeamRoleAssignment{
+		TeamMemberID:  memberID,
+		TeamRoleID:    roleID,
+		TaskProjectID: projectID,
+	})
+	if err != nil {
+		if database.IsUniqConstraintErr(err) {
+			return nil, ErrTeamRoleAssigned
+		}
+		return nil, err
+	}
+	return assignment, nil
+}
+
+// UnassignRole implements TeamRoleService.
+func (s *DbTeamRoleService) UnassignRole(ctx context.Context, teamID uuid.UUID, memberID uuid.UUID, assignmentID uuid.UUID) error {
+	assignments, err := s.Assignments(ctx, teamID, memberID)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(assignments, func(a *models.TeamRoleAssignment) bool { return a.ID == assignmentID }) {
+		return ErrTeamRoleAssignmentNotFound
+	}
+	return s.adapter.TeamRole().DeleteTeamRoleAssignment(ctx, memberID, assignmentID)
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/stores"
+)
+
+func TestTeamRoleServicePermissions(t *testing.T) {
+	ctx := context.Background()
+	adapter := stores.NewAdapterDecorators()
+	var gotProject *uuid.UUID
+	adapter.TeamRoleFunc.ListTeamMemberPermissionsFunc = func(ctx context.Context, member *models.TeamMember, projectID *uuid.UUID) ([]string, error) {
+		gotProject = projectID
+		return []string{models.TeamPermissionTaskCreate, models.TeamPermissionTaskUpdate}, nil
+	}
+	service := NewTeamRoleService(adapter)
+
+	owner := &models.TeamMember{ID: uuid.New(), Role: models.TeamMemberRoleOwner}
+	permissions, err := service.Permissions(ctx, owner, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, models.TeamPermissions, permissions, "owners have every permission")
+
+	member := &models.TeamMember{ID: uuid.New(), Role: models.TeamMemberRoleMember}
+	projectID := uuid.New()
+	ok, err := service.Can(ctx, member, &projectID, models.TeamPermissionTaskDelete, models.TeamPermissionTaskUpdate)
+	require.NoError(t, err)
+	assert.True(t, ok, "one of the permissions is enough")
+	assert.Equal(t, &projectID, gotProject)
+
+	ok, err = service.Can(ctx, member, nil, models.TeamPermissionRoleManage)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestNormalizeTeamRole(t *testing.T) {
+	input, err := normalizeTeamRole(&TeamRoleInput{
+		Name:        " Reviewer ",
+		Permissions: []string{models.TeamPermissionTaskUpdate, models.TeamPermissionTaskUpdate},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "reviewer", input.Name)
+	assert.Equal(t, []string{models.TeamPermissionTaskUpdate}, input.Permissions)
+
+	_, err = normalizeTeamRole(&TeamRoleInput{Name: "Owner"})
+	assert.ErrorIs(t, err, ErrTeamRoleReserved)
+	_, err = normalizeTeamRole(&TeamRoleInput{Name: "reviewer", Permissions: []string{"task:archive"}})
+	assert.ErrorIs(t, err, ErrTeamRoleInvalid)
+	_, err = normalizeTeamRole(&TeamRoleInput{Name: " "})
+	assert.ErrorIs(t, err, ErrTeamRoleInvalid)
+}
+
+func TestTeamRoleServiceAssignRole(t *testing.T) {
+	ctx := context.Background()
+	teamID := uuid.New()
+	member := &models.TeamMember{ID: uuid.New(), TeamID: teamID, Role: models.TeamMemberRoleMember}
+	role := &models.TeamRole{ID: uuid.New(), TeamID: &teamID, Name: "reviewer"}
+	// default roles have no team
+	ownerRole := &models.TeamRole{ID: uuid.New(), Name: string(models.TeamMemberRoleOwner)}
+	ownProject := &models.TaskProject{ID: uuid.New(), TeamID: teamID}
+	otherProject := &models.TaskProject{ID: uuid.New(), TeamID: uuid.New()}
+
+	adapter := stores.NewAdapterDecorators()
+	adapter.TeamMemberFunc.FindTeamMemberFunc = func(ctx context.Context, filter *stores.TeamMemberFilter) (*models.TeamMember, error) {
+		if filter.Ids[0] == member.ID && filter.TeamIds[0] == member.TeamID {
+			return member, nil
+		}
+		return nil, nil
+	}
+	adapter.TeamRoleFunc.FindTeamRoleFunc = func(ctx context.Context, teamID uuid.UUID, id uuid.UUID) (*models.TeamRole, error) {
+		if id == role.ID && teamID == *role.TeamID {
+			return role, nil
+		}
+		if id == ownerRole.ID {
+			return ownerRole, nil
+		}
+		return nil, nil
+	}
+	adapter.TaskFunc.FindTaskProjectByIDFunc = func(ctx context.Context, id uuid.UUID) (*models.TaskProject, error) {
+		for _, project := range []*models.TaskProject{ownProject, otherProject} {
+			if project.ID == id {
+				return project, nil
+			}
+		}
+		return nil, nil
+	}
+	adapter.TeamRoleFunc.CreateTeamRoleAssignmentFunc = func(ctx context.Context, assignment *models.TeamRoleAssignment) (*models.TeamRoleAssignment, error) {
+		created := *assignment
+		created.ID = uuid.New()
+		return &created, nil
+	}
+	service := NewTeamRoleService(adapter)
+
+	assignment, err := service.AssignRole(ctx, teamID, member.ID, role.ID, &ownProject.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &ownProject.ID, assignment.TaskProjectID)
+
+	_, err = service.AssignRole(ctx, teamID, member.ID, role.ID, &otherProject.ID)
+	assert.ErrorIs(t, err, ErrTaskProjectNotFound, "the project belongs to another team")
+	_, err = service.AssignRole(ctx, uuid.New(), member.ID, role.ID, nil)
+	assert.ErrorIs(t, err, ErrTeamMemberNotFound)
+	_, err = service.AssignRole(ctx, teamID, member.ID, uuid.New(), nil)
+	assert.ErrorIs(t, err, ErrTeamRoleNotFound)
+	_, err = service.AssignRole(ctx, teamID, member.ID, ownerRole.ID, nil)
+	assert.ErrorIs(t, err, ErrTeamRoleOwner)
+	_, err = service.AssignRole(ctx, teamID, member.ID, ownerRole.ID, &ownProject.ID)
+	assert.ErrorIs(t, err, ErrTeamRoleOwner)
+}
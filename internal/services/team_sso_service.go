@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/auth/oauth"
+	"github.com/tkahng/playground/internal/database"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/stores"
+	"github.com/tkahng/playground/internal/tools/security"
+)
+
+const (
+	// TeamSsoVerificationRecord is prefixed to a domain for the TXT record
+	// that proves a team owns it.
+	TeamSsoVerificationRecord = "_playground-sso."
+	// TeamSsoVerificationPrefix starts the value of the TXT record.
+	TeamSsoVerificationPrefix = "playground-sso-verification="
+)
+
+var (
+	ErrSsoDomainInvalid     = errors.New("invalid domain")
+	ErrSsoDomainNotFound    = errors.New("domain not found")
+	ErrSsoDomainNotVerified = errors.New("verification record not found")
+	ErrSsoDomainTaken       = errors.New("domain is verified by another team")
+)
+
+// SsoDiscoveryError is returned when the issuer of an IdP can not be
+// discovered. Its message is the same for every failure, Err is kept for
+// logs and is not shown to users.
+type SsoDiscoveryError struct {
+	Err error
+}
+
+func (e *SsoDiscoveryError) Error() string {
+	return "the issuer could not be discovered"
+}
+
+func (e *SsoDiscoveryError) Unwrap() error {
+	return e.Err
+}
+
+type TeamSsoConfigInput struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	DefaultRole  models.TeamMemberRole
+	Enforced     bool
+}
+
+// TeamSsoService manages the IdP of teams and the email domains they verified.
+type TeamSsoService interface {
+	Config(ctx context.Context, teamID uuid.UUID) (*models.TeamSsoConfig, error)
+	// SaveConfig checks the issuer is an https url of a public host that
+	// serves a discovery document, a blank client secret keeps the saved one.
+	SaveConfig(ctx context.Context, teamID uuid.UUID, input *TeamSsoConfigInput) (*models.TeamSsoConfig, error)
+	DeleteConfig(ctx context.Context, teamID uuid.UUID) error
+	Domains(ctx context.Context, teamID uuid.UUID) ([]*models.TeamSsoDomain, error)
+	AddDomain(ctx context.Context, teamID uuid.UUID, domain string) (*models.TeamSsoDomain, error)
+	// VerifyDomain looks the verification token of the domain up in its TXT
+	// records. A domain is verified by one team at most.
+	VerifyDomain(ctx context.Context, teamID uuid.UUID, domainID uuid.UUID) (*models.TeamSsoDomain, error)
+	RemoveDomain(ctx context.Context, teamID uuid.UUID, domainID uuid.UUID) error
+}
+
+type DbTeamSsoService struct {
+	adapter   stores.StorageAdapterInterface
+	lookupTXT func(ctx context.Context, name string) ([]string, error)
+	discover  func(ctx context.Context, issuer string) error
+}
+
+func NewTeamSsoService(adapter stores.StorageAdapterInterface) *DbTeamSsoService {
+	return &DbTeamSsoService{
+		adapter:   adapter,
+		lookupTXT: net.DefaultResolver.LookupTXT,
+		discover: func(ctx context.Context, issuer string) error {
+			ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			defer cancel()
+			_, err := oauth.Discover(ctx, oauth.NewPublicHTTPClient(10*time.Second), issuer)
+			return err
+		},
+	}
+}
+
+var _ TeamSsoService = (*DbTeamSsoService)(nil)
+
+// NormalizeSsoDomain lower cases a domain and checks it has a dot.
+func NormalizeSsoDomain(domain string) (string, error) {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	if len(domain) > 253 || !strings.Contains(domain, ".") || strings.ContainsAny(domain, "@/: ") {
+		return "", ErrSsoDomainInvalid
+	}
+	return domain, nil
+}
+
+// Config implements TeamSsoService.
+func (s *DbTeamSsoService) Config(ctx context.Context, teamID uuid.UUID) (*models.TeamSsoConfig, error) {
+	config, err := s.adapter.TeamSso().FindTeamSsoConfig(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, ErrSsoNotConfigured
+	}
+	return config, nil
+}
+
+// SaveConfig implements TeamSsoService.
+func (s *DbTeamSsoService) SaveConfig(ctx context.Context, teamID uuid.UUID, input *TeamSsoConfigInput) (*models.TeamSsoConfig, error) {
+	if err := oauth.ValidatePublicIssuer(input.Issuer); err != nil {
+		return nil, err
+	}
+	if err := s.discover(ctx, input.Issuer); err != nil {
+		return nil, &SsoDiscoveryError{Err: err}
+	}
+	secret := input.ClientSecret
+	if secret == "" {
+		existing, err := s.adapter.TeamSso().FindTeamSsoConfig(ctx, teamID)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			secret = existing.ClientSecret
+		}
+	}
+	scopes := input.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+	role := input.DefaultRole
+	if role == "" {
+		role = models.TeamMemberRoleMember
+	}
+	return s.adapter.TeamSso().SaveTeamSsoConfig(ctx, &models.TeamSsoConfig{
+		TeamID:       teamID,
+		Issuer:       input.Issuer,
+		ClientID:     input.ClientID,
+		ClientSecret: secret,
+		Scopes:       scopes,
+		DefaultRole:  role,
+		Enforced:     input.Enforced,
+	})
+}
+
+// DeleteConfig implements TeamSsoService.
+func (s *DbTeamSsoService) DeleteConfig(ctx context.Context, teamID uuid.UUID) error {
+	return s.adapter.TeamSso().DeleteTeamSsoConfig(ctx, teamID)
+}
+
+// Domains implements TeamSsoService.
+func (s *DbTeamSsoService) Domains(ctx context.Context, teamID uuid.UUID) ([]*models.TeamSsoDomain, error) {
+	return s.adapter.TeamSso().ListTeamSsoDomains(ctx, teamID)
+}
+
+// AddDomain implements TeamSsoService.
+func (s *DbTeamSsoService) AddDomain(ctx context.Context, teamID uuid.UUID, domain string) (*models.TeamSsoDomain, error) {
+	domain, err := NormalizeSsoDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+	return s.adapter.TeamSso().CreateTeamSsoDomain(ctx, &models.TeamSsoDomain{
+		TeamID:            teamID,
+		Domain:            domain,
+		VerificationToken: TeamSsoVerificationPrefix + security.GenerateTokenKey(),
+	})
+}
+
+// VerifyDomain implements TeamSsoService.
+func (s *DbTeamSsoService) VerifyDomain(ctx context.Context, teamID uuid.UUID, domainID uuid.UUID) (*models.TeamSsoDomain, error) {
+	domain, err := s.adapter.TeamSso().FindTeamSsoDomain(ctx, teamID, domainID)
+	if err != nil {
+		return nil, err
+	}
+	if domain == nil {
+		return nil, ErrSsoDomainNotFound
+	}
+	if domain.VerifiedAt != nil {
+		return domain, nil
+	}
+	verified, err := s.adapter.TeamSso().FindVerifiedTeamSsoDomain(ctx, domain.Domain)
+	if err != nil {
+		return nil, err
+	}
+	if verified != nil {
+		return nil, ErrSsoDomainTaken
+	}
+	records, err := s.lookupTXT(ctx, TeamSsoVerificationRecord+domain.Domain)
+	if err != nil || !slices.Contains(records, domain.VerificationToken) {
+		return nil, ErrSsoDomainNotVerified
+	}
+	domain, err = s.adapter.TeamSso().VerifyTeamSsoDomain(ctx, domain.ID)
+	if err != nil {
+		if database.IsUniqConstraintErr(err) {
+			return nil, ErrSsoDomainTaken
+		}
+		return nil, err
+	}
+	return domain, nil
+}
+
+// RemoveDomain implements TeamSsoService.
+func (s *DbTeamSsoService) RemoveDomain(ctx context.Context, teamID uuid.UUID, domainID uuid.UUID) error {
+	return s.adapter.TeamSso().DeleteTeamSsoDomain(ctx, teamID, domainID)
+}
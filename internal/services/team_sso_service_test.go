@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkahng/playground/internal/auth/oauth"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/stores"
+	"github.com/tkahng/playground/internal/workers"
+)
+
+// withTeamSso verifies acme.com for a team whose IdP enforces SSO.
+func withTeamSso(teamID uuid.UUID) testAuthOption {
+	return func(app *testAuthService) {
+		adapter := app.decorators
+		adapter.TeamSsoFunc.FindVerifiedTeamSsoDomainFunc = func(ctx context.Context, domain string) (*models.TeamSsoDomain, error) {
+			if domain != "acme.com" {
+				return nil, nil
+			}
+			now := time.Now()
+			return &models.TeamSsoDomain{ID: uuid.New(), TeamID: teamID, Domain: domain, VerifiedAt: &now}, nil
+		}
+		adapter.TeamSsoFunc.FindTeamSsoConfigFunc = func(ctx context.Context, id uuid.UUID) (*models.TeamSsoConfig, error) {
+			if id != teamID {
+				return nil, nil
+			}
+			return &models.TeamSsoConfig{
+				TeamID:      teamID,
+				Issuer:      "https://idp.acme.com",
+				ClientID:    "client",
+				DefaultRole: models.TeamMemberRoleGuest,
+				Enforced:    true,
+				UpdatedAt:   time.Now(),
+			}, nil
+		}
+	}
+}
+
+func TestCheckTeamSso(t *testing.T) {
+	ctx := context.Background()
+	teamID := uuid.New()
+	app := newTestAuthService(withTeamSso(teamID))
+
+	tests := []struct {
+		name     string
+		email    string
+		provider models.Providers
+		err      error
+		sso      bool
+	}{
+		{name: "other domains sign in with passwords", email: "bob@example.com", provider: models.ProvidersCredentials},
+		{name: "enforced domains can not sign in with passwords", email: "bob@ACME.com", provider: models.ProvidersCredentials, err: ErrSsoRequired},
+		{name: "enforced domains can not sign in with other providers", email: "bob@acme.com", provider: models.ProvidersGoogle, err: ErrSsoRequired},
+		{name: "enforced domains sign in with the IdP of the team", email: "bob@acme.com", provider: models.TeamSsoProvider(teamID), sso: true},
+		{name: "the IdP of a team can not sign in other domains", email: "bob@example.com", provider: models.TeamSsoProvider(teamID), err: ErrSsoEmailNotAllowed},
+		{name: "the IdP of another team can not sign in the domain", email: "bob@acme.com", provider: models.TeamSsoProvider(uuid.New()), err: ErrSsoEmailNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := app.checkTeamSso(ctx, &AuthenticationInput{Email: tt.email, Provider: tt.provider})
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.sso, config != nil)
+		})
+	}
+
+	_, err := app.Authenticate(ctx, &AuthenticationInput{Email: "bob@acme.com", Provider: models.ProvidersCredentials})
+	assert.ErrorIs(t, err, ErrSsoRequired)
+}
+
+func TestProvisionTeamSsoMember(t *testing.T) {
+	ctx := context.Background()
+	teamID := uuid.New()
+	app := newTestAuthService(withTeamSso(teamID))
+	adapter, jobService := app.decorators, app.jobs
+	user := &models.User{ID: uuid.New(), Email: "bob@acme.com"}
+	config, err := app.adapter.TeamSso().FindTeamSsoConfig(ctx, teamID)
+	require.NoError(t, err)
+
+	var created *models.TeamMember
+	adapter.TeamMemberFunc.FindTeamMemberFunc = func(ctx context.Context, filter *stores.TeamMemberFilter) (*models.TeamMember, error) {
+		return created, nil
+	}
+	adapter.TeamMemberFunc.CreateTeamMemberFunc = func(ctx context.Context, teamId uuid.UUID, userId uuid.UUID, role models.TeamMemberRole, hasBillingAccess bool) (*models.TeamMember, error) {
+		created = &models.TeamMember{ID: uuid.New(), TeamID: teamId, UserID: &userId, Role: role}
+		return created, nil
+	}
+	var jobs int
+	jobService.EnqueueRefreshSubscriptionQuantityJobFunc = func(ctx context.Context, job *workers.RefreshSubscriptionQuantityJobArgs) error {
+		jobs++
+		return nil
+	}
+	jobService.EnqueueTeamMemberAddedJobFunc = func(ctx context.Context, job *workers.NewMemberNotificationJobArgs) error {
+		jobs++
+		return nil
+	}
+
+	require.NoError(t, app.provisionTeamSsoMember(ctx, user, config))
+	require.NotNil(t, created)
+	assert.Equal(t, teamID, created.TeamID)
+	assert.Equal(t, models.TeamMemberRoleGuest, created.Role, "members get the default role of the IdP")
+	assert.Equal(t, 2, jobs)
+
+	// members are provisioned on their first sign in only
+	require.NoError(t, app.provisionTeamSsoMember(ctx, user, config))
+	assert.Equal(t, 2, jobs)
+}
+
+func TestTeamSsoProvider(t *testing.T) {
+	ctx := context.Background()
+	teamID := uuid.New()
+	app := newTestAuthService(withTeamSso(teamID))
+
+	provider, err := app.oauthProvider(ctx, models.TeamSsoProvider(teamID))
+	require.NoError(t, err)
+	oidc, ok := provider.(*oauth.OIDCConfig)
+	if assert.True(t, ok) {
+		assert.Equal(t, "https://idp.acme.com", oidc.Issuer())
+	}
+
+	_, err = app.oauthProvider(ctx, models.TeamSsoProvider(uuid.New()))
+	assert.ErrorIs(t, err, ErrSsoNotConfigured)
+}
+
+func TestNormalizeSsoDomain(t *testing.T) {
+	domain, err := NormalizeSsoDomain(" Acme.COM. ")
+	assert.NoError(t, err)
+	assert.Equal(t, "acme.com", domain)
+	for _, invalid := range []string{"", "localhost", "bob@acme.com", "https://acme.com"} {
+		_, err := NormalizeSsoDomain(invalid)
+		assert.ErrorIs(t, err, ErrSsoDomainInvalid, invalid)
+	}
+}
+
+func TestTeamSsoService_VerifyDomain(t *testing.T) {
+	ctx := context.Background()
+	teamID := uuid.New()
+	domain := &models.TeamSsoDomain{ID: uuid.New(), TeamID: teamID, Domain: "acme.com", VerificationToken: TeamSsoVerificationPrefix + "token"}
+	var verifiedBy *models.TeamSsoDomain
+	adapter := stores.NewAdapterDecorators()
+	adapter.TeamSsoFunc.FindTeamSsoDomainFunc = func(ctx context.Context, team uuid.UUID, id uuid.UUID) (*models.TeamSsoDomain, error) {
+		if team != teamID || id != domain.ID {
+			return nil, nil
+		}
+		return domain, nil
+	}
+	adapter.TeamSsoFunc.FindVerifiedTeamSsoDomainFunc = func(ctx context.Context, name string) (*models.TeamSsoDomain, error) {
+		return verifiedBy, nil
+	}
+	adapter.TeamSsoFunc.VerifyTeamSsoDomainFunc = func(ctx context.Context, id uuid.UUID) (*models.TeamSsoDomain, error) {
+		now := time.Now()
+		verified := *domain
+		verified.VerifiedAt = &now
+		return &verified, nil
+	}
+	var records []string
+	service := NewTeamSsoService(adapter)
+	service.lookupTXT = func(ctx context.Context, name string) ([]string, error) {
+		if name != TeamSsoVerificationRecord+"acme.com" {
+			return nil, errors.New("no such host")
+		}
+		return records, nil
+	}
+
+	_, err := service.VerifyDomain(ctx, uuid.New(), domain.ID)
+	assert.ErrorIs(t, err, ErrSsoDomainNotFound, "domains of other teams are not found")
+
+	records = []string{"v=spf1 -all", TeamSsoVerificationPrefix + "other"}
+	_, err = service.VerifyDomain(ctx, teamID, domain.ID)
+	assert.ErrorIs(t, err, ErrSsoDomainNotVerified)
+
+	records = append(records, domain.VerificationToken)
+	verifiedBy = &models.TeamSsoDomain{ID: uuid.New(), TeamID: uuid.New(), Domain: "acme.com"}
+	_, err = service.VerifyDomain(ctx, teamID, domain.ID)
+	assert.ErrorIs(t, err, ErrSsoDomainTaken)
+
+	verifiedBy = nil
+	verified, err := service.VerifyDomain(ctx, teamID, domain.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, verified.VerifiedAt)
+}
+
+func TestTeamSsoService_SaveConfigIssuers(t *testing.T) {
+	ctx := context.Background()
+	adapter := stores.NewAdapterDecorators()
+	adapter.TeamSsoFunc.FindTeamSsoConfigFunc = func(ctx context.Context, id uuid.UUID) (*models.TeamSsoConfig, error) {
+		return nil, nil
+	}
+	adapter.TeamSsoFunc.SaveTeamSsoConfigFunc = func(ctx context.Context, config *models.TeamSsoConfig) (*models.TeamSsoConfig, error) {
+		return config, nil
+	}
+	var discovered []string
+	service := NewTeamSsoService(adapter)
+	service.discover = func(ctx context.Context, issuer string) error {
+		discovered = append(discovered, issuer)
+		if issuer == "https://broken.example.com" {
+			return errors.New("failed to fetch: internal details")
+		}
+		return nil
+	}
+
+	for _, issuer := range []string{"http://idp.example.com", "https://127.0.0.1:8080", "https://169.254.169.254", "https://192.168.1.1"} {
+		_, err := service.SaveConfig(ctx, uuid.New(), &TeamSsoConfigInput{Issuer: issuer, ClientID: "client"})
+		assert.ErrorIs(t, err, oauth.ErrIssuerNotPublic, issuer)
+	}
+	assert.Empty(t, discovered, "refused issuers are not requested")
+
+	_, err := service.SaveConfig(ctx, uuid.New(), &TeamSsoConfigInput{Issuer: "https://broken.example.com", ClientID: "client"})
+	var discoveryErr *SsoDiscoveryError
+	require.ErrorAs(t, err, &discoveryErr)
+	assert.NotContains(t, err.Error(), "internal details", "responses of the issuer are not shown")
+
+	config, err := service.SaveConfig(ctx, uuid.New(), &TeamSsoConfigInput{Issuer: "https://idp.example.com", ClientID: "client"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://idp.example.com", config.Issuer)
+}
@@ -2,4 +2,8 @@ package shared
 
 const BearerAuthSecurityKey string = "bearer"
 
+// ImpersonatedByHeader flags the responses of impersonation tokens with the
+// id of the superuser acting as the user.
+const ImpersonatedByHeader string = "X-Impersonated-By"
+
 const MemberRoleExtensionKey string = "member_roles"
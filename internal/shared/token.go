@@ -19,6 +19,11 @@ type AuthenticationPayload struct {
 	Email       string    `json:"email"`
 	Roles       []string  `json:"roles"`
 	Permissions []string  `json:"permissions"`
+	SessionID   uuid.UUID `json:"sid,omitzero"`
+	// ImpersonatorId is the superuser acting as the user of UserId on
+	// impersonation tokens, SessionID is the session of the superuser.
+	ImpersonatorId  uuid.UUID `json:"impersonator_id,omitzero"`
+	ImpersonationId uuid.UUID `json:"impersonation_id,omitzero"`
 }
 
 // ----------- Refresh Token Claims -----------------
@@ -33,6 +38,8 @@ type RefreshTokenPayload struct {
 	UserId uuid.UUID `json:"user_id"`
 	Email  string    `json:"email"`
 	Token  string    `json:"token"`
+	// SessionID is the session the token refreshes.
+	SessionID uuid.UUID `json:"sid,omitzero"`
 }
 
 // ----------- Email Verification Claims -----------------
@@ -63,8 +70,18 @@ type ProviderStateClaims struct {
 	ProviderStatePayload
 }
 
+// StateIntent is what an oauth flow is for, the empty intent signs in.
+type StateIntent string
+
+const (
+	// StateIntentLink links the provider account to the user of UserId.
+	StateIntentLink StateIntent = "link"
+)
+
 type ProviderStatePayload struct {
-	// UserId              uuid.UUID        `json:"user_id,omitempty"`
+	Intent StateIntent `json:"intent,omitempty"`
+	// UserId is the signed in user of a link flow.
+	UserId uuid.UUID `json:"user_id,omitzero"`
 	// Email               string           `json:"email,omitempty"`
 	Token               string            `json:"token"`
 	Type                models.TokenTypes `json:"type"`
@@ -72,7 +89,10 @@ type ProviderStatePayload struct {
 	CodeVerifier        string            `json:"code_verifier,omitempty"`
 	CodeChallenge       string            `json:"code_challenge,omitempty"`
 	CodeChallengeMethod string            `json:"code_challenge_method,omitempty"`
-	RedirectTo          string            `json:"redirect_to,omitempty"`
+	// Nonce is sent with the auth request of OIDC providers, their id token
+	// must carry it.
+	Nonce      string `json:"nonce,omitempty"`
+	RedirectTo string `json:"redirect_to,omitempty"`
 }
 
 // ----------- Password Reset Claims -----------------
@@ -81,3 +101,23 @@ type PasswordResetClaims struct {
 	jwt.RegisteredClaims
 	OtpPayload
 }
+
+// ----------- Unsubscribe Claims -----------------
+
+type UnsubscribeClaims struct {
+	jwt.RegisteredClaims
+	Type models.TokenTypes `json:"type"`
+	// Version is the unsubscribe version of the user when the token was
+	// issued, tokens of older versions were revoked.
+	Version int64 `json:"version"`
+	UnsubscribePayload
+}
+
+// UnsubscribePayload is what an unsubscribe link turns off. A notification
+// type stops its emails, Digest stops the daily digest of the team.
+type UnsubscribePayload struct {
+	UserId           uuid.UUID  `json:"user_id"`
+	TeamId           *uuid.UUID `json:"team_id,omitempty"`
+	NotificationType string     `json:"notification_type,omitempty"`
+	Digest           bool       `json:"digest,omitempty"`
+}
@@ -30,6 +30,9 @@ type DbAccountStoreInterface interface {
 	CreateUserAccount(ctx context.Context, account *models.UserAccount) (*models.UserAccount, error)
 	GetUserAccounts(ctx context.Context, userIds ...uuid.UUID) ([][]*models.UserAccount, error)
 	ListUserAccounts(ctx context.Context, input *UserAccountFilter) ([]*models.UserAccount, error)
+	// LockUserAccounts returns the accounts of the user and locks them until
+	// the transaction of the store ends.
+	LockUserAccounts(ctx context.Context, userId uuid.UUID) ([]*models.UserAccount, error)
 	UnlinkAccount(ctx context.Context, userId uuid.UUID, provider models.Providers) error
 	UpdateUserAccount(ctx context.Context, account *models.UserAccount) error
 	UpdateUserPassword(ctx context.Context, userId uuid.UUID, password string) error
@@ -149,6 +152,24 @@ func (u *DbAccountStore) CreateUserAccount(ctx context.Context, account *models.
 	return createdAccount, nil
 }
 
+// LockUserAccounts implements UserAccountStore.
+func (u *DbAccountStore) LockUserAccounts(ctx context.Context, userId uuid.UUID) ([]*models.UserAccount, error) {
+	res, err := database.QueryAll[*models.UserAccount](
+		ctx,
+		u.db,
+		`SELECT id, user_id, type, provider, provider_account_id, password, refresh_token, access_token, expires_at, id_token, scope, session_state, token_type, created_at, updated_at
+		FROM user_accounts
+		WHERE user_id = $1
+		ORDER BY id
+		FOR UPDATE`,
+		userId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error locking user accounts: %w", err)
+	}
+	return res, nil
+}
+
 // UnlinkAccount implements UserAccountStore.
 func (u *DbAccountStore) UnlinkAccount(ctx context.Context, userId uuid.UUID, provider models.Providers) error {
 	_, err := repository.UserAccount.Delete(
@@ -15,6 +15,7 @@ type AccountStoreDecorator struct {
 	FindUserAccountByUserIdAndProviderFunc func(ctx context.Context, userId uuid.UUID, provider models.Providers) (*models.UserAccount, error)
 	GetUserAccountsFunc                    func(ctx context.Context, userIds ...uuid.UUID) ([][]*models.UserAccount, error)
 	ListUserAccountsFunc                   func(ctx context.Context, input *UserAccountFilter) ([]*models.UserAccount, error)
+	LockUserAccountsFunc                   func(ctx context.Context, userId uuid.UUID) ([]*models.UserAccount, error)
 	UnlinkAccountFunc                      func(ctx context.Context, userId uuid.UUID, provider models.Providers) error
 	UpdateUserAccountFunc                  func(ctx context.Context, account *models.UserAccount) error
 	UpdateUserPasswordFunc                 func(ctx context.Context, userId uuid.UUID, password string) error
@@ -48,6 +49,7 @@ func (a *AccountStoreDecorator) Cleanup() {
 	a.FindUserAccountByUserIdAndProviderFunc = nil
 	a.GetUserAccountsFunc = nil
 	a.ListUserAccountsFunc = nil
+	a.LockUserAccountsFunc = nil
 	a.UnlinkAccountFunc = nil
 	a.UpdateUserAccountFunc = nil
 	a.UpdateUserPasswordFunc = nil
@@ -108,6 +110,17 @@ func (a *AccountStoreDecorator) ListUserAccounts(ctx context.Context, input *Use
 	return a.Delegate.ListUserAccounts(ctx, input)
 }
 
+// LockUserAccounts implements DbAccountStoreInterface.
+func (a *AccountStoreDecorator) LockUserAccounts(ctx context.Context, userId uuid.UUID) ([]*models.UserAccount, error) {
+	if a.LockUserAccountsFunc != nil {
+		return a.LockUserAccountsFunc(ctx, userId)
+	}
+	if a.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return a.Delegate.LockUserAccounts(ctx, userId)
+}
+
 // UnlinkAccount implements DbAccountStoreInterface.
 func (a *AccountStoreDecorator) UnlinkAccount(ctx context.Context, userId uuid.UUID, provider models.Providers) error {
 	if a.UnlinkAccountFunc != nil {
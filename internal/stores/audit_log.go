@@ -0,0 +1,74 @@
+package stores
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/database"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/tools/types"
+)
+
+type AuditLogStore interface {
+	CreateAuditLog(ctx context.Context, log *models.AuditLog) (*models.AuditLog, error)
+	// UpdateAuditLogAttributes merges attributes into those of the audit log.
+	UpdateAuditLogAttributes(ctx context.Context, id uuid.UUID, attributes types.JSONMap[any]) error
+	// ListImpersonationAuditLogs returns the audit logs of an impersonation,
+	// oldest first.
+	ListImpersonationAuditLogs(ctx context.Context, impersonationID uuid.UUID) ([]*models.AuditLog, error)
+}
+
+type DbAuditLogStore struct {
+	db database.Dbx
+}
+
+var _ AuditLogStore = (*DbAuditLogStore)(nil)
+
+func NewDbAuditLogStore(db database.Dbx) *DbAuditLogStore {
+	return &DbAuditLogStore{
+		db: db,
+	}
+}
+
+const auditLogColumns = `id, ip, email, audit_log, attributes, creation_date, modification_date`
+
+// CreateAuditLog implements AuditLogStore.
+func (s *DbAuditLogStore) CreateAuditLog(ctx context.Context, log *models.AuditLog) (*models.AuditLog, error) {
+	return database.One[*models.AuditLog](
+		ctx,
+		s.db,
+		`INSERT INTO audit_logs (ip, email, audit_log, attributes)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+auditLogColumns,
+		log.IP,
+		log.Email,
+		log.AuditLog,
+		log.Attributes,
+	)
+}
+
+// UpdateAuditLogAttributes implements AuditLogStore.
+func (s *DbAuditLogStore) UpdateAuditLogAttributes(ctx context.Context, id uuid.UUID, attributes types.JSONMap[any]) error {
+	_, err := database.Exec(
+		ctx,
+		s.db,
+		`UPDATE audit_logs
+		SET attributes = attributes || $2, modification_date = now()
+		WHERE id = $1`,
+		id,
+		attributes,
+	)
+	return err
+}
+
+// ListImpersonationAuditLogs implements AuditLogStore.
+func (s *DbAuditLogStore) ListImpersonationAuditLogs(ctx context.Context, impersonationID uuid.UUID) ([]*models.AuditLog, error) {
+	return database.QueryAll[*models.AuditLog](
+		ctx,
+		s.db,
+		`SELECT `+auditLogColumns+` FROM audit_logs
+		WHERE attributes ->> 'impersonation_id' = $1
+		ORDER BY creation_date`,
+		impersonationID.String(),
+	)
+}
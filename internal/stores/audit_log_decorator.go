@@ -0,0 +1,51 @@
+package stores
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/tools/types"
+)
+
+type AuditLogStoreDecorator struct {
+	Delegate                       *DbAuditLogStore
+	CreateAuditLogFunc             func(ctx context.Context, log *models.AuditLog) (*models.AuditLog, error)
+	UpdateAuditLogAttributesFunc   func(ctx context.Context, id uuid.UUID, attributes types.JSONMap[any]) error
+	ListImpersonationAuditLogsFunc func(ctx context.Context, impersonationID uuid.UUID) ([]*models.AuditLog, error)
+}
+
+var _ AuditLogStore = (*AuditLogStoreDecorator)(nil)
+
+// CreateAuditLog implements AuditLogStore.
+func (s *AuditLogStoreDecorator) CreateAuditLog(ctx context.Context, log *models.AuditLog) (*models.AuditLog, error) {
+	if s.CreateAuditLogFunc != nil {
+		return s.CreateAuditLogFunc(ctx, log)
+	}
+	if s.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return s.Delegate.CreateAuditLog(ctx, log)
+}
+
+// UpdateAuditLogAttributes implements AuditLogStore.
+func (s *AuditLogStoreDecorator) UpdateAuditLogAttributes(ctx context.Context, id uuid.UUID, attributes types.JSONMap[any]) error {
+	if s.UpdateAuditLogAttributesFunc != nil {
+		return s.UpdateAuditLogAttributesFunc(ctx, id, attributes)
+	}
+	if s.Delegate == nil {
+		return ErrDelegateNil
+	}
+	return s.Delegate.UpdateAuditLogAttributes(ctx, id, attributes)
+}
+
+// ListImpersonationAuditLogs implements AuditLogStore.
+func (s *AuditLogStoreDecorator) ListImpersonationAuditLogs(ctx context.Context, impersonationID uuid.UUID) ([]*models.AuditLog, error) {
+	if s.ListImpersonationAuditLogsFunc != nil {
+		return s.ListImpersonationAuditLogsFunc(ctx, impersonationID)
+	}
+	if s.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return s.Delegate.ListImpersonationAuditLogs(ctx, impersonationID)
+}
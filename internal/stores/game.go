@@ -0,0 +1,117 @@
+package stores
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/database"
+	"github.com/tkahng/playground/internal/models"
+)
+
+type GameStore interface {
+	FindGameRatings(ctx context.Context, game string, userIDs []uuid.UUID) ([]*models.GameRating, error)
+	// LockGameRatings creates the missing ratings of the users with the
+	// default rating and locks all of them until the transaction ends.
+	LockGameRatings(ctx context.Context, game string, userIDs []uuid.UUID) ([]*models.GameRating, error)
+	UpdateGameRating(ctx context.Context, rating *models.GameRating) error
+	ListTopGameRatings(ctx context.Context, game string, limit int) ([]*models.GameRating, error)
+	CreateGameResult(ctx context.Context, result *models.GameResult) (*models.GameResult, error)
+}
+
+type DbGameStore struct {
+	db database.Dbx
+}
+
+var _ GameStore = (*DbGameStore)(nil)
+
+func NewDbGameStore(db database.Dbx) *DbGameStore {
+	return &DbGameStore{
+		db: db,
+	}
+}
+
+const FindGameRatingsQuery = `
+SELECT user_id, game, rating, wins, losses, created_at, updated_at
+FROM game_ratings
+WHERE game = $1 AND user_id = ANY($2)
+`
+
+// FindGameRatings implements GameStore.
+func (s *DbGameStore) FindGameRatings(ctx context.Context, game string, userIDs []uuid.UUID) ([]*models.GameRating, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	return database.QueryAll[*models.GameRating](ctx, s.db, FindGameRatingsQuery, game, userIDs)
+}
+
+// LockGameRatings implements GameStore.
+func (s *DbGameStore) LockGameRatings(ctx context.Context, game string, userIDs []uuid.UUID) ([]*models.GameRating, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	_, err := database.Exec(
+		ctx,
+		s.db,
+		`INSERT INTO game_ratings (user_id, game)
+		SELECT id, $1 FROM unnest($2::uuid[]) AS id
+		ON CONFLICT (user_id, game) DO NOTHING`,
+		game,
+		userIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	// lock in a fixed order so two games of the same users do not deadlock
+	return database.QueryAll[*models.GameRating](ctx, s.db, FindGameRatingsQuery+"ORDER BY user_id FOR UPDATE", game, userIDs)
+}
+
+// UpdateGameRating implements GameStore.
+func (s *DbGameStore) UpdateGameRating(ctx context.Context, rating *models.GameRating) error {
+	_, err := database.Exec(
+		ctx,
+		s.db,
+		`UPDATE game_ratings SET rating = $3, wins = $4, losses = $5
+		WHERE user_id = $1 AND game = $2`,
+		rating.UserID,
+		rating.Game,
+		rating.Rating,
+		rating.Wins,
+		rating.Losses,
+	)
+	return err
+}
+
+// ListTopGameRatings implements GameStore.
+func (s *DbGameStore) ListTopGameRatings(ctx context.Context, game string, limit int) ([]*models.GameRating, error) {
+	return database.QueryAll[*models.GameRating](
+		ctx,
+		s.db,
+		`SELECT user_id, game, rating, wins, losses, created_at, updated_at
+		FROM game_ratings
+		WHERE game = $1
+		ORDER BY rating DESC, wins DESC
+		LIMIT $2`,
+		game,
+		limit,
+	)
+}
+
+// CreateGameResult implements GameStore.
+func (s *DbGameStore) CreateGameResult(ctx context.Context, result *models.GameResult) (*models.GameResult, error) {
+	return database.One[*models.GameResult](
+		ctx,
+		s.db,
+		`INSERT INTO game_results (game, game_id, winner_id, loser_id, winner_rating, loser_rating, rating_change, moves, started_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, game, game_id, winner_id, loser_id, winner_rating, loser_rating, rating_change, moves, started_at, finished_at`,
+		result.Game,
+		result.GameID,
+		result.WinnerID,
+		result.LoserID,
+		result.WinnerRating,
+		result.LoserRating,
+		result.RatingChange,
+		result.Moves,
+		result.StartedAt,
+	)
+}
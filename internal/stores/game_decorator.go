@@ -0,0 +1,74 @@
+package stores
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/models"
+)
+
+type GameStoreDecorator struct {
+	Delegate               *DbGameStore
+	FindGameRatingsFunc    func(ctx context.Context, game string, userIDs []uuid.UUID) ([]*models.GameRating, error)
+	LockGameRatingsFunc    func(ctx context.Context, game string, userIDs []uuid.UUID) ([]*models.GameRating, error)
+	UpdateGameRatingFunc   func(ctx context.Context, rating *models.GameRating) error
+	ListTopGameRatingsFunc func(ctx context.Context, game string, limit int) ([]*models.GameRating, error)
+	CreateGameResultFunc   func(ctx context.Context, result *models.GameResult) (*models.GameResult, error)
+}
+
+var _ GameStore = (*GameStoreDecorator)(nil)
+
+// FindGameRatings implements GameStore.
+func (g *GameStoreDecorator) FindGameRatings(ctx context.Context, game string, userIDs []uuid.UUID) ([]*models.GameRating, error) {
+	if g.FindGameRatingsFunc != nil {
+		return g.FindGameRatingsFunc(ctx, game, userIDs)
+	}
+	if g.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return g.Delegate.FindGameRatings(ctx, game, userIDs)
+}
+
+// LockGameRatings implements GameStore.
+func (g *GameStoreDecorator) LockGameRatings(ctx context.Context, game string, userIDs []uuid.UUID) ([]*models.GameRating, error) {
+	if g.LockGameRatingsFunc != nil {
+		return g.LockGameRatingsFunc(ctx, game, userIDs)
+	}
+	if g.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return g.Delegate.LockGameRatings(ctx, game, userIDs)
+}
+
+// UpdateGameRating implements GameStore.
+func (g *GameStoreDecorator) UpdateGameRating(ctx context.Context, rating *models.GameRating) error {
+	if g.UpdateGameRatingFunc != nil {
+		return g.UpdateGameRatingFunc(ctx, rating)
+	}
+	if g.Delegate == nil {
+		return ErrDelegateNil
+	}
+	return g.Delegate.UpdateGameRating(ctx, rating)
+}
+
+// ListTopGameRatings implements GameStore.
+func (g *GameStoreDecorator) ListTopGameRatings(ctx context.Context, game string, limit int) ([]*models.GameRating, error) {
+	if g.ListTopGameRatingsFunc != nil {
+		return g.ListTopGameRatingsFunc(ctx, game, limit)
+	}
+	if g.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return g.Delegate.ListTopGameRatings(ctx, game, limit)
+}
+
+// CreateGameResult implements GameStore.
+func (g *GameStoreDecorator) CreateGameResult(ctx context.Context, result *models.GameResult) (*models.GameResult, error) {
+	if g.CreateGameResultFunc != nil {
+		return g.CreateGameResultFunc(ctx, result)
+	}
+	if g.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return g.Delegate.CreateGameResult(ctx, result)
+}
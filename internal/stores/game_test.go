@@ -0,0 +1,61 @@
+package stores_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkahng/playground/internal/database"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/stores"
+	"github.com/tkahng/playground/internal/test"
+)
+
+func TestGameStore_Ratings(t *testing.T) {
+	test.DbSetup()
+	test.WithTx(t, func(ctx context.Context, db database.Dbx) {
+		adapter := stores.NewStorageAdapter(db)
+		store := stores.NewDbGameStore(db)
+		winner := CreateUser(adapter, ctx, "winner@example.com")
+		loser := CreateUser(adapter, ctx, "loser@example.com")
+
+		ratings, err := store.LockGameRatings(ctx, models.GameSticks, []uuid.UUID{winner.ID, loser.ID})
+		require.NoError(t, err)
+		require.Len(t, ratings, 2)
+		for _, rating := range ratings {
+			assert.Equal(t, 1200, rating.Rating)
+			rating.Rating += 10
+			rating.Wins++
+			require.NoError(t, store.UpdateGameRating(ctx, rating))
+		}
+
+		// locking again keeps the existing ratings
+		ratings, err = store.LockGameRatings(ctx, models.GameSticks, []uuid.UUID{winner.ID})
+		require.NoError(t, err)
+		require.Len(t, ratings, 1)
+		assert.Equal(t, 1210, ratings[0].Rating)
+		assert.Equal(t, 1, ratings[0].Wins)
+
+		top, err := store.ListTopGameRatings(ctx, models.GameSticks, 10)
+		require.NoError(t, err)
+		assert.Len(t, top, 2)
+
+		result, err := store.CreateGameResult(ctx, &models.GameResult{
+			Game:         models.GameSticks,
+			GameID:       "game",
+			WinnerID:     winner.ID,
+			LoserID:      loser.ID,
+			WinnerRating: 1226,
+			LoserRating:  1194,
+			RatingChange: 16,
+			Moves:        9,
+			StartedAt:    time.Now(),
+		})
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		assert.Equal(t, 16, result.RatingChange)
+	})
+}
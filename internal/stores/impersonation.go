@@ -0,0 +1,90 @@
+package stores
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/database"
+	"github.com/tkahng/playground/internal/models"
+)
+
+type ImpersonationStore interface {
+	CreateImpersonation(ctx context.Context, impersonation *models.Impersonation) (*models.Impersonation, error)
+	// FindImpersonation returns nil when the impersonation ended or expired.
+	FindImpersonation(ctx context.Context, id uuid.UUID) (*models.Impersonation, error)
+	ListUserImpersonations(ctx context.Context, userID uuid.UUID) ([]*models.Impersonation, error)
+	// EndImpersonation returns nil when the impersonation already ended.
+	EndImpersonation(ctx context.Context, id uuid.UUID) (*models.Impersonation, error)
+}
+
+type DbImpersonationStore struct {
+	db database.Dbx
+}
+
+var _ ImpersonationStore = (*DbImpersonationStore)(nil)
+
+func NewDbImpersonationStore(db database.Dbx) *DbImpersonationStore {
+	return &DbImpersonationStore{
+		db: db,
+	}
+}
+
+// impersonationSelect reads impersonations from i with the email of the
+// superuser.
+const impersonationSelect = `SELECT i.id, i.impersonator_id, u.email AS impersonator_email, i.user_id, i.session_id, i.expires, i.ended_at, i.created_at
+	FROM i JOIN users u ON u.id = i.impersonator_id`
+
+// CreateImpersonation implements ImpersonationStore.
+func (s *DbImpersonationStore) CreateImpersonation(ctx context.Context, impersonation *models.Impersonation) (*models.Impersonation, error) {
+	return database.One[*models.Impersonation](
+		ctx,
+		s.db,
+		`WITH i AS (
+			INSERT INTO impersonations (impersonator_id, user_id, session_id, expires)
+			VALUES ($1, $2, $3, $4)
+			RETURNING *
+		) `+impersonationSelect,
+		impersonation.ImpersonatorID,
+		impersonation.UserID,
+		impersonation.SessionID,
+		impersonation.Expires,
+	)
+}
+
+// FindImpersonation implements ImpersonationStore.
+func (s *DbImpersonationStore) FindImpersonation(ctx context.Context, id uuid.UUID) (*models.Impersonation, error) {
+	return database.One[*models.Impersonation](
+		ctx,
+		s.db,
+		`WITH i AS (
+			SELECT * FROM impersonations WHERE id = $1 AND ended_at IS NULL AND expires > now()
+		) `+impersonationSelect,
+		id,
+	)
+}
+
+// ListUserImpersonations implements ImpersonationStore.
+func (s *DbImpersonationStore) ListUserImpersonations(ctx context.Context, userID uuid.UUID) ([]*models.Impersonation, error) {
+	return database.QueryAll[*models.Impersonation](
+		ctx,
+		s.db,
+		`WITH i AS (
+			SELECT * FROM impersonations WHERE user_id = $1
+		) `+impersonationSelect+` ORDER BY i.created_at DESC`,
+		userID,
+	)
+}
+
+// EndImpersonation implements ImpersonationStore.
+func (s *DbImpersonationStore) EndImpersonation(ctx context.Context, id uuid.UUID) (*models.Impersonation, error) {
+	return database.One[*models.Impersonation](
+		ctx,
+		s.db,
+		`WITH i AS (
+			UPDATE impersonations SET ended_at = now()
+			WHERE id = $1 AND ended_at IS NULL
+			RETURNING *
+		) `+impersonationSelect,
+		id,
+	)
+}
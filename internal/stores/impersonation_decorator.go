@@ -0,0 +1,62 @@
+package stores
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/models"
+)
+
+type ImpersonationStoreDecorator struct {
+	Delegate                   *DbImpersonationStore
+	CreateImpersonationFunc    func(ctx context.Context, impersonation *models.Impersonation) (*models.Impersonation, error)
+	FindImpersonationFunc      func(ctx context.Context, id uuid.UUID) (*models.Impersonation, error)
+	ListUserImpersonationsFunc func(ctx context.Context, userID uuid.UUID) ([]*models.Impersonation, error)
+	EndImpersonationFunc       func(ctx context.Context, id uuid.UUID) (*models.Impersonation, error)
+}
+
+var _ ImpersonationStore = (*ImpersonationStoreDecorator)(nil)
+
+// CreateImpersonation implements ImpersonationStore.
+func (s *ImpersonationStoreDecorator) CreateImpersonation(ctx context.Context, impersonation *models.Impersonation) (*models.Impersonation, error) {
+	if s.CreateImpersonationFunc != nil {
+		return s.CreateImpersonationFunc(ctx, impersonation)
+	}
+	if s.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return s.Delegate.CreateImpersonation(ctx, impersonation)
+}
+
+// FindImpersonation implements ImpersonationStore.
+func (s *ImpersonationStoreDecorator) FindImpersonation(ctx context.Context, id uuid.UUID) (*models.Impersonation, error) {
+	if s.FindImpersonationFunc != nil {
+		return s.FindImpersonationFunc(ctx, id)
+	}
+	if s.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return s.Delegate.FindImpersonation(ctx, id)
+}
+
+// ListUserImpersonations implements ImpersonationStore.
+func (s *ImpersonationStoreDecorator) ListUserImpersonations(ctx context.Context, userID uuid.UUID) ([]*models.Impersonation, error) {
+	if s.ListUserImpersonationsFunc != nil {
+		return s.ListUserImpersonationsFunc(ctx, userID)
+	}
+	if s.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return s.Delegate.ListUserImpersonations(ctx, userID)
+}
+
+// EndImpersonation implements ImpersonationStore.
+func (s *ImpersonationStoreDecorator) EndImpersonation(ctx context.Context, id uuid.UUID) (*models.Impersonation, error) {
+	if s.EndImpersonationFunc != nil {
+		return s.EndImpersonationFunc(ctx, id)
+	}
+	if s.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return s.Delegate.EndImpersonation(ctx, id)
+}
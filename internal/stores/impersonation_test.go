@@ -0,0 +1,68 @@
+package stores_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkahng/playground/internal/database"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/stores"
+	"github.com/tkahng/playground/internal/test"
+	"github.com/tkahng/playground/internal/tools/types"
+)
+
+func TestImpersonationStore(t *testing.T) {
+	test.Parallel(t)
+	test.SkipIfShort(t)
+	test.WithTx(t, func(ctx context.Context, db database.Dbx) {
+		adapter := stores.NewStorageAdapter(db)
+		store := stores.NewDbImpersonationStore(db)
+		admin := CreateUser(adapter, ctx, "impersonator@example.com")
+		user := CreateUser(adapter, ctx, "impersonated@example.com")
+
+		impersonation, err := store.CreateImpersonation(ctx, &models.Impersonation{
+			ImpersonatorID: admin.ID,
+			UserID:         user.ID,
+			Expires:        time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+		require.NotNil(t, impersonation)
+		assert.Equal(t, admin.Email, impersonation.ImpersonatorEmail)
+
+		found, err := store.FindImpersonation(ctx, impersonation.ID)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+
+		logs := stores.NewDbAuditLogStore(db)
+		_, err = logs.CreateAuditLog(ctx, &models.AuditLog{
+			Email:      types.Pointer(admin.Email),
+			AuditLog:   models.AuditLogImpersonationRequest,
+			Attributes: types.JSONMap[any]{"impersonation_id": impersonation.ID.String(), "path": "/api/auth/me"},
+		})
+		require.NoError(t, err)
+		_, err = logs.CreateAuditLog(ctx, &models.AuditLog{AuditLog: "other"})
+		require.NoError(t, err)
+		listed, err := logs.ListImpersonationAuditLogs(ctx, impersonation.ID)
+		require.NoError(t, err)
+		require.Len(t, listed, 1)
+		assert.Equal(t, "/api/auth/me", listed[0].Attributes["path"])
+
+		ended, err := store.EndImpersonation(ctx, impersonation.ID)
+		require.NoError(t, err)
+		require.NotNil(t, ended)
+		assert.NotNil(t, ended.EndedAt)
+		ended, err = store.EndImpersonation(ctx, impersonation.ID)
+		require.NoError(t, err)
+		assert.Nil(t, ended, "ending twice does nothing")
+		found, err = store.FindImpersonation(ctx, impersonation.ID)
+		require.NoError(t, err)
+		assert.Nil(t, found, "ended impersonations are not found")
+
+		impersonations, err := store.ListUserImpersonations(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Len(t, impersonations, 1)
+	})
+}
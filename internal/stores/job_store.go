@@ -25,6 +25,7 @@ type JobFilter struct {
 	RunAfter   types.OptionalParam[time.Time] `db:"run_after" json:"run_after" query:"run_after" required:"false"`
 	Attempt    types.OptionalParam[int64]     `db:"attempt" json:"attempt" query:"attempt" required:"false"`
 	LastErrors []string                       `db:"last_errors" json:"last_errors" query:"last_errors" required:"false" minimum:"1" maximum:"100" uniqueItems:"true"`
+	GroupIds   []uuid.UUID                    `db:"group_ids" json:"group_ids" query:"group_ids" required:"false" minimum:"1" maximum:"100" format:"uuid"`
 }
 
 type JobStore interface {
@@ -34,6 +35,8 @@ type JobStore interface {
 	CreateJob(ctx context.Context, job *models.JobRow) (*models.JobRow, error)
 	UpdateJob(ctx context.Context, job *models.JobRow) (*models.JobRow, error)
 	DeleteJob(ctx context.Context, filter *JobFilter) (int64, error)
+	GetJobStats(ctx context.Context, since time.Time, windows ...time.Duration) (*models.JobStats, error)
+	GetJobGroup(ctx context.Context, groupID uuid.UUID) (*models.JobGroup, error)
 }
 
 type DbJobStore struct {
@@ -88,6 +91,11 @@ func (d *DbJobStore) filter(filter *JobFilter) *map[string]any {
 			"_in": filter.LastErrors,
 		}
 	}
+	if len(filter.GroupIds) > 0 {
+		where["group_id"] = map[string]any{
+			"_in": filter.GroupIds,
+		}
+	}
 	return &where
 }
 
@@ -144,6 +152,137 @@ func (d *DbJobStore) UpdateJob(ctx context.Context, job *models.JobRow) (*models
 	return repository.Job.PutOne(ctx, d.db, job)
 }
 
+const JobKindStatsQuery = `
+SELECT kind,
+    COUNT(*) FILTER (
+        WHERE status = 'pending'
+    ) AS pending,
+    COUNT(*) FILTER (
+        WHERE status = 'processing'
+    ) AS processing,
+    COUNT(*) FILTER (
+        WHERE status = 'done'
+    ) AS done,
+    COUNT(*) FILTER (
+        WHERE status = 'failed'
+    ) AS failed,
+    COUNT(*) FILTER (
+        WHERE status = 'cancelled'
+    ) AS cancelled,
+    percentile_cont(0.5) WITHIN GROUP (
+        ORDER BY EXTRACT(EPOCH FROM finished_at - started_at) * 1000
+    ) FILTER (
+        WHERE status IN ('done', 'failed')
+            AND finished_at >= $1
+    ) AS p50_duration_ms,
+    percentile_cont(0.95) WITHIN GROUP (
+        ORDER BY EXTRACT(EPOCH FROM finished_at - started_at) * 1000
+    ) FILTER (
+        WHERE status IN ('done', 'failed')
+            AND finished_at >= $1
+    ) AS p95_duration_ms
+FROM jobs
+GROUP BY kind
+ORDER BY kind;
+`
+
+const JobFailureRatesQuery = `
+SELECT w.window_seconds,
+    COUNT(j.id) AS finished,
+    COUNT(j.id) FILTER (
+        WHERE j.status = 'failed'
+    ) AS failed,
+    COALESCE(
+        COUNT(j.id) FILTER (
+            WHERE j.status = 'failed'
+        )::float8 / NULLIF(COUNT(j.id), 0),
+        0
+    ) AS rate
+FROM unnest($1::bigint []) AS w(window_seconds)
+    LEFT JOIN jobs j ON j.status IN ('done', 'failed')
+    AND j.finished_at >= clock_timestamp() - make_interval(secs => w.window_seconds)
+GROUP BY w.window_seconds
+ORDER BY w.window_seconds;
+`
+
+const JobOldestPendingQuery = `
+SELECT MIN(run_after) AS oldest_pending
+FROM jobs
+WHERE status = 'pending'
+    AND run_after <= clock_timestamp();
+`
+
+// GetJobStats implements JobStore. Durations are computed from jobs finished
+// since the given time, failure rates for each of the given windows.
+func (d *DbJobStore) GetJobStats(ctx context.Context, since time.Time, windows ...time.Duration) (*models.JobStats, error) {
+	kinds, err := database.QueryAll[models.JobKindStats](ctx, d.db, JobKindStatsQuery, since)
+	if err != nil {
+		return nil, err
+	}
+	seconds := make([]int64, 0, len(windows))
+	for _, w := range windows {
+		seconds = append(seconds, int64(w.Seconds()))
+	}
+	rates, err := database.QueryAll[models.JobFailureRate](ctx, d.db, JobFailureRatesQuery, seconds)
+	if err != nil {
+		return nil, err
+	}
+	oldest, err := database.QueryAll[struct {
+		OldestPending *time.Time `db:"oldest_pending"`
+	}](ctx, d.db, JobOldestPendingQuery)
+	if err != nil {
+		return nil, err
+	}
+	stats := &models.JobStats{
+		Kinds:        kinds,
+		FailureRates: rates,
+		GeneratedAt:  time.Now(),
+	}
+	if len(oldest) > 0 && oldest[0].OldestPending != nil {
+		stats.OldestPending = oldest[0].OldestPending
+		stats.QueueLagSeconds = max(stats.GeneratedAt.Sub(*stats.OldestPending).Seconds(), 0)
+	}
+	return stats, nil
+}
+
+const JobGroupQuery = `
+SELECT group_id,
+    COUNT(*) AS total,
+    COUNT(*) FILTER (
+        WHERE status = 'pending'
+    ) AS pending,
+    COUNT(*) FILTER (
+        WHERE status = 'processing'
+    ) AS processing,
+    COUNT(*) FILTER (
+        WHERE status = 'done'
+    ) AS done,
+    COUNT(*) FILTER (
+        WHERE status = 'failed'
+    ) AS failed,
+    COUNT(*) FILTER (
+        WHERE status = 'cancelled'
+    ) AS cancelled,
+    MIN(created_at) AS created_at,
+    MAX(finished_at) AS finished_at
+FROM jobs
+WHERE group_id = $1
+GROUP BY group_id;
+`
+
+// GetJobGroup implements JobStore. It returns nil when no job belongs to the
+// group.
+func (d *DbJobStore) GetJobGroup(ctx context.Context, groupID uuid.UUID) (*models.JobGroup, error) {
+	res, err := database.QueryAll[models.JobGroup](ctx, d.db, JobGroupQuery, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, nil
+	}
+	return &res[0], nil
+}
+
 func (d *DbJobStore) WithTx(db database.Dbx) JobStore {
 	return &DbJobStore{
 		db: db,
@@ -151,14 +290,38 @@ func (d *DbJobStore) WithTx(db database.Dbx) JobStore {
 }
 
 type JobStoreDecorator struct {
-	Delegate      JobStore
-	CountJobsFunc func(ctx context.Context, filter *JobFilter) (int64, error)
-	FindJobsFunc  func(ctx context.Context, filter *JobFilter) ([]*models.JobRow, error)
-	FindJobFunc   func(ctx context.Context, filter *JobFilter) (*models.JobRow, error)
-	CreateJobFunc func(ctx context.Context, job *models.JobRow) (*models.JobRow, error)
-	UpdateJobFunc func(ctx context.Context, job *models.JobRow) (*models.JobRow, error)
-	DeleteJobFunc func(ctx context.Context, filter *JobFilter) (int64, error)
-	RunInTxFunc   func(ctx context.Context, fn func(JobStore) error) error
+	Delegate        JobStore
+	CountJobsFunc   func(ctx context.Context, filter *JobFilter) (int64, error)
+	FindJobsFunc    func(ctx context.Context, filter *JobFilter) ([]*models.JobRow, error)
+	FindJobFunc     func(ctx context.Context, filter *JobFilter) (*models.JobRow, error)
+	CreateJobFunc   func(ctx context.Context, job *models.JobRow) (*models.JobRow, error)
+	UpdateJobFunc   func(ctx context.Context, job *models.JobRow) (*models.JobRow, error)
+	DeleteJobFunc   func(ctx context.Context, filter *JobFilter) (int64, error)
+	GetJobStatsFunc func(ctx context.Context, since time.Time, windows ...time.Duration) (*models.JobStats, error)
+	GetJobGroupFunc func(ctx context.Context, groupID uuid.UUID) (*models.JobGroup, error)
+	RunInTxFunc     func(ctx context.Context, fn func(JobStore) error) error
+}
+
+// GetJobGroup implements JobStore.
+func (j *JobStoreDecorator) GetJobGroup(ctx context.Context, groupID uuid.UUID) (*models.JobGroup, error) {
+	if j.GetJobGroupFunc != nil {
+		return j.GetJobGroupFunc(ctx, groupID)
+	}
+	if j.Delegate == nil {
+		return nil, errors.New("delegate for GetJobGroup in JobStore is nil")
+	}
+	return j.Delegate.GetJobGroup(ctx, groupID)
+}
+
+// GetJobStats implements JobStore.
+func (j *JobStoreDecorator) GetJobStats(ctx context.Context, since time.Time, windows ...time.Duration) (*models.JobStats, error) {
+	if j.GetJobStatsFunc != nil {
+		return j.GetJobStatsFunc(ctx, since, windows...)
+	}
+	if j.Delegate == nil {
+		return nil, errors.New("delegate for GetJobStats in JobStore is nil")
+	}
+	return j.Delegate.GetJobStats(ctx, since, windows...)
 }
 
 // CountJobs implements JobStore.
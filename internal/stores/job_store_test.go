@@ -0,0 +1,65 @@
+package stores
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tkahng/playground/internal/database"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/test"
+)
+
+func TestDbJobStore_GetJobStats(t *testing.T) {
+	test.Parallel(t)
+	test.SkipIfShort(t)
+	test.WithTx(t, func(ctx context.Context, db database.Dbx) {
+		store := NewDbJobStore(db)
+		now := time.Now()
+		started := now.Add(-time.Minute)
+		rows := []*models.JobRow{
+			{Kind: "stats_test", Status: models.JobStatusPending, RunAfter: now.Add(-10 * time.Second)},
+			{Kind: "stats_test", Status: models.JobStatusPending, RunAfter: now.Add(time.Hour)},
+			{Kind: "stats_test", Status: models.JobStatusDone, RunAfter: started, StartedAt: &started, FinishedAt: func() *time.Time { t := started.Add(time.Second); return &t }()},
+			{Kind: "stats_test", Status: models.JobStatusFailed, RunAfter: started, StartedAt: &started, FinishedAt: func() *time.Time { t := started.Add(3 * time.Second); return &t }()},
+		}
+		for _, row := range rows {
+			row.Payload = []byte(`{}`)
+			row.MaxAttempts = 3
+			if _, err := store.CreateJob(ctx, row); err != nil {
+				t.Fatalf("failed to create job: %v", err)
+			}
+		}
+
+		stats, err := store.GetJobStats(ctx, now.Add(-time.Hour), 5*time.Minute, time.Hour)
+		if err != nil {
+			t.Fatalf("GetJobStats() error = %v", err)
+		}
+		var kind *models.JobKindStats
+		for i := range stats.Kinds {
+			if stats.Kinds[i].Kind == "stats_test" {
+				kind = &stats.Kinds[i]
+			}
+		}
+		if kind == nil {
+			t.Fatalf("GetJobStats() missing kind stats_test")
+		}
+		if kind.Pending != 2 || kind.Done != 1 || kind.Failed != 1 {
+			t.Errorf("GetJobStats() counts = %+v", kind)
+		}
+		if kind.P50DurationMs == nil || *kind.P50DurationMs != 2000 {
+			t.Errorf("GetJobStats() p50 = %v, want 2000", kind.P50DurationMs)
+		}
+		if len(stats.FailureRates) != 2 {
+			t.Fatalf("GetJobStats() failure rates = %d, want 2", len(stats.FailureRates))
+		}
+		for _, rate := range stats.FailureRates {
+			if rate.Finished < 2 || rate.Failed < 1 {
+				t.Errorf("GetJobStats() failure rate = %+v", rate)
+			}
+		}
+		if stats.QueueLagSeconds < 10 {
+			t.Errorf("GetJobStats() queue lag = %v, want at least 10", stats.QueueLagSeconds)
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package stores
+
+import (
+	"context"
+	"time"
+
+	"github.com/tkahng/playground/internal/database"
+	"github.com/tkahng/playground/internal/models"
+)
+
+type LoginAttemptStore interface {
+	// FindLoginAttempt returns nil when the ip address did not fail the
+	// action for the email since the given time.
+	FindLoginAttempt(ctx context.Context, action models.LoginAttemptAction, email string, ipAddress string, since time.Time) (*models.LoginAttempt, error)
+	// ListLoginAttempts returns the attempts of every ip address at the
+	// action for the email since the given time.
+	ListLoginAttempts(ctx context.Context, action models.LoginAttemptAction, email string, since time.Time) ([]*models.LoginAttempt, error)
+	// RecordLoginFailure counts a failure, failures before since are
+	// forgotten.
+	RecordLoginFailure(ctx context.Context, action models.LoginAttemptAction, email string, ipAddress string, since time.Time) (*models.LoginAttempt, error)
+	// DeleteLoginAttempts forgets the failures of the email at the action, of
+	// every ip address when ipAddress is nil.
+	DeleteLoginAttempts(ctx context.Context, action models.LoginAttemptAction, email string, ipAddress *string) error
+}
+
+type DbLoginAttemptStore struct {
+	db database.Dbx
+}
+
+var _ LoginAttemptStore = (*DbLoginAttemptStore)(nil)
+
+func NewDbLoginAttemptStore(db database.Dbx) *DbLoginAttemptStore {
+	return &DbLoginAttemptStore{
+		db: db,
+	}
+}
+
+const loginAttemptColumns = `action, email, ip_address, failures, last_failed_at, created_at`
+
+// FindLoginAttempt implements LoginAttemptStore.
+func (s *DbLoginAttemptStore) FindLoginAttempt(ctx context.Context, action models.LoginAttemptAction, email string, ipAddress string, since time.Time) (*models.LoginAttempt, error) {
+	return database.One[*models.LoginAttempt](
+		ctx,
+		s.db,
+		`SELECT `+loginAttemptColumns+` FROM login_attempts
+		WHERE action = $1 AND email = $2 AND ip_address = $3 AND last_failed_at > $4`,
+		action,
+		email,
+		ipAddress,
+		since,
+	)
+}
+
+// ListLoginAttempts implements LoginAttemptStore.
+func (s *DbLoginAttemptStore) ListLoginAttempts(ctx context.Context, action models.LoginAttemptAction, email string, since time.Time) ([]*models.LoginAttempt, error) {
+	return database.QueryAll[*models.LoginAttempt](
+		ctx,
+		s.db,
+		`SELECT `+loginAttemptColumns+` FROM login_attempts
+		WHERE action = $1 AND email = $2 AND last_failed_at > $3
+		ORDER BY last_failed_at DESC`,
+		action,
+		email,
+		since,
+	)
+}
+
+// RecordLoginFailure implements LoginAttemptStore.
+func (s *DbLoginAttemptStore) RecordLoginFailure(ctx context.Context, action models.LoginAttemptAction, email string, ipAddress string, since time.Time) (*models.LoginAttempt, error) {
+	return database.One[*models.LoginAttempt](
+		ctx,
+		s.db,
+		`INSERT INTO login_attempts (action, email, ip_address, failures, last_failed_at)
+		VALUES ($1, $2, $3, 1, now())
+		ON CONFLICT (action, email, ip_address) DO UPDATE SET
+			failures = CASE WHEN login_attempts.last_failed_at > $4 THEN login_attempts.failures + 1 ELSE 1 END,
+			last_failed_at = now()
+		RETURNING `+loginAttemptColumns,
+		action,
+		email,
+		ipAddress,
+		since,
+	)
+}
+
+// DeleteLoginAttempts implements LoginAttemptStore.
+func (s *DbLoginAttemptStore) DeleteLoginAttempts(ctx context.Context, action models.LoginAttemptAction, email string, ipAddress *string) error {
+	_, err := database.Exec(
+		ctx,
+		s.db,
+		`DELETE FROM login_attempts WHERE action = $1 AND email = $2 AND ($3::text IS NULL OR ip_address = $3)`,
+		action,
+		email,
+		ipAddress,
+	)
+	return err
+}
@@ -0,0 +1,62 @@
+package stores
+
+import (
+	"context"
+	"time"
+
+	"github.com/tkahng/playground/internal/models"
+)
+
+type LoginAttemptStoreDecorator struct {
+	Delegate                *DbLoginAttemptStore
+	FindLoginAttemptFunc    func(ctx context.Context, action models.LoginAttemptAction, email string, ipAddress string, since time.Time) (*models.LoginAttempt, error)
+	ListLoginAttemptsFunc   func(ctx context.Context, action models.LoginAttemptAction, email string, since time.Time) ([]*models.LoginAttempt, error)
+	RecordLoginFailureFunc  func(ctx context.Context, action models.LoginAttemptAction, email string, ipAddress string, since time.Time) (*models.LoginAttempt, error)
+	DeleteLoginAttemptsFunc func(ctx context.Context, action models.LoginAttemptAction, email string, ipAddress *string) error
+}
+
+var _ LoginAttemptStore = (*LoginAttemptStoreDecorator)(nil)
+
+// FindLoginAttempt implements LoginAttemptStore.
+func (l *LoginAttemptStoreDecorator) FindLoginAttempt(ctx context.Context, action models.LoginAttemptAction, email string, ipAddress string, since time.Time) (*models.LoginAttempt, error) {
+	if l.FindLoginAttemptFunc != nil {
+		return l.FindLoginAttemptFunc(ctx, action, email, ipAddress, since)
+	}
+	if l.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return l.Delegate.FindLoginAttempt(ctx, action, email, ipAddress, since)
+}
+
+// ListLoginAttempts implements LoginAttemptStore.
+func (l *LoginAttemptStoreDecorator) ListLoginAttempts(ctx context.Context, action models.LoginAttemptAction, email string, since time.Time) ([]*models.LoginAttempt, error) {
+	if l.ListLoginAttemptsFunc != nil {
+		return l.ListLoginAttemptsFunc(ctx, action, email, since)
+	}
+	if l.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return l.Delegate.ListLoginAttempts(ctx, action, email, since)
+}
+
+// RecordLoginFailure implements LoginAttemptStore.
+func (l *LoginAttemptStoreDecorator) RecordLoginFailure(ctx context.Context, action models.LoginAttemptAction, email string, ipAddress string, since time.Time) (*models.LoginAttempt, error) {
+	if l.RecordLoginFailureFunc != nil {
+		return l.RecordLoginFailureFunc(ctx, action, email, ipAddress, since)
+	}
+	if l.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return l.Delegate.RecordLoginFailure(ctx, action, email, ipAddress, since)
+}
+
+// DeleteLoginAttempts implements LoginAttemptStore.
+func (l *LoginAttemptStoreDecorator) DeleteLoginAttempts(ctx context.Context, action models.LoginAttemptAction, email string, ipAddress *string) error {
+	if l.DeleteLoginAttemptsFunc != nil {
+		return l.DeleteLoginAttemptsFunc(ctx, action, email, ipAddress)
+	}
+	if l.Delegate == nil {
+		return ErrDelegateNil
+	}
+	return l.Delegate.DeleteLoginAttempts(ctx, action, email, ipAddress)
+}
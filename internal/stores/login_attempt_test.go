@@ -0,0 +1,61 @@
+package stores_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkahng/playground/internal/database"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/stores"
+	"github.com/tkahng/playground/internal/test"
+)
+
+func TestLoginAttemptStore(t *testing.T) {
+	test.DbSetup()
+	test.WithTx(t, func(ctx context.Context, db database.Dbx) {
+		store := stores.NewDbLoginAttemptStore(db)
+		signin := models.LoginAttemptActionSignin
+		email := "attempts@example.com"
+		since := time.Now().Add(-time.Minute)
+
+		attempt, err := store.FindLoginAttempt(ctx, signin, email, "10.0.0.1", since)
+		require.NoError(t, err)
+		assert.Nil(t, attempt)
+
+		for range 2 {
+			_, err = store.RecordLoginFailure(ctx, signin, email, "10.0.0.1", since)
+			require.NoError(t, err)
+		}
+		attempt, err = store.RecordLoginFailure(ctx, signin, email, "10.0.0.2", since)
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempt.Failures)
+
+		attempt, err = store.FindLoginAttempt(ctx, signin, email, "10.0.0.1", since)
+		require.NoError(t, err)
+		require.NotNil(t, attempt)
+		assert.Equal(t, 2, attempt.Failures)
+
+		// failures before since are forgotten
+		attempt, err = store.RecordLoginFailure(ctx, signin, email, "10.0.0.1", time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempt.Failures)
+
+		attempts, err := store.ListLoginAttempts(ctx, signin, email, since)
+		require.NoError(t, err)
+		assert.Len(t, attempts, 2)
+
+		ip := "10.0.0.1"
+		require.NoError(t, store.DeleteLoginAttempts(ctx, signin, email, &ip))
+		attempts, err = store.ListLoginAttempts(ctx, signin, email, since)
+		require.NoError(t, err)
+		assert.Len(t, attempts, 1)
+
+		require.NoError(t, store.DeleteLoginAttempts(ctx, signin, email, nil))
+		attempts, err = store.ListLoginAttempts(ctx, signin, email, since)
+		require.NoError(t, err)
+		assert.Empty(t, attempts)
+	})
+}
@@ -3,6 +3,7 @@ package stores
 import (
 	"context"
 	"errors"
+	"fmt"
 	"slices"
 	"strings"
 	"time"
@@ -18,11 +19,22 @@ import (
 type NotificationStore interface {
 	CreateNotification(ctx context.Context, notification *models.Notification) (*models.Notification, error)
 	InsertManyNotifications(ctx context.Context, notifications []models.Notification) (int64, error)
+	CreateManyNotifications(ctx context.Context, notifications []models.Notification) ([]*models.Notification, error)
 	FindNotification(ctx context.Context, args *NotificationFilter) (*models.Notification, error)
 	FindNotifications(ctx context.Context, args *NotificationFilter) ([]*models.Notification, error)
 	CountNotification(ctx context.Context, args *NotificationFilter) (int64, error)
 	UpdateNotification(ctx context.Context, notification *models.Notification) error
 	DeleteNotifications(ctx context.Context, args *NotificationFilter) (int64, error)
+	MarkNotificationsRead(ctx context.Context, args *NotificationFilter, readAt time.Time) (int64, error)
+	FindNotificationPreferences(ctx context.Context, userIDs []uuid.UUID) ([]*models.NotificationPreference, error)
+	UpsertNotificationPreference(ctx context.Context, preference *models.NotificationPreference) (*models.NotificationPreference, error)
+	DeleteNotificationPreference(ctx context.Context, userID uuid.UUID, teamID *uuid.UUID, kind string) (int64, error)
+	FindDigestTeamMemberIDs(ctx context.Context, since time.Time) ([]uuid.UUID, error)
+	CreateNotificationDigestItems(ctx context.Context, items []models.NotificationDigestItem) error
+	FindNotificationDigestItems(ctx context.Context, teamMemberID uuid.UUID, since time.Time, limit int) ([]*models.NotificationDigestItem, error)
+	DeleteNotificationDigestItems(ctx context.Context, before time.Time) (int64, error)
+	FindUnsubscribeVersion(ctx context.Context, userID uuid.UUID) (int64, error)
+	IncrementUnsubscribeVersion(ctx context.Context, userID uuid.UUID) (int64, error)
 }
 
 type DbNotificationStore struct {
@@ -39,6 +51,23 @@ func (s *DbNotificationStore) DeleteNotifications(ctx context.Context, args *Not
 	)
 }
 
+// MarkNotificationsRead implements NotificationStore. Only the unread
+// notifications matching args are updated, so read_at keeps the first read.
+func (s *DbNotificationStore) MarkNotificationsRead(ctx context.Context, args *NotificationFilter, readAt time.Time) (int64, error) {
+	filter := NotificationFilter{}
+	if args != nil {
+		filter = *args
+	}
+	filter.ReadAt = types.OptionalParam[time.Time]{}
+	filter.Read = types.OptionalParam[bool]{IsSet: true, Value: false}
+	sqlArgs := []any{readAt}
+	query := fmt.Sprintf("UPDATE %s SET read_at = $1", repository.NotificationBuilder.Table())
+	if expr := repository.NotificationBuilder.Where(s.filter(&filter), &sqlArgs, nil); expr != "" {
+		query += fmt.Sprintf(" WHERE %s", expr)
+	}
+	return database.Exec(ctx, s.db, query, sqlArgs...)
+}
+
 // UpdateNotification implements NotificationStore.
 func (s *DbNotificationStore) UpdateNotification(ctx context.Context, notification *models.Notification) error {
 	_, err := repository.Notification.PutOne(
@@ -73,6 +102,19 @@ func (s *DbNotificationStore) InsertManyNotifications(ctx context.Context, notif
 	)
 }
 
+// CreateManyNotifications inserts the notifications and returns the created
+// rows, including the seq assigned to each.
+func (s *DbNotificationStore) CreateManyNotifications(ctx context.Context, notifications []models.Notification) ([]*models.Notification, error) {
+	if len(notifications) == 0 {
+		return nil, nil
+	}
+	return repository.Notification.Post(
+		ctx,
+		s.db,
+		notifications,
+	)
+}
+
 type NotificationFilter struct {
 	PaginatedInput
 	SortParams
@@ -83,6 +125,10 @@ type NotificationFilter struct {
 	Channels      []string                       `query:"channels" json:"channels,omitempty" required:"false" minimum:"1" maximum:"100" uniqueItems:"true"`
 	Types         []string                       `query:"types" json:"types,omitempty" required:"false" minimum:"1" maximum:"100" uniqueItems:"true"`
 	ReadAt        types.OptionalParam[time.Time] `query:"read_at" json:"read_at" required:"false"`
+	SeqAfter      types.OptionalParam[int64]     `query:"seq_after" json:"seq_after" required:"false"`
+	Read          types.OptionalParam[bool]      `query:"read" json:"read" required:"false"`
+	CreatedAfter  types.OptionalParam[time.Time] `query:"created_after" json:"created_after" required:"false"`
+	CreatedBefore types.OptionalParam[time.Time] `query:"created_before" json:"created_before" required:"false"`
 }
 
 func (s *DbNotificationStore) FindNotification(ctx context.Context, args *NotificationFilter) (*models.Notification, error) {
@@ -152,9 +198,33 @@ func (s *DbNotificationStore) filter(args *NotificationFilter) *map[string]any {
 			"_in": args.Types,
 		}
 	}
+	readAt := map[string]any{}
 	if args.ReadAt.IsSet {
-		where["read_at"] = map[string]any{
-			"_eq": args.ReadAt.Value,
+		readAt["_eq"] = args.ReadAt.Value
+	}
+	if args.Read.IsSet {
+		if args.Read.Value {
+			readAt["_isnotnull"] = nil
+		} else {
+			readAt["_isnull"] = nil
+		}
+	}
+	if len(readAt) > 0 {
+		where["read_at"] = readAt
+	}
+	createdAt := map[string]any{}
+	if args.CreatedAfter.IsSet {
+		createdAt["_gte"] = args.CreatedAfter.Value
+	}
+	if args.CreatedBefore.IsSet {
+		createdAt["_lt"] = args.CreatedBefore.Value
+	}
+	if len(createdAt) > 0 {
+		where["created_at"] = createdAt
+	}
+	if args.SeqAfter.IsSet {
+		where["seq"] = map[string]any{
+			"_gt": args.SeqAfter.Value,
 		}
 	}
 	return &where
@@ -175,10 +245,22 @@ type NotificationStoreDecorator struct {
 	CountFunc             func(ctx context.Context, filter *NotificationFilter) (int64, error)
 	CreateFunc            func(ctx context.Context, notification *models.Notification) (*models.Notification, error)
 	CreateManyFunc        func(ctx context.Context, notifications []models.Notification) (int64, error)
+	CreateManyReturnFunc  func(ctx context.Context, notifications []models.Notification) ([]*models.Notification, error)
 	FindNotificationFunc  func(ctx context.Context, args *NotificationFilter) (*models.Notification, error)
 	FindNotificationsFunc func(ctx context.Context, args *NotificationFilter) ([]*models.Notification, error)
 	UpdateFunc            func(ctx context.Context, notification *models.Notification) error
 	DeleteFunc            func(ctx context.Context, args *NotificationFilter) (int64, error)
+	MarkReadFunc          func(ctx context.Context, args *NotificationFilter, readAt time.Time) (int64, error)
+	FindPreferencesFunc   func(ctx context.Context, userIDs []uuid.UUID) ([]*models.NotificationPreference, error)
+	UpsertPreferenceFunc  func(ctx context.Context, preference *models.NotificationPreference) (*models.NotificationPreference, error)
+	DeletePreferenceFunc  func(ctx context.Context, userID uuid.UUID, teamID *uuid.UUID, kind string) (int64, error)
+
+	FindDigestTeamMemberIDsFunc     func(ctx context.Context, since time.Time) ([]uuid.UUID, error)
+	CreateDigestItemsFunc           func(ctx context.Context, items []models.NotificationDigestItem) error
+	FindDigestItemsFunc             func(ctx context.Context, teamMemberID uuid.UUID, since time.Time, limit int) ([]*models.NotificationDigestItem, error)
+	DeleteDigestItemsFunc           func(ctx context.Context, before time.Time) (int64, error)
+	FindUnsubscribeVersionFunc      func(ctx context.Context, userID uuid.UUID) (int64, error)
+	IncrementUnsubscribeVersionFunc func(ctx context.Context, userID uuid.UUID) (int64, error)
 }
 
 // DeleteNotifications implements NotificationStore.
@@ -220,6 +302,17 @@ func (n *NotificationStoreDecorator) InsertManyNotifications(ctx context.Context
 	return n.Delegate.InsertManyNotifications(ctx, notifications)
 }
 
+// CreateManyNotifications implements NotificationStore.
+func (n *NotificationStoreDecorator) CreateManyNotifications(ctx context.Context, notifications []models.Notification) ([]*models.Notification, error) {
+	if n.CreateManyReturnFunc != nil {
+		return n.CreateManyReturnFunc(ctx, notifications)
+	}
+	if n.Delegate == nil {
+		return nil, errors.New("delegate is nil in CreateManyNotifications")
+	}
+	return n.Delegate.CreateManyNotifications(ctx, notifications)
+}
+
 // CreateNotification implements NotificationStore.
 func (n *NotificationStoreDecorator) CreateNotification(ctx context.Context, notification *models.Notification) (*models.Notification, error) {
 	if n.CreateFunc != nil {
@@ -253,6 +346,17 @@ func (n *NotificationStoreDecorator) FindNotifications(ctx context.Context, args
 	return n.Delegate.FindNotifications(ctx, args)
 }
 
+// MarkNotificationsRead implements NotificationStore.
+func (n *NotificationStoreDecorator) MarkNotificationsRead(ctx context.Context, args *NotificationFilter, readAt time.Time) (int64, error) {
+	if n.MarkReadFunc != nil {
+		return n.MarkReadFunc(ctx, args, readAt)
+	}
+	if n.Delegate == nil {
+		return 0, errors.New("delegate is nil in MarkNotificationsRead")
+	}
+	return n.Delegate.MarkNotificationsRead(ctx, args, readAt)
+}
+
 // UpdateNotification implements NotificationStore.
 func (n *NotificationStoreDecorator) UpdateNotification(ctx context.Context, notification *models.Notification) error {
 	if n.UpdateFunc != nil {
@@ -0,0 +1,293 @@
+package stores
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/tkahng/playground/internal/database"
+	"github.com/tkahng/playground/internal/models"
+)
+
+const FindNotificationPreferencesQuery = `
+SELECT id, user_id, team_id, type, delivery, daily_digest, created_at, updated_at
+FROM notification_preferences
+WHERE user_id = ANY($1)
+ORDER BY type, team_id NULLS FIRST
+`
+
+// FindNotificationPreferences returns every preference of the users.
+func (s *DbNotificationStore) FindNotificationPreferences(ctx context.Context, userIDs []uuid.UUID) ([]*models.NotificationPreference, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	return database.QueryAll[*models.NotificationPreference](
+		ctx,
+		s.db,
+		FindNotificationPreferencesQuery,
+		userIDs,
+	)
+}
+
+const UpsertNotificationPreferenceQuery = `
+INSERT INTO notification_preferences (user_id, team_id, type, delivery, daily_digest)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id, team_id, type) DO UPDATE
+SET delivery = EXCLUDED.delivery,
+    daily_digest = EXCLUDED.daily_digest
+RETURNING id, user_id, team_id, type, delivery, daily_digest, created_at, updated_at
+`
+
+// UpsertNotificationPreference creates the preference of a user for a type
+// and team or replaces the existing one.
+func (s *DbNotificationStore) UpsertNotificationPreference(ctx context.Context, preference *models.NotificationPreference) (*models.NotificationPreference, error) {
+	res, err := database.QueryAll[*models.NotificationPreference](
+		ctx,
+		s.db,
+		UpsertNotificationPreferenceQuery,
+		preference.UserID,
+		preference.TeamID,
+		preference.Type,
+		preference.Delivery,
+		preference.DailyDigest,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, nil
+	}
+	return res[0], nil
+}
+
+// DeleteNotificationPreference removes the preference of a user for a type
+// and team, a nil team removes the preference that applies to every team.
+func (s *DbNotificationStore) DeleteNotificationPreference(ctx context.Context, userID uuid.UUID, teamID *uuid.UUID, kind string) (int64, error) {
+	tag, err := s.db.Exec(
+		ctx,
+		`DELETE FROM notification_preferences
+		WHERE user_id = $1 AND team_id IS NOT DISTINCT FROM $2 AND type = $3`,
+		userID,
+		teamID,
+		kind,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// FindNotificationPreferences implements NotificationStore.
+func (n *NotificationStoreDecorator) FindNotificationPreferences(ctx context.Context, userIDs []uuid.UUID) ([]*models.NotificationPreference, error) {
+	if n.FindPreferencesFunc != nil {
+		return n.FindPreferencesFunc(ctx, userIDs)
+	}
+	if n.Delegate == nil {
+		return nil, errors.New("delegate is nil in FindNotificationPreferences")
+	}
+	return n.Delegate.FindNotificationPreferences(ctx, userIDs)
+}
+
+// UpsertNotificationPreference implements NotificationStore.
+func (n *NotificationStoreDecorator) UpsertNotificationPreference(ctx context.Context, preference *models.NotificationPreference) (*models.NotificationPreference, error) {
+	if n.UpsertPreferenceFunc != nil {
+		return n.UpsertPreferenceFunc(ctx, preference)
+	}
+	if n.Delegate == nil {
+		return nil, errors.New("delegate is nil in UpsertNotificationPreference")
+	}
+	return n.Delegate.UpsertNotificationPreference(ctx, preference)
+}
+
+// DeleteNotificationPreference implements NotificationStore.
+func (n *NotificationStoreDecorator) DeleteNotificationPreference(ctx context.Context, userID uuid.UUID, teamID *uuid.UUID, kind string) (int64, error) {
+	if n.DeletePreferenceFunc != nil {
+		return n.DeletePreferenceFunc(ctx, userID, teamID, kind)
+	}
+	if n.Delegate == nil {
+		return 0, errors.New("delegate is nil in DeleteNotificationPreference")
+	}
+	return n.Delegate.DeleteNotificationPreference(ctx, userID, teamID, kind)
+}
+
+const FindDigestTeamMemberIDsQuery = `
+SELECT n.team_member_id
+FROM notifications n
+JOIN team_members tm ON tm.id = n.team_member_id
+JOIN notification_preferences p ON p.user_id = tm.user_id
+    AND p.type = n.type
+    AND (p.team_id IS NULL OR p.team_id = tm.team_id)
+WHERE n.read_at IS NULL
+    AND n.created_at >= $1
+    AND p.daily_digest
+UNION
+SELECT i.team_member_id
+FROM notification_digest_items i
+JOIN team_members tm ON tm.id = i.team_member_id
+JOIN notification_preferences p ON p.user_id = tm.user_id
+    AND p.type = i.type
+    AND (p.team_id IS NULL OR p.team_id = tm.team_id)
+WHERE i.created_at >= $1
+    AND p.daily_digest
+`
+
+// FindDigestTeamMemberIDs returns the team members that have unread
+// notifications, or notifications delivered by email only, since since of a
+// type they may want in their daily digest.
+func (s *DbNotificationStore) FindDigestTeamMemberIDs(ctx context.Context, since time.Time) ([]uuid.UUID, error) {
+	rows, err := s.db.Query(ctx, FindDigestTeamMemberIDsQuery, since)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+}
+
+// FindDigestTeamMemberIDs implements NotificationStore.
+func (n *NotificationStoreDecorator) FindDigestTeamMemberIDs(ctx context.Context, since time.Time) ([]uuid.UUID, error) {
+	if n.FindDigestTeamMemberIDsFunc != nil {
+		return n.FindDigestTeamMemberIDsFunc(ctx, since)
+	}
+	if n.Delegate == nil {
+		return nil, errors.New("delegate is nil in FindDigestTeamMemberIDs")
+	}
+	return n.Delegate.FindDigestTeamMemberIDs(ctx, since)
+}
+
+// CreateNotificationDigestItems keeps notifications delivered by email only
+// for the daily digest.
+func (s *DbNotificationStore) CreateNotificationDigestItems(ctx context.Context, items []models.NotificationDigestItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	batch := &pgx.Batch{}
+	for _, item := range items {
+		batch.Queue(
+			`INSERT INTO notification_digest_items (team_member_id, type, payload) VALUES ($1, $2, $3)`,
+			item.TeamMemberID,
+			item.Type,
+			item.Payload,
+		)
+	}
+	return s.db.SendBatch(ctx, batch).Close()
+}
+
+const FindNotificationDigestItemsQuery = `
+SELECT id, team_member_id, type, payload, created_at
+FROM notification_digest_items
+WHERE team_member_id = $1
+    AND created_at >= $2
+ORDER BY created_at DESC
+LIMIT $3
+`
+
+// FindNotificationDigestItems returns the newest digest items of the team
+// member created since since.
+func (s *DbNotificationStore) FindNotificationDigestItems(ctx context.Context, teamMemberID uuid.UUID, since time.Time, limit int) ([]*models.NotificationDigestItem, error) {
+	return database.QueryAll[*models.NotificationDigestItem](
+		ctx,
+		s.db,
+		FindNotificationDigestItemsQuery,
+		teamMemberID,
+		since,
+		limit,
+	)
+}
+
+// DeleteNotificationDigestItems removes the digest items created before
+// before, the digests that could list them were sent.
+func (s *DbNotificationStore) DeleteNotificationDigestItems(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := s.db.Exec(ctx, `DELETE FROM notification_digest_items WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// FindUnsubscribeVersion returns the version unsubscribe tokens of the user
+// must carry, 0 until they were first revoked.
+func (s *DbNotificationStore) FindUnsubscribeVersion(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var version int64
+	err := s.db.QueryRow(
+		ctx,
+		`SELECT version FROM notification_unsubscribe_versions WHERE user_id = $1`,
+		userID,
+	).Scan(&version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	return version, err
+}
+
+// IncrementUnsubscribeVersion revokes the unsubscribe tokens of the user and
+// returns the new version.
+func (s *DbNotificationStore) IncrementUnsubscribeVersion(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var version int64
+	err := s.db.QueryRow(
+		ctx,
+		`INSERT INTO notification_unsubscribe_versions (user_id, version)
+		VALUES ($1, 1)
+		ON CONFLICT (user_id) DO UPDATE
+		SET version = notification_unsubscribe_versions.version + 1,
+			updated_at = now()
+		RETURNING version`,
+		userID,
+	).Scan(&version)
+	return version, err
+}
+
+// CreateNotificationDigestItems implements NotificationStore.
+func (n *NotificationStoreDecorator) CreateNotificationDigestItems(ctx context.Context, items []models.NotificationDigestItem) error {
+	if n.CreateDigestItemsFunc != nil {
+		return n.CreateDigestItemsFunc(ctx, items)
+	}
+	if n.Delegate == nil {
+		return errors.New("delegate is nil in CreateNotificationDigestItems")
+	}
+	return n.Delegate.CreateNotificationDigestItems(ctx, items)
+}
+
+// FindNotificationDigestItems implements NotificationStore.
+func (n *NotificationStoreDecorator) FindNotificationDigestItems(ctx context.Context, teamMemberID uuid.UUID, since time.Time, limit int) ([]*models.NotificationDigestItem, error) {
+	if n.FindDigestItemsFunc != nil {
+		return n.FindDigestItemsFunc(ctx, teamMemberID, since, limit)
+	}
+	if n.Delegate == nil {
+		return nil, errors.New("delegate is nil in FindNotificationDigestItems")
+	}
+	return n.Delegate.FindNotificationDigestItems(ctx, teamMemberID, since, limit)
+}
+
+// DeleteNotificationDigestItems implements NotificationStore.
+func (n *NotificationStoreDecorator) DeleteNotificationDigestItems(ctx context.Context, before time.Time) (int64, error) {
+	if n.DeleteDigestItemsFunc != nil {
+		return n.DeleteDigestItemsFunc(ctx, before)
+	}
+	if n.Delegate == nil {
+		return 0, errors.New("delegate is nil in DeleteNotificationDigestItems")
+	}
+	return n.Delegate.DeleteNotificationDigestItems(ctx, before)
+}
+
+// FindUnsubscribeVersion implements NotificationStore.
+func (n *NotificationStoreDecorator) FindUnsubscribeVersion(ctx context.Context, userID uuid.UUID) (int64, error) {
+	if n.FindUnsubscribeVersionFunc != nil {
+		return n.FindUnsubscribeVersionFunc(ctx, userID)
+	}
+	if n.Delegate == nil {
+		return 0, errors.New("delegate is nil in FindUnsubscribeVersion")
+	}
+	return n.Delegate.FindUnsubscribeVersion(ctx, userID)
+}
+
+// IncrementUnsubscribeVersion implements NotificationStore.
+func (n *NotificationStoreDecorator) IncrementUnsubscribeVersion(ctx context.Context, userID uuid.UUID) (int64, error) {
+	if n.IncrementUnsubscribeVersionFunc != nil {
+		return n.IncrementUnsubscribeVersionFunc(ctx, userID)
+	}
+	if n.Delegate == nil {
+		return 0, errors.New("delegate is nil in IncrementUnsubscribeVersion")
+	}
+	return n.Delegate.IncrementUnsubscribeVersion(ctx, userID)
+}
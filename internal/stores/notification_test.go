@@ -11,6 +11,7 @@ import (
 	"github.com/tkahng/playground/internal/models"
 	"github.com/tkahng/playground/internal/stores"
 	"github.com/tkahng/playground/internal/test"
+	"github.com/tkahng/playground/internal/tools/types"
 )
 
 func TestNotificationStore_CreateNotification(t *testing.T) {
@@ -62,3 +63,136 @@ func TestNotificationStore_CreateManyNotifications(t *testing.T) {
 
 	})
 }
+
+func TestNotificationStore_FindNotifications_SeqAfter(t *testing.T) {
+	test.DbSetup()
+	test.WithTx(t, func(ctx context.Context, db database.Dbx) {
+		store := stores.NewDbNotificationStore(db)
+
+		var notifications []models.Notification
+		for _, typ := range []string{"first", "second", "third"} {
+			notifications = append(notifications, models.Notification{
+				Channel:  "seq-channel",
+				Type:     typ,
+				Metadata: map[string]any{},
+				Payload:  []byte("{}"),
+			})
+		}
+		created, err := store.CreateManyNotifications(ctx, notifications)
+		assert.NoError(t, err)
+		assert.Len(t, created, 3)
+		assert.Less(t, created[0].Seq, created[1].Seq)
+		assert.Less(t, created[1].Seq, created[2].Seq)
+
+		filter := &stores.NotificationFilter{
+			Channels: []string{"seq-channel"},
+			SeqAfter: types.OptionalParam[int64]{IsSet: true, Value: created[0].Seq},
+		}
+		filter.SortBy = "seq"
+		filter.SortOrder = "asc"
+		got, err := store.FindNotifications(ctx, filter)
+		assert.NoError(t, err)
+		if assert.Len(t, got, 2) {
+			assert.Equal(t, "second", got[0].Type)
+			assert.Equal(t, "third", got[1].Type)
+		}
+	})
+}
+
+func TestNotificationStore_MarkNotificationsRead(t *testing.T) {
+	test.DbSetup()
+	test.WithTx(t, func(ctx context.Context, db database.Dbx) {
+		store := stores.NewDbNotificationStore(db)
+
+		old := time.Now().Add(-48 * time.Hour)
+		earlier := time.Now().Add(-time.Hour)
+		notifications := []models.Notification{
+			{Channel: "read-channel", Type: "first", CreatedAt: old, Metadata: map[string]any{}, Payload: []byte("{}")},
+			{Channel: "read-channel", Type: "second", CreatedAt: time.Now(), Metadata: map[string]any{}, Payload: []byte("{}")},
+			{Channel: "read-channel", Type: "second", CreatedAt: time.Now(), ReadAt: &earlier, Metadata: map[string]any{}, Payload: []byte("{}")},
+		}
+		_, err := store.CreateManyNotifications(ctx, notifications)
+		assert.NoError(t, err)
+
+		unread := &stores.NotificationFilter{
+			Channels: []string{"read-channel"},
+			Read:     types.OptionalParam[bool]{IsSet: true, Value: false},
+		}
+		count, err := store.CountNotification(ctx, unread)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+
+		updated, err := store.MarkNotificationsRead(ctx, &stores.NotificationFilter{
+			Channels: []string{"read-channel"},
+			Types:    []string{"second"},
+		}, time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), updated)
+
+		count, err = store.CountNotification(ctx, unread)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+
+		updated, err = store.MarkNotificationsRead(ctx, &stores.NotificationFilter{
+			Channels:      []string{"read-channel"},
+			CreatedBefore: types.OptionalParam[time.Time]{IsSet: true, Value: time.Now().Add(-24 * time.Hour)},
+		}, time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), updated)
+
+		deleted, err := store.DeleteNotifications(ctx, &stores.NotificationFilter{
+			Channels:      []string{"read-channel"},
+			Read:          types.OptionalParam[bool]{IsSet: true, Value: true},
+			CreatedBefore: types.OptionalParam[time.Time]{IsSet: true, Value: time.Now().Add(-24 * time.Hour)},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+	})
+}
+
+func TestNotificationStore_NotificationPreferences(t *testing.T) {
+	test.DbSetup()
+	test.WithTx(t, func(ctx context.Context, db database.Dbx) {
+		adapter := stores.NewStorageAdapter(db)
+		store := stores.NewDbNotificationStore(db)
+		user := CreateUser(adapter, ctx, "preferences@example.com")
+		team := CreateTeam(adapter, ctx, "PreferencesTeam")
+
+		_, err := store.UpsertNotificationPreference(ctx, &models.NotificationPreference{
+			UserID:   user.ID,
+			Type:     "task_completed",
+			Delivery: models.NotificationDeliveryEmail,
+		})
+		assert.NoError(t, err)
+		// the preference for every team is replaced, not duplicated
+		got, err := store.UpsertNotificationPreference(ctx, &models.NotificationPreference{
+			UserID:      user.ID,
+			Type:        "task_completed",
+			Delivery:    models.NotificationDeliveryBoth,
+			DailyDigest: true,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, models.NotificationDeliveryBoth, got.Delivery)
+		assert.True(t, got.DailyDigest)
+		_, err = store.UpsertNotificationPreference(ctx, &models.NotificationPreference{
+			UserID:   user.ID,
+			TeamID:   types.Pointer(team.ID),
+			Type:     "task_completed",
+			Delivery: models.NotificationDeliveryNone,
+		})
+		assert.NoError(t, err)
+
+		preferences, err := store.FindNotificationPreferences(ctx, []uuid.UUID{user.ID})
+		assert.NoError(t, err)
+		assert.Len(t, preferences, 2)
+
+		count, err := store.DeleteNotificationPreference(ctx, user.ID, nil, "task_completed")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+
+		preferences, err = store.FindNotificationPreferences(ctx, []uuid.UUID{user.ID})
+		assert.NoError(t, err)
+		assert.Len(t, preferences, 1)
+		assert.Equal(t, &team.ID, preferences[0].TeamID)
+	})
+}
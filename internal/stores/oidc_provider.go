@@ -0,0 +1,79 @@
+package stores
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/database"
+	"github.com/tkahng/playground/internal/models"
+)
+
+type OidcProviderStore interface {
+	ListOidcProviders(ctx context.Context) ([]*models.OidcProvider, error)
+	// FindOidcProviderByName returns nil when there is no provider with the
+	// name.
+	FindOidcProviderByName(ctx context.Context, name string) (*models.OidcProvider, error)
+	CreateOidcProvider(ctx context.Context, provider *models.OidcProvider) (*models.OidcProvider, error)
+	DeleteOidcProvider(ctx context.Context, id uuid.UUID) error
+}
+
+type DbOidcProviderStore struct {
+	db database.Dbx
+}
+
+var _ OidcProviderStore = (*DbOidcProviderStore)(nil)
+
+func NewDbOidcProviderStore(db database.Dbx) *DbOidcProviderStore {
+	return &DbOidcProviderStore{
+		db: db,
+	}
+}
+
+const oidcProviderColumns = `id, name, display_name, issuer, client_id, client_secret, scopes, enabled, created_at, updated_at`
+
+// ListOidcProviders implements OidcProviderStore.
+func (s *DbOidcProviderStore) ListOidcProviders(ctx context.Context) ([]*models.OidcProvider, error) {
+	return database.QueryAll[*models.OidcProvider](
+		ctx,
+		s.db,
+		`SELECT `+oidcProviderColumns+` FROM oidc_providers ORDER BY name`,
+	)
+}
+
+// FindOidcProviderByName implements OidcProviderStore.
+func (s *DbOidcProviderStore) FindOidcProviderByName(ctx context.Context, name string) (*models.OidcProvider, error) {
+	providers, err := database.QueryAll[*models.OidcProvider](
+		ctx,
+		s.db,
+		`SELECT `+oidcProviderColumns+` FROM oidc_providers WHERE name = $1`,
+		name,
+	)
+	if err != nil || len(providers) == 0 {
+		return nil, err
+	}
+	return providers[0], nil
+}
+
+// CreateOidcProvider implements OidcProviderStore.
+func (s *DbOidcProviderStore) CreateOidcProvider(ctx context.Context, provider *models.OidcProvider) (*models.OidcProvider, error) {
+	return database.One[*models.OidcProvider](
+		ctx,
+		s.db,
+		`INSERT INTO oidc_providers (name, display_name, issuer, client_id, client_secret, scopes, enabled)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING `+oidcProviderColumns,
+		provider.Name,
+		provider.DisplayName,
+		provider.Issuer,
+		provider.ClientID,
+		provider.ClientSecret,
+		provider.Scopes,
+		provider.Enabled,
+	)
+}
+
+// DeleteOidcProvider implements OidcProviderStore.
+func (s *DbOidcProviderStore) DeleteOidcProvider(ctx context.Context, id uuid.UUID) error {
+	_, err := database.Exec(ctx, s.db, `DELETE FROM oidc_providers WHERE id = $1`, id)
+	return err
+}
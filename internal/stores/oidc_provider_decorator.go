@@ -0,0 +1,62 @@
+package stores
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/models"
+)
+
+type OidcProviderStoreDecorator struct {
+	Delegate                   *DbOidcProviderStore
+	ListOidcProvidersFunc      func(ctx context.Context) ([]*models.OidcProvider, error)
+	FindOidcProviderByNameFunc func(ctx context.Context, name string) (*models.OidcProvider, error)
+	CreateOidcProviderFunc     func(ctx context.Context, provider *models.OidcProvider) (*models.OidcProvider, error)
+	DeleteOidcProviderFunc     func(ctx context.Context, id uuid.UUID) error
+}
+
+var _ OidcProviderStore = (*OidcProviderStoreDecorator)(nil)
+
+// ListOidcProviders implements OidcProviderStore.
+func (o *OidcProviderStoreDecorator) ListOidcProviders(ctx context.Context) ([]*models.OidcProvider, error) {
+	if o.ListOidcProvidersFunc != nil {
+		return o.ListOidcProvidersFunc(ctx)
+	}
+	if o.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return o.Delegate.ListOidcProviders(ctx)
+}
+
+// FindOidcProviderByName implements OidcProviderStore.
+func (o *OidcProviderStoreDecorator) FindOidcProviderByName(ctx context.Context, name string) (*models.OidcProvider, error) {
+	if o.FindOidcProviderByNameFunc != nil {
+		return o.FindOidcProviderByNameFunc(ctx, name)
+	}
+	if o.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return o.Delegate.FindOidcProviderByName(ctx, name)
+}
+
+// CreateOidcProvider implements OidcProviderStore.
+func (o *OidcProviderStoreDecorator) CreateOidcProvider(ctx context.Context, provider *models.OidcProvider) (*models.OidcProvider, error) {
+	if o.CreateOidcProviderFunc != nil {
+		return o.CreateOidcProviderFunc(ctx, provider)
+	}
+	if o.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return o.Delegate.CreateOidcProvider(ctx, provider)
+}
+
+// DeleteOidcProvider implements OidcProviderStore.
+func (o *OidcProviderStoreDecorator) DeleteOidcProvider(ctx context.Context, id uuid.UUID) error {
+	if o.DeleteOidcProviderFunc != nil {
+		return o.DeleteOidcProviderFunc(ctx, id)
+	}
+	if o.Delegate == nil {
+		return ErrDelegateNil
+	}
+	return o.Delegate.DeleteOidcProvider(ctx, id)
+}
@@ -43,6 +43,7 @@ type RbacStoreDecorator struct {
 
 	ListPermissionsFunc              func(ctx context.Context, input *PermissionFilter) ([]*models.Permission, error)
 	ListRolesFunc                    func(ctx context.Context, input *RoleListFilter) ([]*models.Role, error)
+	ListUserPermissionGrantsFunc     func(ctx context.Context, userId uuid.UUID, permissionName string) ([]*models.PermissionGrant, error)
 	ListUserNotPermissionsSourceFunc func(ctx context.Context, userId uuid.UUID, limit int64, offset int64) ([]*models.PermissionSource, error)
 	ListUserPermissionsSourceFunc    func(ctx context.Context, userId uuid.UUID, limit int64, offset int64) ([]*models.PermissionSource, error)
 	LoadProductPermissionsFunc       func(ctx context.Context, productIds ...string) ([][]*models.Permission, error)
@@ -90,6 +91,7 @@ func (r *RbacStoreDecorator) Cleanup() {
 	r.ListPermissionsFunc = nil
 	r.ListRolesFunc = nil
 	r.ListUserNotPermissionsSourceFunc = nil
+	r.ListUserPermissionGrantsFunc = nil
 	r.ListUserPermissionsSourceFunc = nil
 	r.LoadProductPermissionsFunc = nil
 	r.LoadRolePermissionsFunc = nil
@@ -449,6 +451,17 @@ func (r *RbacStoreDecorator) ListUserNotPermissionsSource(ctx context.Context, u
 	return r.Delegate.ListUserNotPermissionsSource(ctx, userId, limit, offset)
 }
 
+// ListUserPermissionGrants implements DbRbacStoreInterface.
+func (r *RbacStoreDecorator) ListUserPermissionGrants(ctx context.Context, userId uuid.UUID, permissionName string) ([]*models.PermissionGrant, error) {
+	if r.ListUserPermissionGrantsFunc != nil {
+		return r.ListUserPermissionGrantsFunc(ctx, userId, permissionName)
+	}
+	if r.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return r.Delegate.ListUserPermissionGrants(ctx, userId, permissionName)
+}
+
 // ListUserPermissionsSource implements DbRbacStoreInterface.
 func (r *RbacStoreDecorator) ListUserPermissionsSource(ctx context.Context, userId uuid.UUID, limit int64, offset int64) ([]*models.PermissionSource, error) {
 	if r.ListUserPermissionsSourceFunc != nil {
@@ -294,6 +294,7 @@ type DbRbacStoreInterface interface { // size=16 (0x10)
 	GetUserRoles(ctx context.Context, userIds ...uuid.UUID) ([][]*models.Role, error)
 	ListPermissions(ctx context.Context, input *PermissionFilter) ([]*models.Permission, error)
 	ListRoles(ctx context.Context, input *RoleListFilter) ([]*models.Role, error)
+	ListUserPermissionGrants(ctx context.Context, userId uuid.UUID, permissionName string) ([]*models.PermissionGrant, error)
 	ListUserNotPermissionsSource(ctx context.Context, userId uuid.UUID, limit int64, offset int64) ([]*models.PermissionSource, error)
 	ListUserPermissionsSource(ctx context.Context, userId uuid.UUID, limit int64, offset int64) ([]*models.PermissionSource, error)
 	LoadProductPermissions(ctx context.Context, productIds ...string) ([][]*models.Permission, error)
@@ -399,6 +399,81 @@ FROM combined_permissions
 	return data, nil
 }
 
+// ListUserPermissionGrants implements DbRbacStoreInterface.
+func (p *DbRbacStore) ListUserPermissionGrants(ctx context.Context, userId uuid.UUID, permissionName string) ([]*models.PermissionGrant, error) {
+	const QueryUserPermissionGrants string = `
+	-- Get the permission assigned directly to user
+	SELECT p.id AS permission_id,
+		p.name AS permission,
+		'direct'::text AS source,
+		NULL::uuid AS role_id,
+		NULL::text AS role_name,
+		NULL::text AS product_id,
+		NULL::text AS product_name,
+		NULL::text AS subscription_id
+	FROM public.user_permissions up
+		JOIN public.permissions p ON up.permission_id = p.id
+	WHERE up.user_id = $1
+		AND p.name = $2
+	UNION ALL
+	-- Get the permission assigned through roles
+	SELECT p.id,
+		p.name,
+		'role'::text,
+		r.id,
+		r.name,
+		NULL::text,
+		NULL::text,
+		NULL::text
+	FROM public.user_roles ur
+		JOIN public.roles r ON ur.role_id = r.id
+		JOIN public.role_permissions rp ON r.id = rp.role_id
+		JOIN public.permissions p ON rp.permission_id = p.id
+	WHERE ur.user_id = $1
+		AND p.name = $2
+	UNION ALL
+	-- Get the permission assigned through products, directly or with their roles
+	SELECT p.id,
+		p.name,
+		'product'::text,
+		pp.role_id,
+		r.name,
+		sproduct.id,
+		sproduct.name,
+		ss.id
+	FROM public.stripe_subscriptions ss
+		JOIN public.stripe_customers sc ON ss.stripe_customer_id = sc.id
+		JOIN public.stripe_prices sprice ON ss.price_id = sprice.id
+		JOIN public.stripe_products sproduct ON sprice.product_id = sproduct.id
+		JOIN (
+			SELECT product_id,
+				NULL::uuid AS role_id,
+				permission_id
+			FROM public.product_permissions
+			UNION ALL
+			SELECT pr.product_id,
+				pr.role_id,
+				rp.permission_id
+			FROM public.product_roles pr
+				JOIN public.role_permissions rp ON pr.role_id = rp.role_id
+		) pp ON sproduct.id = pp.product_id
+		JOIN public.permissions p ON pp.permission_id = p.id
+		LEFT JOIN public.roles r ON pp.role_id = r.id
+	WHERE sc.user_id = $1
+		AND ss.status IN ('active', 'trialing')
+		AND p.name = $2
+	ORDER BY source,
+		role_name NULLS FIRST,
+		product_name NULLS FIRST,
+		subscription_id NULLS FIRST;`
+
+	data, err := database.QueryAll[*models.PermissionGrant](ctx, p.db, QueryUserPermissionGrants, userId, permissionName)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
 func (p *DbRbacStore) DeleteUserRole(ctx context.Context, userId, roleId uuid.UUID) error {
 	_, err := repository.RolePermission.Delete(
 		ctx,
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/tkahng/playground/internal/database"
@@ -539,3 +540,96 @@ func TestGetUserRoles(t *testing.T) {
 		return errors.New("rollback")
 	})
 }
+
+func TestListUserPermissionGrants(t *testing.T) {
+	test.Parallel(t)
+	test.SkipIfShort(t)
+	test.WithTx(t, func(ctx context.Context, dbxx database.Dbx) {
+		adapter := stores.NewStorageAdapter(dbxx)
+		rbacStore := stores.NewDbRBACStore(dbxx)
+		user, err := adapter.User().CreateUser(ctx, &models.User{Email: "grants@test.com"})
+		if err != nil {
+			t.Fatalf("failed to create test user: %v", err)
+		}
+		perm, err := rbacStore.CreatePermission(ctx, "grants_permission", nil)
+		if err != nil {
+			t.Fatalf("failed to create test permission: %v", err)
+		}
+		role, err := rbacStore.CreateRole(ctx, &stores.CreateRoleDto{Name: "grants_role"})
+		if err != nil {
+			t.Fatalf("failed to create test role: %v", err)
+		}
+		if err = rbacStore.CreateRolePermissions(ctx, role.ID, perm.ID); err != nil {
+			t.Fatalf("failed to assign permission to role: %v", err)
+		}
+		if err = rbacStore.CreateUserRoles(ctx, user.ID, role.ID); err != nil {
+			t.Fatalf("failed to assign role to user: %v", err)
+		}
+		if err = rbacStore.CreateUserPermissions(ctx, user.ID, perm.ID); err != nil {
+			t.Fatalf("failed to assign permission to user: %v", err)
+		}
+
+		// the product grants the permission directly and with its role
+		product := &models.StripeProduct{ID: "prod_grants", Active: true, Name: "Grants Product", Metadata: map[string]string{}}
+		if err = adapter.Product().UpsertProduct(ctx, product); err != nil {
+			t.Fatalf("UpsertProduct() error = %v", err)
+		}
+		if err = rbacStore.CreateProductPermissions(ctx, product.ID, perm.ID); err != nil {
+			t.Fatalf("CreateProductPermissions() error = %v", err)
+		}
+		if err = rbacStore.CreateProductRoles(ctx, product.ID, role.ID); err != nil {
+			t.Fatalf("CreateProductRoles() error = %v", err)
+		}
+		price := &models.StripePrice{ID: "price_grants", ProductID: product.ID, Active: true, Currency: "usd", Type: models.StripePricingTypeRecurring, Metadata: map[string]string{}}
+		if err = adapter.Price().UpsertPrice(ctx, price); err != nil {
+			t.Fatalf("UpsertPrice() error = %v", err)
+		}
+		customer := &models.StripeCustomer{ID: "cus_grants", Email: user.Email, CustomerType: models.StripeCustomerTypeUser, UserID: &user.ID}
+		if _, err = adapter.Customer().CreateCustomer(ctx, customer); err != nil {
+			t.Fatalf("CreateCustomer() error = %v", err)
+		}
+		sub := &models.StripeSubscription{
+			ID:                 "sub_grants",
+			StripeCustomerID:   customer.ID,
+			Status:             models.StripeSubscriptionStatusActive,
+			Metadata:           map[string]string{},
+			ItemID:             "item_grants",
+			PriceID:            price.ID,
+			Quantity:           1,
+			Created:            time.Now(),
+			CurrentPeriodStart: time.Now(),
+			CurrentPeriodEnd:   time.Now().Add(30 * 24 * time.Hour),
+		}
+		if err = adapter.Subscription().UpsertSubscription(ctx, sub); err != nil {
+			t.Fatalf("UpsertSubscription() error = %v", err)
+		}
+
+		grants, err := rbacStore.ListUserPermissionGrants(ctx, user.ID, perm.Name)
+		if err != nil {
+			t.Fatalf("ListUserPermissionGrants() error = %v", err)
+		}
+		sources := map[models.PermissionGrantSource]int{}
+		for _, grant := range grants {
+			sources[grant.Source]++
+			if grant.Source == models.PermissionGrantSourceProduct && (grant.SubscriptionID == nil || *grant.SubscriptionID != sub.ID) {
+				t.Errorf("product grant subscription = %v, want %v", grant.SubscriptionID, sub.ID)
+			}
+		}
+		want := map[models.PermissionGrantSource]int{
+			models.PermissionGrantSourceDirect:  1,
+			models.PermissionGrantSourceRole:    1,
+			models.PermissionGrantSourceProduct: 2,
+		}
+		if !reflect.DeepEqual(sources, want) {
+			t.Errorf("ListUserPermissionGrants() sources = %v, want %v", sources, want)
+		}
+
+		grants, err = rbacStore.ListUserPermissionGrants(ctx, user.ID, "missing_permission")
+		if err != nil {
+			t.Fatalf("ListUserPermissionGrants() error = %v", err)
+		}
+		if len(grants) != 0 {
+			t.Errorf("ListUserPermissionGrants() = %v, want none", grants)
+		}
+	})
+}
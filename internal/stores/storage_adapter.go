@@ -21,6 +21,17 @@ type StorageAdapterInterface interface {
 	Rbac() DbRbacStoreInterface
 	Task() DbTaskStoreInterface
 	Job() JobStore
+	Game() GameStore
+	OidcProvider() OidcProviderStore
+	TeamSso() TeamSsoStore
+	TeamRole() TeamRoleStore
+	UserSession() UserSessionStore
+	LoginAttempt() LoginAttemptStore
+	Impersonation() ImpersonationStore
+	AuditLog() AuditLogStore
+	// Db returns the connection the stores run on. Inside RunInTx it is the
+	// transaction, so other components such as the job manager can take part in it.
+	Db() database.Dbx
 	// WithTx(tx database.Dbx) *StorageAdapter
 	RunInTx(fn func(tx StorageAdapterInterface) error) error
 }
@@ -42,6 +53,14 @@ type StorageAdapter struct {
 	notification   *DbNotificationStore
 	job            *DbJobStore
 	userReaction   *DbUserReactionStore
+	game           *DbGameStore
+	oidcProvider   *DbOidcProviderStore
+	teamSso        *DbTeamSsoStore
+	teamRole       *DbTeamRoleStore
+	userSession    *DbUserSessionStore
+	loginAttempt   *DbLoginAttemptStore
+	impersonation  *DbImpersonationStore
+	auditLog       *DbAuditLogStore
 }
 
 // UserReaction implements StorageAdapterInterface.
@@ -49,9 +68,46 @@ func (s *StorageAdapter) UserReaction() UserReactionStore {
 	return s.userReaction
 }
 
+// Db implements StorageAdapterInterface.
+func (s *StorageAdapter) Db() database.Dbx {
+	return s.db
+}
+
 func (s *StorageAdapter) Job() JobStore {
 	return s.job
 }
+
+func (s *StorageAdapter) Game() GameStore {
+	return s.game
+}
+
+func (s *StorageAdapter) OidcProvider() OidcProviderStore {
+	return s.oidcProvider
+}
+
+func (s *StorageAdapter) TeamSso() TeamSsoStore {
+	return s.teamSso
+}
+
+func (s *StorageAdapter) TeamRole() TeamRoleStore {
+	return s.teamRole
+}
+
+func (s *StorageAdapter) UserSession() UserSessionStore {
+	return s.userSession
+}
+
+func (s *StorageAdapter) LoginAttempt() LoginAttemptStore {
+	return s.loginAttempt
+}
+
+func (s *StorageAdapter) Impersonation() ImpersonationStore {
+	return s.impersonation
+}
+
+func (s *StorageAdapter) AuditLog() AuditLogStore {
+	return s.auditLog
+}
 func (s *StorageAdapter) Notification() NotificationStore {
 	return s.notification
 }
@@ -91,6 +147,19 @@ func (s *StorageAdapter) WithTx(tx database.Dbx) *StorageAdapter {
 		product:        s.product.WithTx(tx),
 		subscription:   s.subscription.WithTx(tx),
 		rbac:           s.rbac.WithTx(tx),
+		task:           s.task.WithTx(tx),
+		job:            NewDbJobStore(tx),
+		media:          NewMediaStore(tx),
+		notification:   NewDbNotificationStore(tx),
+		userReaction:   NewDbUserReactionStore(tx),
+		game:           NewDbGameStore(tx),
+		oidcProvider:   NewDbOidcProviderStore(tx),
+		teamSso:        NewDbTeamSsoStore(tx),
+		teamRole:       NewDbTeamRoleStore(tx),
+		userSession:    NewDbUserSessionStore(tx),
+		loginAttempt:   NewDbLoginAttemptStore(tx),
+		impersonation:  NewDbImpersonationStore(tx),
+		auditLog:       NewDbAuditLogStore(tx),
 	}
 }
 
@@ -160,5 +229,13 @@ func NewStorageAdapter(db database.Dbx) *StorageAdapter {
 		media:          NewMediaStore(db),
 		notification:   NewDbNotificationStore(db),
 		userReaction:   NewDbUserReactionStore(db),
+		game:           NewDbGameStore(db),
+		oidcProvider:   NewDbOidcProviderStore(db),
+		teamSso:        NewDbTeamSsoStore(db),
+		teamRole:       NewDbTeamRoleStore(db),
+		userSession:    NewDbUserSessionStore(db),
+		loginAttempt:   NewDbLoginAttemptStore(db),
+		impersonation:  NewDbImpersonationStore(db),
+		auditLog:       NewDbAuditLogStore(db),
 	}
 }
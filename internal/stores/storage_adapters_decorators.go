@@ -20,6 +20,14 @@ func NewAdapterDecorators() *StorageAdapterDecorator {
 		NotificationFunc:   &NotificationStoreDecorator{},
 		Delegate:           &StorageAdapter{},
 		JobFunc:            &JobStoreDecorator{},
+		GameFunc:           &GameStoreDecorator{},
+		OidcProviderFunc:   &OidcProviderStoreDecorator{},
+		TeamSsoFunc:        &TeamSsoStoreDecorator{},
+		UserSessionFunc:    &UserSessionStoreDecorator{},
+		LoginAttemptFunc:   &LoginAttemptStoreDecorator{},
+		TeamRoleFunc:       &TeamRoleStoreDecorator{},
+		ImpersonationFunc:  &ImpersonationStoreDecorator{},
+		AuditLogFunc:       &AuditLogStoreDecorator{},
 	}
 }
 
@@ -43,6 +51,30 @@ func NewDbAdapterDecorators(db database.Dbx) *StorageAdapterDecorator {
 		JobFunc: &JobStoreDecorator{
 			Delegate: NewDbJobStore(db),
 		},
+		GameFunc: &GameStoreDecorator{
+			Delegate: NewDbGameStore(db),
+		},
+		OidcProviderFunc: &OidcProviderStoreDecorator{
+			Delegate: NewDbOidcProviderStore(db),
+		},
+		TeamSsoFunc: &TeamSsoStoreDecorator{
+			Delegate: NewDbTeamSsoStore(db),
+		},
+		UserSessionFunc: &UserSessionStoreDecorator{
+			Delegate: NewDbUserSessionStore(db),
+		},
+		LoginAttemptFunc: &LoginAttemptStoreDecorator{
+			Delegate: NewDbLoginAttemptStore(db),
+		},
+		TeamRoleFunc: &TeamRoleStoreDecorator{
+			Delegate: NewDbTeamRoleStore(db),
+		},
+		ImpersonationFunc: &ImpersonationStoreDecorator{
+			Delegate: NewDbImpersonationStore(db),
+		},
+		AuditLogFunc: &AuditLogStoreDecorator{
+			Delegate: NewDbAuditLogStore(db),
+		},
 	}
 }
 
@@ -107,6 +139,14 @@ type StorageAdapterDecorator struct {
 	RunInTxFunc        func(fn func(tx StorageAdapterInterface) error) error
 	JobFunc            *JobStoreDecorator
 	UserReactionFunc   *DbUserReactionStoreDectorator
+	GameFunc           *GameStoreDecorator
+	OidcProviderFunc   *OidcProviderStoreDecorator
+	TeamSsoFunc        *TeamSsoStoreDecorator
+	UserSessionFunc    *UserSessionStoreDecorator
+	LoginAttemptFunc   *LoginAttemptStoreDecorator
+	TeamRoleFunc       *TeamRoleStoreDecorator
+	ImpersonationFunc  *ImpersonationStoreDecorator
+	AuditLogFunc       *AuditLogStoreDecorator
 }
 
 // UserReaction implements StorageAdapterInterface.
@@ -117,6 +157,11 @@ func (s *StorageAdapterDecorator) UserReaction() UserReactionStore {
 	return s.Delegate.UserReaction()
 }
 
+// Db implements StorageAdapterInterface.
+func (s *StorageAdapterDecorator) Db() database.Dbx {
+	return s.Delegate.Db()
+}
+
 // Job implements StorageAdapterInterface.
 func (s *StorageAdapterDecorator) Job() JobStore {
 	if s.JobFunc != nil {
@@ -125,6 +170,70 @@ func (s *StorageAdapterDecorator) Job() JobStore {
 	return s.Delegate.Job()
 }
 
+// Game implements StorageAdapterInterface.
+func (s *StorageAdapterDecorator) Game() GameStore {
+	if s.GameFunc != nil {
+		return s.GameFunc
+	}
+	return s.Delegate.Game()
+}
+
+// OidcProvider implements StorageAdapterInterface.
+func (s *StorageAdapterDecorator) OidcProvider() OidcProviderStore {
+	if s.OidcProviderFunc != nil {
+		return s.OidcProviderFunc
+	}
+	return s.Delegate.OidcProvider()
+}
+
+// UserSession implements StorageAdapterInterface.
+func (s *StorageAdapterDecorator) UserSession() UserSessionStore {
+	if s.UserSessionFunc != nil {
+		return s.UserSessionFunc
+	}
+	return s.Delegate.UserSession()
+}
+
+// LoginAttempt implements StorageAdapterInterface.
+func (s *StorageAdapterDecorator) LoginAttempt() LoginAttemptStore {
+	if s.LoginAttemptFunc != nil {
+		return s.LoginAttemptFunc
+	}
+	return s.Delegate.LoginAttempt()
+}
+
+// TeamSso implements StorageAdapterInterface.
+func (s *StorageAdapterDecorator) TeamSso() TeamSsoStore {
+	if s.TeamSsoFunc != nil {
+		return s.TeamSsoFunc
+	}
+	return s.Delegate.TeamSso()
+}
+
+// TeamRole implements StorageAdapterInterface.
+func (s *StorageAdapterDecorator) TeamRole() TeamRoleStore {
+	if s.TeamRoleFunc != nil {
+		return s.TeamRoleFunc
+	}
+	return s.Delegate.TeamRole()
+}
+
+// Impersonation implements StorageAdapterInterface.
+func (s *StorageAdapterDecorator) Impersonation() ImpersonationStore {
+	if s.ImpersonationFunc != nil {
+		return s.ImpersonationFunc
+	}
+	return s.Delegate.Impersonation()
+}
+
+// AuditLog implements StorageAdapterInterface.
+func (s *StorageAdapterDecorator) AuditLog() AuditLogStore {
+	if s.AuditLogFunc != nil {
+		return s.AuditLogFunc
+	}
+	return s.Delegate.AuditLog()
+}
+
 var _ StorageAdapterInterface = (*StorageAdapterDecorator)(nil)
 
 func (s *StorageAdapterDecorator) Notification() NotificationStore {
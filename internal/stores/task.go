@@ -10,6 +10,7 @@ import (
 
 	"github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/tkahng/playground/internal/database"
 	"github.com/tkahng/playground/internal/models"
 	"github.com/tkahng/playground/internal/repository"
@@ -47,6 +48,9 @@ type DbTaskStoreInterface interface { // size=16 (0x10)
 	WithTx(dbx database.Dbx) *DbTaskStore
 	GetTeamTaskStats(ctx context.Context, teamId uuid.UUID) (*models.TaskStats, error)
 	FindAndUpdateTask(ctx context.Context, taskID uuid.UUID, input *UpdateTaskDto) error
+	UpsertTaskProjectPresence(ctx context.Context, presence *models.TaskProjectPresence) (*models.TaskProjectPresence, error)
+	DeleteTaskProjectPresence(ctx context.Context, projectID uuid.UUID, teamMemberID uuid.UUID) (bool, error)
+	FindTaskProjectPresences(ctx context.Context, projectID uuid.UUID) ([]*models.TaskProjectPresence, error)
 }
 
 type DbTaskStore struct {
@@ -800,3 +804,78 @@ func (s *DbTaskStore) GetTeamTaskStats(ctx context.Context, teamId uuid.UUID) (*
 	}
 	return &res[0], nil
 }
+
+const UpsertTaskProjectPresenceQuery = `
+INSERT INTO task_project_presences (project_id, team_member_id, task_id, state, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (project_id, team_member_id) DO UPDATE
+SET task_id = EXCLUDED.task_id,
+    state = EXCLUDED.state,
+    expires_at = EXCLUDED.expires_at,
+    updated_at = now()
+RETURNING project_id, team_member_id, task_id, state, expires_at, updated_at
+`
+
+// UpsertTaskProjectPresence records the presence of a team member on a
+// project, replacing the previous one.
+func (s *DbTaskStore) UpsertTaskProjectPresence(ctx context.Context, presence *models.TaskProjectPresence) (*models.TaskProjectPresence, error) {
+	res, err := database.QueryAll[*models.TaskProjectPresence](
+		ctx,
+		s.db,
+		UpsertTaskProjectPresenceQuery,
+		presence.ProjectID,
+		presence.TeamMemberID,
+		presence.TaskID,
+		presence.State,
+		presence.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, nil
+	}
+	return res[0], nil
+}
+
+// DeleteTaskProjectPresence removes the presence of a team member and reports
+// whether it was still active.
+func (s *DbTaskStore) DeleteTaskProjectPresence(ctx context.Context, projectID uuid.UUID, teamMemberID uuid.UUID) (bool, error) {
+	var active bool
+	err := s.db.QueryRow(
+		ctx,
+		`DELETE FROM task_project_presences
+		WHERE project_id = $1 AND team_member_id = $2
+		RETURNING expires_at > now()`,
+		projectID,
+		teamMemberID,
+	).Scan(&active)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return active, err
+}
+
+const FindTaskProjectPresencesQuery = `
+SELECT project_id, team_member_id, task_id, state, expires_at, updated_at
+FROM task_project_presences
+WHERE project_id = $1
+    AND expires_at > now()
+ORDER BY updated_at
+`
+
+// FindTaskProjectPresences returns the presences of a project that have not
+// expired.
+func (s *DbTaskStore) FindTaskProjectPresences(ctx context.Context, projectID uuid.UUID) ([]*models.TaskProjectPresence, error) {
+	// expired rows are only kept until the next read
+	_, err := database.Exec(
+		ctx,
+		s.db,
+		`DELETE FROM task_project_presences WHERE project_id = $1 AND expires_at <= now()`,
+		projectID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return database.QueryAll[*models.TaskProjectPresence](ctx, s.db, FindTaskProjectPresencesQuery, projectID)
+}
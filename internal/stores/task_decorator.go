@@ -38,6 +38,42 @@ type TaskDecorator struct {
 	WithTxFunc                      func(dbx database.Dbx) *DbTaskStore
 	GetTeamTaskStatsFunc            func(ctx context.Context, teamId uuid.UUID) (*models.TaskStats, error)
 	FindAndUpdateTaskFunc           func(ctx context.Context, taskID uuid.UUID, input *UpdateTaskDto) error
+	UpsertTaskProjectPresenceFunc   func(ctx context.Context, presence *models.TaskProjectPresence) (*models.TaskProjectPresence, error)
+	DeleteTaskProjectPresenceFunc   func(ctx context.Context, projectID uuid.UUID, teamMemberID uuid.UUID) (bool, error)
+	FindTaskProjectPresencesFunc    func(ctx context.Context, projectID uuid.UUID) ([]*models.TaskProjectPresence, error)
+}
+
+// UpsertTaskProjectPresence implements DbTaskStoreInterface.
+func (t *TaskDecorator) UpsertTaskProjectPresence(ctx context.Context, presence *models.TaskProjectPresence) (*models.TaskProjectPresence, error) {
+	if t.UpsertTaskProjectPresenceFunc != nil {
+		return t.UpsertTaskProjectPresenceFunc(ctx, presence)
+	}
+	if t.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return t.Delegate.UpsertTaskProjectPresence(ctx, presence)
+}
+
+// DeleteTaskProjectPresence implements DbTaskStoreInterface.
+func (t *TaskDecorator) DeleteTaskProjectPresence(ctx context.Context, projectID uuid.UUID, teamMemberID uuid.UUID) (bool, error) {
+	if t.DeleteTaskProjectPresenceFunc != nil {
+		return t.DeleteTaskProjectPresenceFunc(ctx, projectID, teamMemberID)
+	}
+	if t.Delegate == nil {
+		return false, ErrDelegateNil
+	}
+	return t.Delegate.DeleteTaskProjectPresence(ctx, projectID, teamMemberID)
+}
+
+// FindTaskProjectPresences implements DbTaskStoreInterface.
+func (t *TaskDecorator) FindTaskProjectPresences(ctx context.Context, projectID uuid.UUID) ([]*models.TaskProjectPresence, error) {
+	if t.FindTaskProjectPresencesFunc != nil {
+		return t.FindTaskProjectPresencesFunc(ctx, projectID)
+	}
+	if t.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return t.Delegate.FindTaskProjectPresences(ctx, projectID)
 }
 
 // FindAndUpdateTask implements DbTaskStoreInterface.
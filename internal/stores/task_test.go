@@ -4,6 +4,7 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/tkahng/playground/internal/database"
@@ -3473,3 +3474,89 @@ func TestCreateTaskFromInput(t *testing.T) {
 // 		return test.EndTestErr
 // 	})
 // }
+
+func TestTaskProjectPresence(t *testing.T) {
+	test.Parallel(t)
+	test.SkipIfShort(t)
+	test.WithTx(t, func(ctx context.Context, db database.Dbx) {
+		adapter := stores.NewStorageAdapter(db)
+		user := CreateUser(adapter, ctx, "presence@example.com")
+		team := CreateTeam(adapter, ctx, "PresenceTeam")
+		member := CreateTeamMember(adapter, ctx, team, user, models.TeamMemberRoleOwner, true)
+		project := CreateTeamProject(adapter, ctx, member, "Presence Project", "Presence Project")
+		task := CreateTask(adapter, ctx, &models.Task{
+			ProjectID:         project.ID,
+			Name:              "One",
+			Status:            models.TaskStatusTodo,
+			CreatedByMemberID: types.Pointer(member.ID),
+			TeamID:            team.ID,
+		})
+
+		presence, err := adapter.Task().UpsertTaskProjectPresence(ctx, &models.TaskProjectPresence{
+			ProjectID:    project.ID,
+			TeamMemberID: member.ID,
+			State:        models.TaskPresenceStateViewing,
+			ExpiresAt:    time.Now().Add(time.Minute),
+		})
+		if err != nil {
+			t.Fatalf("UpsertTaskProjectPresence() error = %v", err)
+		}
+		if presence.TaskID != nil {
+			t.Fatalf("expected no task, got %v", presence.TaskID)
+		}
+
+		presence, err = adapter.Task().UpsertTaskProjectPresence(ctx, &models.TaskProjectPresence{
+			ProjectID:    project.ID,
+			TeamMemberID: member.ID,
+			TaskID:       types.Pointer(task.ID),
+			State:        models.TaskPresenceStateEditing,
+			ExpiresAt:    time.Now().Add(time.Minute),
+		})
+		if err != nil {
+			t.Fatalf("UpsertTaskProjectPresence() error = %v", err)
+		}
+		if presence.State != models.TaskPresenceStateEditing || presence.TaskID == nil || *presence.TaskID != task.ID {
+			t.Fatalf("expected member to be editing task, got %+v", presence)
+		}
+
+		presences, err := adapter.Task().FindTaskProjectPresences(ctx, project.ID)
+		if err != nil {
+			t.Fatalf("FindTaskProjectPresences() error = %v", err)
+		}
+		if len(presences) != 1 {
+			t.Fatalf("expected 1 presence, got %d", len(presences))
+		}
+
+		left, err := adapter.Task().DeleteTaskProjectPresence(ctx, project.ID, member.ID)
+		if err != nil {
+			t.Fatalf("DeleteTaskProjectPresence() error = %v", err)
+		}
+		if !left {
+			t.Fatal("expected active presence to be deleted")
+		}
+		left, err = adapter.Task().DeleteTaskProjectPresence(ctx, project.ID, member.ID)
+		if err != nil {
+			t.Fatalf("DeleteTaskProjectPresence() error = %v", err)
+		}
+		if left {
+			t.Fatal("expected no presence to be deleted")
+		}
+
+		_, err = adapter.Task().UpsertTaskProjectPresence(ctx, &models.TaskProjectPresence{
+			ProjectID:    project.ID,
+			TeamMemberID: member.ID,
+			State:        models.TaskPresenceStateViewing,
+			ExpiresAt:    time.Now().Add(-time.Minute),
+		})
+		if err != nil {
+			t.Fatalf("UpsertTaskProjectPresence() error = %v", err)
+		}
+		presences, err = adapter.Task().FindTaskProjectPresences(ctx, project.ID)
+		if err != nil {
+			t.Fatalf("FindTaskProjectPresences() error = %v", err)
+		}
+		if len(presences) != 0 {
+			t.Fatalf("expected expired presence to be dropped, got %d", len(presences))
+		}
+	})
+}
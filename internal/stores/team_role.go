@@ -0,0 +1,171 @@
+package stores
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/database"
+	"github.com/tkahng/playground/internal/models"
+)
+
+type TeamRoleStore interface {
+	// ListTeamRoles returns the roles of the team and the default roles it
+	// does not override.
+	ListTeamRoles(ctx context.Context, teamID uuid.UUID) ([]*models.TeamRole, error)
+	// FindTeamRole returns a role of the team or a default role, or nil.
+	FindTeamRole(ctx context.Context, teamID uuid.UUID, id uuid.UUID) (*models.TeamRole, error)
+	CreateTeamRole(ctx context.Context, role *models.TeamRole) (*models.TeamRole, error)
+	// UpdateTeamRole only updates roles of the team of the role, it returns
+	// nil for default roles.
+	UpdateTeamRole(ctx context.Context, role *models.TeamRole) (*models.TeamRole, error)
+	DeleteTeamRole(ctx context.Context, teamID uuid.UUID, id uuid.UUID) error
+	ListTeamRoleAssignments(ctx context.Context, teamMemberID uuid.UUID) ([]*models.TeamRoleAssignment, error)
+	CreateTeamRoleAssignment(ctx context.Context, assignment *models.TeamRoleAssignment) (*models.TeamRoleAssignment, error)
+	DeleteTeamRoleAssignment(ctx context.Context, teamMemberID uuid.UUID, id uuid.UUID) error
+	// ListTeamMemberPermissions returns the permissions of the role of the
+	// member and of the roles assigned to the member in the team, or in the
+	// task project when projectID is not nil.
+	ListTeamMemberPermissions(ctx context.Context, member *models.TeamMember, projectID *uuid.UUID) ([]string, error)
+}
+
+type DbTeamRoleStore struct {
+	db database.Dbx
+}
+
+var _ TeamRoleStore = (*DbTeamRoleStore)(nil)
+
+func NewDbTeamRoleStore(db database.Dbx) *DbTeamRoleStore {
+	return &DbTeamRoleStore{
+		db: db,
+	}
+}
+
+const (
+	teamRoleColumns           = `id, team_id, name, description, permissions, created_at, updated_at`
+	teamRoleAssignmentColumns = `id, team_member_id, team_role_id, task_project_id, created_at`
+)
+
+// ListTeamRoles implements TeamRoleStore.
+func (s *DbTeamRoleStore) ListTeamRoles(ctx context.Context, teamID uuid.UUID) ([]*models.TeamRole, error) {
+	return database.QueryAll[*models.TeamRole](
+		ctx,
+		s.db,
+		`SELECT DISTINCT ON (name) `+teamRoleColumns+` FROM team_roles
+		WHERE team_id = $1 OR team_id IS NULL
+		ORDER BY name, team_id NULLS LAST`,
+		teamID,
+	)
+}
+
+// FindTeamRole implements TeamRoleStore.
+func (s *DbTeamRoleStore) FindTeamRole(ctx context.Context, teamID uuid.UUID, id uuid.UUID) (*models.TeamRole, error) {
+	return database.One[*models.TeamRole](
+		ctx,
+		s.db,
+		`SELECT `+teamRoleColumns+` FROM team_roles WHERE id = $2 AND (team_id = $1 OR team_id IS NULL)`,
+		teamID,
+		id,
+	)
+}
+
+// CreateTeamRole implements TeamRoleStore.
+func (s *DbTeamRoleStore) CreateTeamRole(ctx context.Context, role *models.TeamRole) (*models.TeamRole, error) {
+	return database.One[*models.TeamRole](
+		ctx,
+		s.db,
+		`INSERT INTO team_roles (team_id, name, description, permissions)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+teamRoleColumns,
+		role.TeamID,
+		role.Name,
+		role.Description,
+		role.Permissions,
+	)
+}
+
+// UpdateTeamRole implements TeamRoleStore.
+func (s *DbTeamRoleStore) UpdateTeamRole(ctx context.Context, role *models.TeamRole) (*models.TeamRole, error) {
+	return database.One[*models.TeamRole](
+		ctx,
+		s.db,
+		`UPDATE team_roles SET name = $3, description = $4, permissions = $5
+		WHERE id = $2 AND team_id = $1
+		RETURNING `+teamRoleColumns,
+		role.TeamID,
+		role.ID,
+		role.Name,
+		role.Description,
+		role.Permissions,
+	)
+}
+
+// DeleteTeamRole implements TeamRoleStore.
+func (s *DbTeamRoleStore) DeleteTeamRole(ctx context.Context, teamID uuid.UUID, id uuid.UUID) error {
+	_, err := database.Exec(ctx, s.db, `DELETE FROM team_roles WHERE team_id = $1 AND id = $2`, teamID, id)
+	return err
+}
+
+// ListTeamRoleAssignments implements TeamRoleStore.
+func (s *DbTeamRoleStore) ListTeamRoleAssignments(ctx context.Context, teamMemberID uuid.UUID) ([]*models.TeamRoleAssignment, error) {
+	return database.QueryAll[*models.TeamRoleAssignment](
+		ctx,
+		s.db,
+		`SELECT `+teamRoleAssignmentColumns+` FROM team_role_assignments WHERE team_member_id = $1 ORDER BY created_at`,
+		teamMemberID,
+	)
+}
+
+// CreateTeamRoleAssignment implements TeamRoleStore.
+func (s *DbTeamRoleStore) CreateTeamRoleAssignment(ctx context.Context, assignment *models.TeamRoleAssignment) (*models.TeamRoleAssignment, error) {
+	return database.One[*models.TeamRoleAssignment](
+		ctx,
+		s.db,
+		`INSERT INTO team_role_assignments (team_member_id, team_role_id, task_project_id)
+		VALUES ($1, $2, $3)
+		RETURNING `+teamRoleAssignmentColumns,
+		assignment.TeamMemberID,
+		assignment.TeamRoleID,
+		assignment.TaskProjectID,
+	)
+}
+
+// DeleteTeamRoleAssignment implements TeamRoleStore.
+func (s *DbTeamRoleStore) DeleteTeamRoleAssignment(ctx context.Context, teamMemberID uuid.UUID, id uuid.UUID) error {
+	_, err := database.Exec(ctx, s.db, `DELETE FROM team_role_assignments WHERE team_member_id = $1 AND id = $2`, teamMemberID, id)
+	return err
+}
+
+// ListTeamMemberPermissions implements TeamRoleStore.
+func (s *DbTeamRoleStore) ListTeamMemberPermissions(ctx context.Context, member *models.TeamMember, projectID *uuid.UUID) ([]string, error) {
+	type memberPermissions struct {
+		Permissions []string `db:"permissions"`
+	}
+	res, err := database.One[*memberPermissions](
+		ctx,
+		s.db,
+		`SELECT COALESCE(array_agg(DISTINCT permission ORDER BY permission), '{}') AS permissions
+		FROM (
+			(
+				SELECT permissions FROM team_roles
+				WHERE name = $3 AND (team_id = $1 OR team_id IS NULL)
+				ORDER BY team_id NULLS LAST
+				LIMIT 1
+			)
+			UNION ALL
+			SELECT r.permissions FROM team_role_assignments a
+			JOIN team_roles r ON r.id = a.team_role_id
+			WHERE a.team_member_id = $2 AND (a.task_project_id IS NULL OR a.task_project_id = $4)
+		) roles, unnest(roles.permissions) AS permission`,
+		member.TeamID,
+		member.ID,
+		member.Role,
+		projectID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, nil
+	}
+	return res.Permissions, nil
+}
@@ -0,0 +1,122 @@
+package stores
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/models"
+)
+
+type TeamRoleStoreDecorator struct {
+	Delegate                      *DbTeamRoleStore
+	ListTeamRolesFunc             func(ctx context.Context, teamID uuid.UUID) ([]*models.TeamRole, error)
+	FindTeamRoleFunc              func(ctx context.Context, teamID uuid.UUID, id uuid.UUID) (*models.TeamRole, error)
+	CreateTeamRoleFunc            func(ctx context.Context, role *models.TeamRole) (*models.TeamRole, error)
+	UpdateTeamRoleFunc            func(ctx context.Context, role *models.TeamRole) (*models.TeamRole, error)
+	DeleteTeamRoleFunc            func(ctx context.Context, teamID uuid.UUID, id uuid.UUID) error
+	ListTeamRoleAssignmentsFunc   func(ctx context.Context, teamMemberID uuid.UUID) ([]*models.TeamRoleAssignment, error)
+	CreateTeamRoleAssignmentFunc  func(ctx context.Context, assignment *models.TeamRoleAssignment) (*models.TeamRoleAssignment, error)
+	DeleteTeamRoleAssignmentFunc  func(ctx context.Context, teamMemberID uuid.UUID, id uuid.UUID) error
+	ListTeamMemberPermissionsFunc func(ctx context.Context, member *models.TeamMember, projectID *uuid.UUID) ([]string, error)
+}
+
+var _ TeamRoleStore = (*TeamRoleStoreDecorator)(nil)
+
+// ListTeamRoles implements TeamRoleStore.
+func (t *TeamRoleStoreDecorator) ListTeamRoles(ctx context.Context, teamID uuid.UUID) ([]*models.TeamRole, error) {
+	if t.ListTeamRolesFunc != nil {
+		return t.ListTeamRolesFunc(ctx, teamID)
+	}
+	if t.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return t.Delegate.ListTeamRoles(ctx, teamID)
+}
+
+// FindTeamRole implements TeamRoleStore.
+func (t *TeamRoleStoreDecorator) FindTeamRole(ctx context.Context, teamID uuid.UUID, id uuid.UUID) (*models.TeamRole, error) {
+	if t.FindTeamRoleFunc != nil {
+		return t.FindTeamRoleFunc(ctx, teamID, id)
+	}
+	if t.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return t.Delegate.FindTeamRole(ctx, teamID, id)
+}
+
+// CreateTeamRole implements TeamRoleStore.
+func (t *TeamRoleStoreDecorator) CreateTeamRole(ctx context.Context, role *models.TeamRole) (*models.TeamRole, error) {
+	if t.CreateTeamRoleFunc != nil {
+		return t.CreateTeamRoleFunc(ctx, role)
+	}
+	if t.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return t.Delegate.CreateTeamRole(ctx, role)
+}
+
+// UpdateTeamRole implements TeamRoleStore.
+func (t *TeamRoleStoreDecorator) UpdateTeamRole(ctx context.Context, role *models.TeamRole) (*models.TeamRole, error) {
+	if t.UpdateTeamRoleFunc != nil {
+		return t.UpdateTeamRoleFunc(ctx, role)
+	}
+	if t.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return t.Delegate.UpdateTeamRole(ctx, role)
+}
+
+// DeleteTeamRole implements TeamRoleStore.
+func (t *TeamRoleStoreDecorator) DeleteTeamRole(ctx context.Context, teamID uuid.UUID, id uuid.UUID) error {
+	if t.DeleteTeamRoleFunc != nil {
+		return t.DeleteTeamRoleFunc(ctx, teamID, id)
+	}
+	if t.Delegate == nil {
+		return ErrDelegateNil
+	}
+	return t.Delegate.DeleteTeamRole(ctx, teamID, id)
+}
+
+// ListTeamRoleAssignments implements TeamRoleStore.
+func (t *TeamRoleStoreDecorator) ListTeamRoleAssignments(ctx context.Context, teamMemberID uuid.UUID) ([]*models.TeamRoleAssignment, error) {
+	if t.ListTeamRoleAssignmentsFunc != nil {
+		return t.ListTeamRoleAssignmentsFunc(ctx, teamMemberID)
+	}
+	if t.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return t.Delegate.ListTeamRoleAssignments(ctx, teamMemberID)
+}
+
+// CreateTeamRoleAssignment implements TeamRoleStore.
+func (t *TeamRoleStoreDecorator) CreateTeamRoleAssignment(ctx context.Context, assignment *models.TeamRoleAssignment) (*models.TeamRoleAssignment, error) {
+	if t.CreateTeamRoleAssignmentFunc != nil {
+		return t.CreateTeamRoleAssignmentFunc(ctx, assignment)
+	}
+	if t.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return t.Delegate.CreateTeamRoleAssignment(ctx, assignment)
+}
+
+// DeleteTeamRoleAssignment implements TeamRoleStore.
+func (t *TeamRoleStoreDecorator) DeleteTeamRoleAssignment(ctx context.Context, teamMemberID uuid.UUID, id uuid.UUID) error {
+	if t.DeleteTeamRoleAssignmentFunc != nil {
+		return t.DeleteTeamRoleAssignmentFunc(ctx, teamMemberID, id)
+	}
+	if t.Delegate == nil {
+		return ErrDelegateNil
+	}
+	return t.Delegate.DeleteTeamRoleAssignment(ctx, teamMemberID, id)
+}
+
+// ListTeamMemberPermissions implements TeamRoleStore.
+func (t *TeamRoleStoreDecorator) ListTeamMemberPermissions(ctx context.Context, member *models.TeamMember, projectID *uuid.UUID) ([]string, error) {
+	if t.ListTeamMemberPermissionsFunc != nil {
+		return t.ListTeamMemberPermissionsFunc(ctx, member, projectID)
+	}
+	if t.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return t.Delegate.ListTeamMemberPermissions(ctx, member, projectID)
+}
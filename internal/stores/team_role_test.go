@@ -0,0 +1,60 @@
+package stores_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkahng/playground/internal/database"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/stores"
+	"github.com/tkahng/playground/internal/test"
+)
+
+func TestTeamRoleStore(t *testing.T) {
+	test.Parallel(t)
+	test.SkipIfShort(t)
+	test.WithTx(t, func(ctx context.Context, db database.Dbx) {
+		adapter := stores.NewStorageAdapter(db)
+		store := stores.NewDbTeamRoleStore(db)
+		team := CreateTeam(adapter, ctx, "RolesTeam")
+		owner := CreateTeamMember(adapter, ctx, team, CreateUser(adapter, ctx, "roles-owner@example.com"), models.TeamMemberRoleOwner, true)
+		guest := CreateTeamMember(adapter, ctx, team, CreateUser(adapter, ctx, "roles-guest@example.com"), models.TeamMemberRoleGuest, false)
+		project := CreateTeamProject(adapter, ctx, owner, "Roles Project", "Roles Project")
+
+		roles, err := store.ListTeamRoles(ctx, team.ID)
+		require.NoError(t, err)
+		require.Len(t, roles, 3, "the default roles")
+
+		// the team overrides the guest role
+		_, err = store.CreateTeamRole(ctx, &models.TeamRole{TeamID: &team.ID, Name: "guest", Permissions: []string{models.TeamPermissionTaskUpdate}})
+		require.NoError(t, err)
+		reviewer, err := store.CreateTeamRole(ctx, &models.TeamRole{TeamID: &team.ID, Name: "reviewer", Permissions: []string{models.TeamPermissionTaskDelete}})
+		require.NoError(t, err)
+		roles, err = store.ListTeamRoles(ctx, team.ID)
+		require.NoError(t, err)
+		require.Len(t, roles, 4)
+
+		permissions, err := store.ListTeamMemberPermissions(ctx, guest, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{models.TeamPermissionTaskUpdate}, permissions)
+
+		_, err = store.CreateTeamRoleAssignment(ctx, &models.TeamRoleAssignment{TeamMemberID: guest.ID, TeamRoleID: reviewer.ID, TaskProjectID: &project.ID})
+		require.NoError(t, err)
+		_, err = store.CreateTeamRoleAssignment(ctx, &models.TeamRoleAssignment{TeamMemberID: guest.ID, TeamRoleID: reviewer.ID, TaskProjectID: &project.ID})
+		assert.True(t, database.IsUniqConstraintErr(err))
+
+		permissions, err = store.ListTeamMemberPermissions(ctx, guest, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{models.TeamPermissionTaskUpdate}, permissions, "project roles only apply in the project")
+		permissions, err = store.ListTeamMemberPermissions(ctx, guest, &project.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{models.TeamPermissionTaskDelete, models.TeamPermissionTaskUpdate}, permissions)
+
+		require.NoError(t, store.DeleteTeamRole(ctx, team.ID, reviewer.ID))
+		assignments, err := store.ListTeamRoleAssignments(ctx, guest.ID)
+		require.NoError(t, err)
+		assert.Empty(t, assignments)
+	})
+}
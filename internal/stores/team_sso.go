@@ -0,0 +1,146 @@
+package stores
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/database"
+	"github.com/tkahng/playground/internal/models"
+)
+
+type TeamSsoStore interface {
+	// FindTeamSsoConfig returns nil when the team has no IdP.
+	FindTeamSsoConfig(ctx context.Context, teamID uuid.UUID) (*models.TeamSsoConfig, error)
+	SaveTeamSsoConfig(ctx context.Context, config *models.TeamSsoConfig) (*models.TeamSsoConfig, error)
+	DeleteTeamSsoConfig(ctx context.Context, teamID uuid.UUID) error
+	ListTeamSsoDomains(ctx context.Context, teamID uuid.UUID) ([]*models.TeamSsoDomain, error)
+	// FindTeamSsoDomain returns nil when the team has no domain with the id.
+	FindTeamSsoDomain(ctx context.Context, teamID uuid.UUID, id uuid.UUID) (*models.TeamSsoDomain, error)
+	// FindVerifiedTeamSsoDomain returns the team that verified the domain,
+	// or nil.
+	FindVerifiedTeamSsoDomain(ctx context.Context, domain string) (*models.TeamSsoDomain, error)
+	CreateTeamSsoDomain(ctx context.Context, domain *models.TeamSsoDomain) (*models.TeamSsoDomain, error)
+	VerifyTeamSsoDomain(ctx context.Context, id uuid.UUID) (*models.TeamSsoDomain, error)
+	DeleteTeamSsoDomain(ctx context.Context, teamID uuid.UUID, id uuid.UUID) error
+}
+
+type DbTeamSsoStore struct {
+	db database.Dbx
+}
+
+var _ TeamSsoStore = (*DbTeamSsoStore)(nil)
+
+func NewDbTeamSsoStore(db database.Dbx) *DbTeamSsoStore {
+	return &DbTeamSsoStore{
+		db: db,
+	}
+}
+
+const (
+	teamSsoConfigColumns = `team_id, issuer, client_id, client_secret, scopes, default_role, enforced, created_at, updated_at`
+	teamSsoDomainColumns = `id, team_id, domain, verification_token, verified_at, created_at`
+)
+
+// FindTeamSsoConfig implements TeamSsoStore.
+func (s *DbTeamSsoStore) FindTeamSsoConfig(ctx context.Context, teamID uuid.UUID) (*models.TeamSsoConfig, error) {
+	return database.One[*models.TeamSsoConfig](
+		ctx,
+		s.db,
+		`SELECT `+teamSsoConfigColumns+` FROM team_sso_configs WHERE team_id = $1`,
+		teamID,
+	)
+}
+
+// SaveTeamSsoConfig implements TeamSsoStore.
+func (s *DbTeamSsoStore) SaveTeamSsoConfig(ctx context.Context, config *models.TeamSsoConfig) (*models.TeamSsoConfig, error) {
+	return database.One[*models.TeamSsoConfig](
+		ctx,
+		s.db,
+		`INSERT INTO team_sso_configs (team_id, issuer, client_id, client_secret, scopes, default_role, enforced)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (team_id) DO UPDATE SET
+			issuer = EXCLUDED.issuer,
+			client_id = EXCLUDED.client_id,
+			client_secret = EXCLUDED.client_secret,
+			scopes = EXCLUDED.scopes,
+			default_role = EXCLUDED.default_role,
+			enforced = EXCLUDED.enforced
+		RETURNING `+teamSsoConfigColumns,
+		config.TeamID,
+		config.Issuer,
+		config.ClientID,
+		config.ClientSecret,
+		config.Scopes,
+		config.DefaultRole,
+		config.Enforced,
+	)
+}
+
+// DeleteTeamSsoConfig implements TeamSsoStore.
+func (s *DbTeamSsoStore) DeleteTeamSsoConfig(ctx context.Context, teamID uuid.UUID) error {
+	_, err := database.Exec(ctx, s.db, `DELETE FROM team_sso_configs WHERE team_id = $1`, teamID)
+	return err
+}
+
+// ListTeamSsoDomains implements TeamSsoStore.
+func (s *DbTeamSsoStore) ListTeamSsoDomains(ctx context.Context, teamID uuid.UUID) ([]*models.TeamSsoDomain, error) {
+	return database.QueryAll[*models.TeamSsoDomain](
+		ctx,
+		s.db,
+		`SELECT `+teamSsoDomainColumns+` FROM team_sso_domains WHERE team_id = $1 ORDER BY domain`,
+		teamID,
+	)
+}
+
+// FindTeamSsoDomain implements TeamSsoStore.
+func (s *DbTeamSsoStore) FindTeamSsoDomain(ctx context.Context, teamID uuid.UUID, id uuid.UUID) (*models.TeamSsoDomain, error) {
+	return database.One[*models.TeamSsoDomain](
+		ctx,
+		s.db,
+		`SELECT `+teamSsoDomainColumns+` FROM team_sso_domains WHERE team_id = $1 AND id = $2`,
+		teamID,
+		id,
+	)
+}
+
+// FindVerifiedTeamSsoDomain implements TeamSsoStore.
+func (s *DbTeamSsoStore) FindVerifiedTeamSsoDomain(ctx context.Context, domain string) (*models.TeamSsoDomain, error) {
+	return database.One[*models.TeamSsoDomain](
+		ctx,
+		s.db,
+		`SELECT `+teamSsoDomainColumns+` FROM team_sso_domains WHERE domain = $1 AND verified_at IS NOT NULL`,
+		domain,
+	)
+}
+
+// CreateTeamSsoDomain implements TeamSsoStore.
+func (s *DbTeamSsoStore) CreateTeamSsoDomain(ctx context.Context, domain *models.TeamSsoDomain) (*models.TeamSsoDomain, error) {
+	return database.One[*models.TeamSsoDomain](
+		ctx,
+		s.db,
+		`INSERT INTO team_sso_domains (team_id, domain, verification_token)
+		VALUES ($1, $2, $3)
+		RETURNING `+teamSsoDomainColumns,
+		domain.TeamID,
+		domain.Domain,
+		domain.VerificationToken,
+	)
+}
+
+// VerifyTeamSsoDomain implements TeamSsoStore.
+func (s *DbTeamSsoStore) VerifyTeamSsoDomain(ctx context.Context, id uuid.UUID) (*models.TeamSsoDomain, error) {
+	return database.One[*models.TeamSsoDomain](
+		ctx,
+		s.db,
+		`UPDATE team_sso_domains SET verified_at = now()
+		WHERE id = $1
+		RETURNING `+teamSsoDomainColumns,
+		id,
+	)
+}
+
+// DeleteTeamSsoDomain implements TeamSsoStore.
+func (s *DbTeamSsoStore) DeleteTeamSsoDomain(ctx context.Context, teamID uuid.UUID, id uuid.UUID) error {
+	_, err := database.Exec(ctx, s.db, `DELETE FROM team_sso_domains WHERE team_id = $1 AND id = $2`, teamID, id)
+	return err
+}
@@ -0,0 +1,122 @@
+package stores
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/models"
+)
+
+type TeamSsoStoreDecorator struct {
+	Delegate                      *DbTeamSsoStore
+	FindTeamSsoConfigFunc         func(ctx context.Context, teamID uuid.UUID) (*models.TeamSsoConfig, error)
+	SaveTeamSsoConfigFunc         func(ctx context.Context, config *models.TeamSsoConfig) (*models.TeamSsoConfig, error)
+	DeleteTeamSsoConfigFunc       func(ctx context.Context, teamID uuid.UUID) error
+	ListTeamSsoDomainsFunc        func(ctx context.Context, teamID uuid.UUID) ([]*models.TeamSsoDomain, error)
+	FindTeamSsoDomainFunc         func(ctx context.Context, teamID uuid.UUID, id uuid.UUID) (*models.TeamSsoDomain, error)
+	FindVerifiedTeamSsoDomainFunc func(ctx context.Context, domain string) (*models.TeamSsoDomain, error)
+	CreateTeamSsoDomainFunc       func(ctx context.Context, domain *models.TeamSsoDomain) (*models.TeamSsoDomain, error)
+	VerifyTeamSsoDomainFunc       func(ctx context.Context, id uuid.UUID) (*models.TeamSsoDomain, error)
+	DeleteTeamSsoDomainFunc       func(ctx context.Context, teamID uuid.UUID, id uuid.UUID) error
+}
+
+var _ TeamSsoStore = (*TeamSsoStoreDecorator)(nil)
+
+// FindTeamSsoConfig implements TeamSsoStore.
+func (t *TeamSsoStoreDecorator) FindTeamSsoConfig(ctx context.Context, teamID uuid.UUID) (*models.TeamSsoConfig, error) {
+	if t.FindTeamSsoConfigFunc != nil {
+		return t.FindTeamSsoConfigFunc(ctx, teamID)
+	}
+	if t.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return t.Delegate.FindTeamSsoConfig(ctx, teamID)
+}
+
+// SaveTeamSsoConfig implements TeamSsoStore.
+func (t *TeamSsoStoreDecorator) SaveTeamSsoConfig(ctx context.Context, config *models.TeamSsoConfig) (*models.TeamSsoConfig, error) {
+	if t.SaveTeamSsoConfigFunc != nil {
+		return t.SaveTeamSsoConfigFunc(ctx, config)
+	}
+	if t.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return t.Delegate.SaveTeamSsoConfig(ctx, config)
+}
+
+// DeleteTeamSsoConfig implements TeamSsoStore.
+func (t *TeamSsoStoreDecorator) DeleteTeamSsoConfig(ctx context.Context, teamID uuid.UUID) error {
+	if t.DeleteTeamSsoConfigFunc != nil {
+		return t.DeleteTeamSsoConfigFunc(ctx, teamID)
+	}
+	if t.Delegate == nil {
+		return ErrDelegateNil
+	}
+	return t.Delegate.DeleteTeamSsoConfig(ctx, teamID)
+}
+
+// ListTeamSsoDomains implements TeamSsoStore.
+func (t *TeamSsoStoreDecorator) ListTeamSsoDomains(ctx context.Context, teamID uuid.UUID) ([]*models.TeamSsoDomain, error) {
+	if t.ListTeamSsoDomainsFunc != nil {
+		return t.ListTeamSsoDomainsFunc(ctx, teamID)
+	}
+	if t.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return t.Delegate.ListTeamSsoDomains(ctx, teamID)
+}
+
+// FindTeamSsoDomain implements TeamSsoStore.
+func (t *TeamSsoStoreDecorator) FindTeamSsoDomain(ctx context.Context, teamID uuid.UUID, id uuid.UUID) (*models.TeamSsoDomain, error) {
+	if t.FindTeamSsoDomainFunc != nil {
+		return t.FindTeamSsoDomainFunc(ctx, teamID, id)
+	}
+	if t.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return t.Delegate.FindTeamSsoDomain(ctx, teamID, id)
+}
+
+// FindVerifiedTeamSsoDomain implements TeamSsoStore.
+func (t *TeamSsoStoreDecorator) FindVerifiedTeamSsoDomain(ctx context.Context, domain string) (*models.TeamSsoDomain, error) {
+	if t.FindVerifiedTeamSsoDomainFunc != nil {
+		return t.FindVerifiedTeamSsoDomainFunc(ctx, domain)
+	}
+	if t.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return t.Delegate.FindVerifiedTeamSsoDomain(ctx, domain)
+}
+
+// CreateTeamSsoDomain implements TeamSsoStore.
+func (t *TeamSsoStoreDecorator) CreateTeamSsoDomain(ctx context.Context, domain *models.TeamSsoDomain) (*models.TeamSsoDomain, error) {
+	if t.CreateTeamSsoDomainFunc != nil {
+		return t.CreateTeamSsoDomainFunc(ctx, domain)
+	}
+	if t.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return t.Delegate.CreateTeamSsoDomain(ctx, domain)
+}
+
+// VerifyTeamSsoDomain implements TeamSsoStore.
+func (t *TeamSsoStoreDecorator) VerifyTeamSsoDomain(ctx context.Context, id uuid.UUID) (*models.TeamSsoDomain, error) {
+	if t.VerifyTeamSsoDomainFunc != nil {
+		return t.VerifyTeamSsoDomainFunc(ctx, id)
+	}
+	if t.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return t.Delegate.VerifyTeamSsoDomain(ctx, id)
+}
+
+// DeleteTeamSsoDomain implements TeamSsoStore.
+func (t *TeamSsoStoreDecorator) DeleteTeamSsoDomain(ctx context.Context, teamID uuid.UUID, id uuid.UUID) error {
+	if t.DeleteTeamSsoDomainFunc != nil {
+		return t.DeleteTeamSsoDomainFunc(ctx, teamID, id)
+	}
+	if t.Delegate == nil {
+		return ErrDelegateNil
+	}
+	return t.Delegate.DeleteTeamSsoDomain(ctx, teamID, id)
+}
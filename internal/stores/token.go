@@ -20,12 +20,27 @@ type CreateTokenDTO struct {
 	ID         *uuid.UUID        `db:"id" json:"id"`
 	UserID     *uuid.UUID        `db:"user_id" json:"user_id"`
 	Otp        *string           `db:"otp" json:"otp"`
+	SessionID  *uuid.UUID        `db:"session_id" json:"session_id"`
 }
 
 type DbTokenStoreInterface interface {
 	GetToken(ctx context.Context, token string) (*models.Token, error)
 	SaveToken(ctx context.Context, token *CreateTokenDTO) error
 	DeleteToken(ctx context.Context, token string) error
+	// UseToken marks a token as used, it returns false when it already was.
+	UseToken(ctx context.Context, token string) (bool, error)
+	// FindOtpToken returns the unexpired token of the type with the one time
+	// code sent to the identifier, nil when there is none.
+	FindOtpToken(ctx context.Context, tokenType models.TokenTypes, identifier string, otp string) (*models.Token, error)
+	// FindNextSessionToken returns the first token of the type of the session
+	// created after createdAfter, nil when there is none.
+	FindNextSessionToken(ctx context.Context, sessionID uuid.UUID, tokenType models.TokenTypes, createdAfter time.Time) (*models.Token, error)
+	// DeleteUsedSessionTokens deletes the tokens of the type of the session
+	// that were used before usedBefore.
+	DeleteUsedSessionTokens(ctx context.Context, sessionID uuid.UUID, tokenType models.TokenTypes, usedBefore time.Time) error
+	// DeleteIdentifierTokens deletes the tokens of the type sent to the
+	// identifier.
+	DeleteIdentifierTokens(ctx context.Context, tokenType models.TokenTypes, identifier string) error
 	VerifyTokenStorage(ctx context.Context, token string) error
 }
 
@@ -72,6 +87,7 @@ func (a *DbTokenStore) SaveToken(ctx context.Context, token *CreateTokenDTO) err
 		Token:      token.Token,
 		UserID:     token.UserID,
 		Otp:        token.Otp,
+		SessionID:  token.SessionID,
 	})
 
 	if err != nil {
@@ -92,6 +108,86 @@ func (a *DbTokenStore) DeleteToken(ctx context.Context, token string) error {
 	return nil
 }
 
+func (a *DbTokenStore) UseToken(ctx context.Context, token string) (bool, error) {
+	count, err := database.Exec(
+		ctx,
+		a.db,
+		`UPDATE tokens SET used_at = now() WHERE token = $1 AND used_at IS NULL`,
+		token,
+	)
+	if err != nil {
+		return false, fmt.Errorf("error at using token: %w", err)
+	}
+	return count > 0, nil
+}
+
+func (a *DbTokenStore) FindOtpToken(ctx context.Context, tokenType models.TokenTypes, identifier string, otp string) (*models.Token, error) {
+	res, err := database.One[*models.Token](
+		ctx,
+		a.db,
+		`SELECT id, type, user_id, otp, identifier, expires, token, created_at, updated_at, session_id, used_at
+		FROM tokens
+		WHERE type = $1 AND lower(identifier) = lower($2) AND otp = $3 AND expires > now()
+		ORDER BY created_at DESC
+		LIMIT 1`,
+		tokenType,
+		identifier,
+		otp,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error at finding otp token: %w", err)
+	}
+	return res, nil
+}
+
+func (a *DbTokenStore) FindNextSessionToken(ctx context.Context, sessionID uuid.UUID, tokenType models.TokenTypes, createdAfter time.Time) (*models.Token, error) {
+	res, err := database.One[*models.Token](
+		ctx,
+		a.db,
+		`SELECT id, type, user_id, otp, identifier, expires, token, created_at, updated_at, session_id, used_at
+		FROM tokens
+		WHERE session_id = $1 AND type = $2 AND created_at > $3
+		ORDER BY created_at ASC
+		LIMIT 1`,
+		sessionID,
+		tokenType,
+		createdAfter,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error at finding next session token: %w", err)
+	}
+	return res, nil
+}
+
+func (a *DbTokenStore) DeleteUsedSessionTokens(ctx context.Context, sessionID uuid.UUID, tokenType models.TokenTypes, usedBefore time.Time) error {
+	_, err := database.Exec(
+		ctx,
+		a.db,
+		`DELETE FROM tokens WHERE session_id = $1 AND type = $2 AND used_at < $3`,
+		sessionID,
+		tokenType,
+		usedBefore,
+	)
+	if err != nil {
+		return fmt.Errorf("error at deleting used session tokens: %w", err)
+	}
+	return nil
+}
+
+func (a *DbTokenStore) DeleteIdentifierTokens(ctx context.Context, tokenType models.TokenTypes, identifier string) error {
+	_, err := database.Exec(
+		ctx,
+		a.db,
+		`DELETE FROM tokens WHERE type = $1 AND lower(identifier) = lower($2)`,
+		tokenType,
+		identifier,
+	)
+	if err != nil {
+		return fmt.Errorf("error at deleting identifier tokens: %w", err)
+	}
+	return nil
+}
+
 func (a *DbTokenStore) VerifyTokenStorage(ctx context.Context, token string) error {
 	res, err := a.GetToken(ctx, token)
 	if err != nil {
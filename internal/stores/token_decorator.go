@@ -2,18 +2,26 @@ package stores
 
 import (
 	"context"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/tkahng/playground/internal/database"
 	"github.com/tkahng/playground/internal/models"
 )
 
 type TokenStoreDecorator struct {
-	Delegate               *DbTokenStore
-	DeleteTokenFunc        func(ctx context.Context, token string) error
-	GetTokenFunc           func(ctx context.Context, token string) (*models.Token, error)
-	SaveTokenFunc          func(ctx context.Context, token *CreateTokenDTO) error
-	VerifyTokenStorageFunc func(ctx context.Context, token string) error
-	WithTxFunc             func(dbx database.Dbx) *TokenStoreDecorator
+	Delegate                    *DbTokenStore
+	DeleteTokenFunc             func(ctx context.Context, token string) error
+	GetTokenFunc                func(ctx context.Context, token string) (*models.Token, error)
+	SaveTokenFunc               func(ctx context.Context, token *CreateTokenDTO) error
+	UseTokenFunc                func(ctx context.Context, token string) (bool, error)
+	FindOtpTokenFunc            func(ctx context.Context, tokenType models.TokenTypes, identifier string, otp string) (*models.Token, error)
+	VerifyTokenStorageFunc      func(ctx context.Context, token string) error
+	FindNextSessionTokenFunc    func(ctx context.Context, sessionID uuid.UUID, tokenType models.TokenTypes, createdAfter time.Time) (*models.Token, error)
+	DeleteUsedSessionTokensFunc func(ctx context.Context, sessionID uuid.UUID, tokenType models.TokenTypes, usedBefore time.Time) error
+	DeleteIdentifierTokensFunc  func(ctx context.Context, tokenType models.TokenTypes, identifier string) error
+	WithTxFunc                  func(dbx database.Dbx) *TokenStoreDecorator
 }
 
 func NewTokenStoreDecorator(db database.Dbx) *TokenStoreDecorator {
@@ -28,7 +36,12 @@ func (t *TokenStoreDecorator) Cleanup() {
 	t.DeleteTokenFunc = nil
 	t.GetTokenFunc = nil
 	t.SaveTokenFunc = nil
+	t.UseTokenFunc = nil
+	t.FindOtpTokenFunc = nil
 	t.VerifyTokenStorageFunc = nil
+	t.FindNextSessionTokenFunc = nil
+	t.DeleteUsedSessionTokensFunc = nil
+	t.DeleteIdentifierTokensFunc = nil
 
 }
 
@@ -66,6 +79,46 @@ func (t *TokenStoreDecorator) SaveToken(ctx context.Context, token *CreateTokenD
 	return t.Delegate.SaveToken(ctx, token)
 }
 
+// UseToken implements DbTokenStoreInterface.
+func (t *TokenStoreDecorator) UseToken(ctx context.Context, token string) (bool, error) {
+	if t.UseTokenFunc != nil {
+		return t.UseTokenFunc(ctx, token)
+	}
+	return t.Delegate.UseToken(ctx, token)
+}
+
+// FindOtpToken implements DbTokenStoreInterface.
+func (t *TokenStoreDecorator) FindOtpToken(ctx context.Context, tokenType models.TokenTypes, identifier string, otp string) (*models.Token, error) {
+	if t.FindOtpTokenFunc != nil {
+		return t.FindOtpTokenFunc(ctx, tokenType, identifier, otp)
+	}
+	return t.Delegate.FindOtpToken(ctx, tokenType, identifier, otp)
+}
+
+// FindNextSessionToken implements DbTokenStoreInterface.
+func (t *TokenStoreDecorator) FindNextSessionToken(ctx context.Context, sessionID uuid.UUID, tokenType models.TokenTypes, createdAfter time.Time) (*models.Token, error) {
+	if t.FindNextSessionTokenFunc != nil {
+		return t.FindNextSessionTokenFunc(ctx, sessionID, tokenType, createdAfter)
+	}
+	return t.Delegate.FindNextSessionToken(ctx, sessionID, tokenType, createdAfter)
+}
+
+// DeleteUsedSessionTokens implements DbTokenStoreInterface.
+func (t *TokenStoreDecorator) DeleteUsedSessionTokens(ctx context.Context, sessionID uuid.UUID, tokenType models.TokenTypes, usedBefore time.Time) error {
+	if t.DeleteUsedSessionTokensFunc != nil {
+		return t.DeleteUsedSessionTokensFunc(ctx, sessionID, tokenType, usedBefore)
+	}
+	return t.Delegate.DeleteUsedSessionTokens(ctx, sessionID, tokenType, usedBefore)
+}
+
+// DeleteIdentifierTokens implements DbTokenStoreInterface.
+func (t *TokenStoreDecorator) DeleteIdentifierTokens(ctx context.Context, tokenType models.TokenTypes, identifier string) error {
+	if t.DeleteIdentifierTokensFunc != nil {
+		return t.DeleteIdentifierTokensFunc(ctx, tokenType, identifier)
+	}
+	return t.Delegate.DeleteIdentifierTokens(ctx, tokenType, identifier)
+}
+
 // VerifyTokenStorage implements DbTokenStoreInterface.
 func (t *TokenStoreDecorator) VerifyTokenStorage(ctx context.Context, token string) error {
 	if t.VerifyTokenStorageFunc != nil {
@@ -47,6 +47,18 @@ func TestTokenStore_CRUD(t *testing.T) {
 			assert.Equal(t, tokenStr, got.Token)
 		})
 
+		t.Run("UseToken", func(t *testing.T) {
+			used, err := store.UseToken(ctx, tokenStr)
+			assert.NoError(t, err)
+			assert.True(t, used)
+			got, err := store.GetToken(ctx, tokenStr)
+			assert.NoError(t, err)
+			assert.NotNil(t, got.UsedAt)
+			used, err = store.UseToken(ctx, tokenStr)
+			assert.NoError(t, err)
+			assert.False(t, used, "a token is used once")
+		})
+
 		t.Run("DeleteToken", func(t *testing.T) {
 			err := store.DeleteToken(ctx, tokenStr)
 			assert.NoError(t, err)
@@ -71,6 +83,26 @@ func TestTokenStore_CRUD(t *testing.T) {
 			assert.Nil(t, got)
 		})
 
+		t.Run("FindOtpToken", func(t *testing.T) {
+			otp := "123456"
+			err := store.SaveToken(ctx, &stores.CreateTokenDTO{
+				Type:       models.TokenTypesMagicLinkToken,
+				Identifier: "magic@example.com",
+				Expires:    time.Now().Add(1 * time.Hour),
+				Token:      "tok_magic",
+				Otp:        &otp,
+			})
+			assert.NoError(t, err)
+			got, err := store.FindOtpToken(ctx, models.TokenTypesMagicLinkToken, "Magic@example.com", otp)
+			assert.NoError(t, err)
+			if assert.NotNil(t, got) {
+				assert.Equal(t, "tok_magic", got.Token)
+			}
+			got, err = store.FindOtpToken(ctx, models.TokenTypesMagicLinkToken, "magic@example.com", "654321")
+			assert.NoError(t, err)
+			assert.Nil(t, got)
+		})
+
 		t.Run("VerifyTokenStorage", func(t *testing.T) {
 			tok2 := &stores.CreateTokenDTO{
 				Type:       models.TokenTypesVerificationToken,
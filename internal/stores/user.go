@@ -347,7 +347,7 @@ SELECT u.id AS user_id,
     u.email AS email,
     array_remove(ARRAY_AGG(DISTINCT p.role), NULL)::text [] AS roles,
     array_remove(ARRAY_AGG(DISTINCT p.permission), NULL)::text [] AS permissions,
-    array_remove(ARRAY_AGG(DISTINCT ua.provider), NULL)::public.providers [] AS providers
+    array_remove(ARRAY_AGG(DISTINCT ua.provider), NULL)::text [] AS providers
 FROM public.users u
     LEFT JOIN combined_permissions p ON u.id = p.user_id
     LEFT JOIN public.user_accounts ua ON u.id = ua.user_id
@@ -0,0 +1,114 @@
+package stores
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/database"
+	"github.com/tkahng/playground/internal/models"
+)
+
+type UserSessionStore interface {
+	CreateUserSession(ctx context.Context, session *models.UserSession) (*models.UserSession, error)
+	// FindUserSession returns nil when the session was revoked or expired.
+	FindUserSession(ctx context.Context, id uuid.UUID) (*models.UserSession, error)
+	ListUserSessions(ctx context.Context, userID uuid.UUID) ([]*models.UserSession, error)
+	// TouchUserSession updates the last seen time of the session, at most
+	// once a minute.
+	TouchUserSession(ctx context.Context, id uuid.UUID) error
+	// ExtendUserSession moves the expiry of the session when it is refreshed.
+	ExtendUserSession(ctx context.Context, id uuid.UUID, expires time.Time) (*models.UserSession, error)
+	// DeleteUserSession revokes a session of the user along with its
+	// refresh tokens, it returns false when the user has no such session.
+	DeleteUserSession(ctx context.Context, userID uuid.UUID, id uuid.UUID) (bool, error)
+	DeleteUserSessions(ctx context.Context, userID uuid.UUID) error
+}
+
+type DbUserSessionStore struct {
+	db database.Dbx
+}
+
+var _ UserSessionStore = (*DbUserSessionStore)(nil)
+
+func NewDbUserSessionStore(db database.Dbx) *DbUserSessionStore {
+	return &DbUserSessionStore{
+		db: db,
+	}
+}
+
+const userSessionColumns = `id, user_id, expires, session_token, user_agent, device, ip_address, city, last_seen_at, created_at, updated_at`
+
+// CreateUserSession implements UserSessionStore.
+func (s *DbUserSessionStore) CreateUserSession(ctx context.Context, session *models.UserSession) (*models.UserSession, error) {
+	return database.One[*models.UserSession](
+		ctx,
+		s.db,
+		`INSERT INTO user_sessions (user_id, expires, session_token, user_agent, device, ip_address, city)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING `+userSessionColumns,
+		session.UserID,
+		session.Expires,
+		session.SessionToken,
+		session.UserAgent,
+		session.Device,
+		session.IPAddress,
+		session.City,
+	)
+}
+
+// FindUserSession implements UserSessionStore.
+func (s *DbUserSessionStore) FindUserSession(ctx context.Context, id uuid.UUID) (*models.UserSession, error) {
+	return database.One[*models.UserSession](
+		ctx,
+		s.db,
+		`SELECT `+userSessionColumns+` FROM user_sessions WHERE id = $1 AND expires > now()`,
+		id,
+	)
+}
+
+// ListUserSessions implements UserSessionStore.
+func (s *DbUserSessionStore) ListUserSessions(ctx context.Context, userID uuid.UUID) ([]*models.UserSession, error) {
+	return database.QueryAll[*models.UserSession](
+		ctx,
+		s.db,
+		`SELECT `+userSessionColumns+` FROM user_sessions WHERE user_id = $1 AND expires > now() ORDER BY last_seen_at DESC`,
+		userID,
+	)
+}
+
+// TouchUserSession implements UserSessionStore.
+func (s *DbUserSessionStore) TouchUserSession(ctx context.Context, id uuid.UUID) error {
+	_, err := database.Exec(
+		ctx,
+		s.db,
+		`UPDATE user_sessions SET last_seen_at = now() WHERE id = $1 AND last_seen_at < now() - interval '1 minute'`,
+		id,
+	)
+	return err
+}
+
+// ExtendUserSession implements UserSessionStore.
+func (s *DbUserSessionStore) ExtendUserSession(ctx context.Context, id uuid.UUID, expires time.Time) (*models.UserSession, error) {
+	return database.One[*models.UserSession](
+		ctx,
+		s.db,
+		`UPDATE user_sessions SET expires = $2, last_seen_at = now()
+		WHERE id = $1 AND expires > now()
+		RETURNING `+userSessionColumns,
+		id,
+		expires,
+	)
+}
+
+// DeleteUserSession implements UserSessionStore.
+func (s *DbUserSessionStore) DeleteUserSession(ctx context.Context, userID uuid.UUID, id uuid.UUID) (bool, error) {
+	count, err := database.Exec(ctx, s.db, `DELETE FROM user_sessions WHERE user_id = $1 AND id = $2`, userID, id)
+	return count > 0, err
+}
+
+// DeleteUserSessions implements UserSessionStore.
+func (s *DbUserSessionStore) DeleteUserSessions(ctx context.Context, userID uuid.UUID) error {
+	_, err := database.Exec(ctx, s.db, `DELETE FROM user_sessions WHERE user_id = $1`, userID)
+	return err
+}
@@ -0,0 +1,99 @@
+package stores
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/models"
+)
+
+type UserSessionStoreDecorator struct {
+	Delegate               *DbUserSessionStore
+	CreateUserSessionFunc  func(ctx context.Context, session *models.UserSession) (*models.UserSession, error)
+	FindUserSessionFunc    func(ctx context.Context, id uuid.UUID) (*models.UserSession, error)
+	ListUserSessionsFunc   func(ctx context.Context, userID uuid.UUID) ([]*models.UserSession, error)
+	TouchUserSessionFunc   func(ctx context.Context, id uuid.UUID) error
+	ExtendUserSessionFunc  func(ctx context.Context, id uuid.UUID, expires time.Time) (*models.UserSession, error)
+	DeleteUserSessionFunc  func(ctx context.Context, userID uuid.UUID, id uuid.UUID) (bool, error)
+	DeleteUserSessionsFunc func(ctx context.Context, userID uuid.UUID) error
+}
+
+var _ UserSessionStore = (*UserSessionStoreDecorator)(nil)
+
+// CreateUserSession implements UserSessionStore.
+func (u *UserSessionStoreDecorator) CreateUserSession(ctx context.Context, session *models.UserSession) (*models.UserSession, error) {
+	if u.CreateUserSessionFunc != nil {
+		return u.CreateUserSessionFunc(ctx, session)
+	}
+	if u.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return u.Delegate.CreateUserSession(ctx, session)
+}
+
+// FindUserSession implements UserSessionStore.
+func (u *UserSessionStoreDecorator) FindUserSession(ctx context.Context, id uuid.UUID) (*models.UserSession, error) {
+	if u.FindUserSessionFunc != nil {
+		return u.FindUserSessionFunc(ctx, id)
+	}
+	if u.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return u.Delegate.FindUserSession(ctx, id)
+}
+
+// ListUserSessions implements UserSessionStore.
+func (u *UserSessionStoreDecorator) ListUserSessions(ctx context.Context, userID uuid.UUID) ([]*models.UserSession, error) {
+	if u.ListUserSessionsFunc != nil {
+		return u.ListUserSessionsFunc(ctx, userID)
+	}
+	if u.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return u.Delegate.ListUserSessions(ctx, userID)
+}
+
+// TouchUserSession implements UserSessionStore.
+func (u *UserSessionStoreDecorator) TouchUserSession(ctx context.Context, id uuid.UUID) error {
+	if u.TouchUserSessionFunc != nil {
+		return u.TouchUserSessionFunc(ctx, id)
+	}
+	if u.Delegate == nil {
+		return ErrDelegateNil
+	}
+	return u.Delegate.TouchUserSession(ctx, id)
+}
+
+// ExtendUserSession implements UserSessionStore.
+func (u *UserSessionStoreDecorator) ExtendUserSession(ctx context.Context, id uuid.UUID, expires time.Time) (*models.UserSession, error) {
+	if u.ExtendUserSessionFunc != nil {
+		return u.ExtendUserSessionFunc(ctx, id, expires)
+	}
+	if u.Delegate == nil {
+		return nil, ErrDelegateNil
+	}
+	return u.Delegate.ExtendUserSession(ctx, id, expires)
+}
+
+// DeleteUserSession implements UserSessionStore.
+func (u *UserSessionStoreDecorator) DeleteUserSession(ctx context.Context, userID uuid.UUID, id uuid.UUID) (bool, error) {
+	if u.DeleteUserSessionFunc != nil {
+		return u.DeleteUserSessionFunc(ctx, userID, id)
+	}
+	if u.Delegate == nil {
+		return false, ErrDelegateNil
+	}
+	return u.Delegate.DeleteUserSession(ctx, userID, id)
+}
+
+// DeleteUserSessions implements UserSessionStore.
+func (u *UserSessionStoreDecorator) DeleteUserSessions(ctx context.Context, userID uuid.UUID) error {
+	if u.DeleteUserSessionsFunc != nil {
+		return u.DeleteUserSessionsFunc(ctx, userID)
+	}
+	if u.Delegate == nil {
+		return ErrDelegateNil
+	}
+	return u.Delegate.DeleteUserSessions(ctx, userID)
+}
@@ -0,0 +1,61 @@
+package stores_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkahng/playground/internal/database"
+	"github.com/tkahng/playground/internal/models"
+	"github.com/tkahng/playground/internal/stores"
+	"github.com/tkahng/playground/internal/test"
+)
+
+func TestUserSessionStore(t *testing.T) {
+	test.DbSetup()
+	test.WithTx(t, func(ctx context.Context, db database.Dbx) {
+		adapter := stores.NewStorageAdapter(db)
+		store := stores.NewDbUserSessionStore(db)
+		user := CreateUser(adapter, ctx, "sessions@example.com")
+
+		session, err := store.CreateUserSession(ctx, &models.UserSession{
+			UserID:       user.ID,
+			Expires:      time.Now().Add(time.Hour),
+			SessionToken: "session-token",
+			Device:       "Firefox on Linux",
+			IPAddress:    "127.0.0.1",
+		})
+		require.NoError(t, err)
+		require.NotNil(t, session)
+		err = adapter.Token().SaveToken(ctx, &stores.CreateTokenDTO{
+			Type:       models.TokenTypesRefreshToken,
+			Identifier: user.Email,
+			Expires:    time.Now().Add(time.Hour),
+			Token:      "refresh-token",
+			UserID:     &user.ID,
+			SessionID:  &session.ID,
+		})
+		require.NoError(t, err)
+
+		sessions, err := store.ListUserSessions(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Len(t, sessions, 1)
+
+		extended, err := store.ExtendUserSession(ctx, session.ID, time.Now().Add(2*time.Hour))
+		require.NoError(t, err)
+		require.NotNil(t, extended)
+		assert.True(t, extended.Expires.After(session.Expires))
+
+		deleted, err := store.DeleteUserSession(ctx, user.ID, session.ID)
+		require.NoError(t, err)
+		assert.True(t, deleted)
+		found, err := store.FindUserSession(ctx, session.ID)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+		// the refresh tokens of the session go with it
+		_, err = adapter.Token().GetToken(ctx, "refresh-token")
+		assert.Error(t, err)
+	})
+}
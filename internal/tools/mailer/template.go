@@ -17,6 +17,16 @@ const (
 	EmailTypeSecurityPasswordReset EmailType = "security-password-reset"
 	EmailTypeTeamInvite            EmailType = "team-invite"
 	EmailTypeInvite                EmailType = "invite"
+	EmailTypeMagicLink             EmailType = "magic-link"
+)
+
+const (
+	// SecurityAlertRefreshTokenReuse is sent when a refresh token is used
+	// twice and its session was signed out.
+	SecurityAlertRefreshTokenReuse = "refresh-token-reuse"
+	// SecurityAlertAccountLocked is sent when too many failed sign ins
+	// locked the account.
+	SecurityAlertAccountLocked = "account-locked"
 )
 
 var (
@@ -50,6 +60,12 @@ var (
 			TemplatePath: "/password-reset",
 			Template:     DefaultSecurityPasswordResetMail,
 		},
+		EmailTypeMagicLink: {
+			Type:         EmailTypeMagicLink,
+			Subject:      "%s - Your sign in link",
+			TemplatePath: "/magic-link",
+			Template:     DefaultMagicLinkMail,
+		},
 	}
 )
 
@@ -154,6 +170,12 @@ const DefaultConfirmationMail = `<h2>Confirm your email</h2>
 <p>Alternatively, enter the code: {{ .Token }}</p>
 `
 
+const DefaultMagicLinkMail = `<h2>Sign in to {{ .SiteURL }}</h2>
+<p>Follow this link to sign in:</p>
+<p><a href="{{ .ConfirmationURL }}">Sign in</a></p>
+<p>Alternatively, enter the code: {{ .Token }}</p>
+<p>If you did not ask to sign in, you can ignore this email.</p>`
+
 const DefaultSecurityPasswordResetMail = `<h2>Your password has been reset due to security concerns</h2>
 <p>We noticed that you signed in with a social provider while you were already signed in with an unverified email/password account.</p>
 <p>For your security, we have reset your password to a temporary password.</p>
@@ -161,6 +183,16 @@ const DefaultSecurityPasswordResetMail = `<h2>Your password has been reset due t
 <p><a href="{{ .ConfirmationURL }}">Reset password</a></p>
 <p>Alternatively, enter the code: {{ .Token }}</p>`
 
+const DefaultRefreshTokenReuseMail = `<h2>We signed out one of your devices</h2>
+<p>A sign in token of your account on {{ .SiteURL }} was used twice, which happens when it was copied from your device.</p>
+<p>For your security, we signed that device out{{ if .Device }} ({{ .Device }}{{ if .City }} near {{ .City }}{{ end }}{{ if .IPAddress }}, {{ .IPAddress }}{{ end }}){{ end }}. Sign in again to continue using it.</p>
+<p>If you do not recognize this, change your password and sign out of your other devices.</p>`
+
+const DefaultAccountLockedMail = `<h2>Suspicious sign in attempts</h2>
+<p>Someone failed to sign in to your account on {{ .SiteURL }} too many times{{ if .IPAddress }}, last from {{ .IPAddress }}{{ if .City }} near {{ .City }}{{ end }}{{ if .Device }} with {{ .Device }}{{ end }}{{ end }}.</p>
+<p>For your security, we locked signing in with your password for a while. You can still reset your password.</p>
+<p>If this was not you, we recommend choosing a new password.</p>`
+
 const DefaultRecoveryMail = `<h2>Reset password</h2>
 
 <p>Follow this link to reset the password for your user:</p>
@@ -1,5 +1,22 @@
 package sse
 
 const (
-	UserReactionsChannel = "user-reactions"
+	UserReactionsChannel = "reactions"
+	AdminJobStatsChannel = "admin-job-stats"
 )
+
+// TeamMemberChannel is the channel the notifications of a team member are
+// sent on.
+func TeamMemberChannel(teamMemberID string) string {
+	return "team_member:" + teamMemberID
+}
+
+// ProjectChannel is the channel the changes of a task project are sent on.
+func ProjectChannel(projectID string) string {
+	return "project:" + projectID
+}
+
+// SticksGameChannel is the channel the state of a sticks game is sent on.
+func SticksGameChannel(gameID string) string {
+	return "sticks_game:" + gameID
+}
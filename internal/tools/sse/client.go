@@ -4,13 +4,34 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
 	"runtime"
+	"slices"
 	"sync"
 	"time"
+
+	"github.com/google/uuid"
+
+	humasse "github.com/danielgtaylor/huma/v2/sse"
 )
 
+// Sender writes a single message to the connection.
+type Sender = humasse.Sender
+
 type Message struct {
-	Data any `json:"data"`
+	// ID is sent as the SSE id field and is echoed back by reconnecting
+	// clients in the Last-Event-ID header. Zero means the message has no id.
+	// Ids are assigned by the manager so every message of a connection shares
+	// one increasing id space.
+	ID int64 `json:"id,omitempty"`
+	// Seq is the sequence of the row the message was stored as, such as a
+	// notification. Clients created WithSeqIDs send it instead of ID since
+	// they replay from the database.
+	Seq int64 `json:"seq,omitempty"`
+	// Event is the name the data is sent under, taken from the event types
+	// the operation was registered with.
+	Event string `json:"event,omitempty"`
+	Data  any    `json:"data"`
 }
 
 type Client interface {
@@ -24,29 +45,88 @@ type Client interface {
 	// Client Key is the unique client identifier. usually some kind of keyName:keyValue
 	Channel() string
 
+	// ID identifies the connection for topic changes made outside of it.
+	ID() string
+	// Owner is the user the connection was opened by, only the owner can
+	// change its topics.
+	Owner() string
+	// Topics returns the topics the client is subscribed to besides Channel.
+	Topics() []string
+	Subscribe(topics ...string)
+	Unsubscribe(topics ...string)
+	// Subscribed reports whether messages sent to topic reach the client.
+	Subscribed(topic string) bool
+
 	// write is a low level function to send messages to the client
 	Write(Message) error
 
+	// Replay writes messages the client missed while disconnected, ahead of
+	// anything queued by Write. It must be called before WriteForever, queued
+	// messages already covered by the replay are skipped.
+	Replay([]Message) error
+
 	// Close implements the Closer interface. Note the behavior of calling Close()
 	// multiple times is undefined; this implementation swallows all errors.
 	Close() error
 }
 type client struct {
+	id               string
+	owner            string
+	topics           map[string]struct{}
 	lock             *sync.RWMutex
 	wg               *sync.WaitGroup
 	egress           chan Message
 	logger           *slog.Logger
 	channel          string
-	send             func(any) error
+	send             Sender
+	replayed         map[int64]struct{}
+	seqIDs           bool
 	pingMessageFunc  func() any
 	closeMessageFunc func() any
 }
 
-func NewClient(clientId string, sender func(any) error, logger *slog.Logger, pingMessageFunc func() any) Client {
+// ClientOption configures a Client created by NewClient.
+type ClientOption func(*client)
+
+// WithID sets the id of the client instead of a random one.
+func WithID(id string) ClientOption {
+	return func(c *client) {
+		c.id = id
+	}
+}
+
+// WithOwner sets the user allowed to change the topics of the client.
+func WithOwner(owner string) ClientOption {
+	return func(c *client) {
+		c.owner = owner
+	}
+}
+
+// WithSeqIDs makes the client send the Seq of messages as their id, for
+// connections replayed from the database by seq. Messages without a seq are
+// sent without an id, so the Last-Event-ID of the client stays a seq.
+func WithSeqIDs() ClientOption {
+	return func(c *client) {
+		c.seqIDs = true
+	}
+}
+
+// WithTopics subscribes the client to topics from the start.
+func WithTopics(topics ...string) ClientOption {
+	return func(c *client) {
+		for _, topic := range topics {
+			c.topics[topic] = struct{}{}
+		}
+	}
+}
+
+func NewClient(clientId string, sender Sender, logger *slog.Logger, pingMessageFunc func() any, opts ...ClientOption) Client {
 	// add 2 to the wait group for the read/write goroutines
 	wg := &sync.WaitGroup{}
 	wg.Add(1)
-	return &client{
+	c := &client{
+		id:              uuid.NewString(),
+		topics:          make(map[string]struct{}),
 		lock:            &sync.RWMutex{},
 		wg:              wg,
 		send:            sender,
@@ -55,18 +135,90 @@ func NewClient(clientId string, sender func(any) error, logger *slog.Logger, pin
 		logger:          logger,
 		pingMessageFunc: pingMessageFunc,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 func (c *client) Channel() string {
 	return c.channel
 }
 
+func (c *client) ID() string {
+	return c.id
+}
+
+func (c *client) Owner() string {
+	return c.owner
+}
+
+func (c *client) Topics() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return slices.Sorted(maps.Keys(c.topics))
+}
+
+func (c *client) Subscribe(topics ...string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, topic := range topics {
+		c.topics[topic] = struct{}{}
+	}
+}
+
+func (c *client) Unsubscribe(topics ...string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, topic := range topics {
+		delete(c.topics, topic)
+	}
+}
+
+func (c *client) Subscribed(topic string) bool {
+	if topic == c.channel {
+		return true
+	}
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	_, ok := c.topics[topic]
+	return ok
+}
+
 // Write implements the Writer interface.
 func (c *client) Write(p Message) error {
 	c.egress <- p
 	return nil
 }
 
+// Replay implements Client.
+func (c *client) Replay(messages []Message) error {
+	for _, m := range messages {
+		if err := c.write(m); err != nil {
+			return err
+		}
+		if id := c.eventID(m); id > 0 {
+			if c.replayed == nil {
+				c.replayed = make(map[int64]struct{}, len(messages))
+			}
+			c.replayed[id] = struct{}{}
+		}
+	}
+	return nil
+}
+
+// eventID is the id m is sent with.
+func (c *client) eventID(m Message) int64 {
+	if c.seqIDs {
+		return m.Seq
+	}
+	return m.ID
+}
+
+func (c *client) write(m Message) error {
+	return c.send(humasse.Message{ID: int(c.eventID(m)), Data: m.Data})
+}
+
 // Close implements the Closer interface. Note the behavior of calling Close()
 // multiple times is undefined; this implementation swallows all errors.
 func (c *client) Close() error {
@@ -92,7 +244,7 @@ func (c *client) WriteForever(ctx context.Context, onDestroy func(Client), ping
 		select {
 		case <-ctx.Done():
 			if c.closeMessageFunc != nil {
-				if err := c.send(c.closeMessageFunc()); err != nil {
+				if err := c.send.Data(c.closeMessageFunc()); err != nil {
 					c.Log(int(slog.LevelError), fmt.Sprintf("error writing close message: %v", err))
 				}
 			}
@@ -101,20 +253,27 @@ func (c *client) WriteForever(ctx context.Context, onDestroy func(Client), ping
 			// ok will be false in case the egress channel is closed
 			if !ok {
 				if c.closeMessageFunc != nil {
-					if err := c.send(c.closeMessageFunc()); err != nil {
+					if err := c.send.Data(c.closeMessageFunc()); err != nil {
 						c.Log(int(slog.LevelError), fmt.Sprintf("error writing close message: %v", err))
 					}
 				}
 				return
 			}
+			// ids of instances are not sent in order, so only the ids
+			// that were replayed are skipped, not every lower one
+			if _, ok := c.replayed[c.eventID(message)]; ok {
+				// already sent by Replay
+				delete(c.replayed, c.eventID(message))
+				continue
+			}
 			// write a message to the connection
-			if err := c.send(message.Data); err != nil {
+			if err := c.write(message); err != nil {
 				c.Log(int(slog.LevelError), fmt.Sprintf("error writing message: %v", err))
 				return
 			}
 		case <-pingTicker.C:
 			if c.pingMessageFunc != nil {
-				if err := c.send(c.pingMessageFunc()); err != nil {
+				if err := c.send.Data(c.pingMessageFunc()); err != nil {
 					c.Log(int(slog.LevelError), fmt.Sprintf("error writing ping: %v", err))
 					return
 				}
@@ -0,0 +1,90 @@
+package sse
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// historySize is the number of messages kept per channel for replay.
+	historySize = 100
+	// historyChannels bounds the number of channels with a replay buffer,
+	// the channel whose last message is the oldest is dropped first.
+	historyChannels = 1024
+)
+
+// history keeps the last messages sent to each channel so clients that
+// reconnect with a Last-Event-ID can be sent what they missed.
+type history struct {
+	mu       sync.Mutex
+	lastID   int64
+	channels map[string][]Message
+}
+
+func newHistory() *history {
+	return &history{
+		channels: make(map[string][]Message),
+	}
+}
+
+// nextID returns an id greater than every id seen so far. Ids are based on the
+// clock so they keep increasing across restarts. Managers shared by several
+// instances take their ids from the database instead.
+func (h *history) nextID() int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastID = max(h.lastID+1, time.Now().UnixMicro())
+	return h.lastID
+}
+
+// record adds msg to the replay buffer of channel. Messages without an id
+// cannot be asked for and are not kept.
+func (h *history) record(channel string, msg Message) {
+	if msg.ID == 0 {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastID = max(h.lastID, msg.ID)
+	buf, ok := h.channels[channel]
+	if !ok && len(h.channels) >= historyChannels {
+		h.evict()
+	}
+	if len(buf) == historySize {
+		buf = append(buf[:0], buf[1:]...)
+	}
+	// messages of other instances can arrive after later ones, keep the
+	// buffer ordered by id for since
+	i := len(buf)
+	for i > 0 && buf[i-1].ID > msg.ID {
+		i--
+	}
+	h.channels[channel] = slices.Insert(buf, i, msg)
+}
+
+func (h *history) evict() {
+	var oldest string
+	var oldestID int64
+	for channel, buf := range h.channels {
+		id := buf[len(buf)-1].ID
+		if oldest == "" || id < oldestID {
+			oldest, oldestID = channel, id
+		}
+	}
+	delete(h.channels, oldest)
+}
+
+// since returns the buffered messages of channel with an id after lastID, in
+// the order they were sent.
+func (h *history) since(channel string, lastID int64) []Message {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var messages []Message
+	for _, msg := range h.channels[channel] {
+		if msg.ID > lastID {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
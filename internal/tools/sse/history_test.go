@@ -0,0 +1,53 @@
+package sse
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory(t *testing.T) {
+	h := newHistory()
+	var ids []int64
+	for i := range historySize + 10 {
+		id := h.nextID()
+		ids = append(ids, id)
+		h.record("a", Message{ID: id, Data: i})
+	}
+	h.record("a", Message{Data: "without id"})
+
+	got := h.since("a", 0)
+	assert.Len(t, got, historySize, "buffer is bounded")
+	assert.Equal(t, 10, got[0].Data, "oldest messages are dropped first")
+
+	got = h.since("a", ids[len(ids)-3])
+	assert.Equal(t, []Message{{ID: ids[len(ids)-2], Data: historySize + 8}, {ID: ids[len(ids)-1], Data: historySize + 9}}, got)
+	assert.Empty(t, h.since("b", 0))
+}
+
+func TestHistory_Evict(t *testing.T) {
+	h := newHistory()
+	for i := range historyChannels + 1 {
+		h.record(fmt.Sprintf("channel-%d", i), Message{ID: h.nextID()})
+	}
+	assert.Len(t, h.channels, historyChannels)
+	assert.Empty(t, h.since("channel-0", 0), "least recently used channel is evicted")
+	assert.Len(t, h.since(fmt.Sprintf("channel-%d", historyChannels), 0), 1)
+}
+
+func TestHistory_NextIDFollowsRecorded(t *testing.T) {
+	h := newHistory()
+	remote := h.nextID() + 1_000_000
+	h.record("a", Message{ID: remote})
+	assert.Greater(t, h.nextID(), remote)
+}
+
+func TestHistory_OrderedByID(t *testing.T) {
+	h := newHistory()
+	h.record("a", Message{ID: 1})
+	h.record("a", Message{ID: 3})
+	// sent by another instance before 3 but received after it
+	h.record("a", Message{ID: 2})
+	assert.Equal(t, []Message{{ID: 2}, {ID: 3}}, h.since("a", 1))
+}
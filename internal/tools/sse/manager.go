@@ -1,9 +1,11 @@
 package sse
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"log/slog"
+	"slices"
 	"sync"
 )
 
@@ -15,6 +17,48 @@ type Manager interface {
 
 	Send(clientId string, data any) error
 	SendAll(data any) error
+
+	// SendMessage sends msg to the clients of channel and keeps it for
+	// Replay. An id is assigned when msg.ID is zero.
+	SendMessage(channel string, msg Message) error
+	// Replay returns the messages sent to channel after lastEventID that are
+	// still buffered.
+	Replay(channel string, lastEventID int64) []Message
+
+	// UpdateTopics subscribes the client with the given id to subscribe and
+	// unsubscribes it from unsubscribe, then sends it a TopicsEvent. owner must
+	// match the owner of the client.
+	UpdateTopics(clientID, owner string, subscribe, unsubscribe []string) error
+}
+
+var (
+	ErrClientNotFound = errors.New("sse client not found")
+	ErrNotClientOwner = errors.New("sse client belongs to another user")
+)
+
+// TopicsEvent tells a client which topics it is subscribed to.
+type TopicsEvent struct {
+	ClientID string   `json:"client_id"`
+	Topics   []string `json:"topics"`
+}
+
+// ClientChannel is the channel every client with the given id is subscribed
+// to.
+func ClientChannel(clientID string) string {
+	return "sse_client:" + clientID
+}
+
+// ReplayTopics returns the buffered messages of every topic sent after
+// lastEventID, ordered by id.
+func ReplayTopics(m Manager, topics []string, lastEventID int64) []Message {
+	var messages []Message
+	for _, topic := range topics {
+		messages = append(messages, m.Replay(topic, lastEventID)...)
+	}
+	slices.SortFunc(messages, func(a, b Message) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return messages
 }
 
 type manager struct {
@@ -23,17 +67,45 @@ type manager struct {
 	clients    map[Client]context.CancelFunc
 	register   chan regreq
 	unregister chan regreq
+	history    *history
 }
 
 // Send implements Manager.
 func (m *manager) Send(channel string, data any) error {
+	return m.SendMessage(channel, Message{Data: data})
+}
+
+// SendMessage implements Manager.
+func (m *manager) SendMessage(channel string, msg Message) error {
+	if msg.ID == 0 {
+		msg.ID = m.history.nextID()
+	}
+	return m.deliver(channel, msg)
+}
+
+// Replay implements Manager.
+func (m *manager) Replay(channel string, lastEventID int64) []Message {
+	if lastEventID <= 0 {
+		return nil
+	}
+	return m.history.since(channel, lastEventID)
+}
+
+// deliver records msg and writes it to the local clients of channel.
+func (m *manager) deliver(channel string, msg Message) error {
+	if msg.Event == "" {
+		msg.Event = eventName(msg.Data)
+	}
+	m.history.record(channel, msg)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	var errs []error
 	for c := range m.clients {
 		if c == nil {
 			continue
 		}
-		if c.Channel() == channel {
-			err := c.Write(Message{Data: data})
+		if c.Subscribed(channel) {
+			err := c.Write(msg)
 			if err != nil {
 				errs = append(errs, err)
 			}
@@ -48,9 +120,12 @@ func (m *manager) Send(channel string, data any) error {
 
 // SendAll implements Manager.
 func (m *manager) SendAll(data any) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	var errs []error
+	msg := Message{Event: eventName(data), Data: data}
 	for c := range m.clients {
-		if err := c.Write(Message{Data: data}); err != nil {
+		if err := c.Write(msg); err != nil {
 			errs = append(errs, err)
 		}
 	}
@@ -60,6 +135,27 @@ func (m *manager) SendAll(data any) error {
 	return nil
 }
 
+// UpdateTopics implements Manager.
+func (m *manager) UpdateTopics(clientID, owner string, subscribe, unsubscribe []string) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for c := range m.clients {
+		if c.ID() != clientID {
+			continue
+		}
+		if c.Owner() != owner {
+			return ErrNotClientOwner
+		}
+		c.Unsubscribe(unsubscribe...)
+		c.Subscribe(subscribe...)
+		return c.Write(Message{
+			Event: eventName(TopicsEvent{}),
+			Data:  TopicsEvent{ClientID: clientID, Topics: c.Topics()},
+		})
+	}
+	return ErrClientNotFound
+}
+
 type regreq struct {
 	context context.Context
 	cancel  context.CancelFunc
@@ -74,6 +170,7 @@ func NewManager(logger *slog.Logger) Manager {
 		clients:    make(map[Client]context.CancelFunc),
 		register:   make(chan regreq),
 		unregister: make(chan regreq),
+		history:    newHistory(),
 	}
 }
 
@@ -0,0 +1,117 @@
+package sse
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	humasse "github.com/danielgtaylor/huma/v2/sse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_UpdateTopics(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	RegisterEventTypes(map[string]any{"test": &testEvent{}, "topics": &TopicsEvent{}})
+
+	m := NewManager(slog.Default())
+	go m.Run(ctx)
+
+	c := NewClient("own", func(humasse.Message) error { return nil }, slog.Default(), nil,
+		WithOwner("user-1"),
+		WithTopics("project:1"),
+	).(*client)
+	m.RegisterClient(ctx, func() {}, c)
+
+	require.NoError(t, m.Send("project:1", testEvent{Message: "subscribed at connect"}))
+	require.NoError(t, m.Send("project:2", testEvent{Message: "not subscribed"}))
+	require.NoError(t, m.Send("own", testEvent{Message: "own channel"}))
+
+	assert.ErrorIs(t, m.UpdateTopics(c.ID(), "user-2", []string{"project:2"}, nil), ErrNotClientOwner)
+	assert.ErrorIs(t, m.UpdateTopics("unknown", "user-1", []string{"project:2"}, nil), ErrClientNotFound)
+	require.NoError(t, m.UpdateTopics(c.ID(), "user-1", []string{"project:2"}, []string{"project:1"}))
+
+	require.NoError(t, m.Send("project:1", testEvent{Message: "unsubscribed"}))
+	require.NoError(t, m.Send("project:2", testEvent{Message: "subscribed later"}))
+
+	var got []any
+	for len(c.egress) > 0 {
+		got = append(got, (<-c.egress).Data)
+	}
+	assert.Equal(t, []any{
+		testEvent{Message: "subscribed at connect"},
+		testEvent{Message: "own channel"},
+		TopicsEvent{ClientID: c.ID(), Topics: []string{"project:2"}},
+		testEvent{Message: "subscribed later"},
+	}, got)
+}
+
+func TestReplayTopics(t *testing.T) {
+	m := NewManager(slog.Default())
+	require.NoError(t, m.Send("a", testEvent{Message: "a1"}))
+	require.NoError(t, m.Send("b", testEvent{Message: "b1"}))
+	require.NoError(t, m.Send("a", testEvent{Message: "a2"}))
+	first := m.Replay("a", 1)[0]
+
+	var got []any
+	for _, msg := range ReplayTopics(m, []string{"a", "b"}, first.ID) {
+		got = append(got, msg.Data)
+	}
+	assert.Equal(t, []any{testEvent{Message: "b1"}, testEvent{Message: "a2"}}, got)
+}
+
+func TestReplayTopics_SeqMessages(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := NewManager(slog.Default())
+	go m.Run(ctx)
+
+	// notifications carry the seq of their row, a small number next to the
+	// ids of the manager
+	require.NoError(t, m.Send("reactions", testEvent{Message: "r1"}))
+	require.NoError(t, m.SendMessage("team_member:1", Message{Seq: 7, Data: testEvent{Message: "n7"}}))
+	require.NoError(t, m.Send("reactions", testEvent{Message: "r2"}))
+	first := m.Replay("reactions", 1)[0]
+
+	var sent []humasse.Message
+	c := NewClient("topics", func(msg humasse.Message) error {
+		sent = append(sent, msg)
+		return nil
+	}, slog.Default(), nil, WithTopics("reactions", "team_member:1")).(*client)
+	m.RegisterClient(ctx, func() {}, c)
+	require.NoError(t, c.Replay(ReplayTopics(m, c.Topics(), first.ID)))
+	require.NoError(t, m.SendMessage("team_member:1", Message{Seq: 8, Data: testEvent{Message: "n8"}}))
+	live := <-c.egress
+	assert.NotContains(t, c.replayed, live.ID, "live notifications are not mistaken for replayed ones")
+
+	require.Len(t, sent, 2)
+	assert.Equal(t, testEvent{Message: "n7"}, sent[0].Data)
+	assert.Equal(t, testEvent{Message: "r2"}, sent[1].Data)
+	assert.Greater(t, sent[0].ID, int(first.ID), "the topics connection sends ids of the manager")
+
+	seqClient := NewClient("team_member:1", func(msg humasse.Message) error {
+		sent = append(sent, msg)
+		return nil
+	}, slog.Default(), nil, WithSeqIDs()).(*client)
+	require.NoError(t, seqClient.Replay([]Message{{Seq: 7, Data: testEvent{Message: "n7"}}}))
+	assert.Equal(t, 7, sent[2].ID, "connections replayed from the database send seqs")
+	assert.Contains(t, seqClient.replayed, int64(7))
+}
+
+func TestClient_ReplaySkipsOnlyReplayedIDs(t *testing.T) {
+	var sent []int
+	c := NewClient("a", func(msg humasse.Message) error {
+		sent = append(sent, msg.ID)
+		return nil
+	}, slog.Default(), nil).(*client)
+	require.NoError(t, c.Replay([]Message{{ID: 5, Data: testEvent{Message: "5"}}}))
+
+	// 4 was sent by another instance and arrives after 5 was replayed
+	require.NoError(t, c.Write(Message{ID: 4, Data: testEvent{Message: "4"}}))
+	require.NoError(t, c.Write(Message{ID: 5, Data: testEvent{Message: "5"}}))
+	close(c.egress)
+	c.WriteForever(context.Background(), func(Client) {}, time.Minute)
+	assert.Equal(t, []int{5, 4}, sent)
+}
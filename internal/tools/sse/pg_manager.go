@@ -0,0 +1,233 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/database"
+	"github.com/tkahng/playground/internal/tools/notifier"
+)
+
+// PgNotifyChannel is the Postgres channel messages are published on.
+const PgNotifyChannel = "sse_messages"
+
+// maxNotifyPayload keeps envelopes under the 8000 bytes NOTIFY limit. Larger
+// messages are stored in sse_payloads and only their id is published.
+const maxNotifyPayload = 7900
+
+// envelope is the published form of a message. Data is empty when the message
+// was stored in sse_payloads under PayloadID.
+type envelope struct {
+	Origin    uuid.UUID       `json:"origin"`
+	ID        int64           `json:"id,omitempty"`
+	Seq       int64           `json:"seq,omitempty"`
+	Channel   string          `json:"channel,omitempty"`
+	All       bool            `json:"all,omitempty"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	PayloadID *uuid.UUID      `json:"payload_id,omitempty"`
+	Topics    *topicsUpdate   `json:"topics,omitempty"`
+}
+
+// topicsUpdate is a topic change for a client connected to another instance.
+type topicsUpdate struct {
+	ClientID    string   `json:"client_id"`
+	Owner       string   `json:"owner"`
+	Subscribe   []string `json:"subscribe,omitempty"`
+	Unsubscribe []string `json:"unsubscribe,omitempty"`
+}
+
+// pgManager is a Manager that reaches clients connected to every instance.
+// Messages are delivered to local clients directly and published through
+// Postgres NOTIFY, other instances decode them back into the registered event
+// type and deliver them to their own clients.
+type pgManager struct {
+	*manager
+	id       uuid.UUID
+	db       database.Dbx
+	notifier notifier.Notifier
+	incoming chan []byte
+}
+
+var _ Manager = (*pgManager)(nil)
+
+// NewPgManager returns a Manager that fans messages out to all instances
+// sharing the database. The notifier must be running for messages from other
+// instances to be received.
+func NewPgManager(logger *slog.Logger, db database.Dbx, n notifier.Notifier) Manager {
+	return &pgManager{
+		manager:  NewManager(logger).(*manager),
+		id:       uuid.New(),
+		db:       db,
+		notifier: n,
+		incoming: make(chan []byte, 256),
+	}
+}
+
+// Send implements Manager.
+func (m *pgManager) Send(channel string, data any) error {
+	return m.SendMessage(channel, Message{Data: data})
+}
+
+// SendMessage implements Manager. The id is assigned here so every instance
+// keeps the message under the same id for Replay. Ids come from a sequence,
+// the clocks of instances are not in step.
+func (m *pgManager) SendMessage(channel string, msg Message) error {
+	if msg.ID == 0 {
+		err := m.db.QueryRow(context.Background(), "SELECT nextval('public.sse_message_id_seq')").Scan(&msg.ID)
+		if err != nil {
+			return fmt.Errorf("next sse message id: %w", err)
+		}
+	}
+	if err := m.manager.SendMessage(channel, msg); err != nil {
+		return err
+	}
+	return m.publish(context.Background(), &envelope{Channel: channel, ID: msg.ID, Seq: msg.Seq}, msg.Data)
+}
+
+// SendAll implements Manager.
+func (m *pgManager) SendAll(data any) error {
+	if err := m.manager.SendAll(data); err != nil {
+		return err
+	}
+	return m.publish(context.Background(), &envelope{All: true}, data)
+}
+
+// UpdateTopics implements Manager. Clients connected to another instance are
+// updated by that instance, so ErrClientNotFound is only returned for local
+// lookups and the change is published instead.
+func (m *pgManager) UpdateTopics(clientID, owner string, subscribe, unsubscribe []string) error {
+	err := m.manager.UpdateTopics(clientID, owner, subscribe, unsubscribe)
+	if !errors.Is(err, ErrClientNotFound) {
+		return err
+	}
+	payload, err := json.Marshal(&envelope{
+		Origin: m.id,
+		Topics: &topicsUpdate{
+			ClientID:    clientID,
+			Owner:       owner,
+			Subscribe:   subscribe,
+			Unsubscribe: unsubscribe,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("marshal sse envelope: %w", err)
+	}
+	_, err = m.db.Exec(context.Background(), "SELECT pg_notify($1, $2)", PgNotifyChannel, string(payload))
+	return err
+}
+
+func (m *pgManager) publish(ctx context.Context, env *envelope, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("marshal sse data: %w", err)
+	}
+	env.Origin = m.id
+	env.Type = eventTypeName(reflect.TypeOf(data))
+	env.Data = raw
+	payload, err := json.Marshal(env)
+	if err != nil {
+		return fmt.Errorf("marshal sse envelope: %w", err)
+	}
+	if len(payload) > maxNotifyPayload {
+		var id uuid.UUID
+		err = m.db.QueryRow(ctx, `
+			INSERT INTO sse_payloads (payload) VALUES ($1) RETURNING id
+		`, raw).Scan(&id)
+		if err != nil {
+			return fmt.Errorf("store sse payload: %w", err)
+		}
+		// payloads only need to outlive the notification
+		_, err = m.db.Exec(ctx, `
+			DELETE FROM sse_payloads WHERE created_at < clock_timestamp() - interval '5 minutes'
+		`)
+		if err != nil {
+			m.logger.ErrorContext(ctx, "error deleting old sse payloads", slog.Any("error", err))
+		}
+		env.Data = nil
+		env.PayloadID = &id
+		if payload, err = json.Marshal(env); err != nil {
+			return fmt.Errorf("marshal sse envelope: %w", err)
+		}
+	}
+	_, err = m.db.Exec(ctx, "SELECT pg_notify($1, $2)", PgNotifyChannel, string(payload))
+	return err
+}
+
+// Run implements Manager.
+func (m *pgManager) Run(ctx context.Context) {
+	sub := m.notifier.Subscribe(PgNotifyChannel)
+	// ctx is cancelled by the time Run returns
+	defer sub.Unlisten(context.WithoutCancel(ctx))
+
+	// the notifier drops notifications when its small buffer is full, move
+	// them to our own buffer before decoding
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case payload := <-sub.NotificationC():
+				select {
+				case m.incoming <- payload:
+				default:
+					m.logger.Error("dropped sse notification due to full buffer")
+				}
+			}
+		}
+	}()
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case payload := <-m.incoming:
+				if err := m.receive(ctx, payload); err != nil {
+					m.logger.ErrorContext(ctx, "error delivering sse notification", slog.Any("error", err))
+				}
+			}
+		}
+	}()
+
+	m.manager.Run(ctx)
+}
+
+func (m *pgManager) receive(ctx context.Context, payload []byte) error {
+	var env envelope
+	if err := json.Unmarshal(payload, &env); err != nil {
+		return fmt.Errorf("unmarshal sse envelope: %w", err)
+	}
+	if env.Origin == m.id {
+		// already delivered locally by Send
+		return nil
+	}
+	if env.Topics != nil {
+		err := m.manager.UpdateTopics(env.Topics.ClientID, env.Topics.Owner, env.Topics.Subscribe, env.Topics.Unsubscribe)
+		if errors.Is(err, ErrClientNotFound) {
+			// connected to yet another instance
+			return nil
+		}
+		return err
+	}
+	if env.PayloadID != nil {
+		err := m.db.QueryRow(ctx, `
+			SELECT payload FROM sse_payloads WHERE id = $1
+		`, *env.PayloadID).Scan(&env.Data)
+		if err != nil {
+			return fmt.Errorf("load sse payload: %w", err)
+		}
+	}
+	data, err := decodeEventData(env.Type, env.Data)
+	if err != nil {
+		return fmt.Errorf("decode sse data: %w", err)
+	}
+	if env.All {
+		return m.manager.SendAll(data)
+	}
+	return m.manager.SendMessage(env.Channel, Message{ID: env.ID, Seq: env.Seq, Data: data})
+}
@@ -0,0 +1,150 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkahng/playground/internal/database"
+	"github.com/tkahng/playground/internal/test"
+	"github.com/tkahng/playground/internal/tools/notifier"
+)
+
+type testEvent struct {
+	Message string `json:"message"`
+}
+
+// recordingClient is a Client that records the messages written to it.
+type recordingClient struct {
+	channel  string
+	messages chan Message
+}
+
+func newRecordingClient(channel string) *recordingClient {
+	return &recordingClient{channel: channel, messages: make(chan Message, 8)}
+}
+
+func (c *recordingClient) WriteForever(context.Context, func(Client), time.Duration) {}
+func (c *recordingClient) Wait()                                                     {}
+func (c *recordingClient) Channel() string                                           { return c.channel }
+func (c *recordingClient) ID() string                                                { return c.channel }
+func (c *recordingClient) Owner() string                                             { return "" }
+func (c *recordingClient) Topics() []string                                          { return nil }
+func (c *recordingClient) Subscribe(...string)                                       {}
+func (c *recordingClient) Unsubscribe(...string)                                     {}
+func (c *recordingClient) Subscribed(topic string) bool                              { return topic == c.channel }
+func (c *recordingClient) Close() error                                              { return nil }
+func (c *recordingClient) Replay(messages []Message) error {
+	for _, m := range messages {
+		c.messages <- m
+	}
+	return nil
+}
+func (c *recordingClient) Write(m Message) error {
+	c.messages <- m
+	return nil
+}
+
+func startPgManager(t *testing.T, ctx context.Context, dbx database.Dbx) Manager {
+	t.Helper()
+	listener := notifier.NewListener(dbx)
+	if err := listener.Connect(ctx); err != nil {
+		t.Fatalf("listener.Connect() error = %v", err)
+	}
+	t.Cleanup(func() {
+		// nolint:errcheck
+		listener.Close(context.Background())
+	})
+	n := notifier.NewNotifier(slog.Default(), listener)
+	go n.Run(ctx)
+	m := NewPgManager(slog.Default(), dbx, n)
+	go m.Run(ctx)
+
+	probe := n.Subscribe(PgNotifyChannel)
+	select {
+	case <-probe.EstablishedC():
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for LISTEN")
+	}
+	return m
+}
+
+func TestDecodeEventData(t *testing.T) {
+	RegisterEventTypes(map[string]any{"test": &testEvent{}})
+
+	got, err := decodeEventData(eventTypeName(reflect.TypeOf(testEvent{})), []byte(`{"message":"hi"}`))
+	require.NoError(t, err)
+	assert.Equal(t, &testEvent{Message: "hi"}, got)
+
+	got, err = decodeEventData("unknown.Type", []byte(`{"message":"hi"}`))
+	require.NoError(t, err)
+	assert.Equal(t, json.RawMessage(`{"message":"hi"}`), got)
+}
+
+func TestPgManager_Send(t *testing.T) {
+	test.SkipIfShort(t)
+	RegisterEventTypes(map[string]any{"test": &testEvent{}})
+
+	tests := []struct {
+		name    string
+		message string
+	}{
+		{
+			name:    "small payload is sent in the notification",
+			message: "hello",
+		},
+		{
+			name:    "payload over the notify limit is stored",
+			message: strings.Repeat("x", 10000),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, dbx := test.DbSetup()
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+
+			a := startPgManager(t, ctx, dbx)
+			b := startPgManager(t, ctx, dbx)
+
+			local := newRecordingClient("team")
+			a.RegisterClient(ctx, func() {}, local)
+			remote := newRecordingClient("team")
+			b.RegisterClient(ctx, func() {}, remote)
+			other := newRecordingClient("other")
+			b.RegisterClient(ctx, func() {}, other)
+
+			require.NoError(t, a.Send("team", testEvent{Message: tt.message}))
+
+			for _, c := range []*recordingClient{local, remote} {
+				select {
+				case m := <-c.messages:
+					got, ok := m.Data.(*testEvent)
+					if !ok {
+						// delivered locally with the type it was sent with
+						v, isValue := m.Data.(testEvent)
+						require.True(t, isValue, "unexpected data type %T", m.Data)
+						got = &v
+					}
+					assert.Equal(t, tt.message, got.Message)
+				case <-time.After(5 * time.Second):
+					t.Fatal("timed out waiting for message")
+				}
+			}
+
+			select {
+			case m := <-local.messages:
+				t.Errorf("local client got message twice: %v", m)
+			case m := <-other.messages:
+				t.Errorf("client on another channel got message: %v", m)
+			case <-time.After(200 * time.Millisecond):
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package sse
 
 import (
 	"context"
+	"log/slog"
 	"time"
 
 	humasse "github.com/danielgtaylor/huma/v2/sse"
@@ -10,10 +11,13 @@ import (
 func ServeSSE[I any](
 
 	// clientFactory is a function that takes a connection and returns a new Client
-	clientFactory func(context.Context, func(any) error, *I) Client,
+	clientFactory func(context.Context, Sender, *I) Client,
 	// onCreate is a function to call once the Client is created (e.g.,
 	// store it in a some collection on the service for later reference)
 	onCreate func(context.Context, context.CancelFunc, Client),
+	// replay returns the messages the client missed before reconnecting. It
+	// runs after onCreate so nothing sent in between is lost, may be nil
+	replay func(context.Context, *I, Client) ([]Message, error),
 	// onDestroy is a function to call after the WebSocket connection is closed
 	// (e.g., remove it from the collection on the service)
 	onDestroy func(Client),
@@ -23,8 +27,17 @@ func ServeSSE[I any](
 ) func(context.Context, *I, humasse.Sender) {
 	return func(ctx context.Context, input *I, send humasse.Sender) {
 		baseCtx, cf := context.WithCancel(ctx)
-		client := clientFactory(baseCtx, send.Data, input)
+		client := clientFactory(baseCtx, send, input)
 		onCreate(baseCtx, cf, client)
+		if replay != nil {
+			messages, err := replay(baseCtx, input, client)
+			if err == nil {
+				err = client.Replay(messages)
+			}
+			if err != nil {
+				slog.ErrorContext(ctx, "error replaying sse messages", slog.Any("error", err))
+			}
+		}
 		client.WriteForever(baseCtx, onDestroy, ping)
 	}
 }
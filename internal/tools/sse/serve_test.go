@@ -2,6 +2,7 @@ package sse_test
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -37,9 +38,9 @@ func TestWSHandler(t *testing.T) {
 	go manager.Run(ctx)
 
 	h := sse.ServeSSE(
-		func(ctx context.Context, f func(any) error, _ *struct{}) sse.Client {
-			return sse.NewClient("test", func(a any) error {
-				err := f(a)
+		func(ctx context.Context, f sse.Sender, _ *struct{}) sse.Client {
+			return sse.NewClient("test", func(m humasse.Message) error {
+				err := f(m)
 				messageChan <- struct{}{}
 				return err
 			}, slog.Default(), nil)
@@ -51,6 +52,7 @@ func TestWSHandler(t *testing.T) {
 			t.Log("registered client")
 			doneReg <- c
 		},
+		nil,
 		func(_c sse.Client) {
 			t.Log("unregistering client in ondestroy")
 			manager.UnregisterClient(_c)
@@ -109,11 +111,73 @@ func TestWSHandler(t *testing.T) {
 	// _p := <-doneReg
 	assert.Equal(t, http.StatusOK, resp.Code)
 	assert.Equal(t, "text/event-stream", resp.Header().Get("Content-Type"))
-	assert.Equal(t, `data: {"message":"test"}
+	assert.Regexp(t, `^id: \d+
+data: {"message":"test"}
 
-`, resp.Body.String())
+$`, resp.Body.String())
 
 	// _p := <-doneUnreg
 	// time.Sleep(1 * time.Second)
 	//FIXME: seems to be leaking goroutines
 }
+
+type replayInput struct {
+	LastEventID int64 `header:"Last-Event-ID"`
+}
+
+func TestServeSSE_Replay(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, api := humatest.New(t)
+
+	manager := sse.NewManager(slog.Default())
+	go manager.Run(ctx)
+
+	h := sse.ServeSSE(
+		func(ctx context.Context, f sse.Sender, _ *replayInput) sse.Client {
+			return sse.NewClient("test", f, slog.Default(), nil)
+		},
+		func(ctx context.Context, cf context.CancelFunc, c sse.Client) {
+			manager.RegisterClient(ctx, cf, c)
+			// end the stream once the replay is written
+			cf()
+		},
+		func(ctx context.Context, input *replayInput, c sse.Client) ([]sse.Message, error) {
+			return manager.Replay(c.Channel(), input.LastEventID), nil
+		},
+		func(c sse.Client) {
+			manager.UnregisterClient(c)
+		},
+		50*time.Second,
+	)
+	sse.Register(
+		api,
+		huma.Operation{
+			OperationID: "sse-replay",
+			Method:      http.MethodGet,
+			Path:        "/sse",
+		},
+		map[string]any{
+			"message": &DefaultMessage{},
+		},
+		h,
+	)
+
+	for _, m := range []string{"first", "second", "third"} {
+		assert.NoError(t, manager.Send("test", DefaultMessage{Message: m}))
+	}
+	first := manager.Replay("test", 1)[0]
+	assert.Equal(t, "message", first.Event)
+
+	resp := api.Get("/sse", fmt.Sprintf("Last-Event-ID: %d", first.ID))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	body := resp.Body.String()
+	assert.NotContains(t, body, "first")
+	assert.Regexp(t, `(?s)^id: \d+
+data: {"message":"second"}
+
+id: \d+
+data: {"message":"third"}
+
+$`, body)
+}
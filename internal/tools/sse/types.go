@@ -0,0 +1,79 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+
+	"github.com/danielgtaylor/huma/v2"
+	humasse "github.com/danielgtaylor/huma/v2/sse"
+)
+
+// eventTypes maps the name of every type registered as SSE event data to its
+// type, so data received from another instance can be decoded back into the
+// type huma uses to pick the event name.
+var eventTypes sync.Map
+
+// eventNames maps the name of every registered type to the event name it is
+// sent under.
+var eventNames sync.Map
+
+func deref(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func eventTypeName(t reflect.Type) string {
+	t = deref(t)
+	return t.PkgPath() + "." + t.Name()
+}
+
+// RegisterEventTypes records the data types of an SSE event type map.
+func RegisterEventTypes(eventTypeMap map[string]any) {
+	for event, v := range eventTypeMap {
+		t := deref(reflect.TypeOf(v))
+		eventTypes.Store(eventTypeName(t), t)
+		eventNames.Store(eventTypeName(t), event)
+	}
+}
+
+// EventName returns the event name data is sent under, or an empty string
+// when its type was not registered.
+func EventName(data any) string {
+	return eventName(data)
+}
+
+func eventName(data any) string {
+	if data == nil {
+		return ""
+	}
+	name, ok := eventNames.Load(eventTypeName(reflect.TypeOf(data)))
+	if !ok {
+		return ""
+	}
+	return name.(string)
+}
+
+// decodeEventData decodes data into a pointer to the registered type with the
+// given name. Unknown types are returned as json.RawMessage.
+func decodeEventData(name string, data json.RawMessage) (any, error) {
+	t, ok := eventTypes.Load(name)
+	if !ok {
+		return data, nil
+	}
+	v := reflect.New(t.(reflect.Type))
+	if err := json.Unmarshal(data, v.Interface()); err != nil {
+		return nil, err
+	}
+	return v.Interface(), nil
+}
+
+// Register registers an SSE operation like humasse.Register and records its
+// event types with RegisterEventTypes.
+func Register[I any](api huma.API, op huma.Operation, eventTypeMap map[string]any, f func(ctx context.Context, input *I, send humasse.Sender)) {
+	RegisterEventTypes(eventTypeMap)
+	humasse.Register(api, op, eventTypeMap, f)
+}
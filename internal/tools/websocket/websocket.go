@@ -12,6 +12,7 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
 	"runtime"
 	"slices"
@@ -33,6 +34,19 @@ func DefaultSetupConn(c *websocket.Conn) {
 	})
 }
 
+// SetupConn returns a connection setup like DefaultSetupConn that accepts
+// messages of up to readLimit bytes.
+func SetupConn(readLimit int64, pongWait time.Duration) func(*websocket.Conn) {
+	return func(c *websocket.Conn) {
+		c.SetReadLimit(readLimit)
+		c.SetReadDeadline(time.Now().Add(pongWait))
+		c.SetPongHandler(func(string) error {
+			c.SetReadDeadline(time.Now().Add(pongWait))
+			return nil
+		})
+	}
+}
+
 func DefaultUpgrader(origins []string) websocket.Upgrader {
 	upgrader := websocket.Upgrader{ReadBufferSize: 1024, WriteBufferSize: 1024}
 	upgrader.CheckOrigin = func(r *http.Request) bool {
@@ -68,6 +82,28 @@ type Client interface {
 	Wait()
 }
 
+// SameOriginUpgrader accepts connections without an Origin header, from the
+// host the request was sent to and from hosts.
+func SameOriginUpgrader(hosts ...string) websocket.Upgrader {
+	upgrader := websocket.Upgrader{ReadBufferSize: 1024, WriteBufferSize: 1024}
+	upgrader.CheckOrigin = func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		u, err := url.Parse(origin)
+		if err != nil {
+			return false
+		}
+		return u.Host == r.Host || slices.Contains(hosts, u.Host)
+	}
+	return upgrader
+}
+
+// ErrClientClosed is returned when writing to a client that stopped writing
+// to its connection.
+var ErrClientClosed = errors.New("websocket client closed")
+
 type MessageHandler func(Client, []byte)
 
 // ServeWS upgrades HTTP connections to WebSocket, creates the Client, calls the
@@ -119,6 +155,7 @@ type client struct {
 	wg     *sync.WaitGroup
 	conn   *websocket.Conn
 	egress chan []byte
+	done   chan struct{}
 	logger *slog.Logger
 }
 
@@ -138,14 +175,20 @@ func NewClient(c *websocket.Conn) Client {
 		wg:     wg,
 		conn:   c,
 		egress: make(chan []byte, 32),
+		done:   make(chan struct{}),
 		logger: slog.New(slog.NewJSONHandler(os.Stdout, nil)),
 	}
 }
 
-// Write implements the Writer interface.
+// Write implements the Writer interface. It fails once WriteForever has
+// returned instead of blocking on a connection nobody writes to anymore.
 func (c *client) Write(p []byte) (int, error) {
-	c.egress <- p
-	return len(p), nil
+	select {
+	case c.egress <- p:
+		return len(p), nil
+	case <-c.done:
+		return 0, ErrClientClosed
+	}
 }
 
 // Close implements the Closer interface. Note the behavior of calling Close()
@@ -162,6 +205,7 @@ func (c *client) Close() error {
 func (c *client) WriteForever(ctx context.Context, onDestroy func(Client), ping time.Duration) {
 	pingTicker := time.NewTicker(ping)
 	defer func() {
+		close(c.done)
 		c.wg.Done()
 		pingTicker.Stop()
 		onDestroy(c)
@@ -363,6 +407,7 @@ func (m *manager) Run(ctx context.Context) {
 				cleanupClient(client)
 			}
 			m.mu.Unlock()
+			return
 		case rr := <-m.register:
 			m.mu.Lock()
 			m.clients[rr.client] = rr.cancel
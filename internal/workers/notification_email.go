@@ -0,0 +1,59 @@
+package workers
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/jobs"
+)
+
+// NotificationEmailJobArgs sends one notification to a team member by email.
+type NotificationEmailJobArgs struct {
+	TeamMemberID     uuid.UUID       `json:"team_member_id" required:"true"`
+	NotificationType string          `json:"notification_type" required:"true"`
+	Payload          json.RawMessage `json:"payload" required:"true"`
+}
+
+func (j NotificationEmailJobArgs) Kind() string {
+	return "notification_email"
+}
+
+type NotificationEmailJobWorker jobs.Worker[NotificationEmailJobArgs]
+
+// NotificationDigestJobArgs sends the daily digests of Day. The job enqueues
+// one NotificationDigestEmailJobArgs per member and schedules the next day.
+type NotificationDigestJobArgs struct {
+	Day time.Time `json:"day" required:"true"`
+}
+
+func (j NotificationDigestJobArgs) Kind() string {
+	return "notification_digest"
+}
+
+type NotificationDigestJobWorker jobs.Worker[NotificationDigestJobArgs]
+
+// NotificationDigestEmailJobArgs sends the unread notifications a team member
+// got since Since in one email.
+type NotificationDigestEmailJobArgs struct {
+	TeamMemberID uuid.UUID `json:"team_member_id" required:"true"`
+	Since        time.Time `json:"since" required:"true"`
+}
+
+func (j NotificationDigestEmailJobArgs) Kind() string {
+	return "notification_digest_email"
+}
+
+type NotificationDigestEmailJobWorker jobs.Worker[NotificationDigestEmailJobArgs]
+
+// NotificationRetentionJobArgs deletes the read notifications that are older
+// than the retention period on Day and schedules the next day.
+type NotificationRetentionJobArgs struct {
+	Day time.Time `json:"day" required:"true"`
+}
+
+func (j NotificationRetentionJobArgs) Kind() string {
+	return "notification_retention"
+}
+
+type NotificationRetentionJobWorker jobs.Worker[NotificationRetentionJobArgs]
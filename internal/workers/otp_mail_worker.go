@@ -12,6 +12,8 @@ import (
 type OtpEmailJobArgs struct {
 	UserID uuid.UUID
 	Type   mailer.EmailType
+	// Email is where magic links go, their users may not exist yet.
+	Email string
 }
 
 func (j OtpEmailJobArgs) Kind() string {
@@ -26,6 +28,8 @@ type otpMailWorker struct {
 type OtpMailServiceInterface interface {
 	SendTeamInvitationEmail(ctx context.Context, params *TeamInvitationJobArgs) error
 	SendOtpEmail(ctx context.Context, emailType mailer.EmailType, userId uuid.UUID) error
+	SendSecurityAlertEmail(ctx context.Context, params *SecurityAlertJobArgs) error
+	SendMagicLinkEmail(ctx context.Context, email string) error
 }
 
 func NewOtpEmailWorker(otpMailService OtpMailServiceInterface) jobs.Worker[OtpEmailJobArgs] {
@@ -36,7 +40,12 @@ func NewOtpEmailWorker(otpMailService OtpMailServiceInterface) jobs.Worker[OtpEm
 
 // Work implements jobs.Worker.
 func (w *otpMailWorker) Work(ctx context.Context, job *jobs.Job[OtpEmailJobArgs]) error {
-	err := w.mail.SendOtpEmail(ctx, job.Args.Type, job.Args.UserID)
+	var err error
+	if job.Args.Type == mailer.EmailTypeMagicLink {
+		err = w.mail.SendMagicLinkEmail(ctx, job.Args.Email)
+	} else {
+		err = w.mail.SendOtpEmail(ctx, job.Args.Type, job.Args.UserID)
+	}
 	if err != nil {
 		slog.ErrorContext(
 			ctx,
@@ -0,0 +1,39 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tkahng/playground/internal/jobs"
+)
+
+// SecurityAlertJobArgs emails a user that their account may be compromised.
+// The device, ip and city are of the session the alert is about.
+type SecurityAlertJobArgs struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Alert     string    `json:"alert"`
+	Device    string    `json:"device"`
+	IPAddress string    `json:"ip_address"`
+	City      string    `json:"city"`
+	At        time.Time `json:"at"`
+}
+
+func (j SecurityAlertJobArgs) Kind() string {
+	return "security_alert_email"
+}
+
+type securityAlertWorker struct {
+	mail OtpMailServiceInterface
+}
+
+// Work implements jobs.Worker.
+func (w *securityAlertWorker) Work(ctx context.Context, job *jobs.Job[SecurityAlertJobArgs]) error {
+	return w.mail.SendSecurityAlertEmail(ctx, &job.Args)
+}
+
+func NewSecurityAlertWorker(otpMailService OtpMailServiceInterface) jobs.Worker[SecurityAlertJobArgs] {
+	return &securityAlertWorker{
+		mail: otpMailService,
+	}
+}